@@ -0,0 +1,176 @@
+package xyJson
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// adaptiveBuckets 输入大小直方图的桶数，第i个桶包含长度的二进制位数为i的文档
+// adaptiveBuckets is the number of buckets of the input size histogram, bucket i holding documents whose
+// length has i binary digits
+const adaptiveBuckets = 40
+
+// adaptiveDecay 移动平均中新文档的权重为1/adaptiveDecay
+// adaptiveDecay gives a new document a weight of 1/adaptiveDecay in the moving averages
+const adaptiveDecay = 8
+
+// SizingHints 自适应大小调整对某个输入大小给出的容量预估
+// SizingHints holds the capacities adaptive sizing expects for an input size
+type SizingHints struct {
+	// ObjectKeys 每个对象预留的键数
+	// ObjectKeys is the number of keys reserved per object
+	ObjectKeys int
+
+	// ArrayElements 每个数组预留的元素数
+	// ArrayElements is the number of elements reserved per array
+	ArrayElements int
+
+	// Documents 该大小等级已观察到的文档数
+	// Documents is the number of documents observed in this size class
+	Documents int64
+}
+
+// sizeBucket 某个输入大小等级的统计，使用指数移动平均使旧文档的影响逐渐消失
+// sizeBucket holds the statistics of an input size class, using exponential moving averages so old
+// documents fade out
+type sizeBucket struct {
+	documents     int64
+	keysPerObject float64
+	elemsPerArray float64
+}
+
+// adaptiveSizer 按输入大小记录文档中对象和数组的平均大小
+// adaptiveSizer records the average object and array sizes of documents by input size
+type adaptiveSizer struct {
+	mu      sync.RWMutex
+	buckets [adaptiveBuckets]sizeBucket
+}
+
+// parseCounts 一次解析中的容器计数
+// parseCounts holds the container counts of one parse
+type parseCounts struct {
+	objects  int
+	members  int
+	arrays   int
+	elements int
+}
+
+// globalSizer ParseOptions.AdaptiveSizing使用的全局统计
+// globalSizer holds the global statistics used by ParseOptions.AdaptiveSizing
+var globalSizer = &adaptiveSizer{}
+
+// AdaptiveSizingHints 返回自适应大小调整对size字节的文档给出的容量预估，尚无统计时为零值
+// AdaptiveSizingHints returns the capacities adaptive sizing expects for a document of size bytes, the zero
+// value while nothing has been observed
+//
+// 示例 Example:
+//
+//	hints := xyJson.AdaptiveSizingHints(len(data))
+//	fmt.Println(hints.ObjectKeys, hints.ArrayElements)
+func AdaptiveSizingHints(size int) SizingHints {
+	return globalSizer.hints(size)
+}
+
+// ResetAdaptiveSizing 清除自适应大小调整的统计，例如在流量结构改变之后
+// ResetAdaptiveSizing clears the adaptive sizing statistics, for example after the traffic changed shape
+func ResetAdaptiveSizing() {
+	globalSizer.mu.Lock()
+	defer globalSizer.mu.Unlock()
+	globalSizer.buckets = [adaptiveBuckets]sizeBucket{}
+}
+
+// bucketOf 返回输入大小所属的桶
+// bucketOf returns the bucket of an input size
+func bucketOf(size int) int {
+	return min(bits.Len(uint(max(size, 0))), adaptiveBuckets-1)
+}
+
+// hints 返回size字节的文档的容量预估
+// hints returns the capacity estimates for a document of size bytes
+func (s *adaptiveSizer) hints(size int) SizingHints {
+	s.mu.RLock()
+	b := s.buckets[bucketOf(size)]
+	s.mu.RUnlock()
+
+	return SizingHints{
+		ObjectKeys:    int(b.keysPerObject + 0.5),
+		ArrayElements: int(b.elemsPerArray + 0.5),
+		Documents:     b.documents,
+	}
+}
+
+// observe 记录一个size字节的文档中的容器计数
+// observe records the container counts of a document of size bytes
+func (s *adaptiveSizer) observe(size int, counts parseCounts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.buckets[bucketOf(size)]
+	b.documents++
+	if counts.objects > 0 {
+		b.keysPerObject = movingAverage(b.keysPerObject, float64(counts.members)/float64(counts.objects), b.documents)
+	}
+	if counts.arrays > 0 {
+		b.elemsPerArray = movingAverage(b.elemsPerArray, float64(counts.elements)/float64(counts.arrays), b.documents)
+	}
+}
+
+// movingAverage 将sample并入平均值avg，前adaptiveDecay个文档使用算术平均
+// movingAverage folds sample into the average avg, using the arithmetic mean for the first adaptiveDecay
+// documents
+func movingAverage(avg, sample float64, documents int64) float64 {
+	weight := float64(min(documents, adaptiveDecay))
+	return avg + (sample-avg)/weight
+}
+
+// startSizing 开始一次解析的大小调整
+// startSizing starts the sizing of one parse
+func (p *parser) startSizing(size int) {
+	if p.sizer == nil {
+		return
+	}
+	p.hints = p.sizer.hints(size)
+	p.counts = parseCounts{}
+}
+
+// finishSizing 将成功解析的文档计入统计
+// finishSizing records a successfully parsed document in the statistics
+func (p *parser) finishSizing(size int) {
+	if p.sizer != nil {
+		p.sizer.observe(size, p.counts)
+	}
+}
+
+// sizeObject 按预估为新对象预留容量
+// sizeObject reserves the estimated capacity for a new object
+func (p *parser) sizeObject(obj IObject) {
+	if p.sizer != nil && p.hints.ObjectKeys > 0 {
+		obj.EnsureCapacity(p.hints.ObjectKeys)
+	}
+}
+
+// sizeArray 按预估为新数组预留容量
+// sizeArray reserves the estimated capacity for a new array
+func (p *parser) sizeArray(arr IArray) {
+	if p.sizer != nil && p.hints.ArrayElements > 0 {
+		arr.Grow(p.hints.ArrayElements)
+	}
+}
+
+// countObject 记录解析完成的非空对象，空对象不需要容量，不计入平均值
+// countObject records a completed non-empty object, empty objects need no capacity and stay out of the average
+func (p *parser) countObject(obj IObject) {
+	if p.sizer != nil {
+		p.counts.objects++
+		p.counts.members += obj.Size()
+	}
+}
+
+// countArray 记录解析完成的非空数组
+// countArray records a completed non-empty array
+func (p *parser) countArray(arr IArray) {
+	if p.sizer != nil {
+		p.counts.arrays++
+		p.counts.elements += arr.Length()
+	}
+}
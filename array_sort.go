@@ -0,0 +1,180 @@
+package xyJson
+
+import (
+	"sort"
+	"strings"
+)
+
+// ArraySortRule 序列化时对数组元素排序的规则
+// ArraySortRule is a rule that sorts array elements during serialization
+//
+// 示例 Example:
+//
+//	serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+//		Indent:   "  ",
+//		SortKeys: true,
+//		MaxDepth: xyJson.DefaultMaxDepth,
+//		ArraySort: []xyJson.ArraySortRule{
+//			{Path: "$.users", By: ".id"},
+//			{Path: "$.groups[*].members"},
+//		},
+//	})
+type ArraySortRule struct {
+	// Path 要排序的数组的JSONPath，可以匹配多个数组
+	// Path is the JSONPath of the arrays to sort, it may match several arrays
+	Path string
+
+	// By 元素内排序键的相对路径，如".id"或"$.meta.rank"，为空时按元素本身排序
+	// By is the relative path of the sort key inside each element, such as ".id" or "$.meta.rank",
+	// elements are compared themselves when empty
+	By string
+
+	// Descending 是否降序排列
+	// Descending indicates descending order
+	Descending bool
+}
+
+// withArrayOrder 返回带有本次序列化数组顺序的序列化器副本
+// withArrayOrder returns a copy of the serializer carrying the array order for this serialization
+//
+// 多条规则匹配同一数组时第一条生效。缺少排序键的元素总是排在最后，相等的元素保持原有顺序
+// When several rules match the same array the first one wins. Elements missing the sort key always come
+// last, equal elements keep their original order
+func (s *serializer) withArrayOrder(root IValue) (*serializer, error) {
+	pq := &pathQuery{factory: defaultFactory()}
+	order := make(map[IValue][]int)
+
+	for _, rule := range s.options.ArraySort {
+		arrays, err := pq.SelectAll(root, rule.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		var by []*pathSegment
+		if byPath := normalizeSortKeyPath(rule.By); byPath != "$" {
+			if by, err = pq.parsePath(byPath); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, value := range arrays {
+			arr, ok := value.(IArray)
+			if !ok {
+				continue
+			}
+			if _, exists := order[arr]; exists {
+				continue
+			}
+			order[arr] = sortedArrayOrder(pq, arr, by, rule.Descending)
+		}
+	}
+
+	return &serializer{options: s.options, arrayOrder: order, monitor: s.monitor}, nil
+}
+
+// sortedArrayOrder 计算数组元素排序后的下标顺序
+// sortedArrayOrder computes the index order of the sorted array elements
+func sortedArrayOrder(pq *pathQuery, arr IArray, by []*pathSegment, descending bool) []int {
+	length := arr.Length()
+	indices := make([]int, length)
+	keys := make([]IValue, length)
+	for i := 0; i < length; i++ {
+		indices[i] = i
+		element := arr.Get(i)
+		if element == nil || by == nil {
+			keys[i] = element
+			continue
+		}
+		if results := pq.executeQuery(element, by, false); len(results) > 0 {
+			keys[i] = results[0]
+		}
+	}
+
+	sort.SliceStable(indices, func(a, b int) bool {
+		ka, kb := keys[indices[a]], keys[indices[b]]
+		if ka == nil || kb == nil {
+			return ka != nil
+		}
+		cmp := compareSortKeys(ka, kb)
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	return indices
+}
+
+// normalizeSortKeyPath 将".id"、"id"等相对写法转换为JSONPath
+// normalizeSortKeyPath converts relative forms such as ".id" and "id" into a JSONPath
+func normalizeSortKeyPath(path string) string {
+	switch {
+	case path == "" || path == "$":
+		return "$"
+	case strings.HasPrefix(path, "$"):
+		return path
+	case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+		return "$" + path
+	default:
+		return "$." + path
+	}
+}
+
+// sortTypeRank 不同类型排序键之间的顺序
+// sortTypeRank is the order between sort keys of different types
+func sortTypeRank(t ValueType) int {
+	switch t {
+	case NullValueType:
+		return 0
+	case BoolValueType:
+		return 1
+	case NumberValueType:
+		return 2
+	case StringValueType:
+		return 3
+	case ArrayValueType:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// compareSortKeys 比较两个排序键，类型不同时按类型排序，容器按紧凑序列化结果比较
+// compareSortKeys compares two sort keys, ordering by type when the types differ and comparing containers
+// by their compact serialization
+func compareSortKeys(a, b IValue) int {
+	ra, rb := sortTypeRank(a.Type()), sortTypeRank(b.Type())
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch a.Type() {
+	case NullValueType:
+		return 0
+	case BoolValueType:
+		ba, bb := a.AsBool(), b.AsBool()
+		switch {
+		case ba == bb:
+			return 0
+		case bb:
+			return -1
+		default:
+			return 1
+		}
+	case NumberValueType:
+		fa, fb := a.AsFloat64(), b.AsFloat64()
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	case StringValueType:
+		return strings.Compare(a.AsString(), b.AsString())
+	default:
+		sa, _ := mappingSerializer.Serialize(a)
+		sb, _ := mappingSerializer.Serialize(b)
+		return strings.Compare(string(sa), string(sb))
+	}
+}
@@ -7,24 +7,39 @@ import (
 
 // arrayValue JSON数组实现
 // arrayValue implements the IArray interface
+//
+// 新数组的data指向内联的small，不超过SmallArraySize个元素时不需要单独分配切片；
+// append超出容量时自然溢出到堆上
+// A new array's data points at the inline small storage, so up to SmallArraySize elements need no separate
+// slice allocation; append spills to the heap naturally once the capacity is exceeded
 type arrayValue struct {
-	data []IValue
-	mu   sync.RWMutex
+	valueMeta
+	jsonCache
+	small [SmallArraySize]IValue
+	data  []IValue
+	mu    sync.RWMutex
+}
+
+// newArrayValue 创建使用内联存储的数组
+// newArrayValue creates an array using the inline storage
+func newArrayValue() *arrayValue {
+	av := &arrayValue{}
+	av.data = av.small[:0]
+	return av
 }
 
 // NewArray 创建新的JSON数组
 // NewArray creates a new JSON array
 func NewArray() IArray {
-	return &arrayValue{
-		data: make([]IValue, 0, DefaultArrayCapacity),
-	}
+	return newArrayValue()
 }
 
-// NewArrayWithCapacity 创建指定容量的JSON数组
-// NewArrayWithCapacity creates a JSON array with specified capacity
+// NewArrayWithCapacity 创建指定容量的JSON数组，容量不超过SmallArraySize时使用内联存储
+// NewArrayWithCapacity creates a JSON array with specified capacity, using the inline storage when the capacity
+// does not exceed SmallArraySize
 func NewArrayWithCapacity(capacity int) IArray {
-	if capacity <= 0 {
-		capacity = DefaultArrayCapacity
+	if capacity <= SmallArraySize {
+		return newArrayValue()
 	}
 	return &arrayValue{
 		data: make([]IValue, 0, capacity),
@@ -92,6 +107,9 @@ func (av *arrayValue) Clone() IValue {
 	for _, value := range av.data {
 		newArr.Append(value.Clone())
 	}
+	if nv, ok := newArr.(*arrayValue); ok {
+		av.copyMetaTo(&nv.valueMeta)
+	}
 	return newArr
 }
 
@@ -164,6 +182,7 @@ func (av *arrayValue) Set(index int, value interface{}) error {
 	}
 
 	av.data[index] = jsonValue
+	av.touch()
 	return nil
 }
 
@@ -190,6 +209,7 @@ func (av *arrayValue) Append(value interface{}) error {
 	defer av.mu.Unlock()
 
 	av.data = append(av.data, jsonValue)
+	av.touch()
 	return nil
 }
 
@@ -225,6 +245,7 @@ func (av *arrayValue) Insert(index int, value interface{}) error {
 	copy(av.data[index+1:], av.data[index:])
 	// 插入新值
 	av.data[index] = jsonValue
+	av.touch()
 
 	return nil
 }
@@ -242,6 +263,7 @@ func (av *arrayValue) Delete(index int) error {
 	// 移动元素并缩短切片
 	copy(av.data[index:], av.data[index+1:])
 	av.data = av.data[:len(av.data)-1]
+	av.touch()
 
 	return nil
 }
@@ -261,8 +283,10 @@ func (av *arrayValue) Clear() {
 	av.mu.Lock()
 	defer av.mu.Unlock()
 
-	// 重置切片但保留容量
+	// 重置切片但保留容量，并释放对旧元素的引用
+	clear(av.data)
 	av.data = av.data[:0]
+	av.touch()
 }
 
 // Range 遍历数组元素
@@ -292,39 +316,54 @@ func (av *arrayValue) reset() {
 	defer av.mu.Unlock()
 
 	// 清空数据但保留底层切片的容量
+	clear(av.data)
 	av.data = av.data[:0]
+	av.touch()
+	av.clearMeta()
 }
 
-// AppendAll 批量追加多个值
-// AppendAll appends multiple values at once
-func (av *arrayValue) AppendAll(values ...interface{}) error {
-	factory := NewValueFactory()
-	jsonValues := make([]IValue, 0, len(values))
+// AppendAll 一次性追加多个值，只扩容一次；nil元素追加为null
+// AppendAll appends multiple values at once, growing only once; nil elements are appended as null
+func (av *arrayValue) AppendAll(values ...IValue) {
+	if len(values) == 0 {
+		return
+	}
 
-	// 先转换所有值
+	av.mu.Lock()
+	defer av.mu.Unlock()
+
+	av.grow(len(values))
 	for _, value := range values {
-		var jsonValue IValue
-		switch v := value.(type) {
-		case IValue:
-			jsonValue = v
-		case nil:
-			jsonValue = &scalarValue{valueType: NullValueType, rawData: nil}
-		default:
-			var err error
-			jsonValue, err = factory.CreateFromRaw(value)
-			if err != nil {
-				return err
-			}
+		if value == nil {
+			value = &scalarValue{valueType: NullValueType, rawData: nil}
 		}
-		jsonValues = append(jsonValues, jsonValue)
+		av.data = append(av.data, value)
+	}
+	av.touch()
+}
+
+// Grow 预留至少n个元素的空间，之后的n次追加不会再扩容
+// Grow reserves room for at least n more elements, so the next n appends do not reallocate
+func (av *arrayValue) Grow(n int) {
+	if n <= 0 {
+		return
 	}
 
-	// 批量追加
 	av.mu.Lock()
 	defer av.mu.Unlock()
 
-	av.data = append(av.data, jsonValues...)
-	return nil
+	av.grow(n)
+}
+
+// grow 确保还能容纳n个元素，调用方需持有写锁
+// grow ensures room for n more elements, the caller must hold the write lock
+func (av *arrayValue) grow(n int) {
+	if cap(av.data)-len(av.data) >= n {
+		return
+	}
+	data := make([]IValue, len(av.data), len(av.data)+n)
+	copy(data, av.data)
+	av.data = data
 }
 
 // IndexOf 查找值的索引
@@ -408,6 +447,7 @@ func (av *arrayValue) Reverse() {
 	for i := 0; i < length/2; i++ {
 		av.data[i], av.data[length-1-i] = av.data[length-1-i], av.data[i]
 	}
+	av.touch()
 }
 
 // Filter 过滤数组元素
@@ -483,3 +523,27 @@ func (av *arrayValue) AsObject() IObject {
 func (av *arrayValue) AsArray() IArray {
 	return av
 }
+
+// AsInt64OK 数组类型总是返回false
+// AsInt64OK always returns false for array type
+func (av *arrayValue) AsInt64OK() (int64, bool) {
+	return 0, false
+}
+
+// AsFloat64OK 数组类型总是返回false
+// AsFloat64OK always returns false for array type
+func (av *arrayValue) AsFloat64OK() (float64, bool) {
+	return 0, false
+}
+
+// AsStringOK 数组类型总是返回false
+// AsStringOK always returns false for array type
+func (av *arrayValue) AsStringOK() (string, bool) {
+	return "", false
+}
+
+// AsBoolOK 数组类型总是返回false
+// AsBoolOK always returns false for array type
+func (av *arrayValue) AsBoolOK() (bool, bool) {
+	return false, false
+}
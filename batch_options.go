@@ -0,0 +1,149 @@
+package xyJson
+
+import (
+	"fmt"
+)
+
+// BatchOptions 批量操作选项
+// BatchOptions holds batch operation options
+type BatchOptions struct {
+	// StopOnError 遇到第一个错误时是否停止，之后的路径不再读取或写入
+	// StopOnError indicates whether to stop at the first error, leaving the remaining paths unread or unwritten
+	StopOnError bool
+}
+
+// DefaultBatchOptions 返回默认批量操作选项（处理所有条目）
+// DefaultBatchOptions returns default batch options (every entry is processed)
+func DefaultBatchOptions() *BatchOptions {
+	return &BatchOptions{
+		StopOnError: false,
+	}
+}
+
+// MultiError 批量操作中失败的所有条目
+// MultiError holds every entry that failed in a batch operation
+type MultiError struct {
+	failures []BatchResult
+	total    int
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (me *MultiError) Error() string {
+	first := me.failures[0]
+	if len(me.failures) == 1 {
+		return fmt.Sprintf("batch operation on %s failed: %s", first.Path, first.Error.Error())
+	}
+	return fmt.Sprintf("%d of %d batch operations failed, first on %s: %s",
+		len(me.failures), me.total, first.Path, first.Error.Error())
+}
+
+// Unwrap 返回所有失败条目的错误，便于errors.Is和errors.As
+// Unwrap returns the errors of every failed entry for errors.Is and errors.As
+func (me *MultiError) Unwrap() []error {
+	errs := make([]error, len(me.failures))
+	for i, failure := range me.failures {
+		errs[i] = failure.Error
+	}
+	return errs
+}
+
+// Failed 按输入顺序返回失败的条目；SetBatchWithOptions的条目Value为nil
+// Failed returns the failed entries in input order; entries from SetBatchWithOptions have a nil Value
+func (me *MultiError) Failed() []BatchResult {
+	failed := make([]BatchResult, len(me.failures))
+	copy(failed, me.failures)
+	return failed
+}
+
+// newMultiError 收集失败的条目，没有失败时返回nil
+// newMultiError collects the failed entries, returning nil when nothing failed
+func newMultiError(results []BatchResult, total int) error {
+	var failures []BatchResult
+	for _, result := range results {
+		if result.Error != nil {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &MultiError{failures: failures, total: total}
+}
+
+// GetBatchWithOptions 使用指定选项批量获取多个路径的值，并将失败汇总为*MultiError
+// GetBatchWithOptions retrieves values for multiple paths with the specified options, aggregating failures
+// into a *MultiError
+//
+// 结果顺序与输入路径顺序一致。StopOnError时结果在第一个失败的路径处截止，包含该路径
+// Results are in the order of the input paths. With StopOnError the results end at the first failing path,
+// which is included
+//
+// 示例 Example:
+//
+//	results, err := xyJson.GetBatchWithOptions(root, paths, &xyJson.BatchOptions{StopOnError: true})
+//	var multiErr *xyJson.MultiError
+//	if errors.As(err, &multiErr) {
+//		for _, failure := range multiErr.Failed() {
+//			log.Printf("%s: %v", failure.Path, failure.Error)
+//		}
+//	}
+func GetBatchWithOptions(root IValue, paths []string, options *BatchOptions) ([]BatchResult, error) {
+	if options == nil {
+		options = DefaultBatchOptions()
+	}
+
+	results := make([]BatchResult, 0, len(paths))
+	for _, path := range paths {
+		value, err := Get(root, path)
+		results = append(results, BatchResult{
+			Path:  path,
+			Value: value,
+			Error: err,
+		})
+		if err != nil && options.StopOnError {
+			break
+		}
+	}
+	return results, newMultiError(results, len(paths))
+}
+
+// SetBatchWithOptions 使用指定选项批量设置多个路径的值，并将失败汇总为*MultiError
+// SetBatchWithOptions sets values for multiple paths with the specified options, aggregating failures into a
+// *MultiError
+//
+// 结果顺序与输入操作顺序一致。StopOnError时在第一个失败的操作处停止，之前的操作已经生效，之后的操作不会执行
+// Results are in the order of the input operations. With StopOnError it stops at the first failing
+// operation; earlier operations have already taken effect and later ones are not applied
+//
+// 示例 Example:
+//
+//	results, err := xyJson.SetBatchWithOptions(root, operations, nil)
+//	if err != nil {
+//		return fmt.Errorf("update config: %w", err)
+//	}
+func SetBatchWithOptions(root IValue, operations []BatchSetOperation, options *BatchOptions) ([]BatchSetResult, error) {
+	if options == nil {
+		options = DefaultBatchOptions()
+	}
+
+	results := make([]BatchSetResult, 0, len(operations))
+	var failures []BatchResult
+	for _, op := range operations {
+		err := Set(root, op.Path, op.Value)
+		results = append(results, BatchSetResult{
+			Path:  op.Path,
+			Error: err,
+		})
+		if err != nil {
+			failures = append(failures, BatchResult{Path: op.Path, Error: err})
+			if options.StopOnError {
+				break
+			}
+		}
+	}
+	if len(failures) == 0 {
+		return results, nil
+	}
+	return results, &MultiError{failures: failures, total: len(operations)}
+}
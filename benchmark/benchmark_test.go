@@ -392,6 +392,44 @@ func BenchmarkConcurrentOperations(b *testing.B) {
 	})
 }
 
+// BenchmarkSharedDocument 共享文档条带锁与单一锁的对比基准测试，四分之一的操作写入$.users，其余读取$.settings
+// BenchmarkSharedDocument compares striped locking of a shared document with a single lock, a quarter of the
+// operations write $.users and the rest read $.settings
+func BenchmarkSharedDocument(b *testing.B) {
+	for _, stripes := range []int{1, 64} {
+		b.Run(fmt.Sprintf("Stripes%d", stripes), func(b *testing.B) {
+			doc, err := xyJson.NewSharedDocumentWithOptions(
+				xyJson.MustParseString(`{"users": {"count": 0, "names": ["a", "b", "c"]}, "settings": {"theme": "dark"}}`),
+				&xyJson.SharedDocumentOptions{Stripes: stripes},
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					var err error
+					if i%4 == 0 {
+						err = doc.Update("$.users", func(users xyJson.IValue) error {
+							count := users.(xyJson.IObject).Get("count").AsInt()
+							return users.(xyJson.IObject).Set("count", count+1)
+						})
+					} else {
+						err = doc.Read("$.settings.theme", func(theme xyJson.IValue) error {
+							_ = theme.String()
+							return nil
+						})
+					}
+					if err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
 // BenchmarkObjectPool 对象池基准测试
 // BenchmarkObjectPool benchmarks object pool
 func BenchmarkObjectPool(b *testing.B) {
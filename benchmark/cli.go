@@ -0,0 +1,60 @@
+package benchmark
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Main 解析命令行参数并运行基准，供xyjson-bench和第三方对比模块共用
+// Main parses command-line arguments and runs the benchmarks, shared by xyjson-bench and the third-party module
+//
+// 文本表格总是写到stdout；指定-out时JSON报告写入该文件
+// The text table is always written to stdout; the JSON report is written to the -out file when given
+func Main(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("xyjson-bench", flag.ContinueOnError)
+	corpusDir := flags.String("corpus", "", "directory containing twitter.json, canada.json and citm_catalog.json")
+	out := flags.String("out", "", "write the machine-readable JSON report to this file ('-' for stdout)")
+	minDuration := flags.Duration("min-duration", time.Second, "minimum measuring time of each benchmark")
+	only := flags.String("contenders", "", "comma-separated contenders to run (default all: "+strings.Join(ContenderNames(), ",")+")")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config := Config{
+		CorpusDir:   *corpusDir,
+		MinDuration: *minDuration,
+	}
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			config.Contenders = append(config.Contenders, strings.TrimSpace(name))
+		}
+	}
+
+	report, err := Run(config)
+	if err != nil {
+		return err
+	}
+
+	switch *out {
+	case "":
+		return report.WriteText(stdout)
+	case "-":
+		return report.WriteJSON(stdout)
+	}
+
+	if err := report.WriteText(stdout); err != nil {
+		return err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,158 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// CorpusSeed 生成替代语料使用的固定随机种子
+// CorpusSeed is the fixed random seed used to generate stand-in corpora
+const CorpusSeed = 20240101
+
+// GenerateCorpora 生成与标准语料结构相似的替代语料
+// GenerateCorpora generates stand-in corpora shaped like the standard corpora
+//
+// 生成结果只依赖CorpusSeed，因此不同机器上的报告可以直接比较；
+// 发布数据时应优先使用真实的标准语料文件
+// The output depends only on CorpusSeed so reports from different machines are comparable;
+// prefer the real standard corpus files when publishing numbers
+func GenerateCorpora() []Corpus {
+	rng := rand.New(rand.NewSource(CorpusSeed))
+
+	generators := map[string]func(*rand.Rand) interface{}{
+		"twitter.json":      generateTwitter,
+		"canada.json":       generateCanada,
+		"citm_catalog.json": generateCITM,
+	}
+
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	corpora := make([]Corpus, 0, len(names))
+	for _, name := range names {
+		data, err := json.Marshal(generators[name](rng))
+		if err != nil {
+			panic(err)
+		}
+		corpora = append(corpora, Corpus{
+			Name:      name,
+			Data:      data,
+			QueryKeys: StandardCorpora[name],
+			Synthetic: true,
+		})
+	}
+	return corpora
+}
+
+// generateTwitter 生成以字符串和嵌套对象为主的推文列表
+// generateTwitter generates a list of tweets dominated by strings and nested objects
+func generateTwitter(rng *rand.Rand) interface{} {
+	words := []string{"json", "golang", "性能", "benchmark", "日本語", "🚀", "stream", "parser", "\"quoted\"", "line\nbreak"}
+
+	statuses := make([]interface{}, 100)
+	for i := range statuses {
+		text := ""
+		for j := 0; j < 8+rng.Intn(12); j++ {
+			text += words[rng.Intn(len(words))] + " "
+		}
+		statuses[i] = map[string]interface{}{
+			"id":         rng.Int63(),
+			"id_str":     fmt.Sprint(rng.Int63()),
+			"text":       text,
+			"truncated":  rng.Intn(2) == 0,
+			"created_at": "Sun Aug 31 00:29:15 +0000 2014",
+			"entities": map[string]interface{}{
+				"hashtags":      []interface{}{map[string]interface{}{"text": words[rng.Intn(len(words))], "indices": []int{rng.Intn(50), rng.Intn(50) + 50}}},
+				"user_mentions": []interface{}{},
+			},
+			"user": map[string]interface{}{
+				"id":              rng.Int63(),
+				"screen_name":     fmt.Sprintf("user_%d", rng.Intn(100000)),
+				"name":            words[rng.Intn(len(words))],
+				"followers_count": rng.Intn(100000),
+				"verified":        rng.Intn(10) == 0,
+				"description":     text,
+				"profile_image":   nil,
+			},
+			"retweet_count":  rng.Intn(1000),
+			"favorite_count": rng.Intn(1000),
+			"lang":           "en",
+		}
+	}
+	return map[string]interface{}{
+		"statuses":        statuses,
+		"search_metadata": map[string]interface{}{"count": len(statuses), "max_id": rng.Int63()},
+	}
+}
+
+// generateCanada 生成以浮点数数组为主的地理数据
+// generateCanada generates geographic data dominated by arrays of floats
+func generateCanada(rng *rand.Rand) interface{} {
+	polygons := make([]interface{}, 40)
+	for i := range polygons {
+		ring := make([]interface{}, 250)
+		for j := range ring {
+			ring[j] = []float64{-141 + rng.Float64()*90, 41 + rng.Float64()*42}
+		}
+		polygons[i] = []interface{}{ring}
+	}
+	return map[string]interface{}{
+		"type": "FeatureCollection",
+		"features": []interface{}{
+			map[string]interface{}{
+				"type":       "Feature",
+				"properties": map[string]interface{}{"name": "Canada"},
+				"geometry": map[string]interface{}{
+					"type":        "Polygon",
+					"coordinates": polygons,
+				},
+			},
+		},
+	}
+}
+
+// generateCITM 生成以整数、null和重复结构为主的票务目录
+// generateCITM generates a ticketing catalog dominated by integers, nulls and repeated structures
+func generateCITM(rng *rand.Rand) interface{} {
+	events := make(map[string]interface{}, 150)
+	for i := 0; i < 150; i++ {
+		id := 138586341 + i
+		events[fmt.Sprint(id)] = map[string]interface{}{
+			"id":          id,
+			"name":        fmt.Sprintf("Event %d", i),
+			"description": nil,
+			"logo":        nil,
+			"subTopicIds": []int{337184269 + rng.Intn(10), 337184283 + rng.Intn(10)},
+			"topicIds":    []int{324846099 + rng.Intn(5)},
+		}
+	}
+
+	performances := make([]interface{}, 200)
+	for i := range performances {
+		prices := make([]interface{}, 3+rng.Intn(4))
+		for j := range prices {
+			prices[j] = map[string]interface{}{"amount": 9000 + rng.Intn(100)*500, "audienceSubCategoryId": 337100890, "seatCategoryId": 338937295 + j}
+		}
+		performances[i] = map[string]interface{}{
+			"id":             339887544 + i,
+			"eventId":        138586341 + rng.Intn(150),
+			"logo":           nil,
+			"name":           nil,
+			"prices":         prices,
+			"seatCategories": []interface{}{map[string]interface{}{"areas": []interface{}{map[string]interface{}{"areaId": 205705999 + rng.Intn(20), "blockIds": []int{}}}, "seatCategoryId": 338937295}},
+			"start":          1372701600000 + int64(i)*86400000,
+			"venueCode":      "PLEYEL_PLEYEL",
+		}
+	}
+
+	return map[string]interface{}{
+		"events":       events,
+		"performances": performances,
+		"venueNames":   map[string]interface{}{"PLEYEL_PLEYEL": "Salle Pleyel"},
+	}
+}
@@ -0,0 +1,414 @@
+package benchmark
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+// 基准测试操作名称
+// Benchmark operation names
+const (
+	// OpUnmarshal 解析整个文档
+	// OpUnmarshal parses the whole document
+	OpUnmarshal = "unmarshal"
+	// OpMarshal 序列化已解析的文档
+	// OpMarshal serializes the parsed document
+	OpMarshal = "marshal"
+	// OpQuery 从原始字节中取出单个值
+	// OpQuery extracts a single value from the raw bytes
+	OpQuery = "query"
+)
+
+// Contender 参与对比的JSON库适配器
+// Contender is an adapter for a JSON library taking part in the comparison
+//
+// 未实现的操作保持为nil，对应的基准会被跳过
+// Operations that are not implemented stay nil and the corresponding benchmarks are skipped
+type Contender struct {
+	// Name 库名称
+	// Name is the library name
+	Name string
+
+	// Unmarshal 解析文档，返回值会传给Marshal
+	// Unmarshal parses a document, the returned value is passed to Marshal
+	Unmarshal func(data []byte) (interface{}, error)
+
+	// Marshal 序列化Unmarshal返回的值
+	// Marshal serializes a value returned by Unmarshal
+	Marshal func(v interface{}) ([]byte, error)
+
+	// Query 按键名和索引序列从原始字节中取值
+	// Query gets a value from the raw bytes by a sequence of keys and indices
+	Query func(data []byte, keys []interface{}) error
+}
+
+// Corpus 基准测试语料
+// Corpus is a benchmark corpus
+type Corpus struct {
+	// Name 语料名称
+	// Name is the corpus name
+	Name string
+
+	// Data 原始JSON数据
+	// Data is the raw JSON data
+	Data []byte
+
+	// QueryKeys 查询基准使用的键名和索引序列
+	// QueryKeys is the sequence of keys and indices used by the query benchmark
+	QueryKeys []interface{}
+
+	// Synthetic 是否为生成的替代语料
+	// Synthetic indicates whether the corpus is a generated stand-in
+	Synthetic bool
+}
+
+// StandardCorpora 标准语料文件名及其查询路径
+// StandardCorpora maps standard corpus file names to their query keys
+var StandardCorpora = map[string][]interface{}{
+	"twitter.json":      {"statuses", 10, "user", "screen_name"},
+	"canada.json":       {"features", 0, "geometry", "type"},
+	"citm_catalog.json": {"performances", 3, "id"},
+}
+
+// Config 基准测试配置
+// Config represents benchmark configuration
+type Config struct {
+	// CorpusDir 标准语料目录，缺失的语料用固定种子生成的数据替代
+	// CorpusDir is the standard corpus directory, missing corpora are replaced by data generated from a fixed seed
+	CorpusDir string
+
+	// MinDuration 每个基准的最短测量时间
+	// MinDuration is the minimum measuring time of each benchmark
+	MinDuration time.Duration
+
+	// Contenders 参与对比的库，为空时使用所有已注册的库
+	// Contenders are the libraries to compare, all registered libraries are used when empty
+	Contenders []string
+}
+
+// Result 单个基准的结果
+// Result is the result of a single benchmark
+type Result struct {
+	Contender   string  `json:"contender"`
+	Corpus      string  `json:"corpus"`
+	Operation   string  `json:"operation"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	MBPerSec    float64 `json:"mb_per_sec"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// CorpusInfo 报告中记录的语料信息
+// CorpusInfo is the corpus information recorded in the report
+type CorpusInfo struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	SHA256    string `json:"sha256"`
+	Synthetic bool   `json:"synthetic"`
+}
+
+// Report 机器可读的基准报告
+// Report is a machine-readable benchmark report
+type Report struct {
+	Timestamp   time.Time    `json:"timestamp"`
+	GoVersion   string       `json:"go_version"`
+	GOOS        string       `json:"goos"`
+	GOARCH      string       `json:"goarch"`
+	NumCPU      int          `json:"num_cpu"`
+	GOMAXPROCS  int          `json:"gomaxprocs"`
+	MinDuration string       `json:"min_duration"`
+	Corpora     []CorpusInfo `json:"corpora"`
+	Results     []Result     `json:"results"`
+}
+
+var (
+	// contenders 已注册的库
+	// contenders holds the registered libraries
+	contenders     = make(map[string]Contender)
+	contenderMutex sync.RWMutex
+)
+
+func init() {
+	RegisterContender(Contender{
+		Name: "xyJson",
+		Unmarshal: func(data []byte) (interface{}, error) {
+			return xyJson.Parse(data)
+		},
+		Marshal: func(v interface{}) ([]byte, error) {
+			return xyJson.Serialize(v.(xyJson.IValue))
+		},
+		Query: func(data []byte, keys []interface{}) error {
+			root, err := xyJson.Parse(data)
+			if err != nil {
+				return err
+			}
+			_, err = xyJson.Get(root, xyJson.BuildPath(keys...))
+			return err
+		},
+	})
+
+	RegisterContender(Contender{
+		Name: "encoding/json",
+		Unmarshal: func(data []byte) (interface{}, error) {
+			var v interface{}
+			err := json.Unmarshal(data, &v)
+			return v, err
+		},
+		Marshal: json.Marshal,
+		Query: func(data []byte, keys []interface{}) error {
+			var v interface{}
+			if err := json.Unmarshal(data, &v); err != nil {
+				return err
+			}
+			return walkKeys(v, keys)
+		},
+	})
+}
+
+// RegisterContender 注册参与对比的库，同名的库会被替换
+// RegisterContender registers a library to compare, replacing one with the same name
+func RegisterContender(c Contender) {
+	contenderMutex.Lock()
+	defer contenderMutex.Unlock()
+	contenders[c.Name] = c
+}
+
+// ContenderNames 返回已注册库的名称，按字母排序
+// ContenderNames returns the names of the registered libraries in alphabetical order
+func ContenderNames() []string {
+	contenderMutex.RLock()
+	defer contenderMutex.RUnlock()
+
+	names := make([]string, 0, len(contenders))
+	for name := range contenders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadCorpora 加载标准语料，缺失的文件用生成的数据替代
+// LoadCorpora loads the standard corpora, replacing missing files with generated data
+func LoadCorpora(dir string) ([]Corpus, error) {
+	generated := GenerateCorpora()
+
+	corpora := make([]Corpus, 0, len(generated))
+	for _, corpus := range generated {
+		if dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, corpus.Name))
+			if err == nil {
+				corpus.Data = data
+				corpus.Synthetic = false
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		corpora = append(corpora, corpus)
+	}
+	return corpora, nil
+}
+
+// Run 运行所有语料和库的对比基准
+// Run runs the comparison benchmarks over all corpora and libraries
+func Run(config Config) (*Report, error) {
+	if config.MinDuration <= 0 {
+		config.MinDuration = time.Second
+	}
+
+	corpora, err := LoadCorpora(config.CorpusDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := config.Contenders
+	if len(names) == 0 {
+		names = ContenderNames()
+	}
+	selected := make([]Contender, 0, len(names))
+	contenderMutex.RLock()
+	for _, name := range names {
+		c, exists := contenders[name]
+		if !exists {
+			contenderMutex.RUnlock()
+			return nil, fmt.Errorf("unknown contender %q, registered: %v", name, ContenderNames())
+		}
+		selected = append(selected, c)
+	}
+	contenderMutex.RUnlock()
+
+	report := &Report{
+		Timestamp:   time.Now().UTC(),
+		GoVersion:   runtime.Version(),
+		GOOS:        runtime.GOOS,
+		GOARCH:      runtime.GOARCH,
+		NumCPU:      runtime.NumCPU(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		MinDuration: config.MinDuration.String(),
+	}
+
+	for _, corpus := range corpora {
+		sum := sha256.Sum256(corpus.Data)
+		report.Corpora = append(report.Corpora, CorpusInfo{
+			Name:      corpus.Name,
+			Size:      len(corpus.Data),
+			SHA256:    hex.EncodeToString(sum[:]),
+			Synthetic: corpus.Synthetic,
+		})
+
+		for _, c := range selected {
+			report.Results = append(report.Results, runContender(c, corpus, config.MinDuration)...)
+		}
+	}
+	return report, nil
+}
+
+// runContender 对单个库运行一个语料上的所有操作
+// runContender runs all operations of a single library over one corpus
+func runContender(c Contender, corpus Corpus, minDuration time.Duration) []Result {
+	var results []Result
+	size := len(corpus.Data)
+
+	if c.Unmarshal != nil {
+		results = append(results, measure(c.Name, corpus.Name, OpUnmarshal, size, minDuration, func() error {
+			_, err := c.Unmarshal(corpus.Data)
+			return err
+		}))
+	}
+
+	if c.Unmarshal != nil && c.Marshal != nil {
+		parsed, err := c.Unmarshal(corpus.Data)
+		if err != nil {
+			results = append(results, Result{Contender: c.Name, Corpus: corpus.Name, Operation: OpMarshal, Error: err.Error()})
+		} else {
+			results = append(results, measure(c.Name, corpus.Name, OpMarshal, size, minDuration, func() error {
+				_, err := c.Marshal(parsed)
+				return err
+			}))
+		}
+	}
+
+	if c.Query != nil && len(corpus.QueryKeys) > 0 {
+		results = append(results, measure(c.Name, corpus.Name, OpQuery, size, minDuration, func() error {
+			return c.Query(corpus.Data, corpus.QueryKeys)
+		}))
+	}
+	return results
+}
+
+// measure 重复执行fn直到达到最短时间，并统计耗时和内存分配
+// measure runs fn repeatedly until the minimum duration is reached, recording time and allocations
+func measure(contender, corpus, operation string, size int, minDuration time.Duration, fn func() error) Result {
+	result := Result{Contender: contender, Corpus: corpus, Operation: operation}
+
+	// 预热并检查操作是否可用
+	// Warm up and check that the operation works
+	if err := fn(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	n := 1
+	for {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if err := fn(); err != nil {
+				result.Error = err.Error()
+				return result
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed >= minDuration || n >= 1<<30 {
+			result.Iterations = n
+			result.NsPerOp = float64(elapsed.Nanoseconds()) / float64(n)
+			result.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(n)
+			result.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(n)
+			if result.NsPerOp > 0 {
+				result.MBPerSec = float64(size) / result.NsPerOp * 1e9 / (1 << 20)
+			}
+			return result
+		}
+
+		// 按testing包的方式预测下一轮次数
+		// Predict the next round count the way the testing package does
+		next := n * 2
+		if elapsed > 0 {
+			predicted := int(int64(n) * minDuration.Nanoseconds() / elapsed.Nanoseconds() * 6 / 5)
+			if predicted > next {
+				next = predicted
+			}
+		}
+		if next > 100*n {
+			next = 100 * n
+		}
+		n = next
+	}
+}
+
+// WriteJSON 以JSON格式输出报告
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText 以表格形式输出报告
+// WriteText writes the report as a table
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "corpus\toperation\tcontender\tns/op\tMB/s\tallocs/op\tB/op\t\n")
+	for _, result := range r.Results {
+		if result.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\t%s\terror: %s\t\t\t\t\n", result.Corpus, result.Operation, result.Contender, result.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.0f\t%.2f\t%.1f\t%.0f\t\n",
+			result.Corpus, result.Operation, result.Contender,
+			result.NsPerOp, result.MBPerSec, result.AllocsPerOp, result.BytesPerOp)
+	}
+	return tw.Flush()
+}
+
+// walkKeys 在encoding/json解析出的值中按键名和索引取值
+// walkKeys walks keys and indices through a value decoded by encoding/json
+func walkKeys(v interface{}, keys []interface{}) error {
+	for _, key := range keys {
+		switch k := key.(type) {
+		case string:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("expected object at key %q", k)
+			}
+			if v, ok = obj[k]; !ok {
+				return fmt.Errorf("key %q not found", k)
+			}
+		case int:
+			arr, ok := v.([]interface{})
+			if !ok || k < 0 || k >= len(arr) {
+				return fmt.Errorf("index %d out of range", k)
+			}
+			v = arr[k]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestGenerateCorporaDeterministic 测试替代语料的生成结果是固定的
+// TestGenerateCorporaDeterministic tests that the stand-in corpora are reproducible
+func TestGenerateCorporaDeterministic(t *testing.T) {
+	first, second := GenerateCorpora(), GenerateCorpora()
+	if len(first) != len(StandardCorpora) {
+		t.Fatalf("expected %d corpora, got %d", len(StandardCorpora), len(first))
+	}
+	for i := range first {
+		if !bytes.Equal(first[i].Data, second[i].Data) {
+			t.Errorf("corpus %s is not deterministic", first[i].Name)
+		}
+		if !json.Valid(first[i].Data) {
+			t.Errorf("corpus %s is not valid JSON", first[i].Name)
+		}
+	}
+}
+
+// TestRunReport 测试运行基准并输出报告
+// TestRunReport tests running the benchmarks and writing the report
+func TestRunReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "citm_catalog.json"), []byte(`{"performances": [{}, {}, {}, {"id": 1}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Config{CorpusDir: dir, MinDuration: time.Millisecond, Contenders: []string{"xyJson", "encoding/json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != len(StandardCorpora)*2*3 {
+		t.Fatalf("unexpected result count %d", len(report.Results))
+	}
+	for _, result := range report.Results {
+		if result.Error != "" || result.Iterations == 0 || result.NsPerOp <= 0 {
+			t.Errorf("bad result: %+v", result)
+		}
+	}
+	for _, corpus := range report.Corpora {
+		if corpus.Synthetic == (corpus.Name == "citm_catalog.json") {
+			t.Errorf("unexpected synthetic flag for %s", corpus.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Results) != len(report.Results) {
+		t.Errorf("report did not round-trip")
+	}
+
+	if _, err := Run(Config{Contenders: []string{"missing"}}); err == nil {
+		t.Error("expected error for unknown contender")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/ihuem/xyJson/benchmark/thirdparty
+
+go 1.21
+
+require (
+	github.com/bytedance/sonic v1.11.6
+	github.com/ihuem/xyJson v0.0.0
+	github.com/json-iterator/go v1.1.12
+	github.com/tidwall/gjson v1.17.1
+)
+
+replace github.com/ihuem/xyJson => ../..
@@ -0,0 +1,98 @@
+// Command thirdparty 运行xyJson与encoding/json、jsoniter、gjson和sonic的对比基准
+// Command thirdparty runs comparison benchmarks of xyJson against encoding/json, jsoniter, gjson and sonic
+//
+// 第三方库位于独立模块中，主模块不会因此增加依赖。首次使用前运行go mod tidy生成go.sum
+// The third-party libraries live in a separate module so the main module gains no dependencies.
+// Run go mod tidy once to create go.sum before first use
+//
+// 用法 Usage:
+//
+//	cd benchmark/thirdparty
+//	go mod tidy
+//	go run . -corpus ../testdata -out report.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"github.com/ihuem/xyJson/benchmark"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	jsoniterAPI := jsoniter.ConfigCompatibleWithStandardLibrary
+	benchmark.RegisterContender(benchmark.Contender{
+		Name: "jsoniter",
+		Unmarshal: func(data []byte) (interface{}, error) {
+			var v interface{}
+			err := jsoniterAPI.Unmarshal(data, &v)
+			return v, err
+		},
+		Marshal: jsoniterAPI.Marshal,
+		Query: func(data []byte, keys []interface{}) error {
+			if value := jsoniterAPI.Get(data, keys...); value.LastError() != nil {
+				return value.LastError()
+			}
+			return nil
+		},
+	})
+
+	benchmark.RegisterContender(benchmark.Contender{
+		Name: "sonic",
+		Unmarshal: func(data []byte) (interface{}, error) {
+			var v interface{}
+			err := sonic.Unmarshal(data, &v)
+			return v, err
+		},
+		Marshal: sonic.Marshal,
+		Query: func(data []byte, keys []interface{}) error {
+			_, err := sonic.Get(data, keys...)
+			return err
+		},
+	})
+
+	// gjson只做查询，不构建文档树
+	// gjson only queries and does not build a document tree
+	benchmark.RegisterContender(benchmark.Contender{
+		Name: "gjson",
+		Query: func(data []byte, keys []interface{}) error {
+			if !gjson.GetBytes(data, gjsonPath(keys)).Exists() {
+				return fmt.Errorf("path %v not found", keys)
+			}
+			return nil
+		},
+	})
+}
+
+// gjsonPath 将键名和索引序列转换为gjson路径语法
+// gjsonPath converts a sequence of keys and indices into gjson path syntax
+func gjsonPath(keys []interface{}) string {
+	path := ""
+	for i, key := range keys {
+		if i > 0 {
+			path += "."
+		}
+		switch k := key.(type) {
+		case string:
+			path += gjson.Escape(k)
+		case int:
+			path += strconv.Itoa(k)
+		}
+	}
+	return path
+}
+
+func main() {
+	if err := benchmark.Main(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "thirdparty:", err)
+		if err == flag.ErrHelp {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
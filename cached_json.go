@@ -0,0 +1,122 @@
+package xyJson
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// mutationEpoch 每次建立序列化缓存时递增的全局纪元，容器被修改时记录当前纪元加一
+// mutationEpoch is the global epoch advanced whenever a serialization cache is taken, containers record the
+// current epoch plus one when they are modified
+var mutationEpoch atomic.Uint64
+
+// jsonCache 容器的修改戳以及依赖它的序列化缓存和快照视图，嵌入到对象和数组中
+// jsonCache holds the modification stamp of a container and the serialization cache and snapshot view relying
+// on it, embedded in objects and arrays
+//
+// 修改戳总是大于修改之前建立的任何缓存的纪元，因此缓存有效当且仅当树中没有容器的修改戳大于缓存的纪元。
+// 修改只需一次原子读和一次写自己的字段，不会在并发解析之间争用同一个计数器
+// A modification stamp is always greater than the epoch of any cache taken before the modification, so a
+// cache is valid exactly when no container in the tree has a stamp greater than the cache's epoch.
+// Modifying costs one atomic load and one store to the container's own field, concurrent parses do not
+// contend on a shared counter
+type jsonCache struct {
+	stamp    atomic.Uint64
+	snapshot atomic.Pointer[jsonSnapshot]
+
+	// view 容器上一次冻结的拷贝，供Snapshot共享未修改的子树
+	// view is the copy the container was last frozen into, letting Snapshot share unmodified subtrees
+	view atomic.Pointer[frozenView]
+}
+
+// jsonSnapshot 一次序列化的结果
+// jsonSnapshot is the result of one serialization
+type jsonSnapshot struct {
+	data       []byte
+	epoch      uint64
+	serializer ISerializer
+
+	// etag 按需计算的实体标签
+	// etag is the entity tag, computed on demand
+	etagOnce sync.Once
+	etag     string
+}
+
+// touch 记录容器被修改
+// touch records that the container was modified
+func (c *jsonCache) touch() {
+	c.stamp.Store(mutationEpoch.Load() + 1)
+}
+
+// CachedJSON 返回对象序列化后的字节，未被修改时复用上一次的结果，见IValue.CachedJSON
+// CachedJSON returns the serialized bytes of the object, reusing the previous result while unmodified, see
+// IValue.CachedJSON
+func (ov *objectValue) CachedJSON() ([]byte, error) {
+	return cachedJSON(ov, &ov.jsonCache)
+}
+
+// CachedJSON 返回数组序列化后的字节，未被修改时复用上一次的结果，见IValue.CachedJSON
+// CachedJSON returns the serialized bytes of the array, reusing the previous result while unmodified, see
+// IValue.CachedJSON
+func (av *arrayValue) CachedJSON() ([]byte, error) {
+	return cachedJSON(av, &av.jsonCache)
+}
+
+// CachedJSON 标量序列化的开销很小，直接序列化而不缓存
+// CachedJSON serializes scalars directly without caching, as they are cheap to serialize
+func (sv *scalarValue) CachedJSON() ([]byte, error) {
+	return defaultSerializer().Serialize(sv)
+}
+
+// cachedJSON 验证并返回cache中的序列化结果，失效时重新序列化
+// cachedJSON validates and returns the serialization in cache, serializing again when it is stale
+func cachedJSON(root IValue, cache *jsonCache) ([]byte, error) {
+	serializer := defaultSerializer()
+	if snapshot := cache.snapshot.Load(); snapshot != nil && snapshot.serializer == serializer {
+		if !modifiedSince(root, snapshot.epoch, 0) {
+			return snapshot.data, nil
+		}
+	}
+
+	epoch := mutationEpoch.Add(1)
+	data, err := serializer.Serialize(root)
+	if err != nil {
+		return nil, err
+	}
+	cache.snapshot.Store(&jsonSnapshot{data: data, epoch: epoch, serializer: serializer})
+	return data, nil
+}
+
+// modifiedSince 检查树中是否有容器在纪元epoch之后被修改，遍历不排序也不分配
+// modifiedSince checks whether any container in the tree was modified after epoch, walking without sorting or
+// allocating
+func modifiedSince(value IValue, epoch uint64, depth int) bool {
+	if depth > DefaultMaxDepth {
+		return true
+	}
+
+	modified := false
+	switch v := value.(type) {
+	case *objectValue:
+		if v.stamp.Load() > epoch {
+			return true
+		}
+		v.mu.RLock()
+		v.each(func(_ string, child IValue) {
+			modified = modified || modifiedSince(child, epoch, depth+1)
+		})
+		v.mu.RUnlock()
+	case *arrayValue:
+		if v.stamp.Load() > epoch {
+			return true
+		}
+		v.mu.RLock()
+		for _, child := range v.data {
+			if modified = modifiedSince(child, epoch, depth+1); modified {
+				break
+			}
+		}
+		v.mu.RUnlock()
+	}
+	return modified
+}
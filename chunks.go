@@ -0,0 +1,136 @@
+package xyJson
+
+import (
+	"strconv"
+)
+
+// ChunkOptions 分块处理数组的选项
+// ChunkOptions holds the options of processing arrays in chunks
+type ChunkOptions struct {
+	// StartIndex 开始处理的下标，用于从检查点恢复
+	// StartIndex is the index processing starts at, for resuming from a checkpoint
+	StartIndex int
+
+	// Checkpoint 每个分块处理成功后调用，next为下一个未处理元素的下标；返回错误时停止处理
+	// Checkpoint is called after each chunk is processed successfully, next being the index of the next
+	// unprocessed element; processing stops when it returns an error
+	Checkpoint func(next int) error
+
+	// ReleaseProcessed 分块及其检查点成功后将源数组中已处理的元素替换为null，使其子树可以被回收
+	// ReleaseProcessed replaces the processed elements in the source array with null after each chunk and its
+	// checkpoint succeed, so their subtrees can be reclaimed
+	ReleaseProcessed bool
+}
+
+// DefaultChunkOptions 返回默认分块选项
+// DefaultChunkOptions returns the default chunk options
+func DefaultChunkOptions() *ChunkOptions {
+	return &ChunkOptions{
+		StartIndex:       0,
+		Checkpoint:       nil,
+		ReleaseProcessed: false,
+	}
+}
+
+// ChunkError 分块处理失败的错误，Start是失败分块的第一个下标，可作为StartIndex恢复
+// ChunkError is the error of a failed chunk, Start is the first index of the failed chunk and can be used as
+// StartIndex to resume
+type ChunkError struct {
+	Start int
+	End   int
+	Err   error
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (e *ChunkError) Error() string {
+	return "chunk [" + strconv.Itoa(e.Start) + ", " + strconv.Itoa(e.End) + "): " + e.Err.Error()
+}
+
+// Unwrap 返回底层错误
+// Unwrap returns the underlying error
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessArrayChunks 以固定大小的窗口依次处理path处的数组，见ProcessArrayChunksWithOptions
+// ProcessArrayChunks processes the array at path in fixed-size windows, see ProcessArrayChunksWithOptions
+//
+// 示例 Example:
+//
+//	err := xyJson.ProcessArrayChunks(doc, "$.items", 1000, func(chunk xyJson.IArray) error {
+//		return store.InsertBatch(chunk)
+//	})
+func ProcessArrayChunks(root IValue, path string, chunkSize int, fn func(chunk IArray) error) error {
+	return ProcessArrayChunksWithOptions(root, path, chunkSize, fn, nil)
+}
+
+// ProcessArrayChunksWithOptions 以chunkSize个元素的窗口依次处理path处的数组，支持检查点恢复和增量释放
+// ProcessArrayChunksWithOptions processes the array at path in windows of chunkSize elements, with
+// checkpoint resumption and incremental release
+//
+// 每个分块是引用源数组元素的新数组，最后一个分块可能较小。每次取分块时重新读取数组长度，
+// 因此fn追加的元素也会被处理。fn或Checkpoint返回错误时停止并返回*ChunkError，
+// 其Start可作为StartIndex从失败的分块重新开始。options为nil时使用默认选项
+// Each chunk is a new array referencing the elements of the source array, the last one may be smaller. The
+// array length is read again for every chunk, so elements appended by fn are processed as well. Processing
+// stops at the first error of fn or Checkpoint and returns a *ChunkError whose Start can be used as
+// StartIndex to restart from the failed chunk. nil options means the default options
+//
+// 示例 Example:
+//
+//	err := xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 1000, handle, &xyJson.ChunkOptions{
+//		StartIndex:       saved,
+//		Checkpoint:       func(next int) error { return saveCheckpoint(next) },
+//		ReleaseProcessed: true,
+//	})
+func ProcessArrayChunksWithOptions(root IValue, path string, chunkSize int, fn func(chunk IArray) error, options *ChunkOptions) error {
+	if fn == nil {
+		return NewNullPointerError("chunk function cannot be nil")
+	}
+	if chunkSize <= 0 {
+		return NewInvalidOperationError("process array chunks", "chunkSize must be positive")
+	}
+	if options == nil {
+		options = DefaultChunkOptions()
+	}
+	if options.StartIndex < 0 {
+		return NewIndexOutOfRangeError(options.StartIndex, 0, path)
+	}
+
+	value, err := defaultPathQuery().SelectOne(root, path)
+	if err != nil {
+		return err
+	}
+	arr, ok := value.(IArray)
+	if !ok {
+		return NewTypeMismatchError(ArrayValueType, value.Type(), path)
+	}
+
+	for start := options.StartIndex; start < arr.Length(); {
+		end := min(start+chunkSize, arr.Length())
+		items := make([]IValue, end-start)
+		for i := range items {
+			items[i] = arr.Get(start + i)
+		}
+		chunk := defaultFactory().CreateArray()
+		chunk.AppendAll(items...)
+
+		if err := fn(chunk); err != nil {
+			return &ChunkError{Start: start, End: end, Err: err}
+		}
+		if options.Checkpoint != nil {
+			if err := options.Checkpoint(end); err != nil {
+				return &ChunkError{Start: start, End: end, Err: err}
+			}
+		}
+		if options.ReleaseProcessed {
+			null := defaultFactory().CreateNull()
+			for i := start; i < end; i++ {
+				arr.Set(i, null)
+			}
+		}
+		start = end
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+// Command xyjson-bench 运行xyJson与其它JSON库的对比基准并输出报告
+// Command xyjson-bench runs comparison benchmarks of xyJson against other JSON libraries and writes a report
+//
+// 用法 Usage:
+//
+//	go run github.com/ihuem/xyJson/cmd/xyjson-bench -corpus ./testdata -out report.json
+//
+// 本命令只包含xyJson和encoding/json；与jsoniter、gjson和sonic的对比位于benchmark/thirdparty模块
+// This command only includes xyJson and encoding/json; comparisons with jsoniter, gjson and sonic live in the
+// benchmark/thirdparty module
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ihuem/xyJson/benchmark"
+)
+
+func main() {
+	if err := benchmark.Main(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "xyjson-bench:", err)
+		if err == flag.ErrHelp {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
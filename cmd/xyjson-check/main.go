@@ -0,0 +1,105 @@
+// Command xyjson-check 在构建时校验Go源码中MustLiteral的JSON字面量
+// Command xyjson-check validates the JSON literals passed to MustLiteral in Go source at build time
+//
+// 用法 Usage:
+//
+//	//go:generate go run github.com/ihuem/xyJson/cmd/xyjson-check .
+//
+// 参数为目录或文件，默认为当前目录；以"/..."结尾的目录会递归检查。包括_test.go文件，
+// 发现无效字面量时逐个输出并以状态码1退出
+// Arguments are directories or files, the current directory by default; directories ending in "/..." are
+// checked recursively. _test.go files are included, and invalid literals are printed one by one with exit
+// status 1
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+func main() {
+	flag.Parse()
+	targets := flag.Args()
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+
+	files, err := goFiles(targets)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "xyjson-check:", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, file := range files {
+		problems, err := check(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "xyjson-check:", err)
+			os.Exit(1)
+		}
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// check 校验一个文件
+// check checks one file
+func check(file string) ([]*xyJson.LiteralError, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return xyJson.CheckLiterals(file, src)
+}
+
+// goFiles 展开参数为要检查的Go文件
+// goFiles expands the arguments into the Go files to check
+func goFiles(targets []string) ([]string, error) {
+	var files []string
+	for _, target := range targets {
+		recursive := strings.HasSuffix(target, "/...")
+		if recursive {
+			target = strings.TrimSuffix(target, "/...")
+		}
+
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, target)
+			continue
+		}
+
+		err = filepath.WalkDir(target, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != target && (!recursive || strings.HasPrefix(entry.Name(), ".") || entry.Name() == "vendor" || entry.Name() == "testdata") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".go") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,74 @@
+// Command xyjson-gen 根据示例JSON或JSON Schema生成Go结构体和类型化访问函数
+// Command xyjson-gen generates Go structs and typed accessors from a sample JSON document or JSON Schema
+//
+// 用法 Usage:
+//
+//	//go:generate go run github.com/ihuem/xyJson/cmd/xyjson-gen -in user.json -out user_gen.go -pkg model -type User
+//
+// 当输入文档包含"$schema"键或指定-schema时，输入被视为JSON Schema
+// The input is treated as JSON Schema when it contains a "$schema" key or -schema is given
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+func main() {
+	in := flag.String("in", "", "sample JSON or JSON Schema file (required)")
+	out := flag.String("out", "", "output Go file (default stdout)")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated code")
+	typeName := flag.String("type", "Root", "name of the root struct type")
+	schema := flag.Bool("schema", false, "treat the input as JSON Schema")
+	noAccessors := flag.Bool("no-accessors", false, "do not generate typed accessor functions")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out, *pkg, *typeName, *schema, !*noAccessors); err != nil {
+		fmt.Fprintln(os.Stderr, "xyjson-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run 执行代码生成
+// run performs code generation
+func run(in, out, pkg, typeName string, schema, accessors bool) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	root, err := xyJson.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	options := &xyJson.CodeGenOptions{
+		PackageName: pkg,
+		TypeName:    typeName,
+		Accessors:   accessors,
+	}
+
+	var src []byte
+	if schema || xyJson.Exists(root, "$['$schema']") {
+		src, err = xyJson.GenerateCodeFromSchema(root, options)
+	} else {
+		src, err = xyJson.GenerateCode(root, options)
+	}
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,549 @@
+package xyJson
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CodeGenOptions 代码生成选项
+// CodeGenOptions represents code generation options
+type CodeGenOptions struct {
+	// PackageName 生成代码的包名
+	// PackageName is the package name of the generated code
+	PackageName string
+
+	// TypeName 根结构体类型名
+	// TypeName is the name of the root struct type
+	TypeName string
+
+	// Accessors 是否生成基于预编译路径的类型化访问函数
+	// Accessors indicates whether to generate typed accessors backed by compiled paths
+	Accessors bool
+}
+
+// DefaultCodeGenOptions 返回默认代码生成选项
+// DefaultCodeGenOptions returns default code generation options
+func DefaultCodeGenOptions() *CodeGenOptions {
+	return &CodeGenOptions{
+		PackageName: "model",
+		TypeName:    "Root",
+		Accessors:   true,
+	}
+}
+
+// codeGenerator 代码生成器状态
+// codeGenerator holds code generator state
+type codeGenerator struct {
+	options   *CodeGenOptions
+	structs   []*codeGenStruct
+	typeNames map[string]int
+	accessors []codeGenAccessor
+	funcNames map[string]int
+}
+
+// codeGenStruct 待生成的结构体
+// codeGenStruct is a struct to be generated
+type codeGenStruct struct {
+	Name   string
+	Fields []codeGenField
+}
+
+// codeGenField 待生成的结构体字段
+// codeGenField is a struct field to be generated
+type codeGenField struct {
+	Name    string
+	Type    string
+	JSONKey string
+}
+
+// codeGenAccessor 待生成的访问函数
+// codeGenAccessor is an accessor function to be generated
+type codeGenAccessor struct {
+	FuncName string
+	VarName  string
+	Path     string
+	GoType   string
+}
+
+// GenerateCode 根据示例JSON生成Go结构体和类型化访问函数
+// GenerateCode generates Go structs and typed accessors from a sample JSON document
+//
+// 参数 Parameters:
+//   - sample: 示例JSON文档，根必须是对象 / Sample JSON document, root must be an object
+//   - options: 生成选项，nil时使用默认值 / Generation options, defaults are used when nil
+//
+// 返回值 Returns:
+//   - []byte: 经过gofmt格式化的Go源码 / Go source code formatted by gofmt
+//   - error: 生成错误 / Generation error
+//
+// 注意 Notes:
+//   - 数组元素类型由所有元素合并推断：对象取字段并集，整数和浮点数合并为float64，类型冲突时为interface{}
+//     / Array element types are inferred by merging every element: objects take the union of their fields,
+//     integers and floats merge into float64, conflicting types become interface{}
+//   - 访问函数只为不经过数组的标量路径生成 / Accessors are only generated for scalar paths that do not cross arrays
+//
+// 示例 Example:
+//
+//	sample := xyJson.MustParseString(`{"user":{"name":"Alice","age":30}}`)
+//	src, err := xyJson.GenerateCode(sample, &xyJson.CodeGenOptions{PackageName: "api", TypeName: "Payload", Accessors: true})
+//	// src 包含 type Payload struct{...} 和 func GetUserName(root xyJson.IValue) string
+//	// src contains type Payload struct{...} and func GetUserName(root xyJson.IValue) string
+func GenerateCode(sample IValue, options *CodeGenOptions) ([]byte, error) {
+	if sample == nil {
+		return nil, NewNullPointerError("code generation sample cannot be nil")
+	}
+	obj, ok := sample.(IObject)
+	if !ok {
+		return nil, NewTypeMismatchError(ObjectValueType, sample.Type(), "$")
+	}
+
+	opts := DefaultCodeGenOptions()
+	if options != nil {
+		copied := *options
+		opts = &copied
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = DefaultCodeGenOptions().PackageName
+	}
+	if opts.TypeName == "" {
+		opts.TypeName = DefaultCodeGenOptions().TypeName
+	}
+
+	gen := &codeGenerator{
+		options:   opts,
+		typeNames: make(map[string]int),
+		funcNames: make(map[string]int),
+	}
+	gen.structType(opts.TypeName, obj, "$", nil, true)
+
+	return gen.render()
+}
+
+// GenerateCodeFromSchema 根据JSON Schema生成Go结构体和类型化访问函数
+// GenerateCodeFromSchema generates Go structs and typed accessors from a JSON Schema document
+//
+// Schema先被转换为示例文档再交给GenerateCode处理，支持GenerateSchema输出的type、properties、items、
+// additionalProperties、anyOf以及#/definitions引用
+// The schema is converted into a sample document and passed to GenerateCode; type, properties, items,
+// additionalProperties, anyOf and #/definitions references as emitted by GenerateSchema are supported
+//
+// 调用方没有指定TypeName（options为nil或TypeName为空）时，根类型名取自Schema的title
+// When the caller does not set TypeName (options is nil or TypeName is empty) the root type is named after the
+// title of the schema
+func GenerateCodeFromSchema(schema IValue, options *CodeGenOptions) ([]byte, error) {
+	if schema == nil {
+		return nil, NewNullPointerError("code generation schema cannot be nil")
+	}
+	sample, err := sampleFromSchema(schema, schema, map[string]bool{"#": true}, 0)
+	if err != nil {
+		return nil, err
+	}
+	opts := DefaultCodeGenOptions()
+	if options != nil {
+		copied := *options
+		opts = &copied
+	}
+	if options == nil || options.TypeName == "" {
+		if title, ok := TryGetString(schema, "$.title"); ok {
+			opts.TypeName = title
+		}
+	}
+	return GenerateCode(sample, opts)
+}
+
+// structType 为对象生成结构体类型并返回类型名
+// structType generates a struct type for an object and returns the type name
+func (g *codeGenerator) structType(name string, obj IObject, path string, prefix []string, accessible bool) string {
+	typeName := g.uniqueName(g.typeNames, exportedName(name))
+	st := &codeGenStruct{Name: typeName}
+	g.structs = append(g.structs, st)
+
+	fieldNames := make(map[string]int)
+	for _, key := range obj.Keys() {
+		value := obj.Get(key)
+		fieldName := g.uniqueName(fieldNames, exportedName(key))
+		childPath := appendPathKey(path, key)
+		childPrefix := append(append([]string(nil), prefix...), exportedName(key))
+
+		goType := g.goType(key, value, childPath, childPrefix, accessible)
+		st.Fields = append(st.Fields, codeGenField{
+			Name:    fieldName,
+			Type:    goType,
+			JSONKey: key,
+		})
+
+		if accessible && g.options.Accessors && isCodeGenScalar(goType) {
+			funcName := g.uniqueName(g.funcNames, "Get"+strings.Join(childPrefix, ""))
+			g.accessors = append(g.accessors, codeGenAccessor{
+				FuncName: funcName,
+				VarName:  lowerFirst(strings.TrimPrefix(funcName, "Get")) + "Path",
+				Path:     childPath,
+				GoType:   goType,
+			})
+		}
+	}
+
+	return typeName
+}
+
+// goType 推断JSON值对应的Go类型
+// goType infers the Go type for a JSON value
+func (g *codeGenerator) goType(key string, value IValue, path string, prefix []string, accessible bool) string {
+	if value == nil {
+		return "interface{}"
+	}
+
+	switch value.Type() {
+	case StringValueType:
+		return "string"
+	case BoolValueType:
+		return "bool"
+	case NumberValueType:
+		if _, isInt := value.Raw().(int64); isInt {
+			return "int64"
+		}
+		return "float64"
+	case ObjectValueType:
+		obj := value.(IObject)
+		if obj.Size() == 0 {
+			return "map[string]interface{}"
+		}
+		return "*" + g.structType(key, obj, path, prefix, accessible)
+	case ArrayValueType:
+		arr := value.(IArray)
+		if arr.Length() == 0 {
+			return "[]interface{}"
+		}
+		// 元素类型由所有元素合并得出；数组内部的路径无法映射为单值访问函数
+		// The element type is merged from every element; paths inside arrays cannot be mapped to single-value
+		// accessors
+		elem := arr.Get(0)
+		for i := 1; i < arr.Length(); i++ {
+			elem = mergeCodeGenSamples(elem, arr.Get(i))
+		}
+		elemType := g.goType(singularName(key), elem, path+"[0]", prefix, false)
+		return "[]" + elemType
+	default:
+		return "interface{}"
+	}
+}
+
+// codeGenConflict 类型冲突的合并结果，与null不同，它不会在之后的合并中被其它类型取代
+// codeGenConflict is the merge result of conflicting types, unlike null it is not replaced by other types in
+// later merges
+var codeGenConflict IValue = &scalarValue{valueType: NullValueType}
+
+// mergeCodeGenSamples 合并两个示例值，使结果的类型能够容纳两者；类型冲突时返回codeGenConflict，生成interface{}
+// mergeCodeGenSamples merges two sample values so the type of the result holds both; codeGenConflict is
+// returned for conflicting types, generating interface{}
+func mergeCodeGenSamples(a, b IValue) IValue {
+	if a == codeGenConflict || b == codeGenConflict {
+		return codeGenConflict
+	}
+	if a.IsNull() {
+		return b
+	}
+	if b.IsNull() {
+		return a
+	}
+	if a.Type() != b.Type() {
+		return codeGenConflict
+	}
+
+	switch a.Type() {
+	case NumberValueType:
+		if _, isInt := a.Raw().(int64); isInt {
+			return b
+		}
+		return a
+	case ObjectValueType:
+		left, right := a.(IObject), b.(IObject)
+		merged := CreateObject()
+		for _, key := range left.Keys() {
+			value := left.Get(key)
+			if right.Has(key) {
+				value = mergeCodeGenSamples(value, right.Get(key))
+			}
+			merged.Set(key, value)
+		}
+		for _, key := range right.Keys() {
+			if !left.Has(key) {
+				merged.Set(key, right.Get(key))
+			}
+		}
+		return merged
+	case ArrayValueType:
+		var elem IValue
+		found := false
+		for _, arr := range []IArray{a.(IArray), b.(IArray)} {
+			for i := 0; i < arr.Length(); i++ {
+				if !found {
+					elem, found = arr.Get(i), true
+					continue
+				}
+				elem = mergeCodeGenSamples(elem, arr.Get(i))
+			}
+		}
+		merged := CreateArray()
+		if found {
+			merged.Append(elem)
+		}
+		return merged
+	default:
+		return a
+	}
+}
+
+// uniqueName 生成不冲突的名称
+// uniqueName generates a non-conflicting name
+func (g *codeGenerator) uniqueName(used map[string]int, name string) string {
+	count := used[name]
+	used[name] = count + 1
+	if count == 0 {
+		return name
+	}
+	return name + strconv.Itoa(count+1)
+}
+
+// render 输出Go源码
+// render renders Go source code
+func (g *codeGenerator) render() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by xyJson code generator. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.options.PackageName)
+	if len(g.accessors) > 0 {
+		buf.WriteString("import xyJson \"github.com/ihuem/xyJson\"\n\n")
+	}
+
+	for _, st := range g.structs {
+		fmt.Fprintf(&buf, "// %s is generated from the sample JSON document.\n", st.Name)
+		fmt.Fprintf(&buf, "type %s struct {\n", st.Name)
+		for _, field := range st.Fields {
+			fmt.Fprintf(&buf, "\t%s %s `json:%s`\n", field.Name, field.Type, strconv.Quote(field.JSONKey))
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	if len(g.accessors) > 0 {
+		sort.SliceStable(g.accessors, func(i, j int) bool {
+			return g.accessors[i].FuncName < g.accessors[j].FuncName
+		})
+
+		buf.WriteString("var (\n")
+		for _, acc := range g.accessors {
+			fmt.Fprintf(&buf, "\t%s = mustCompilePath(%s)\n", acc.VarName, strconv.Quote(acc.Path))
+		}
+		buf.WriteString(")\n\n")
+
+		buf.WriteString("func mustCompilePath(path string) *xyJson.CompiledPath {\n")
+		buf.WriteString("\tcompiled, err := xyJson.CompilePath(path)\n")
+		buf.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+		buf.WriteString("\treturn compiled\n}\n\n")
+
+		for _, acc := range g.accessors {
+			fmt.Fprintf(&buf, "// %s returns the value at %s, or the zero value when it is missing.\n", acc.FuncName, acc.Path)
+			fmt.Fprintf(&buf, "func %s(root xyJson.IValue) %s {\n", acc.FuncName, acc.GoType)
+			fmt.Fprintf(&buf, "\tvalue, err := %s.Query(root)\n", acc.VarName)
+			fmt.Fprintf(&buf, "\tif err != nil {\n\t\treturn %s\n\t}\n", codeGenZero(acc.GoType))
+			fmt.Fprintf(&buf, "\treturn value.%s()\n}\n\n", codeGenConverter(acc.GoType))
+		}
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, NewInvalidOperationError("generate code", err.Error())
+	}
+	return formatted, nil
+}
+
+// sampleFromSchema 将JSON Schema转换为示例文档
+// sampleFromSchema converts a JSON Schema into a sample document
+//
+// visiting记录正在展开的引用，递归引用会被展开为空对象
+// visiting records references being expanded, recursive references become empty objects
+func sampleFromSchema(root, schema IValue, visiting map[string]bool, depth int) (IValue, error) {
+	if depth > MaxStructDepth {
+		return nil, NewMaxDepthExceededError(MaxStructDepth)
+	}
+	obj, ok := schema.(IObject)
+	if !ok {
+		return CreateNull(), nil
+	}
+
+	if ref, ok := TryGetString(obj, "$['$ref']"); ok {
+		if visiting[ref] {
+			return CreateObject(), nil
+		}
+		target, err := resolveSchemaRef(root, ref)
+		if err != nil {
+			return nil, err
+		}
+		visiting[ref] = true
+		defer delete(visiting, ref)
+		return sampleFromSchema(root, target, visiting, depth+1)
+	}
+
+	if variants, ok := obj.Get("anyOf").(IArray); ok {
+		for i := 0; i < variants.Length(); i++ {
+			if GetStringWithDefault(variants.Get(i), "$.type", "") != "null" {
+				return sampleFromSchema(root, variants.Get(i), visiting, depth+1)
+			}
+		}
+		return CreateNull(), nil
+	}
+
+	switch GetStringWithDefault(obj, "$.type", "") {
+	case "string":
+		return CreateString(""), nil
+	case "integer":
+		return CreateNumber(0), nil
+	case "number":
+		return CreateNumber(0.5), nil
+	case "boolean":
+		return CreateBool(false), nil
+	case "array":
+		arr := CreateArray()
+		if items := obj.Get("items"); items != nil {
+			item, err := sampleFromSchema(root, items, visiting, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arr.Append(item)
+		}
+		return arr, nil
+	case "object", "":
+		result := CreateObject()
+		if props, ok := obj.Get("properties").(IObject); ok {
+			for _, key := range props.Keys() {
+				child, err := sampleFromSchema(root, props.Get(key), visiting, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				result.Set(key, child)
+			}
+		}
+		return result, nil
+	default:
+		return CreateNull(), nil
+	}
+}
+
+// resolveSchemaRef 解析Schema内部引用
+// resolveSchemaRef resolves an internal schema reference
+func resolveSchemaRef(root IValue, ref string) (IValue, error) {
+	if ref == "#" {
+		return root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, NewInvalidOperationError("resolve schema reference", "only local references are supported: "+ref)
+	}
+	current := root
+	for _, part := range strings.Split(ref[2:], "/") {
+		obj, ok := current.(IObject)
+		if !ok {
+			return nil, NewPathNotFoundError(ref)
+		}
+		current = obj.Get(part)
+		if current == nil {
+			return nil, NewPathNotFoundError(ref)
+		}
+	}
+	return current, nil
+}
+
+// exportedName 将JSON键转换为导出的Go标识符
+// exportedName converts a JSON key into an exported Go identifier
+func exportedName(key string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			sb.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+
+	name := sb.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// lowerFirst 将首字母转换为小写
+// lowerFirst lowercases the first letter
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// singularName 推断数组元素的单数名称
+// singularName infers the singular name of array elements
+func singularName(key string) string {
+	switch {
+	case strings.HasSuffix(key, "ies") && len(key) > 3:
+		return key[:len(key)-3] + "y"
+	case strings.HasSuffix(key, "s") && len(key) > 1:
+		return key[:len(key)-1]
+	default:
+		return key + "Item"
+	}
+}
+
+// isCodeGenScalar 检查Go类型是否为可生成访问函数的标量类型
+// isCodeGenScalar checks if a Go type is a scalar type that gets an accessor
+func isCodeGenScalar(goType string) bool {
+	switch goType {
+	case "string", "bool", "int64", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// codeGenZero 返回Go类型的零值字面量
+// codeGenZero returns the zero value literal of a Go type
+func codeGenZero(goType string) string {
+	switch goType {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	default:
+		return "0"
+	}
+}
+
+// codeGenConverter 返回Go类型对应的IValue转换方法
+// codeGenConverter returns the IValue conversion method for a Go type
+func codeGenConverter(goType string) string {
+	switch goType {
+	case "string":
+		return "AsString"
+	case "bool":
+		return "AsBool"
+	case "int64":
+		return "AsInt64"
+	default:
+		return "AsFloat64"
+	}
+}
@@ -0,0 +1,309 @@
+package xyJson
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoercionError Coerce无法转换的所有值
+// CoercionError holds every value Coerce could not convert
+type CoercionError struct {
+	// Failures 每个无法转换的值对应一个错误，Path为该值的JSONPath
+	// Failures has one error per value that could not be converted, Path is the JSONPath of the value
+	Failures []*JSONError
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (ce *CoercionError) Error() string {
+	if len(ce.Failures) == 1 {
+		return ce.Failures[0].Error()
+	}
+	return fmt.Sprintf("%d values could not be coerced, first: %s", len(ce.Failures), ce.Failures[0].Error())
+}
+
+// Unwrap 返回所有失败，便于errors.Is和errors.As
+// Unwrap returns every failure for errors.Is and errors.As
+func (ce *CoercionError) Unwrap() []error {
+	errs := make([]error, len(ce.Failures))
+	for i, failure := range ce.Failures {
+		errs[i] = failure
+	}
+	return errs
+}
+
+// Coerce 复制文档并将兼容的值转换为Schema声明的类型，用于规范化来自表格和表单的数据
+// Coerce copies the document and converts compatible values to the types declared by the schema, normalizing
+// data coming from spreadsheets and forms
+//
+// 支持的转换：数字字符串转为number或integer，"true"/"false"等转为boolean，0和1转为boolean，
+// 数字和布尔值转为string，空字符串转为null。format为date-time或date的string字段接受Unix时间戳
+// （秒，绝对值不小于1e12时按毫秒）并转换为RFC 3339的UTC时间。allOf的分支依次应用，anyOf和oneOf
+// 选择第一个转换后能通过校验的分支
+// Supported conversions: numeric strings to number or integer, "true"/"false" and similar to boolean, 0 and 1
+// to boolean, numbers and booleans to string, and the empty string to null. string fields with format
+// date-time or date accept Unix timestamps (seconds, milliseconds when the absolute value is at least 1e12)
+// and convert them to RFC 3339 UTC times. allOf branches are applied in turn, anyOf and oneOf pick the first
+// branch that validates after conversion
+//
+// root不会被修改。无法转换的值保持原样，此时仍返回转换后的文档以及列出每个失败路径的*CoercionError。
+// Coerce只做类型转换，结果仍需用Validate校验其它约束
+// root is not modified. Values that cannot be converted are kept as is; the converted document is still
+// returned together with a *CoercionError listing every failing path. Coerce only converts types, the result
+// still needs Validate for the other constraints
+//
+// 示例 Example:
+//
+//	normalized, err := xyJson.Coerce(row, schema)
+//	var coercionErr *xyJson.CoercionError
+//	if errors.As(err, &coercionErr) {
+//		for _, failure := range coercionErr.Failures {
+//			fmt.Println(failure.Path, failure.Message)
+//		}
+//	}
+func Coerce(root IValue, schema *Schema) (IValue, error) {
+	if root == nil {
+		return nil, NewNullPointerError("value cannot be nil")
+	}
+	if schema == nil {
+		return nil, NewNullPointerError("schema cannot be nil")
+	}
+
+	c := &coercer{factory: defaultFactory()}
+	result := c.coerce(root.Clone(), schema, "$", 0)
+	if len(c.failures) > 0 {
+		return result, &CoercionError{Failures: c.failures}
+	}
+	return result, nil
+}
+
+// coercer 在文档副本上按Schema转换值并收集失败
+// coercer converts values of a document copy according to a schema and collects failures
+type coercer struct {
+	factory  IValueFactory
+	failures []*JSONError
+}
+
+// coerceTargets 转换时尝试目标类型的顺序
+// coerceTargets is the order in which target types are tried during conversion
+var coerceTargets = []schemaType{schemaInteger, schemaNumber, schemaBoolean, schemaString, schemaNull}
+
+// coerce 转换值及其子节点，返回转换后的值
+// coerce converts a value and its children, returning the converted value
+func (c *coercer) coerce(value IValue, schema *Schema, path string, depth int) IValue {
+	s := schema.resolved()
+	if depth > MaxNestingDepth {
+		c.failures = append(c.failures, NewMaxDepthExceededError(MaxNestingDepth).WithPath(path))
+		return value
+	}
+	if s.reject {
+		return value
+	}
+
+	for _, branch := range s.allOf {
+		value = c.coerce(value, branch, path, depth+1)
+	}
+	value = c.convert(value, s, path)
+
+	switch value.Type() {
+	case ObjectValueType:
+		obj := value.AsObject()
+		keys := obj.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, childSchema := obj.Get(key), s.propertySchema(key)
+			if child == nil || childSchema == nil {
+				continue
+			}
+			if converted := c.coerce(child, childSchema, appendPathKey(path, key), depth+1); converted != child {
+				obj.Set(key, converted)
+			}
+		}
+	case ArrayValueType:
+		arr := value.AsArray()
+		for i := 0; i < arr.Length(); i++ {
+			child, childSchema := arr.Get(i), s.itemSchema(i)
+			if child == nil || childSchema == nil {
+				continue
+			}
+			if converted := c.coerce(child, childSchema, path+"["+strconv.Itoa(i)+"]", depth+1); converted != child {
+				arr.Set(i, converted)
+			}
+		}
+	}
+
+	if s.anyOf != nil {
+		value = c.coerceAlternatives(value, s.anyOf, path, depth)
+	}
+	if s.oneOf != nil {
+		value = c.coerceAlternatives(value, s.oneOf, path, depth)
+	}
+	return value
+}
+
+// coerceAlternatives 为anyOf或oneOf选择分支：已通过校验的分支优先，其次是转换后能通过校验的分支，
+// 最后是转换没有失败的分支
+// coerceAlternatives picks a branch for anyOf or oneOf: a branch that already validates first, then one that
+// validates after conversion, then one whose conversion did not fail
+func (c *coercer) coerceAlternatives(value IValue, branches []*Schema, path string, depth int) IValue {
+	for _, branch := range branches {
+		if branch.validate(value, path, depth+1) == nil {
+			return value
+		}
+	}
+
+	var fallback IValue
+	for _, branch := range branches {
+		trial := &coercer{factory: c.factory}
+		candidate := trial.coerce(value.Clone(), branch, path, depth+1)
+		if len(trial.failures) > 0 {
+			continue
+		}
+		if branch.validate(candidate, path, depth+1) == nil {
+			return candidate
+		}
+		if fallback == nil {
+			fallback = candidate
+		}
+	}
+	if fallback != nil {
+		return fallback
+	}
+
+	c.failures = append(c.failures, NewJSONError(ErrTypeMismatch, "value cannot be coerced to any alternative schema", nil).WithPath(path))
+	return value
+}
+
+// convert 将值转换为Schema的type关键字允许的类型
+// convert converts a value to a type allowed by the type keyword of the schema
+func (c *coercer) convert(value IValue, s *Schema, path string) IValue {
+	if s.types == 0 || s.checkType(value, path) == nil {
+		return value
+	}
+
+	for _, target := range coerceTargets {
+		if s.types&target == 0 {
+			continue
+		}
+		if converted, ok := c.convertTo(value, target, s.format); ok {
+			return converted
+		}
+	}
+
+	err := NewTypeMismatchError(schemaTypeToValueType(s.types), value.Type(), path)
+	err.WithContext(fmt.Sprintf("cannot coerce %s to %s", describeCoerced(value), s.types))
+	c.failures = append(c.failures, err)
+	return value
+}
+
+// convertTo 尝试将标量转换为指定的Schema类型
+// convertTo tries to convert a scalar to the given schema type
+func (c *coercer) convertTo(value IValue, target schemaType, format string) (IValue, bool) {
+	switch value.Type() {
+	case StringValueType:
+		str := strings.TrimSpace(value.AsString())
+		switch target {
+		case schemaInteger:
+			if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+				return c.factory.CreateNumber(i), true
+			}
+			if f, err := strconv.ParseFloat(str, 64); err == nil && isWholeNumber(f) && math.Abs(f) < 1<<63 {
+				return c.factory.CreateNumber(int64(f)), true
+			}
+		case schemaNumber:
+			if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+				return c.factory.CreateNumber(i), true
+			}
+			if f, err := strconv.ParseFloat(str, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return c.factory.CreateNumber(f), true
+			}
+		case schemaBoolean:
+			if b, err := strconv.ParseBool(str); err == nil {
+				return c.factory.CreateBool(b), true
+			}
+		case schemaNull:
+			if str == "" {
+				return c.factory.CreateNull(), true
+			}
+		}
+
+	case NumberValueType:
+		f := value.AsFloat64()
+		switch target {
+		case schemaBoolean:
+			if f == 0 || f == 1 {
+				return c.factory.CreateBool(f == 1), true
+			}
+		case schemaString:
+			if format == "date-time" || format == "date" {
+				return c.factory.CreateString(epochToRFC3339(f, format)), true
+			}
+			return c.factory.CreateString(value.String()), true
+		}
+
+	case BoolValueType:
+		switch target {
+		case schemaInteger, schemaNumber:
+			if value.AsBool() {
+				return c.factory.CreateNumber(int64(1)), true
+			}
+			return c.factory.CreateNumber(int64(0)), true
+		case schemaString:
+			return c.factory.CreateString(value.String()), true
+		}
+	}
+	return nil, false
+}
+
+// epochToRFC3339 将Unix时间戳转换为RFC 3339的UTC时间，绝对值不小于1e12时按毫秒处理
+// epochToRFC3339 converts a Unix timestamp to an RFC 3339 UTC time, treating it as milliseconds when its
+// absolute value is at least 1e12
+func epochToRFC3339(epoch float64, format string) string {
+	var t time.Time
+	if math.Abs(epoch) >= 1e12 {
+		t = time.UnixMilli(int64(epoch))
+	} else {
+		sec, frac := math.Modf(epoch)
+		t = time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+	}
+	if format == "date" {
+		return t.UTC().Format(time.DateOnly)
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// schemaTypeToValueType 返回类型位集中第一个类型对应的值类型，用于错误信息
+// schemaTypeToValueType returns the value type of the first type in the set, used in error messages
+func schemaTypeToValueType(types schemaType) ValueType {
+	switch {
+	case types&(schemaInteger|schemaNumber) != 0:
+		return NumberValueType
+	case types&schemaBoolean != 0:
+		return BoolValueType
+	case types&schemaString != 0:
+		return StringValueType
+	case types&schemaNull != 0:
+		return NullValueType
+	case types&schemaObject != 0:
+		return ObjectValueType
+	default:
+		return ArrayValueType
+	}
+}
+
+// describeCoerced 返回用于错误信息的值描述
+// describeCoerced returns a description of a value for error messages
+func describeCoerced(value IValue) string {
+	switch value.Type() {
+	case StringValueType:
+		return "string " + strconv.Quote(value.AsString())
+	case ObjectValueType, ArrayValueType, NullValueType:
+		return value.Type().String()
+	default:
+		return value.Type().String() + " " + value.String()
+	}
+}
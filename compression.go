@@ -0,0 +1,240 @@
+package xyJson
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// 支持的内容编码名称
+// Supported content encoding names
+const (
+	// EncodingAuto 根据魔数自动检测编码
+	// EncodingAuto detects the encoding from magic bytes
+	EncodingAuto = ""
+	// EncodingIdentity 未压缩
+	// EncodingIdentity means uncompressed
+	EncodingIdentity = "identity"
+	// EncodingGzip gzip压缩
+	// EncodingGzip is gzip compression
+	EncodingGzip = "gzip"
+	// EncodingZstd zstd压缩
+	// EncodingZstd is zstd compression
+	EncodingZstd = "zstd"
+)
+
+// CompressionCodec 压缩编解码器
+// CompressionCodec is a compression codec
+//
+// 标准库不包含zstd实现，因此zstd只内置了魔数检测；
+// 需要读写zstd时可以用第三方实现注册编解码器
+// The standard library has no zstd implementation, so only zstd magic-byte detection is built in;
+// register a codec backed by a third-party implementation to read and write zstd
+//
+// 示例 Example:
+//
+//	xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{
+//		Name:  xyJson.EncodingZstd,
+//		Magic: []byte{0x28, 0xb5, 0x2f, 0xfd},
+//		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+//			d, err := zstd.NewReader(r)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return d.IOReadCloser(), nil
+//		},
+//		NewWriter: func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) },
+//	})
+type CompressionCodec struct {
+	// Name 编码名称，与Content-Encoding一致
+	// Name is the encoding name, matching Content-Encoding
+	Name string
+	// Magic 用于自动检测的魔数前缀
+	// Magic is the magic-byte prefix used for auto detection
+	Magic []byte
+	// NewReader 创建解压读取器
+	// NewReader creates a decompressing reader
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+	// NewWriter 创建压缩写入器
+	// NewWriter creates a compressing writer
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	// compressionCodecs 已注册的编解码器
+	// compressionCodecs holds the registered codecs
+	compressionCodecs = map[string]CompressionCodec{
+		EncodingGzip: {
+			Name:  EncodingGzip,
+			Magic: []byte{0x1f, 0x8b},
+			NewReader: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+		},
+		EncodingZstd: {
+			Name:  EncodingZstd,
+			Magic: []byte{0x28, 0xb5, 0x2f, 0xfd},
+		},
+	}
+	compressionMutex sync.RWMutex
+)
+
+// RegisterCompressionCodec 注册或替换压缩编解码器
+// RegisterCompressionCodec registers or replaces a compression codec
+func RegisterCompressionCodec(codec CompressionCodec) error {
+	name := strings.ToLower(strings.TrimSpace(codec.Name))
+	if name == EncodingAuto || name == EncodingIdentity {
+		return NewInvalidOperationError("register codec", fmt.Sprintf("reserved encoding name '%s'", codec.Name))
+	}
+	if codec.NewReader == nil && codec.NewWriter == nil {
+		return NewNullPointerError("codec must provide NewReader or NewWriter")
+	}
+	codec.Name = name
+
+	compressionMutex.Lock()
+	defer compressionMutex.Unlock()
+	compressionCodecs[name] = codec
+	return nil
+}
+
+// DetectEncoding 根据魔数检测数据的内容编码
+// DetectEncoding detects the content encoding of data from its magic bytes
+//
+// 多个编解码器的魔数都匹配时选择魔数最长的，长度相同时选择名称排序靠前的，因此结果与注册顺序无关
+// When the magic bytes of several codecs match the longest magic wins, ties going to the name sorting first, so
+// the result does not depend on the registration order
+func DetectEncoding(data []byte) string {
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	detected, magicLength := EncodingIdentity, 0
+	for name, codec := range compressionCodecs {
+		if len(codec.Magic) == 0 || len(codec.Magic) < magicLength || !bytes.HasPrefix(data, codec.Magic) {
+			continue
+		}
+		if len(codec.Magic) > magicLength || name < detected {
+			detected, magicLength = name, len(codec.Magic)
+		}
+	}
+	return detected
+}
+
+// maxMagicLength 返回已注册魔数的最大长度，即自动检测需要预读的字节数
+// maxMagicLength returns the greatest length of the registered magic bytes, the number of bytes auto detection
+// needs to peek
+func maxMagicLength() int {
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	length := 0
+	for _, codec := range compressionCodecs {
+		length = max(length, len(codec.Magic))
+	}
+	return length
+}
+
+// ParseCompressed 从可能被压缩的读取器中解析JSON
+// ParseCompressed parses JSON from a possibly compressed reader
+//
+// encoding为EncodingAuto时根据魔数检测编码，未识别的数据按未压缩处理
+// When encoding is EncodingAuto the encoding is detected from magic bytes, unrecognized data is treated as uncompressed
+func ParseCompressed(r io.Reader, encoding string) (IValue, error) {
+	if r == nil {
+		return nil, NewNullPointerError("reader cannot be nil")
+	}
+
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == EncodingAuto {
+		br := bufio.NewReaderSize(r, DefaultParserBufferSize)
+		magic, err := br.Peek(min(maxMagicLength(), br.Size()))
+		if err != nil && err != io.EOF {
+			return nil, NewInvalidJSONError("failed to read input", err)
+		}
+		encoding = DetectEncoding(magic)
+		r = br
+	}
+
+	if encoding != EncodingIdentity {
+		codec, err := lookupCodec(encoding)
+		if err != nil {
+			return nil, err
+		}
+		if codec.NewReader == nil {
+			return nil, NewInvalidOperationError("decompress", fmt.Sprintf("no reader registered for encoding '%s'", encoding))
+		}
+		rc, err := codec.NewReader(r)
+		if err != nil {
+			return nil, NewInvalidJSONError(fmt.Sprintf("failed to open %s stream", encoding), err)
+		}
+		defer rc.Close()
+		r = rc
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, NewInvalidJSONError(fmt.Sprintf("failed to read %s stream", encoding), err)
+	}
+	return Parse(data)
+}
+
+// SerializeCompressed 序列化JSON值并按指定编码压缩写入
+// SerializeCompressed serializes a JSON value and writes it compressed with the given encoding
+//
+// encoding为EncodingAuto时使用gzip
+// gzip is used when encoding is EncodingAuto
+func SerializeCompressed(value IValue, w io.Writer, encoding string) error {
+	if w == nil {
+		return NewNullPointerError("writer cannot be nil")
+	}
+
+	data, err := Serialize(value)
+	if err != nil {
+		return err
+	}
+
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == EncodingAuto {
+		encoding = EncodingGzip
+	}
+	if encoding == EncodingIdentity {
+		_, err := w.Write(data)
+		return err
+	}
+
+	codec, err := lookupCodec(encoding)
+	if err != nil {
+		return err
+	}
+	if codec.NewWriter == nil {
+		return NewInvalidOperationError("compress", fmt.Sprintf("no writer registered for encoding '%s'", encoding))
+	}
+	wc, err := codec.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return err
+	}
+	return wc.Close()
+}
+
+// lookupCodec 查找已注册的编解码器
+// lookupCodec looks up a registered codec
+func lookupCodec(encoding string) (CompressionCodec, error) {
+	compressionMutex.RLock()
+	defer compressionMutex.RUnlock()
+
+	codec, exists := compressionCodecs[encoding]
+	if !exists {
+		return CompressionCodec{}, NewInvalidOperationError("compression", fmt.Sprintf("unsupported encoding '%s'", encoding))
+	}
+	return codec, nil
+}
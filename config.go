@@ -116,6 +116,10 @@ type JSONPathConfig struct {
 
 	// 缓存TTL
 	CacheTTL time.Duration `json:"cache_ttl"`
+
+	// Set超出数组长度的索引时返回错误，而不是用null填充数组
+	// StrictArrayIndex makes Set on an index beyond the array length fail instead of padding with nulls
+	StrictArrayIndex bool `json:"strict_array_index"`
 }
 
 // DefaultConfig 返回默认配置
@@ -156,6 +160,8 @@ func DefaultConfig() *Config {
 			EnableCache:   true,
 			CacheSize:     100,
 			CacheTTL:      30 * time.Minute,
+			// 保持原有的null填充行为
+			StrictArrayIndex: false,
 		},
 	}
 }
@@ -228,10 +234,11 @@ func SetGlobalConfig(config *Config) {
 	if config == nil {
 		return
 	}
+	previous := globalConfig
 	globalConfig = config
 
 	// 应用配置到各个组件
-	applyConfigToComponents(config)
+	applyConfigToComponents(previous, config)
 }
 
 // GetGlobalConfig 获取全局配置
@@ -240,21 +247,26 @@ func GetGlobalConfig() *Config {
 	return globalConfig
 }
 
-// applyConfigToComponents 将配置应用到各个组件
-// applyConfigToComponents applies configuration to components
-func applyConfigToComponents(config *Config) {
+// applyConfigToComponents 将相对previous发生变化的设置应用到各个组件，未变化的设置不会重置组件的当前状态
+// applyConfigToComponents applies the settings that changed relative to previous to the components, unchanged
+// settings do not reset the current state of the components
+func applyConfigToComponents(previous, config *Config) {
 	// 应用性能监控配置
-	if config.Performance.Enabled {
-		EnablePerformanceMonitoring()
-	} else {
-		DisablePerformanceMonitoring()
+	if previous == nil || previous.Performance.Enabled != config.Performance.Enabled {
+		if config.Performance.Enabled {
+			EnablePerformanceMonitoring()
+		} else {
+			DisablePerformanceMonitoring()
+		}
 	}
 
 	// 应用内存分析配置
-	if config.Performance.EnableMemoryProfiling {
-		StartMemoryProfiling()
-	} else {
-		StopMemoryProfiling()
+	if previous == nil || previous.Performance.EnableMemoryProfiling != config.Performance.EnableMemoryProfiling {
+		if config.Performance.EnableMemoryProfiling {
+			StartMemoryProfiling()
+		} else {
+			StopMemoryProfiling()
+		}
 	}
 }
 
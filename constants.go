@@ -1,5 +1,10 @@
 package xyJson
 
+import (
+	"reflect"
+	"strings"
+)
+
 // ValueType JSON值类型枚举
 // ValueType represents the type of a JSON value
 type ValueType int
@@ -46,6 +51,61 @@ func (vt ValueType) String() string {
 	}
 }
 
+// IsContainer 报告值类型是否为对象或数组
+// IsContainer reports whether the value type is object or array
+func (vt ValueType) IsContainer() bool {
+	return vt == ObjectValueType || vt == ArrayValueType
+}
+
+// IsScalar 报告值类型是否为null、字符串、数字或布尔
+// IsScalar reports whether the value type is null, string, number or boolean
+func (vt ValueType) IsScalar() bool {
+	return vt >= NullValueType && vt <= BoolValueType
+}
+
+// ValueTypeFromString 将String返回的名称转换为值类型，不区分大小写，"bool"也表示BoolValueType
+// ValueTypeFromString converts a name returned by String to the value type, case-insensitively; "bool" also
+// denotes BoolValueType
+//
+// 示例 Example:
+//
+//	vt, err := xyJson.ValueTypeFromString("object") // ObjectValueType
+func ValueTypeFromString(name string) (ValueType, error) {
+	lower := strings.ToLower(name)
+	if lower == "bool" {
+		return BoolValueType, nil
+	}
+	for vt := NullValueType; vt <= ArrayValueType; vt++ {
+		if vt.String() == lower {
+			return vt, nil
+		}
+	}
+	return NullValueType, NewJSONError(ErrInvalidOperation, "unknown value type '"+name+"'", nil)
+}
+
+// ValueTypeFromKind 返回Go类型种类对应的JSON值类型：数字种类为number，切片和数组为array，map和结构体为object，
+// 指针、接口和其它种类为null
+// ValueTypeFromKind returns the JSON value type matching a Go kind: numeric kinds are number, slices and arrays
+// are array, maps and structs are object, and pointers, interfaces and other kinds are null
+func ValueTypeFromKind(kind reflect.Kind) ValueType {
+	switch kind {
+	case reflect.String:
+		return StringValueType
+	case reflect.Bool:
+		return BoolValueType
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NumberValueType
+	case reflect.Slice, reflect.Array:
+		return ArrayValueType
+	case reflect.Map, reflect.Struct:
+		return ObjectValueType
+	default:
+		return NullValueType
+	}
+}
+
 // 默认容量常量
 // Default capacity constants
 const (
@@ -55,6 +115,12 @@ const (
 	// DefaultArrayCapacity 默认数组容量
 	// DefaultArrayCapacity is the default capacity for arrays
 	DefaultArrayCapacity = 8
+	// SmallObjectSize 对象内联存储的最大键数，超过后溢出到map
+	// SmallObjectSize is the maximum number of keys stored inline in an object before spilling to a map
+	SmallObjectSize = 8
+	// SmallArraySize 数组内联存储的最大元素数，超过后溢出到堆上的切片
+	// SmallArraySize is the maximum number of elements stored inline in an array before spilling to a heap slice
+	SmallArraySize = 8
 	// DefaultParserBufferSize 默认解析器缓冲区大小
 	// DefaultParserBufferSize is the default buffer size for parsers
 	DefaultParserBufferSize = 4096
@@ -66,8 +132,8 @@ const (
 	DefaultMaxDepth = 1000
 )
 
-// 路径段类型枚举
-// Path segment type enumeration
+// SegmentType 路径段类型枚举
+// SegmentType is the kind of a path segment
 type SegmentType int
 
 const (
@@ -83,8 +149,42 @@ const (
 	// WildcardSegmentType 通配符段类型
 	// WildcardSegmentType represents a wildcard path segment
 	WildcardSegmentType
+	// CustomSegmentType 通过RegisterSegment注册的自定义段类型
+	// CustomSegmentType represents a custom segment registered with RegisterSegment
+	CustomSegmentType
 )
 
+// String 返回路径段类型的名称
+// String returns the name of the segment type
+func (st SegmentType) String() string {
+	switch st {
+	case PropertySegmentType:
+		return "property"
+	case IndexSegmentType:
+		return "index"
+	case FilterSegmentType:
+		return "filter"
+	case WildcardSegmentType:
+		return "wildcard"
+	case CustomSegmentType:
+		return "custom"
+	default:
+		return "unknown"
+	}
+}
+
+// SegmentTypeFromString 将String返回的名称转换为路径段类型，不区分大小写
+// SegmentTypeFromString converts a name returned by String to the segment type, case-insensitively
+func SegmentTypeFromString(name string) (SegmentType, error) {
+	lower := strings.ToLower(name)
+	for st := PropertySegmentType; st <= CustomSegmentType; st++ {
+		if st.String() == lower {
+			return st, nil
+		}
+	}
+	return PropertySegmentType, NewJSONError(ErrInvalidOperation, "unknown segment type '"+name+"'", nil)
+}
+
 // 序列化选项常量
 // Serialization option constants
 const (
@@ -108,9 +208,15 @@ const (
 	// DefaultSnapshotInterval 默认快照间隔（毫秒）
 	// DefaultSnapshotInterval is the default snapshot interval in milliseconds
 	DefaultSnapshotInterval = 1000
+	// DefaultAllocSampleRate 默认内存分配采样率，每100个计时操作测量一个
+	// DefaultAllocSampleRate is the default allocation sampling rate, measuring one timed operation in 100
+	DefaultAllocSampleRate = 100
 	// DefaultPathCacheSize 默认路径缓存大小
 	// DefaultPathCacheSize is the default size for path cache
 	DefaultPathCacheSize = 1000
+	// MaxParentRenderLength 错误信息中父节点渲染的最大长度
+	// MaxParentRenderLength is the maximum length of the parent node rendering in error messages
+	MaxParentRenderLength = 120
 )
 
 // 对象池测试常量
@@ -0,0 +1,750 @@
+package xyJson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CRDTTimestamp CRDT中每次写入的Lamport时间戳，Counter相同时按Actor排序，保证任意两次写入都有确定的先后
+// CRDTTimestamp is the Lamport timestamp of a write in the CRDT, ordered by Actor when Counter is equal so that any
+// two writes have a deterministic order
+type CRDTTimestamp struct {
+	Counter uint64
+	Actor   string
+}
+
+// Compare 比较两个时间戳，t较早返回-1，相同返回0，较晚返回1
+// Compare compares two timestamps, returning -1 when t is earlier, 0 when equal and 1 when later
+func (t CRDTTimestamp) Compare(other CRDTTimestamp) int {
+	switch {
+	case t.Counter < other.Counter:
+		return -1
+	case t.Counter > other.Counter:
+		return 1
+	default:
+		return strings.Compare(t.Actor, other.Actor)
+	}
+}
+
+// String 返回"counter@actor"形式的时间戳
+// String returns the timestamp in the form "counter@actor"
+func (t CRDTTimestamp) String() string {
+	return strconv.FormatUint(t.Counter, 10) + "@" + t.Actor
+}
+
+// parseCRDTTimestamp 解析"counter@actor"形式的时间戳
+// parseCRDTTimestamp parses a timestamp in the form "counter@actor"
+func parseCRDTTimestamp(s string) (CRDTTimestamp, bool) {
+	counter, actor, ok := strings.Cut(s, "@")
+	if !ok {
+		return CRDTTimestamp{}, false
+	}
+	n, err := strconv.ParseUint(counter, 10, 64)
+	return CRDTTimestamp{Counter: n, Actor: actor}, err == nil
+}
+
+// crdtNode CRDT中的一个节点：标量保存值，对象保存成员，数组保存按位置排序的元素
+// crdtNode is a node of the CRDT: scalars hold a value, objects hold members and arrays hold elements ordered by
+// position
+type crdtNode struct {
+	id     CRDTTimestamp
+	value  IValue
+	fields map[string]*crdtEntry
+	elems  map[string]*crdtEntry
+}
+
+// crdtEntry 对象成员或数组元素的LWW寄存器，node为nil表示已删除（墓碑）
+// crdtEntry is the LWW register of an object member or array element, a nil node marks a deletion (tombstone)
+type crdtEntry struct {
+	// ts 最后一次写入的时间
+	// ts is the time of the last write
+	ts CRDTTimestamp
+
+	// id和pos 数组元素的创建时间和位置键，元素按pos排序，pos相同时按id排序
+	// id and pos are the creation time and position key of an array element, elements are ordered by pos and
+	// then by id
+	id  CRDTTimestamp
+	pos string
+
+	node *crdtNode
+}
+
+// visible 返回数组中未删除的元素，按位置排序
+// visible returns the elements of an array that are not deleted, ordered by position
+func (n *crdtNode) visible() []*crdtEntry {
+	entries := make([]*crdtEntry, 0, len(n.elems))
+	for _, entry := range n.elems {
+		if entry.node != nil {
+			entries = append(entries, entry)
+		}
+	}
+	sortCRDTElements(entries)
+	return entries
+}
+
+// sortCRDTElements 按位置键和创建时间排序数组元素
+// sortCRDTElements orders array elements by position key and creation time
+func sortCRDTElements(entries []*crdtEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].pos != entries[j].pos {
+			return entries[i].pos < entries[j].pos
+		}
+		return entries[i].id.Compare(entries[j].id) < 0
+	})
+}
+
+// clone 深拷贝节点
+// clone deep-copies the node
+func (n *crdtNode) clone() *crdtNode {
+	c := &crdtNode{id: n.id}
+	switch {
+	case n.fields != nil:
+		c.fields = make(map[string]*crdtEntry, len(n.fields))
+		for key, entry := range n.fields {
+			c.fields[key] = entry.clone()
+		}
+	case n.elems != nil:
+		c.elems = make(map[string]*crdtEntry, len(n.elems))
+		for key, entry := range n.elems {
+			c.elems[key] = entry.clone()
+		}
+	default:
+		c.value = n.value.Clone()
+	}
+	return c
+}
+
+// clone 深拷贝寄存器
+// clone deep-copies the register
+func (e *crdtEntry) clone() *crdtEntry {
+	c := *e
+	if e.node != nil {
+		c.node = e.node.clone()
+	}
+	return &c
+}
+
+// crdtDigits 位置键使用的数字，按字典序与数值顺序一致
+// crdtDigits are the digits of position keys, whose lexicographic order matches their numeric order
+const crdtDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// crdtKeyBetween 返回严格位于a和b之间的位置键，a为空表示最小，b为空表示最大
+// crdtKeyBetween returns a position key strictly between a and b, an empty a means the minimum and an empty b
+// the maximum
+//
+// 生成的键不以'0'结尾，因此任意两个不同的键之间总能再插入一个键。没有上界时每位只加一，使追加的键增长缓慢
+// Generated keys never end in '0', so another key always fits between two distinct keys. Without an upper bound
+// each digit only grows by one, keeping appended keys short
+func crdtKeyBetween(a, b string) string {
+	if b != "" && a >= b {
+		b = ""
+	}
+	var key []byte
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = strings.IndexByte(crdtDigits, a[i])
+		}
+		hi := len(crdtDigits)
+		if b != "" && i < len(b) {
+			hi = strings.IndexByte(crdtDigits, b[i])
+		}
+		switch {
+		case b == "" && lo+1 < hi:
+			return string(append(key, crdtDigits[lo+1]))
+		case hi-lo > 1:
+			return string(append(key, crdtDigits[(lo+hi)/2]))
+		case hi-lo == 1:
+			// 取lo后键已小于b，之后不再受b约束
+			// After taking lo the key is already below b, which no longer constrains it
+			b = ""
+		}
+		key = append(key, crdtDigits[lo])
+	}
+}
+
+// CRDTDocument 基于LWW元素映射和LWW元素数组的JSON CRDT，用于离线优先的多端同步，并发安全
+// CRDTDocument is a JSON CRDT built from LWW-element maps and LWW-element arrays for offline-first sync between
+// devices, safe for concurrent use
+//
+// 每个对象成员和数组元素都是带Lamport时间戳和操作者ID的寄存器，Merge对每个寄存器保留较晚的写入，
+// 因此各副本以任意顺序合并后都收敛到同一文档。同一容器内的并发修改会合并；替换整个容器时，较早版本中的
+// 并发修改被丢弃。数组元素按插入时生成的位置键排序，并发插入都会保留。删除的成员保留为墓碑以便合并，
+// 不会被回收。每个副本必须使用唯一的操作者ID
+// Every object member and array element is a register carrying a Lamport timestamp and an actor ID, and Merge
+// keeps the later write of every register, so replicas merged in any order converge to the same document.
+// Concurrent edits inside the same container are combined; when a whole container is replaced, concurrent edits
+// in the older version are discarded. Array elements are ordered by position keys generated on insertion and
+// concurrent insertions are all kept. Deleted members stay as tombstones for merging and are never collected.
+// Every replica must use a unique actor ID
+//
+// 示例 Example:
+//
+//	phone := xyJson.NewCRDTDocument("phone")
+//	phone.Set("$.todos[+]", map[string]any{"title": "milk"})
+//	laptop := phone.Fork("laptop")
+//
+//	phone.Set("$.todos[0].done", true)     // 离线修改 offline edits
+//	laptop.Set("$.todos[+]", map[string]any{"title": "eggs"})
+//
+//	phone.Merge(laptop)
+//	laptop.Merge(phone)                    // 两端得到相同的文档 both sides hold the same document
+type CRDTDocument struct {
+	mu    sync.Mutex
+	actor string
+	clock uint64
+	root  *crdtNode
+}
+
+// NewCRDTDocument 为操作者创建空对象文档，所有副本的根对象相同，因此独立创建的空文档也可以合并
+// NewCRDTDocument creates an empty object document for the actor; the root object is the same on every replica,
+// so independently created empty documents merge too
+func NewCRDTDocument(actor string) *CRDTDocument {
+	return &CRDTDocument{actor: actor, root: &crdtNode{fields: make(map[string]*crdtEntry)}}
+}
+
+// NewCRDTDocumentFrom 以对象的成员为初始内容创建文档，其它副本应通过Fork或ParseCRDTState获得它，而不是各自创建
+// NewCRDTDocumentFrom creates a document with the members of an object as initial content; other replicas should
+// obtain it through Fork or ParseCRDTState rather than create their own
+func NewCRDTDocumentFrom(actor string, value IValue) (*CRDTDocument, error) {
+	if value == nil {
+		return nil, NewNullPointerError("value cannot be nil")
+	}
+	if value.Type() != ObjectValueType {
+		return nil, NewTypeMismatchError(ObjectValueType, value.Type(), "$")
+	}
+	doc := NewCRDTDocument(actor)
+	ts := doc.tick()
+	value.AsObject().Range(func(key string, child IValue) bool {
+		doc.root.fields[key] = &crdtEntry{ts: ts, node: doc.nodeFrom(child)}
+		return true
+	})
+	return doc, nil
+}
+
+// Actor 返回本副本的操作者ID
+// Actor returns the actor ID of this replica
+func (d *CRDTDocument) Actor() string {
+	return d.actor
+}
+
+// Fork 复制文档状态，作为另一个操作者的副本
+// Fork copies the document state as a replica for another actor
+func (d *CRDTDocument) Fork(actor string) *CRDTDocument {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &CRDTDocument{actor: actor, clock: d.clock, root: d.root.clone()}
+}
+
+// Value 返回文档当前内容的JSON对象
+// Value returns the current content of the document as a JSON object
+func (d *CRDTDocument) Value() IValue {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return crdtValue(defaultFactory(), d.root)
+}
+
+// crdtValue 将节点物化为JSON值
+// crdtValue materializes a node as a JSON value
+func crdtValue(factory IValueFactory, n *crdtNode) IValue {
+	switch {
+	case n.fields != nil:
+		obj := factory.CreateObject()
+		for key, entry := range n.fields {
+			if entry.node != nil {
+				obj.Set(key, crdtValue(factory, entry.node))
+			}
+		}
+		return obj
+	case n.elems != nil:
+		arr := factory.CreateArray()
+		for _, entry := range n.visible() {
+			arr.Append(crdtValue(factory, entry.node))
+		}
+		return arr
+	default:
+		return n.value.Clone()
+	}
+}
+
+// tick 推进时钟并返回新的时间戳，调用方需持有锁
+// tick advances the clock and returns a new timestamp, the caller must hold the lock
+func (d *CRDTDocument) tick() CRDTTimestamp {
+	d.clock++
+	return CRDTTimestamp{Counter: d.clock, Actor: d.actor}
+}
+
+// nodeFrom 将JSON值转换为节点，每个容器和数组元素使用新的时间戳
+// nodeFrom converts a JSON value to a node, every container and array element taking a new timestamp
+func (d *CRDTDocument) nodeFrom(value IValue) *crdtNode {
+	switch value.Type() {
+	case ObjectValueType:
+		n := &crdtNode{id: d.tick(), fields: make(map[string]*crdtEntry)}
+		value.AsObject().Range(func(key string, child IValue) bool {
+			n.fields[key] = &crdtEntry{ts: n.id, node: d.nodeFrom(child)}
+			return true
+		})
+		return n
+	case ArrayValueType:
+		n := &crdtNode{id: d.tick(), elems: make(map[string]*crdtEntry)}
+		pos := ""
+		value.AsArray().Range(func(_ int, child IValue) bool {
+			pos = crdtKeyBetween(pos, "")
+			d.addElement(n, pos, d.nodeFrom(child))
+			return true
+		})
+		return n
+	default:
+		return &crdtNode{value: value.Clone()}
+	}
+}
+
+// addElement 以新的时间戳在pos处添加数组元素
+// addElement adds an array element at pos with a new timestamp
+func (d *CRDTDocument) addElement(arr *crdtNode, pos string, node *crdtNode) {
+	id := d.tick()
+	arr.elems[id.String()] = &crdtEntry{ts: id, id: id, pos: pos, node: node}
+}
+
+// Set 根据路径设置值，缺少的中间节点会被创建，规则与Set相同：数组索引等于长度或使用[+]时追加，超出长度时用null填充
+// Set sets a value by path, creating missing intermediate nodes with the same rules as Set: an array index equal
+// to the length or [+] appends, and an index beyond the length pads with nulls
+func (d *CRDTDocument) Set(path string, value any) (err error) {
+	defer recoverPanic("set", &err)
+	v, err := defaultFactory().CreateFromRaw(value)
+	if err != nil {
+		return err
+	}
+	segments, err := d.segments(path)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.walk(segments[:len(segments)-1], segments, true)
+	if err != nil {
+		return err
+	}
+
+	last := segments[len(segments)-1]
+	switch {
+	case last.Type == PropertySegmentType && parent.fields != nil:
+		parent.fields[last.Key] = &crdtEntry{ts: d.tick(), node: d.nodeFrom(v)}
+		return nil
+	case last.Type == IndexSegmentType && parent.elems != nil:
+		elems := parent.visible()
+		index, ok := crdtIndex(last, len(elems), true)
+		if !ok {
+			return NewIndexOutOfRangeError(last.Index, len(elems), path)
+		}
+		if index < len(elems) {
+			elems[index].ts, elems[index].node = d.tick(), d.nodeFrom(v)
+			return nil
+		}
+		pos := ""
+		if len(elems) > 0 {
+			pos = elems[len(elems)-1].pos
+		}
+		for i := len(elems); i < index; i++ {
+			pos = crdtKeyBetween(pos, "")
+			d.addElement(parent, pos, &crdtNode{value: defaultFactory().CreateNull()})
+		}
+		d.addElement(parent, crdtKeyBetween(pos, ""), d.nodeFrom(v))
+		return nil
+	default:
+		return NewInvalidOperationError("set", "path '"+path+"' does not match the document structure")
+	}
+}
+
+// Insert 在路径最后一个数组索引指向的位置之前插入元素，索引等于长度时追加
+// Insert inserts an element before the position the last array index of the path refers to, appending when the
+// index equals the length
+func (d *CRDTDocument) Insert(path string, value any) (err error) {
+	defer recoverPanic("insert", &err)
+	v, err := defaultFactory().CreateFromRaw(value)
+	if err != nil {
+		return err
+	}
+	segments, err := d.segments(path)
+	if err != nil {
+		return err
+	}
+	last := segments[len(segments)-1]
+	if last.Type != IndexSegmentType {
+		return NewInvalidOperationError("insert", "path '"+path+"' must end with an array index")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.walk(segments[:len(segments)-1], segments, true)
+	if err != nil {
+		return err
+	}
+	if parent.elems == nil {
+		return NewInvalidOperationError("insert", "path '"+path+"' does not refer to an array element")
+	}
+
+	elems := parent.visible()
+	index, ok := crdtIndex(last, len(elems), true)
+	if !ok || index > len(elems) {
+		return NewIndexOutOfRangeError(last.Index, len(elems), path)
+	}
+	before, after := "", ""
+	if index > 0 {
+		before = elems[index-1].pos
+	}
+	if index < len(elems) {
+		after = elems[index].pos
+	}
+	d.addElement(parent, crdtKeyBetween(before, after), d.nodeFrom(v))
+	return nil
+}
+
+// Delete 根据路径删除值，删除的成员保留为墓碑
+// Delete deletes a value by path, the deleted member stays as a tombstone
+func (d *CRDTDocument) Delete(path string) error {
+	segments, err := d.segments(path)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	parent, err := d.walk(segments[:len(segments)-1], segments, false)
+	if err != nil {
+		return err
+	}
+
+	last := segments[len(segments)-1]
+	var entry *crdtEntry
+	switch {
+	case last.Type == PropertySegmentType && parent.fields != nil:
+		entry = parent.fields[last.Key]
+	case last.Type == IndexSegmentType && parent.elems != nil:
+		elems := parent.visible()
+		if index, ok := crdtIndex(last, len(elems), false); ok {
+			entry = elems[index]
+		}
+	}
+	if entry == nil || entry.node == nil {
+		return NewPathNotFoundError(path)
+	}
+	entry.ts, entry.node = d.tick(), nil
+	return nil
+}
+
+// segments 解析只包含属性和索引的路径
+// segments parses a path made of properties and indices only
+func (d *CRDTDocument) segments(path string) ([]*pathSegment, error) {
+	segments, err := (&pathQuery{}).parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, NewInvalidJSONError("cannot set root value", nil)
+	}
+	for _, segment := range segments {
+		if segment.Recursive || segment.Wildcard || segment.Filter != nil ||
+			segment.Type != PropertySegmentType && segment.Type != IndexSegmentType {
+			return nil, NewInvalidPathError(path, nil)
+		}
+	}
+	return segments, nil
+}
+
+// crdtIndex 将索引段解析为可见元素中的位置，appendable为true时允许等于或超出长度
+// crdtIndex resolves an index segment to a position among the visible elements, allowing the length or beyond
+// when appendable is true
+func crdtIndex(segment *pathSegment, length int, appendable bool) (int, bool) {
+	switch {
+	case segment.Append && appendable:
+		return length, true
+	case segment.Append:
+		return length - 1, length > 0
+	case segment.Index < 0:
+		return normalizeIndex(segment.Index, length)
+	default:
+		return segment.Index, segment.Index < length || appendable
+	}
+}
+
+// walk 沿路径段找到容器节点，create为true时像Set一样创建缺少的中间节点
+// walk follows the segments to a container node, creating missing intermediate nodes like Set when create is true
+func (d *CRDTDocument) walk(segments, all []*pathSegment, create bool) (*crdtNode, error) {
+	node := d.root
+	for i, segment := range segments {
+		next := all[i+1]
+		var entry *crdtEntry
+		switch {
+		case segment.Type == PropertySegmentType && node.fields != nil:
+			entry = node.fields[segment.Key]
+			if (entry == nil || entry.node == nil) && create {
+				entry = &crdtEntry{ts: d.tick(), node: d.containerFor(next)}
+				node.fields[segment.Key] = entry
+			}
+		case segment.Type == IndexSegmentType && node.elems != nil:
+			elems := node.visible()
+			index, ok := crdtIndex(segment, len(elems), create)
+			switch {
+			case ok && index < len(elems):
+				entry = elems[index]
+			case ok && index == len(elems):
+				pos := ""
+				if len(elems) > 0 {
+					pos = elems[len(elems)-1].pos
+				}
+				d.addElement(node, crdtKeyBetween(pos, ""), d.containerFor(next))
+				entry = node.visible()[index]
+			default:
+				return nil, NewIndexOutOfRangeError(segment.Index, len(elems), "")
+			}
+		default:
+			return nil, NewInvalidOperationError("walk", "path does not match the document structure")
+		}
+		if entry == nil || entry.node == nil {
+			return nil, NewPathNotFoundError(segment.Key)
+		}
+		node = entry.node
+	}
+	return node, nil
+}
+
+// containerFor 为下一个路径段创建空容器：索引段创建数组，属性段创建对象
+// containerFor creates an empty container for the next segment: an array for an index, an object for a property
+func (d *CRDTDocument) containerFor(next *pathSegment) *crdtNode {
+	if next.Type == IndexSegmentType {
+		return &crdtNode{id: d.tick(), elems: make(map[string]*crdtEntry)}
+	}
+	return &crdtNode{id: d.tick(), fields: make(map[string]*crdtEntry)}
+}
+
+// Merge 将另一个副本的状态合并到本文档，合并满足交换律、结合律和幂等性，remote不会被修改
+// Merge merges the state of another replica into this document; merging is commutative, associative and
+// idempotent, and remote is not modified
+func (d *CRDTDocument) Merge(remote *CRDTDocument) error {
+	if remote == nil {
+		return NewNullPointerError("remote cannot be nil")
+	}
+	if remote == d {
+		return nil
+	}
+	remote.mu.Lock()
+	root, clock := remote.root.clone(), remote.clock
+	remote.mu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	mergeCRDTNode(d.root, root)
+	d.clock = max(d.clock, clock)
+	return nil
+}
+
+// mergeCRDTNode 合并同一个容器的两个版本
+// mergeCRDTNode merges two versions of the same container
+func mergeCRDTNode(local, remote *crdtNode) {
+	for key, entry := range remote.fields {
+		local.fields[key] = mergeCRDTEntry(local.fields[key], entry)
+	}
+	for key, entry := range remote.elems {
+		local.elems[key] = mergeCRDTEntry(local.elems[key], entry)
+	}
+}
+
+// mergeCRDTEntry 保留较晚的写入；时间戳相同表示同一次写入，两边都是容器时合并其中的修改
+// mergeCRDTEntry keeps the later write; equal timestamps mean the same write, whose containers have their edits
+// merged
+func mergeCRDTEntry(local, remote *crdtEntry) *crdtEntry {
+	if local == nil {
+		return remote
+	}
+	switch local.ts.Compare(remote.ts) {
+	case -1:
+		return remote
+	case 1:
+		return local
+	}
+	if local.node != nil && remote.node != nil && local.node.id == remote.node.id &&
+		(local.node.fields != nil && remote.node.fields != nil || local.node.elems != nil && remote.node.elems != nil) {
+		mergeCRDTNode(local.node, remote.node)
+	}
+	return local
+}
+
+// State 返回包含所有时间戳和墓碑的完整状态，可以序列化后发送给其它副本
+// State returns the full state including every timestamp and tombstone, ready to be serialized and sent to other
+// replicas
+//
+// 格式 Format:
+//
+//	{"actor":"phone","clock":7,"root":node}
+//	node:  {"value":x} | {"id":"3@phone","fields":{"k":entry}} | {"id":"3@phone","elements":{"4@phone":entry}}
+//	entry: {"ts":"5@laptop","pos":"i","node":node}，墓碑没有node / tombstones have no node
+func (d *CRDTDocument) State() IValue {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	factory := defaultFactory()
+	obj := factory.CreateObject()
+	obj.Set("actor", factory.CreateString(d.actor))
+	obj.Set("clock", factory.CreateNumber(d.clock))
+	obj.Set("root", crdtNodeState(factory, d.root))
+	return obj
+}
+
+// MarshalState 将完整状态序列化为紧凑JSON
+// MarshalState serializes the full state to compact JSON
+func (d *CRDTDocument) MarshalState() ([]byte, error) {
+	return CompactSerializer().Serialize(d.State())
+}
+
+// crdtNodeState 将节点转换为状态JSON
+// crdtNodeState converts a node to state JSON
+func crdtNodeState(factory IValueFactory, n *crdtNode) IValue {
+	obj := factory.CreateObject()
+	if n.fields == nil && n.elems == nil {
+		obj.Set("value", n.value.Clone())
+		return obj
+	}
+	obj.Set("id", factory.CreateString(n.id.String()))
+	entries, key := n.fields, "fields"
+	if n.elems != nil {
+		entries, key = n.elems, "elements"
+	}
+	members := factory.CreateObject()
+	for name, entry := range entries {
+		state := factory.CreateObject()
+		state.Set("ts", factory.CreateString(entry.ts.String()))
+		if n.elems != nil {
+			state.Set("pos", factory.CreateString(entry.pos))
+		}
+		if entry.node != nil {
+			state.Set("node", crdtNodeState(factory, entry.node))
+		}
+		members.Set(name, state)
+	}
+	obj.Set(key, members)
+	return obj
+}
+
+// ParseCRDTState 从State生成的JSON恢复文档，用于接收其它副本的状态或恢复本地保存的副本
+// ParseCRDTState restores a document from JSON produced by State, for receiving the state of another replica or
+// restoring a locally saved one
+//
+// 示例 Example:
+//
+//	remote, err := xyJson.ParseCRDTState(body)
+//	if err != nil {
+//		return err
+//	}
+//	local.Merge(remote)
+func ParseCRDTState(data []byte) (*CRDTDocument, error) {
+	state, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return CRDTFromState(state)
+}
+
+// CRDTFromState 从State返回的JSON值恢复文档
+// CRDTFromState restores a document from the JSON value returned by State
+func CRDTFromState(state IValue) (*CRDTDocument, error) {
+	if state == nil || state.Type() != ObjectValueType {
+		return nil, NewInvalidJSONError("CRDT state must be an object", nil).WithPath("$")
+	}
+	obj := state.AsObject()
+	actor, ok := patchMember(obj, "actor")
+	if !ok {
+		return nil, NewInvalidJSONError("CRDT state requires a string actor", nil).WithPath("$.actor")
+	}
+	clock, err := frameNumber(obj, "clock")
+	if err != nil {
+		return nil, err
+	}
+	root, err := crdtNodeFromState(obj.Get("root"), "$.root", 0)
+	if err != nil {
+		return nil, err
+	}
+	if root.fields == nil {
+		return nil, NewInvalidJSONError("CRDT root must be an object node", nil).WithPath("$.root")
+	}
+	return &CRDTDocument{actor: actor, clock: clock, root: root}, nil
+}
+
+// crdtNodeFromState 从状态JSON恢复节点
+// crdtNodeFromState restores a node from state JSON
+func crdtNodeFromState(state IValue, path string, depth int) (*crdtNode, error) {
+	if depth > MaxNestingDepth {
+		return nil, NewMaxDepthExceededError(MaxNestingDepth).WithPath(path)
+	}
+	if state == nil || state.Type() != ObjectValueType {
+		return nil, NewInvalidJSONError("CRDT node must be an object", nil).WithPath(path)
+	}
+	obj := state.AsObject()
+	if value := obj.Get("value"); value != nil {
+		return &crdtNode{value: value.Clone()}, nil
+	}
+
+	n := &crdtNode{}
+	text, _ := patchMember(obj, "id")
+	var ok bool
+	if n.id, ok = parseCRDTTimestamp(text); !ok || n.id.Counter == 0 && depth > 0 {
+		return nil, NewInvalidJSONError(fmt.Sprintf("invalid CRDT node id %q", text), nil).WithPath(path)
+	}
+	members, key := obj.Get("fields"), "fields"
+	if members == nil {
+		members, key = obj.Get("elements"), "elements"
+	}
+	if members == nil || members.Type() != ObjectValueType {
+		return nil, NewInvalidJSONError("CRDT node requires a value, fields or elements", nil).WithPath(path)
+	}
+
+	entries := make(map[string]*crdtEntry, members.AsObject().Size())
+	var failure error
+	members.AsObject().Range(func(name string, member IValue) bool {
+		entryPath := appendPathKey(appendPathKey(path, key), name)
+		entry, err := crdtEntryFromState(member, entryPath, depth)
+		if err == nil && key == "elements" {
+			var ok bool
+			if entry.id, ok = parseCRDTTimestamp(name); !ok {
+				err = NewInvalidJSONError(fmt.Sprintf("invalid CRDT element id %q", name), nil).WithPath(entryPath)
+			}
+		}
+		entries[name], failure = entry, err
+		return err == nil
+	})
+	if failure != nil {
+		return nil, failure
+	}
+	if key == "fields" {
+		n.fields = entries
+	} else {
+		n.elems = entries
+	}
+	return n, nil
+}
+
+// crdtEntryFromState 从状态JSON恢复寄存器
+// crdtEntryFromState restores a register from state JSON
+func crdtEntryFromState(state IValue, path string, depth int) (*crdtEntry, error) {
+	if state.Type() != ObjectValueType {
+		return nil, NewInvalidJSONError("CRDT entry must be an object", nil).WithPath(path)
+	}
+	obj := state.AsObject()
+	text, _ := patchMember(obj, "ts")
+	ts, ok := parseCRDTTimestamp(text)
+	if !ok {
+		return nil, NewInvalidJSONError(fmt.Sprintf("invalid CRDT timestamp %q", text), nil).WithPath(appendPathKey(path, "ts"))
+	}
+	entry := &crdtEntry{ts: ts}
+	entry.pos, _ = patchMember(obj, "pos")
+	if node := obj.Get("node"); node != nil {
+		var err error
+		if entry.node, err = crdtNodeFromState(node, appendPathKey(path, "node"), depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
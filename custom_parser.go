@@ -3,31 +3,10 @@ package xyJson
 import (
 	"reflect"
 	"strconv"
-	"unsafe"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
-// reflectKindToValueType 将reflect.Kind转换为ValueType
-func reflectKindToValueType(kind reflect.Kind) ValueType {
-	switch kind {
-	case reflect.String:
-		return StringValueType
-	case reflect.Bool:
-		return BoolValueType
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64:
-		return NumberValueType
-	case reflect.Slice, reflect.Array:
-		return ArrayValueType
-	case reflect.Map, reflect.Struct:
-		return ObjectValueType
-	case reflect.Ptr, reflect.Interface:
-		return NullValueType // 可能为null
-	default:
-		return NullValueType
-	}
-}
-
 // 自定义JSON解析器常量
 // Custom JSON parser constants
 const (
@@ -76,34 +55,54 @@ type customParser struct {
 	pos    int
 	length int
 	
-	// 缓存的反射信息
-	// Cached reflection info
-	structInfoCache map[reflect.Type]*customStructInfo
-}
+	// projection 当前层级的投影，nil表示解码结构体的全部字段
+	// projection is the projection of the current level, nil decodes all struct fields
+	projection *Projection
 
-// customStructInfo 自定义结构体信息
-// customStructInfo holds custom struct information
-type customStructInfo struct {
-	Fields map[string]*customFieldInfo
-}
+	// strictNumbers 是否严格按RFC 8259语法校验数字
+	// strictNumbers indicates whether numbers are checked strictly against the RFC 8259 grammar
+	strictNumbers bool
+	// strictSyntax 是否与IValue解析器一样拒绝重复的键和值之后的多余数据
+	// strictSyntax indicates whether duplicate keys and extra data after the value are rejected, as the IValue
+	// parser does
+	strictSyntax bool
+	// allowNonFinite 是否接受NaN、Infinity和-Infinity字面量
+	// allowNonFinite indicates whether the NaN, Infinity and -Infinity literals are accepted
+	allowNonFinite bool
+	// numbers 数字转换选项，nil表示默认选项
+	// numbers are the number conversion options, nil means the defaults
+	numbers *NumberConversionOptions
 
-// customFieldInfo 自定义字段信息
-// customFieldInfo holds custom field information
-type customFieldInfo struct {
-	Index    int
-	Name     string
-	Type     reflect.Type
-	Kind     reflect.Kind
-	IsPtr    bool
-	Offset   uintptr
-	Setter   func(ptr unsafe.Pointer, value interface{}) error
+	// depth和maxDepth 当前嵌套深度和允许的最大深度，解码和跳过的值都计入
+	// depth and maxDepth are the current nesting depth and the maximum allowed, counting both decoded and
+	// skipped values
+	depth    int
+	maxDepth int
 }
 
-// NewCustomParser 创建新的自定义解析器
-// NewCustomParser creates a new custom parser
+// NewCustomParser 创建新的自定义解析器，解析器可被多个协程共享，结构体信息使用全局缓存
+// NewCustomParser creates a new custom parser, which can be shared between goroutines and uses the global
+// struct info cache
 func NewCustomParser() ICustomParser {
 	return &customParser{
-		structInfoCache: make(map[reflect.Type]*customStructInfo),
+		maxDepth: DefaultMaxDepth,
+	}
+}
+
+// NewCustomParserWithOptions 使用指定解析选项创建自定义解析器
+// NewCustomParserWithOptions creates a custom parser with the specified parse options
+func NewCustomParserWithOptions(options *ParseOptions) ICustomParser {
+	if options == nil {
+		options = DefaultParseOptions()
+	}
+	maxDepth := options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return &customParser{
+		strictNumbers:  options.StrictNumbers,
+		allowNonFinite: options.AllowNonFinite,
+		maxDepth:       maxDepth,
 	}
 }
 
@@ -124,8 +123,20 @@ func (cp *customParser) UnmarshalDirect(data []byte, target interface{}) error {
 		return NewInvalidJSONError("target must be settable", nil)
 	}
 	
-	cp.reset(data)
-	return cp.parseValueDirect(rv)
+	// 每次调用在配置的副本上解析，解析器可以被多个协程共享
+	// Every call parses on a copy of the configuration, so the parser can be shared between goroutines
+	state := *cp
+	state.reset(data)
+	if err := state.parseValueDirect(rv); err != nil {
+		return err
+	}
+	if state.strictSyntax {
+		state.skipWhitespace()
+		if state.pos < state.length {
+			return NewInvalidJSONError("unexpected character after JSON", nil)
+		}
+	}
+	return nil
 }
 
 // UnmarshalDirectString 直接解析JSON字符串到结构体
@@ -140,6 +151,7 @@ func (cp *customParser) reset(data []byte) {
 	cp.data = data
 	cp.pos = 0
 	cp.length = len(data)
+	cp.depth = 0
 }
 
 // parseValueDirect 直接解析值到reflect.Value
@@ -151,6 +163,17 @@ func (cp *customParser) parseValueDirect(rv reflect.Value) error {
 		return NewInvalidJSONError("unexpected end of input", nil)
 	}
 	
+	if cp.allowNonFinite {
+		if f, n := matchNonFinite(cp.data[cp.pos:]); n > 0 {
+			cp.pos += n
+			if rv.Kind() != reflect.Float32 && rv.Kind() != reflect.Float64 {
+				return NewTypeMismatchError(NumberValueType, ValueTypeFromKind(rv.Kind()), "non-finite number for non-float field")
+			}
+			rv.SetFloat(f)
+			return nil
+		}
+	}
+	
 	ch := cp.data[cp.pos]
 	switch ch {
 	case CharQuote:
@@ -193,7 +216,7 @@ func (cp *customParser) parseStringDirect(rv reflect.Value) error {
 				rv.SetString(str)
 				return nil
 			}
-			return NewTypeMismatchError(StringValueType, reflectKindToValueType(rv.Type().Kind()), "")
+			return NewTypeMismatchError(StringValueType, ValueTypeFromKind(rv.Type().Kind()), "")
 		}
 		if ch == CharBackslash {
 			// 处理转义字符
@@ -221,7 +244,7 @@ func (cp *customParser) parseStringWithEscapeDirect(rv reflect.Value, start int)
 				rv.SetString(str)
 				return nil
 			}
-			return NewTypeMismatchError(StringValueType, reflectKindToValueType(rv.Type().Kind()), "")
+			return NewTypeMismatchError(StringValueType, ValueTypeFromKind(rv.Type().Kind()), "")
 		}
 		if ch == CharBackslash {
 			cp.pos++
@@ -246,6 +269,12 @@ func (cp *customParser) parseStringWithEscapeDirect(rv reflect.Value, start int)
 				buf = append(buf, CharReturn)
 			case 't':
 				buf = append(buf, CharTab)
+			case 'u':
+				r, err := cp.parseUnicodeEscapeDirect()
+				if err != nil {
+					return err
+				}
+				buf = utf8.AppendRune(buf, r)
 			default:
 				return NewInvalidJSONError("invalid escape character", nil)
 			}
@@ -258,106 +287,120 @@ func (cp *customParser) parseStringWithEscapeDirect(rv reflect.Value, start int)
 	return NewInvalidJSONError("unterminated string", nil)
 }
 
+// parseUnicodeEscapeDirect 解析当前位置'u'开始的Unicode转义，代理对组合为一个字符，孤立的代理解码为U+FFFD；
+// 返回时当前位置为转义的最后一个字节
+// parseUnicodeEscapeDirect parses the Unicode escape starting at the 'u' at the current position, surrogate pairs
+// combine into one rune and lone surrogates decode to U+FFFD; the current position is the last byte of the
+// escape on return
+func (cp *customParser) parseUnicodeEscapeDirect() (rune, error) {
+	r, ok := cp.hex4At(cp.pos + 1)
+	if !ok {
+		return 0, NewInvalidJSONError("invalid unicode escape", nil)
+	}
+	cp.pos += 4
+
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if cp.pos+2 < cp.length && cp.data[cp.pos+1] == CharBackslash && cp.data[cp.pos+2] == 'u' {
+		if low, ok := cp.hex4At(cp.pos + 3); ok {
+			if combined := utf16.DecodeRune(r, low); combined != utf8.RuneError {
+				cp.pos += 6
+				return combined, nil
+			}
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+// hex4At 读取pos处的4位十六进制数
+// hex4At reads the 4 hex digits at pos
+func (cp *customParser) hex4At(pos int) (rune, bool) {
+	if pos+4 > cp.length {
+		return 0, false
+	}
+	var r rune
+	for _, c := range cp.data[pos : pos+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			r = r<<4 | rune(c-'0')
+		case 'a' <= c && c <= 'f':
+			r = r<<4 | rune(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			r = r<<4 | rune(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return r, true
+}
+
 // parseNumberDirect 直接解析数字
 // parseNumberDirect parses number directly
 func (cp *customParser) parseNumberDirect(rv reflect.Value) error {
 	start := cp.pos
-	
-	// 扫描数字
-	if cp.data[cp.pos] == '-' {
-		cp.pos++
-	}
-	
-	if cp.pos >= cp.length || (cp.data[cp.pos] < '0' || cp.data[cp.pos] > '9') {
-		return NewInvalidJSONError("invalid number", nil)
-	}
-	
-	// 扫描整数部分
-	for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
-		cp.pos++
-	}
-	
-	// 检查小数点
-	hasDecimal := false
-	if cp.pos < cp.length && cp.data[cp.pos] == '.' {
-		hasDecimal = true
-		cp.pos++
-		for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
-			cp.pos++
-		}
-	}
-	
-	// 检查指数
-	if cp.pos < cp.length && (cp.data[cp.pos] == 'e' || cp.data[cp.pos] == 'E') {
-		hasDecimal = true
-		cp.pos++
-		if cp.pos < cp.length && (cp.data[cp.pos] == '+' || cp.data[cp.pos] == '-') {
-			cp.pos++
-		}
-		for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
-			cp.pos++
-		}
+	hasDecimal, err := cp.scanNumber()
+	if err != nil {
+		return err
 	}
 	
 	numStr := string(cp.data[start:cp.pos])
-	
-	// 根据目标类型解析
-	switch rv.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if hasDecimal {
-			return NewTypeMismatchError(NumberValueType, reflectKindToValueType(rv.Kind()), "decimal number for integer field")
-		}
-		val, err := strconv.ParseInt(numStr, 10, 64)
-		if err != nil {
-			return NewInvalidJSONError("invalid integer", err)
-		}
-		rv.SetInt(val)
-		return nil
-		
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if hasDecimal {
-			return NewTypeMismatchError(NumberValueType, reflectKindToValueType(rv.Kind()), "decimal number for unsigned integer field")
-		}
-		val, err := strconv.ParseUint(numStr, 10, 64)
-		if err != nil {
-			return NewInvalidJSONError("invalid unsigned integer", err)
-		}
-		rv.SetUint(val)
-		return nil
-		
-	case reflect.Float32, reflect.Float64:
-		val, err := strconv.ParseFloat(numStr, 64)
+
+	// 整数保持精确，超出int64的正整数按uint64解析，其余按float64解析，再由统一的数字转换检查目标类型
+	// Integers stay exact, positive integers beyond int64 parse as uint64 and the rest as float64, then the
+	// shared number conversion checks the target type
+	var raw any
+	if !hasDecimal {
+		if i, err := strconv.ParseInt(numStr, 10, 64); err == nil {
+			raw = i
+		} else if u, err := strconv.ParseUint(numStr, 10, 64); err == nil {
+			raw = u
+		}
+	}
+	if raw == nil {
+		f, err := strconv.ParseFloat(numStr, 64)
 		if err != nil {
-			return NewInvalidJSONError("invalid float", err)
+			return NewInvalidJSONError("invalid number", err)
 		}
-		rv.SetFloat(val)
+		raw = f
+	}
+	if rv.Kind() == reflect.String {
+		// 与IValue策略一样把数字转换为字符串
+		// Convert the number to a string as the IValue strategy does
+		rv.SetString(formatNumber(raw))
 		return nil
-		
-	default:
-		return NewTypeMismatchError(NumberValueType, reflectKindToValueType(rv.Kind()), "")
 	}
+	if err := setReflectNumber(rv, raw, conversionOptions(cp.numbers)); err != nil {
+		return err
+	}
+	return nil
 }
 
 // parseBoolDirect 直接解析布尔值
 // parseBoolDirect parses boolean directly
 func (cp *customParser) parseBoolDirect(rv reflect.Value) error {
-	if rv.Kind() != reflect.Bool {
-		return NewTypeMismatchError(BoolValueType, reflectKindToValueType(rv.Kind()), "")
+	if rv.Kind() != reflect.Bool && rv.Kind() != reflect.String {
+		return NewTypeMismatchError(BoolValueType, ValueTypeFromKind(rv.Kind()), "")
 	}
 	
+	var b bool
 	if cp.pos+4 <= cp.length && string(cp.data[cp.pos:cp.pos+4]) == "true" {
 		cp.pos += 4
-		rv.SetBool(true)
-		return nil
+		b = true
+	} else if cp.pos+5 <= cp.length && string(cp.data[cp.pos:cp.pos+5]) == "false" {
+		cp.pos += 5
+	} else {
+		return NewInvalidJSONError("invalid boolean value", nil)
 	}
 	
-	if cp.pos+5 <= cp.length && string(cp.data[cp.pos:cp.pos+5]) == "false" {
-		cp.pos += 5
-		rv.SetBool(false)
+	if rv.Kind() == reflect.String {
+		// 与IValue策略一样把布尔值转换为字符串
+		// Convert the boolean to a string as the IValue strategy does
+		rv.SetString(strconv.FormatBool(b))
 		return nil
 	}
-	
-	return NewInvalidJSONError("invalid boolean value", nil)
+	rv.SetBool(b)
+	return nil
 }
 
 // parseNullDirect 直接解析null值
@@ -374,14 +417,26 @@ func (cp *customParser) parseNullDirect(rv reflect.Value) error {
 // parseObjectDirect 直接解析对象
 // parseObjectDirect parses object directly
 func (cp *customParser) parseObjectDirect(rv reflect.Value) error {
+	if rv.Kind() == reflect.String {
+		// 与IValue策略一样，容器写入字符串字段时得到空字符串
+		// As with the IValue strategy, a container decoded into a string field yields the empty string
+		rv.SetString("")
+		return cp.skipValue()
+	}
 	if rv.Kind() != reflect.Struct {
-		return NewTypeMismatchError(ObjectValueType, reflectKindToValueType(rv.Kind()), "")
+		return NewTypeMismatchError(ObjectValueType, ValueTypeFromKind(rv.Kind()), "")
 	}
 	
 	if cp.data[cp.pos] != CharLeftBrace {
 		return NewInvalidJSONError("expected '{'", nil)
 	}
 	
+	cp.depth++
+	defer func() { cp.depth-- }()
+	if cp.depth > cp.maxDepth {
+		return NewMaxDepthExceededError(cp.maxDepth)
+	}
+	
 	cp.pos++ // 跳过 '{'
 	cp.skipWhitespace()
 	
@@ -392,7 +447,8 @@ func (cp *customParser) parseObjectDirect(rv reflect.Value) error {
 	}
 	
 	// 获取结构体信息
-	structInfo := cp.getCustomStructInfo(rv.Type())
+	structInfo := getStructInfo(rv.Type())
+	var seen duplicateKeys
 	
 	for {
 		// 解析键
@@ -416,6 +472,9 @@ func (cp *customParser) parseObjectDirect(rv reflect.Value) error {
 		
 		key := string(cp.data[keyStart:cp.pos])
 		cp.pos++ // 跳过结束引号
+		if cp.strictSyntax && seen.add(key) {
+			return NewInvalidJSONError("duplicate key: "+key, nil)
+		}
 		
 		// 跳过冒号
 		cp.skipWhitespace()
@@ -424,10 +483,19 @@ func (cp *customParser) parseObjectDirect(rv reflect.Value) error {
 		}
 		cp.pos++
 		
-		// 解析值
-		if fieldInfo, exists := structInfo.Fields[key]; exists {
-			fieldValue := rv.Field(fieldInfo.Index)
-			if err := cp.parseValueDirect(fieldValue); err != nil {
+		// 解析值，投影模式下未投影的字段同样被跳过
+		// Parse the value, in projection mode unprojected fields are skipped as well
+		fieldInfo, exists := structInfo.Fields[key]
+		var child *Projection
+		if exists && cp.projection != nil {
+			child, exists = cp.projection.fields[key]
+		}
+		if exists {
+			parent := cp.projection
+			cp.projection = child
+			err := cp.parseValueDirect(rv.Field(fieldInfo.Index))
+			cp.projection = parent
+			if err != nil {
 				return err
 			}
 		} else {
@@ -460,14 +528,24 @@ func (cp *customParser) parseObjectDirect(rv reflect.Value) error {
 // parseArrayDirect 直接解析数组
 // parseArrayDirect parses array directly
 func (cp *customParser) parseArrayDirect(rv reflect.Value) error {
+	if rv.Kind() == reflect.String {
+		rv.SetString("")
+		return cp.skipValue()
+	}
 	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
-		return NewTypeMismatchError(ArrayValueType, reflectKindToValueType(rv.Kind()), "")
+		return NewTypeMismatchError(ArrayValueType, ValueTypeFromKind(rv.Kind()), "")
 	}
 	
 	if cp.data[cp.pos] != CharLeftBracket {
 		return NewInvalidJSONError("expected '['", nil)
 	}
 	
+	cp.depth++
+	defer func() { cp.depth-- }()
+	if cp.depth > cp.maxDepth {
+		return NewMaxDepthExceededError(cp.maxDepth)
+	}
+	
 	cp.pos++ // 跳过 '['
 	cp.skipWhitespace()
 	
@@ -538,6 +616,13 @@ func (cp *customParser) skipValue() error {
 		return NewInvalidJSONError("unexpected end of input", nil)
 	}
 	
+	if cp.allowNonFinite {
+		if _, n := matchNonFinite(cp.data[cp.pos:]); n > 0 {
+			cp.pos += n
+			return nil
+		}
+	}
+	
 	ch := cp.data[cp.pos]
 	switch ch {
 	case CharQuote:
@@ -602,6 +687,12 @@ func (cp *customParser) skipObject() error {
 		return NewInvalidJSONError("expected '{'", nil)
 	}
 	
+	cp.depth++
+	defer func() { cp.depth-- }()
+	if cp.depth > cp.maxDepth {
+		return NewMaxDepthExceededError(cp.maxDepth)
+	}
+	
 	cp.pos++
 	cp.skipWhitespace()
 	
@@ -654,6 +745,12 @@ func (cp *customParser) skipArray() error {
 		return NewInvalidJSONError("expected '['", nil)
 	}
 	
+	cp.depth++
+	defer func() { cp.depth-- }()
+	if cp.depth > cp.maxDepth {
+		return NewMaxDepthExceededError(cp.maxDepth)
+	}
+	
 	cp.pos++
 	cp.skipWhitespace()
 	
@@ -690,39 +787,108 @@ func (cp *customParser) skipArray() error {
 // skipNumber 跳过数字
 // skipNumber skips a number
 func (cp *customParser) skipNumber() error {
+	_, err := cp.scanNumber()
+	return err
+}
+
+// scanNumber 扫描数字并返回是否含小数或指数部分
+// scanNumber scans a number and reports whether it has a fraction or exponent part
+//
+// 宽松模式下接受前导零以及没有数字的小数和指数部分（如"01"、"1."、"1e"），
+// 严格模式下完全按RFC 8259语法校验
+// The lenient mode accepts leading zeros and fraction or exponent parts without digits (such as "01", "1."
+// and "1e"), the strict mode follows the RFC 8259 grammar exactly
+func (cp *customParser) scanNumber() (bool, error) {
 	if cp.data[cp.pos] == '-' {
 		cp.pos++
 	}
 	
 	if cp.pos >= cp.length || (cp.data[cp.pos] < '0' || cp.data[cp.pos] > '9') {
-		return NewInvalidJSONError("invalid number", nil)
+		return false, NewInvalidJSONError("invalid number", nil)
 	}
 	
-	// 跳过整数部分
+	// 扫描整数部分
+	// Scan the integer part
+	leadingZero := cp.data[cp.pos] == '0'
+	intStart := cp.pos
 	for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
 		cp.pos++
 	}
+	if cp.strictNumbers && leadingZero && cp.pos-intStart > 1 {
+		return false, NewInvalidJSONError("invalid number: leading zeros are not allowed", nil)
+	}
 	
-	// 跳过小数部分
+	// 扫描小数部分
+	// Scan the fraction part
+	hasDecimal := false
 	if cp.pos < cp.length && cp.data[cp.pos] == '.' {
+		hasDecimal = true
 		cp.pos++
+		if cp.strictNumbers && !cp.isDigitAt(cp.pos) {
+			return false, NewInvalidJSONError("invalid number: missing digits after decimal point", nil)
+		}
 		for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
 			cp.pos++
 		}
 	}
 	
-	// 跳过指数部分
+	// 扫描指数部分
+	// Scan the exponent part
 	if cp.pos < cp.length && (cp.data[cp.pos] == 'e' || cp.data[cp.pos] == 'E') {
+		hasDecimal = true
 		cp.pos++
 		if cp.pos < cp.length && (cp.data[cp.pos] == '+' || cp.data[cp.pos] == '-') {
 			cp.pos++
 		}
+		if cp.strictNumbers && !cp.isDigitAt(cp.pos) {
+			return false, NewInvalidJSONError("invalid number: missing digits in exponent", nil)
+		}
 		for cp.pos < cp.length && cp.data[cp.pos] >= '0' && cp.data[cp.pos] <= '9' {
 			cp.pos++
 		}
 	}
 	
-	return nil
+	return hasDecimal, nil
+}
+
+// duplicateKeys 记录对象中已出现的键，键少时线性查找，键多时改用map
+// duplicateKeys records the keys seen in an object, searching linearly while there are few keys and switching
+// to a map when there are many
+type duplicateKeys struct {
+	keys []string
+	set  map[string]struct{}
+}
+
+// add 记录键并报告它是否已经出现过
+// add records a key and reports whether it was seen before
+func (dk *duplicateKeys) add(key string) bool {
+	if dk.set != nil {
+		if _, ok := dk.set[key]; ok {
+			return true
+		}
+		dk.set[key] = struct{}{}
+		return false
+	}
+	for _, k := range dk.keys {
+		if k == key {
+			return true
+		}
+	}
+	dk.keys = append(dk.keys, key)
+	if len(dk.keys) > 16 {
+		dk.set = make(map[string]struct{}, len(dk.keys)*2)
+		for _, k := range dk.keys {
+			dk.set[k] = struct{}{}
+		}
+		dk.keys = nil
+	}
+	return false
+}
+
+// isDigitAt 检查指定位置是否为数字
+// isDigitAt checks whether the byte at the position is a digit
+func (cp *customParser) isDigitAt(pos int) bool {
+	return pos < cp.length && cp.data[pos] >= '0' && cp.data[pos] <= '9'
 }
 
 // skipWhitespace 跳过空白字符
@@ -737,62 +903,3 @@ func (cp *customParser) skipWhitespace() {
 		}
 	}
 }
-
-// getCustomStructInfo 获取自定义结构体信息
-// getCustomStructInfo gets custom struct info
-func (cp *customParser) getCustomStructInfo(t reflect.Type) *customStructInfo {
-	if info, exists := cp.structInfoCache[t]; exists {
-		return info
-	}
-	
-	info := &customStructInfo{
-		Fields: make(map[string]*customFieldInfo),
-	}
-	
-	// 遍历所有字段
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		
-		// 跳过非导出字段
-		if !field.IsExported() {
-			continue
-		}
-		
-		// 解析JSON标签
-		tag := parseJSONTag(field.Tag.Get("json"))
-		
-		// 跳过标记为忽略的字段
-		if tag.Skip {
-			continue
-		}
-		
-		// 确定字段名
-		fieldName := field.Name
-		if tag.Name != "" {
-			fieldName = tag.Name
-		}
-		
-		// 检查是否为指针类型
-		isPtr := field.Type.Kind() == reflect.Ptr
-		fieldType := field.Type
-		if isPtr {
-			fieldType = field.Type.Elem()
-		}
-		
-		info.Fields[fieldName] = &customFieldInfo{
-			Index:  i,
-			Name:   fieldName,
-			Type:   fieldType,
-			Kind:   fieldType.Kind(),
-			IsPtr:  isPtr,
-			Offset: field.Offset,
-		}
-	}
-	
-	// 缓存结构体信息
-	if len(cp.structInfoCache) < StructCacheSize {
-		cp.structInfoCache[t] = info
-	}
-	
-	return info
-}
\ No newline at end of file
@@ -0,0 +1,288 @@
+package xyJson
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DebugInfo 诊断信息快照，包含对象池、路径缓存、性能监控和内存状态
+// DebugInfo is a diagnostics snapshot of the object pool, path cache, performance monitor and memory state
+type DebugInfo struct {
+	// Timestamp 采集时间
+	// Timestamp is the collection time
+	Timestamp time.Time
+
+	// Pool 默认对象池统计，默认对象池未设置时为nil
+	// Pool contains the default object pool statistics, nil when no default pool is set
+	Pool *PoolStats
+
+	// PathCacheSize 路径缓存当前大小
+	// PathCacheSize is the current size of the path cache
+	PathCacheSize int
+
+	// PathCacheMaxSize 路径缓存最大大小
+	// PathCacheMaxSize is the maximum size of the path cache
+	PathCacheMaxSize int
+
+	// CachedPaths 已缓存的路径（排序后）
+	// CachedPaths are the cached paths (sorted)
+	CachedPaths []string
+
+	// Monitor 全局性能监控器统计
+	// Monitor contains the global performance monitor statistics
+	Monitor PerformanceStats
+
+	// Memory 当前内存统计
+	// Memory contains the current memory statistics
+	Memory MemorySnapshot
+
+	// Snapshots 全局内存分析器保存的快照，分析器未运行过时为空
+	// Snapshots are the snapshots kept by the global memory profiler, empty if it never ran
+	Snapshots []MemorySnapshot
+}
+
+// CollectDebugInfo 采集当前的诊断信息
+// CollectDebugInfo collects the current diagnostics
+func CollectDebugInfo() *DebugInfo {
+	info := &DebugInfo{
+		Timestamp:   time.Now(),
+		CachedPaths: cachedPaths(),
+		Monitor:     GetGlobalMonitor().GetStats(),
+		Memory:      GetMemoryStats(),
+		Snapshots:   GetGlobalProfiler().GetSnapshots(),
+	}
+	info.PathCacheSize, info.PathCacheMaxSize = GetPathCacheStats()
+	if pool := GetDefaultPool(); pool != nil {
+		info.Pool = pool.GetStats()
+	}
+	return info
+}
+
+// cachedPaths 返回内置路径缓存中的所有路径（排序后），SetPathCache设置的缓存无法列出，返回nil
+// cachedPaths returns all paths in the built-in path cache (sorted), nil for a cache set by SetPathCache which
+// cannot be listed
+func cachedPaths() []string {
+	if customPathCache.Load() != nil {
+		return nil
+	}
+	globalPathCache.mu.RLock()
+	paths := make([]string, 0, len(globalPathCache.cache))
+	for path := range globalPathCache.cache {
+		paths = append(paths, path)
+	}
+	globalPathCache.mu.RUnlock()
+
+	sort.Strings(paths)
+	return paths
+}
+
+// ToValue 将诊断信息转换为JSON值，时间段以纳秒表示，字段名与PerformanceStats和MemorySnapshot的json标签一致
+// ToValue converts the diagnostics to a JSON value, durations are in nanoseconds and field names match the json
+// tags of PerformanceStats and MemorySnapshot
+func (di *DebugInfo) ToValue() IValue {
+	paths := make([]interface{}, len(di.CachedPaths))
+	for i, path := range di.CachedPaths {
+		paths[i] = path
+	}
+	snapshots := make([]interface{}, len(di.Snapshots))
+	for i, snapshot := range di.Snapshots {
+		snapshots[i] = memorySnapshotRaw(snapshot)
+	}
+
+	raw := map[string]interface{}{
+		"timestamp": di.Timestamp.Format(time.RFC3339Nano),
+		"pool":      nil,
+		"path_cache": map[string]interface{}{
+			"size":     di.PathCacheSize,
+			"max_size": di.PathCacheMaxSize,
+			"paths":    paths,
+		},
+		"monitor":   performanceStatsRaw(di.Monitor),
+		"memory":    memorySnapshotRaw(di.Memory),
+		"snapshots": snapshots,
+	}
+	if di.Pool != nil {
+		raw["pool"] = map[string]interface{}{
+			"total_allocated": di.Pool.TotalAllocated,
+			"total_reused":    di.Pool.TotalReused,
+			"current_in_use":  di.Pool.CurrentInUse,
+			"pool_hit_rate":   di.Pool.PoolHitRate,
+		}
+	}
+
+	value, err := defaultFactory().CreateFromRaw(raw)
+	if err != nil {
+		// 原始数据只包含基本类型，不会失败
+		// The raw data only holds basic types, this cannot fail
+		panic(err)
+	}
+	return value
+}
+
+// performanceStatsRaw 将性能统计转换为原始map
+// performanceStatsRaw converts performance statistics to a raw map
+func performanceStatsRaw(stats PerformanceStats) map[string]interface{} {
+	return map[string]interface{}{
+		"parse_count":          stats.ParseCount,
+		"serialize_count":      stats.SerializeCount,
+		"avg_parse_time":       int64(stats.AvgParseTime),
+		"avg_serialize_time":   int64(stats.AvgSerializeTime),
+		"total_parse_time":     int64(stats.TotalParseTime),
+		"total_serialize_time": int64(stats.TotalSerializeTime),
+		"alloc_count":          stats.AllocCount,
+		"alloc_bytes":          stats.AllocBytes,
+		"alloc_objects":        stats.AllocObjects,
+		"alloc_sample_rate":    stats.AllocSampleRate,
+		"gc_count":             int64(stats.GCCount),
+		"max_memory_usage":     stats.MaxMemoryUsage,
+		"current_memory_usage": stats.CurrentMemoryUsage,
+		"error_count":          stats.ErrorCount,
+		"uptime":               int64(stats.Uptime),
+		"enabled":              stats.Enabled,
+	}
+}
+
+// memorySnapshotRaw 将内存快照转换为原始map
+// memorySnapshotRaw converts a memory snapshot to a raw map
+func memorySnapshotRaw(snapshot MemorySnapshot) map[string]interface{} {
+	return map[string]interface{}{
+		"timestamp":       snapshot.Timestamp.Format(time.RFC3339Nano),
+		"alloc":           snapshot.Alloc,
+		"total_alloc":     snapshot.TotalAlloc,
+		"sys":             snapshot.Sys,
+		"num_gc":          int64(snapshot.NumGC),
+		"gc_cpu_fraction": snapshot.GCCPUFraction,
+		"heap_alloc":      snapshot.HeapAlloc,
+		"heap_sys":        snapshot.HeapSys,
+		"heap_inuse":      snapshot.HeapInuse,
+		"stack_inuse":     snapshot.StackInuse,
+	}
+}
+
+// DebugHandler 返回诊断页面的HTTP处理器，类似expvar和pprof，用于生产环境排查
+// DebugHandler returns an HTTP handler serving a diagnostics page, similar to expvar and pprof, for production
+// triage
+//
+// 默认返回HTML；查询参数format=json或Accept头包含application/json时返回JSON。
+// 页面会暴露缓存的路径等内部信息，不要在公网上注册
+// HTML is served by default; JSON is served for the format=json query parameter or an Accept header containing
+// application/json. The page exposes internals such as cached paths, do not register it publicly
+//
+// 示例 Example:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/debug/xyjson", xyJson.DebugHandler())
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(serveDebug)
+}
+
+// serveDebug 处理诊断请求
+// serveDebug handles a diagnostics request
+func serveDebug(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	info := CollectDebugInfo()
+	w.Header().Set("Cache-Control", "no-store")
+
+	if wantsJSON(r) {
+		data, err := PrettySerializer(DefaultIndent).Serialize(info.ToValue())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(data)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := debugPage.Execute(&buf, info); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// wantsJSON 检查请求是否要求JSON格式
+// wantsJSON checks whether the request asks for JSON
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// debugPage 诊断页面模板
+// debugPage is the diagnostics page template
+var debugPage = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>xyJson diagnostics</title>
+<style>
+body { font-family: monospace; margin: 1em 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>xyJson diagnostics</h1>
+<p>Collected at {{.Timestamp.Format "2006-01-02 15:04:05.000 MST"}} &middot; <a href="?format=json">JSON</a></p>
+
+<h2>Performance monitor</h2>
+<table>
+<tr><th>enabled</th><td>{{.Monitor.Enabled}}</td></tr>
+<tr><th>uptime</th><td>{{.Monitor.Uptime}}</td></tr>
+<tr><th>parse count</th><td>{{.Monitor.ParseCount}}</td></tr>
+<tr><th>avg parse time</th><td>{{.Monitor.AvgParseTime}}</td></tr>
+<tr><th>total parse time</th><td>{{.Monitor.TotalParseTime}}</td></tr>
+<tr><th>serialize count</th><td>{{.Monitor.SerializeCount}}</td></tr>
+<tr><th>avg serialize time</th><td>{{.Monitor.AvgSerializeTime}}</td></tr>
+<tr><th>total serialize time</th><td>{{.Monitor.TotalSerializeTime}}</td></tr>
+<tr><th>error count</th><td>{{.Monitor.ErrorCount}}</td></tr>
+<tr><th>alloc bytes</th><td>{{.Monitor.AllocBytes}}</td></tr>
+<tr><th>alloc objects</th><td>{{.Monitor.AllocObjects}}</td></tr>
+<tr><th>max memory usage</th><td>{{.Monitor.MaxMemoryUsage}}</td></tr>
+</table>
+
+<h2>Object pool</h2>
+{{with .Pool}}<table>
+<tr><th>total allocated</th><td>{{.TotalAllocated}}</td></tr>
+<tr><th>total reused</th><td>{{.TotalReused}}</td></tr>
+<tr><th>current in use</th><td>{{.CurrentInUse}}</td></tr>
+<tr><th>hit rate</th><td>{{printf "%.4f" .PoolHitRate}}</td></tr>
+</table>{{else}}<p>No default pool.</p>{{end}}
+
+<h2>Path cache ({{.PathCacheSize}} / {{.PathCacheMaxSize}})</h2>
+{{if .CachedPaths}}<table>
+{{range .CachedPaths}}<tr><td>{{.}}</td></tr>
+{{end}}</table>{{else}}<p>Empty.</p>{{end}}
+
+<h2>Memory</h2>
+<table>
+<tr><th>alloc</th><td>{{.Memory.Alloc}}</td></tr>
+<tr><th>total alloc</th><td>{{.Memory.TotalAlloc}}</td></tr>
+<tr><th>sys</th><td>{{.Memory.Sys}}</td></tr>
+<tr><th>heap alloc</th><td>{{.Memory.HeapAlloc}}</td></tr>
+<tr><th>heap in use</th><td>{{.Memory.HeapInuse}}</td></tr>
+<tr><th>stack in use</th><td>{{.Memory.StackInuse}}</td></tr>
+<tr><th>GC count</th><td>{{.Memory.NumGC}}</td></tr>
+</table>
+
+<h2>Memory snapshots</h2>
+{{if .Snapshots}}<table>
+<tr><th>time</th><th>alloc</th><th>heap in use</th><th>GC count</th></tr>
+{{range .Snapshots}}<tr><td>{{.Timestamp.Format "15:04:05.000"}}</td><td>{{.Alloc}}</td><td>{{.HeapInuse}}</td><td>{{.NumGC}}</td></tr>
+{{end}}</table>{{else}}<p>No snapshots; start the global memory profiler to record them.</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,139 @@
+package xyJson
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Dedupe 将结构相同的子树替换为同一个共享引用，返回root
+// Dedupe replaces structurally identical subtrees with a single shared reference and returns root
+//
+// 适用于大量重复内容的文档（例如共享相同元数据块的遥测批次），可显著降低内存占用。
+// 替换在原文档上进行；之后修改任一共享子树会影响所有引用它的位置，需要独立修改时请先Clone。
+// 数字按原始Go类型区分（int64与float64不会合并），带有元数据（SetMeta）的值不会被合并
+// Useful for documents with heavy repetition, such as telemetry batches sharing metadata blocks. The document
+// is modified in place; afterwards mutating a shared subtree affects every place referencing it, so Clone
+// first when independent mutation is needed. Numbers are distinguished by their Go type (int64 and float64
+// are never merged) and values carrying metadata (SetMeta) are never merged
+//
+// 示例 Example:
+//
+//	batch := xyJson.MustParse(data)
+//	xyJson.Dedupe(batch)
+func Dedupe(root IValue) IValue {
+	if root == nil {
+		return nil
+	}
+	d := &deduper{
+		ids:   make(map[IValue]int),
+		canon: make(map[string]IValue),
+	}
+	return d.visit(root, 0)
+}
+
+// deduper 自底向上为每个子树计算结构键并合并相同的子树
+// deduper computes a structural key for every subtree bottom-up and merges identical ones
+type deduper struct {
+	// ids 规范节点的编号，父节点的结构键由子节点编号组成
+	// ids numbers canonical nodes, the structural key of a parent is built from its children's numbers
+	ids map[IValue]int
+
+	// canon 结构键到规范节点的映射
+	// canon maps structural keys to canonical nodes
+	canon map[string]IValue
+}
+
+// visit 对子树去重并返回其规范节点
+// visit deduplicates a subtree and returns its canonical node
+func (d *deduper) visit(value IValue, depth int) IValue {
+	if _, ok := d.ids[value]; ok {
+		return value
+	}
+	if depth > DefaultMaxDepth {
+		return d.unique(value)
+	}
+
+	var key []byte
+	switch v := value.(type) {
+	case IObject:
+		keys := v.Keys()
+		sort.Strings(keys)
+		key = append(key, 'o')
+		for _, k := range keys {
+			child := v.Get(k)
+			if child == nil {
+				continue
+			}
+			shared := d.visit(child, depth+1)
+			if shared != child {
+				v.Set(k, shared)
+			}
+			key = strconv.AppendInt(key, int64(len(k)), 10)
+			key = append(key, ':')
+			key = append(key, k...)
+			key = strconv.AppendInt(key, int64(d.ids[shared]), 10)
+			key = append(key, ',')
+		}
+
+	case IArray:
+		key = append(key, 'a')
+		for i := 0; i < v.Length(); i++ {
+			child := v.Get(i)
+			if child == nil {
+				continue
+			}
+			shared := d.visit(child, depth+1)
+			if shared != child {
+				v.Set(i, shared)
+			}
+			key = strconv.AppendInt(key, int64(d.ids[shared]), 10)
+			key = append(key, ',')
+		}
+
+	default:
+		var ok bool
+		if key, ok = scalarDedupeKey(value); !ok {
+			return d.unique(value)
+		}
+	}
+
+	if len(value.MetaKeys()) > 0 {
+		return d.unique(value)
+	}
+	if shared, ok := d.canon[string(key)]; ok {
+		return shared
+	}
+	d.canon[string(key)] = value
+	return d.unique(value)
+}
+
+// unique 为不参与合并或首次出现的节点分配编号
+// unique assigns a number to a node that is not merged or seen for the first time
+func (d *deduper) unique(value IValue) IValue {
+	d.ids[value] = len(d.ids) + 1
+	return value
+}
+
+// scalarDedupeKey 返回标量的结构键，无法识别的原始类型返回false
+// scalarDedupeKey returns the structural key of a scalar, false for unrecognized raw types
+func scalarDedupeKey(value IValue) ([]byte, bool) {
+	switch value.Type() {
+	case NullValueType:
+		return []byte{'n'}, true
+	case BoolValueType:
+		if value.AsBool() {
+			return []byte{'t'}, true
+		}
+		return []byte{'f'}, true
+	case StringValueType:
+		return append([]byte{'s'}, value.String()...), true
+	case NumberValueType:
+		switch raw := value.Raw().(type) {
+		case int64:
+			return strconv.AppendInt([]byte{'i'}, raw, 10), true
+		case float64:
+			return strconv.AppendFloat([]byte{'d'}, raw, 'g', -1, 64), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,255 @@
+package xyJson
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Defaults 包级函数使用的默认实例快照，创建后不可修改
+// Defaults is an immutable snapshot of the default instances used by package-level functions
+//
+// 包级函数每次调用都原子地读取当前快照，因此替换默认实例与并发的Parse/Serialize调用之间没有数据竞争
+// Package-level functions load the current snapshot atomically on every call, so replacing the defaults does
+// not race with concurrent Parse/Serialize calls
+type Defaults struct {
+	factory    IValueFactory
+	parser     IParser
+	serializer ISerializer
+	pathQuery  IPathQuery
+
+	// pool factory使用的对象池，工厂不是NewValueFactory系列创建时为nil
+	// pool is the object pool used by factory, nil when the factory was not created by the NewValueFactory family
+	pool IObjectPool
+
+	// parserPool Parse和ParseString使用的解析器池
+	// parserPool is the pool of parsers used by Parse and ParseString
+	parserPool *sync.Pool
+
+	// safeMode 公共入口是否将panic转换为错误，见WithSafeMode
+	// safeMode indicates whether the public entry points convert panics into errors, see WithSafeMode
+	safeMode bool
+
+	// failureRecorder 包级解析失败时使用的记录器，nil表示不记录，见WithFailureRecorder
+	// failureRecorder is the recorder used when package-level parses fail, nil means nothing is recorded, see
+	// WithFailureRecorder
+	failureRecorder *FailureRecorder
+}
+
+// currentDefaults 当前生效的默认实例快照
+// currentDefaults is the snapshot of the default instances currently in effect
+var currentDefaults atomic.Pointer[Defaults]
+
+func init() {
+	pool := NewObjectPool()
+	factory := NewValueFactoryWithPool(pool)
+	currentDefaults.Store(&Defaults{
+		factory:    factory,
+		parser:     NewParserWithFactory(factory),
+		serializer: NewSerializer(),
+		pathQuery:  NewPathQueryWithFactory(factory),
+		pool:       pool,
+		parserPool: newParserPool(factory),
+	})
+}
+
+// CurrentDefaults 返回当前的默认实例快照
+// CurrentDefaults returns the current snapshot of the default instances
+func CurrentDefaults() *Defaults {
+	return currentDefaults.Load()
+}
+
+// WithDefaults 安装新的默认实例快照，返回恢复之前快照的函数
+// WithDefaults installs a new snapshot of default instances, returning a function that restores the previous one
+//
+// 主要用于测试中临时替换默认实例。替换对所有goroutine生效，嵌套调用应按相反顺序恢复
+// Mainly used to temporarily replace the defaults in tests. The replacement applies to all goroutines,
+// nested calls should restore in reverse order
+//
+// 示例 Example:
+//
+//	d := xyJson.CurrentDefaults().WithSerializer(xyJson.NewSerializerWithOptions(opts))
+//	t.Cleanup(xyJson.WithDefaults(d))
+func WithDefaults(d *Defaults) (restore func()) {
+	if d == nil {
+		return func() {}
+	}
+	previous := currentDefaults.Swap(d)
+	return func() {
+		currentDefaults.Store(previous)
+	}
+}
+
+// Factory 返回默认工厂
+// Factory returns the default factory
+func (d *Defaults) Factory() IValueFactory {
+	return d.factory
+}
+
+// Parser 返回默认解析器
+// Parser returns the default parser
+func (d *Defaults) Parser() IParser {
+	return d.parser
+}
+
+// Serializer 返回默认序列化器
+// Serializer returns the default serializer
+func (d *Defaults) Serializer() ISerializer {
+	return d.serializer
+}
+
+// PathQuery 返回默认路径查询器
+// PathQuery returns the default path query
+func (d *Defaults) PathQuery() IPathQuery {
+	return d.pathQuery
+}
+
+// Pool 返回默认工厂使用的对象池，未知时返回nil
+// Pool returns the object pool used by the default factory, nil when unknown
+func (d *Defaults) Pool() IObjectPool {
+	return d.pool
+}
+
+// WithFactory 返回使用新工厂的副本，解析器和路径查询器随之重建
+// WithFactory returns a copy using a new factory, rebuilding the parser and path query with it
+func (d *Defaults) WithFactory(factory IValueFactory) *Defaults {
+	next := *d
+	if factory != nil {
+		next.factory = factory
+		next.parser = NewParserWithFactory(factory)
+		next.pathQuery = NewPathQueryWithFactory(factory)
+		next.pool = nil
+		if vf, ok := factory.(*valueFactory); ok {
+			next.pool = vf.pool
+		}
+		next.parserPool = newParserPool(factory)
+	}
+	return &next
+}
+
+// WithPool 返回使用新对象池的副本，工厂以该对象池重建并保留其它工厂选项，解析器和路径查询器随之重建
+// WithPool returns a copy using a new object pool, rebuilding the factory on the pool while keeping the other
+// factory options, and the parser and path query with it
+func (d *Defaults) WithPool(pool IObjectPool) *Defaults {
+	if pool == nil {
+		next := *d
+		return &next
+	}
+	factory := NewValueFactoryWithPool(pool)
+	if vf, ok := d.factory.(*valueFactory); ok {
+		copied := *vf
+		copied.pool = pool
+		factory = &copied
+	}
+	return d.WithFactory(factory)
+}
+
+// WithParser 返回使用新解析器的副本，包级的Parse、ParseString和ParseFromMap也使用该解析器
+// WithParser returns a copy using a new parser, which the package-level Parse, ParseString and ParseFromMap
+// use as well
+//
+// 解析器不保证可以并发使用，因此这些调用通过该解析器串行执行；需要并发解析时应使用WithFactory
+// Parsers are not guaranteed to be safe for concurrent use, so those calls run through the parser one at a
+// time; use WithFactory when concurrent parsing is needed
+func (d *Defaults) WithParser(parser IParser) *Defaults {
+	next := *d
+	if parser != nil {
+		next.parser = parser
+		shared := &lockedParser{IParser: parser}
+		next.parserPool = &sync.Pool{
+			New: func() interface{} {
+				return shared
+			},
+		}
+	}
+	return &next
+}
+
+// lockedParser 串行执行对共享解析器的解析调用
+// lockedParser runs the parse calls on a shared parser one at a time
+type lockedParser struct {
+	IParser
+	mu sync.Mutex
+}
+
+// Parse 加锁后解析JSON数据
+// Parse parses JSON data under the lock
+func (lp *lockedParser) Parse(data []byte) (IValue, error) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return lp.IParser.Parse(data)
+}
+
+// ParseString 加锁后解析JSON字符串
+// ParseString parses a JSON string under the lock
+func (lp *lockedParser) ParseString(jsonStr string) (IValue, error) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return lp.IParser.ParseString(jsonStr)
+}
+
+// ParseFromMap 加锁后从map解析
+// ParseFromMap parses from a map under the lock
+func (lp *lockedParser) ParseFromMap(data map[string]interface{}) (IValue, error) {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+	return lp.IParser.ParseFromMap(data)
+}
+
+// WithSerializer 返回使用新序列化器的副本
+// WithSerializer returns a copy using a new serializer
+func (d *Defaults) WithSerializer(serializer ISerializer) *Defaults {
+	next := *d
+	if serializer != nil {
+		next.serializer = serializer
+	}
+	return &next
+}
+
+// WithPathQuery 返回使用新路径查询器的副本
+// WithPathQuery returns a copy using a new path query
+func (d *Defaults) WithPathQuery(pathQuery IPathQuery) *Defaults {
+	next := *d
+	if pathQuery != nil {
+		next.pathQuery = pathQuery
+	}
+	return &next
+}
+
+// updateDefaults 原子地基于当前快照生成并安装新快照
+// updateDefaults atomically derives a new snapshot from the current one and installs it
+func updateDefaults(derive func(d *Defaults) *Defaults) {
+	for {
+		current := currentDefaults.Load()
+		if currentDefaults.CompareAndSwap(current, derive(current)) {
+			return
+		}
+	}
+}
+
+// newParserPool 创建使用指定工厂的解析器池
+// newParserPool creates a parser pool using the given factory
+func newParserPool(factory IValueFactory) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			return NewParserWithFactory(factory)
+		},
+	}
+}
+
+// defaultFactory 返回当前默认工厂
+// defaultFactory returns the current default factory
+func defaultFactory() IValueFactory {
+	return currentDefaults.Load().factory
+}
+
+// defaultSerializer 返回当前默认序列化器
+// defaultSerializer returns the current default serializer
+func defaultSerializer() ISerializer {
+	return currentDefaults.Load().serializer
+}
+
+// defaultPathQuery 返回当前默认路径查询器
+// defaultPathQuery returns the current default path query
+func defaultPathQuery() IPathQuery {
+	return currentDefaults.Load().pathQuery
+}
@@ -0,0 +1,138 @@
+package xyJson
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// discriminator 接口类型的判别字段及其取值到具体类型的映射
+// discriminator is the discriminator field of an interface type and its mapping from values to concrete types
+type discriminator struct {
+	iface reflect.Type
+	field string
+	types map[string]reflect.Type
+}
+
+var (
+	// discriminators 已注册的判别字段，按接口类型索引
+	// discriminators holds the registered discriminators, keyed by interface type
+	discriminators     = make(map[reflect.Type]*discriminator)
+	discriminatorMutex sync.RWMutex
+)
+
+// RegisterDiscriminator 为接口类型注册判别字段，解码到该接口时根据对象中field的字符串值选择具体类型
+// RegisterDiscriminator registers a discriminator field for an interface type; decoding into the interface
+// picks the concrete type from the string value of field in the object
+//
+// 适用于SerializeToStruct、Unmarshal和DecodeStrictInto，包括接口类型的字段、切片元素和map值。
+// 具体类型本身未实现接口而其指针实现时，解码结果为指针。重复注册同一接口会替换之前的注册，
+// types为空时删除注册。判别字段仍会作为普通字段写入具体类型
+// Applies to SerializeToStruct, Unmarshal and DecodeStrictInto, including interface-typed fields, slice
+// elements and map values. When a concrete type does not implement the interface but its pointer does, the
+// decoded value is a pointer. Registering the same interface again replaces the earlier registration, and an
+// empty types map removes it. The discriminator field is still written into the concrete type as a normal
+// field
+//
+// 示例 Example:
+//
+//	type Shape interface{ Area() float64 }
+//	xyJson.RegisterDiscriminator(reflect.TypeOf((*Shape)(nil)).Elem(), "type", map[string]reflect.Type{
+//		"circle": reflect.TypeOf(Circle{}),
+//		"rect":   reflect.TypeOf(Rect{}),
+//	})
+//	var drawing struct{ Shapes []Shape `json:"shapes"` }
+//	err := xyJson.UnmarshalString(`{"shapes":[{"type":"circle","r":1},{"type":"rect","w":2,"h":3}]}`, &drawing)
+func RegisterDiscriminator(interfaceType reflect.Type, field string, types map[string]reflect.Type) error {
+	if interfaceType == nil || interfaceType.Kind() != reflect.Interface {
+		return NewInvalidOperationError("register discriminator", "type must be an interface type")
+	}
+
+	discriminatorMutex.Lock()
+	defer discriminatorMutex.Unlock()
+
+	if len(types) == 0 {
+		delete(discriminators, interfaceType)
+		return nil
+	}
+	if field == "" {
+		return NewInvalidOperationError("register discriminator", "field cannot be empty")
+	}
+
+	d := &discriminator{iface: interfaceType, field: field, types: make(map[string]reflect.Type, len(types))}
+	for value, t := range types {
+		if t == nil {
+			return NewInvalidOperationError("register discriminator", "nil type for value '"+value+"'")
+		}
+		if !t.Implements(interfaceType) && !reflect.PointerTo(t).Implements(interfaceType) {
+			return NewInvalidOperationError("register discriminator",
+				t.String()+" does not implement "+interfaceType.String())
+		}
+		d.types[value] = t
+	}
+	discriminators[interfaceType] = d
+	return nil
+}
+
+// lookupDiscriminator 返回接口类型的判别字段注册，未注册时返回nil
+// lookupDiscriminator returns the discriminator registered for an interface type, nil if none
+func lookupDiscriminator(t reflect.Type) *discriminator {
+	discriminatorMutex.RLock()
+	defer discriminatorMutex.RUnlock()
+	return discriminators[t]
+}
+
+// resolve 根据对象的判别字段返回具体类型
+// resolve returns the concrete type selected by the discriminator field of the object
+func (d *discriminator) resolve(value IValue) (reflect.Type, *JSONError) {
+	obj, ok := value.(IObject)
+	if !ok || value.Type() != ObjectValueType {
+		return nil, NewTypeMismatchError(ObjectValueType, value.Type(), "")
+	}
+
+	field := obj.Get(d.field)
+	if field == nil {
+		return nil, NewKeyNotFoundError(d.field, "").WithContext("discriminator of " + d.iface.String())
+	}
+	name, ok := field.AsStringOK()
+	if !ok {
+		return nil, NewTypeMismatchError(StringValueType, field.Type(), "").
+			WithContext("discriminator '" + d.field + "' of " + d.iface.String())
+	}
+
+	t, exists := d.types[name]
+	if !exists {
+		known := make([]string, 0, len(d.types))
+		for key := range d.types {
+			known = append(known, key)
+		}
+		sort.Strings(known)
+		return nil, NewJSONError(ErrTypeMismatch, "unknown discriminator value '"+name+"' for "+d.iface.String(), nil).
+			WithContext("known values: " + joinQuoted(known))
+	}
+	return t, nil
+}
+
+// assign 将解码后的具体值写入接口目标，具体类型未实现接口时使用其指针
+// assign writes a decoded concrete value into the interface target, using its pointer when the concrete type
+// does not implement the interface
+func (d *discriminator) assign(rv reflect.Value, concrete reflect.Value) {
+	if concrete.Type().Implements(d.iface) {
+		rv.Set(concrete)
+		return
+	}
+	rv.Set(concrete.Addr())
+}
+
+// joinQuoted 用逗号连接带引号的字符串
+// joinQuoted joins quoted strings with commas
+func joinQuoted(values []string) string {
+	result := ""
+	for i, value := range values {
+		if i > 0 {
+			result += ", "
+		}
+		result += "'" + value + "'"
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package xyJson
+
+import "reflect"
+
+var (
+	// iobjectType 预缓存的IObject接口类型
+	// iobjectType is the cached IObject interface type
+	iobjectType = reflect.TypeOf((*IObject)(nil)).Elem()
+
+	// iarrayType 预缓存的IArray接口类型
+	// iarrayType is the cached IArray interface type
+	iarrayType = reflect.TypeOf((*IArray)(nil)).Elem()
+)
+
+// isDocumentType 检查类型是否为IValue、IObject或IArray
+// isDocumentType checks whether the type is IValue, IObject or IArray
+func isDocumentType(t reflect.Type) bool {
+	return t == ivalueType || t == iobjectType || t == iarrayType
+}
+
+// setDocumentValue 将子树直接写入IValue、IObject或IArray类型的目标，不转换为Raw()
+// setDocumentValue writes a subtree directly into an IValue, IObject or IArray target, without converting it
+// to Raw()
+//
+// 目标与源文档共享节点，修改其中一方会影响另一方，需要独立副本时应调用Clone。
+// IValue目标也接收null节点，以区分显式null和缺失的字段；IObject和IArray目标遇到null时为nil
+// The target shares nodes with the source document, so modifying one affects the other; call Clone when an
+// independent copy is needed. IValue targets receive null nodes too, distinguishing explicit null from an
+// absent field; IObject and IArray targets are nil for null
+func setDocumentValue(rv reflect.Value, value IValue, targetType reflect.Type) error {
+	switch targetType {
+	case iobjectType:
+		if value.IsNull() {
+			rv.Set(reflect.Zero(targetType))
+			return nil
+		}
+		obj, ok := value.(IObject)
+		if !ok || value.Type() != ObjectValueType {
+			return NewTypeMismatchError(ObjectValueType, value.Type(), "")
+		}
+		rv.Set(reflect.ValueOf(&obj).Elem())
+	case iarrayType:
+		if value.IsNull() {
+			rv.Set(reflect.Zero(targetType))
+			return nil
+		}
+		arr, ok := value.(IArray)
+		if !ok || value.Type() != ArrayValueType {
+			return NewTypeMismatchError(ArrayValueType, value.Type(), "")
+		}
+		rv.Set(reflect.ValueOf(&arr).Elem())
+	default:
+		rv.Set(reflect.ValueOf(&value).Elem())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package xyJson
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// decodeInput 去除UTF-8 BOM，并将UTF-16LE/BE输入转码为UTF-8
+// decodeInput strips a UTF-8 BOM and transcodes UTF-16LE/BE input to UTF-8
+//
+// UTF-16按BOM识别，没有BOM时按RFC 4627的方式根据前两个字符中的零字节识别。
+// UTF-32输入返回错误。普通UTF-8输入原样返回，不产生复制
+// UTF-16 is recognized by its BOM, or without one by the zero bytes of the first two characters as allowed by
+// RFC 4627. UTF-32 input returns an error. Plain UTF-8 input is returned as is without copying
+func decodeInput(data []byte) ([]byte, error) {
+	if len(data) < 2 || (data[0] != 0xEF && data[0] != 0xFE && data[0] != 0xFF && data[0] != 0 && data[1] != 0) {
+		return data, nil
+	}
+
+	switch {
+	case len(data) >= 3 && data[0] == 0xEF && data[1] == 0xBB && data[2] == 0xBF:
+		return data[3:], nil
+	case len(data) >= 4 && data[0] == 0xFF && data[1] == 0xFE && data[2] == 0 && data[3] == 0,
+		len(data) >= 4 && data[0] == 0 && data[1] == 0 && data[2] == 0xFE && data[3] == 0xFF:
+		return nil, NewInvalidJSONError("unsupported encoding: UTF-32", nil)
+	case data[0] == 0xFF && data[1] == 0xFE:
+		return decodeUTF16(data[2:], binary.LittleEndian)
+	case data[0] == 0xFE && data[1] == 0xFF:
+		return decodeUTF16(data[2:], binary.BigEndian)
+	}
+
+	if len(data) >= 4 {
+		switch {
+		case data[0] == 0 && data[1] == 0 && data[2] == 0 && data[3] != 0,
+			data[0] != 0 && data[1] == 0 && data[2] == 0 && data[3] == 0:
+			return nil, NewInvalidJSONError("unsupported encoding: UTF-32", nil)
+		case data[0] == 0 && data[1] != 0 && data[2] == 0 && data[3] != 0:
+			return decodeUTF16(data, binary.BigEndian)
+		case data[0] != 0 && data[1] == 0 && data[2] != 0 && data[3] == 0:
+			return decodeUTF16(data, binary.LittleEndian)
+		}
+	}
+	if data[0] == 0 && data[1] != 0 {
+		return decodeUTF16(data, binary.BigEndian)
+	}
+	if data[0] != 0 && data[1] == 0 && len(data) == 2 {
+		return decodeUTF16(data, binary.LittleEndian)
+	}
+	return data, nil
+}
+
+// decodeUTF16 将UTF-16字节序列转码为UTF-8，未配对的代理项替换为U+FFFD
+// decodeUTF16 transcodes a UTF-16 byte sequence to UTF-8, replacing unpaired surrogates with U+FFFD
+func decodeUTF16(data []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, NewInvalidJSONError("invalid UTF-16 input: odd number of bytes", nil)
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+
+	out := make([]byte, 0, len(data))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,302 @@
+package xyJson
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix 加密字段信封的前缀，格式为 xyenc:v1:<keyID>:<base64密文>
+// encryptedPrefix is the prefix of encrypted field envelopes, formatted as xyenc:v1:<keyID>:<base64 ciphertext>
+const encryptedPrefix = "xyenc:v1:"
+
+// FieldCipher 字段加密算法，可插拔实现
+// FieldCipher is a pluggable field encryption algorithm
+//
+// Encrypt返回的keyID与密文一起保存在信封中，解密时原样传回Decrypt，用于密钥轮换
+// The keyID returned by Encrypt is stored in the envelope with the ciphertext and passed back to Decrypt
+// unchanged, for key rotation
+type FieldCipher interface {
+	// Encrypt 加密明文，返回所用密钥的标识和密文
+	// Encrypt encrypts the plaintext, returning the identifier of the key used and the ciphertext
+	Encrypt(plaintext []byte) (keyID string, ciphertext []byte, err error)
+
+	// Decrypt 使用keyID标识的密钥解密密文
+	// Decrypt decrypts the ciphertext with the key identified by keyID
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// AESGCMCipher 基于AES-GCM的FieldCipher，使用当前密钥加密，按密钥标识解密
+// AESGCMCipher is an AES-GCM FieldCipher that encrypts with the active key and decrypts by key identifier
+//
+// 轮换密钥时创建包含新旧密钥、以新密钥为当前密钥的AESGCMCipher，依次调用DecryptPaths和EncryptPaths
+// To rotate keys, create an AESGCMCipher holding both old and new keys with the new one active, then call
+// DecryptPaths followed by EncryptPaths
+type AESGCMCipher struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewAESGCMCipher 创建AES-GCM字段加密算法，keys为密钥标识到16、24或32字节密钥的映射
+// NewAESGCMCipher creates an AES-GCM field cipher, keys maps key identifiers to 16, 24 or 32 byte keys
+//
+// 示例 Example:
+//
+//	c, err := xyJson.NewAESGCMCipher("2024-06", map[string][]byte{
+//		"2024-01": oldKey,
+//		"2024-06": newKey,
+//	})
+func NewAESGCMCipher(activeKeyID string, keys map[string][]byte) (*AESGCMCipher, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, NewKeyNotFoundError(activeKeyID, "").WithContext("active key of AES-GCM cipher")
+	}
+
+	c := &AESGCMCipher{active: activeKeyID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, NewEncryptionError("create cipher for key '"+id+"'", "", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, NewEncryptionError("create cipher for key '"+id+"'", "", err)
+		}
+		c.aeads[id] = aead
+	}
+	return c, nil
+}
+
+// Encrypt 使用当前密钥加密，密文由随机nonce和密封数据组成，密钥标识作为附加认证数据
+// Encrypt encrypts with the active key; the ciphertext is a random nonce followed by the sealed data, with the
+// key identifier as additional authenticated data
+func (c *AESGCMCipher) Encrypt(plaintext []byte) (string, []byte, error) {
+	aead := c.aeads[c.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return c.active, aead.Seal(nonce, nonce, plaintext, []byte(c.active)), nil
+}
+
+// Decrypt 使用keyID标识的密钥解密
+// Decrypt decrypts with the key identified by keyID
+func (c *AESGCMCipher) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return nil, errors.New("unknown key '" + keyID + "'")
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, []byte(keyID))
+}
+
+// EncryptPaths 将路径匹配的子树替换为加密信封字符串 xyenc:v1:<keyID>:<base64密文>
+// EncryptPaths replaces the subtrees matched by the paths with encrypted envelope strings
+// xyenc:v1:<keyID>:<base64 ciphertext>
+//
+// 子树以紧凑JSON加密，解密后恢复原始类型。路径支持通配符和过滤器，不匹配任何值的路径被忽略。
+// 已经加密的值不会再次加密；匹配的值位于另一个匹配值内部时只加密外层。根节点不能加密。
+// 通过Dedupe共享的子树在每个引用位置都会被替换
+// Subtrees are encrypted as compact JSON, so decryption restores their original types. Paths may use
+// wildcards and filters, and paths matching nothing are ignored. Values that are already encrypted are not
+// encrypted again, and when a matched value lies inside another matched value only the outer one is
+// encrypted. The root cannot be encrypted. Subtrees shared through Dedupe are replaced at every position
+// referencing them
+//
+// 示例 Example:
+//
+//	err := xyJson.EncryptPaths(record, []string{"$.user.ssn", "$.cards[*].number"}, c)
+func EncryptPaths(root IValue, paths []string, c FieldCipher) error {
+	if root == nil {
+		return NewNullPointerError("root cannot be nil")
+	}
+	if c == nil {
+		return NewNullPointerError("cipher cannot be nil")
+	}
+
+	locations, err := cipherLocations(root, paths, func(value IValue) bool {
+		_, encrypted := EncryptedKeyID(value)
+		return !encrypted
+	})
+	if err != nil {
+		return err
+	}
+
+	var outer string
+	for _, location := range locations {
+		if outer != "" && isDescendantPath(location, outer) {
+			continue
+		}
+		outer = location
+
+		value, err := defaultPathQuery().SelectOne(root, location)
+		if err != nil {
+			return err
+		}
+		plaintext, err := Serialize(value)
+		if err != nil {
+			return err
+		}
+		keyID, ciphertext, err := c.Encrypt(plaintext)
+		if err != nil {
+			return NewEncryptionError("encrypt", location, err)
+		}
+		envelope := encryptedPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+		if err := defaultPathQuery().Set(root, location, defaultFactory().CreateString(envelope)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecryptPaths 解密路径匹配的加密信封，恢复原始子树；匹配到的未加密值保持不变
+// DecryptPaths decrypts the encrypted envelopes matched by the paths, restoring the original subtrees;
+// matched values that are not encrypted are left unchanged
+//
+// 示例 Example:
+//
+//	err := xyJson.DecryptPaths(record, []string{"$.user.ssn", "$.cards[*].number"}, c)
+func DecryptPaths(root IValue, paths []string, c FieldCipher) error {
+	if root == nil {
+		return NewNullPointerError("root cannot be nil")
+	}
+	if c == nil {
+		return NewNullPointerError("cipher cannot be nil")
+	}
+
+	locations, err := cipherLocations(root, paths, func(value IValue) bool {
+		_, encrypted := EncryptedKeyID(value)
+		return encrypted
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, location := range locations {
+		value, err := defaultPathQuery().SelectOne(root, location)
+		if err != nil {
+			return err
+		}
+		keyID, encoded := splitEnvelope(value.AsString())
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return NewEncryptionError("decode", location, err)
+		}
+		plaintext, err := c.Decrypt(keyID, ciphertext)
+		if err != nil {
+			return NewEncryptionError("decrypt", location, err)
+		}
+		decrypted, err := Parse(plaintext)
+		if err != nil {
+			return NewEncryptionError("decrypt", location, err)
+		}
+		if err := defaultPathQuery().Set(root, location, decrypted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SerializeEncrypted 序列化文档的副本，路径匹配的子树被加密，root本身不被修改
+// SerializeEncrypted serializes a copy of the document with the subtrees matched by the paths encrypted,
+// leaving root itself unchanged
+//
+// 示例 Example:
+//
+//	data, err := xyJson.SerializeEncrypted(record, []string{"$.user.ssn"}, c)
+func SerializeEncrypted(root IValue, paths []string, c FieldCipher) ([]byte, error) {
+	if root == nil {
+		return nil, NewNullPointerError("root cannot be nil")
+	}
+	clone := root.Clone()
+	if err := EncryptPaths(clone, paths, c); err != nil {
+		return nil, err
+	}
+	return Serialize(clone)
+}
+
+// ParseDecrypted 解析JSON并解密路径匹配的加密信封
+// ParseDecrypted parses JSON and decrypts the encrypted envelopes matched by the paths
+//
+// 示例 Example:
+//
+//	record, err := xyJson.ParseDecrypted(data, []string{"$.user.ssn"}, c)
+func ParseDecrypted(data []byte, paths []string, c FieldCipher) (IValue, error) {
+	root, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := DecryptPaths(root, paths, c); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// EncryptedKeyID 返回加密信封使用的密钥标识，value不是加密信封时返回false；可用于查找需要轮换的字段
+// EncryptedKeyID returns the key identifier of an encrypted envelope, false when value is not an envelope;
+// useful for finding fields that need rotating
+//
+// 示例 Example:
+//
+//	stale := xyJson.FindPaths(record, func(v xyJson.IValue) bool {
+//		keyID, ok := xyJson.EncryptedKeyID(v)
+//		return ok && keyID != "2024-06"
+//	})
+func EncryptedKeyID(value IValue) (string, bool) {
+	if value == nil || value.Type() != StringValueType {
+		return "", false
+	}
+	s := value.AsString()
+	if !strings.HasPrefix(s, encryptedPrefix) || !strings.Contains(s[len(encryptedPrefix):], ":") {
+		return "", false
+	}
+	keyID, _ := splitEnvelope(s)
+	return keyID, true
+}
+
+// splitEnvelope 将信封拆分为密钥标识和base64密文；base64不含冒号，因此按最后一个冒号拆分
+// splitEnvelope splits an envelope into key identifier and base64 ciphertext; base64 never contains a colon,
+// so it splits at the last one
+func splitEnvelope(envelope string) (keyID, encoded string) {
+	rest := envelope[len(encryptedPrefix):]
+	i := strings.LastIndexByte(rest, ':')
+	return rest[:i], rest[i+1:]
+}
+
+// cipherLocations 返回路径匹配且满足accept的值在文档中的具体路径，按深度优先先序排列
+// cipherLocations returns the concrete paths of the values matched by the paths and accepted by accept, in
+// depth-first pre-order
+func cipherLocations(root IValue, paths []string, accept func(IValue) bool) ([]string, error) {
+	targets := make(map[IValue]bool)
+	for _, path := range paths {
+		if path == "" || path == "$" {
+			return nil, NewInvalidOperationError("encrypt paths", "the root cannot be encrypted")
+		}
+		matches, err := defaultPathQuery().SelectAll(root, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if match != root && accept(match) {
+				targets[match] = true
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	return FindPaths(root, func(value IValue) bool { return targets[value] }), nil
+}
+
+// isDescendantPath 检查path是否位于ancestor之下
+// isDescendantPath checks whether path lies below ancestor
+func isDescendantPath(path, ancestor string) bool {
+	return len(path) > len(ancestor) && strings.HasPrefix(path, ancestor) &&
+		(path[len(ancestor)] == '.' || path[len(ancestor)] == '[')
+}
@@ -0,0 +1,321 @@
+package xyJson
+
+import (
+	"sync"
+)
+
+// EngineOptions 引擎配置选项
+// EngineOptions represents engine configuration options
+type EngineOptions struct {
+	// Serialize 序列化选项，nil表示使用NewSerializer的默认选项
+	// Serialize is the serialization options, nil means the defaults of NewSerializer
+	Serialize *SerializeOptions
+
+	// ObjectPool 对象池选项，nil表示使用DefaultObjectPoolOptions
+	// ObjectPool is the object pool options, nil means DefaultObjectPoolOptions
+	ObjectPool *ObjectPoolOptions
+
+	// MaxDepth 最大解析深度，0表示使用解析器默认值
+	// MaxDepth is the maximum parsing depth, 0 means the parser default
+	MaxDepth int
+
+	// EnableMonitoring 是否启用引擎自己的性能监控器
+	// EnableMonitoring indicates whether the engine's own performance monitor is enabled
+	EnableMonitoring bool
+
+	// StrictArrayIndex 引擎的Set在超出数组长度的索引上失败而不是用null填充，取代全局配置的JSONPath.StrictArrayIndex
+	// StrictArrayIndex makes the engine's Set fail on an index beyond the array length instead of padding with
+	// nulls, replacing JSONPath.StrictArrayIndex of the global config
+	StrictArrayIndex bool
+}
+
+// DefaultEngineOptions 返回默认引擎选项
+// DefaultEngineOptions returns default engine options
+func DefaultEngineOptions() *EngineOptions {
+	return &EngineOptions{
+		ObjectPool:       DefaultObjectPoolOptions(),
+		EnableMonitoring: true,
+	}
+}
+
+// Engine 独立的xyJson实例，拥有自己的工厂、解析器、序列化器、路径查询器、对象池和性能监控器
+// Engine is a self-contained xyJson instance owning its factory, parsers, serializer, path query, object pool
+// and performance monitor
+//
+// Engine的方法不读取也不修改包级默认实例、全局配置、全局监控器和全局路径缓存，因此同一进程中的多个库可以
+// 安全地使用不同配置。包级函数（例如ParseFile、ParseWithOptions、SetRaw和CompilePath）以及通过WithDefaults
+// 使用引擎实例的包级函数仍然使用这些全局状态。Engine的方法可以并发调用
+// Engine methods neither read nor modify the package-level defaults, the global config, the global monitor
+// and the global path cache, so several libraries in one process can safely use different configurations.
+// Package-level functions (such as ParseFile, ParseWithOptions, SetRaw and CompilePath), including those using
+// the engine's instances through WithDefaults, still use that global state. Engine methods are safe for
+// concurrent use
+//
+// 示例 Example:
+//
+//	engine := xyJson.NewEngine(&xyJson.EngineOptions{
+//		Serialize: &xyJson.SerializeOptions{Indent: "  ", SortKeys: true, MaxDepth: xyJson.DefaultMaxDepth},
+//	})
+//	root, _ := engine.ParseString(`{"b":1,"a":2}`)
+//	out, _ := engine.SerializeToString(root)
+type Engine struct {
+	options    EngineOptions
+	pool       IObjectPool
+	factory    IValueFactory
+	serializer ISerializer
+	pathQuery  IPathQuery
+	monitor    *PerformanceMonitor
+	parserPool *sync.Pool
+}
+
+// NewEngine 使用指定选项创建引擎，options为nil时使用DefaultEngineOptions
+// NewEngine creates an engine with the given options, using DefaultEngineOptions when options is nil
+func NewEngine(options *EngineOptions) *Engine {
+	if options == nil {
+		options = DefaultEngineOptions()
+	}
+
+	e := &Engine{options: *options}
+	e.pool = NewObjectPoolWithOptions(options.ObjectPool)
+	e.factory = NewValueFactoryWithPool(e.pool)
+	strict := options.StrictArrayIndex
+	e.pathQuery = &pathQuery{factory: e.factory, strictArrayIndex: &strict}
+
+	if options.Serialize != nil {
+		serializeOptions := *options.Serialize
+		e.serializer = NewSerializerWithOptions(&serializeOptions)
+	} else {
+		e.serializer = NewSerializer()
+	}
+
+	e.monitor = NewPerformanceMonitor()
+	if !options.EnableMonitoring {
+		e.monitor.Disable()
+	}
+
+	e.parserPool = &sync.Pool{
+		New: func() interface{} {
+			return e.NewParser()
+		},
+	}
+	return e
+}
+
+// Options 返回引擎选项的副本
+// Options returns a copy of the engine options
+func (e *Engine) Options() EngineOptions {
+	return e.options
+}
+
+// Factory 返回引擎的值工厂
+// Factory returns the engine's value factory
+func (e *Engine) Factory() IValueFactory {
+	return e.factory
+}
+
+// Serializer 返回引擎的序列化器
+// Serializer returns the engine's serializer
+func (e *Engine) Serializer() ISerializer {
+	return e.serializer
+}
+
+// PathQuery 返回引擎的路径查询器
+// PathQuery returns the engine's path query
+func (e *Engine) PathQuery() IPathQuery {
+	return e.pathQuery
+}
+
+// Pool 返回引擎的对象池
+// Pool returns the engine's object pool
+func (e *Engine) Pool() IObjectPool {
+	return e.pool
+}
+
+// Monitor 返回引擎的性能监控器
+// Monitor returns the engine's performance monitor
+func (e *Engine) Monitor() *PerformanceMonitor {
+	return e.monitor
+}
+
+// Close 停止引擎对象池的后台清理协程，短期使用的引擎用完后应调用
+// Close stops the background cleanup goroutine of the engine's object pool, short-lived engines should call it
+// when done
+func (e *Engine) Close() error {
+	return e.pool.Close()
+}
+
+// NewParser 创建使用引擎工厂和深度限制的新解析器
+// NewParser creates a new parser using the engine's factory and depth limit
+func (e *Engine) NewParser() IParser {
+	p := NewParserWithFactory(e.factory)
+	if e.options.MaxDepth > 0 {
+		p.SetMaxDepth(e.options.MaxDepth)
+	}
+	return p
+}
+
+// Defaults 返回由引擎实例构成的默认实例快照，可配合WithDefaults让包级函数临时使用该引擎
+// Defaults returns a snapshot of default instances built from the engine, which can be combined with
+// WithDefaults to make package-level functions use the engine temporarily
+func (e *Engine) Defaults() *Defaults {
+	return &Defaults{
+		factory:    e.factory,
+		parser:     e.NewParser(),
+		serializer: e.serializer,
+		pathQuery:  e.pathQuery,
+		pool:       e.pool,
+		parserPool: e.parserPool,
+	}
+}
+
+// Parse 解析JSON字节数组
+// Parse parses a JSON byte array
+func (e *Engine) Parse(data []byte) (IValue, error) {
+	timer := e.monitor.StartParseTimer()
+
+	parser := e.parserPool.Get().(IParser)
+	defer e.parserPool.Put(parser)
+
+	result, err := parser.Parse(data)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return result, nil
+}
+
+// ParseString 解析JSON字符串
+// ParseString parses a JSON string
+func (e *Engine) ParseString(data string) (IValue, error) {
+	timer := e.monitor.StartParseTimer()
+
+	parser := e.parserPool.Get().(IParser)
+	defer e.parserPool.Put(parser)
+
+	result, err := parser.ParseString(data)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return result, nil
+}
+
+// MustParse 解析JSON字节数组，失败时panic
+// MustParse parses a JSON byte array, panics on failure
+func (e *Engine) MustParse(data []byte) IValue {
+	result, err := e.Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// MustParseString 解析JSON字符串，失败时panic
+// MustParseString parses a JSON string, panics on failure
+func (e *Engine) MustParseString(data string) IValue {
+	result, err := e.ParseString(data)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Serialize 使用引擎的序列化选项序列化值
+// Serialize serializes a value with the engine's serialization options
+func (e *Engine) Serialize(value IValue) ([]byte, error) {
+	timer := e.monitor.StartSerializeTimer()
+
+	result, err := e.serializer.Serialize(value)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return result, nil
+}
+
+// SerializeToString 使用引擎的序列化选项将值序列化为字符串
+// SerializeToString serializes a value to a string with the engine's serialization options
+func (e *Engine) SerializeToString(value IValue) (string, error) {
+	data, err := e.Serialize(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SerializeToStruct 将JSON值映射到Go结构体
+// SerializeToStruct maps a JSON value to a Go struct
+func (e *Engine) SerializeToStruct(value IValue, target interface{}) error {
+	return e.serializer.SerializeToStruct(value, target)
+}
+
+// Unmarshal 使用引擎的解析器将JSON字节数组解码到Go结构体
+// Unmarshal decodes a JSON byte array into a Go struct with the engine's parser
+func (e *Engine) Unmarshal(data []byte, target interface{}) error {
+	value, err := e.Parse(data)
+	if err != nil {
+		return err
+	}
+	return e.serializer.SerializeToStruct(value, target)
+}
+
+// Get 使用JSONPath获取单个值
+// Get gets a single value using JSONPath
+func (e *Engine) Get(root IValue, path string) (IValue, error) {
+	return e.pathQuery.SelectOne(root, path)
+}
+
+// GetAll 使用JSONPath获取所有匹配的值
+// GetAll gets all matching values using JSONPath
+func (e *Engine) GetAll(root IValue, path string) ([]IValue, error) {
+	return e.pathQuery.SelectAll(root, path)
+}
+
+// Set 根据路径设置值，value通过引擎的工厂转换
+// Set sets a value by path, converting value with the engine's factory
+func (e *Engine) Set(root IValue, path string, value any) error {
+	v, err := e.factory.CreateFromRaw(value)
+	if err != nil {
+		return err
+	}
+	return e.pathQuery.Set(root, path, v)
+}
+
+// Delete 根据路径删除值
+// Delete deletes a value by path
+func (e *Engine) Delete(root IValue, path string) error {
+	return e.pathQuery.Delete(root, path)
+}
+
+// Exists 检查路径是否存在
+// Exists checks whether a path exists
+func (e *Engine) Exists(root IValue, path string) bool {
+	return e.pathQuery.Exists(root, path)
+}
+
+// Count 统计匹配路径的数量
+// Count counts the matches of a path
+func (e *Engine) Count(root IValue, path string) int {
+	return e.pathQuery.Count(root, path)
+}
+
+// CreateObject 使用引擎的工厂创建对象
+// CreateObject creates an object with the engine's factory
+func (e *Engine) CreateObject() IObject {
+	return e.factory.CreateObject()
+}
+
+// CreateArray 使用引擎的工厂创建数组
+// CreateArray creates an array with the engine's factory
+func (e *Engine) CreateArray() IArray {
+	return e.factory.CreateArray()
+}
+
+// CreateFromRaw 使用引擎的工厂从Go值创建JSON值
+// CreateFromRaw creates a JSON value from a Go value with the engine's factory
+func (e *Engine) CreateFromRaw(data interface{}) (IValue, error) {
+	return e.factory.CreateFromRaw(data)
+}
@@ -42,6 +42,27 @@ const (
 	// ErrInvalidOperation 无效操作
 	// ErrInvalidOperation indicates invalid operation
 	ErrInvalidOperation
+	// ErrNullValue 对nil值进行转换
+	// ErrNullValue indicates a conversion of a nil value
+	ErrNullValue
+	// ErrSchemaViolation 值不符合JSON Schema
+	// ErrSchemaViolation indicates a value does not conform to a JSON Schema
+	ErrSchemaViolation
+	// ErrPanic 安全模式下从panic恢复
+	// ErrPanic indicates a panic recovered in safe mode
+	ErrPanic
+	// ErrInvalidExpression 无效谓词表达式
+	// ErrInvalidExpression indicates an invalid predicate expression
+	ErrInvalidExpression
+	// ErrEncryption 字段加密或解密失败
+	// ErrEncryption indicates a field could not be encrypted or decrypted
+	ErrEncryption
+	// ErrValidation 字段值不满足validate标签规则
+	// ErrValidation indicates a field value does not satisfy its validate tag rules
+	ErrValidation
+	// ErrNumberConversion 数字超出目标类型范围或无法无损转换
+	// ErrNumberConversion indicates a number outside the range of the target type or not convertible without loss
+	ErrNumberConversion
 )
 
 // String 返回错误码的字符串表示
@@ -68,6 +89,20 @@ func (ec ErrorCode) String() string {
 		return "NULL_POINTER"
 	case ErrInvalidOperation:
 		return "INVALID_OPERATION"
+	case ErrNullValue:
+		return "NULL_VALUE"
+	case ErrSchemaViolation:
+		return "SCHEMA_VIOLATION"
+	case ErrPanic:
+		return "PANIC"
+	case ErrInvalidExpression:
+		return "INVALID_EXPRESSION"
+	case ErrEncryption:
+		return "ENCRYPTION_ERROR"
+	case ErrValidation:
+		return "VALIDATION_FAILED"
+	case ErrNumberConversion:
+		return "NUMBER_CONVERSION"
 	default:
 		return "UNKNOWN_ERROR"
 	}
@@ -83,18 +118,29 @@ type JSONError struct {
 	Line    int       `json:"line,omitempty"`
 	Column  int       `json:"column,omitempty"`
 	Context string    `json:"context,omitempty"`
+	// Parent 出错位置最近的已存在父节点的截断渲染，用于调试
+	// Parent is a truncated rendering of the nearest existing parent node, for debugging
+	Parent string `json:"parent,omitempty"`
 }
 
 // Error 实现error接口
 // Error implements the error interface
 func (je *JSONError) Error() string {
+	var message string
 	if je.Path != "" {
-		return fmt.Sprintf("[%s] %s at path '%s'", je.Code.String(), je.Message, je.Path)
+		message = fmt.Sprintf("[%s] %s at path '%s'", je.Code.String(), je.Message, je.Path)
+	} else if je.Line > 0 && je.Column > 0 {
+		message = fmt.Sprintf("[%s] %s at line %d, column %d", je.Code.String(), je.Message, je.Line, je.Column)
+	} else {
+		message = fmt.Sprintf("[%s] %s", je.Code.String(), je.Message)
 	}
-	if je.Line > 0 && je.Column > 0 {
-		return fmt.Sprintf("[%s] %s at line %d, column %d", je.Code.String(), je.Message, je.Line, je.Column)
+	if je.Context != "" {
+		message += " (" + je.Context + ")"
 	}
-	return fmt.Sprintf("[%s] %s", je.Code.String(), je.Message)
+	if je.Parent != "" {
+		message += " in " + je.Parent
+	}
+	return message
 }
 
 // Unwrap 返回底层错误
@@ -118,6 +164,13 @@ func (je *JSONError) WithPosition(line, column int) *JSONError {
 	return je
 }
 
+// WithParent 添加父节点渲染信息
+// WithParent adds the parent node rendering to the error
+func (je *JSONError) WithParent(parent string) *JSONError {
+	je.Parent = parent
+	return je
+}
+
 // WithContext 添加上下文信息
 // WithContext adds context information to the error
 func (je *JSONError) WithContext(context string) *JSONError {
@@ -144,7 +197,7 @@ func NewInvalidJSONError(message string, cause error) *JSONError {
 // NewPathNotFoundError 创建路径不存在错误
 // NewPathNotFoundError creates a path not found error
 func NewPathNotFoundError(path string) *JSONError {
-	return NewJSONError(ErrPathNotFound, fmt.Sprintf("path '%s' not found", path), nil).WithPath(path)
+	return NewJSONError(ErrPathNotFound, "path not found", nil).WithPath(path)
 }
 
 // NewTypeMismatchError 创建类型不匹配错误
@@ -185,8 +238,7 @@ func NewMaxDepthExceededError(depth int) *JSONError {
 // NewInvalidPathError 创建无效路径错误
 // NewInvalidPathError creates an invalid path error
 func NewInvalidPathError(path string, cause error) *JSONError {
-	message := fmt.Sprintf("invalid path expression: %s", path)
-	return NewJSONError(ErrInvalidPath, message, cause).WithPath(path)
+	return NewJSONError(ErrInvalidPath, "invalid path expression", cause).WithPath(path)
 }
 
 // NewNullPointerError 创建空指针错误
@@ -202,3 +254,63 @@ func NewInvalidOperationError(operation, context string) *JSONError {
 	message := fmt.Sprintf("invalid operation '%s'", operation)
 	return NewJSONError(ErrInvalidOperation, message, nil).WithContext(context)
 }
+
+// NewSchemaViolationError 创建Schema校验失败错误
+// NewSchemaViolationError creates a schema violation error
+func NewSchemaViolationError(path, message string) *JSONError {
+	return NewJSONError(ErrSchemaViolation, message, nil).WithPath(path)
+}
+
+// NewPanicError 创建从panic恢复的错误，recovered是error时作为Cause保留
+// NewPanicError creates an error recovered from a panic, keeping recovered as the Cause when it is an error
+func NewPanicError(operation string, recovered interface{}) *JSONError {
+	message := fmt.Sprintf("recovered panic in %s: %v", operation, recovered)
+	cause, _ := recovered.(error)
+	return NewJSONError(ErrPanic, message, cause)
+}
+
+// NewInvalidExpressionError 创建无效谓词表达式错误，offset为出错位置的字节偏移量
+// NewInvalidExpressionError creates an invalid predicate expression error, offset is the byte offset of the
+// problem
+func NewInvalidExpressionError(expr string, offset int, message string) *JSONError {
+	message = fmt.Sprintf("%s at offset %d", message, offset)
+	return NewJSONError(ErrInvalidExpression, message, nil).WithContext(expr)
+}
+
+// NewEncryptionError 创建字段加密或解密错误
+// NewEncryptionError creates a field encryption or decryption error
+func NewEncryptionError(operation, path string, cause error) *JSONError {
+	message := fmt.Sprintf("failed to %s field", operation)
+	if cause != nil {
+		message += ": " + cause.Error()
+	}
+	return NewJSONError(ErrEncryption, message, cause).WithPath(path)
+}
+
+// NullValueError 对nil值进行类型转换时返回的错误
+// NullValueError is returned when converting a nil value
+//
+// 可以通过errors.As同时匹配*NullValueError和*JSONError
+// Both *NullValueError and *JSONError can be matched with errors.As
+type NullValueError struct {
+	*JSONError
+	// Expected 期望转换到的类型
+	// Expected is the type the conversion expected
+	Expected ValueType
+}
+
+// Unwrap 返回底层的JSONError
+// Unwrap returns the underlying JSONError
+func (e *NullValueError) Unwrap() error {
+	return e.JSONError
+}
+
+// NewNullValueError 创建nil值转换错误
+// NewNullValueError creates a nil value conversion error
+func NewNullValueError(expected ValueType) *NullValueError {
+	message := fmt.Sprintf("cannot convert nil value to %s", expected.String())
+	return &NullValueError{
+		JSONError: NewJSONError(ErrNullValue, message, nil),
+		Expected:  expected,
+	}
+}
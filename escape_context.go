@@ -0,0 +1,102 @@
+package xyJson
+
+import "bytes"
+
+// EscapeContext 序列化输出将被嵌入的上下文，决定额外的转义
+// EscapeContext is the context the serialized output will be embedded in, deciding the extra escaping
+type EscapeContext int
+
+const (
+	// EscapeContextJSON 普通JSON，只按EscapeHTML和EscapeUnicode转义
+	// EscapeContextJSON is plain JSON, escaped only according to EscapeHTML and EscapeUnicode
+	EscapeContextJSON EscapeContext = iota
+
+	// EscapeContextScript 嵌入<script>元素或JavaScript代码：字符串中的<、>、&、'以及U+2028、U+2029
+	// 总是转义为\u形式，因此无法提前关闭script元素或破坏JS字符串字面量
+	// EscapeContextScript embeds into a <script> element or JavaScript code: <, >, &, ' and U+2028, U+2029 in
+	// strings are always escaped in the \u form, so the script element cannot be closed early and JS string
+	// literals cannot be broken
+	EscapeContextScript
+
+	// EscapeContextHTMLAttribute 嵌入加引号的HTML属性值：在EscapeContextScript的基础上，
+	// 整个输出中的双引号编码为&#34;，浏览器解码属性后得到原始JSON
+	// EscapeContextHTMLAttribute embeds into a quoted HTML attribute value: on top of EscapeContextScript,
+	// double quotes in the whole output are encoded as &#34;, the browser gets the original JSON back after
+	// decoding the attribute
+	EscapeContextHTMLAttribute
+
+	// EscapeContextURL 嵌入URL查询参数或路径段：在EscapeContextScript的基础上，整个输出按RFC 3986
+	// 百分号编码，除字母、数字和-._~之外的字节（包括引号、空白和控制字符）都被编码
+	// EscapeContextURL embeds into a URL query parameter or path segment: on top of EscapeContextScript, the
+	// whole output is percent-encoded per RFC 3986, every byte other than letters, digits and -._~ (including
+	// quotes, whitespace and control characters) is encoded
+	EscapeContextURL
+)
+
+// String 返回转义上下文的字符串表示
+// String returns the string representation of the escape context
+func (ec EscapeContext) String() string {
+	switch ec {
+	case EscapeContextJSON:
+		return "json"
+	case EscapeContextScript:
+		return "script"
+	case EscapeContextHTMLAttribute:
+		return "html-attribute"
+	case EscapeContextURL:
+		return "url"
+	default:
+		return "unknown"
+	}
+}
+
+// SerializeForContext 序列化JSON值以便安全地嵌入指定上下文，输出为紧凑格式
+// SerializeForContext serializes a JSON value for safe embedding in the given context, the output is compact
+//
+// 每次调用可以选择不同的上下文，适用于把同一个文档插入模板中不同位置的场景
+// Each call may pick a different context, suited to interpolating the same document at different places of
+// a template
+//
+// 示例 Example:
+//
+//	attr, _ := xyJson.SerializeForContext(state, xyJson.EscapeContextHTMLAttribute)
+//	fmt.Fprintf(w, `<div data-state="%s"></div>`, attr)
+//	query, _ := xyJson.SerializeForContext(filter, xyJson.EscapeContextURL)
+//	link := "/search?filter=" + string(query)
+func SerializeForContext(value IValue, context EscapeContext) ([]byte, error) {
+	return NewSerializerWithOptions(&SerializeOptions{
+		Compact:       true,
+		EscapeHTML:    true,
+		MaxDepth:      DefaultMaxDepth,
+		EscapeContext: context,
+	}).Serialize(value)
+}
+
+// encode 对完整的序列化输出应用上下文的整体编码
+// encode applies the whole-output encoding of the context to complete serialized output
+func (ec EscapeContext) encode(data []byte) []byte {
+	switch ec {
+	case EscapeContextHTMLAttribute:
+		return bytes.ReplaceAll(data, []byte{'"'}, []byte("&#34;"))
+	case EscapeContextURL:
+		return percentEncode(data)
+	default:
+		return data
+	}
+}
+
+// percentEncode 按RFC 3986百分号编码除非保留字符外的所有字节
+// percentEncode percent-encodes every byte other than the RFC 3986 unreserved characters
+func percentEncode(data []byte) []byte {
+	const hex = "0123456789ABCDEF"
+	out := make([]byte, 0, len(data)*3)
+	for _, b := range data {
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' ||
+			b == '-' || b == '.' || b == '_' || b == '~' {
+			out = append(out, b)
+			continue
+		}
+		out = append(out, '%', hex[b>>4], hex[b&0x0f])
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package xyJson
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+)
+
+// Version 返回对象树的版本号，见IValue.Version
+// Version returns the version of the object tree, see IValue.Version
+func (ov *objectValue) Version() uint64 {
+	return treeVersion(ov)
+}
+
+// Version 返回数组树的版本号，见IValue.Version
+// Version returns the version of the array tree, see IValue.Version
+func (av *arrayValue) Version() uint64 {
+	return treeVersion(av)
+}
+
+// Version 标量无法通过API修改，版本号总是0
+// Version is always 0 for scalars, which cannot be modified through the APIs
+func (sv *scalarValue) Version() uint64 {
+	return 0
+}
+
+// ETag 返回对象序列化结果的实体标签，见IValue.ETag
+// ETag returns the entity tag of the object's serialization, see IValue.ETag
+func (ov *objectValue) ETag() (string, error) {
+	return cachedETag(ov, &ov.jsonCache)
+}
+
+// ETag 返回数组序列化结果的实体标签，见IValue.ETag
+// ETag returns the entity tag of the array's serialization, see IValue.ETag
+func (av *arrayValue) ETag() (string, error) {
+	return cachedETag(av, &av.jsonCache)
+}
+
+// ETag 返回标量序列化结果的实体标签
+// ETag returns the entity tag of the scalar's serialization
+func (sv *scalarValue) ETag() (string, error) {
+	data, err := sv.CachedJSON()
+	if err != nil {
+		return "", err
+	}
+	return computeETag(data), nil
+}
+
+// treeVersion 返回树中最大的修改戳，并把纪元推进到该值，使之后的任何修改都得到更大的修改戳
+// treeVersion returns the greatest modification stamp in the tree and advances the epoch to it, so any later
+// modification gets a greater stamp
+func treeVersion(root IValue) uint64 {
+	version := maxStamp(root)
+	for {
+		epoch := mutationEpoch.Load()
+		if epoch >= version || mutationEpoch.CompareAndSwap(epoch, version) {
+			return version
+		}
+	}
+}
+
+// maxStamp 使用显式栈返回树中容器的最大修改戳，与serializeIterative一样不受嵌套深度限制
+// maxStamp returns the greatest modification stamp of the containers in the tree with an explicit stack, not
+// limited by the nesting depth, like serializeIterative
+//
+// 每个容器只在复制其子值时持有读锁，不会在遍历整棵树期间一直持有祖先的锁；循环引用的容器只访问一次
+// Each container holds its read lock only while its children are copied, instead of the locks of all ancestors
+// being held for the whole walk; containers in a circular reference are visited once
+func maxStamp(root IValue) uint64 {
+	var stamp uint64
+	visited := make(map[IValue]bool)
+	stack := []IValue{root}
+	for len(stack) > 0 {
+		value := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch v := value.(type) {
+		case *objectValue:
+			if visited[v] {
+				continue
+			}
+			visited[v] = true
+			stamp = max(stamp, v.stamp.Load())
+			v.mu.RLock()
+			v.each(func(_ string, child IValue) {
+				stack = append(stack, child)
+			})
+			v.mu.RUnlock()
+		case *arrayValue:
+			if visited[v] {
+				continue
+			}
+			visited[v] = true
+			stamp = max(stamp, v.stamp.Load())
+			v.mu.RLock()
+			stack = append(stack, v.data...)
+			v.mu.RUnlock()
+		}
+	}
+	return stamp
+}
+
+// cachedETag 返回缓存的序列化结果的实体标签，只在内容改变后重新计算哈希
+// cachedETag returns the entity tag of the cached serialization, hashing again only after the content changed
+func cachedETag(root IValue, cache *jsonCache) (string, error) {
+	if _, err := cachedJSON(root, cache); err != nil {
+		return "", err
+	}
+	snapshot := cache.snapshot.Load()
+	snapshot.etagOnce.Do(func() {
+		snapshot.etag = computeETag(snapshot.data)
+	})
+	return snapshot.etag, nil
+}
+
+// computeETag 计算数据的强实体标签，格式为带引号的128位FNV-1a哈希
+// computeETag computes the strong entity tag of data, a quoted 128-bit FNV-1a hash
+func computeETag(data []byte) string {
+	h := fnv.New128a()
+	h.Write(data)
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
@@ -10,23 +10,45 @@ import (
 // NewObjectValue 创建新的对象值
 // NewObjectValue creates a new object value
 func NewObjectValue() IValue {
-	return &objectValue{
-		data: make(map[string]IValue),
-	}
+	return &objectValue{}
 }
 
 // NewArrayValue 创建新的数组值
 // NewArrayValue creates a new array value
 func NewArrayValue() IValue {
-	return &arrayValue{
-		data: make([]IValue, 0),
-	}
+	return newArrayValue()
 }
 
 // valueFactory 值工厂实现
 // valueFactory implements the IValueFactory interface
 type valueFactory struct {
 	pool IObjectPool
+
+	// mapKey 将非map[string]interface{}的Go map键转换为对象键名
+	// mapKey converts the keys of Go maps other than map[string]interface{} into object keys
+	mapKey MapKeyFunc
+}
+
+// ValueFactoryOptions 值工厂选项
+// ValueFactoryOptions holds value factory options
+type ValueFactoryOptions struct {
+	// Pool 对象池，nil时不使用对象池
+	// Pool is the object pool, no pool is used when nil
+	Pool IObjectPool
+
+	// MapKey CreateFromRaw转换map[int]T、map[fmt.Stringer]T等map键的函数，nil时使用DefaultMapKey
+	// MapKey is the function CreateFromRaw uses to convert the keys of maps such as map[int]T and
+	// map[fmt.Stringer]T, DefaultMapKey is used when nil
+	MapKey MapKeyFunc
+}
+
+// DefaultValueFactoryOptions 返回默认值工厂选项
+// DefaultValueFactoryOptions returns default value factory options
+func DefaultValueFactoryOptions() *ValueFactoryOptions {
+	return &ValueFactoryOptions{
+		Pool:   NewObjectPool(),
+		MapKey: DefaultMapKey,
+	}
 }
 
 // NewValueFactory 创建新的值工厂
@@ -45,6 +67,27 @@ func NewValueFactoryWithPool(pool IObjectPool) IValueFactory {
 	}
 }
 
+// NewValueFactoryWithOptions 使用指定选项创建值工厂
+// NewValueFactoryWithOptions creates a value factory with the specified options
+//
+// 示例 Example:
+//
+//	factory := xyJson.NewValueFactoryWithOptions(&xyJson.ValueFactoryOptions{
+//		Pool: xyJson.NewObjectPool(),
+//		MapKey: func(key interface{}) (string, error) {
+//			return fmt.Sprintf("id-%v", key), nil
+//		},
+//	})
+func NewValueFactoryWithOptions(options *ValueFactoryOptions) IValueFactory {
+	if options == nil {
+		options = DefaultValueFactoryOptions()
+	}
+	return &valueFactory{
+		pool:   options.Pool,
+		mapKey: options.MapKey,
+	}
+}
+
 // CreateNull 创建null值
 // CreateNull creates a null value
 func (f *valueFactory) CreateNull() IValue {
@@ -201,6 +244,10 @@ func (f *valueFactory) CreateArray() IArray {
 
 // CreateFromRaw 从原始数据创建值
 // CreateFromRaw creates a value from raw data
+//
+// 对象不保留键的插入顺序，Keys、Range和序列化总是按键名排序，因此由Go map构建的文档每次输出都相同
+// Objects do not keep key insertion order, Keys, Range and serialization are always sorted by key, so documents
+// built from Go maps serialize identically on every run
 func (f *valueFactory) CreateFromRaw(data interface{}) (IValue, error) {
 	if data == nil {
 		return f.CreateNull(), nil
@@ -290,8 +337,15 @@ func (f *valueFactory) createFromReflect(rv reflect.Value) (IValue, error) {
 		return arr, nil
 	case reflect.Map:
 		obj := f.CreateObject()
+		mapKey := f.mapKey
+		if mapKey == nil {
+			mapKey = DefaultMapKey
+		}
 		for _, key := range rv.MapKeys() {
-			keyStr := fmt.Sprintf("%v", key.Interface())
+			keyStr, err := mapKey(key.Interface())
+			if err != nil {
+				return nil, err
+			}
 			val, err := f.createFromReflect(rv.MapIndex(key))
 			if err != nil {
 				return nil, err
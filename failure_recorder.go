@@ -0,0 +1,310 @@
+package xyJson
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseFailure 一次解析失败的记录
+// ParseFailure is the record of a failed parse
+type ParseFailure struct {
+	// Time 失败发生的时间
+	// Time is when the failure happened
+	Time time.Time
+
+	// Data 输入的拷贝，超过MaxPayloadSize时只保留开头部分
+	// Data is a copy of the input, only its beginning is kept beyond MaxPayloadSize
+	Data []byte
+
+	// Size 输入的原始长度
+	// Size is the original length of the input
+	Size int
+
+	// Err 解析返回的错误
+	// Err is the error returned by the parse
+	Err error
+}
+
+// Truncated 报告Data是否只是输入的开头部分
+// Truncated reports whether Data is only the beginning of the input
+func (f ParseFailure) Truncated() bool {
+	return len(f.Data) < f.Size
+}
+
+// FailureRecorderOptions 解析失败记录器配置选项
+// FailureRecorderOptions configures the parse failure recorder
+type FailureRecorderOptions struct {
+	// Capacity 保留的最大记录数，超出时覆盖最早的记录，0表示不保留记录，只调用OnFailure
+	// Capacity is the maximum number of records kept, the oldest are overwritten beyond it; 0 keeps no records
+	// and only calls OnFailure
+	Capacity int
+
+	// MaxPayloadSize 每条记录保留的最大输入字节数，0表示不限制
+	// MaxPayloadSize is the maximum number of input bytes kept per record, 0 means unlimited
+	MaxPayloadSize int
+
+	// OnFailure 每次失败时同步调用的回调，记录已截断，可以被保存
+	// OnFailure is called synchronously on every failure with the truncated record, which it may keep
+	OnFailure func(ParseFailure)
+
+	// Clock 返回记录时间的函数，nil时使用time.Now
+	// Clock returns the record time, time.Now is used when nil
+	Clock func() time.Time
+}
+
+// DefaultFailureRecorderOptions 返回默认的解析失败记录器选项：保留最近100条记录，每条最多64KB
+// DefaultFailureRecorderOptions returns the default parse failure recorder options: the latest 100 records are
+// kept with at most 64KB each
+func DefaultFailureRecorderOptions() *FailureRecorderOptions {
+	return &FailureRecorderOptions{
+		Capacity:       100,
+		MaxPayloadSize: 64 * 1024,
+		Clock:          time.Now,
+	}
+}
+
+// FailureRecorder 在环形缓冲区中保留解析失败的输入，用于离线重现客户特有的错误输入，并发安全
+// FailureRecorder keeps the inputs of failed parses in a ring buffer for reproducing customer specific malformed
+// input offline, safe for concurrent use
+//
+// 示例 Example:
+//
+//	recorder := xyJson.NewFailureRecorder(nil)
+//	xyJson.WithDefaults(xyJson.CurrentDefaults().WithFailureRecorder(recorder))
+//	...
+//	file, _ := os.Create("failures.ndjson")
+//	recorder.WriteTo(file)
+type FailureRecorder struct {
+	mu       sync.Mutex
+	options  FailureRecorderOptions
+	failures []ParseFailure
+	next     int
+	total    uint64
+}
+
+// NewFailureRecorder 创建解析失败记录器，options为nil时使用默认选项
+// NewFailureRecorder creates a parse failure recorder, the default options are used when options is nil
+func NewFailureRecorder(options *FailureRecorderOptions) *FailureRecorder {
+	if options == nil {
+		options = DefaultFailureRecorderOptions()
+	}
+	r := &FailureRecorder{options: *options}
+	if r.options.Clock == nil {
+		r.options.Clock = time.Now
+	}
+	if r.options.Capacity < 0 {
+		r.options.Capacity = 0
+	}
+	return r
+}
+
+// Record 记录一次解析失败，data会被拷贝
+// Record records a failed parse, data is copied
+func (r *FailureRecorder) Record(data []byte, err error) {
+	kept := data
+	if max := r.options.MaxPayloadSize; max > 0 && len(kept) > max {
+		kept = kept[:max]
+	}
+	failure := ParseFailure{
+		Time: r.options.Clock(),
+		Data: append([]byte(nil), kept...),
+		Size: len(data),
+		Err:  err,
+	}
+
+	r.mu.Lock()
+	r.total++
+	if r.options.Capacity > 0 {
+		if len(r.failures) < r.options.Capacity {
+			r.failures = append(r.failures, failure)
+		} else {
+			r.failures[r.next] = failure
+		}
+		r.next = (r.next + 1) % r.options.Capacity
+	}
+	r.mu.Unlock()
+
+	if r.options.OnFailure != nil {
+		r.options.OnFailure(failure)
+	}
+}
+
+// Failures 返回保留的记录，从最早到最新
+// Failures returns the kept records, from oldest to newest
+func (r *FailureRecorder) Failures() []ParseFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failures := make([]ParseFailure, 0, len(r.failures))
+	if len(r.failures) == r.options.Capacity {
+		failures = append(failures, r.failures[r.next:]...)
+		return append(failures, r.failures[:r.next]...)
+	}
+	return append(failures, r.failures...)
+}
+
+// Total 返回记录过的失败总数，包括已被覆盖的记录
+// Total returns the number of failures ever recorded, including overwritten records
+func (r *FailureRecorder) Total() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// Clear 清空保留的记录，不影响Total
+// Clear removes the kept records without affecting Total
+func (r *FailureRecorder) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures, r.next = nil, 0
+}
+
+// Replay 用解析器p重新解析保留的记录，返回每条记录的结果，修复后能解析的记录对应nil；p为nil时使用基于默认工厂的解析器
+// Replay parses the kept records again with the parser p and returns the result of each, nil for records that now
+// parse; a parser using the default factory is used when p is nil
+//
+// 被截断的记录通常仍会失败，但错误可能与原始错误不同
+// Truncated records usually still fail, possibly with an error different from the original one
+func (r *FailureRecorder) Replay(p IParser) []error {
+	if p == nil {
+		p = NewParserWithFactory(defaultFactory())
+	}
+	failures := r.Failures()
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		_, errs[i] = p.Parse(failure.Data)
+	}
+	return errs
+}
+
+// WriteTo 以NDJSON格式写出保留的记录，每行一个对象，包含time、size、error和data；
+// 不是有效UTF-8的输入写为base64成员而不是data
+// WriteTo writes the kept records as NDJSON, one object per line with time, size, error and data; input that is
+// not valid UTF-8 is written as a base64 member instead of data
+func (r *FailureRecorder) WriteTo(w io.Writer) (int64, error) {
+	factory := defaultFactory()
+	serializer := CompactSerializer()
+	var written int64
+	for _, failure := range r.Failures() {
+		obj := factory.CreateObject()
+		obj.Set("time", factory.CreateString(failure.Time.UTC().Format(time.RFC3339Nano)))
+		obj.Set("size", factory.CreateNumber(failure.Size))
+		if failure.Err != nil {
+			obj.Set("error", factory.CreateString(failure.Err.Error()))
+		}
+		if utf8.Valid(failure.Data) {
+			obj.Set("data", factory.CreateString(string(failure.Data)))
+		} else {
+			obj.Set("base64", factory.CreateString(base64.StdEncoding.EncodeToString(failure.Data)))
+		}
+		line, err := serializer.Serialize(obj)
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(append(line, '\n'))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// ReadParseFailures 读取WriteTo写出的记录，错误以其消息文本恢复，格式错误的行不会被全局记录器记录
+// ReadParseFailures reads records written by WriteTo, errors are restored as their message text; malformed
+// lines are not recorded by the global recorder
+func ReadParseFailures(reader io.Reader) ([]ParseFailure, error) {
+	var failures []ParseFailure
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		failure, err := parseFailureLine(p, scanner.Bytes())
+		if err != nil {
+			return nil, NewInvalidJSONError("invalid parse failure record", err).WithContext("line " + strconv.Itoa(line))
+		}
+		failures = append(failures, failure)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// parseFailureLine 解析WriteTo写出的一行
+// parseFailureLine parses one line written by WriteTo
+func parseFailureLine(p *parser, line []byte) (ParseFailure, error) {
+	var failure ParseFailure
+	value, err := p.parse(line)
+	if err != nil {
+		return failure, err
+	}
+	if value.Type() != ObjectValueType {
+		return failure, NewTypeMismatchError(ObjectValueType, value.Type(), "$")
+	}
+	obj := value.AsObject()
+	if text, ok := patchMember(obj, "time"); ok {
+		if failure.Time, err = time.Parse(time.RFC3339Nano, text); err != nil {
+			return failure, err
+		}
+	}
+	if message, ok := patchMember(obj, "error"); ok {
+		failure.Err = errors.New(message)
+	}
+	if data, ok := patchMember(obj, "data"); ok {
+		failure.Data = []byte(data)
+	} else if encoded, ok := patchMember(obj, "base64"); ok {
+		if failure.Data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return failure, err
+		}
+	}
+	failure.Size = len(failure.Data)
+	if size := obj.Get("size"); size != nil {
+		n, ok := size.AsInt64OK()
+		if !ok || n < int64(len(failure.Data)) {
+			return failure, NewInvalidJSONError("size must be an integer not less than the data length", nil).WithPath("$.size")
+		}
+		failure.Size = int(n)
+	}
+	return failure, nil
+}
+
+// WithFailureRecorder 返回使用解析失败记录器的副本，包级的Parse、ParseString及其Context变体失败时记录输入，nil停止记录
+// WithFailureRecorder returns a copy using the parse failure recorder, the input of every failed package-level
+// Parse, ParseString and their Context variants is recorded; nil stops recording
+//
+// 未设置记录器时解析路径只多一次nil判断，因此可以在生产环境中常开；ParseWithOptions通过ParseOptions.FailureRecorder记录
+// Without a recorder the parse path only costs one extra nil check, so it can stay enabled in production;
+// ParseWithOptions records through ParseOptions.FailureRecorder
+//
+// 示例 Example:
+//
+//	recorder := xyJson.NewFailureRecorder(nil)
+//	xyJson.WithDefaults(xyJson.CurrentDefaults().WithFailureRecorder(recorder))
+func (d *Defaults) WithFailureRecorder(recorder *FailureRecorder) *Defaults {
+	next := *d
+	next.failureRecorder = recorder
+	return &next
+}
+
+// FailureRecorder 返回解析失败记录器，未设置时为nil
+// FailureRecorder returns the parse failure recorder, nil when unset
+func (d *Defaults) FailureRecorder() *FailureRecorder {
+	return d.failureRecorder
+}
+
+// recordParseFailure 将失败的输入交给记录器，recorder为nil时不记录
+// recordParseFailure hands the failed input to the recorder, nothing is recorded when recorder is nil
+func recordParseFailure(recorder *FailureRecorder, data []byte, err error) {
+	if recorder != nil {
+		recorder.Record(data, err)
+	}
+}
@@ -0,0 +1,114 @@
+package xyJson
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// fieldSetter 通过字段地址直接写入标量值，类型不匹配或超出范围时返回false，由反射路径处理并报告错误
+// fieldSetter writes a scalar value directly through the field address, returning false on type mismatch or
+// overflow so the reflection path handles it and reports the error
+type fieldSetter func(ptr unsafe.Pointer, value IValue) bool
+
+// newFieldSetter 为标量字段类型创建直接写入函数，其它类型返回nil
+// newFieldSetter creates a direct setter for scalar field types, returning nil for other types
+func newFieldSetter(t reflect.Type) fieldSetter {
+	switch t.Kind() {
+	case reflect.String:
+		return func(ptr unsafe.Pointer, value IValue) bool {
+			if value.Type() != StringValueType {
+				return false
+			}
+			*(*string)(ptr) = value.AsString()
+			return true
+		}
+	case reflect.Bool:
+		return func(ptr unsafe.Pointer, value IValue) bool {
+			if value.Type() != BoolValueType {
+				return false
+			}
+			*(*bool)(ptr) = value.AsBool()
+			return true
+		}
+	case reflect.Int:
+		return intFieldSetter(0, func(ptr unsafe.Pointer, v int64) { *(*int)(ptr) = int(v) })
+	case reflect.Int8:
+		return intFieldSetter(8, func(ptr unsafe.Pointer, v int64) { *(*int8)(ptr) = int8(v) })
+	case reflect.Int16:
+		return intFieldSetter(16, func(ptr unsafe.Pointer, v int64) { *(*int16)(ptr) = int16(v) })
+	case reflect.Int32:
+		return intFieldSetter(32, func(ptr unsafe.Pointer, v int64) { *(*int32)(ptr) = int32(v) })
+	case reflect.Int64:
+		return intFieldSetter(64, func(ptr unsafe.Pointer, v int64) { *(*int64)(ptr) = v })
+	case reflect.Uint:
+		return uintFieldSetter(0, func(ptr unsafe.Pointer, v uint64) { *(*uint)(ptr) = uint(v) })
+	case reflect.Uint8:
+		return uintFieldSetter(8, func(ptr unsafe.Pointer, v uint64) { *(*uint8)(ptr) = uint8(v) })
+	case reflect.Uint16:
+		return uintFieldSetter(16, func(ptr unsafe.Pointer, v uint64) { *(*uint16)(ptr) = uint16(v) })
+	case reflect.Uint32:
+		return uintFieldSetter(32, func(ptr unsafe.Pointer, v uint64) { *(*uint32)(ptr) = uint32(v) })
+	case reflect.Uint64:
+		return uintFieldSetter(64, func(ptr unsafe.Pointer, v uint64) { *(*uint64)(ptr) = v })
+	case reflect.Float32:
+		return func(ptr unsafe.Pointer, value IValue) bool {
+			if value.Type() != NumberValueType {
+				return false
+			}
+			f, err := convertToFloat(value.Raw(), 32, &defaultNumberConversion)
+			if err != nil {
+				return false
+			}
+			*(*float32)(ptr) = float32(f)
+			return true
+		}
+	case reflect.Float64:
+		return func(ptr unsafe.Pointer, value IValue) bool {
+			if value.Type() != NumberValueType {
+				return false
+			}
+			f, err := convertToFloat(value.Raw(), 64, &defaultNumberConversion)
+			if err != nil {
+				return false
+			}
+			*(*float64)(ptr) = f
+			return true
+		}
+	default:
+		return nil
+	}
+}
+
+// intFieldSetter 创建通过统一数字转换检查范围的有符号整数写入函数，bits为0表示int
+// intFieldSetter creates a signed integer setter checking the range through the shared number conversion, 0
+// bits meaning int
+func intFieldSetter(bits int, store func(ptr unsafe.Pointer, v int64)) fieldSetter {
+	return func(ptr unsafe.Pointer, value IValue) bool {
+		if value.Type() != NumberValueType {
+			return false
+		}
+		v, err := convertToInt(value.Raw(), bits, &defaultNumberConversion)
+		if err != nil {
+			return false
+		}
+		store(ptr, v)
+		return true
+	}
+}
+
+// uintFieldSetter 创建通过统一数字转换检查范围的无符号整数写入函数，bits为0表示uint
+// uintFieldSetter creates an unsigned integer setter checking the range through the shared number conversion, 0
+// bits meaning uint
+func uintFieldSetter(bits int, store func(ptr unsafe.Pointer, v uint64)) fieldSetter {
+	return func(ptr unsafe.Pointer, value IValue) bool {
+		if value.Type() != NumberValueType {
+			return false
+		}
+		v, err := convertToUint(value.Raw(), bits, &defaultNumberConversion)
+		if err != nil {
+			return false
+		}
+		store(ptr, v)
+		return true
+	}
+}
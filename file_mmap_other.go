@@ -0,0 +1,15 @@
+//go:build !unix
+
+package xyJson
+
+// mmapFile 当前平台不支持内存映射，返回nil以回退到ReadFile
+// mmapFile is unsupported on this platform, returning nil to fall back to ReadFile
+func mmapFile(path string) ([]byte, error) {
+	return nil, nil
+}
+
+// munmapFile 当前平台不支持内存映射
+// munmapFile is unsupported on this platform
+func munmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package xyJson
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile 以只读方式映射文件，空文件返回nil
+// mmapFile maps a file read-only, returning nil for empty files
+func mmapFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 || size != int64(int(size)) {
+		return nil, nil
+	}
+
+	return syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// munmapFile 解除文件映射
+// munmapFile unmaps a file mapping
+func munmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,155 @@
+package xyJson
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// ParseFileOptions 文件解析选项
+// ParseFileOptions represents file parsing options
+type ParseFileOptions struct {
+	// UseMmap 是否使用内存映射读取文件，不支持的平台上自动回退到ReadFile
+	// UseMmap indicates whether to memory-map the file, falling back to ReadFile on unsupported platforms
+	UseMmap bool
+
+	// ZeroCopy 未转义的字符串是否直接引用文件数据而不复制
+	// ZeroCopy indicates whether unescaped strings reference the file data without copying
+	ZeroCopy bool
+
+	// MaxDepth 最大解析深度
+	// MaxDepth is the maximum parsing depth
+	MaxDepth int
+}
+
+// DefaultParseFileOptions 返回默认文件解析选项
+// DefaultParseFileOptions returns default file parsing options
+func DefaultParseFileOptions() *ParseFileOptions {
+	return &ParseFileOptions{
+		UseMmap:  true,
+		ZeroCopy: true,
+		MaxDepth: DefaultMaxDepth,
+	}
+}
+
+// FileDocument 从文件解析得到的文档，持有底层文件数据
+// FileDocument is a document parsed from a file, holding the underlying file data
+//
+// 使用内存映射和零拷贝时，文档中的字符串直接引用映射的内存；
+// Close之后不能再访问Root返回的值及从中取出的字符串，需要保留时应先调用Detach或用strings.Clone复制
+// With mmap and zero-copy, strings in the document reference the mapped memory directly;
+// values returned by Root and strings taken from them must not be used after Close,
+// call Detach or clone strings with strings.Clone if they need to outlive the document
+type FileDocument struct {
+	root   IValue
+	data   []byte
+	mapped bool
+	once   sync.Once
+	err    error
+}
+
+// ParseFile 解析JSON文件
+// ParseFile parses a JSON file
+//
+// options为nil时使用DefaultParseFileOptions。调用方必须在使用完毕后调用Close释放映射
+// DefaultParseFileOptions is used when options is nil. Callers must call Close when done to release the mapping
+//
+// 根值带有MetaSource（文件路径）和MetaParseDuration元数据
+// The root value carries MetaSource (the file path) and MetaParseDuration metadata
+//
+// 使用示例 Usage Example:
+//
+//	doc, err := xyJson.ParseFile("dataset.json", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer doc.Close()
+//	name := xyJson.MustGetString(doc.Root(), "$.meta.name")
+func ParseFile(path string, options *ParseFileOptions) (*FileDocument, error) {
+	if options == nil {
+		options = DefaultParseFileOptions()
+	}
+
+	doc := &FileDocument{}
+	if options.UseMmap {
+		data, err := mmapFile(path)
+		if err == nil {
+			doc.data = data
+			doc.mapped = data != nil
+		} else if os.IsNotExist(err) || os.IsPermission(err) {
+			return nil, NewInvalidOperationError("parse file", err.Error())
+		}
+	}
+	if !doc.mapped {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, NewInvalidOperationError("parse file", err.Error())
+		}
+		doc.data = data
+	}
+
+	timer := GetGlobalMonitor().StartParseTimer()
+	start := time.Now()
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+	p.SetMaxDepth(options.MaxDepth)
+	p.zeroCopy = options.ZeroCopy
+
+	root, err := p.Parse(doc.data)
+	if err != nil {
+		timer.EndWithError()
+		doc.Close()
+		if je, ok := err.(*JSONError); ok {
+			return nil, je.WithContext("file " + path)
+		}
+		return nil, err
+	}
+	timer.End()
+	root.SetMeta(MetaSource, path)
+	root.SetMeta(MetaParseDuration, time.Since(start))
+
+	if options.ZeroCopy {
+		trackBorrowed(root, doc.data)
+	}
+	doc.root = root
+	return doc, nil
+}
+
+// Root 返回文档的根值
+// Root returns the root value of the document
+func (d *FileDocument) Root() IValue {
+	return d.root
+}
+
+// Detach 将根值中的字符串复制为独立内存并返回根值，之后在Close后仍可使用
+// Detach copies the strings of the root value into owned memory and returns it, so it remains usable after Close
+func (d *FileDocument) Detach() IValue {
+	return Detach(d.root)
+}
+
+// Mapped 返回文件数据是否通过内存映射读取
+// Mapped returns whether the file data was read via memory mapping
+func (d *FileDocument) Mapped() bool {
+	return d.mapped
+}
+
+// Size 返回文件数据的字节数
+// Size returns the size of the file data in bytes
+func (d *FileDocument) Size() int {
+	return len(d.data)
+}
+
+// Close 释放文件数据，多次调用是安全的
+// Close releases the file data, it is safe to call multiple times
+func (d *FileDocument) Close() error {
+	d.once.Do(func() {
+		if d.root != nil {
+			releaseBorrowed(d.root)
+		}
+		if d.mapped {
+			d.err = munmapFile(d.data)
+		}
+		d.data = nil
+		d.root = nil
+	})
+	return d.err
+}
@@ -0,0 +1,53 @@
+package xyJson
+
+import "strconv"
+
+// FindPaths 返回谓词匹配的所有节点的JSONPath，用于数据质量审计等反向查询
+// FindPaths returns the JSONPaths of all nodes the predicate matches, for reverse queries such as data-quality
+// audits
+//
+// 节点按深度优先先序遍历，对象成员按键名排序，根节点本身也会被检查。返回的路径与Get兼容，
+// 特殊键名按EscapeKey转义
+// Nodes are visited depth-first in pre-order with object members sorted by key, and the root itself is checked
+// too. The returned paths are compatible with Get, special keys are escaped as by EscapeKey
+//
+// 示例 Example:
+//
+//	// 查找所有超过1MB的字符串 / Find all strings longer than 1MB
+//	paths := xyJson.FindPaths(doc, func(v xyJson.IValue) bool {
+//		return v.Type() == xyJson.StringValueType && len(v.AsString()) > 1<<20
+//	})
+func FindPaths(root IValue, pred func(IValue) bool) []string {
+	if root == nil || pred == nil {
+		return nil
+	}
+	paths := []string{}
+	findPaths(root, "$", pred, 0, &paths)
+	return paths
+}
+
+// findPaths 递归收集匹配节点的路径
+// findPaths collects the paths of matching nodes recursively
+func findPaths(value IValue, path string, pred func(IValue) bool, depth int, paths *[]string) {
+	if pred(value) {
+		*paths = append(*paths, path)
+	}
+	if depth >= DefaultMaxDepth {
+		return
+	}
+
+	switch v := value.(type) {
+	case IObject:
+		for _, key := range v.Keys() {
+			if child := v.Get(key); child != nil {
+				findPaths(child, appendPathKey(path, key), pred, depth+1, paths)
+			}
+		}
+	case IArray:
+		for i := 0; i < v.Length(); i++ {
+			if child := v.Get(i); child != nil {
+				findPaths(child, path+"["+strconv.Itoa(i)+"]", pred, depth+1, paths)
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package xyJson
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// withPathContext 为便捷获取函数的错误补充路径和父节点信息
+// withPathContext enriches errors from the convenience getters with the path and parent node
+//
+// 父节点是路径上最近的已存在节点：类型不匹配时为目标值的父节点，路径不存在时为最深的已存在前缀
+// The parent is the nearest existing node on the path: the parent of the target on type mismatch,
+// or the deepest existing prefix when the path does not exist
+//
+// 渲染父节点需要逐个前缀重新查询，因此只用于返回错误的Get系列；Try、Must和WithDefault系列丢弃错误，不做这些工作
+// Rendering the parent re-queries every prefix, so it is only done for the error-returning Get family; the
+// Try, Must and WithDefault families discard the error and skip this work
+func withPathContext(root IValue, path string, err error) error {
+	var je *JSONError
+	if !errors.As(err, &je) || root == nil {
+		return err
+	}
+	if je.Path == "" {
+		je.Path = path
+	}
+	if je.Parent == "" {
+		je.Parent = describeParent(root, path)
+	}
+	return err
+}
+
+// describeParent 渲染路径上最近的已存在父节点
+// describeParent renders the nearest existing parent node on the path
+func describeParent(root IValue, path string) string {
+	// 复用缓存的编译路径 Reuse the cached compiled path
+	compiled, err := CompilePath(path)
+	if err != nil {
+		return ""
+	}
+	compiled.mu.RLock()
+	segments := compiled.segments
+	compiled.mu.RUnlock()
+	if len(segments) == 0 {
+		return ""
+	}
+
+	pq := &pathQuery{factory: defaultFactory()}
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		results := pq.executeQuery(root, segments[:i], false)
+		if len(results) == 0 {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString("$")
+		for _, segment := range segments[:i] {
+			sb.WriteString(formatSegment(segment))
+		}
+		sb.WriteString(" ")
+		sb.WriteString(results[0].Type().String())
+		sb.WriteString(" ")
+		sb.WriteString(renderTruncated(results[0], MaxParentRenderLength))
+		return sb.String()
+	}
+	return ""
+}
+
+// renderTruncated 浅层渲染值，嵌套容器只显示大小，结果超过limit时截断
+// renderTruncated renders a value shallowly, showing only the size of nested containers,
+// and truncates the result beyond limit
+func renderTruncated(value IValue, limit int) string {
+	var sb strings.Builder
+	switch v := value.(type) {
+	case IObject:
+		sb.WriteString("{")
+		for i, key := range v.Keys() {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if sb.Len() > limit {
+				break
+			}
+			sb.WriteString(strconv.Quote(key))
+			sb.WriteString(": ")
+			sb.WriteString(renderShallow(v.Get(key)))
+		}
+		sb.WriteString("}")
+	case IArray:
+		sb.WriteString("[")
+		for i := 0; i < v.Length(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if sb.Len() > limit {
+				break
+			}
+			sb.WriteString(renderShallow(v.Get(i)))
+		}
+		sb.WriteString("]")
+	default:
+		sb.WriteString(renderShallow(value))
+	}
+	return truncateString(sb.String(), limit)
+}
+
+// renderShallow 渲染单个值，容器只显示元素数量
+// renderShallow renders a single value, containers only show their element count
+func renderShallow(value IValue) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case IObject:
+		return "{…" + strconv.Itoa(v.Size()) + " keys}"
+	case IArray:
+		return "[…" + strconv.Itoa(v.Length()) + " items]"
+	default:
+		if v.Type() == StringValueType {
+			return strconv.Quote(v.String())
+		}
+		return v.String()
+	}
+}
+
+// truncateString 按字符边界截断字符串并追加省略号
+// truncateString truncates a string at a rune boundary and appends an ellipsis
+func truncateString(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// RequireGet 使用JSONPath获取值，失败时panic
+// RequireGet gets a value using JSONPath, panics on failure
+//
+// 与返回零值的MustGet系列不同，Require系列在失败时panic，panic值为*JSONError，
+// 其中包含路径、实际类型和最近父节点的截断渲染
+// Unlike the MustGet family which returns zero values, the Require family panics on failure with a *JSONError
+// carrying the path, the actual type found and a truncated rendering of the nearest parent node
+//
+// 示例 Example:
+//
+//	age := xyJson.RequireInt(root, "$.user.age")
+//	// panic: [TYPE_MISMATCH] expected number but got string at path '$.user.age' in $.user object {"age": "x"}
+func RequireGet(root IValue, path string) IValue {
+	result, err := Get(root, path)
+	if err != nil {
+		panic(withPathContext(root, path, err))
+	}
+	return result
+}
+
+// RequireString 使用JSONPath获取字符串值，失败时panic
+// RequireString gets string value using JSONPath, panics on failure
+func RequireString(root IValue, path string) string {
+	result, err := GetString(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireInt 使用JSONPath获取整数值，失败时panic
+// RequireInt gets integer value using JSONPath, panics on failure
+func RequireInt(root IValue, path string) int {
+	result, err := GetInt(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireInt64 使用JSONPath获取64位整数值，失败时panic
+// RequireInt64 gets 64-bit integer value using JSONPath, panics on failure
+func RequireInt64(root IValue, path string) int64 {
+	result, err := GetInt64(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireFloat64 使用JSONPath获取浮点数值，失败时panic
+// RequireFloat64 gets float64 value using JSONPath, panics on failure
+func RequireFloat64(root IValue, path string) float64 {
+	result, err := GetFloat64(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireBool 使用JSONPath获取布尔值，失败时panic
+// RequireBool gets boolean value using JSONPath, panics on failure
+func RequireBool(root IValue, path string) bool {
+	result, err := GetBool(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireObject 使用JSONPath获取对象值，失败时panic
+// RequireObject gets object value using JSONPath, panics on failure
+func RequireObject(root IValue, path string) IObject {
+	result, err := GetObject(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// RequireArray 使用JSONPath获取数组值，失败时panic
+// RequireArray gets array value using JSONPath, panics on failure
+func RequireArray(root IValue, path string) IArray {
+	result, err := GetArray(root, path)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package xyJson
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CompletionHook 操作完成时调用的钩子，size为字节数，duration为耗时，err为操作返回的错误
+// CompletionHook is the hook called when an operation completes, size being the number of bytes, duration
+// the time taken and err the error returned by the operation
+type CompletionHook func(size int, duration time.Duration, err error)
+
+var (
+	// parseHooks Parse和ParseString完成时调用的钩子
+	// parseHooks are the hooks called when Parse and ParseString complete
+	parseHooks = &hookList{}
+
+	// serializeHooks Serialize和SerializeToString完成时调用的钩子
+	// serializeHooks are the hooks called when Serialize and SerializeToString complete
+	serializeHooks = &hookList{}
+)
+
+// OnParseComplete 注册Parse和ParseString完成时调用的钩子，size为输入的字节数，返回注销该钩子的函数
+// OnParseComplete registers a hook called when Parse and ParseString complete, size being the number of
+// input bytes, and returns the function unregistering the hook
+//
+// 钩子独立于性能监控器，不需要启用监控即可采集自己的指标；钩子在调用方的goroutine中同步执行，应当轻量。
+// 没有注册钩子时不会读取时钟，也不会产生任何内存分配
+// Hooks are independent of the performance monitor, apps collect their own metrics without enabling
+// monitoring; hooks run synchronously on the caller's goroutine and should be lightweight. When no hook is
+// registered the clock is not read and nothing is allocated
+//
+// 示例 Example:
+//
+//	remove := xyJson.OnParseComplete(func(size int, d time.Duration, err error) {
+//		parseBytes.Add(float64(size))
+//		parseSeconds.Observe(d.Seconds())
+//	})
+//	defer remove()
+func OnParseComplete(hook CompletionHook) (remove func()) {
+	return parseHooks.add(hook)
+}
+
+// OnSerializeComplete 注册Serialize和SerializeToString完成时调用的钩子，size为输出的字节数（出错时为0），
+// 返回注销该钩子的函数；其余行为与OnParseComplete相同
+// OnSerializeComplete registers a hook called when Serialize and SerializeToString complete, size being the
+// number of output bytes (0 on error), and returns the function unregistering the hook; it otherwise
+// behaves like OnParseComplete
+func OnSerializeComplete(hook CompletionHook) (remove func()) {
+	return serializeHooks.add(hook)
+}
+
+// hookEntry 已注册的钩子及其编号
+// hookEntry is a registered hook with its id
+type hookEntry struct {
+	id   uint64
+	hook CompletionHook
+}
+
+// hookList 写时复制的钩子列表，读取只需一次原子加载
+// hookList is a copy-on-write hook list, reading takes a single atomic load
+type hookList struct {
+	mu      sync.Mutex
+	entries atomic.Pointer[[]hookEntry]
+	nextID  uint64
+}
+
+// add 注册钩子并返回注销函数，hook为nil时不注册
+// add registers a hook and returns the unregister function, nothing is registered when hook is nil
+func (hl *hookList) add(hook CompletionHook) func() {
+	if hook == nil {
+		return func() {}
+	}
+
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	hl.nextID++
+	id := hl.nextID
+	var entries []hookEntry
+	if current := hl.entries.Load(); current != nil {
+		entries = append(entries, *current...)
+	}
+	entries = append(entries, hookEntry{id: id, hook: hook})
+	hl.entries.Store(&entries)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { hl.remove(id) })
+	}
+}
+
+// remove 注销编号为id的钩子，最后一个钩子注销后列表恢复为nil
+// remove unregisters the hook numbered id, the list goes back to nil after the last hook is unregistered
+func (hl *hookList) remove(id uint64) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	current := hl.entries.Load()
+	if current == nil {
+		return
+	}
+	var entries []hookEntry
+	for _, entry := range *current {
+		if entry.id != id {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		hl.entries.Store(nil)
+		return
+	}
+	hl.entries.Store(&entries)
+}
+
+// start 有钩子时返回当前钩子和开始时间，没有时返回nil
+// start returns the current hooks and the start time when there are hooks, nil otherwise
+func (hl *hookList) start() (*[]hookEntry, time.Time) {
+	entries := hl.entries.Load()
+	if entries == nil {
+		return nil, time.Time{}
+	}
+	return entries, time.Now()
+}
+
+// runHooks 以操作结果调用钩子，entries为nil时不做任何事
+// runHooks calls the hooks with the outcome of an operation, doing nothing when entries is nil
+func runHooks(entries *[]hookEntry, start time.Time, size int, err error) {
+	if entries == nil {
+		return
+	}
+	duration := time.Since(start)
+	for _, entry := range *entries {
+		entry.hook(size, duration, err)
+	}
+}
@@ -0,0 +1,79 @@
+package xyJson
+
+import "io"
+
+// forwardWriter 记录首个写入错误的转发目标
+// forwardWriter is a forwarding destination recording the first write error
+type forwardWriter struct {
+	w   io.Writer
+	err error
+}
+
+// Write 写入目标并记录错误
+// Write writes to the destination and records the error
+func (fw *forwardWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err != nil && fw.err == nil {
+		fw.err = err
+	}
+	return n, err
+}
+
+// InspectAndForward 将输入原样转发到输出，同时提取指定路径的值
+// InspectAndForward forwards the input to the output unchanged while extracting the values at the given paths
+//
+// 输出与输入逐字节相同，数据从不经过重新编码；每个匹配以请求的路径字符串和值调用fn，值按文档顺序报告。
+// 输入可以包含多个连续的顶层JSON值（如NDJSON）。任何路径无法编译时在读取输入前返回错误；
+// 输入不是合法JSON时剩余数据仍会被完整转发，随后返回检查错误
+// The output is byte-identical to the input, the data is never re-encoded; fn is called with the requested
+// path string and the value for each match, in document order. The input may contain several consecutive
+// top-level JSON values (such as NDJSON). If any path fails to compile an error is returned before the input
+// is read; if the input is not valid JSON the rest of it is still forwarded in full before the inspection
+// error is returned
+//
+// 示例 Example:
+//
+//	err := xyJson.InspectAndForward(req.Body, upstream, []string{"$.user.id", "$.items[*].sku"},
+//		func(path string, v xyJson.IValue) {
+//			log.Printf("%s = %s", path, v.String())
+//		})
+func InspectAndForward(r io.Reader, w io.Writer, paths []string, fn func(path string, v IValue)) error {
+	if r == nil {
+		return NewNullPointerError("reader cannot be nil")
+	}
+	if w == nil {
+		return NewNullPointerError("writer cannot be nil")
+	}
+	if fn == nil {
+		return NewNullPointerError("callback cannot be nil")
+	}
+
+	queries := make([][]*pathSegment, len(paths))
+	for i, path := range paths {
+		compiled, err := CompilePath(path)
+		if err != nil {
+			return err
+		}
+		compiled.mu.RLock()
+		queries[i] = compiled.segments
+		compiled.mu.RUnlock()
+	}
+
+	// 读取的每个字节都先写入输出，缓冲区中的数据因此已经转发
+	// Every byte read is written to the output first, so buffered data has already been forwarded
+	fw := &forwardWriter{w: w}
+	sq := newStreamQuery(io.TeeReader(r, fw), queries, func(query int, value IValue) bool {
+		fn(paths[query], value)
+		return true
+	})
+	err := sq.run()
+	if fw.err != nil {
+		return fw.err
+	}
+	if err != nil {
+		if _, copyErr := io.Copy(fw, r); copyErr != nil {
+			return copyErr
+		}
+	}
+	return err
+}
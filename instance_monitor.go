@@ -0,0 +1,39 @@
+package xyJson
+
+// NewParserWithMonitor 创建绑定指定性能监控器的解析器，Parse和ParseString的耗时、内存和错误记录到该监控器
+// NewParserWithMonitor creates a parser bound to the given performance monitor, recording the time, memory and
+// errors of Parse and ParseString there
+//
+// 包级Parse函数仍然使用全局监控器；多租户服务可以为每个租户创建独立的监控器和解析器。
+// monitor为nil时与NewParser相同，不计时
+// The package-level Parse functions keep using the global monitor; multi-tenant services can create a
+// separate monitor and parser per tenant. A nil monitor behaves like NewParser, without timing
+//
+// 示例 Example:
+//
+//	monitor := xyJson.NewPerformanceMonitor()
+//	parser := xyJson.NewParserWithMonitor(monitor)
+//	value, err := parser.Parse(body)
+//	stats := monitor.GetStats()
+func NewParserWithMonitor(monitor *PerformanceMonitor) IParser {
+	p := NewParser().(*parser)
+	p.monitor = monitor
+	return p
+}
+
+// NewSerializerWithMonitor 使用指定选项创建绑定性能监控器的序列化器，Serialize和SerializeToString记录到该监控器
+// NewSerializerWithMonitor creates a serializer with the given options bound to a performance monitor,
+// recording Serialize and SerializeToString there
+//
+// options为nil时使用默认选项，monitor为nil时不计时
+// Default options are used when options is nil, no timing when monitor is nil
+//
+// 示例 Example:
+//
+//	serializer := xyJson.NewSerializerWithMonitor(&xyJson.SerializeOptions{Compact: true, MaxDepth: xyJson.DefaultMaxDepth}, tenant.Monitor)
+//	data, err := serializer.Serialize(value)
+func NewSerializerWithMonitor(options *SerializeOptions, monitor *PerformanceMonitor) ISerializer {
+	s := NewSerializerWithOptions(options).(*serializer)
+	s.monitor = monitor
+	return s
+}
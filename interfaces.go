@@ -1,6 +1,7 @@
 package xyJson
 
 import (
+	"log/slog"
 	"time"
 )
 
@@ -130,6 +131,159 @@ type IValue interface {
 	// 返回值 Returns:
 	//   - IArray: 转换后的数组值 / Converted array value
 	AsArray() IArray
+
+	// AsInt64OK 返回整数值，仅当值是可无损表示为int64的JSON数字时ok为true；不转换字符串和布尔值，不分配内存
+	// AsInt64OK returns the integer value, ok is true only when the value is a JSON number exactly representable
+	// as int64; strings and booleans are not converted and nothing is allocated
+	//
+	// 返回值 Returns:
+	//   - int64: 整数值 / Integer value
+	//   - bool: 是否成功 / Whether it succeeded
+	AsInt64OK() (int64, bool)
+
+	// AsFloat64OK 返回浮点数值，仅当值是JSON数字时ok为true；不转换字符串和布尔值，不分配内存
+	// AsFloat64OK returns the float value, ok is true only when the value is a JSON number; strings and booleans
+	// are not converted and nothing is allocated
+	//
+	// 返回值 Returns:
+	//   - float64: 浮点数值 / Float value
+	//   - bool: 是否成功 / Whether it succeeded
+	AsFloat64OK() (float64, bool)
+
+	// AsStringOK 返回字符串值，仅当值是JSON字符串时ok为true，不分配内存
+	// AsStringOK returns the string value, ok is true only when the value is a JSON string, nothing is allocated
+	//
+	// 返回值 Returns:
+	//   - string: 字符串值 / String value
+	//   - bool: 是否成功 / Whether it succeeded
+	AsStringOK() (string, bool)
+
+	// AsBoolOK 返回布尔值，仅当值是JSON布尔值时ok为true，不分配内存
+	// AsBoolOK returns the boolean value, ok is true only when the value is a JSON boolean, nothing is allocated
+	//
+	// 返回值 Returns:
+	//   - bool: 布尔值 / Boolean value
+	//   - bool: 是否成功 / Whether it succeeded
+	AsBoolOK() (bool, bool)
+
+	// SetMeta 为值附加元数据，元数据不参与序列化和比较，value为nil时删除该键
+	// SetMeta attaches metadata to the value, metadata takes no part in serialization or comparison,
+	// a nil value removes the key
+	//
+	// 参数 Parameters:
+	//   - key: 元数据键 / Metadata key
+	//   - value: 元数据值 / Metadata value
+	SetMeta(key string, value interface{})
+
+	// Meta 返回元数据值，不存在时返回nil
+	// Meta returns a metadata value, nil if absent
+	//
+	// 参数 Parameters:
+	//   - key: 元数据键 / Metadata key
+	//
+	// 返回值 Returns:
+	//   - interface{}: 元数据值 / Metadata value
+	Meta(key string) interface{}
+
+	// MetaKeys 返回排序后的元数据键
+	// MetaKeys returns the sorted metadata keys
+	//
+	// 返回值 Returns:
+	//   - []string: 元数据键列表 / List of metadata keys
+	MetaKeys() []string
+
+	// LogValue 实现slog.LogValuer，值可以直接传给slog，只有日志级别启用时才会被渲染
+	// LogValue implements slog.LogValuer so values can be passed to slog directly, rendered only when the
+	// log level is enabled
+	//
+	// 示例 Example:
+	//
+	//	slog.Debug("request", "body", doc)
+	//
+	// 返回值 Returns:
+	//   - slog.Value: 对象为分组，数组为JSON，标量为原始值 / Groups for objects, JSON for arrays, raw values for scalars
+	LogValue() slog.Value
+
+	// CachedJSON 返回值用默认序列化器序列化后的字节，只要树未通过本库的API修改就复用上一次的结果，
+	// 适合反复输出不变的文档（例如通过HTTP提供的配置快照）
+	// CachedJSON returns the bytes of the value serialized with the default serializer, reusing the previous
+	// result as long as the tree was not modified through this library's APIs, suited to documents output
+	// over and over unchanged (such as configuration snapshots served over HTTP)
+	//
+	// 验证缓存需要遍历树，但不排序、不分配，远比序列化便宜；标量不缓存。返回的字节在多次调用间共享，不能修改。
+	// 替换默认序列化器后缓存失效。与其他读取一样，不能与修改并发调用
+	// Validating the cache walks the tree, without sorting or allocating, which is far cheaper than
+	// serializing; scalars are not cached. The returned bytes are shared between calls and must not be
+	// modified. Replacing the default serializer invalidates the cache. Like other reads, it must not run
+	// concurrently with modifications
+	//
+	// 示例 Example:
+	//
+	//	data, err := config.CachedJSON()
+	//	if err != nil {
+	//		return err
+	//	}
+	//	w.Write(data)
+	//
+	// 返回值 Returns:
+	//   - []byte: 紧凑的JSON，只读 / Compact JSON, read-only
+	//   - error: 序列化错误 / Serialization error
+	CachedJSON() ([]byte, error)
+
+	// Version 返回树的版本号，通过本库的API对树中任何位置的修改（Set、Delete、Append等）都会使之后返回的版本号变大；
+	// 版本号只在进程内有意义，标量总是0
+	// Version returns the version of the tree, any modification anywhere in the tree through this library's
+	// APIs (Set, Delete, Append and so on) makes the versions returned afterwards greater; versions are only
+	// meaningful within the process, and always 0 for scalars
+	//
+	// 示例 Example:
+	//
+	//	seen := doc.Version()
+	//	// ...
+	//	if doc.Version() != seen {
+	//		reload()
+	//	}
+	Version() uint64
+
+	// ETag 返回CachedJSON结果的强实体标签（带引号的内容哈希），内容相同的文档在不同进程中得到相同的标签；
+	// 哈希与序列化结果一起缓存，只在树被修改后重新计算
+	// ETag returns the strong entity tag of the CachedJSON result (a quoted content hash), documents with the
+	// same content get the same tag in every process; the hash is cached along with the serialization and only
+	// computed again after the tree was modified
+	//
+	// 示例 Example:
+	//
+	//	etag, err := config.ETag()
+	//	if err == nil && r.Header.Get("If-None-Match") == etag {
+	//		w.WriteHeader(http.StatusNotModified)
+	//		return
+	//	}
+	//	w.Header().Set("ETag", etag)
+	ETag() (string, error)
+
+	// Snapshot 返回值当前状态的不可变快照，快照上的多次读取彼此一致，即使原文档随后被其他协程修改，
+	// 适合读取实时配置的指标采集器
+	// Snapshot returns an immutable snapshot of the current state of the value, reads from the snapshot are
+	// consistent with each other even while other goroutines go on modifying the original, suited to metric
+	// scrapers reading live configuration
+	//
+	// 快照与之前的快照共享自上次快照以来未修改的子树，因此只有修改过的部分需要拷贝，但仍需遍历整个树；
+	// 原文档保留最近一次快照的拷贝。建立快照时逐个容器读取，与修改并发时各容器各自一致，
+	// 需要跨容器的原子快照时使用SharedDocument.Snapshot
+	// A snapshot shares the subtrees unmodified since the previous snapshot with it, so only modified parts are
+	// copied, though the whole tree is still walked; the original retains the copy of its latest snapshot.
+	// Taking a snapshot reads one container at a time, so concurrently with modifications each container is
+	// consistent on its own; use SharedDocument.Snapshot for a snapshot that is atomic across containers
+	//
+	// 示例 Example:
+	//
+	//	snap, err := config.Snapshot()
+	//	if err != nil {
+	//		return err
+	//	}
+	//	limit, _ := snap.Get("$.limits.cpu")
+	//	hosts, _ := snap.GetAll("$.hosts[*]")
+	Snapshot() (*DocumentSnapshot, error)
 }
 
 // IScalarValue 标量值接口（字符串、数字、布尔值）
@@ -246,8 +400,14 @@ type IObject interface {
 	// Clear removes all key-value pairs from the object
 	Clear()
 
-	// Range 遍历对象中的所有键值对
-	// Range iterates over all key-value pairs in the object
+	// Range 按键名顺序遍历对象中的所有键值对
+	// Range iterates over all key-value pairs in the object in key order
+	//
+	// 遍历的是调用时键值对的快照，fn可以修改对象：遍历中删除、添加或替换的键值对不影响本次遍历，
+	// 已删除的键仍以原值被访问，新添加的键不会被访问
+	// The iteration runs over a snapshot of the pairs taken at the call, so fn may modify the object: pairs
+	// deleted, added or replaced during the iteration do not affect it, deleted keys are still visited with
+	// their original values and added keys are not visited
 	//
 	// 参数 Parameters:
 	//   - fn: 遍历函数，接收键名和值作为参数，返回false可提前终止遍历
@@ -263,6 +423,54 @@ type IObject interface {
 	//		return true // 继续遍历
 	//	})
 	Range(fn func(key string, value IValue) bool)
+
+	// KeysInOrder 返回按规范顺序排列的键名，规范顺序即按字节比较的键名升序，与Keys、Range和序列化的顺序相同，
+	// 不依赖插入顺序、运行次数或平台
+	// KeysInOrder returns the keys in canonical order, which is ascending byte-wise key order, the same order as
+	// Keys, Range and serialization; it does not depend on insertion order, the run or the platform
+	KeysInOrder() []string
+
+	// ToOrderedPairs 返回按规范顺序排列的键值对，值不会被复制
+	// ToOrderedPairs returns the pairs in canonical order, the values are not copied
+	//
+	// 用于将有序数据交给签名、模板等需要确定顺序的系统而无需重新排序
+	// Meant for feeding ordered data into systems that need a deterministic order, such as signing or
+	// templating, without sorting again
+	//
+	// 示例 Example:
+	//
+	//	for _, kv := range obj.ToOrderedPairs() {
+	//		mac.Write([]byte(kv.Key))
+	//		mac.Write([]byte(kv.Value.String()))
+	//	}
+	ToOrderedPairs() []KV
+
+	// UnsafeRange 以未指定的顺序遍历键值对，不复制快照，遍历期间持有对象的读锁
+	// UnsafeRange iterates over the pairs in an unspecified order without copying a snapshot, holding the read
+	// lock of the object during the iteration
+	//
+	// 用于热路径上的只读遍历。fn不能修改该对象，否则会死锁；其它协程的修改会等待遍历结束
+	// Meant for read-only iteration on hot paths. fn must not modify the object or it deadlocks; modifications
+	// from other goroutines wait until the iteration ends
+	UnsafeRange(fn func(key string, value IValue) bool)
+
+	// SetAll 一次性设置多个键值对，只扩容一次
+	// SetAll sets multiple key-value pairs at once, growing only once
+	//
+	// 任一键为空时不做任何修改并返回错误；nil值设置为null
+	// Nothing is changed and an error is returned when any key is empty; nil values are set to null
+	//
+	// 示例 Example:
+	//
+	//	err := obj.SetAll(map[string]IValue{
+	//		"id":   factory.CreateNumber(1),
+	//		"name": factory.CreateString("Alice"),
+	//	})
+	SetAll(values map[string]IValue) error
+
+	// EnsureCapacity 确保对象至少能容纳capacity个键而无需扩容
+	// EnsureCapacity ensures the object can hold at least capacity keys without growing
+	EnsureCapacity(capacity int)
 }
 
 // IArray JSON数组接口
@@ -300,7 +508,31 @@ type IArray interface {
 
 	// Range 遍历数组元素
 	// Range iterates over array elements
+	//
+	// 与IObject.Range相同，遍历的是调用时元素的快照，fn可以修改数组
+	// As with IObject.Range the iteration runs over a snapshot of the elements taken at the call, so fn may
+	// modify the array
 	Range(fn func(index int, value IValue) bool)
+
+	// AppendAll 一次性追加多个值，只扩容一次；nil元素追加为null
+	// AppendAll appends multiple values at once, growing only once; nil elements are appended as null
+	//
+	// 示例 Example:
+	//
+	//	arr.AppendAll(values...)
+	AppendAll(values ...IValue)
+
+	// Grow 预留至少n个元素的空间，之后的n次追加不会再扩容；n不大于0时不做任何事
+	// Grow reserves room for at least n more elements, so the next n appends do not reallocate; does nothing
+	// when n is not positive
+	//
+	// 示例 Example:
+	//
+	//	arr.Grow(len(rows))
+	//	for _, row := range rows {
+	//		arr.Append(row)
+	//	}
+	Grow(n int)
 }
 
 // IParser JSON解析器接口
@@ -341,6 +573,24 @@ type IParser interface {
 	// GetMaxDepth 获取最大解析深度
 	// GetMaxDepth gets the maximum parsing depth
 	GetMaxDepth() int
+
+	// Reset 设置下一次ParseInto要解析的数据并重置解析器状态
+	// Reset sets the data for the next ParseInto and resets the parser state
+	Reset(data []byte)
+
+	// ParseInto 解析Reset设置的数据，结构相同的位置原地覆盖root中已有的节点，返回解析后的根
+	// ParseInto parses the data set by Reset, overwriting the existing nodes of root in place where the
+	// structure matches, and returns the parsed root
+	//
+	// 用于反复解析结构相同的消息的循环，解析器和值树都可以复用；root必须由调用方独占
+	// Meant for loops that repeatedly parse same-shaped messages, reusing both the parser and the value tree;
+	// root must be owned exclusively by the caller
+	//
+	// 示例 Example:
+	//
+	//	parser.Reset(msg)
+	//	doc, err = parser.ParseInto(doc)
+	ParseInto(root IValue) (IValue, error)
 }
 
 // ISerializer JSON序列化器接口
@@ -537,6 +787,14 @@ type IObjectPool interface {
 	// GetStats 获取池统计信息
 	// GetStats gets pool statistics
 	GetStats() *PoolStats
+
+	// Warm 预分配指定数量的标量值、对象和数组放入池中
+	// Warm preallocates the given numbers of scalar values, objects and arrays into the pool
+	Warm(nValues, nObjects, nArrays int)
+
+	// Close 停止池的后台清理协程
+	// Close stops the background cleanup goroutine of the pool
+	Close() error
 }
 
 // SerializeOptions 序列化选项
@@ -565,6 +823,35 @@ type SerializeOptions struct {
 	// MaxDepth 最大序列化深度
 	// MaxDepth is the maximum serialization depth
 	MaxDepth int
+
+	// ArraySort 序列化前对匹配数组的元素排序的规则，不修改原文档
+	// ArraySort holds rules that sort the elements of matching arrays before emission, without modifying
+	// the document
+	ArraySort []ArraySortRule
+
+	// AllowNonFinite 是否将NaN和正负无穷输出为NaN、Infinity、-Infinity字面量（非标准扩展），否则输出null
+	// AllowNonFinite indicates whether NaN and infinities are emitted as the NaN, Infinity and -Infinity
+	// literals (a non-standard extension) instead of null
+	AllowNonFinite bool
+	// Iterative 是否使用显式栈代替递归，用于嵌套极深的文档；输出与递归实现相同，需要同时提高MaxDepth
+	// Iterative indicates whether an explicit stack is used instead of recursion, for extremely deeply nested
+	// documents; the output is identical to the recursive implementation, and MaxDepth must be raised as well
+	Iterative bool
+
+	// KeyTransform 输出时对对象键名应用的命名风格转换，不修改文档
+	// KeyTransform is the naming style conversion applied to object keys on output, without modifying the
+	// document
+	KeyTransform KeyTransform
+
+	// YieldInterval 每序列化多少个值调用一次runtime.Gosched，避免序列化巨大文档时饿死同一P上的其它协程；0表示从不让出
+	// YieldInterval is the number of values serialized between calls to runtime.Gosched, so serializing a huge
+	// document does not starve other goroutines on the same P; 0 never yields
+	YieldInterval int
+
+	// EscapeContext 输出将被嵌入的上下文（script、HTML属性或URL），决定EscapeHTML之外的额外转义
+	// EscapeContext is the context the output will be embedded in (script, HTML attribute or URL), deciding
+	// the extra escaping beyond EscapeHTML
+	EscapeContext EscapeContext
 }
 
 // PoolStats 对象池统计信息
@@ -585,4 +872,16 @@ type PoolStats struct {
 	// PoolHitRate 池命中率
 	// PoolHitRate is the pool hit rate
 	PoolHitRate float64
+
+	// WarmTarget 已请求预热的对象总数
+	// WarmTarget is the total number of objects requested for warm-up
+	WarmTarget int64
+
+	// WarmCompleted 已预热完成的对象数，等于WarmTarget时预热结束
+	// WarmCompleted is the number of objects warmed so far, warm-up is finished when it equals WarmTarget
+	WarmCompleted int64
+
+	// Shrinks 因空闲而丢弃池中对象的次数
+	// Shrinks is the number of times the pool entries were dropped for being idle
+	Shrinks int64
 }
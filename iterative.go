@@ -0,0 +1,306 @@
+package xyJson
+
+import (
+	"bytes"
+	"sort"
+)
+
+// parseFrame 迭代解析时一个未完成的对象或数组
+// parseFrame is an unfinished object or array during iterative parsing
+type parseFrame struct {
+	obj IObject
+	arr IArray
+	key string
+}
+
+// container 返回帧对应的容器值
+// container returns the container value of the frame
+func (f *parseFrame) container() IValue {
+	if f.obj != nil {
+		return f.obj
+	}
+	return f.arr
+}
+
+// parseValueIterative 使用显式栈解析值，嵌套深度只受MaxDepth限制而不消耗goroutine栈；
+// 结果和错误与parseValue相同
+// parseValueIterative parses a value with an explicit stack, so the nesting depth is bounded only by
+// MaxDepth and does not consume goroutine stack; results and errors match parseValue
+func (p *parser) parseValueIterative() (IValue, error) {
+	var stack []parseFrame
+
+	for {
+		// 解析一个值的开头
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return nil, NewInvalidJSONError("unexpected end of input", nil)
+		}
+
+		var value IValue
+		switch ch := p.data[p.pos]; ch {
+		case '{', '[':
+			if len(stack) >= p.maxDepth {
+				return nil, NewInvalidJSONError("maximum depth exceeded", nil)
+			}
+			p.advance()
+			p.skipWhitespace()
+
+			if ch == '{' {
+				obj := p.factory.CreateObject()
+				if p.pos < len(p.data) && p.data[p.pos] == '}' {
+					p.advance()
+					value = obj
+					break
+				}
+				p.sizeObject(obj)
+				stack = append(stack, parseFrame{obj: obj})
+				if err := p.parseMemberKey(&stack[len(stack)-1]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			arr := p.factory.CreateArray()
+			if p.pos < len(p.data) && p.data[p.pos] == ']' {
+				p.advance()
+				value = arr
+				break
+			}
+			p.sizeArray(arr)
+			stack = append(stack, parseFrame{arr: arr})
+			continue
+		default:
+			var err error
+			if value, err = p.parseValue(); err != nil {
+				return nil, err
+			}
+		}
+
+		// 值已完成，放入父容器；父容器结束时继续向上完成
+		// The value is complete, store it in its parent; when the parent ends, complete it in turn
+		for {
+			if len(stack) == 0 {
+				return value, nil
+			}
+
+			top := &stack[len(stack)-1]
+			if top.obj != nil {
+				if err := top.obj.Set(top.key, value); err != nil {
+					return nil, err
+				}
+			} else {
+				top.arr.Append(value)
+			}
+
+			p.skipWhitespace()
+			if p.pos >= len(p.data) {
+				if top.obj != nil {
+					return nil, NewInvalidJSONError("unexpected end of input in object", nil)
+				}
+				return nil, NewInvalidJSONError("unexpected end of input in array", nil)
+			}
+
+			ch := p.data[p.pos]
+			if ch == ',' {
+				p.advance()
+				if top.obj != nil {
+					if err := p.parseMemberKey(top); err != nil {
+						return nil, err
+					}
+				}
+				break
+			}
+
+			if top.obj != nil && ch == '}' || top.arr != nil && ch == ']' {
+				p.advance()
+				if top.obj != nil {
+					p.countObject(top.obj)
+				} else {
+					p.countArray(top.arr)
+				}
+				value = top.container()
+				stack[len(stack)-1] = parseFrame{}
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			if top.obj != nil {
+				return nil, NewInvalidJSONError("expected ',' or '}'", nil)
+			}
+			return nil, NewInvalidJSONError("expected ',' or ']'", nil)
+		}
+	}
+}
+
+// parseMemberKey 解析对象成员的键和冒号，检查重复键并记录到帧中
+// parseMemberKey parses the key and colon of an object member, checks for duplicates and records the key
+// in the frame
+func (p *parser) parseMemberKey(f *parseFrame) error {
+	p.skipWhitespace()
+	if p.pos >= len(p.data) {
+		return NewInvalidJSONError("unexpected end of input in object", nil)
+	}
+	if p.data[p.pos] != '"' {
+		return NewInvalidJSONError("expected string key", nil)
+	}
+
+	key, _, _, err := p.parseKey(nil, 0, false)
+	if err != nil {
+		return err
+	}
+
+	p.skipWhitespace()
+	if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+		return NewInvalidJSONError("expected ':'", nil)
+	}
+	p.advance() // 跳过 ':'
+
+	key = p.keyTransform.Apply(key)
+	if f.obj.Has(key) {
+		return NewInvalidJSONError("duplicate key: "+key, nil)
+	}
+	f.key = key
+	return nil
+}
+
+// serializeFrame 迭代序列化时一个未写完的对象或数组
+// serializeFrame is an object or array that is not fully written during iterative serialization
+type serializeFrame struct {
+	value IValue
+	obj   IObject
+	keys  []string
+	arr   IArray
+	order []int
+	next  int
+	depth int
+	first bool
+}
+
+// nextChild 返回下一个非nil的子值及其键，没有剩余子值时ok为false
+// nextChild returns the next non-nil child and its key, ok is false when no children remain
+func (f *serializeFrame) nextChild() (key string, child IValue, ok bool) {
+	if f.obj != nil {
+		for f.next < len(f.keys) {
+			key = f.keys[f.next]
+			f.next++
+			if child = f.obj.Get(key); child != nil {
+				return key, child, true
+			}
+		}
+		return "", nil, false
+	}
+
+	for f.next < f.arr.Length() {
+		index := f.next
+		if f.order != nil {
+			index = f.order[f.next]
+		}
+		f.next++
+		if child = f.arr.Get(index); child != nil {
+			return "", child, true
+		}
+	}
+	return "", nil, false
+}
+
+// serializeIterative 使用显式栈序列化值，输出与serializeValue逐字节相同
+// serializeIterative serializes a value with an explicit stack, producing output byte-for-byte identical
+// to serializeValue
+func (s *serializer) serializeIterative(root IValue, buf *bytes.Buffer) error {
+	indent := s.options.Indent != "" && !s.options.Compact
+	visited := make(map[IValue]bool)
+	var stack []serializeFrame
+
+	// open 写出标量，或写出容器的开始括号并入栈
+	// open writes a scalar, or writes the opening bracket of a container and pushes it
+	open := func(value IValue, depth int) error {
+		if value == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		if depth > s.options.MaxDepth {
+			return NewInvalidJSONError("maximum serialization depth exceeded", nil)
+		}
+		if visited[value] {
+			return NewInvalidJSONError("circular reference detected", nil)
+		}
+		s.yield.step(1)
+
+		frame := serializeFrame{value: value, depth: depth, first: true}
+		switch value.Type() {
+		case ObjectValueType:
+			frame.obj = value.(IObject)
+			frame.keys = frame.obj.Keys()
+			if s.options.SortKeys {
+				sort.Strings(frame.keys)
+			}
+			buf.WriteByte('{')
+		case ArrayValueType:
+			frame.arr = value.(IArray)
+			frame.order = s.arrayOrder[frame.arr]
+			buf.WriteByte('[')
+		default:
+			return s.serializeScalar(value, buf)
+		}
+		visited[value] = true
+		stack = append(stack, frame)
+		return nil
+	}
+
+	if err := open(root, 0); err != nil {
+		return err
+	}
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		key, child, ok := top.nextChild()
+		if !ok {
+			// 添加结束缩进
+			if indent && !top.first {
+				buf.WriteByte('\n')
+				for i := 0; i < top.depth; i++ {
+					buf.WriteString(s.options.Indent)
+				}
+			}
+			if top.obj != nil {
+				buf.WriteByte('}')
+			} else {
+				buf.WriteByte(']')
+			}
+			delete(visited, top.value)
+			stack[len(stack)-1] = serializeFrame{}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if !top.first {
+			buf.WriteByte(',')
+		}
+		top.first = false
+
+		// 添加缩进
+		if indent {
+			buf.WriteByte('\n')
+			for i := 0; i <= top.depth; i++ {
+				buf.WriteString(s.options.Indent)
+			}
+		}
+
+		if top.obj != nil {
+			if err := s.serializeString(s.options.KeyTransform.Apply(key), buf); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if indent {
+				buf.WriteByte(' ')
+			}
+		}
+
+		// open可能扩容栈，之后不再使用top
+		// open may grow the stack, top is not used afterwards
+		if err := open(child, top.depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package xyJson
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MetaJournal 保存文档变更日志（*Journal）的元数据键
+// MetaJournal is the metadata key holding the change journal (*Journal) of a document
+const MetaJournal = "journal"
+
+// JournalOp 变更日志记录的操作类型
+// JournalOp is the kind of operation recorded in the change journal
+type JournalOp int
+
+const (
+	// JournalSet 通过Set设置值
+	// JournalSet sets a value through Set
+	JournalSet JournalOp = iota
+	// JournalDelete 通过Delete删除值
+	// JournalDelete deletes a value through Delete
+	JournalDelete
+)
+
+// String 返回操作类型的名称
+// String returns the name of the operation
+func (op JournalOp) String() string {
+	switch op {
+	case JournalSet:
+		return "set"
+	case JournalDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// JournalEntry 一条变更记录，旧值和新值是记录时的深拷贝，不存在时为nil
+// JournalEntry is a single change record, the old and new values are deep copies taken at record time and nil
+// when absent
+type JournalEntry struct {
+	// Seq 从1开始递增的序号，清空或丢弃记录后也不会重复
+	// Seq is an increasing sequence number starting at 1, never reused after entries are cleared or dropped
+	Seq      uint64
+	Op       JournalOp
+	Path     string
+	OldValue IValue
+	NewValue IValue
+	Time     time.Time
+	Actor    string
+}
+
+// JournalOptions 变更日志配置选项
+// JournalOptions configures the change journal
+type JournalOptions struct {
+	// MaxEntries 保留的最大记录数，超出时丢弃最早的记录，0表示不限制
+	// MaxEntries is the maximum number of entries kept, the oldest are dropped beyond it; 0 means unlimited
+	MaxEntries int
+
+	// Clock 返回记录时间的函数，nil时使用time.Now
+	// Clock returns the record time, time.Now is used when nil
+	Clock func() time.Time
+}
+
+// DefaultJournalOptions 返回默认的变更日志选项
+// DefaultJournalOptions returns the default journal options
+func DefaultJournalOptions() *JournalOptions {
+	return &JournalOptions{
+		MaxEntries: 0,
+		Clock:      time.Now,
+	}
+}
+
+// Journal 记录通过Set、Delete和SetBatch对文档所做变更的审计日志，并发安全
+// Journal is an audit log of the changes made to a document through Set, Delete and SetBatch, safe for
+// concurrent use
+//
+// 只记录以启用日志的根值为root的调用；直接修改子节点（例如IObject.Set）或在克隆上的修改不会被记录
+// Only calls whose root is the value the journal was enabled on are recorded; mutating a child directly
+// (for example IObject.Set) or mutating a clone is not recorded
+type Journal struct {
+	root    IValue
+	options JournalOptions
+
+	mu      sync.Mutex
+	entries []JournalEntry
+	seq     uint64
+}
+
+// journalActorKey 上下文中保存操作者的键
+// journalActorKey is the context key holding the actor
+type journalActorKey struct{}
+
+// WithJournalActor 返回携带操作者的上下文，SetContext和DeleteContext将其写入变更记录
+// WithJournalActor returns a context carrying the actor, SetContext and DeleteContext record it in the journal
+//
+// 示例 Example:
+//
+//	ctx := xyJson.WithJournalActor(r.Context(), user.Name)
+//	xyJson.SetContext(ctx, config, "$.limits.rps", 200)
+func WithJournalActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, journalActorKey{}, actor)
+}
+
+// JournalActor 返回上下文中的操作者，不存在时返回空字符串
+// JournalActor returns the actor in the context, or an empty string when absent
+func JournalActor(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(journalActorKey{}).(string)
+	return actor
+}
+
+// EnableJournal 使用默认选项为根值启用变更日志，已启用时返回现有日志
+// EnableJournal enables the change journal on the root with default options, returning the existing journal
+// when already enabled
+//
+// 示例 Example:
+//
+//	config := xyJson.MustParseString(`{"limits":{"rps":100}}`)
+//	journal := xyJson.EnableJournal(config)
+//	xyJson.Set(config, "$.limits.rps", 200)
+//	data, _ := journal.Serialize() // [{"op":"set","path":"$.limits.rps","old":100,"new":200,...}]
+func EnableJournal(root IValue) *Journal {
+	return EnableJournalWithOptions(root, nil)
+}
+
+// EnableJournalWithOptions 使用指定选项为根值启用变更日志，已启用时返回现有日志且忽略选项
+// EnableJournalWithOptions enables the change journal on the root with the specified options, returning the
+// existing journal and ignoring the options when already enabled
+func EnableJournalWithOptions(root IValue, options *JournalOptions) *Journal {
+	if root == nil {
+		return nil
+	}
+	if journal := journalOf(root); journal != nil {
+		return journal
+	}
+	if options == nil {
+		options = DefaultJournalOptions()
+	}
+
+	journal := &Journal{root: root, options: *options}
+	if journal.options.Clock == nil {
+		journal.options.Clock = time.Now
+	}
+	root.SetMeta(MetaJournal, journal)
+	return journal
+}
+
+// DisableJournal 停止记录根值的变更，已有的记录仍可从返回的日志中读取
+// DisableJournal stops recording changes to the root, existing entries remain readable from the returned journal
+func DisableJournal(root IValue) *Journal {
+	journal := journalOf(root)
+	if journal != nil {
+		root.SetMeta(MetaJournal, nil)
+	}
+	return journal
+}
+
+// JournalOf 返回根值的变更日志，未启用时返回nil
+// JournalOf returns the change journal of the root, nil when not enabled
+func JournalOf(root IValue) *Journal {
+	return journalOf(root)
+}
+
+// journalOf 返回根值上启用的日志；克隆会复制元数据，因此只接受为该根值创建的日志
+// journalOf returns the journal enabled on the root; clones copy metadata, so only a journal created for this
+// very root is accepted
+func journalOf(root IValue) *Journal {
+	if root == nil {
+		return nil
+	}
+	journal, _ := root.Meta(MetaJournal).(*Journal)
+	if journal == nil || journal.root != root {
+		return nil
+	}
+	return journal
+}
+
+// record 追加一条记录，值应已是深拷贝
+// record appends an entry, the values must already be deep copies
+func (j *Journal) record(ctx context.Context, op JournalOp, path string, oldValue, newValue IValue) {
+	entry := JournalEntry{
+		Op:       op,
+		Path:     path,
+		OldValue: oldValue,
+		NewValue: newValue,
+		Time:     j.options.Clock(),
+		Actor:    JournalActor(ctx),
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.seq++
+	entry.Seq = j.seq
+	j.entries = append(j.entries, entry)
+	if max := j.options.MaxEntries; max > 0 && len(j.entries) > max {
+		drop := len(j.entries) - max
+		copy(j.entries, j.entries[drop:])
+		clear(j.entries[max:])
+		j.entries = j.entries[:max]
+	}
+}
+
+// Entries 返回按时间顺序排列的记录副本
+// Entries returns a copy of the entries in chronological order
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalEntry(nil), j.entries...)
+}
+
+// Since 返回序号大于seq的记录；其中有记录已被丢弃或清空时complete为false，此时调用方需要重新获取完整文档
+// Since returns the entries whose sequence number is greater than seq; complete is false when some of them were
+// dropped or cleared, in which case the caller has to fetch the whole document again
+func (j *Journal) Since(seq uint64) (entries []JournalEntry, complete bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if seq >= j.seq {
+		return nil, true
+	}
+	start := len(j.entries)
+	for start > 0 && j.entries[start-1].Seq > seq {
+		start--
+	}
+	entries = append([]JournalEntry(nil), j.entries[start:]...)
+	return entries, len(entries) > 0 && entries[0].Seq == seq+1
+}
+
+// Seq 返回最近一条记录的序号，没有记录时为0
+// Seq returns the sequence number of the latest entry, 0 when nothing was recorded
+func (j *Journal) Seq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Len 返回记录数
+// Len returns the number of entries
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// Clear 清空所有记录，日志保持启用
+// Clear removes all entries, the journal stays enabled
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	clear(j.entries)
+	j.entries = j.entries[:0]
+}
+
+// ToValue 将记录转换为JSON数组，每条记录包含op、path、old、new、time（RFC3339Nano），有操作者时包含actor
+// ToValue converts the entries to a JSON array, each entry holding op, path, old, new, time (RFC3339Nano) and
+// actor when present
+func (j *Journal) ToValue() IArray {
+	entries := j.Entries()
+	factory := defaultFactory()
+
+	arr := factory.CreateArray()
+	for _, entry := range entries {
+		obj := factory.CreateObject()
+		obj.Set("op", factory.CreateString(entry.Op.String()))
+		obj.Set("path", factory.CreateString(entry.Path))
+		obj.Set("old", journalValue(factory, entry.OldValue))
+		obj.Set("new", journalValue(factory, entry.NewValue))
+		obj.Set("time", factory.CreateString(entry.Time.Format(time.RFC3339Nano)))
+		if entry.Actor != "" {
+			obj.Set("actor", factory.CreateString(entry.Actor))
+		}
+		arr.Append(obj)
+	}
+	return arr
+}
+
+// Serialize 将记录序列化为紧凑的JSON数组
+// Serialize serializes the entries to a compact JSON array
+func (j *Journal) Serialize() ([]byte, error) {
+	return defaultSerializer().Serialize(j.ToValue())
+}
+
+// journalValue 返回记录值的拷贝，nil表示为JSON null，使记录保持不可变
+// journalValue returns a copy of a recorded value with nil rendered as JSON null, keeping the entry immutable
+func journalValue(factory IValueFactory, value IValue) IValue {
+	if value == nil {
+		return factory.CreateNull()
+	}
+	return value.Clone()
+}
+
+// journalOld 返回路径当前值的深拷贝，不存在时返回nil
+// journalOld returns a deep copy of the current value at the path, nil when absent
+func journalOld(root IValue, path string) IValue {
+	old, err := defaultPathQuery().SelectOne(root, path)
+	if err != nil || old == nil {
+		return nil
+	}
+	return old.Clone()
+}
+
+// SetContext 根据路径设置值，启用变更日志时记录上下文中的操作者
+// SetContext sets value by path, recording the actor from the context when the journal is enabled
+func SetContext(ctx context.Context, root IValue, path string, value any) (err error) {
+	defer recoverPanic("set", &err)
+	v, err := defaultFactory().CreateFromRaw(value)
+	if err != nil {
+		return err
+	}
+
+	journal := journalOf(root)
+	if journal == nil {
+		return defaultPathQuery().Set(root, path, v)
+	}
+	old := journalOld(root, path)
+	if err := defaultPathQuery().Set(root, path, v); err != nil {
+		return err
+	}
+	journal.record(ctx, JournalSet, path, old, v.Clone())
+	return nil
+}
+
+// DeleteContext 根据路径删除值，启用变更日志时记录上下文中的操作者
+// DeleteContext deletes value by path, recording the actor from the context when the journal is enabled
+func DeleteContext(ctx context.Context, root IValue, path string) (err error) {
+	defer recoverPanic("delete", &err)
+	journal := journalOf(root)
+	if journal == nil {
+		return defaultPathQuery().Delete(root, path)
+	}
+	old := journalOld(root, path)
+	if err := defaultPathQuery().Delete(root, path); err != nil {
+		return err
+	}
+	journal.record(ctx, JournalDelete, path, old, nil)
+	return nil
+}
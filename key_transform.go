@@ -0,0 +1,149 @@
+package xyJson
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyTransform 序列化和解析时对对象键名的命名风格转换
+// KeyTransform is the naming style conversion applied to object keys on serialize and parse
+type KeyTransform int
+
+const (
+	// KeyTransformNone 不转换键名
+	// KeyTransformNone leaves keys unchanged
+	KeyTransformNone KeyTransform = iota
+	// KeyTransformCamelCase 转换为camelCase，例如user_id -> userId
+	// KeyTransformCamelCase converts to camelCase, e.g. user_id -> userId
+	KeyTransformCamelCase
+	// KeyTransformSnakeCase 转换为snake_case，例如userId -> user_id
+	// KeyTransformSnakeCase converts to snake_case, e.g. userId -> user_id
+	KeyTransformSnakeCase
+	// KeyTransformKebabCase 转换为kebab-case，例如userId -> user-id
+	// KeyTransformKebabCase converts to kebab-case, e.g. userId -> user-id
+	KeyTransformKebabCase
+)
+
+// String 返回键名转换的字符串表示
+// String returns the string representation of the key transform
+func (kt KeyTransform) String() string {
+	switch kt {
+	case KeyTransformNone:
+		return "none"
+	case KeyTransformCamelCase:
+		return "camelCase"
+	case KeyTransformSnakeCase:
+		return "snake_case"
+	case KeyTransformKebabCase:
+		return "kebab-case"
+	default:
+		return "unknown"
+	}
+}
+
+// Apply 转换单个键名
+// Apply converts a single key
+//
+// 键名在下划线、连字符、空格以及小写到大写的边界处拆分为单词，连续大写的缩写在最后一个大写字母前拆分
+// （HTTPServer -> HTTP、Server），数字归属前一个单词。开头的下划线和连字符原样保留，
+// 因此_id在所有风格下保持不变。不同的键可能转换为同一个键名：序列化时都会输出，解析时报告重复键
+// Keys are split into words at underscores, hyphens, spaces and lower-to-upper boundaries, runs of capitals
+// are split before their last capital (HTTPServer -> HTTP, Server), and digits belong to the preceding word.
+// Leading underscores and hyphens are kept as they are, so _id stays unchanged in every style. Different keys
+// may convert to the same key: serialization emits both, parsing reports a duplicate key
+//
+// 示例 Example:
+//
+//	xyJson.KeyTransformSnakeCase.Apply("userID")     // user_id
+//	xyJson.KeyTransformCamelCase.Apply("created-at") // createdAt
+func (kt KeyTransform) Apply(key string) string {
+	var sep byte
+	switch kt {
+	case KeyTransformCamelCase:
+	case KeyTransformSnakeCase:
+		sep = '_'
+	case KeyTransformKebabCase:
+		sep = '-'
+	default:
+		return key
+	}
+	if isLowerKey(key, sep) {
+		return key
+	}
+
+	trimmed := strings.TrimLeft(key, "_-")
+	words := splitKeyWords(trimmed)
+	if len(words) == 0 {
+		return key
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(key) + len(words))
+	sb.WriteString(key[:len(key)-len(trimmed)])
+	for i, word := range words {
+		word = strings.ToLower(word)
+		switch {
+		case sep != 0:
+			if i > 0 {
+				sb.WriteByte(sep)
+			}
+			sb.WriteString(word)
+		case i == 0:
+			sb.WriteString(word)
+		default:
+			r, size := utf8.DecodeRuneInString(word)
+			sb.WriteRune(unicode.ToUpper(r))
+			sb.WriteString(word[size:])
+		}
+	}
+	return sb.String()
+}
+
+// isLowerKey 检查键名是否只含小写字母、数字和单个出现的sep，此时转换结果与原键名相同
+// isLowerKey checks whether a key only contains lowercase letters, digits and single occurrences of sep, in
+// which case the conversion returns the key unchanged
+func isLowerKey(key string, sep byte) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if sep != 0 && c == sep {
+			if i > 0 && (key[i-1] == sep || i == len(key)-1) {
+				return false
+			}
+			continue
+		}
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitKeyWords 将键名拆分为单词
+// splitKeyWords splits a key into words
+func splitKeyWords(key string) []string {
+	runes := []rune(key)
+	words := make([]string, 0, 4)
+	start := 0
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,130 @@
+package xyJson
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"runtime"
+	"strconv"
+)
+
+// MustLiteral 将JSON字面量解析为新的值，字面量无效时panic并指出调用位置
+// MustLiteral parses a JSON literal into a new value, panicking with the call site when the literal is invalid
+//
+// 与返回null的MustParseString不同，MustLiteral用于测试和固定数据中内嵌的JSON片段，错误的字面量应当立即暴露。
+// 每次调用都返回新的树，修改结果不会影响之后的调用。参数为字符串字面量时可以用xyjson-check在构建时校验
+// Unlike MustParseString, which returns null, MustLiteral is meant for JSON snippets embedded in tests and
+// fixtures, where a broken literal should surface immediately. Every call returns a new tree, so modifying the
+// result does not affect later calls. When the argument is a string literal it can be checked at build time
+// with xyjson-check
+//
+// 示例 Example:
+//
+//	//go:generate go run github.com/ihuem/xyJson/cmd/xyjson-check .
+//
+//	expected := xyJson.MustLiteral(`{"a": 1, "b": [true, null]}`)
+func MustLiteral(literal string) IValue {
+	value, err := ParseString(literal)
+	if err == nil {
+		return value
+	}
+
+	if _, file, line, ok := runtime.Caller(1); ok {
+		if je, isJSONError := err.(*JSONError); isJSONError {
+			err = je.WithContext(fmt.Sprintf("MustLiteral at %s:%d", file, line))
+		}
+	}
+	panic(err)
+}
+
+// LiteralError 源码中无效的MustLiteral字面量
+// LiteralError is an invalid MustLiteral literal in source code
+type LiteralError struct {
+	// File、Line和Column 字面量在源码中的位置
+	// File, Line and Column are the position of the literal in the source
+	File   string
+	Line   int
+	Column int
+
+	// Err 解析字面量的错误
+	// Err is the error parsing the literal
+	Err error
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (le *LiteralError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: invalid JSON literal: %s", le.File, le.Line, le.Column, le.Err.Error())
+}
+
+// Unwrap 返回解析错误
+// Unwrap returns the parse error
+func (le *LiteralError) Unwrap() error {
+	return le.Err
+}
+
+// CheckLiterals 校验Go源码中所有以字符串字面量为参数的MustLiteral调用
+// CheckLiterals checks every MustLiteral call in Go source whose argument is a string literal
+//
+// 调用按函数名识别，包括xyJson.MustLiteral、别名导入和点导入；参数不是字符串字面量（变量、拼接等）的调用会被跳过。
+// 返回的第二个值是Go源码本身的语法错误
+// Calls are recognized by function name, covering xyJson.MustLiteral, aliased imports and dot imports; calls
+// whose argument is not a string literal (variables, concatenations and so on) are skipped. The second return
+// value is a syntax error in the Go source itself
+//
+// 示例 Example:
+//
+//	src, _ := os.ReadFile("fixtures_test.go")
+//	problems, err := xyJson.CheckLiterals("fixtures_test.go", src)
+//	for _, problem := range problems {
+//		fmt.Println(problem)
+//	}
+func CheckLiterals(filename string, src []byte) ([]*LiteralError, error) {
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, filename, src, goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []*LiteralError
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !isMustLiteral(call.Fun) {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+
+		literal, err := strconv.Unquote(lit.Value)
+		if err == nil {
+			_, err = ParseString(literal)
+		}
+		if err != nil {
+			pos := fset.Position(lit.Pos())
+			problems = append(problems, &LiteralError{
+				File:   pos.Filename,
+				Line:   pos.Line,
+				Column: pos.Column,
+				Err:    err,
+			})
+		}
+		return true
+	})
+	return problems, nil
+}
+
+// isMustLiteral 判断被调用的函数是否为MustLiteral
+// isMustLiteral reports whether the called function is MustLiteral
+func isMustLiteral(fun ast.Expr) bool {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name == "MustLiteral"
+	case *ast.SelectorExpr:
+		return f.Sel.Name == "MustLiteral"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,430 @@
+package xyJson
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PatchFrame 实时同步的一帧：将文档从序号Base更新到Seq的JSON Patch，或序号Seq时的完整快照
+// PatchFrame is one frame of live sync: a JSON Patch updating the document from sequence Base to Seq, or a full
+// snapshot at sequence Seq
+type PatchFrame struct {
+	// Base 补丁适用的文档序号，快照帧为0
+	// Base is the document sequence the patch applies to, 0 for snapshot frames
+	Base uint64
+
+	// Seq 应用本帧后的文档序号，即变更日志中最后一条已包含记录的序号
+	// Seq is the document sequence after applying the frame, the sequence of the last journal entry included
+	Seq uint64
+
+	// Patch 补丁操作，快照帧为空
+	// Patch holds the patch operations, empty for snapshot frames
+	Patch []PatchOperation
+
+	// Snapshot 完整文档，补丁帧为nil
+	// Snapshot is the whole document, nil for patch frames
+	Snapshot IValue
+}
+
+// IsSnapshot 是否为快照帧
+// IsSnapshot reports whether the frame is a snapshot frame
+func (f *PatchFrame) IsSnapshot() bool {
+	return f.Snapshot != nil
+}
+
+// ToValue 将帧转换为JSON对象：{"seq":n,"snapshot":...}或{"base":m,"seq":n,"patch":[...]}
+// ToValue converts the frame to a JSON object: {"seq":n,"snapshot":...} or {"base":m,"seq":n,"patch":[...]}
+func (f *PatchFrame) ToValue() IObject {
+	factory := defaultFactory()
+	obj := factory.CreateObject()
+	if f.IsSnapshot() {
+		obj.Set("seq", factory.CreateNumber(f.Seq))
+		obj.Set("snapshot", f.Snapshot.Clone())
+		return obj
+	}
+	obj.Set("base", factory.CreateNumber(f.Base))
+	obj.Set("seq", factory.CreateNumber(f.Seq))
+	obj.Set("patch", PatchToValue(f.Patch))
+	return obj
+}
+
+// Serialize 将帧序列化为单行紧凑JSON，可以直接作为WebSocket文本消息发送
+// Serialize serializes the frame to single-line compact JSON, ready to send as a WebSocket text message
+func (f *PatchFrame) Serialize() ([]byte, error) {
+	return CompactSerializer().Serialize(f.ToValue())
+}
+
+// WriteSSE 将帧写为一个Server-Sent Events事件，事件id为Seq，事件类型为patch或snapshot
+// WriteSSE writes the frame as a Server-Sent Events event whose id is Seq and whose type is patch or snapshot
+//
+// 示例 Example:
+//
+//	w.Header().Set("Content-Type", "text/event-stream")
+//	encoder.Snapshot().WriteSSE(w)
+//	for range ticker.C {
+//		if frame := encoder.Next(); frame != nil {
+//			frame.WriteSSE(w)
+//			w.(http.Flusher).Flush()
+//		}
+//	}
+func (f *PatchFrame) WriteSSE(w io.Writer) error {
+	data, err := f.Serialize()
+	if err != nil {
+		return err
+	}
+	event := "patch"
+	if f.IsSnapshot() {
+		event = "snapshot"
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", f.Seq, event, data)
+	return err
+}
+
+// ParsePatchFrame 解析Serialize生成的帧
+// ParsePatchFrame parses a frame produced by Serialize
+func ParsePatchFrame(data []byte) (*PatchFrame, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Type() != ObjectValueType {
+		return nil, NewTypeMismatchError(ObjectValueType, doc.Type(), "$")
+	}
+	obj := doc.AsObject()
+
+	frame := &PatchFrame{}
+	if frame.Seq, err = frameNumber(obj, "seq"); err != nil {
+		return nil, err
+	}
+	if snapshot := obj.Get("snapshot"); snapshot != nil {
+		frame.Snapshot = snapshot
+		return frame, nil
+	}
+	if frame.Base, err = frameNumber(obj, "base"); err != nil {
+		return nil, err
+	}
+	patch := obj.Get("patch")
+	if patch == nil {
+		return nil, NewInvalidJSONError("frame requires a patch or a snapshot", nil)
+	}
+	if frame.Patch, err = PatchFromValue(patch); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// frameNumber 返回帧中的非负整数成员
+// frameNumber returns a non-negative integer member of a frame
+func frameNumber(obj IObject, key string) (uint64, error) {
+	value := obj.Get(key)
+	if value == nil {
+		return 0, NewInvalidJSONError("frame requires "+key, nil).WithPath(appendPathKey("$", key))
+	}
+	n, ok := value.AsInt64OK()
+	if !ok || n < 0 {
+		return 0, NewInvalidJSONError(key+" must be a non-negative integer", nil).WithPath(appendPathKey("$", key))
+	}
+	return uint64(n), nil
+}
+
+// PatchEncoder 将文档的变更日志转换为实时同步帧，并发安全
+// PatchEncoder turns the change journal of a document into live sync frames, safe for concurrent use
+//
+// 编码器保存文档的一份影子副本，在副本上重放每条记录，从而把JSONPath（包括负索引、追加和自动创建的中间节点）
+// 转换为精确的JSON Pointer。变更日志丢弃了尚未编码的记录，或记录无法表示为补丁时，下一帧改为完整快照，
+// 快照从文档当前状态生成，此时调用方需保证没有并发修改
+// The encoder keeps a shadow copy of the document and replays every entry on it, turning JSONPaths (negative
+// indices, appends and automatically created intermediate nodes included) into exact JSON Pointers. When the
+// journal dropped entries not yet encoded, or an entry cannot be expressed as a patch, the next frame is a full
+// snapshot taken from the current document, which must not be modified concurrently at that moment
+type PatchEncoder struct {
+	root    IValue
+	journal *Journal
+
+	mu     sync.Mutex
+	shadow IValue
+	seq    uint64
+}
+
+// NewPatchEncoder 为文档创建编码器，文档未启用变更日志时使用默认选项启用
+// NewPatchEncoder creates an encoder for the document, enabling the change journal with default options when
+// it is not enabled yet
+//
+// 示例 Example:
+//
+//	encoder := xyJson.NewPatchEncoder(doc)
+//	xyJson.Set(doc, "$.users[+]", user)
+//	frame := encoder.Next() // {"base":0,"seq":1,"patch":[{"op":"add","path":"/users/3","value":{...}}]}
+//	conn.WriteMessage(websocket.TextMessage, must(frame.Serialize()))
+func NewPatchEncoder(root IValue) *PatchEncoder {
+	if root == nil {
+		return nil
+	}
+	journal := EnableJournal(root)
+	return &PatchEncoder{
+		root:    root,
+		journal: journal,
+		shadow:  root.Clone(),
+		seq:     journal.Seq(),
+	}
+}
+
+// Snapshot 返回编码器当前序号的快照帧，用于新连接的客户端
+// Snapshot returns a snapshot frame at the current sequence of the encoder, for newly connected clients
+func (e *PatchEncoder) Snapshot() *PatchFrame {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return &PatchFrame{Seq: e.seq, Snapshot: e.shadow.Clone()}
+}
+
+// Next 返回自上一帧以来的所有变更组成的帧，没有新变更时返回nil
+// Next returns a frame holding every change since the previous frame, nil when nothing changed
+func (e *PatchEncoder) Next() *PatchFrame {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entries, complete := e.journal.Since(e.seq)
+	if complete && len(entries) == 0 {
+		return nil
+	}
+	if !complete {
+		return e.resync()
+	}
+
+	frame := &PatchFrame{Base: e.seq, Patch: make([]PatchOperation, 0, len(entries))}
+	for _, entry := range entries {
+		op, err := e.replay(entry)
+		if err != nil {
+			return e.resync()
+		}
+		frame.Patch = append(frame.Patch, op)
+		e.seq = entry.Seq
+	}
+	frame.Seq = e.seq
+	return frame
+}
+
+// resync 从文档当前状态重建影子副本并返回快照帧
+// resync rebuilds the shadow copy from the current document and returns a snapshot frame
+func (e *PatchEncoder) resync() *PatchFrame {
+	e.seq = e.journal.Seq()
+	e.shadow = e.root.Clone()
+	return &PatchFrame{Seq: e.seq, Snapshot: e.shadow.Clone()}
+}
+
+// replay 在影子副本上重放一条记录并返回对应的补丁操作
+// replay replays one entry on the shadow copy and returns the matching patch operation
+func (e *PatchEncoder) replay(entry JournalEntry) (PatchOperation, error) {
+	pq := &pathQuery{factory: defaultFactory()}
+	segments, err := pq.parsePath(entry.Path)
+	if err != nil {
+		return PatchOperation{}, err
+	}
+
+	// created是第一个尚不存在的段，padded表示数组需要用null填充
+	// created is the first segment that does not exist yet, padded means the array needs null padding
+	tokens := make([]string, 0, len(segments))
+	created, padded := -1, false
+	node := e.shadow
+	for i, segment := range segments {
+		var child IValue
+		switch {
+		case segment.Recursive || segment.Wildcard || segment.Filter != nil:
+			return PatchOperation{}, NewInvalidPathError(entry.Path, nil)
+		case segment.Type == PropertySegmentType && node.Type() == ObjectValueType:
+			tokens = append(tokens, escapePointerToken(segment.Key))
+			child = node.AsObject().Get(segment.Key)
+		case segment.Type == IndexSegmentType && node.Type() == ArrayValueType:
+			arr := node.AsArray()
+			index := segment.Index
+			switch {
+			case segment.Append && entry.Op == JournalDelete:
+				index = arr.Length() - 1
+			case segment.Append:
+				index = arr.Length()
+			case index < 0:
+				index += arr.Length()
+			}
+			tokens = append(tokens, strconv.Itoa(index))
+			if index < arr.Length() {
+				child = arr.Get(index)
+			}
+			padded = index > arr.Length()
+		default:
+			return PatchOperation{}, NewInvalidPathError(entry.Path, nil)
+		}
+		if child == nil {
+			created = i
+			break
+		}
+		node = child
+	}
+
+	if entry.Op == JournalDelete {
+		if created >= 0 {
+			return PatchOperation{}, NewPathNotFoundError(entry.Path)
+		}
+		if err := pq.Delete(e.shadow, entry.Path); err != nil {
+			return PatchOperation{}, err
+		}
+		return PatchOperation{Op: "remove", Path: joinPointer(tokens)}, nil
+	}
+
+	if entry.NewValue == nil {
+		return PatchOperation{}, NewNullPointerError("journal entry has no value")
+	}
+	if err := pq.Set(e.shadow, entry.Path, entry.NewValue.Clone()); err != nil {
+		return PatchOperation{}, err
+	}
+	switch {
+	case created < 0:
+		return PatchOperation{Op: "replace", Path: joinPointer(tokens), Value: entry.NewValue.Clone()}, nil
+	case padded:
+		// 填充后的整个数组替换原数组
+		// The whole padded array replaces the original one
+		pointer := joinPointer(tokens[:created])
+		return PatchOperation{Op: "replace", Path: pointer, Value: lookupPointer(e.shadow, pointer).Clone()}, nil
+	default:
+		pointer := joinPointer(tokens)
+		return PatchOperation{Op: "add", Path: pointer, Value: lookupPointer(e.shadow, pointer).Clone()}, nil
+	}
+}
+
+// joinPointer 将已转义的引用段连接为JSON Pointer
+// joinPointer joins escaped reference tokens into a JSON Pointer
+func joinPointer(tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(tokens, "/")
+}
+
+// PatchReceiver 客户端的实时同步状态：应用收到的帧并维护文档副本，并发安全
+// PatchReceiver is the client side of live sync: it applies received frames and maintains a copy of the document,
+// safe for concurrent use
+type PatchReceiver struct {
+	mu  sync.RWMutex
+	doc IValue
+	seq uint64
+}
+
+// NewPatchReceiver 创建接收器，应用第一个快照帧后才有文档
+// NewPatchReceiver creates a receiver, which has a document only after applying the first snapshot frame
+func NewPatchReceiver() *PatchReceiver {
+	return &PatchReceiver{}
+}
+
+// Document 返回当前文档，尚未收到快照时为nil。后续帧会替换而不是修改返回的值，因此可以在读取时继续接收帧，
+// 但调用方不应修改它
+// Document returns the current document, nil before a snapshot arrived. Later frames replace rather than modify
+// the returned value, so frames may keep arriving while it is read, but callers must not modify it
+func (r *PatchReceiver) Document() IValue {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.doc
+}
+
+// Seq 返回当前文档的序号
+// Seq returns the sequence of the current document
+func (r *PatchReceiver) Seq() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seq
+}
+
+// Apply 应用一帧。快照帧替换文档；补丁帧的Base必须等于当前序号，已包含的旧帧被忽略。
+// 序号不连续时返回ErrInvalidOperation错误，调用方应重新请求快照
+// Apply applies a frame. Snapshot frames replace the document; the Base of a patch frame must equal the current
+// sequence, and stale frames already included are ignored. A gap in the sequence returns an ErrInvalidOperation
+// error, upon which the caller should request a new snapshot
+func (r *PatchReceiver) Apply(frame *PatchFrame) error {
+	if frame == nil {
+		return NewNullPointerError("frame cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if frame.IsSnapshot() {
+		r.doc, r.seq = frame.Snapshot.Clone(), frame.Seq
+		return nil
+	}
+	if r.doc == nil {
+		return NewInvalidOperationError("apply frame", "no snapshot received yet")
+	}
+	if frame.Seq <= r.seq {
+		return nil
+	}
+	if frame.Base != r.seq {
+		return NewInvalidOperationError("apply frame", fmt.Sprintf("frame based on %d does not follow %d", frame.Base, r.seq))
+	}
+	doc, err := ApplyPatch(r.doc, frame.Patch)
+	if err != nil {
+		return err
+	}
+	r.doc, r.seq = doc, frame.Seq
+	return nil
+}
+
+// ApplyMessage 解析并应用一条WebSocket消息或SSE事件数据
+// ApplyMessage parses and applies a WebSocket message or SSE event data
+func (r *PatchReceiver) ApplyMessage(data []byte) error {
+	frame, err := ParsePatchFrame(data)
+	if err != nil {
+		return err
+	}
+	return r.Apply(frame)
+}
+
+// ReadSSE 从Server-Sent Events流中循环读取并应用帧，每应用一帧调用一次onUpdate（可以为nil），
+// 流结束时返回nil，遇到无效帧或序号不连续时返回错误。类型不是patch、snapshot或message的事件被忽略
+// ReadSSE reads and applies frames from a Server-Sent Events stream in a loop, calling onUpdate (which may be
+// nil) after every applied frame; it returns nil at the end of the stream and an error on an invalid frame or a
+// gap in the sequence. Events whose type is not patch, snapshot or message are ignored
+//
+// 示例 Example:
+//
+//	resp, _ := http.Get(server + "/live")
+//	receiver := xyJson.NewPatchReceiver()
+//	err := receiver.ReadSSE(resp.Body, func(doc xyJson.IValue, seq uint64) {
+//		render(doc)
+//	})
+func (r *PatchReceiver) ReadSSE(reader io.Reader, onUpdate func(doc IValue, seq uint64)) error {
+	br := bufio.NewReader(reader)
+	var event string
+	var data []string
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			// 流末尾未以空行结束的事件按SSE规范丢弃
+			// An event not terminated by a blank line at the end of the stream is discarded as SSE specifies
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch {
+		case line == "":
+			if len(data) > 0 && (event == "" || event == "patch" || event == "snapshot" || event == "message") {
+				if err := r.ApplyMessage([]byte(strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+				if onUpdate != nil {
+					onUpdate(r.Document(), r.Seq())
+				}
+			}
+			event, data = "", nil
+		case field == "data":
+			data = append(data, value)
+		case field == "event":
+			event = value
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package xyJson
+
+import (
+	"log/slog"
+)
+
+// LogValue 实现slog.LogValuer，对象渲染为分组，数组渲染为JSON
+// LogValue implements slog.LogValuer, objects are rendered as groups and arrays as JSON
+func (ov *objectValue) LogValue() slog.Value {
+	return logValue(ov, 0)
+}
+
+// LogValue 实现slog.LogValuer，数组渲染为JSON
+// LogValue implements slog.LogValuer, arrays are rendered as JSON
+func (av *arrayValue) LogValue() slog.Value {
+	return logValue(av, 0)
+}
+
+// LogValue 实现slog.LogValuer，标量渲染为对应的slog类型
+// LogValue implements slog.LogValuer, scalars are rendered as the matching slog kind
+func (sv *scalarValue) LogValue() slog.Value {
+	return logValue(sv, 0)
+}
+
+// logValue 将值转换为slog.Value：对象转换为按键名排序的分组，数组和空对象转换为在输出时序列化的JSON，
+// 标量转换为其原始值；超过DefaultMaxDepth的嵌套同样按JSON输出
+// logValue converts a value to a slog.Value: objects become groups sorted by key, arrays and empty objects
+// become JSON serialized on output, and scalars become their raw values; nesting beyond DefaultMaxDepth is
+// output as JSON as well
+func logValue(value IValue, depth int) slog.Value {
+	switch v := value.(type) {
+	case IObject:
+		keys := v.Keys()
+		if len(keys) == 0 || depth >= DefaultMaxDepth {
+			return slog.AnyValue(logJSON{v})
+		}
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			attrs = append(attrs, slog.Attr{Key: key, Value: logValue(v.Get(key), depth+1)})
+		}
+		return slog.GroupValue(attrs...)
+	case IArray:
+		return slog.AnyValue(logJSON{v})
+	case nil:
+		return slog.AnyValue(nil)
+	default:
+		return slog.AnyValue(v.Raw())
+	}
+}
+
+// logJSON 在日志处理器输出时才序列化的值，JSON处理器嵌入原始JSON，文本处理器输出紧凑JSON字符串
+// logJSON is a value serialized only when the log handler outputs it, the JSON handler embeds it as raw JSON
+// and the text handler outputs it as a compact JSON string
+type logJSON struct {
+	value IValue
+}
+
+// MarshalJSON 实现json.Marshaler
+// MarshalJSON implements json.Marshaler
+func (l logJSON) MarshalJSON() ([]byte, error) {
+	return Serialize(l.value)
+}
+
+// MarshalText 实现encoding.TextMarshaler
+// MarshalText implements encoding.TextMarshaler
+func (l logJSON) MarshalText() ([]byte, error) {
+	return Serialize(l.value)
+}
@@ -0,0 +1,46 @@
+package xyJson
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MapKeyFunc 将Go map的键转换为JSON对象的键名
+// MapKeyFunc converts a Go map key into a JSON object key
+type MapKeyFunc func(key interface{}) (string, error)
+
+// DefaultMapKey 默认的map键转换：字符串原样使用，实现encoding.TextMarshaler的键使用MarshalText，
+// 实现fmt.Stringer的键使用String，整数使用十进制，其它键使用fmt的%v格式
+// DefaultMapKey is the default map key conversion: strings are used as is, keys implementing
+// encoding.TextMarshaler use MarshalText, keys implementing fmt.Stringer use String, integers use decimal and
+// other keys use fmt's %v format
+func DefaultMapKey(key interface{}) (string, error) {
+	rv := reflect.ValueOf(key)
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if marshaler, ok := key.(encoding.TextMarshaler); ok {
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "", nil
+		}
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", NewInvalidOperationError("convert map key", err.Error())
+		}
+		return string(text), nil
+	}
+
+	if stringer, ok := key.(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return fmt.Sprintf("%v", key), nil
+}
@@ -0,0 +1,116 @@
+package xyJson
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// 常用元数据键
+// Well-known metadata keys
+const (
+	// MetaSource 文档来源，例如文件名
+	// MetaSource is the document source, such as a file name
+	MetaSource = "source"
+
+	// MetaRequestID 产生文档的请求ID
+	// MetaRequestID is the ID of the request that produced the document
+	MetaRequestID = "request_id"
+
+	// MetaParseDuration 解析耗时（time.Duration）
+	// MetaParseDuration is the parse duration (time.Duration)
+	MetaParseDuration = "parse_duration"
+)
+
+// valueMeta 值的元数据存储，嵌入到各个值类型中
+// valueMeta stores the metadata of a value, embedded in every value type
+//
+// 绝大多数值没有元数据，因此存储在首次SetMeta时才分配，未使用时只占一个指针
+// Most values carry no metadata, so the storage is allocated on the first SetMeta and costs one pointer otherwise
+type valueMeta struct {
+	meta atomic.Pointer[metaStore]
+}
+
+// metaStore 元数据的键值存储
+// metaStore is the key-value storage of metadata
+type metaStore struct {
+	mu   sync.RWMutex
+	data map[string]interface{}
+}
+
+// SetMeta 为值附加元数据，value为nil时删除该键
+// SetMeta attaches metadata to the value, a nil value removes the key
+func (vm *valueMeta) SetMeta(key string, value interface{}) {
+	store := vm.meta.Load()
+	if store == nil {
+		if value == nil {
+			return
+		}
+		vm.meta.CompareAndSwap(nil, &metaStore{data: make(map[string]interface{}, 1)})
+		store = vm.meta.Load()
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if value == nil {
+		delete(store.data, key)
+		return
+	}
+	store.data[key] = value
+}
+
+// Meta 返回元数据值，不存在时返回nil
+// Meta returns a metadata value, nil if absent
+func (vm *valueMeta) Meta(key string) interface{} {
+	store := vm.meta.Load()
+	if store == nil {
+		return nil
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.data[key]
+}
+
+// MetaKeys 返回排序后的元数据键
+// MetaKeys returns the sorted metadata keys
+func (vm *valueMeta) MetaKeys() []string {
+	store := vm.meta.Load()
+	if store == nil {
+		return nil
+	}
+
+	store.mu.RLock()
+	keys := make([]string, 0, len(store.data))
+	for key := range store.data {
+		keys = append(keys, key)
+	}
+	store.mu.RUnlock()
+
+	sort.Strings(keys)
+	return keys
+}
+
+// copyMetaTo 将元数据浅拷贝到dst，供Clone使用
+// copyMetaTo shallow-copies the metadata into dst, used by Clone
+func (vm *valueMeta) copyMetaTo(dst *valueMeta) {
+	store := vm.meta.Load()
+	if store == nil {
+		return
+	}
+
+	store.mu.RLock()
+	data := make(map[string]interface{}, len(store.data))
+	for key, value := range store.data {
+		data[key] = value
+	}
+	store.mu.RUnlock()
+
+	dst.meta.Store(&metaStore{data: data})
+}
+
+// clearMeta 清除所有元数据，供对象池回收时使用
+// clearMeta removes all metadata, used when values are recycled by the object pool
+func (vm *valueMeta) clearMeta() {
+	vm.meta.Store(nil)
+}
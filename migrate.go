@@ -0,0 +1,430 @@
+package xyJson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// MigrationOpKind 声明式迁移操作的类型
+// MigrationOpKind is the kind of a declarative migration operation
+type MigrationOpKind string
+
+const (
+	// MigrationMove 将From移动到Path，见Move
+	// MigrationMove moves From to Path, see Move
+	MigrationMove MigrationOpKind = "move"
+	// MigrationRename 在Path匹配的对象中将Key重命名为NewKey，见Rename
+	// MigrationRename renames Key to NewKey in the objects matched by Path, see Rename
+	MigrationRename MigrationOpKind = "rename"
+	// MigrationSet 将Path设置为Value
+	// MigrationSet sets Path to Value
+	MigrationSet MigrationOpKind = "set"
+	// MigrationDelete 删除Path
+	// MigrationDelete deletes Path
+	MigrationDelete MigrationOpKind = "delete"
+	// MigrationTransformKeys 对Path处子树中的所有对象键名递归应用Transform
+	// MigrationTransformKeys applies Transform recursively to every object key in the subtree at Path
+	MigrationTransformKeys MigrationOpKind = "transformKeys"
+)
+
+// MigrationOp 声明式迁移操作
+// MigrationOp is a declarative migration operation
+type MigrationOp struct {
+	// Op 操作类型
+	// Op is the kind of operation
+	Op MigrationOpKind
+
+	// Path 操作的目标路径
+	// Path is the target path of the operation
+	Path string
+
+	// From MigrationMove的源路径
+	// From is the source path of MigrationMove
+	From string
+
+	// Key和NewKey MigrationRename的旧键名和新键名
+	// Key and NewKey are the old and new key of MigrationRename
+	Key, NewKey string
+
+	// Value MigrationSet设置的值，IValue在每次应用时被克隆
+	// Value is the value set by MigrationSet, an IValue is cloned every time it is applied
+	Value interface{}
+
+	// Transform MigrationTransformKeys使用的键名转换
+	// Transform is the key conversion used by MigrationTransformKeys
+	Transform KeyTransform
+
+	// Optional 为true时源路径（MigrationMove的From、MigrationDelete和MigrationTransformKeys的Path）
+	// 不存在不视为错误，便于迁移形状不一致的历史文档
+	// Optional makes a missing source path (From of MigrationMove, Path of MigrationDelete and
+	// MigrationTransformKeys) not an error, for migrating historical documents of inconsistent shape
+	Optional bool
+}
+
+// MigrationStep 一个版本的迁移步骤，将文档从Version-1迁移到Version
+// MigrationStep is the migration step of one version, migrating documents from Version-1 to Version
+//
+// Ops按顺序应用，之后调用Func（如果设置）
+// Ops are applied in order, followed by Func when it is set
+type MigrationStep struct {
+	// Version 步骤迁移到的版本
+	// Version is the version the step migrates to
+	Version int
+
+	// Description 步骤说明，用于报告和错误信息
+	// Description describes the step, for reports and error messages
+	Description string
+
+	// Ops 声明式操作列表
+	// Ops is the list of declarative operations
+	Ops []MigrationOp
+
+	// Func 自定义迁移函数
+	// Func is a custom migration function
+	Func func(root IValue) error
+}
+
+// Migration 按版本排序的迁移步骤集合
+// Migration is a set of migration steps ordered by version
+type Migration struct {
+	steps []MigrationStep
+}
+
+// MigrateOptions 迁移选项
+// MigrateOptions holds the migration options
+type MigrateOptions struct {
+	// DryRun 只在副本上执行迁移并返回报告，不修改文档
+	// DryRun runs the migration on a copy and returns the report without modifying the document
+	DryRun bool
+
+	// VersionPath 迁移成功后写入目标版本号的路径，为空时不写入
+	// VersionPath is the path the target version is written to after a successful migration, nothing is
+	// written when empty
+	VersionPath string
+}
+
+// DefaultMigrateOptions 返回默认迁移选项
+// DefaultMigrateOptions returns the default migration options
+func DefaultMigrateOptions() *MigrateOptions {
+	return &MigrateOptions{
+		DryRun:      false,
+		VersionPath: "",
+	}
+}
+
+// MigrationStepResult 单个迁移步骤的结果
+// MigrationStepResult is the result of a single migration step
+type MigrationStepResult struct {
+	Version     int
+	Description string
+	// Err 步骤失败的原因，成功时为nil
+	// Err is why the step failed, nil on success
+	Err error
+}
+
+// MigrationReport 迁移报告，按执行顺序列出已执行的步骤，失败的步骤是最后一项
+// MigrationReport is a migration report listing the executed steps in order, a failed step being the last
+type MigrationReport struct {
+	FromVersion int
+	ToVersion   int
+	DryRun      bool
+	Steps       []MigrationStepResult
+
+	// Result 迁移后的文档；试运行时是迁移后的副本，失败时为nil
+	// Result is the migrated document; a migrated copy in a dry run, nil on failure
+	Result IValue
+}
+
+// MigrationError 迁移步骤失败的错误
+// MigrationError is the error of a failed migration step
+type MigrationError struct {
+	Version     int
+	Description string
+	// Op 失败操作在Ops中的下标，Func失败时为-1
+	// Op is the index of the failed operation in Ops, -1 when Func failed
+	Op  int
+	Err error
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (e *MigrationError) Error() string {
+	msg := "migration to version " + strconv.Itoa(e.Version)
+	if e.Description != "" {
+		msg += " (" + e.Description + ")"
+	}
+	if e.Op >= 0 {
+		msg += ": op " + strconv.Itoa(e.Op)
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap 返回底层错误
+// Unwrap returns the underlying error
+func (e *MigrationError) Unwrap() error {
+	return e.Err
+}
+
+// NewMigration 创建迁移，步骤按版本排序，版本不能重复
+// NewMigration creates a migration, steps are sorted by version and versions must not repeat
+//
+// 示例 Example:
+//
+//	m, err := xyJson.NewMigration(
+//		xyJson.MigrationStep{Version: 2, Description: "nest name", Ops: []xyJson.MigrationOp{
+//			{Op: xyJson.MigrationMove, From: "$.firstName", Path: "$.name.first"},
+//			{Op: xyJson.MigrationRename, Path: "$.orders[*]", Key: "qty", NewKey: "quantity"},
+//		}},
+//		xyJson.MigrationStep{Version: 3, Func: func(root xyJson.IValue) error {
+//			return xyJson.Set(root, "$.migrated", true)
+//		}},
+//	)
+func NewMigration(steps ...MigrationStep) (*Migration, error) {
+	sorted := make([]MigrationStep, len(steps))
+	copy(sorted, steps)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, step := range sorted {
+		if i > 0 && step.Version == sorted[i-1].Version {
+			return nil, NewInvalidOperationError("new migration", fmt.Sprintf("duplicate step for version %d", step.Version))
+		}
+		for j, op := range step.Ops {
+			if err := validateMigrationOp(op); err != nil {
+				return nil, &MigrationError{Version: step.Version, Description: step.Description, Op: j, Err: err}
+			}
+		}
+	}
+	return &Migration{steps: sorted}, nil
+}
+
+// LatestVersion 返回最后一个步骤的版本，没有步骤时返回0
+// LatestVersion returns the version of the last step, 0 when there are no steps
+func (m *Migration) LatestVersion() int {
+	if len(m.steps) == 0 {
+		return 0
+	}
+	return m.steps[len(m.steps)-1].Version
+}
+
+// Migrate 将文档从fromVersion迁移到toVersion，见MigrateWithOptions
+// Migrate migrates a document from fromVersion to toVersion, see MigrateWithOptions
+func (m *Migration) Migrate(root IValue, fromVersion, toVersion int) (*MigrationReport, error) {
+	return m.MigrateWithOptions(root, fromVersion, toVersion, nil)
+}
+
+// MigrateWithOptions 按版本顺序应用fromVersion < Version <= toVersion的步骤
+// MigrateWithOptions applies the steps with fromVersion < Version <= toVersion in version order
+//
+// 步骤在文档的副本上执行，全部成功后才写回root（对象和数组保持同一实例），
+// 因此失败时root保持不变，返回的报告列出已执行的步骤，错误为*MigrationError。
+// 不支持降级（toVersion < fromVersion）。options为nil时使用默认选项
+// The steps run on a copy of the document which is written back to root (objects and arrays keep their
+// identity) only after all of them succeed, so root is unchanged on failure; the returned report lists the
+// executed steps and the error is a *MigrationError. Downgrades (toVersion < fromVersion) are not supported.
+// nil options means the default options
+//
+// 示例 Example:
+//
+//	report, err := m.MigrateWithOptions(doc, 1, m.LatestVersion(), &xyJson.MigrateOptions{DryRun: true})
+func (m *Migration) MigrateWithOptions(root IValue, fromVersion, toVersion int, options *MigrateOptions) (*MigrationReport, error) {
+	if root == nil {
+		return nil, NewNullPointerError("root cannot be nil")
+	}
+	if toVersion < fromVersion {
+		return nil, NewInvalidOperationError("migrate",
+			fmt.Sprintf("cannot downgrade from version %d to %d", fromVersion, toVersion))
+	}
+	if options == nil {
+		options = DefaultMigrateOptions()
+	}
+
+	report := &MigrationReport{FromVersion: fromVersion, ToVersion: toVersion, DryRun: options.DryRun}
+	work := root.Clone()
+	for _, step := range m.steps {
+		if step.Version <= fromVersion || step.Version > toVersion {
+			continue
+		}
+		err := runMigrationStep(work, step)
+		report.Steps = append(report.Steps, MigrationStepResult{
+			Version:     step.Version,
+			Description: step.Description,
+			Err:         err,
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if options.VersionPath != "" {
+		if err := Set(work, options.VersionPath, toVersion); err != nil {
+			return report, err
+		}
+	}
+
+	if options.DryRun {
+		report.Result = work
+		return report, nil
+	}
+	if err := replaceContents(root, work); err != nil {
+		return report, err
+	}
+	report.Result = root
+	return report, nil
+}
+
+// runMigrationStep 执行单个步骤
+// runMigrationStep runs a single step
+func runMigrationStep(root IValue, step MigrationStep) error {
+	for i, op := range step.Ops {
+		if err := applyMigrationOp(root, op); err != nil {
+			return &MigrationError{Version: step.Version, Description: step.Description, Op: i, Err: err}
+		}
+	}
+	if step.Func != nil {
+		if err := step.Func(root); err != nil {
+			return &MigrationError{Version: step.Version, Description: step.Description, Op: -1, Err: err}
+		}
+	}
+	return nil
+}
+
+// validateMigrationOp 检查操作的类型和必需字段
+// validateMigrationOp checks the kind and required fields of an operation
+func validateMigrationOp(op MigrationOp) error {
+	switch op.Op {
+	case MigrationMove:
+		if op.From == "" || op.Path == "" {
+			return NewInvalidOperationError(string(op.Op), "From and Path are required")
+		}
+	case MigrationRename:
+		if op.Key == "" || op.NewKey == "" {
+			return NewInvalidOperationError(string(op.Op), "Key and NewKey are required")
+		}
+	case MigrationSet, MigrationDelete, MigrationTransformKeys:
+		if op.Path == "" {
+			return NewInvalidOperationError(string(op.Op), "Path is required")
+		}
+	default:
+		return NewInvalidOperationError(string(op.Op), "unknown migration operation")
+	}
+	return nil
+}
+
+// applyMigrationOp 应用单个声明式操作
+// applyMigrationOp applies a single declarative operation
+func applyMigrationOp(root IValue, op MigrationOp) error {
+	switch op.Op {
+	case MigrationMove:
+		if op.Optional && !Exists(root, op.From) {
+			return nil
+		}
+		return Move(root, op.From, op.Path)
+	case MigrationRename:
+		path := op.Path
+		if path == "" {
+			path = "$"
+		}
+		_, err := Rename(root, path, op.Key, op.NewKey)
+		return err
+	case MigrationSet:
+		value := op.Value
+		if v, ok := value.(IValue); ok && v != nil {
+			value = v.Clone()
+		}
+		return Set(root, op.Path, value)
+	case MigrationDelete:
+		if op.Optional && !Exists(root, op.Path) {
+			return nil
+		}
+		return Delete(root, op.Path)
+	case MigrationTransformKeys:
+		matches, err := GetAll(root, op.Path)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 && !op.Optional {
+			return NewPathNotFoundError(op.Path)
+		}
+		for _, match := range matches {
+			if err := transformKeys(match, op.Transform, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return validateMigrationOp(op)
+	}
+}
+
+// transformKeys 递归转换子树中所有对象的键名，转换后重复的键名返回错误
+// transformKeys converts the keys of every object in a subtree recursively, returning an error when converted
+// keys collide
+func transformKeys(value IValue, transform KeyTransform, depth int) error {
+	if depth > DefaultMaxDepth {
+		return NewMaxDepthExceededError(DefaultMaxDepth)
+	}
+	switch v := value.(type) {
+	case IObject:
+		renames := make(map[string]string)
+		for _, key := range v.Keys() {
+			if name := transform.Apply(key); name != key {
+				renames[key] = name
+			}
+		}
+		if len(renames) > 0 {
+			if _, err := RenameBatch(v, "$", renames); err != nil {
+				return err
+			}
+		}
+		for _, key := range v.Keys() {
+			if err := transformKeys(v.Get(key), transform, depth+1); err != nil {
+				return err
+			}
+		}
+	case IArray:
+		for i := 0; i < v.Length(); i++ {
+			if err := transformKeys(v.Get(i), transform, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replaceContents 用src的内容替换dst，对象和数组保持dst实例不变
+// replaceContents replaces the contents of dst with those of src, keeping the dst instance of objects and
+// arrays
+func replaceContents(dst, src IValue) error {
+	switch d := dst.(type) {
+	case IObject:
+		s, ok := src.(IObject)
+		if !ok {
+			return NewTypeMismatchError(ObjectValueType, src.Type(), "$")
+		}
+		d.Clear()
+		for _, key := range s.Keys() {
+			if err := d.Set(key, s.Get(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case IArray:
+		s, ok := src.(IArray)
+		if !ok {
+			return NewTypeMismatchError(ArrayValueType, src.Type(), "$")
+		}
+		items := make([]IValue, s.Length())
+		for i := range items {
+			items[i] = s.Get(i)
+		}
+		d.Clear()
+		d.AppendAll(items...)
+		return nil
+	default:
+		if !dst.Equals(src) {
+			return NewInvalidOperationError("migrate", "a scalar root cannot be changed in place")
+		}
+		return nil
+	}
+}
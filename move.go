@@ -0,0 +1,226 @@
+package xyJson
+
+import (
+	"sort"
+)
+
+// Move 将fromPath的值移动到toPath，toPath的中间路径不存在时自动创建
+// Move moves the value at fromPath to toPath, creating missing intermediate paths of toPath
+//
+// 与JSON Patch的move操作一样，值先从fromPath删除，再添加到toPath：toPath以索引结尾时值被插入该位置，
+// 后面的元素依次后移，以[+]结尾时追加到数组，以属性结尾时替换已有的值。两个路径都只能由属性和索引组成。
+// toPath中通向父节点的索引按移动前的文档解析，最后一个索引按删除源值之后的数组计算，
+// 因此在同一数组内把[0]移动到[1]会交换前两个元素。值被原样移动而不复制；不能把值移动到它自己的内部，
+// toPath包含fromPath时（例如$.a.b移动到$.a）原来的父节点被整体替换
+// Like the JSON Patch move operation, the value is removed from fromPath first and then added at toPath: when
+// toPath ends with an index the value is inserted at that position and later items shift back, when it ends
+// with [+] the value is appended to the array and when it ends with a property the existing value is
+// replaced. Both paths may only consist of properties and indices. Indices of toPath leading to the parent are
+// resolved against the document before the move, the last index against the array after the source value is
+// removed, so moving [0] to [1] within an array swaps the first two items. The value is moved as is without
+// copying; a value cannot be moved inside itself, and when toPath contains fromPath (for example $.a.b moved to
+// $.a) the old parent is replaced entirely
+//
+// 示例 Example:
+//
+//	err := xyJson.Move(doc, "$.user.mail", "$.user.contact.email")
+//	err = xyJson.Move(doc, "$.steps[3]", "$.steps[0]") // 移到最前 move to the front
+func Move(root IValue, fromPath, toPath string) (err error) {
+	defer recoverPanic("move", &err)
+	if root == nil {
+		return NewNullPointerError("root cannot be nil")
+	}
+
+	from, err := parseMovePath(fromPath, false)
+	if err != nil {
+		return err
+	}
+	to, err := parseMovePath(toPath, true)
+	if err != nil {
+		return err
+	}
+
+	pq := &pathQuery{factory: defaultFactory()}
+	value, err := pq.SelectOne(root, fromPath)
+	if err != nil {
+		return err
+	}
+	if segmentsEqual(from, to) {
+		return nil
+	}
+	if len(from) < len(to) && segmentsEqual(from, to[:len(from)]) {
+		return NewInvalidOperationError("move", "cannot move "+fromPath+" inside itself to "+toPath)
+	}
+
+	// 在删除源值之前解析目标的父节点，父节点本身不受删除影响
+	// Resolve the parent of the target before the source value is removed, the parent itself is not affected
+	// by the removal
+	parent := root
+	for i, segment := range to[:len(to)-1] {
+		next, err := pq.navigateSegment(parent, segment)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			if next, err = pq.createIntermediatePath(parent, segment, to[i+1]); err != nil {
+				return err
+			}
+		}
+		parent = next
+	}
+
+	if err := pq.deleteValueAtPath(root, from); err != nil {
+		return err
+	}
+	return pq.insertFinalValue(parent, to[len(to)-1], value)
+}
+
+// MoveBatch 按源路径排序依次执行多个移动，moves为源路径到目标路径的映射；遇到第一个错误时停止
+// MoveBatch performs several moves one after another in order of source path, moves maps source paths to
+// target paths; it stops at the first error
+//
+// 示例 Example:
+//
+//	err := xyJson.MoveBatch(doc, map[string]string{
+//		"$.firstName": "$.name.first",
+//		"$.lastName":  "$.name.last",
+//	})
+func MoveBatch(root IValue, moves map[string]string) error {
+	froms := make([]string, 0, len(moves))
+	for from := range moves {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	for _, from := range froms {
+		if err := Move(root, from, moves[from]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rename 将objPath匹配的每个对象中的oldKey重命名为newKey，返回重命名的对象数
+// Rename renames oldKey to newKey in every object matched by objPath, returning the number of objects renamed
+//
+// 等价于只含一个映射的RenameBatch
+// Equivalent to RenameBatch with a single mapping
+//
+// 示例 Example:
+//
+//	n, err := xyJson.Rename(doc, "$.users[*]", "mail", "email")
+func Rename(root IValue, objPath, oldKey, newKey string) (int, error) {
+	return RenameBatch(root, objPath, map[string]string{oldKey: newKey})
+}
+
+// RenameBatch 在objPath匹配的每个对象中按renames（旧键名到新键名）重命名键，返回重命名的对象数
+// RenameBatch renames keys by renames (old key to new key) in every object matched by objPath, returning the
+// number of objects renamed
+//
+// objPath可以使用通配符和过滤器，不是对象的匹配值和不含旧键名的对象被跳过。同一个对象中的重命名同时生效，
+// 因此可以交换两个键名。新键名已存在且不会被重命名移走时返回错误，此时文档不被修改
+// objPath may use wildcards and filters; matched values that are not objects and objects without the old
+// keys are skipped. The renames in one object take effect at the same time, so two keys can be swapped. An
+// error is returned when a new key already exists and is not renamed away itself, in which case the document
+// is not modified
+//
+// 示例 Example:
+//
+//	n, err := xyJson.RenameBatch(doc, "$.orders[*]", map[string]string{"qty": "quantity", "amt": "amount"})
+func RenameBatch(root IValue, objPath string, renames map[string]string) (_ int, err error) {
+	defer recoverPanic("rename", &err)
+	if root == nil {
+		return 0, NewNullPointerError("root cannot be nil")
+	}
+
+	targets := make(map[string]string, len(renames))
+	for oldKey, newKey := range renames {
+		if other, exists := targets[newKey]; exists {
+			return 0, NewInvalidOperationError("rename", "both '"+other+"' and '"+oldKey+"' renamed to '"+newKey+"'")
+		}
+		targets[newKey] = oldKey
+	}
+
+	matches, err := defaultPathQuery().SelectAll(root, objPath)
+	if err != nil {
+		return 0, err
+	}
+
+	// 先检查所有对象，确保出错时不修改文档
+	// Check every object first so the document is left unchanged on error
+	seen := make(map[IValue]bool, len(matches))
+	objects := make([]IObject, 0, len(matches))
+	for _, match := range matches {
+		obj, ok := match.(IObject)
+		if !ok || seen[match] {
+			continue
+		}
+		seen[match] = true
+		for oldKey, newKey := range renames {
+			if oldKey == newKey || !obj.Has(oldKey) || !obj.Has(newKey) {
+				continue
+			}
+			if _, renamed := renames[newKey]; !renamed {
+				return 0, NewInvalidOperationError("rename", "key '"+newKey+"' already exists")
+			}
+		}
+		objects = append(objects, obj)
+	}
+
+	count := 0
+	for _, obj := range objects {
+		values := make(map[string]IValue, len(renames))
+		for oldKey, newKey := range renames {
+			if oldKey != newKey && obj.Has(oldKey) {
+				values[newKey] = obj.Get(oldKey)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		for newKey := range values {
+			obj.Delete(targets[newKey])
+		}
+		for newKey, value := range values {
+			if err := obj.Set(newKey, value); err != nil {
+				return count, err
+			}
+		}
+		count++
+	}
+	return count, nil
+}
+
+// insertFinalValue 按JSON Patch的add语义写入最终值：数组索引处插入，[+]追加，属性替换
+// insertFinalValue writes the final value with the JSON Patch add semantics: inserted at an array index,
+// appended for [+] and replacing for a property
+func (pq *pathQuery) insertFinalValue(parent IValue, segment *pathSegment, value IValue) error {
+	arr, ok := parent.(IArray)
+	if !ok || segment.Type != IndexSegmentType || segment.Append {
+		return pq.setFinalValue(parent, segment, value)
+	}
+	if index, ok := normalizeIndex(segment.Index, arr.Length()); ok {
+		return arr.Insert(index, value)
+	}
+	return pq.setFinalValue(parent, segment, value)
+}
+
+// parseMovePath 解析移动路径，只接受属性和索引段；allowAppend时最后一段可以是[+]
+// parseMovePath parses a move path, accepting only property and index segments; with allowAppend the last
+// segment may be [+]
+func parseMovePath(path string, allowAppend bool) ([]*pathSegment, error) {
+	segments, err := (&pathQuery{}).parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, NewInvalidOperationError("move", "the root cannot be moved or replaced")
+	}
+	for i, segment := range segments {
+		if plainSegment(segment) || (allowAppend && i == len(segments)-1 && segment.Append) {
+			continue
+		}
+		return nil, NewInvalidOperationError("move", "only properties and indices are supported: "+path)
+	}
+	return segments, nil
+}
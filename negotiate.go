@@ -0,0 +1,301 @@
+package xyJson
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MediaTypeJSON JSON的媒体类型
+	// MediaTypeJSON is the media type of JSON
+	MediaTypeJSON = "application/json"
+
+	// MediaTypeNDJSON 换行分隔JSON的媒体类型
+	// MediaTypeNDJSON is the media type of newline-delimited JSON
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+// ndjsonMediaTypes 表示换行分隔JSON的媒体类型
+// ndjsonMediaTypes are the media types denoting newline-delimited JSON
+var ndjsonMediaTypes = []string{MediaTypeNDJSON, "application/ndjson", "application/jsonl", "application/x-jsonlines"}
+
+// Representation 内容协商选出的响应表示
+// Representation is the response representation chosen by content negotiation
+type Representation struct {
+	// MediaType 响应的媒体类型，例如application/json或application/problem+json
+	// MediaType is the media type of the response, such as application/json or application/problem+json
+	MediaType string
+
+	// Charset 响应的字符集，JSON总是utf-8
+	// Charset is the charset of the response, always utf-8 for JSON
+	Charset string
+
+	// Indent 格式化输出的缩进，为空时输出紧凑JSON
+	// Indent is the indentation of pretty output, compact JSON when empty
+	Indent string
+
+	// Lines 是否输出换行分隔JSON：数组的每个元素一行，其它值单独一行
+	// Lines reports whether newline-delimited JSON is produced: one line per array element, other values on a
+	// single line
+	Lines bool
+}
+
+// ContentType 返回Content-Type响应头的值
+// ContentType returns the value of the Content-Type response header
+func (r *Representation) ContentType() string {
+	return r.MediaType + "; charset=" + r.Charset
+}
+
+// Serializer 返回生成该表示的序列化器，换行分隔JSON的每一行使用紧凑序列化器
+// Serializer returns the serializer producing the representation, every line of newline-delimited JSON uses the
+// compact serializer
+func (r *Representation) Serializer() ISerializer {
+	if r.Indent != "" && !r.Lines {
+		return PrettySerializer(r.Indent)
+	}
+	return CompactSerializer()
+}
+
+// Serialize 按该表示序列化值
+// Serialize serializes a value in the representation
+func (r *Representation) Serialize(value IValue) ([]byte, error) {
+	if value == nil {
+		return nil, NewNullPointerError("value cannot be nil")
+	}
+	serializer := r.Serializer()
+	if !r.Lines {
+		return serializer.Serialize(value)
+	}
+
+	items := []IValue{value}
+	if value.Type() == ArrayValueType {
+		arr := value.AsArray()
+		items = make([]IValue, 0, arr.Length())
+		for i := 0; i < arr.Length(); i++ {
+			items = append(items, arr.Get(i))
+		}
+	}
+	var out []byte
+	for _, item := range items {
+		data, err := serializer.Serialize(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(append(out, data...), '\n')
+	}
+	return out, nil
+}
+
+// Write 设置Content-Type和Vary响应头，写入状态码和序列化后的值
+// Write sets the Content-Type and Vary response headers and writes the status code and the serialized value
+func (r *Representation) Write(w http.ResponseWriter, status int, value IValue) error {
+	data, err := r.Serialize(value)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", r.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// Negotiate 根据Accept请求头选择响应表示
+// Negotiate chooses the response representation for an Accept request header
+//
+// 支持application/json、application/*+json（如application/problem+json）和换行分隔JSON
+// （application/x-ndjson、application/ndjson、application/jsonl、application/x-jsonlines）。
+// 媒体范围的indent参数（空格数）或pretty=true参数选择格式化输出。按q值选择，q值相同时优先JSON；
+// 每个媒体类型使用与它匹配的最具体的媒体范围的q值。空的Accept等同于*/*。
+// 要求utf-8以外字符集的媒体范围不匹配。没有可接受的表示时返回ErrInvalidOperation错误
+// Supports application/json, application/*+json (such as application/problem+json) and newline-delimited JSON
+// (application/x-ndjson, application/ndjson, application/jsonl, application/x-jsonlines). The indent parameter
+// (a number of spaces) or pretty=true parameter of a media range selects pretty output. The choice follows the
+// q values with JSON preferred on ties; every media type takes the q value of the most specific media range
+// matching it. An empty Accept is the same as */*. Media ranges asking for a charset other than utf-8 do not
+// match. An ErrInvalidOperation error is returned when no representation is acceptable
+//
+// 示例 Example:
+//
+//	rep, err := xyJson.Negotiate("application/x-ndjson, application/json;q=0.5")
+//	// rep.MediaType == "application/x-ndjson", rep.Lines == true
+func Negotiate(accept string) (*Representation, error) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return &Representation{MediaType: MediaTypeJSON, Charset: "utf-8"}, nil
+	}
+
+	// 候选类型按优先顺序排列，Accept中显式列出的+json类型也是候选
+	// Candidates are in preference order, +json types listed explicitly in Accept are candidates too
+	candidates := []string{MediaTypeJSON}
+	for _, mr := range ranges {
+		if strings.HasPrefix(mr.mediaType, "application/") && strings.HasSuffix(mr.mediaType, "+json") {
+			candidates = append(candidates, mr.mediaType)
+		}
+	}
+	candidates = append(candidates, ndjsonMediaTypes...)
+
+	var best *Representation
+	bestQ := 0.0
+	for _, candidate := range candidates {
+		mr := bestMediaRange(ranges, candidate)
+		if mr == nil || mr.q <= bestQ {
+			continue
+		}
+		rep := &Representation{MediaType: candidate, Charset: "utf-8", Indent: mr.indent()}
+		for _, ndjson := range ndjsonMediaTypes {
+			rep.Lines = rep.Lines || candidate == ndjson
+		}
+		best, bestQ = rep, mr.q
+	}
+	if best == nil {
+		return nil, NewInvalidOperationError("negotiate", fmt.Sprintf("no acceptable representation for %q", accept))
+	}
+	return best, nil
+}
+
+// NegotiateRequest 根据请求的Accept和Accept-Charset请求头选择响应表示，Accept-Charset不接受utf-8时返回错误
+// NegotiateRequest chooses the response representation from the Accept and Accept-Charset headers of a request,
+// returning an error when Accept-Charset does not accept utf-8
+func NegotiateRequest(r *http.Request) (*Representation, error) {
+	if charsets := r.Header.Get("Accept-Charset"); charsets != "" && !acceptsUTF8(charsets) {
+		return nil, NewInvalidOperationError("negotiate", fmt.Sprintf("no acceptable charset for %q", charsets))
+	}
+	return Negotiate(strings.Join(r.Header.Values("Accept"), ","))
+}
+
+// WriteNegotiated 协商响应表示并写入值，没有可接受的表示时响应406 Not Acceptable
+// WriteNegotiated negotiates the response representation and writes the value, responding 406 Not Acceptable
+// when no representation is acceptable
+//
+// 示例 Example:
+//
+//	func listUsers(w http.ResponseWriter, r *http.Request) {
+//		xyJson.WriteNegotiated(w, r, http.StatusOK, users)
+//	}
+func WriteNegotiated(w http.ResponseWriter, r *http.Request, status int, value IValue) error {
+	rep, err := NegotiateRequest(r)
+	if err != nil {
+		w.Header().Add("Vary", "Accept")
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return err
+	}
+	return rep.Write(w, status, value)
+}
+
+// mediaRange Accept请求头中的一个媒体范围
+// mediaRange is one media range of an Accept request header
+type mediaRange struct {
+	mediaType string
+	params    map[string]string
+	q         float64
+}
+
+// specificity 返回媒体范围匹配candidate的具体程度，不匹配时返回-1
+// specificity returns how specifically the media range matches candidate, -1 when it does not match
+func (mr *mediaRange) specificity(candidate string) int {
+	if charset, ok := mr.params["charset"]; ok && !isUTF8(charset) {
+		return -1
+	}
+	switch {
+	case mr.mediaType == candidate:
+		return 2
+	case mr.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mr.mediaType, "/*") && strings.HasPrefix(candidate, strings.TrimSuffix(mr.mediaType, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// indent 返回indent或pretty参数要求的缩进
+// indent returns the indentation asked for by the indent or pretty parameter
+func (mr *mediaRange) indent() string {
+	if value, ok := mr.params["indent"]; ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 16 {
+			return strings.Repeat(" ", n)
+		}
+		return DefaultIndent
+	}
+	if pretty, err := strconv.ParseBool(mr.params["pretty"]); err == nil && pretty {
+		return DefaultIndent
+	}
+	return ""
+}
+
+// bestMediaRange 返回匹配candidate的最具体的媒体范围，不匹配或q为0时返回nil
+// bestMediaRange returns the most specific media range matching candidate, nil when none matches or q is 0
+func bestMediaRange(ranges []*mediaRange, candidate string) *mediaRange {
+	var best *mediaRange
+	bestSpecificity := -1
+	for _, mr := range ranges {
+		if s := mr.specificity(candidate); s > bestSpecificity {
+			best, bestSpecificity = mr, s
+		}
+	}
+	if best == nil || best.q <= 0 {
+		return nil
+	}
+	return best
+}
+
+// parseAccept 解析Accept请求头，忽略无效的媒体范围
+// parseAccept parses an Accept request header, ignoring invalid media ranges
+func parseAccept(accept string) []*mediaRange {
+	var ranges []*mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if !strings.Contains(mediaType, "/") {
+			continue
+		}
+		mr := &mediaRange{mediaType: mediaType, params: make(map[string]string), q: 1}
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(param, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					mr.q = q
+				}
+				continue
+			}
+			mr.params[key] = value
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+// acceptsUTF8 检查Accept-Charset请求头是否接受utf-8
+// acceptsUTF8 checks whether an Accept-Charset request header accepts utf-8
+func acceptsUTF8(charsets string) bool {
+	wildcard := false
+	for _, part := range strings.Split(charsets, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.TrimSpace(name)
+		q := 1.0
+		if key, value, ok := strings.Cut(params, "="); ok && strings.TrimSpace(key) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		switch {
+		case isUTF8(name):
+			return q > 0
+		case name == "*":
+			wildcard = q > 0
+		}
+	}
+	return wildcard
+}
+
+// isUTF8 检查字符集名称是否为utf-8
+// isUTF8 checks whether a charset name is utf-8
+func isUTF8(charset string) bool {
+	charset = strings.ToLower(strings.Trim(strings.TrimSpace(charset), `"`))
+	return charset == "utf-8" || charset == "utf8"
+}
@@ -0,0 +1,43 @@
+package xyJson
+
+import (
+	"bytes"
+	"math"
+)
+
+// 非有限数字的字面量，由部分Python/JavaScript生成器输出
+// Literals of non-finite numbers, emitted by some Python/JavaScript producers
+const (
+	literalNaN         = "NaN"
+	literalInfinity    = "Infinity"
+	literalNegInfinity = "-Infinity"
+)
+
+// matchNonFinite 检查data是否以NaN、Infinity或-Infinity开头，返回对应的浮点数和字面量长度，不匹配时长度为0
+// matchNonFinite checks whether data starts with NaN, Infinity or -Infinity, returning the float value and the
+// literal length, the length is 0 when nothing matches
+func matchNonFinite(data []byte) (float64, int) {
+	switch {
+	case bytes.HasPrefix(data, []byte(literalNaN)):
+		return math.NaN(), len(literalNaN)
+	case bytes.HasPrefix(data, []byte(literalInfinity)):
+		return math.Inf(1), len(literalInfinity)
+	case bytes.HasPrefix(data, []byte(literalNegInfinity)):
+		return math.Inf(-1), len(literalNegInfinity)
+	default:
+		return 0, 0
+	}
+}
+
+// nonFiniteLiteral 返回非有限浮点数的字面量
+// nonFiniteLiteral returns the literal of a non-finite float
+func nonFiniteLiteral(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return literalNaN
+	case f > 0:
+		return literalInfinity
+	default:
+		return literalNegInfinity
+	}
+}
@@ -0,0 +1,282 @@
+package xyJson
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NullPolicy 控制JSON null解码到不可为空的Go字段时的行为
+// NullPolicy controls what happens when JSON null is decoded into a non-nullable Go field
+type NullPolicy int
+
+const (
+	// NullAsZero 将字段设为零值（与encoding/json一致）
+	// NullAsZero sets the field to its zero value (same as encoding/json)
+	NullAsZero NullPolicy = iota
+	// NullError 返回错误
+	// NullError returns an error
+	NullError
+)
+
+// String 返回null策略的字符串表示
+// String returns the string representation of the null policy
+func (np NullPolicy) String() string {
+	switch np {
+	case NullAsZero:
+		return "zero"
+	case NullError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// FieldState 结构体字段在输入中的状态
+// FieldState is the state of a struct field in the input
+type FieldState int
+
+const (
+	// FieldAbsent 字段在输入中不存在
+	// FieldAbsent means the field is not present in the input
+	FieldAbsent FieldState = iota
+	// FieldNull 字段显式为null
+	// FieldNull means the field is explicitly null
+	FieldNull
+	// FieldSet 字段有非null的值
+	// FieldSet means the field has a non-null value
+	FieldSet
+)
+
+// String 返回字段状态的字符串表示
+// String returns the string representation of the field state
+func (fs FieldState) String() string {
+	switch fs {
+	case FieldAbsent:
+		return "absent"
+	case FieldNull:
+		return "null"
+	case FieldSet:
+		return "set"
+	default:
+		return "unknown"
+	}
+}
+
+// FieldPresence 记录解码时每个结构体字段是显式null、有值还是缺失，用于PATCH语义
+// FieldPresence records whether each struct field was explicitly null, set or absent during decoding, for
+// PATCH semantics
+//
+// 字段以JSONPath表示，例如"$.owner.name"和"$.items[0].price"
+// Fields are identified by JSONPath, such as "$.owner.name" and "$.items[0].price"
+//
+// 示例 Example:
+//
+//	presence := xyJson.NewFieldPresence()
+//	err := xyJson.Unmarshal(body, &patch, &xyJson.UnmarshalOptions{Presence: presence})
+//	if presence.IsNull("$.email") {
+//		clearEmail()
+//	}
+type FieldPresence struct {
+	states map[string]FieldState
+}
+
+// NewFieldPresence 创建空的字段状态记录
+// NewFieldPresence creates an empty field presence record
+func NewFieldPresence() *FieldPresence {
+	return &FieldPresence{states: make(map[string]FieldState)}
+}
+
+// State 返回字段状态，未记录的路径为FieldAbsent
+// State returns the state of a field, FieldAbsent for unrecorded paths
+func (fp *FieldPresence) State(path string) FieldState {
+	return fp.states[path]
+}
+
+// Has 检查键在输入中是否出现（null或有值），path可以省略"$."前缀，例如"a.b"等价于"$.a.b"
+// Has checks whether a key appeared in the input (null or set), path may omit the "$." prefix, so "a.b" is
+// equivalent to "$.a.b"
+func (fp *FieldPresence) Has(path string) bool {
+	if !strings.HasPrefix(path, "$") {
+		path = "$." + path
+	}
+	return fp.IsPresent(path)
+}
+
+// IsNull 检查字段是否显式为null
+// IsNull checks whether a field is explicitly null
+func (fp *FieldPresence) IsNull(path string) bool {
+	return fp.states[path] == FieldNull
+}
+
+// IsSet 检查字段是否有非null的值
+// IsSet checks whether a field has a non-null value
+func (fp *FieldPresence) IsSet(path string) bool {
+	return fp.states[path] == FieldSet
+}
+
+// IsPresent 检查字段是否出现在输入中（null或有值）
+// IsPresent checks whether a field appears in the input (null or set)
+func (fp *FieldPresence) IsPresent(path string) bool {
+	return fp.states[path] != FieldAbsent
+}
+
+// Paths 返回处于指定状态的所有路径（排序后），FieldAbsent总是返回空
+// Paths returns all paths in the given state (sorted), always empty for FieldAbsent
+func (fp *FieldPresence) Paths(state FieldState) []string {
+	var paths []string
+	for path, s := range fp.states {
+		if s == state {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Reset 清空所有记录
+// Reset clears all records
+func (fp *FieldPresence) Reset() {
+	for path := range fp.states {
+		delete(fp.states, path)
+	}
+}
+
+// nullInspector 按目标类型遍历IValue树，检查null策略并记录字段状态
+// nullInspector walks an IValue tree along the target type, enforcing the null policy and recording field states
+type nullInspector struct {
+	policy   NullPolicy
+	presence *FieldPresence
+}
+
+// inspect 检查value能否按策略解码到类型t
+// inspect checks whether value can be decoded into type t under the policy
+func (ni *nullInspector) inspect(value IValue, t reflect.Type, path string, depth int) error {
+	if depth > DefaultMaxDepth {
+		return NewMaxDepthExceededError(depth)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj := value.AsObject()
+		if obj == nil || t == timeType || customUnmarshaler(t) {
+			return nil
+		}
+		return ni.inspectStruct(obj, t, path, depth)
+
+	case reflect.Slice, reflect.Array:
+		arr := value.AsArray()
+		if arr == nil {
+			return nil
+		}
+		for i := 0; i < arr.Length(); i++ {
+			if err := ni.inspectElement(arr.Get(i), t.Elem(), path+"["+strconv.Itoa(i)+"]", depth); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		obj := value.AsObject()
+		if obj == nil {
+			return nil
+		}
+		keys := obj.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := ni.inspectElement(obj.Get(key), t.Elem(), appendPathKey(path, key), depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// inspectStruct 按声明顺序检查结构体字段
+// inspectStruct checks struct fields in declaration order
+func (ni *nullInspector) inspectStruct(obj IObject, t reflect.Type, path string, depth int) error {
+	info := getStructInfo(t)
+	fields := make([]*fieldInfo, 0, len(info.Fields))
+	for _, field := range info.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Index < fields[j].Index })
+
+	for _, field := range fields {
+		child := obj.Get(field.Name)
+		if child == nil {
+			continue
+		}
+
+		fieldPath := appendPathKey(path, field.Name)
+		if child.IsNull() {
+			ni.record(fieldPath, FieldNull)
+			if ni.policy == NullError && !field.IsPtr && !nullableKind(field.Type.Kind()) {
+				return nullFieldError(field.Type, fieldPath)
+			}
+			continue
+		}
+
+		ni.record(fieldPath, FieldSet)
+		if field.Tag.AsString {
+			continue
+		}
+		if err := ni.inspect(child, field.Type, fieldPath, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inspectElement 检查数组元素或map值
+// inspectElement checks an array element or map value
+func (ni *nullInspector) inspectElement(value IValue, t reflect.Type, path string, depth int) error {
+	if value == nil {
+		return nil
+	}
+	if value.IsNull() {
+		if ni.policy == NullError && !nullableKind(t.Kind()) {
+			return nullFieldError(t, path)
+		}
+		return nil
+	}
+	return ni.inspect(value, t, path, depth+1)
+}
+
+// record 记录字段状态
+// record records a field state
+func (ni *nullInspector) record(path string, state FieldState) {
+	if ni.presence == nil {
+		return
+	}
+	if ni.presence.states == nil {
+		ni.presence.states = make(map[string]FieldState)
+	}
+	ni.presence.states[path] = state
+}
+
+// nullableKind 检查该种类的Go值能否表示null
+// nullableKind checks whether Go values of the kind can represent null
+func nullableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
+	default:
+		return false
+	}
+}
+
+// nullFieldError 创建null写入不可为空字段的错误
+// nullFieldError creates the error for null decoded into a non-nullable field
+func nullFieldError(t reflect.Type, path string) error {
+	expected := ValueTypeFromKind(t.Kind())
+	if t == timeType {
+		expected = StringValueType
+	}
+	err := NewNullValueError(expected)
+	err.WithPath(path).WithContext("field of type " + t.String() + " is not nullable")
+	return err
+}
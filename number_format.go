@@ -0,0 +1,178 @@
+package xyJson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat 带分组分隔符的本地化数字格式，用于转换"1,234.56"或"1.234,56"这类字符串
+// NumberFormat is a localized number format with group separators, used to convert strings such as "1,234.56"
+// or "1.234,56"
+//
+// 分组分隔符只能出现在小数点之前，第一组1到3位数字，其后每组恰好3位。零值表示不启用格式化数字
+// Group separators may only appear before the decimal separator, the first group has 1 to 3 digits and every
+// later group exactly 3. The zero value disables formatted numbers
+type NumberFormat struct {
+	// GroupSeparators 接受的分组分隔符，可以包含多个字符，例如" \u00a0"
+	// GroupSeparators are the accepted group separators, possibly several characters such as " \u00a0"
+	GroupSeparators string
+
+	// DecimalSeparator 小数点
+	// DecimalSeparator is the decimal separator
+	DecimalSeparator rune
+}
+
+// NumberFormatEnglish 返回英语格式，例如1,234.56
+// NumberFormatEnglish returns the English format, such as 1,234.56
+func NumberFormatEnglish() NumberFormat {
+	return NumberFormat{GroupSeparators: ",", DecimalSeparator: '.'}
+}
+
+// NumberFormatGerman 返回德语格式，例如1.234,56
+// NumberFormatGerman returns the German format, such as 1.234,56
+func NumberFormatGerman() NumberFormat {
+	return NumberFormat{GroupSeparators: ".", DecimalSeparator: ','}
+}
+
+// NumberFormatFrench 返回法语格式，例如1 234,56，接受空格、不换行空格和窄不换行空格
+// NumberFormatFrench returns the French format, such as 1 234,56, accepting spaces, no-break spaces and narrow
+// no-break spaces
+func NumberFormatFrench() NumberFormat {
+	return NumberFormat{GroupSeparators: " \u00a0\u202f", DecimalSeparator: ','}
+}
+
+// NumberFormatSwiss 返回瑞士格式，例如1'234.56
+// NumberFormatSwiss returns the Swiss format, such as 1'234.56
+func NumberFormatSwiss() NumberFormat {
+	return NumberFormat{GroupSeparators: "'\u2019", DecimalSeparator: '.'}
+}
+
+// ParseFloat 按格式解析字符串为浮点数
+// ParseFloat parses a string as a float in the format
+//
+// 示例 Example:
+//
+//	f, err := xyJson.NumberFormatGerman().ParseFloat("1.234,56") // 1234.56
+func (nf NumberFormat) ParseFloat(s string) (float64, error) {
+	normalized, ok := nf.normalize(s)
+	if ok {
+		if f, err := strconv.ParseFloat(normalized, 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, NewInvalidOperationError("float64 conversion", fmt.Sprintf("cannot parse '%s' as a formatted number", s))
+}
+
+// ParseInt 按格式解析字符串为整数，带小数部分的字符串返回错误
+// ParseInt parses a string as an integer in the format, strings with a fractional part return an error
+func (nf NumberFormat) ParseInt(s string) (int64, error) {
+	normalized, ok := nf.normalize(s)
+	if ok {
+		if i, err := strconv.ParseInt(normalized, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	return 0, NewInvalidOperationError("int64 conversion", fmt.Sprintf("cannot parse '%s' as a formatted integer", s))
+}
+
+// normalize 去掉分组分隔符并将小数点替换为'.'，返回strconv可以解析的字符串
+// normalize removes the group separators and replaces the decimal separator with '.', returning a string
+// strconv can parse
+func (nf NumberFormat) normalize(s string) (string, bool) {
+	if nf.DecimalSeparator == 0 || strings.ContainsRune(nf.GroupSeparators, nf.DecimalSeparator) {
+		return "", false
+	}
+	s = strings.TrimSpace(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+
+	digits, group, grouped, fraction := 0, 0, false, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+			group++
+		case r == nf.DecimalSeparator && !fraction:
+			if grouped && group != 3 {
+				return "", false
+			}
+			b.WriteByte('.')
+			fraction = true
+		case !fraction && strings.ContainsRune(nf.GroupSeparators, r):
+			if group == 0 || group > 3 || grouped && group != 3 {
+				return "", false
+			}
+			grouped, group = true, 0
+		default:
+			return "", false
+		}
+	}
+	if digits == 0 || grouped && !fraction && group != 3 {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// ToInt 将值转换为整数，字符串先按格式解析，不符合格式时与ToInt相同
+// ToInt converts a value to an integer, strings are parsed with the format first and otherwise behave as in
+// ToInt
+//
+// 格式只作用于本次调用，因此德语格式下"1.234"为1234，而ToInt仍然按strconv解析
+// The format only applies to this call, so "1.234" is 1234 in the German format while ToInt still parses
+// with strconv
+//
+// 示例 Example:
+//
+//	qty, err := xyJson.NumberFormatGerman().ToInt(xyJson.CreateString("2.500")) // 2500
+func (nf NumberFormat) ToInt(value IValue) (int, error) {
+	if normalized, ok := nf.normalizeValue(value); ok {
+		if i, err := strconv.Atoi(normalized); err == nil {
+			return i, nil
+		}
+	}
+	return ToInt(value)
+}
+
+// ToInt64 将值转换为64位整数，字符串先按格式解析，不符合格式时与ToInt64相同
+// ToInt64 converts a value to a 64-bit integer, strings are parsed with the format first and otherwise behave
+// as in ToInt64
+func (nf NumberFormat) ToInt64(value IValue) (int64, error) {
+	if normalized, ok := nf.normalizeValue(value); ok {
+		if i, err := strconv.ParseInt(normalized, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	return ToInt64(value)
+}
+
+// ToFloat64 将值转换为64位浮点数，字符串先按格式解析，不符合格式时与ToFloat64相同
+// ToFloat64 converts a value to a 64-bit float, strings are parsed with the format first and otherwise behave
+// as in ToFloat64
+//
+// 示例 Example:
+//
+//	price, err := xyJson.NumberFormatGerman().ToFloat64(xyJson.CreateString("1.234,56")) // 1234.56
+func (nf NumberFormat) ToFloat64(value IValue) (float64, error) {
+	if normalized, ok := nf.normalizeValue(value); ok {
+		if f, err := strconv.ParseFloat(normalized, 64); err == nil {
+			return f, nil
+		}
+	}
+	return ToFloat64(value)
+}
+
+// normalizeValue 按格式规范化字符串值，其他类型的值返回false
+// normalizeValue normalizes a string value with the format, returning false for values of other types
+func (nf NumberFormat) normalizeValue(value IValue) (string, bool) {
+	if value == nil || value.Type() != StringValueType {
+		return "", false
+	}
+	return nf.normalize(value.AsString())
+}
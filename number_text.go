@@ -0,0 +1,106 @@
+package xyJson
+
+import "strconv"
+
+// textNumber 保留原始文本的数字，由开启ParseOptions.PreserveNumberText的解析创建
+// textNumber is a number keeping its original text, created by parses with ParseOptions.PreserveNumberText
+//
+// 数值与普通数字相同，只有序列化、String和AsString使用原始文本；超出int64范围的整数以近似的float64为数值，
+// 原始文本保持精确
+// The numeric value is the same as for a plain number, only serialization, String and AsString use the
+// original text; integers beyond the int64 range get an approximate float64 value while the text stays exact
+type textNumber struct {
+	scalarValue
+	text string
+
+	// inexact 数值只是原始文本的近似
+	// inexact means the numeric value only approximates the original text
+	inexact bool
+}
+
+// NumberText 返回保留的数字原始文本；value不是开启PreserveNumberText解析出的数字时ok为false
+// NumberText returns the preserved original text of a number; ok is false when value is not a number parsed
+// with PreserveNumberText
+//
+// 示例 Example:
+//
+//	value, _ := xyJson.ParseWithOptions([]byte(`{"id":123456789012345678901234567890}`),
+//		&xyJson.ParseOptions{PreserveNumberText: true})
+//	text, _ := xyJson.NumberText(xyJson.MustGet(value, "$.id")) // "123456789012345678901234567890"
+func NumberText(value IValue) (text string, ok bool) {
+	if tn, ok := value.(*textNumber); ok {
+		return tn.text, true
+	}
+	return "", false
+}
+
+// newTextNumber 根据数字词法单元创建保留原始文本的数字，超出int64范围的整数转为近似的浮点数
+// newTextNumber creates a number keeping the text of a numeric token, integers beyond the int64 range become
+// approximate floats
+func newTextNumber(text string, isFloat bool) (*textNumber, error) {
+	tn := &textNumber{scalarValue: scalarValue{valueType: NumberValueType}, text: text}
+	if !isFloat {
+		if val, err := strconv.ParseInt(text, 10, 64); err == nil {
+			tn.rawData = val
+			return tn, nil
+		}
+		tn.inexact = true
+	}
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil && !tn.inexact {
+		return nil, NewInvalidJSONError("invalid number: "+text, nil)
+	}
+	tn.rawData = val
+	return tn, nil
+}
+
+// String 返回原始文本
+// String returns the original text
+func (tn *textNumber) String() string {
+	return tn.text
+}
+
+// AsString 返回原始文本
+// AsString returns the original text
+func (tn *textNumber) AsString() string {
+	return tn.text
+}
+
+// Clone 创建保留原始文本的拷贝
+// Clone creates a copy keeping the original text
+func (tn *textNumber) Clone() IValue {
+	clone := &textNumber{
+		scalarValue: scalarValue{valueType: tn.valueType, rawData: tn.rawData},
+		text:        tn.text,
+		inexact:     tn.inexact,
+	}
+	tn.copyMetaTo(&clone.valueMeta)
+	return clone
+}
+
+// Equals 比较数值，任一方的数值不精确时比较原始文本
+// Equals compares the numeric values, or the original texts when either numeric value is inexact
+func (tn *textNumber) Equals(other IValue) bool {
+	if o, ok := other.(*textNumber); ok && (tn.inexact || o.inexact) {
+		return tn.text == o.text
+	}
+	return tn.scalarValue.Equals(other)
+}
+
+// CachedJSON 直接序列化，见scalarValue.CachedJSON
+// CachedJSON serializes directly, see scalarValue.CachedJSON
+func (tn *textNumber) CachedJSON() ([]byte, error) {
+	return defaultSerializer().Serialize(tn)
+}
+
+// ETag 返回原始文本的实体标签
+// ETag returns the entity tag of the original text
+func (tn *textNumber) ETag() (string, error) {
+	return computeETag([]byte(tn.text)), nil
+}
+
+// Snapshot 返回保留原始文本的快照
+// Snapshot returns a snapshot keeping the original text
+func (tn *textNumber) Snapshot() (*DocumentSnapshot, error) {
+	return takeSnapshot(tn)
+}
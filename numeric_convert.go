@@ -0,0 +1,363 @@
+package xyJson
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// FloatTruncation 浮点数转换为整数时对小数部分的处理策略
+// FloatTruncation is the policy for the fractional part when converting a float to an integer
+type FloatTruncation int
+
+const (
+	// TruncationReject 有小数部分时返回错误
+	// TruncationReject returns an error when there is a fractional part
+	TruncationReject FloatTruncation = iota
+	// TruncationTowardZero 向零截断
+	// TruncationTowardZero truncates toward zero
+	TruncationTowardZero
+	// TruncationRound 四舍五入，.5远离零
+	// TruncationRound rounds to the nearest integer, halves away from zero
+	TruncationRound
+	// TruncationFloor 向下取整
+	// TruncationFloor rounds toward negative infinity
+	TruncationFloor
+	// TruncationCeil 向上取整
+	// TruncationCeil rounds toward positive infinity
+	TruncationCeil
+)
+
+// String 返回策略的名称
+// String returns the name of the policy
+func (t FloatTruncation) String() string {
+	switch t {
+	case TruncationReject:
+		return "reject"
+	case TruncationTowardZero:
+		return "toward-zero"
+	case TruncationRound:
+		return "round"
+	case TruncationFloor:
+		return "floor"
+	case TruncationCeil:
+		return "ceil"
+	default:
+		return "unknown"
+	}
+}
+
+// NumberConversionOptions 数字类型之间转换的选项，超出目标范围始终是错误
+// NumberConversionOptions configures conversions between numeric types, exceeding the target range is always an
+// error
+type NumberConversionOptions struct {
+	// Truncation 浮点数转换为整数时对小数部分的处理策略
+	// Truncation is the policy for the fractional part when converting a float to an integer
+	Truncation FloatTruncation
+
+	// RejectPrecisionLoss 为true时，无法被float64或float32精确表示的整数和无法被float32精确表示的float64返回错误
+	// RejectPrecisionLoss makes integers not exactly representable by float64 or float32, and float64 values not
+	// exactly representable by float32, return an error
+	RejectPrecisionLoss bool
+}
+
+// DefaultNumberConversionOptions 返回默认的数字转换选项：拒绝小数部分，允许转换为浮点数时丢失精度
+// DefaultNumberConversionOptions returns the default number conversion options: fractional parts are rejected
+// and precision loss when converting to floats is allowed
+func DefaultNumberConversionOptions() *NumberConversionOptions {
+	return &NumberConversionOptions{
+		Truncation:          TruncationReject,
+		RejectPrecisionLoss: false,
+	}
+}
+
+// conversionOptions 返回options，为nil时返回默认选项
+// conversionOptions returns options, or the defaults when it is nil
+func conversionOptions(options *NumberConversionOptions) *NumberConversionOptions {
+	if options != nil {
+		return options
+	}
+	return &defaultNumberConversion
+}
+
+// defaultNumberConversion 未指定选项时使用的默认选项
+// defaultNumberConversion are the defaults used when no options are given
+var defaultNumberConversion = *DefaultNumberConversionOptions()
+
+// NumberToInt 将数字转换为bits位有符号整数，bits为0表示int，与strconv.ParseInt的bitSize相同；options为nil时使用默认选项
+// NumberToInt converts a number to a signed integer of the given bits, 0 meaning int as for the bitSize of
+// strconv.ParseInt; the default options are used when options is nil
+//
+// 示例 Example:
+//
+//	n, err := xyJson.NumberToInt(xyJson.CreateNumber(300), 8, nil) // 错误 error: value 300 out of int8 range
+func NumberToInt(value IValue, bits int, options *NumberConversionOptions) (int64, error) {
+	raw, err := numberRaw(value)
+	if err != nil {
+		return 0, err
+	}
+	i, jerr := convertToInt(raw, bits, conversionOptions(options))
+	if jerr != nil {
+		return 0, jerr
+	}
+	return i, nil
+}
+
+// NumberToUint 将数字转换为bits位无符号整数，bits为0表示uint；options为nil时使用默认选项
+// NumberToUint converts a number to an unsigned integer of the given bits, 0 meaning uint; the default options are
+// used when options is nil
+func NumberToUint(value IValue, bits int, options *NumberConversionOptions) (uint64, error) {
+	raw, err := numberRaw(value)
+	if err != nil {
+		return 0, err
+	}
+	u, jerr := convertToUint(raw, bits, conversionOptions(options))
+	if jerr != nil {
+		return 0, jerr
+	}
+	return u, nil
+}
+
+// NumberToFloat 将数字转换为32位或64位浮点数；options为nil时使用默认选项
+// NumberToFloat converts a number to a 32-bit or 64-bit float; the default options are used when options is nil
+func NumberToFloat(value IValue, bits int, options *NumberConversionOptions) (float64, error) {
+	raw, err := numberRaw(value)
+	if err != nil {
+		return 0, err
+	}
+	f, jerr := convertToFloat(raw, bits, conversionOptions(options))
+	if jerr != nil {
+		return 0, jerr
+	}
+	return f, nil
+}
+
+// numberRaw 返回数字值的原始数据
+// numberRaw returns the raw data of a number value
+func numberRaw(value IValue) (any, error) {
+	if value == nil {
+		return nil, NewNullValueError(NumberValueType)
+	}
+	if value.Type() != NumberValueType {
+		return nil, NewTypeMismatchError(NumberValueType, value.Type(), "")
+	}
+	return value.Raw(), nil
+}
+
+// intBits 将bits规范化为1到64，0表示平台int的位数
+// intBits normalizes bits to 1 through 64, 0 meaning the size of the platform int
+func intBits(bits int) int {
+	if bits <= 0 || bits > 64 {
+		return strconv.IntSize
+	}
+	return bits
+}
+
+// intTypeName 返回整数类型名称，如int8、uint
+// intTypeName returns the name of an integer type, such as int8 or uint
+func intTypeName(signed bool, bits int) string {
+	name := "int"
+	if !signed {
+		name = "uint"
+	}
+	if bits > 0 && bits <= 64 {
+		name += strconv.Itoa(bits)
+	}
+	return name
+}
+
+// rawNumberString 返回原始数字的文本
+// rawNumberString returns the text of a raw number
+func rawNumberString(raw any) string {
+	switch v := raw.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return "?"
+	}
+}
+
+// outOfRangeError 创建超出目标类型范围的错误
+// outOfRangeError creates an error for a number outside the range of the target type
+func outOfRangeError(raw any, target string) *JSONError {
+	return NewJSONError(ErrNumberConversion, "value "+rawNumberString(raw)+" out of "+target+" range", nil)
+}
+
+// truncateFloat 按策略去掉小数部分，策略为拒绝时返回错误
+// truncateFloat removes the fractional part according to the policy, returning an error when it rejects
+func truncateFloat(f float64, target string, options *NumberConversionOptions) (float64, *JSONError) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, outOfRangeError(f, target)
+	}
+	if f == math.Trunc(f) {
+		return f, nil
+	}
+	switch options.Truncation {
+	case TruncationTowardZero:
+		return math.Trunc(f), nil
+	case TruncationRound:
+		return math.Round(f), nil
+	case TruncationFloor:
+		return math.Floor(f), nil
+	case TruncationCeil:
+		return math.Ceil(f), nil
+	default:
+		return 0, NewJSONError(ErrNumberConversion, "value "+rawNumberString(f)+" has a fractional part, cannot convert to "+target, nil)
+	}
+}
+
+// convertToInt 将原始数字转换为bits位有符号整数
+// convertToInt converts a raw number to a signed integer of the given bits
+func convertToInt(raw any, bits int, options *NumberConversionOptions) (int64, *JSONError) {
+	target := intTypeName(true, bits)
+	size := intBits(bits)
+	switch v := raw.(type) {
+	case int64:
+		if size < 64 && (v < -1<<(size-1) || v > 1<<(size-1)-1) {
+			return 0, outOfRangeError(v, target)
+		}
+		return v, nil
+	case uint64:
+		if v > 1<<(size-1)-1 {
+			return 0, outOfRangeError(v, target)
+		}
+		return int64(v), nil
+	case float64:
+		f, err := truncateFloat(v, target, options)
+		if err != nil {
+			return 0, err
+		}
+		// 2^(size-1)本身超出范围，因此上界用>=
+		// 2^(size-1) itself is out of range, hence >= for the upper bound
+		limit := math.Ldexp(1, size-1)
+		if f < -limit || f >= limit {
+			return 0, outOfRangeError(v, target)
+		}
+		return int64(f), nil
+	default:
+		return 0, NewJSONError(ErrNumberConversion, "unexpected number type", nil)
+	}
+}
+
+// convertToUint 将原始数字转换为bits位无符号整数
+// convertToUint converts a raw number to an unsigned integer of the given bits
+func convertToUint(raw any, bits int, options *NumberConversionOptions) (uint64, *JSONError) {
+	target := intTypeName(false, bits)
+	size := intBits(bits)
+	switch v := raw.(type) {
+	case int64:
+		if v < 0 || size < 64 && uint64(v) > 1<<size-1 {
+			return 0, outOfRangeError(v, target)
+		}
+		return uint64(v), nil
+	case uint64:
+		if size < 64 && v > 1<<size-1 {
+			return 0, outOfRangeError(v, target)
+		}
+		return v, nil
+	case float64:
+		f, err := truncateFloat(v, target, options)
+		if err != nil {
+			return 0, err
+		}
+		if f < 0 || f >= math.Ldexp(1, size) {
+			return 0, outOfRangeError(v, target)
+		}
+		return uint64(f), nil
+	default:
+		return 0, NewJSONError(ErrNumberConversion, "unexpected number type", nil)
+	}
+}
+
+// convertToFloat 将原始数字转换为32位或64位浮点数，超出float32范围的有限值是错误
+// convertToFloat converts a raw number to a 32-bit or 64-bit float, finite values beyond the float32 range are
+// an error
+func convertToFloat(raw any, bits int, options *NumberConversionOptions) (float64, *JSONError) {
+	target := "float64"
+	if bits == 32 {
+		target = "float32"
+	}
+	switch v := raw.(type) {
+	case int64:
+		f := float64(v)
+		if bits == 32 {
+			f = float64(float32(f))
+		}
+		if options.RejectPrecisionLoss && (f >= math.Ldexp(1, 63) || int64(f) != v) {
+			return 0, NewJSONError(ErrNumberConversion, "value "+rawNumberString(v)+" loses precision as "+target, nil)
+		}
+		return f, nil
+	case uint64:
+		f := float64(v)
+		if bits == 32 {
+			f = float64(float32(f))
+		}
+		if options.RejectPrecisionLoss && (f >= math.Ldexp(1, 64) || uint64(f) != v) {
+			return 0, NewJSONError(ErrNumberConversion, "value "+rawNumberString(v)+" loses precision as "+target, nil)
+		}
+		return f, nil
+	case float64:
+		if bits != 32 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return v, nil
+		}
+		if math.Abs(v) > math.MaxFloat32 {
+			return 0, outOfRangeError(v, target)
+		}
+		if options.RejectPrecisionLoss && float64(float32(v)) != v {
+			return 0, NewJSONError(ErrNumberConversion, "value "+rawNumberString(v)+" loses precision as "+target, nil)
+		}
+		return v, nil
+	default:
+		return 0, NewJSONError(ErrNumberConversion, "unexpected number type", nil)
+	}
+}
+
+// setReflectNumber 将数字写入整数或浮点数类型的reflect.Value，按目标类型的位数检查范围
+// setReflectNumber writes a number into an integer or float reflect.Value, checking the range of the target's
+// bit size
+func setReflectNumber(rv reflect.Value, raw any, options *NumberConversionOptions) *JSONError {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := convertToInt(raw, kindBits(rv.Kind()), options)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := convertToUint(raw, kindBits(rv.Kind()), options)
+		if err != nil {
+			return err
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := convertToFloat(raw, kindBits(rv.Kind()), options)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	default:
+		return NewTypeMismatchError(NumberValueType, ValueTypeFromKind(rv.Kind()), "")
+	}
+	return nil
+}
+
+// kindBits 返回数字种类的位数，int、uint和uintptr返回0以使用平台名称
+// kindBits returns the bit size of a numeric kind, 0 for int, uint and uintptr so the platform names are used
+func kindBits(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 8
+	case reflect.Int16, reflect.Uint16:
+		return 16
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 32
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return 64
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,146 @@
+package xyJson
+
+import "math"
+
+// NumericTolerance 数字比较的容差，两个数相差不超过Absolute，或不超过Relative乘以较大的绝对值时视为相等
+// NumericTolerance is the tolerance of numeric comparisons, two numbers are equal when they differ by at most
+// Absolute, or by at most Relative times the larger magnitude
+//
+// 零值表示精确比较。NaN与任何数都不相等
+// The zero value means exact comparison. NaN equals no number
+type NumericTolerance struct {
+	// Absolute 允许的绝对误差
+	// Absolute is the allowed absolute difference
+	Absolute float64
+
+	// Relative 允许的相对误差，例如1e-9
+	// Relative is the allowed relative difference, such as 1e-9
+	Relative float64
+}
+
+// Equal 判断两个数在容差内是否相等
+// Equal reports whether two numbers are equal within the tolerance
+func (t NumericTolerance) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	if math.IsNaN(a) || math.IsNaN(b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	diff := math.Abs(a - b)
+	return diff <= t.Absolute || diff <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// Compare 在容差内比较两个数，相等返回0，a较小返回-1，a较大返回1
+// Compare compares two numbers within the tolerance, returning 0 when equal, -1 when a is smaller and 1 when a
+// is larger
+func (t NumericTolerance) Compare(a, b float64) int {
+	switch {
+	case t.Equal(a, b):
+		return 0
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// isExact 是否为精确比较
+// isExact reports whether the comparison is exact
+func (t NumericTolerance) isExact() bool {
+	return t.Absolute <= 0 && t.Relative <= 0
+}
+
+// EqualOptions 深度比较选项
+// EqualOptions holds the options of deep comparison
+type EqualOptions struct {
+	// Tolerance 数字比较的容差，零值表示精确比较
+	// Tolerance is the tolerance of numeric comparisons, the zero value means exact comparison
+	Tolerance NumericTolerance
+}
+
+// DefaultEqualOptions 返回默认的深度比较选项（精确比较）
+// DefaultEqualOptions returns the default deep comparison options (exact comparison)
+func DefaultEqualOptions() *EqualOptions {
+	return &EqualOptions{}
+}
+
+// Equal 深度比较两个值，数字按数值比较，因此1与1.0相等；两个nil相等
+// Equal compares two values deeply, numbers by value so 1 equals 1.0; two nils are equal
+func Equal(a, b IValue) bool {
+	return EqualWithOptions(a, b, nil)
+}
+
+// EqualWithOptions 使用指定选项深度比较两个值，对象键的顺序不影响结果
+// EqualWithOptions compares two values deeply with the specified options, the order of object keys does not
+// matter
+//
+// 示例 Example:
+//
+//	computed := xyJson.MustParseString(`{"total":0.30000000000000004}`)
+//	expected := xyJson.MustParseString(`{"total":0.3}`)
+//	xyJson.EqualWithOptions(computed, expected, &xyJson.EqualOptions{
+//		Tolerance: xyJson.NumericTolerance{Relative: 1e-9},
+//	}) // true
+func EqualWithOptions(a, b IValue, options *EqualOptions) bool {
+	if options == nil {
+		options = DefaultEqualOptions()
+	}
+	return valuesEqualWithin(a, b, options.Tolerance, 0)
+}
+
+// valuesEqualWithin 递归比较两个值，数字使用容差
+// valuesEqualWithin compares two values recursively, numbers with the tolerance
+func valuesEqualWithin(a, b IValue, tolerance NumericTolerance, depth int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.Type() != b.Type() || depth > MaxNestingDepth {
+		return false
+	}
+
+	switch a.Type() {
+	case NumberValueType:
+		l, lok := a.AsFloat64OK()
+		r, rok := b.AsFloat64OK()
+		if !lok || !rok {
+			return a.Equals(b)
+		}
+		// 超出float64精度的整数精确比较
+		// Integers beyond float64 precision compare exactly
+		if li, ok := a.AsInt64OK(); ok {
+			if ri, ok := b.AsInt64OK(); ok && (li == ri || tolerance.isExact()) {
+				return li == ri
+			}
+		}
+		return tolerance.Equal(l, r)
+
+	case ObjectValueType:
+		left, right := a.AsObject(), b.AsObject()
+		if left.Size() != right.Size() {
+			return false
+		}
+		equal := true
+		left.Range(func(key string, value IValue) bool {
+			other := right.Get(key)
+			equal = other != nil && valuesEqualWithin(value, other, tolerance, depth+1)
+			return equal
+		})
+		return equal
+
+	case ArrayValueType:
+		left, right := a.AsArray(), b.AsArray()
+		if left.Length() != right.Length() {
+			return false
+		}
+		for i := 0; i < left.Length(); i++ {
+			if !valuesEqualWithin(left.Get(i), right.Get(i), tolerance, depth+1) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return a.Equals(b)
+	}
+}
@@ -0,0 +1,36 @@
+package xyJson
+
+// KV 对象中的一个键值对
+// KV is one key-value pair of an object
+type KV struct {
+	// Key 键名
+	// Key is the key
+	Key string
+
+	// Value 值
+	// Value is the value
+	Value IValue
+}
+
+// KeysInOrder 返回按规范顺序排列的键名
+// KeysInOrder returns the keys in canonical order
+func (ov *objectValue) KeysInOrder() []string {
+	ov.mu.RLock()
+	defer ov.mu.RUnlock()
+
+	return ov.sortedKeys()
+}
+
+// ToOrderedPairs 返回按规范顺序排列的键值对
+// ToOrderedPairs returns the pairs in canonical order
+func (ov *objectValue) ToOrderedPairs() []KV {
+	ov.mu.RLock()
+	entries := ov.sortedEntries()
+	ov.mu.RUnlock()
+
+	pairs := make([]KV, len(entries))
+	for i, entry := range entries {
+		pairs[i] = KV{Key: entry.key, Value: entry.value}
+	}
+	return pairs
+}
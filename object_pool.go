@@ -9,22 +9,43 @@ import (
 // objectPool 对象池实现
 // objectPool implements the IObjectPool interface
 type objectPool struct {
-	valuePool  sync.Pool
-	objectPool sync.Pool
-	arrayPool  sync.Pool
+	// pools 当前的池集合，收缩和清空时整体替换
+	// pools is the current set of pools, replaced as a whole on shrink and clear
+	pools atomic.Pointer[poolSet]
+
+	// used 自上次清理以来是否有过Get，由清理协程检查并清除
+	// used reports whether there was a Get since the last cleanup, tested and cleared by the cleanup goroutine
+	used atomic.Bool
+
+	// stopCleanup 关闭时停止清理协程，closeOnce保证只关闭一次
+	// stopCleanup stops the cleanup goroutine on close, closeOnce ensures it is closed once
+	stopCleanup chan struct{}
+	closeOnce   sync.Once
 
 	// 统计信息
 	stats struct {
 		totalAllocated int64
 		totalReused    int64
 		currentInUse   int64
+		warmTarget     int64
+		warmCompleted  int64
+		shrinks        int64
 	}
 
 	// 配置选项
 	maxPoolSize int
+	maxIdleTime time.Duration
 	enabled     bool
 }
 
+// poolSet 标量值、对象和数组的sync.Pool集合
+// poolSet is the set of sync.Pools for scalar values, objects and arrays
+type poolSet struct {
+	valuePool  sync.Pool
+	objectPool sync.Pool
+	arrayPool  sync.Pool
+}
+
 // NewObjectPool 创建新的对象池
 // NewObjectPool creates a new object pool
 func NewObjectPool() IObjectPool {
@@ -42,9 +63,26 @@ type ObjectPoolOptions struct {
 	// Enabled indicates whether the object pool is enabled
 	Enabled bool
 
-	// CleanupInterval 清理间隔
-	// CleanupInterval is the cleanup interval
+	// CleanupInterval 清理间隔，0表示不启动清理协程
+	// CleanupInterval is the cleanup interval, 0 means no cleanup goroutine is started
 	CleanupInterval time.Duration
+
+	// MaxIdleTime 池在没有Get的情况下保留空闲对象的最长时间，超过后在下一次清理时丢弃；0表示使用CleanupInterval
+	// MaxIdleTime is how long the pool keeps idle entries without any Get, after which the next cleanup drops
+	// them; 0 means CleanupInterval
+	MaxIdleTime time.Duration
+
+	// WarmValues、WarmObjects、WarmArrays 创建时预分配的标量值、对象和数组数量，见Warm
+	// WarmValues, WarmObjects and WarmArrays are the numbers of scalar values, objects and arrays preallocated
+	// on creation, see Warm
+	WarmValues  int
+	WarmObjects int
+	WarmArrays  int
+
+	// WarmInBackground 是否在后台协程中预分配，使创建立即返回；进度见PoolStats
+	// WarmInBackground indicates whether the preallocation runs in a background goroutine so creation returns
+	// immediately; the progress is reported in PoolStats
+	WarmInBackground bool
 }
 
 // DefaultObjectPoolOptions 返回默认对象池选项
@@ -66,30 +104,27 @@ func NewObjectPoolWithOptions(options *ObjectPoolOptions) IObjectPool {
 
 	pool := &objectPool{
 		maxPoolSize: options.MaxPoolSize,
+		maxIdleTime: options.MaxIdleTime,
 		enabled:     options.Enabled,
+		stopCleanup: make(chan struct{}),
 	}
-
-	// 初始化sync.Pool
-	pool.valuePool.New = func() interface{} {
-		atomic.AddInt64(&pool.stats.totalAllocated, 1)
-		return &scalarValue{}
-	}
-
-	pool.objectPool.New = func() interface{} {
-		atomic.AddInt64(&pool.stats.totalAllocated, 1)
-		return NewObject()
-	}
-
-	pool.arrayPool.New = func() interface{} {
-		atomic.AddInt64(&pool.stats.totalAllocated, 1)
-		return NewArray()
+	if pool.maxIdleTime <= 0 {
+		pool.maxIdleTime = options.CleanupInterval
 	}
+	pool.pools.Store(pool.newPoolSet())
 
 	// 启动清理协程
 	if options.CleanupInterval > 0 {
 		go pool.cleanupRoutine(options.CleanupInterval)
 	}
 
+	// 预热
+	if options.WarmInBackground {
+		go pool.Warm(options.WarmValues, options.WarmObjects, options.WarmArrays)
+	} else {
+		pool.Warm(options.WarmValues, options.WarmObjects, options.WarmArrays)
+	}
+
 	return pool
 }
 
@@ -102,7 +137,7 @@ func (p *objectPool) GetValue() IValue {
 
 	atomic.AddInt64(&p.stats.currentInUse, 1)
 
-	if value := p.valuePool.Get(); value != nil {
+	if value := p.acquire().valuePool.Get(); value != nil {
 		atomic.AddInt64(&p.stats.totalReused, 1)
 		if sv, ok := value.(*scalarValue); ok {
 			sv.reset()
@@ -126,7 +161,7 @@ func (p *objectPool) PutValue(value IValue) {
 	// 只回收标量值
 	if sv, ok := value.(*scalarValue); ok {
 		sv.reset()
-		p.valuePool.Put(sv)
+		p.pools.Load().valuePool.Put(sv)
 	}
 }
 
@@ -139,7 +174,7 @@ func (p *objectPool) GetObject() IObject {
 
 	atomic.AddInt64(&p.stats.currentInUse, 1)
 
-	if obj := p.objectPool.Get(); obj != nil {
+	if obj := p.acquire().objectPool.Get(); obj != nil {
 		atomic.AddInt64(&p.stats.totalReused, 1)
 		if ov, ok := obj.(*objectValue); ok {
 			ov.reset()
@@ -166,7 +201,7 @@ func (p *objectPool) PutObject(obj IObject) {
 
 	// 清空对象并放回池中
 	obj.Clear()
-	p.objectPool.Put(obj)
+	p.pools.Load().objectPool.Put(obj)
 }
 
 // GetArray 从池中获取数组
@@ -178,7 +213,7 @@ func (p *objectPool) GetArray() IArray {
 
 	atomic.AddInt64(&p.stats.currentInUse, 1)
 
-	if arr := p.arrayPool.Get(); arr != nil {
+	if arr := p.acquire().arrayPool.Get(); arr != nil {
 		atomic.AddInt64(&p.stats.totalReused, 1)
 		if av, ok := arr.(*arrayValue); ok {
 			av.reset()
@@ -205,7 +240,7 @@ func (p *objectPool) PutArray(arr IArray) {
 
 	// 清空数组并放回池中
 	arr.Clear()
-	p.arrayPool.Put(arr)
+	p.pools.Load().arrayPool.Put(arr)
 }
 
 // GetStats 获取池统计信息
@@ -225,6 +260,9 @@ func (p *objectPool) GetStats() *PoolStats {
 		TotalReused:    totalReused,
 		CurrentInUse:   currentInUse,
 		PoolHitRate:    hitRate,
+		WarmTarget:     atomic.LoadInt64(&p.stats.warmTarget),
+		WarmCompleted:  atomic.LoadInt64(&p.stats.warmCompleted),
+		Shrinks:        atomic.LoadInt64(&p.stats.shrinks),
 	}
 }
 
@@ -233,21 +271,91 @@ func (p *objectPool) GetStats() *PoolStats {
 func (sv *scalarValue) reset() {
 	sv.valueType = NullValueType
 	sv.rawData = nil
+	sv.clearMeta()
 }
 
-// cleanupRoutine 定期清理协程
-// cleanupRoutine is the periodic cleanup routine
+// cleanupRoutine 定期清理协程，直到Close
+// cleanupRoutine is the periodic cleanup routine, running until Close
 func (p *objectPool) cleanupRoutine(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		// 这里可以添加清理逻辑，比如释放长时间未使用的对象
-		// 由于sync.Pool会自动进行GC清理，这里主要用于统计信息重置
-		p.resetStatsIfNeeded()
+	idleSince := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			idleSince = p.shrinkIfIdle(now, idleSince)
+			p.resetStatsIfNeeded()
+		case <-p.stopCleanup:
+			return
+		}
 	}
 }
 
+// shrinkIfIdle 检查并清除使用标记，自idleSince起没有Get且超过MaxIdleTime时丢弃池中的对象，使其可以被回收；
+// 返回新的空闲起点
+// shrinkIfIdle tests and clears the used flag, dropping the entries of the pool when there was no Get since
+// idleSince for longer than MaxIdleTime so they can be reclaimed; it returns the new start of the idle period
+func (p *objectPool) shrinkIfIdle(now, idleSince time.Time) time.Time {
+	if p.used.Swap(false) {
+		return now
+	}
+	if now.Sub(idleSince) < p.maxIdleTime {
+		return idleSince
+	}
+	p.pools.Store(p.newPoolSet())
+	atomic.AddInt64(&p.stats.shrinks, 1)
+	return now
+}
+
+// Close 停止清理协程，可以多次调用；关闭后池仍可使用，只是不再收缩
+// Close stops the cleanup goroutine and may be called more than once; the pool remains usable after
+// closing, it just no longer shrinks
+//
+// 短期创建大量池的程序（例如测试）应在用完后调用Close，否则每个池的清理协程会一直存在
+// Programs creating many short-lived pools (tests for example) should call Close when done, otherwise the
+// cleanup goroutine of every pool lives forever
+//
+// 示例 Example:
+//
+//	pool := xyJson.NewObjectPool()
+//	defer pool.Close()
+func (p *objectPool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stopCleanup)
+	})
+	return nil
+}
+
+// acquire 标记池被使用并返回当前池集合，标记已设置时只读取，避免争用缓存行
+// acquire marks the pool as used and returns the current set of pools, only reading the flag when it is
+// already set to avoid contending on the cache line
+func (p *objectPool) acquire() *poolSet {
+	if !p.used.Load() {
+		p.used.Store(true)
+	}
+	return p.pools.Load()
+}
+
+// newPoolSet 创建分配时计入统计的池集合
+// newPoolSet creates a set of pools that count their allocations in the statistics
+func (p *objectPool) newPoolSet() *poolSet {
+	set := &poolSet{}
+	set.valuePool.New = func() interface{} {
+		atomic.AddInt64(&p.stats.totalAllocated, 1)
+		return &scalarValue{}
+	}
+	set.objectPool.New = func() interface{} {
+		atomic.AddInt64(&p.stats.totalAllocated, 1)
+		return NewObject()
+	}
+	set.arrayPool.New = func() interface{} {
+		atomic.AddInt64(&p.stats.totalAllocated, 1)
+		return NewArray()
+	}
+	return set
+}
+
 // resetStatsIfNeeded 在需要时重置统计信息
 // resetStatsIfNeeded resets statistics when needed
 func (p *objectPool) resetStatsIfNeeded() {
@@ -276,48 +384,32 @@ func (p *objectPool) IsEnabled() bool {
 // Clear 清空对象池
 // Clear clears the object pool
 func (p *objectPool) Clear() {
-	// 创建新的sync.Pool实例来清空池
-	p.valuePool = sync.Pool{
-		New: func() interface{} {
-			atomic.AddInt64(&p.stats.totalAllocated, 1)
-			return &scalarValue{}
-		},
-	}
-
-	p.objectPool = sync.Pool{
-		New: func() interface{} {
-			atomic.AddInt64(&p.stats.totalAllocated, 1)
-			return NewObject()
-		},
-	}
-
-	p.arrayPool = sync.Pool{
-		New: func() interface{} {
-			atomic.AddInt64(&p.stats.totalAllocated, 1)
-			return NewArray()
-		},
-	}
+	// 替换为新的池集合来清空池
+	p.pools.Store(p.newPoolSet())
 
 	// 重置统计信息
 	atomic.StoreInt64(&p.stats.totalAllocated, 0)
 	atomic.StoreInt64(&p.stats.totalReused, 0)
 	atomic.StoreInt64(&p.stats.currentInUse, 0)
+	atomic.StoreInt64(&p.stats.warmTarget, 0)
+	atomic.StoreInt64(&p.stats.warmCompleted, 0)
+	atomic.StoreInt64(&p.stats.shrinks, 0)
 }
 
-// 全局默认对象池实例
-// Global default object pool instance
-var defaultPool IObjectPool = NewObjectPool()
-
-// GetDefaultPool 获取默认对象池
-// GetDefaultPool gets the default object pool
+// GetDefaultPool 获取默认工厂使用的对象池，默认工厂不是NewValueFactory系列创建时返回nil
+// GetDefaultPool gets the object pool used by the default factory, nil when the default factory was not
+// created by the NewValueFactory family
 func GetDefaultPool() IObjectPool {
-	return defaultPool
+	return currentDefaults.Load().pool
 }
 
-// SetDefaultPool 设置默认对象池
-// SetDefaultPool sets the default object pool
+// SetDefaultPool 设置默认对象池，默认工厂随之重建，见Defaults.WithPool；pool为nil时不做任何事
+// SetDefaultPool sets the default object pool, rebuilding the default factory with it, see Defaults.WithPool;
+// nothing happens when pool is nil
 func SetDefaultPool(pool IObjectPool) {
 	if pool != nil {
-		defaultPool = pool
+		updateDefaults(func(d *Defaults) *Defaults {
+			return d.WithPool(pool)
+		})
 	}
 }
@@ -6,30 +6,177 @@ import (
 	"time"
 )
 
+// objectEntry 内联存储的键值对
+// objectEntry is a key-value pair stored inline
+type objectEntry struct {
+	key   string
+	value IValue
+}
+
 // objectValue JSON对象实现
 // objectValue implements the IObject interface
+//
+// 不超过SmallObjectSize个键时键值对内联存放在small中，避免为大量小对象分配map；
+// 超过后一次性溢出到data，之后不再回到内联存储，直到Clear
+// Up to SmallObjectSize keys are stored inline in small, avoiding a map allocation for the many tiny objects;
+// beyond that they spill to data once and stay there until Clear
 type objectValue struct {
-	data map[string]IValue
-	mu   sync.RWMutex
+	valueMeta
+	jsonCache
+	small [SmallObjectSize]objectEntry
+	n     int
+	data  map[string]IValue
+	mu    sync.RWMutex
+
+	// capacity 创建data时预留的容量，用于避免重复扩容
+	// capacity is the capacity data was created with, used to avoid regrowing
+	capacity int
 }
 
 // NewObject 创建新的JSON对象
 // NewObject creates a new JSON object
 func NewObject() IObject {
-	return &objectValue{
-		data: make(map[string]IValue, DefaultMapCapacity),
-	}
+	return &objectValue{}
 }
 
-// NewObjectWithCapacity 创建指定容量的JSON对象
-// NewObjectWithCapacity creates a JSON object with specified capacity
+// NewObjectWithCapacity 创建指定容量的JSON对象，容量超过SmallObjectSize时直接使用map
+// NewObjectWithCapacity creates a JSON object with specified capacity, using a map directly when the capacity
+// exceeds SmallObjectSize
 func NewObjectWithCapacity(capacity int) IObject {
-	if capacity <= 0 {
-		capacity = DefaultMapCapacity
+	if capacity <= SmallObjectSize {
+		return &objectValue{}
 	}
 	return &objectValue{
-		data: make(map[string]IValue, capacity),
+		data:     make(map[string]IValue, capacity),
+		capacity: capacity,
+	}
+}
+
+// lookup 查找键，调用方需持有锁
+// lookup looks up a key, the caller must hold the lock
+func (ov *objectValue) lookup(key string) (IValue, bool) {
+	if ov.data != nil {
+		value, exists := ov.data[key]
+		return value, exists
+	}
+	for i := 0; i < ov.n; i++ {
+		if ov.small[i].key == key {
+			return ov.small[i].value, true
+		}
+	}
+	return nil, false
+}
+
+// store 设置键值对，内联存储已满时溢出到map，调用方需持有写锁
+// store sets a key-value pair, spilling to a map when the inline storage is full; the caller must hold the
+// write lock
+func (ov *objectValue) store(key string, value IValue) {
+	ov.touch()
+	if ov.data != nil {
+		ov.data[key] = value
+		return
+	}
+	for i := 0; i < ov.n; i++ {
+		if ov.small[i].key == key {
+			ov.small[i].value = value
+			return
+		}
+	}
+	if ov.n < SmallObjectSize {
+		ov.small[ov.n] = objectEntry{key: key, value: value}
+		ov.n++
+		return
+	}
+
+	ov.spill(DefaultMapCapacity)
+	ov.data[key] = value
+}
+
+// spill 将键值对移到容量至少为capacity的map中，调用方需持有写锁
+// spill moves the key-value pairs into a map with at least the given capacity, the caller must hold the
+// write lock
+func (ov *objectValue) spill(capacity int) {
+	if capacity < ov.count() {
+		capacity = ov.count()
 	}
+	data := make(map[string]IValue, capacity)
+	ov.each(func(key string, value IValue) {
+		data[key] = value
+	})
+	ov.data = data
+	ov.capacity = capacity
+	ov.small = [SmallObjectSize]objectEntry{}
+	ov.n = 0
+}
+
+// remove 删除键，调用方需持有写锁
+// remove deletes a key, the caller must hold the write lock
+func (ov *objectValue) remove(key string) bool {
+	if ov.data != nil {
+		if _, exists := ov.data[key]; exists {
+			delete(ov.data, key)
+			ov.touch()
+			return true
+		}
+		return false
+	}
+	for i := 0; i < ov.n; i++ {
+		if ov.small[i].key == key {
+			// 用最后一个条目填补空位，键的顺序由Keys排序决定
+			// Fill the gap with the last entry, key order is decided by the sort in Keys
+			ov.n--
+			ov.small[i] = ov.small[ov.n]
+			ov.small[ov.n] = objectEntry{}
+			ov.touch()
+			return true
+		}
+	}
+	return false
+}
+
+// count 返回键数，调用方需持有锁
+// count returns the number of keys, the caller must hold the lock
+func (ov *objectValue) count() int {
+	if ov.data != nil {
+		return len(ov.data)
+	}
+	return ov.n
+}
+
+// each 按存储顺序遍历键值对，调用方需持有锁
+// each iterates over the key-value pairs in storage order, the caller must hold the lock
+func (ov *objectValue) each(fn func(key string, value IValue)) {
+	if ov.data != nil {
+		for key, value := range ov.data {
+			fn(key, value)
+		}
+		return
+	}
+	for i := 0; i < ov.n; i++ {
+		fn(ov.small[i].key, ov.small[i].value)
+	}
+}
+
+// sortedKeys 返回排序后的键，调用方需持有锁
+// sortedKeys returns the sorted keys, the caller must hold the lock
+func (ov *objectValue) sortedKeys() []string {
+	keys := make([]string, 0, ov.count())
+	ov.each(func(key string, _ IValue) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedEntries 返回按键排序的键值对副本，调用方需持有锁
+// sortedEntries returns a copy of the pairs sorted by key, the caller must hold the lock
+func (ov *objectValue) sortedEntries() []objectEntry {
+	entries := make([]objectEntry, 0, ov.count())
+	ov.each(func(key string, value IValue) {
+		entries = append(entries, objectEntry{key: key, value: value})
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+	return entries
 }
 
 // Type 返回值的类型
@@ -44,10 +191,10 @@ func (ov *objectValue) Raw() interface{} {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	result := make(map[string]interface{}, len(ov.data))
-	for key, value := range ov.data {
+	result := make(map[string]interface{}, ov.count())
+	ov.each(func(key string, value IValue) {
 		result[key] = value.Raw()
-	}
+	})
 	return result
 }
 
@@ -70,10 +217,11 @@ func (ov *objectValue) Clone() IValue {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	newObj := NewObjectWithCapacity(len(ov.data))
-	for key, value := range ov.data {
-		newObj.Set(key, value.Clone())
-	}
+	newObj := NewObjectWithCapacity(ov.count()).(*objectValue)
+	ov.each(func(key string, value IValue) {
+		newObj.store(key, value.Clone())
+	})
+	ov.copyMetaTo(&newObj.valueMeta)
 	return newObj
 }
 
@@ -92,18 +240,22 @@ func (ov *objectValue) Equals(other IValue) bool {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	if ov.Size() != otherObj.Size() {
+	if ov.count() != otherObj.Size() {
 		return false
 	}
 
-	for key, value := range ov.data {
+	equal := true
+	ov.each(func(key string, value IValue) {
+		if !equal {
+			return
+		}
 		otherValue := otherObj.Get(key)
 		if otherValue == nil || !value.Equals(otherValue) {
-			return false
+			equal = false
 		}
-	}
+	})
 
-	return true
+	return equal
 }
 
 // Get 根据键名获取值
@@ -112,7 +264,8 @@ func (ov *objectValue) Get(key string) IValue {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	return ov.data[key]
+	value, _ := ov.lookup(key)
+	return value
 }
 
 // Set 设置键值对
@@ -141,7 +294,7 @@ func (ov *objectValue) Set(key string, value interface{}) error {
 	ov.mu.Lock()
 	defer ov.mu.Unlock()
 
-	ov.data[key] = jsonValue
+	ov.store(key, jsonValue)
 	return nil
 }
 
@@ -151,11 +304,7 @@ func (ov *objectValue) Delete(key string) bool {
 	ov.mu.Lock()
 	defer ov.mu.Unlock()
 
-	if _, exists := ov.data[key]; exists {
-		delete(ov.data, key)
-		return true
-	}
-	return false
+	return ov.remove(key)
 }
 
 // Has 检查是否包含指定键
@@ -164,7 +313,7 @@ func (ov *objectValue) Has(key string) bool {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	_, exists := ov.data[key]
+	_, exists := ov.lookup(key)
 	return exists
 }
 
@@ -174,14 +323,8 @@ func (ov *objectValue) Keys() []string {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	keys := make([]string, 0, len(ov.data))
-	for key := range ov.data {
-		keys = append(keys, key)
-	}
-
 	// 对键名进行排序，确保结果的一致性
-	sort.Strings(keys)
-	return keys
+	return ov.sortedKeys()
 }
 
 // Size 返回键值对数量
@@ -190,7 +333,7 @@ func (ov *objectValue) Size() int {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
-	return len(ov.data)
+	return ov.count()
 }
 
 // Clear 清空所有键值对
@@ -199,8 +342,54 @@ func (ov *objectValue) Clear() {
 	ov.mu.Lock()
 	defer ov.mu.Unlock()
 
-	// 创建新的map而不是逐个删除，更高效
-	ov.data = make(map[string]IValue, DefaultMapCapacity)
+	// 丢弃map并回到内联存储
+	ov.touch()
+	ov.data = nil
+	ov.capacity = 0
+	ov.small = [SmallObjectSize]objectEntry{}
+	ov.n = 0
+}
+
+// SetAll 一次性设置多个键值对，只扩容一次；任一键为空时不做任何修改并返回错误，nil值设置为null
+// SetAll sets multiple key-value pairs at once, growing only once; nothing is changed and an error is
+// returned when any key is empty, nil values are set to null
+func (ov *objectValue) SetAll(values map[string]IValue) error {
+	if _, exists := values[""]; exists {
+		return NewInvalidOperationError("set object key", "key cannot be empty")
+	}
+
+	ov.mu.Lock()
+	defer ov.mu.Unlock()
+
+	ov.ensureCapacity(ov.count() + len(values))
+	for key, value := range values {
+		if value == nil {
+			value = &scalarValue{valueType: NullValueType, rawData: nil}
+		}
+		ov.store(key, value)
+	}
+	return nil
+}
+
+// EnsureCapacity 确保对象至少能容纳capacity个键而无需扩容
+// EnsureCapacity ensures the object can hold at least capacity keys without growing
+func (ov *objectValue) EnsureCapacity(capacity int) {
+	ov.mu.Lock()
+	defer ov.mu.Unlock()
+
+	ov.ensureCapacity(capacity)
+}
+
+// ensureCapacity 容量超过内联存储或已有map的容量时换用更大的map，调用方需持有写锁
+// ensureCapacity switches to a larger map when the capacity exceeds the inline storage or the current map,
+// the caller must hold the write lock
+func (ov *objectValue) ensureCapacity(capacity int) {
+	// map至少能容纳已有的键，无需为此重建
+	// A map can hold at least the keys it already has, no need to rebuild for that
+	if capacity <= SmallObjectSize || (ov.data != nil && (capacity <= ov.capacity || capacity <= len(ov.data))) {
+		return
+	}
+	ov.spill(capacity)
 }
 
 // Range 遍历所有键值对
@@ -210,27 +399,42 @@ func (ov *objectValue) Range(fn func(key string, value IValue) bool) {
 		return
 	}
 
+	// 在一次读锁内复制键值对的快照，遍历时不持有锁，fn可以修改对象
+	// Copy a snapshot of the pairs under a single read lock and iterate without the lock, so fn may modify
+	// the object
 	ov.mu.RLock()
-	// 创建键的副本以避免在遍历时持有锁
-	keys := make([]string, 0, len(ov.data))
-	for key := range ov.data {
-		keys = append(keys, key)
-	}
+	entries := ov.sortedEntries()
 	ov.mu.RUnlock()
 
-	// 对键进行排序以确保遍历顺序的一致性
-	sort.Strings(keys)
+	for _, entry := range entries {
+		if !fn(entry.key, entry.value) {
+			break
+		}
+	}
+}
 
-	for _, key := range keys {
-		ov.mu.RLock()
-		value, exists := ov.data[key]
-		ov.mu.RUnlock()
+// UnsafeRange 持有读锁以存储顺序遍历键值对，fn不能修改对象
+// UnsafeRange iterates over the pairs in storage order holding the read lock, fn must not modify the object
+func (ov *objectValue) UnsafeRange(fn func(key string, value IValue) bool) {
+	if fn == nil {
+		return
+	}
 
-		if exists {
+	ov.mu.RLock()
+	defer ov.mu.RUnlock()
+
+	if ov.data != nil {
+		for key, value := range ov.data {
 			if !fn(key, value) {
-				break
+				return
 			}
 		}
+		return
+	}
+	for i := 0; i < ov.n; i++ {
+		if !fn(ov.small[i].key, ov.small[i].value) {
+			return
+		}
 	}
 }
 
@@ -240,10 +444,14 @@ func (ov *objectValue) reset() {
 	ov.mu.Lock()
 	defer ov.mu.Unlock()
 
-	// 清空数据但保留底层map的容量
+	// 清空数据但保留已溢出的map的容量
+	ov.touch()
 	for key := range ov.data {
 		delete(ov.data, key)
 	}
+	ov.small = [SmallObjectSize]objectEntry{}
+	ov.n = 0
+	ov.clearMeta()
 }
 
 // GetSorted 按键名排序返回所有键值对
@@ -255,24 +463,20 @@ func (ov *objectValue) GetSorted() []struct {
 	ov.mu.RLock()
 	defer ov.mu.RUnlock()
 
+	keys := ov.sortedKeys()
 	result := make([]struct {
 		Key   string
 		Value IValue
-	}, 0, len(ov.data))
-
-	keys := make([]string, 0, len(ov.data))
-	for key := range ov.data {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
+	}, 0, len(keys))
 
 	for _, key := range keys {
+		value, _ := ov.lookup(key)
 		result = append(result, struct {
 			Key   string
 			Value IValue
 		}{
 			Key:   key,
-			Value: ov.data[key],
+			Value: value,
 		})
 	}
 
@@ -347,3 +551,27 @@ func (ov *objectValue) AsObject() IObject {
 func (ov *objectValue) AsArray() IArray {
 	return nil
 }
+
+// AsInt64OK 对象类型总是返回false
+// AsInt64OK always returns false for object type
+func (ov *objectValue) AsInt64OK() (int64, bool) {
+	return 0, false
+}
+
+// AsFloat64OK 对象类型总是返回false
+// AsFloat64OK always returns false for object type
+func (ov *objectValue) AsFloat64OK() (float64, bool) {
+	return 0, false
+}
+
+// AsStringOK 对象类型总是返回false
+// AsStringOK always returns false for object type
+func (ov *objectValue) AsStringOK() (string, bool) {
+	return "", false
+}
+
+// AsBoolOK 对象类型总是返回false
+// AsBoolOK always returns false for object type
+func (ov *objectValue) AsBoolOK() (bool, bool) {
+	return false, false
+}
@@ -0,0 +1,219 @@
+package xyJson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ObjectWriter 流式JSON对象写入器
+// ObjectWriter is a streaming JSON object writer
+//
+// 字段在写入时立即序列化到底层io.Writer，无需先在内存中构建完整文档；
+// 每一层对象都会检查重复键。写入器不是线程安全的，出错后所有后续操作都返回同一个错误
+// Fields are serialized to the underlying io.Writer as they are written, without building the whole document
+// in memory first; duplicate keys are rejected at every object level. The writer is not thread-safe,
+// and after an error all subsequent operations return the same error
+//
+// 使用示例 Usage Example:
+//
+//	ow := xyJson.NewObjectWriter(w)
+//	ow.Begin()
+//	ow.WriteField("status", xyJson.CreateString("ok"))
+//	ow.BeginObject("data")
+//	ow.WriteFieldJSON("items", cachedItemsJSON)
+//	ow.End()
+//	ow.End()
+//	err := ow.Close()
+type ObjectWriter struct {
+	writer     *bufio.Writer
+	serializer *serializer
+	frames     []*objectWriterFrame
+	buf        bytes.Buffer
+	started    bool
+	err        error
+}
+
+// objectWriterFrame 一层正在写入的对象
+// objectWriterFrame is one object level being written
+type objectWriterFrame struct {
+	keys map[string]struct{}
+}
+
+// NewObjectWriter 创建流式对象写入器
+// NewObjectWriter creates a streaming object writer
+func NewObjectWriter(w io.Writer) *ObjectWriter {
+	return &ObjectWriter{
+		writer:     bufio.NewWriterSize(w, DefaultParserBufferSize),
+		serializer: NewSerializer().(*serializer),
+	}
+}
+
+// Begin 开始写入顶层对象
+// Begin starts the top-level object
+func (ow *ObjectWriter) Begin() error {
+	if ow.err != nil {
+		return ow.err
+	}
+	if ow.started {
+		return ow.fail(NewInvalidOperationError("begin", "top-level object already started"))
+	}
+	ow.started = true
+	ow.push()
+	return ow.write([]byte{'{'})
+}
+
+// BeginObject 在当前对象中开始写入名为key的嵌套对象
+// BeginObject starts a nested object named key in the current object
+func (ow *ObjectWriter) BeginObject(key string) error {
+	if err := ow.writeKey(key); err != nil {
+		return err
+	}
+	ow.push()
+	return ow.write([]byte{'{'})
+}
+
+// WriteField 写入一个字段
+// WriteField writes a field
+func (ow *ObjectWriter) WriteField(key string, value IValue) error {
+	if value == nil {
+		value = CreateNull()
+	}
+	if err := ow.writeKey(key); err != nil {
+		return err
+	}
+
+	ow.buf.Reset()
+	if err := ow.serializer.serializeValue(value, &ow.buf, len(ow.frames), make(map[IValue]bool)); err != nil {
+		return ow.fail(err)
+	}
+	return ow.write(ow.buf.Bytes())
+}
+
+// WriteFieldJSON 写入一个值为原始JSON的字段，原始JSON会先被校验
+// WriteFieldJSON writes a field whose value is raw JSON, the raw JSON is validated first
+//
+// 校验只扫描原始JSON而不构建值树，写入大的预序列化片段不会产生额外分配
+// Validation only scans the raw JSON without building a value tree, so writing large pre-serialized fragments
+// does not allocate
+func (ow *ObjectWriter) WriteFieldJSON(key string, raw []byte) error {
+	if ow.err != nil {
+		return ow.err
+	}
+	raw = bytes.TrimSpace(raw)
+	if !json.Valid(raw) {
+		return ow.fail(NewInvalidJSONError(fmt.Sprintf("invalid raw JSON for field '%s'", key), nil))
+	}
+	if err := ow.writeKey(key); err != nil {
+		return err
+	}
+	return ow.write(raw)
+}
+
+// End 结束当前对象，结束顶层对象时刷新缓冲区
+// End ends the current object, flushing the buffer when the top-level object ends
+func (ow *ObjectWriter) End() error {
+	if ow.err != nil {
+		return ow.err
+	}
+	if len(ow.frames) == 0 {
+		return ow.fail(NewInvalidOperationError("end", "no open object"))
+	}
+	ow.frames = ow.frames[:len(ow.frames)-1]
+	if err := ow.write([]byte{'}'}); err != nil {
+		return err
+	}
+	if len(ow.frames) == 0 {
+		return ow.Flush()
+	}
+	return nil
+}
+
+// Depth 返回当前打开的对象层数
+// Depth returns the number of currently open objects
+func (ow *ObjectWriter) Depth() int {
+	return len(ow.frames)
+}
+
+// Flush 将缓冲的数据写入底层io.Writer
+// Flush writes buffered data to the underlying io.Writer
+func (ow *ObjectWriter) Flush() error {
+	if ow.err != nil {
+		return ow.err
+	}
+	if err := ow.writer.Flush(); err != nil {
+		return ow.fail(err)
+	}
+	return nil
+}
+
+// Close 检查所有对象都已结束并刷新缓冲区
+// Close checks that all objects have been ended and flushes the buffer
+func (ow *ObjectWriter) Close() error {
+	if ow.err != nil {
+		return ow.err
+	}
+	if !ow.started {
+		return ow.fail(NewInvalidOperationError("close", "top-level object was never started"))
+	}
+	if len(ow.frames) > 0 {
+		return ow.fail(NewInvalidOperationError("close", fmt.Sprintf("%d object(s) not ended", len(ow.frames))))
+	}
+	return ow.Flush()
+}
+
+// writeKey 写入分隔符和键，并检查重复键
+// writeKey writes the separator and the key, checking for duplicate keys
+func (ow *ObjectWriter) writeKey(key string) error {
+	if ow.err != nil {
+		return ow.err
+	}
+	if len(ow.frames) == 0 {
+		return ow.fail(NewInvalidOperationError("write field", "no open object"))
+	}
+	if len(ow.frames) >= MaxNestingDepth {
+		return ow.fail(NewMaxDepthExceededError(MaxNestingDepth))
+	}
+
+	frame := ow.frames[len(ow.frames)-1]
+	if _, exists := frame.keys[key]; exists {
+		return ow.fail(NewInvalidOperationError("write field", fmt.Sprintf("duplicate key '%s'", key)))
+	}
+
+	ow.buf.Reset()
+	if len(frame.keys) > 0 {
+		ow.buf.WriteByte(',')
+	}
+	frame.keys[key] = struct{}{}
+	if err := ow.serializer.serializeString(key, &ow.buf); err != nil {
+		return ow.fail(err)
+	}
+	ow.buf.WriteByte(':')
+	return ow.write(ow.buf.Bytes())
+}
+
+// push 压入新的对象层
+// push pushes a new object level
+func (ow *ObjectWriter) push() {
+	ow.frames = append(ow.frames, &objectWriterFrame{keys: make(map[string]struct{})})
+}
+
+// write 写入字节并记录错误
+// write writes bytes and records the error
+func (ow *ObjectWriter) write(data []byte) error {
+	if _, err := ow.writer.Write(data); err != nil {
+		return ow.fail(err)
+	}
+	return nil
+}
+
+// fail 记录第一个错误
+// fail records the first error
+func (ow *ObjectWriter) fail(err error) error {
+	if ow.err == nil {
+		ow.err = err
+	}
+	return ow.err
+}
@@ -0,0 +1,396 @@
+// Package openapi 加载OpenAPI 3文档，并使用xyJson的JSON Schema引擎校验请求和响应体
+// Package openapi loads OpenAPI 3 documents and validates request and response bodies with the xyJson JSON
+// Schema engine
+//
+// 文档中的$ref必须指向同一文档内部；OpenAPI 3.0的nullable会被转换为允许null的type。
+// 只校验消息体，参数和安全要求不在本包的范围内
+// $refs in the document must point into the same document; the OpenAPI 3.0 nullable keyword is turned into a
+// type that also allows null. Only message bodies are validated, parameters and security requirements are out
+// of scope for this package
+//
+// 示例 Example:
+//
+//	spec, err := openapi.LoadFile("api.json")
+//	if err != nil {
+//		return err
+//	}
+//	body, _ := io.ReadAll(r.Body)
+//	if err := spec.ValidateRequest(r.Method, r.URL.Path, r.Header.Get("Content-Type"), body); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+package openapi
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+// methods 路径项中可以出现的HTTP方法，按OpenAPI规范的顺序排列
+// methods are the HTTP methods a path item may hold, in the order of the OpenAPI specification
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document 加载后的OpenAPI文档，创建后不可修改，可以被多个goroutine并发使用
+// Document is a loaded OpenAPI document, immutable once loaded and safe for concurrent use
+type Document struct {
+	root       xyJson.IValue
+	version    string
+	operations []*Operation
+	byID       map[string]*Operation
+	schemas    map[string]*xyJson.Schema
+}
+
+// Load 从IValue加载OpenAPI 3文档，编译所有操作的请求体和响应体Schema
+// Load loads an OpenAPI 3 document from an IValue, compiling the request and response body schemas of every
+// operation
+//
+// 文档在加载时被深拷贝，之后修改原文档不会影响Document
+// The document is deep-copied on load, later changes to the original do not affect the Document
+func Load(doc xyJson.IValue) (*Document, error) {
+	if doc == nil {
+		return nil, xyJson.NewNullPointerError("openapi document cannot be nil")
+	}
+	obj := doc.AsObject()
+	if obj == nil {
+		return nil, invalid("document must be an object")
+	}
+	version := ""
+	if v := obj.Get("openapi"); v != nil {
+		version = v.AsString()
+	}
+	if !strings.HasPrefix(version, "3.") {
+		return nil, invalid("unsupported openapi version " + strconv.Quote(version))
+	}
+
+	root := doc.Clone()
+	if !strings.HasPrefix(version, "3.1") {
+		convertNullable(root, 0)
+	}
+
+	d := &Document{
+		root:    root,
+		version: version,
+		byID:    make(map[string]*Operation),
+		schemas: make(map[string]*xyJson.Schema),
+	}
+	if err := d.loadSchemas(); err != nil {
+		return nil, err
+	}
+	if err := d.loadOperations(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// LoadBytes 从JSON字节数组加载OpenAPI 3文档
+// LoadBytes loads an OpenAPI 3 document from a JSON byte array
+func LoadBytes(data []byte) (*Document, error) {
+	doc, err := xyJson.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Load(doc)
+}
+
+// LoadFile 从JSON文件加载OpenAPI 3文档
+// LoadFile loads an OpenAPI 3 document from a JSON file
+func LoadFile(filename string) (*Document, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return LoadBytes(data)
+}
+
+// MustLoad 加载OpenAPI 3文档，失败时panic
+// MustLoad loads an OpenAPI 3 document, panics on failure
+func MustLoad(doc xyJson.IValue) *Document {
+	d, err := Load(doc)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Version 返回文档的openapi版本
+// Version returns the openapi version of the document
+func (d *Document) Version() string {
+	return d.version
+}
+
+// Operations 返回按路径和方法排序的所有操作
+// Operations returns every operation, sorted by path and method
+func (d *Document) Operations() []*Operation {
+	return append([]*Operation(nil), d.operations...)
+}
+
+// OperationByID 按operationId查找操作
+// OperationByID looks up an operation by its operationId
+func (d *Document) OperationByID(id string) (*Operation, bool) {
+	op, ok := d.byID[id]
+	return op, ok
+}
+
+// FindOperation 查找匹配方法和请求路径的操作，并返回路径参数
+// FindOperation finds the operation matching the method and request path, returning the path parameters
+//
+// 路径相对于服务器URL，查询字符串会被忽略。多个模板匹配时优先选择字面段更多的路径，例如/pets/mine优先于/pets/{id}
+// The path is relative to the server URL and the query string is ignored. When several templates match, the
+// path with more literal segments wins, so /pets/mine is preferred over /pets/{id}
+func (d *Document) FindOperation(method, path string) (*Operation, map[string]string, error) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	method = strings.ToLower(method)
+	segments := splitPath(path)
+
+	var best *Operation
+	var bestParams map[string]string
+	bestLiterals := -1
+	pathMatched := false
+	for _, op := range d.operations {
+		params, literals, ok := op.match(segments)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if op.method == method && literals > bestLiterals {
+			best, bestParams, bestLiterals = op, params, literals
+		}
+	}
+
+	if best == nil {
+		if pathMatched {
+			return nil, nil, invalid("method " + strings.ToUpper(method) + " not allowed for " + path)
+		}
+		return nil, nil, xyJson.NewJSONError(xyJson.ErrPathNotFound, "no operation matches "+path, nil)
+	}
+	return best, bestParams, nil
+}
+
+// Schema 返回components/schemas中指定名称的Schema
+// Schema returns the schema with the given name in components/schemas
+func (d *Document) Schema(name string) (*xyJson.Schema, bool) {
+	schema, ok := d.schemas[name]
+	return schema, ok
+}
+
+// SchemaNames 返回components/schemas中排序后的Schema名称
+// SchemaNames returns the sorted schema names in components/schemas
+func (d *Document) SchemaNames() []string {
+	names := make([]string, 0, len(d.schemas))
+	for name := range d.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateRequest 查找操作并按Content-Type校验请求体，body为空表示没有请求体
+// ValidateRequest finds the operation and validates the request body for the Content-Type, an empty body means
+// no request body
+func (d *Document) ValidateRequest(method, path, contentType string, body []byte) error {
+	op, _, err := d.FindOperation(method, path)
+	if err != nil {
+		return err
+	}
+	value, err := parseBody(body)
+	if err != nil {
+		return err
+	}
+	return op.ValidateRequest(contentType, value)
+}
+
+// ValidateResponse 查找操作并按状态码和Content-Type校验响应体，body为空表示没有响应体
+// ValidateResponse finds the operation and validates the response body for the status code and Content-Type, an
+// empty body means no response body
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	op, _, err := d.FindOperation(method, path)
+	if err != nil {
+		return err
+	}
+	value, err := parseBody(body)
+	if err != nil {
+		return err
+	}
+	return op.ValidateResponse(status, contentType, value)
+}
+
+// loadSchemas 编译components/schemas中的所有Schema
+// loadSchemas compiles every schema in components/schemas
+func (d *Document) loadSchemas() error {
+	components := d.root.AsObject().Get("components")
+	if components == nil || components.AsObject() == nil {
+		return nil
+	}
+	schemas := components.AsObject().Get("schemas")
+	if schemas == nil || schemas.AsObject() == nil {
+		return nil
+	}
+
+	var err error
+	schemas.AsObject().Range(func(name string, _ xyJson.IValue) bool {
+		var schema *xyJson.Schema
+		if schema, err = d.compile("#/components/schemas/" + escapePointer(name)); err != nil {
+			return false
+		}
+		d.schemas[name] = schema
+		return true
+	})
+	return err
+}
+
+// loadOperations 读取paths中的所有操作
+// loadOperations reads every operation in paths
+func (d *Document) loadOperations() error {
+	paths := d.root.AsObject().Get("paths")
+	if paths == nil {
+		return nil
+	}
+	if paths.AsObject() == nil {
+		return invalid("paths must be an object")
+	}
+
+	templates := paths.AsObject().Keys()
+	sort.Strings(templates)
+	for _, template := range templates {
+		item := paths.AsObject().Get(template).AsObject()
+		if item == nil {
+			return invalid("path item " + template + " must be an object")
+		}
+		for _, method := range methods {
+			node := item.Get(method)
+			if node == nil {
+				continue
+			}
+			location := "#/paths/" + escapePointer(template) + "/" + method
+			op, err := d.loadOperation(template, method, node, location)
+			if err != nil {
+				return err
+			}
+			d.operations = append(d.operations, op)
+			if op.id != "" {
+				if _, exists := d.byID[op.id]; exists {
+					return invalid("duplicate operationId " + op.id)
+				}
+				d.byID[op.id] = op
+			}
+		}
+	}
+	return nil
+}
+
+// compile 编译文档中pointer指向的Schema，Schema内的$ref相对于整个文档解析
+// compile compiles the schema pointer refers to, $refs inside it resolve against the whole document
+func (d *Document) compile(pointer string) (*xyJson.Schema, error) {
+	// 用只包含$ref的根引用目标Schema，同时保留文档的顶层成员供其它$ref解析
+	// A root holding only a $ref points at the target schema while keeping the top-level members of the
+	// document for other $refs to resolve against
+	wrapper := xyJson.CreateObject()
+	d.root.AsObject().Range(func(key string, value xyJson.IValue) bool {
+		wrapper.Set(key, value)
+		return true
+	})
+	wrapper.Set("$ref", xyJson.CreateString(pointer))
+	return xyJson.CompileSchema(wrapper)
+}
+
+// resolve 沿$ref找到文档中的节点，返回节点及其JSON Pointer位置
+// resolve follows $refs to a node in the document, returning the node and its JSON Pointer location
+func (d *Document) resolve(node xyJson.IValue, location string) (xyJson.IObject, string, error) {
+	for depth := 0; ; depth++ {
+		obj := node.AsObject()
+		if obj == nil {
+			return nil, "", invalid(location + " must be an object")
+		}
+		ref := obj.Get("$ref")
+		if ref == nil {
+			return obj, location, nil
+		}
+		if depth >= xyJson.MaxNestingDepth {
+			return nil, "", invalid("circular $ref at " + location)
+		}
+
+		location = ref.AsString()
+		if !strings.HasPrefix(location, "#/") {
+			return nil, "", invalid("only references into the same document are supported: " + location)
+		}
+		node = d.root
+		for _, token := range strings.Split(location[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			if node.AsObject() == nil {
+				node = nil
+				break
+			}
+			if node = node.AsObject().Get(token); node == nil {
+				break
+			}
+		}
+		if node == nil {
+			return nil, "", invalid("unresolvable $ref " + location)
+		}
+	}
+}
+
+// parseBody 解析消息体，空消息体返回nil
+// parseBody parses a message body, an empty body returns nil
+func parseBody(body []byte) (xyJson.IValue, error) {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, nil
+	}
+	return xyJson.Parse(body)
+}
+
+// convertNullable 将OpenAPI 3.0的nullable: true转换为同时允许null的type和enum
+// convertNullable turns the OpenAPI 3.0 nullable: true into a type and enum that also allow null
+func convertNullable(node xyJson.IValue, depth int) {
+	if depth > xyJson.MaxNestingDepth {
+		return
+	}
+	switch node.Type() {
+	case xyJson.ObjectValueType:
+		obj := node.AsObject()
+		if nullable := obj.Get("nullable"); nullable != nil && nullable.Type() == xyJson.BoolValueType && nullable.AsBool() {
+			if t := obj.Get("type"); t != nil && t.Type() == xyJson.StringValueType {
+				types := xyJson.CreateArray()
+				types.Append(t)
+				types.Append(xyJson.CreateString("null"))
+				obj.Set("type", types)
+			}
+			if enum := obj.Get("enum"); enum != nil && enum.AsArray() != nil {
+				enum.AsArray().Append(xyJson.CreateNull())
+			}
+		}
+		obj.Range(func(_ string, child xyJson.IValue) bool {
+			convertNullable(child, depth+1)
+			return true
+		})
+	case xyJson.ArrayValueType:
+		node.AsArray().Range(func(_ int, child xyJson.IValue) bool {
+			convertNullable(child, depth+1)
+			return true
+		})
+	}
+}
+
+// splitPath 将路径拆分为非空段
+// splitPath splits a path into non-empty segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// escapePointer 按JSON Pointer规则转义一个引用段
+// escapePointer escapes a reference token by the JSON Pointer rules
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// invalid 创建OpenAPI文档或请求无效的错误
+// invalid creates an error for an invalid OpenAPI document or request
+func invalid(message string) error {
+	return xyJson.NewInvalidOperationError("openapi", message)
+}
@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+// Operation OpenAPI文档中的一个操作，即路径模板和HTTP方法的组合
+// Operation is a single operation of an OpenAPI document, the combination of a path template and an HTTP method
+type Operation struct {
+	id       string
+	method   string
+	template string
+	segments []string
+
+	requestRequired bool
+	requestBodies   map[string]*xyJson.Schema
+	responses       map[string]map[string]*xyJson.Schema
+}
+
+// ID 返回operationId，未声明时为空字符串
+// ID returns the operationId, an empty string when not declared
+func (o *Operation) ID() string {
+	return o.id
+}
+
+// Method 返回大写的HTTP方法
+// Method returns the upper-case HTTP method
+func (o *Operation) Method() string {
+	return strings.ToUpper(o.method)
+}
+
+// Path 返回路径模板，例如/pets/{id}
+// Path returns the path template, such as /pets/{id}
+func (o *Operation) Path() string {
+	return o.template
+}
+
+// RequestSchema 返回Content-Type对应的请求体Schema，contentType为空时使用application/json
+// RequestSchema returns the request body schema for the Content-Type, application/json is used when contentType
+// is empty
+func (o *Operation) RequestSchema(contentType string) (*xyJson.Schema, bool) {
+	return selectMedia(o.requestBodies, contentType)
+}
+
+// ResponseSchema 返回状态码和Content-Type对应的响应体Schema，依次匹配精确状态码、nXX范围和default
+// ResponseSchema returns the response body schema for the status code and Content-Type, matching the exact
+// status, then the nXX range and then default
+func (o *Operation) ResponseSchema(status int, contentType string) (*xyJson.Schema, bool) {
+	content, ok := o.response(status)
+	if !ok {
+		return nil, false
+	}
+	return selectMedia(content, contentType)
+}
+
+// ValidateRequest 校验请求体，body为nil表示没有请求体
+// ValidateRequest validates a request body, a nil body means no request body
+//
+// 请求体是必需的却缺失、Content-Type未声明时返回错误；声明的媒体类型没有Schema时接受任何请求体。
+// 违反Schema时返回Code为ErrSchemaViolation的*JSONError
+// A missing required body and an undeclared Content-Type return an error; a declared media type without a
+// schema accepts any body. A schema violation returns a *JSONError with Code ErrSchemaViolation
+func (o *Operation) ValidateRequest(contentType string, body xyJson.IValue) error {
+	if body == nil {
+		if o.requestRequired {
+			return xyJson.NewSchemaViolationError("$", "request body is required").WithContext(o.describe("request"))
+		}
+		return nil
+	}
+	if o.requestBodies == nil {
+		return invalid(o.describe("request") + " does not accept a body")
+	}
+	schema, ok := selectMedia(o.requestBodies, contentType)
+	if !ok {
+		return invalid("content type " + mediaType(contentType) + " not declared for " + o.describe("request"))
+	}
+	return o.check(schema, body, "request")
+}
+
+// ValidateResponse 校验响应体，body为nil表示没有响应体
+// ValidateResponse validates a response body, a nil body means no response body
+//
+// 状态码未声明、响应体的Content-Type未声明时返回错误；没有content的响应只接受空响应体
+// An undeclared status code or Content-Type returns an error; a response without content only accepts an empty
+// body
+func (o *Operation) ValidateResponse(status int, contentType string, body xyJson.IValue) error {
+	content, ok := o.response(status)
+	if !ok {
+		return invalid("status " + strconv.Itoa(status) + " not declared for " + o.describe("response"))
+	}
+	if body == nil {
+		return nil
+	}
+	if content == nil {
+		return invalid(o.describe("response") + " with status " + strconv.Itoa(status) + " does not have a body")
+	}
+	schema, ok := selectMedia(content, contentType)
+	if !ok {
+		return invalid("content type " + mediaType(contentType) + " not declared for " + o.describe("response"))
+	}
+	return o.check(schema, body, "response "+strconv.Itoa(status))
+}
+
+// check 使用Schema校验消息体，为违反项补充操作信息；nil Schema接受任何消息体
+// check validates a body with the schema, adding the operation to violations; a nil schema accepts any body
+func (o *Operation) check(schema *xyJson.Schema, body xyJson.IValue, what string) error {
+	if schema == nil {
+		return nil
+	}
+	err := schema.Validate(body)
+	if je, ok := err.(*xyJson.JSONError); ok && je.Context == "" {
+		je.WithContext(o.describe(what))
+	}
+	return err
+}
+
+// describe 描述操作的请求或响应，例如request of POST /pets
+// describe describes the request or response of the operation, such as request of POST /pets
+func (o *Operation) describe(what string) string {
+	return what + " of " + o.Method() + " " + o.template
+}
+
+// response 依次按精确状态码、nXX范围和default查找响应
+// response looks up the response by the exact status, then the nXX range and then default
+func (o *Operation) response(status int) (map[string]*xyJson.Schema, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if content, ok := o.responses[key]; ok {
+			return content, true
+		}
+	}
+	return nil, false
+}
+
+// match 检查请求路径段是否匹配路径模板，返回路径参数和字面段数
+// match checks whether the request path segments match the path template, returning the path parameters and the
+// number of literal segments
+func (o *Operation) match(segments []string) (map[string]string, int, bool) {
+	if len(segments) != len(o.segments) {
+		return nil, 0, false
+	}
+	var params map[string]string
+	literals := 0
+	for i, pattern := range o.segments {
+		if name, ok := templateParam(pattern); ok {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+		if pattern != segments[i] {
+			return nil, 0, false
+		}
+		literals++
+	}
+	return params, literals, true
+}
+
+// templateParam 返回{name}形式的模板段中的参数名
+// templateParam returns the parameter name of a template segment of the form {name}
+func templateParam(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+// loadOperation 读取一个操作并编译其请求体和响应体Schema
+// loadOperation reads an operation and compiles its request and response body schemas
+func (d *Document) loadOperation(template, method string, node xyJson.IValue, location string) (*Operation, error) {
+	obj := node.AsObject()
+	if obj == nil {
+		return nil, invalid(location + " must be an object")
+	}
+
+	op := &Operation{
+		method:    method,
+		template:  template,
+		segments:  splitPath(template),
+		responses: make(map[string]map[string]*xyJson.Schema),
+	}
+	if id := obj.Get("operationId"); id != nil {
+		op.id = id.AsString()
+	}
+
+	if body := obj.Get("requestBody"); body != nil {
+		bodyObj, bodyLocation, err := d.resolve(body, location+"/requestBody")
+		if err != nil {
+			return nil, err
+		}
+		if required := bodyObj.Get("required"); required != nil {
+			op.requestRequired = required.AsBool()
+		}
+		if op.requestBodies, err = d.loadContent(bodyObj, bodyLocation); err != nil {
+			return nil, err
+		}
+		if op.requestBodies == nil {
+			op.requestBodies = map[string]*xyJson.Schema{}
+		}
+	}
+
+	if responses := obj.Get("responses"); responses != nil {
+		if responses.AsObject() == nil {
+			return nil, invalid(location + "/responses must be an object")
+		}
+		codes := responses.AsObject().Keys()
+		sort.Strings(codes)
+		for _, code := range codes {
+			responseObj, responseLocation, err := d.resolve(responses.AsObject().Get(code), location+"/responses/"+escapePointer(code))
+			if err != nil {
+				return nil, err
+			}
+			content, err := d.loadContent(responseObj, responseLocation)
+			if err != nil {
+				return nil, err
+			}
+			if code != "default" {
+				code = strings.ToUpper(code)
+			}
+			op.responses[code] = content
+		}
+	}
+	return op, nil
+}
+
+// loadContent 编译content中每个媒体类型的Schema，没有content时返回nil
+// loadContent compiles the schema of every media type in content, nil when there is no content
+func (d *Document) loadContent(obj xyJson.IObject, location string) (map[string]*xyJson.Schema, error) {
+	content := obj.Get("content")
+	if content == nil {
+		return nil, nil
+	}
+	if content.AsObject() == nil {
+		return nil, invalid(location + "/content must be an object")
+	}
+
+	schemas := make(map[string]*xyJson.Schema, content.AsObject().Size())
+	var err error
+	content.AsObject().Range(func(media string, value xyJson.IValue) bool {
+		mediaObj := value.AsObject()
+		if mediaObj == nil {
+			err = invalid(location + "/content/" + escapePointer(media) + " must be an object")
+			return false
+		}
+		var schema *xyJson.Schema
+		if mediaObj.Has("schema") {
+			schema, err = d.compile(location + "/content/" + escapePointer(media) + "/schema")
+			if err != nil {
+				return false
+			}
+		}
+		schemas[strings.ToLower(media)] = schema
+		return true
+	})
+	return schemas, err
+}
+
+// selectMedia 依次按精确媒体类型、type/*和*/*选择Schema
+// selectMedia selects a schema by the exact media type, then type/* and then */*
+func selectMedia(content map[string]*xyJson.Schema, contentType string) (*xyJson.Schema, bool) {
+	media := mediaType(contentType)
+	if schema, ok := content[media]; ok {
+		return schema, true
+	}
+	if i := strings.IndexByte(media, '/'); i >= 0 {
+		if schema, ok := content[media[:i]+"/*"]; ok {
+			return schema, true
+		}
+	}
+	schema, ok := content["*/*"]
+	return schema, ok
+}
+
+// mediaType 去掉Content-Type中的参数并转换为小写，为空时返回application/json
+// mediaType strips the parameters from a Content-Type and lower-cases it, returning application/json when empty
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return "application/json"
+	}
+	return contentType
+}
@@ -0,0 +1,489 @@
+package xyJson
+
+import (
+	"strconv"
+	"unsafe"
+)
+
+// Reset 设置下一次ParseInto要解析的数据并重置解析器状态
+// Reset sets the data for the next ParseInto and resets the parser state
+func (p *parser) Reset(data []byte) {
+	p.reset(data)
+}
+
+// ParseInto 解析Reset设置的数据，尽量复用root中已有的节点
+// ParseInto parses the data set by Reset, reusing the existing nodes of root where possible
+//
+// 结构相同的位置原地覆盖：对象按键复用子节点并删除消息中不存在的键，数组按下标复用元素并截断多余的元素，
+// 标量原地改写，未改变的字符串和键不会重新分配。结构不同的位置以及root为nil时创建新节点。
+// 返回解析后的根，类型相同时就是root本身
+// Positions with the same structure are overwritten in place: objects reuse children by key and drop keys
+// absent from the message, arrays reuse elements by index and truncate the rest, scalars are rewritten in
+// place, and unchanged strings and keys are not reallocated. New nodes are created where the structure differs
+// and when root is nil. The parsed root is returned, which is root itself when the types match
+//
+// root必须由调用方独占（通常是上一次ParseInto的结果），不能与其他文档共享节点；
+// 出错时root处于未定义的部分覆盖状态
+// root must be owned exclusively by the caller (typically the result of the previous ParseInto) and must not
+// share nodes with other documents; on error root is left partially overwritten
+//
+// 示例 Example:
+//
+//	parser := xyJson.NewParser()
+//	var doc xyJson.IValue
+//	for msg := range messages {
+//		parser.Reset(msg)
+//		if doc, err = parser.ParseInto(doc); err != nil {
+//			return err
+//		}
+//		handle(doc)
+//	}
+func (p *parser) ParseInto(root IValue) (IValue, error) {
+	if p.monitor == nil {
+		return p.parseInto(root)
+	}
+
+	timer := p.monitor.StartParseTimer()
+	value, err := p.parseInto(root)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return value, nil
+}
+
+// parseInto 解析到已有的树，不计时
+// parseInto parses into an existing tree without timing
+func (p *parser) parseInto(root IValue) (IValue, error) {
+	if len(p.data) == 0 {
+		return nil, NewInvalidJSONError("empty input", nil)
+	}
+
+	data, err := decodeInput(p.data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.reset(data)
+	p.skipWhitespace()
+
+	if p.pos >= len(p.data) {
+		return nil, NewInvalidJSONError("unexpected end of input", nil)
+	}
+
+	value, err := p.parseValueInto(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查是否还有多余的字符
+	p.skipWhitespace()
+	if p.pos < len(p.data) {
+		return nil, NewInvalidJSONError("unexpected character after JSON", nil)
+	}
+
+	return value, nil
+}
+
+// parseValueInto 解析JSON值，尽量复用existing
+// parseValueInto parses a JSON value, reusing existing where possible
+func (p *parser) parseValueInto(existing IValue) (IValue, error) {
+	p.skipWhitespace()
+
+	if p.pos >= len(p.data) {
+		return nil, NewInvalidJSONError("unexpected end of input", nil)
+	}
+
+	if p.allowNonFinite {
+		if f, n := matchNonFinite(p.data[p.pos:]); n > 0 {
+			p.pos += n
+			p.column += n
+			return p.reuseFloat(existing, f), nil
+		}
+	}
+
+	switch p.data[p.pos] {
+	case '"':
+		return p.parseStringInto(existing)
+	case '{':
+		return p.parseObjectInto(existing)
+	case '[':
+		return p.parseArrayInto(existing)
+	case 't', 'f', 'n':
+		return p.parseLiteralInto(existing)
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return p.parseNumberInto(existing)
+	default:
+		return nil, NewInvalidJSONError("unexpected character: "+string(p.data[p.pos]), nil)
+	}
+}
+
+// reusableScalar 返回可以原地改写的标量节点，不可复用时返回nil
+// reusableScalar returns the scalar node that can be rewritten in place, nil when it cannot be reused
+func reusableScalar(existing IValue) *scalarValue {
+	sv, ok := existing.(*scalarValue)
+	if !ok {
+		return nil
+	}
+	sv.clearMeta()
+	return sv
+}
+
+// parseLiteralInto 解析true、false或null并原地改写existing；布尔值和nil装箱不分配内存
+// parseLiteralInto parses true, false or null and rewrites existing in place; boxing booleans and nil does not
+// allocate
+func (p *parser) parseLiteralInto(existing IValue) (IValue, error) {
+	var valueType ValueType
+	var raw interface{}
+	switch {
+	case p.hasLiteral("true"):
+		valueType, raw = BoolValueType, true
+	case p.hasLiteral("false"):
+		valueType, raw = BoolValueType, false
+	case p.hasLiteral("null"):
+		valueType = NullValueType
+	case p.data[p.pos] == 'n':
+		return nil, NewInvalidJSONError("invalid null value", nil)
+	default:
+		return nil, NewInvalidJSONError("invalid boolean value", nil)
+	}
+
+	sv := reusableScalar(existing)
+	if sv == nil {
+		if valueType == NullValueType {
+			return p.factory.CreateNull(), nil
+		}
+		return p.factory.CreateBool(raw.(bool)), nil
+	}
+	sv.valueType = valueType
+	sv.rawData = raw
+	return sv, nil
+}
+
+// hasLiteral 当前位置是给定字面量时跳过它并返回true
+// hasLiteral skips the given literal and returns true when it is at the current position
+func (p *parser) hasLiteral(literal string) bool {
+	end := p.pos + len(literal)
+	if end > len(p.data) || string(p.data[p.pos:end]) != literal {
+		return false
+	}
+	p.pos = end
+	p.column += len(literal)
+	return true
+}
+
+// reuseFloat 将浮点数写入existing，值未变时不重新装箱
+// reuseFloat writes a float into existing without reboxing when the value is unchanged
+func (p *parser) reuseFloat(existing IValue, f float64) IValue {
+	sv := reusableScalar(existing)
+	if sv == nil {
+		return p.factory.CreateNumber(f)
+	}
+	if current, ok := sv.rawData.(float64); !ok || current != f || sv.valueType != NumberValueType {
+		sv.valueType = NumberValueType
+		sv.rawData = f
+	}
+	return sv
+}
+
+// parseStringInto 解析字符串，内容未变时保留existing中的字符串
+// parseStringInto parses a string, keeping the string in existing when the content is unchanged
+func (p *parser) parseStringInto(existing IValue) (IValue, error) {
+	raw, escaped, err := p.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	sv := reusableScalar(existing)
+	if sv != nil && !escaped {
+		if current, ok := sv.rawData.(string); ok && current == string(raw) {
+			return sv, nil
+		}
+	}
+
+	str := string(raw)
+	if escaped {
+		if str, err = p.unescapeString(str); err != nil {
+			return nil, err
+		}
+	}
+	if sv == nil {
+		return p.factory.CreateString(str), nil
+	}
+	sv.valueType = StringValueType
+	sv.rawData = str
+	return sv, nil
+}
+
+// parseNumberInto 解析数字，值未变时不重新装箱
+// parseNumberInto parses a number without reboxing when the value is unchanged
+func (p *parser) parseNumberInto(existing IValue) (IValue, error) {
+	start, isFloat, err := p.scanNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// 只在本函数内使用的临时字符串，直接引用输入
+	// A temporary string used only within this function, referencing the input directly
+	numStr := unsafe.String(&p.data[start], p.pos-start)
+
+	if isFloat {
+		val, err := strconv.ParseFloat(numStr, 64)
+		if err != nil {
+			return nil, NewInvalidJSONError("invalid number: "+string(p.data[start:p.pos]), nil)
+		}
+		return p.reuseFloat(existing, val), nil
+	}
+
+	val, err := strconv.ParseInt(numStr, 10, 64)
+	if err != nil {
+		return nil, NewInvalidJSONError("invalid number: "+string(p.data[start:p.pos]), nil)
+	}
+	sv := reusableScalar(existing)
+	if sv == nil {
+		return p.factory.CreateNumber(val), nil
+	}
+	if current, ok := sv.rawData.(int64); !ok || current != val || sv.valueType != NumberValueType {
+		sv.valueType = NumberValueType
+		sv.rawData = val
+	}
+	return sv, nil
+}
+
+// parseObjectInto 解析对象，按键复用existing的子节点
+// parseObjectInto parses an object, reusing the children of existing by key
+func (p *parser) parseObjectInto(existing IValue) (IValue, error) {
+	ov, ok := existing.(*objectValue)
+	if !ok {
+		return p.parseObject()
+	}
+	ov.clearMeta()
+	// 标量子值会被原地覆盖而不经过store，因此整体记录一次修改
+	// Scalar children are overwritten in place without going through store, so record the modification once
+	ov.touch()
+
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > p.maxDepth {
+		return nil, NewInvalidJSONError("maximum depth exceeded", nil)
+	}
+
+	p.advance() // 跳过 '{'
+	p.skipWhitespace()
+
+	seen := p.seenKeys()
+
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.advance()
+	} else {
+		for {
+			p.skipWhitespace()
+			if p.pos >= len(p.data) {
+				return nil, NewInvalidJSONError("unexpected end of input in object", nil)
+			}
+			if p.data[p.pos] != '"' {
+				return nil, NewInvalidJSONError("expected string key", nil)
+			}
+
+			raw, escaped, err := p.scanString()
+			if err != nil {
+				return nil, err
+			}
+
+			// key是对象自己持有的或新分配的键；seenKey只用于本次的重复检测，可能直接引用输入
+			// key is the object's own or a freshly allocated key; seenKey is only used for this duplicate check
+			// and may reference the input directly
+			var key, seenKey string
+			var child IValue
+			var exists bool
+			if escaped {
+				if key, err = p.unescapeString(string(raw)); err != nil {
+					return nil, err
+				}
+				ov.mu.RLock()
+				child, exists = ov.lookup(key)
+				ov.mu.RUnlock()
+				seenKey = key
+			} else {
+				ov.mu.RLock()
+				key, child, exists = ov.lookupBytes(raw)
+				ov.mu.RUnlock()
+				switch {
+				case key != "":
+					seenKey = key
+				case exists:
+					seenKey = unsafe.String(&raw[0], len(raw))
+				default:
+					key = string(raw)
+					seenKey = key
+				}
+			}
+			if seenKey == "" {
+				return nil, NewInvalidOperationError("set object key", "key cannot be empty")
+			}
+
+			p.skipWhitespace()
+			if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+				return nil, NewInvalidJSONError("expected ':'", nil)
+			}
+			p.advance() // 跳过 ':'
+
+			if _, duplicate := seen[seenKey]; duplicate {
+				return nil, NewInvalidJSONError("duplicate key: "+string(raw), nil)
+			}
+			seen[seenKey] = struct{}{}
+
+			value, err := p.parseValueInto(child)
+			if err != nil {
+				return nil, err
+			}
+			if !exists || value != child {
+				if key == "" {
+					// 给map写入已有的键会替换其中的键，不能使用引用输入的seenKey
+					// Assigning an existing key to a map replaces the stored key, so seenKey must not be used
+					key = string(raw)
+				}
+				ov.mu.Lock()
+				ov.store(key, value)
+				ov.mu.Unlock()
+			}
+
+			p.skipWhitespace()
+			if p.pos >= len(p.data) {
+				return nil, NewInvalidJSONError("unexpected end of input in object", nil)
+			}
+
+			ch := p.data[p.pos]
+			if ch == '}' {
+				p.advance()
+				break
+			} else if ch == ',' {
+				p.advance()
+			} else {
+				return nil, NewInvalidJSONError("expected ',' or '}'", nil)
+			}
+		}
+	}
+
+	// 删除本次消息中没有出现的键
+	// Drop the keys that did not appear in this message
+	ov.mu.Lock()
+	if ov.count() > len(seen) {
+		stale := p.stale[:0]
+		ov.each(func(key string, _ IValue) {
+			if _, ok := seen[key]; !ok {
+				stale = append(stale, key)
+			}
+		})
+		for _, key := range stale {
+			ov.remove(key)
+		}
+		clear(stale)
+		p.stale = stale[:0]
+	}
+	ov.mu.Unlock()
+
+	return ov, nil
+}
+
+// seenKeys 返回当前深度复用的已见键集合，已清空
+// seenKeys returns the cleared set of seen keys reused at the current depth
+func (p *parser) seenKeys() map[string]struct{} {
+	for len(p.seen) < p.depth {
+		p.seen = append(p.seen, make(map[string]struct{}))
+	}
+	seen := p.seen[p.depth-1]
+	clear(seen)
+	return seen
+}
+
+// lookupBytes 按原始字节查找不含转义的键，不分配内存；内联存储时返回对象自己持有的键，map存储时键返回空字符串
+// lookupBytes looks up an unescaped key by its raw bytes without allocating; inline objects return the key they
+// hold, map objects return an empty key
+func (ov *objectValue) lookupBytes(raw []byte) (string, IValue, bool) {
+	if ov.data != nil {
+		// map[string(b)]形式的查找不会分配内存
+		// Lookups of the form map[string(b)] do not allocate
+		value, exists := ov.data[string(raw)]
+		return "", value, exists
+	}
+	for i := 0; i < ov.n; i++ {
+		if ov.small[i].key == string(raw) {
+			return ov.small[i].key, ov.small[i].value, true
+		}
+	}
+	return "", nil, false
+}
+
+// parseArrayInto 解析数组，按下标复用existing的元素并截断多余的元素
+// parseArrayInto parses an array, reusing the elements of existing by index and truncating the rest
+func (p *parser) parseArrayInto(existing IValue) (IValue, error) {
+	av, ok := existing.(*arrayValue)
+	if !ok {
+		return p.parseArray()
+	}
+	av.clearMeta()
+	av.touch()
+
+	p.depth++
+	defer func() { p.depth-- }()
+
+	if p.depth > p.maxDepth {
+		return nil, NewInvalidJSONError("maximum depth exceeded", nil)
+	}
+
+	p.advance() // 跳过 '['
+	p.skipWhitespace()
+
+	index := 0
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.advance()
+	} else {
+		for {
+			var child IValue
+			av.mu.RLock()
+			if index < len(av.data) {
+				child = av.data[index]
+			}
+			av.mu.RUnlock()
+
+			value, err := p.parseValueInto(child)
+			if err != nil {
+				return nil, err
+			}
+
+			av.mu.Lock()
+			if index < len(av.data) {
+				av.data[index] = value
+			} else {
+				av.data = append(av.data, value)
+			}
+			av.mu.Unlock()
+			index++
+
+			p.skipWhitespace()
+			if p.pos >= len(p.data) {
+				return nil, NewInvalidJSONError("unexpected end of input in array", nil)
+			}
+
+			ch := p.data[p.pos]
+			if ch == ']' {
+				p.advance()
+				break
+			} else if ch == ',' {
+				p.advance()
+			} else {
+				return nil, NewInvalidJSONError("expected ',' or ']'", nil)
+			}
+		}
+	}
+
+	av.mu.Lock()
+	clear(av.data[index:])
+	av.data = av.data[:index]
+	av.mu.Unlock()
+
+	return av, nil
+}
@@ -0,0 +1,65 @@
+package xyJson
+
+import "strconv"
+
+// ParseMulti 解析包含多个连续JSON值的输入，值之间可以没有分隔符，也可以有任意空白
+// ParseMulti parses input containing several concatenated JSON values, with or without whitespace between them
+//
+// 适用于流式API和日志收集器输出的`{"a":1}{"b":2}`形式的数据。两个数字或字面量（true、false、null）
+// 之间仍需空白才能区分。只包含空白的输入返回空切片
+// Suited to data such as `{"a":1}{"b":2}` emitted by streaming APIs and log shippers. Two numbers or literals
+// (true, false, null) still need whitespace between them to be told apart. Input containing only whitespace
+// returns an empty slice
+//
+// 示例 Example:
+//
+//	values, err := xyJson.ParseMulti([]byte(`{"id":1}{"id":2} {"id":3}`))
+//	for _, v := range values {
+//		fmt.Println(xyJson.MustGetInt(v, "$.id"))
+//	}
+func ParseMulti(data []byte) ([]IValue, error) {
+	timer := GetGlobalMonitor().StartParseTimer()
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+
+	values, err := p.parseMulti(data)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return values, nil
+}
+
+// ParseMultiString 解析包含多个连续JSON值的字符串
+// ParseMultiString parses a string containing several concatenated JSON values
+func ParseMultiString(data string) ([]IValue, error) {
+	return ParseMulti([]byte(data))
+}
+
+// parseMulti 依次解析data中的所有值
+// parseMulti parses all values in data one after another
+func (p *parser) parseMulti(data []byte) ([]IValue, error) {
+	data, err := decodeInput(data)
+	if err != nil {
+		return nil, err
+	}
+	p.reset(data)
+
+	values := []IValue{}
+	for {
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return values, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			if je, ok := err.(*JSONError); ok {
+				return nil, je.WithPosition(p.line, p.column).
+					WithContext("document " + strconv.Itoa(len(values)) + " at offset " + strconv.Itoa(p.pos))
+			}
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
@@ -0,0 +1,90 @@
+package xyJson
+
+// ParseOptions 解析选项
+// ParseOptions holds parse options
+type ParseOptions struct {
+	// StrictNumbers 自定义解析器是否严格按RFC 8259语法校验数字，拒绝"01"、"1."、"1e"等写法
+	// StrictNumbers indicates whether the custom parser checks numbers strictly against the RFC 8259 grammar,
+	// rejecting forms such as "01", "1." and "1e"
+	StrictNumbers bool
+
+	// AllowNonFinite 是否接受NaN、Infinity和-Infinity字面量并解析为对应的浮点数（非标准扩展）
+	// AllowNonFinite indicates whether the NaN, Infinity and -Infinity literals are accepted and parsed into
+	// the matching floats (a non-standard extension)
+	AllowNonFinite bool
+
+	// MaxDepth 最大嵌套深度，0表示使用DefaultMaxDepth；自定义解析器在解码和跳过的值超过该深度时返回ErrMaxDepthExceeded错误
+	// MaxDepth is the maximum nesting depth, 0 means DefaultMaxDepth; the custom parser returns an
+	// ErrMaxDepthExceeded error when decoded or skipped values exceed it
+	MaxDepth int
+
+	// Iterative ParseWithOptions是否使用显式栈代替递归，用于嵌套极深的文档（如图导出的深层链表）；需要同时提高MaxDepth
+	// Iterative indicates whether ParseWithOptions uses an explicit stack instead of recursion, for extremely
+	// deeply nested documents (such as deep linked structures from graph exports); MaxDepth must be raised
+	// as well
+	Iterative bool
+
+	// KeyTransform ParseWithOptions对解析出的对象键名应用的命名风格转换
+	// KeyTransform is the naming style conversion ParseWithOptions applies to parsed object keys
+	KeyTransform KeyTransform
+
+	// AdaptiveSizing ParseWithOptions是否按以往相近大小文档中对象和数组的平均大小预留容量，减少解析时切片扩容的分配；
+	// 统计在进程内共享，结果不受影响
+	// AdaptiveSizing indicates whether ParseWithOptions reserves capacity for objects and arrays from their
+	// average sizes in earlier documents of similar size, cutting the allocations of slice growth during
+	// parsing; the statistics are shared process-wide and the results are unaffected
+	AdaptiveSizing bool
+
+	// PreserveNumberText ParseWithOptions解析出的数字是否保留原始文本，序列化时原样输出，使"1.10"、"1e3"和超出int64范围的整数
+	// 逐字节不变，用于只检查而不能改动载荷的代理；原始文本可以通过NumberText读取
+	// PreserveNumberText indicates whether numbers parsed by ParseWithOptions keep their original text and are
+	// serialized verbatim, so "1.10", "1e3" and integers beyond the int64 range stay byte-for-byte identical,
+	// for proxies that inspect payloads but must not alter them; the text can be read with NumberText
+	PreserveNumberText bool
+
+	// FailureRecorder ParseWithOptions失败时记录输入的记录器，nil表示不记录
+	// FailureRecorder is the recorder that keeps the input of a failed ParseWithOptions, nil records nothing
+	FailureRecorder *FailureRecorder
+}
+
+// DefaultParseOptions 返回默认解析选项
+// DefaultParseOptions returns default parse options
+func DefaultParseOptions() *ParseOptions {
+	return &ParseOptions{
+		StrictNumbers:  false,
+		AllowNonFinite: false,
+		MaxDepth:       DefaultMaxDepth,
+	}
+}
+
+// ParseWithOptions 使用指定解析选项解析JSON字节数组
+// ParseWithOptions parses a JSON byte array with the specified parse options
+//
+// 示例 Example:
+//
+//	value, err := xyJson.ParseWithOptions(data, &xyJson.ParseOptions{AllowNonFinite: true})
+func ParseWithOptions(data []byte, options *ParseOptions) (IValue, error) {
+	if options == nil {
+		options = DefaultParseOptions()
+	}
+
+	timer := GetGlobalMonitor().StartParseTimer()
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+	p.allowNonFinite = options.AllowNonFinite
+	p.SetMaxDepth(options.MaxDepth)
+	p.iterative = options.Iterative
+	p.keyTransform = options.KeyTransform
+	p.preserveNumbers = options.PreserveNumberText
+	if options.AdaptiveSizing {
+		p.sizer = globalSizer
+	}
+
+	value, err := p.Parse(data)
+	if err != nil {
+		recordParseFailure(options.FailureRecorder, data, err)
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return value, nil
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 	"unicode/utf8"
+	"unsafe"
 )
 
 // parser JSON解析器实现
@@ -19,6 +20,47 @@ type parser struct {
 	depth    int
 	lastChar rune
 	lastSize int
+
+	// zeroCopy 未转义的字符串直接引用data而不复制，仅在data的生命周期受控时使用
+	// zeroCopy makes unescaped strings reference data without copying, only used when data's lifetime is managed
+	zeroCopy bool
+
+	// allowNonFinite 是否接受NaN、Infinity和-Infinity字面量
+	// allowNonFinite indicates whether the NaN, Infinity and -Infinity literals are accepted
+	allowNonFinite bool
+
+	// iterative 是否使用显式栈解析对象和数组
+	// iterative indicates whether objects and arrays are parsed with an explicit stack
+	iterative bool
+
+	// keyTransform 对解析出的对象键名应用的命名风格转换
+	// keyTransform is the naming style conversion applied to parsed object keys
+	keyTransform KeyTransform
+
+	// preserveNumbers 数字是否保留原始文本
+	// preserveNumbers indicates whether numbers keep their original text
+	preserveNumbers bool
+
+	// monitor 绑定到该解析器的性能监控器，为nil时不计时
+	// monitor is the performance monitor bound to this parser, no timing when nil
+	monitor *PerformanceMonitor
+
+	// seen和stale是ParseInto在各次调用间复用的临时空间
+	// seen and stale are scratch space ParseInto reuses across calls
+	seen  []map[string]struct{}
+	stale []string
+
+	// shapes 形状缓存的根，为nil时不使用形状缓存；shape是下一个要解析的值的形状
+	// shapes is the root of the shape cache, no shape cache when nil; shape is the shape of the next value to parse
+	shapes *shape
+	shape  *shape
+
+	// sizer 自适应大小调整的统计，为nil时不使用；hints和counts是当前文档的预估和计数
+	// sizer holds the adaptive sizing statistics, unused when nil; hints and counts are the estimates and
+	// counts of the current document
+	sizer  *adaptiveSizer
+	hints  SizingHints
+	counts parseCounts
 }
 
 // NewParser 创建新的JSON解析器
@@ -143,18 +185,47 @@ func NewParserWithFactory(factory IValueFactory) IParser {
 // Parse 解析JSON字节数组
 // Parse parses JSON byte array
 func (p *parser) Parse(data []byte) (IValue, error) {
+	if p.monitor == nil {
+		return p.parse(data)
+	}
+
+	timer := p.monitor.StartParseTimer()
+	value, err := p.parse(data)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return value, nil
+}
+
+// parse 解析JSON字节数组，不计时
+// parse parses a JSON byte array without timing
+func (p *parser) parse(data []byte) (IValue, error) {
 	if len(data) == 0 {
 		return nil, NewInvalidJSONError("empty input", nil)
 	}
 
+	data, err := decodeInput(data)
+	if err != nil {
+		return nil, err
+	}
+
 	p.reset(data)
+	p.shape = p.shapes
+	p.startSizing(len(data))
 	p.skipWhitespace()
 
 	if p.pos >= len(p.data) {
 		return nil, NewInvalidJSONError("unexpected end of input", nil)
 	}
 
-	value, err := p.parseValue()
+	var value IValue
+	if p.iterative {
+		value, err = p.parseValueIterative()
+	} else {
+		value, err = p.parseValue()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +236,7 @@ func (p *parser) Parse(data []byte) (IValue, error) {
 		return nil, NewInvalidJSONError("unexpected character after JSON", nil)
 	}
 
+	p.finishSizing(len(data))
 	return value, nil
 }
 
@@ -198,6 +270,7 @@ func (p *parser) reset(data []byte) {
 	p.depth = 0
 	p.lastChar = 0
 	p.lastSize = 0
+	p.shape = nil
 }
 
 // parseValue 解析JSON值
@@ -209,6 +282,14 @@ func (p *parser) parseValue() (IValue, error) {
 		return nil, NewInvalidJSONError("unexpected end of input", nil)
 	}
 
+	if p.allowNonFinite {
+		if f, n := matchNonFinite(p.data[p.pos:]); n > 0 {
+			p.pos += n
+			p.column += n
+			return p.factory.CreateNumber(f), nil
+		}
+	}
+
 	ch := p.data[p.pos]
 	switch ch {
 	case '"':
@@ -231,51 +312,54 @@ func (p *parser) parseValue() (IValue, error) {
 // parseString 解析字符串
 // parseString parses a string
 func (p *parser) parseString() (IValue, error) {
+	raw, escaped, err := p.scanString()
+	if err != nil {
+		return nil, err
+	}
+
+	var str string
+	if escaped {
+		str, err = p.unescapeString(string(raw))
+		if err != nil {
+			return nil, err
+		}
+	} else if p.zeroCopy && len(raw) > 0 {
+		str = unsafe.String(&raw[0], len(raw))
+	} else {
+		str = string(raw)
+	}
+	return p.factory.CreateString(str), nil
+}
+
+// scanString 校验并跳过字符串，返回引号之间未反转义的原始字节以及是否包含转义
+// scanString validates and skips a string, returning the raw bytes between the quotes without unescaping and
+// whether they contain escapes
+func (p *parser) scanString() ([]byte, bool, error) {
 	if p.data[p.pos] != '"' {
-		return nil, NewInvalidJSONError("expected '\"'", nil)
+		return nil, false, NewInvalidJSONError("expected '\"'", nil)
 	}
 
 	p.advance() // 跳过开始的引号
 	start := p.pos
-	var buf []byte
 	hasEscape := false
 
 	for p.pos < len(p.data) {
 		ch := p.data[p.pos]
 		if ch == '"' {
 			// 字符串结束
-			var str string
-			if hasEscape {
-				if buf == nil {
-					buf = make([]byte, 0, p.pos-start)
-					buf = append(buf, p.data[start:p.pos]...)
-				}
-				var err error
-				str, err = p.unescapeString(string(buf))
-				if err != nil {
-					return nil, err
-				}
-			} else {
-				str = string(p.data[start:p.pos])
-			}
+			raw := p.data[start:p.pos]
 			p.advance() // 跳过结束的引号
-			return p.factory.CreateString(str), nil
+			return raw, hasEscape, nil
 		}
 
 		if ch == '\\' {
+			// 转义序列原样保留，稍后由unescapeString处理
 			hasEscape = true
-			if buf == nil {
-				buf = make([]byte, 0, len(p.data)-start)
-				buf = append(buf, p.data[start:p.pos]...)
-			}
-			// 将转义序列原样添加到缓冲区，稍后由unescapeString处理
-			buf = append(buf, ch) // 添加反斜杠
-			p.advance()           // 跳过反斜杠
+			p.advance() // 跳过反斜杠
 			if p.pos >= len(p.data) {
-				return nil, NewInvalidJSONError("unexpected end of input in string escape", nil)
+				return nil, false, NewInvalidJSONError("unexpected end of input in string escape", nil)
 			}
 			escapeChar := p.data[p.pos]
-			buf = append(buf, escapeChar) // 添加转义字符
 
 			// 验证转义字符的有效性
 			switch escapeChar {
@@ -284,39 +368,34 @@ func (p *parser) parseString() (IValue, error) {
 			case 'u':
 				// Unicode转义，需要验证后续4个十六进制字符
 				if p.pos+4 >= len(p.data) {
-					return nil, NewInvalidJSONError("incomplete unicode escape", nil)
+					return nil, false, NewInvalidJSONError("incomplete unicode escape", nil)
 				}
 				p.advance() // 跳过'u'
 				for i := 0; i < 4; i++ {
 					if p.pos >= len(p.data) {
-						return nil, NewInvalidJSONError("incomplete unicode escape", nil)
+						return nil, false, NewInvalidJSONError("incomplete unicode escape", nil)
 					}
 					ch = p.data[p.pos]
 					if !((ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')) {
-						return nil, NewInvalidJSONError("invalid unicode escape", nil)
+						return nil, false, NewInvalidJSONError("invalid unicode escape", nil)
 					}
-					buf = append(buf, ch)
 					p.advance()
 				}
 				continue
 			default:
-				return nil, NewInvalidJSONError("invalid escape character: \\"+string(escapeChar), nil)
+				return nil, false, NewInvalidJSONError("invalid escape character: \\"+string(escapeChar), nil)
 			}
 			p.advance()
 			continue
 		}
 
 		if ch < 0x20 {
-			return nil, NewInvalidJSONError("invalid character in string", nil)
-		}
-
-		if hasEscape && buf != nil {
-			buf = append(buf, ch)
+			return nil, false, NewInvalidJSONError("invalid character in string", nil)
 		}
 		p.advance()
 	}
 
-	return nil, NewInvalidJSONError("unterminated string", nil)
+	return nil, false, NewInvalidJSONError("unterminated string", nil)
 }
 
 // parseUnicodeEscape 解析Unicode转义序列
@@ -391,6 +470,7 @@ func (p *parser) parseObject() (IValue, error) {
 	p.advance() // 跳过 '{'
 	p.skipWhitespace()
 
+	layout := p.shape
 	obj := p.factory.CreateObject()
 
 	// 空对象
@@ -399,7 +479,17 @@ func (p *parser) parseObject() (IValue, error) {
 		return obj, nil
 	}
 
-	for {
+	// matched表示到目前为止的键都与形状记录的位置一致，learning表示仍在记录当前消息的布局
+	// matched means every key so far is at its recorded position, learning means the layout of the current
+	// message is still being recorded
+	matched, learning := layout != nil, layout != nil
+	if layout != nil {
+		obj.EnsureCapacity(len(layout.keys))
+	} else {
+		p.sizeObject(obj)
+	}
+
+	for i := 0; ; i++ {
 		// 解析键
 		p.skipWhitespace()
 		if p.pos >= len(p.data) {
@@ -410,11 +500,11 @@ func (p *parser) parseObject() (IValue, error) {
 			return nil, NewInvalidJSONError("expected string key", nil)
 		}
 
-		keyValue, err := p.parseString()
+		key, hit, escaped, err := p.parseKey(layout, i, matched)
 		if err != nil {
 			return nil, err
 		}
-		key := keyValue.String()
+		matched = hit
 
 		// 解析冒号
 		p.skipWhitespace()
@@ -423,9 +513,23 @@ func (p *parser) parseObject() (IValue, error) {
 		}
 		p.advance() // 跳过 ':'
 
-		// 检查重复键
-		if obj.Has(key) {
-			return nil, NewInvalidJSONError("duplicate key: "+key, nil)
+		// 检查重复键；与形状一致的键彼此不同，无需检查，但转换后的键名可能相同
+		name := p.keyTransform.Apply(key)
+		if (!hit || name != key) && obj.Has(name) {
+			return nil, NewInvalidJSONError("duplicate key: "+name, nil)
+		}
+
+		p.shape = nil
+		switch {
+		case hit:
+			p.shape = layout.child(i)
+		case learning && !escaped:
+			layout.learn(i, key)
+			p.shape = layout.child(i)
+		case learning:
+			// 含转义的键无法按原始字节比较，不再记录该对象之后的布局
+			layout.truncate(i)
+			learning = false
 		}
 
 		// 解析值
@@ -434,7 +538,7 @@ func (p *parser) parseObject() (IValue, error) {
 			return nil, err
 		}
 
-		if err := obj.Set(key, value); err != nil {
+		if err := obj.Set(name, value); err != nil {
 			return nil, err
 		}
 
@@ -456,9 +560,36 @@ func (p *parser) parseObject() (IValue, error) {
 		}
 	}
 
+	p.countObject(obj)
 	return obj, nil
 }
 
+// parseKey 解析对象的第i个键；matched时与形状中记录的键比较，一致时直接返回记录的键而不分配
+// parseKey parses the i-th key of an object; when matched it is compared with the key recorded in the layout,
+// returning the recorded key without allocating when they are equal
+func (p *parser) parseKey(s *shape, i int, matched bool) (key string, hit bool, escaped bool, err error) {
+	if s == nil {
+		keyValue, err := p.parseString()
+		if err != nil {
+			return "", false, false, err
+		}
+		return keyValue.String(), false, false, nil
+	}
+
+	raw, escaped, err := p.scanString()
+	if err != nil {
+		return "", false, false, err
+	}
+	if escaped {
+		key, err = p.unescapeString(string(raw))
+		return key, false, true, err
+	}
+	if matched && i < len(s.keys) && s.keys[i] == string(raw) {
+		return s.keys[i], true, false, nil
+	}
+	return string(raw), false, false, nil
+}
+
 // parseArray 解析数组
 // parseArray parses an array
 func (p *parser) parseArray() (IValue, error) {
@@ -476,16 +607,29 @@ func (p *parser) parseArray() (IValue, error) {
 	p.advance() // 跳过 '['
 	p.skipWhitespace()
 
+	layout := p.shape
 	arr := p.factory.CreateArray()
 
 	// 空数组
 	if p.pos < len(p.data) && p.data[p.pos] == ']' {
 		p.advance()
+		if layout != nil {
+			layout.length = 0
+		}
 		return arr, nil
 	}
 
+	var elem *shape
+	if layout != nil {
+		elem = layout.element()
+		arr.Grow(layout.length)
+	} else {
+		p.sizeArray(arr)
+	}
+
 	for {
 		// 解析值
+		p.shape = elem
 		value, err := p.parseValue()
 		if err != nil {
 			return nil, err
@@ -513,6 +657,10 @@ func (p *parser) parseArray() (IValue, error) {
 		}
 	}
 
+	if layout != nil {
+		layout.length = arr.Length()
+	}
+	p.countArray(arr)
 	return arr, nil
 }
 
@@ -549,6 +697,35 @@ func (p *parser) parseNull() (IValue, error) {
 // parseNumber 解析数字
 // parseNumber parses a number
 func (p *parser) parseNumber() (IValue, error) {
+	start, isFloat, err := p.scanNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	numStr := string(p.data[start:p.pos])
+	if p.preserveNumbers {
+		return newTextNumber(numStr, isFloat)
+	}
+
+	if isFloat {
+		val, err := strconv.ParseFloat(numStr, 64)
+		if err != nil {
+			return nil, NewInvalidJSONError("invalid number: "+numStr, nil)
+		}
+		return p.factory.CreateNumber(val), nil
+	} else {
+		val, err := strconv.ParseInt(numStr, 10, 64)
+		if err != nil {
+			return nil, NewInvalidJSONError("invalid number: "+numStr, nil)
+		}
+		return p.factory.CreateNumber(val), nil
+	}
+}
+
+// scanNumber 校验并跳过数字，返回起始位置以及是否包含小数或指数部分
+// scanNumber validates and skips a number, returning its start position and whether it has a fraction or
+// exponent
+func (p *parser) scanNumber() (int, bool, error) {
 	start := p.pos
 
 	// 处理负号
@@ -558,18 +735,21 @@ func (p *parser) parseNumber() (IValue, error) {
 
 	// 处理整数部分
 	if p.pos >= len(p.data) {
-		return nil, NewInvalidJSONError("incomplete number", nil)
+		return 0, false, NewInvalidJSONError("incomplete number", nil)
 	}
 
 	if p.data[p.pos] == '0' {
 		p.advance()
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			return 0, false, NewInvalidJSONError("invalid number: leading zeros are not allowed", nil)
+		}
 	} else if p.data[p.pos] >= '1' && p.data[p.pos] <= '9' {
 		p.advance()
 		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
 			p.advance()
 		}
 	} else {
-		return nil, NewInvalidJSONError("invalid number", nil)
+		return 0, false, NewInvalidJSONError("invalid number", nil)
 	}
 
 	isFloat := false
@@ -579,7 +759,7 @@ func (p *parser) parseNumber() (IValue, error) {
 		isFloat = true
 		p.advance()
 		if p.pos >= len(p.data) || p.data[p.pos] < '0' || p.data[p.pos] > '9' {
-			return nil, NewInvalidJSONError("invalid number: missing digits after decimal point", nil)
+			return 0, false, NewInvalidJSONError("invalid number: missing digits after decimal point", nil)
 		}
 		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
 			p.advance()
@@ -594,28 +774,14 @@ func (p *parser) parseNumber() (IValue, error) {
 			p.advance()
 		}
 		if p.pos >= len(p.data) || p.data[p.pos] < '0' || p.data[p.pos] > '9' {
-			return nil, NewInvalidJSONError("invalid number: missing digits in exponent", nil)
+			return 0, false, NewInvalidJSONError("invalid number: missing digits in exponent", nil)
 		}
 		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
 			p.advance()
 		}
 	}
 
-	numStr := string(p.data[start:p.pos])
-
-	if isFloat {
-		val, err := strconv.ParseFloat(numStr, 64)
-		if err != nil {
-			return nil, NewInvalidJSONError("invalid number: "+numStr, nil)
-		}
-		return p.factory.CreateNumber(val), nil
-	} else {
-		val, err := strconv.ParseInt(numStr, 10, 64)
-		if err != nil {
-			return nil, NewInvalidJSONError("invalid number: "+numStr, nil)
-		}
-		return p.factory.CreateNumber(val), nil
-	}
+	return start, isFloat, nil
 }
 
 // skipWhitespace 跳过空白字符
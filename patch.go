@@ -0,0 +1,365 @@
+package xyJson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation 一个JSON Patch（RFC 6902）操作，Path和From是JSON Pointer
+// PatchOperation is a single JSON Patch (RFC 6902) operation, Path and From are JSON Pointers
+type PatchOperation struct {
+	// Op 操作名：add、remove、replace、move、copy或test
+	// Op is the operation name: add, remove, replace, move, copy or test
+	Op string
+
+	// Path 目标位置，空字符串表示整个文档
+	// Path is the target location, the empty string denotes the whole document
+	Path string
+
+	// From move和copy的源位置
+	// From is the source location of move and copy
+	From string
+
+	// Value add、replace和test的值
+	// Value is the value of add, replace and test
+	Value IValue
+}
+
+// ToValue 将操作转换为JSON对象
+// ToValue converts the operation to a JSON object
+func (op PatchOperation) ToValue() IObject {
+	factory := defaultFactory()
+	obj := factory.CreateObject()
+	obj.Set("op", factory.CreateString(op.Op))
+	if op.Op == "move" || op.Op == "copy" {
+		obj.Set("from", factory.CreateString(op.From))
+	}
+	obj.Set("path", factory.CreateString(op.Path))
+	if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		obj.Set("value", journalValue(factory, op.Value))
+	}
+	return obj
+}
+
+// PatchToValue 将操作列表转换为JSON Patch文档
+// PatchToValue converts a list of operations to a JSON Patch document
+func PatchToValue(patch []PatchOperation) IArray {
+	arr := defaultFactory().CreateArray()
+	for _, op := range patch {
+		arr.Append(op.ToValue())
+	}
+	return arr
+}
+
+// ParsePatch 解析JSON Patch文档
+// ParsePatch parses a JSON Patch document
+func ParsePatch(data []byte) ([]PatchOperation, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return PatchFromValue(doc)
+}
+
+// PatchFromValue 将JSON数组转换为操作列表，检查每个操作的必需成员
+// PatchFromValue converts a JSON array to a list of operations, checking the required members of every operation
+func PatchFromValue(value IValue) ([]PatchOperation, error) {
+	if value == nil {
+		return nil, NewNullPointerError("patch cannot be nil")
+	}
+	if value.Type() != ArrayValueType {
+		return nil, NewTypeMismatchError(ArrayValueType, value.Type(), "$")
+	}
+
+	arr := value.AsArray()
+	patch := make([]PatchOperation, 0, arr.Length())
+	for i := 0; i < arr.Length(); i++ {
+		path := "$[" + strconv.Itoa(i) + "]"
+		item := arr.Get(i)
+		if item.Type() != ObjectValueType {
+			return nil, NewTypeMismatchError(ObjectValueType, item.Type(), path)
+		}
+		obj := item.AsObject()
+
+		var op PatchOperation
+		var ok bool
+		if op.Op, ok = patchMember(obj, "op"); !ok {
+			return nil, NewInvalidJSONError("patch operation requires a string op", nil).WithPath(path)
+		}
+		if op.Path, ok = patchMember(obj, "path"); !ok {
+			return nil, NewInvalidJSONError("patch operation requires a string path", nil).WithPath(path)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value = obj.Get("value"); op.Value == nil {
+				return nil, NewInvalidJSONError(op.Op+" operation requires a value", nil).WithPath(path)
+			}
+		case "move", "copy":
+			if op.From, ok = patchMember(obj, "from"); !ok {
+				return nil, NewInvalidJSONError(op.Op+" operation requires a string from", nil).WithPath(path)
+			}
+		case "remove":
+		default:
+			return nil, NewInvalidJSONError("unknown patch operation '"+op.Op+"'", nil).WithPath(path)
+		}
+		patch = append(patch, op)
+	}
+	return patch, nil
+}
+
+// patchMember 返回对象中的字符串成员
+// patchMember returns a string member of an object
+func patchMember(obj IObject, key string) (string, bool) {
+	value := obj.Get(key)
+	if value == nil {
+		return "", false
+	}
+	return value.AsStringOK()
+}
+
+// ApplyPatch 对文档的副本应用JSON Patch并返回结果，root不会被修改
+// ApplyPatch applies a JSON Patch to a copy of the document and returns the result, root is not modified
+//
+// 操作按顺序执行，任何一个失败时整个补丁不生效并返回错误。test按数值比较数字，因此1与1.0相等
+// Operations run in order; when any of them fails the whole patch has no effect and an error is returned.
+// test compares numbers by value, so 1 equals 1.0
+//
+// 示例 Example:
+//
+//	patch, _ := xyJson.ParsePatch([]byte(`[{"op":"replace","path":"/limits/rps","value":200}]`))
+//	updated, err := xyJson.ApplyPatch(config, patch)
+func ApplyPatch(root IValue, patch []PatchOperation) (IValue, error) {
+	if root == nil {
+		return nil, NewNullPointerError("value cannot be nil")
+	}
+	doc := root.Clone()
+	for i, op := range patch {
+		var err *JSONError
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			return nil, err.WithContext(fmt.Sprintf("patch operation %d: %s %s", i, op.Op, op.Path))
+		}
+	}
+	return doc, nil
+}
+
+// applyPatchOperation 在文档上执行一个操作，返回可能被替换的文档
+// applyPatchOperation performs one operation on the document, returning the possibly replaced document
+func applyPatchOperation(doc IValue, op PatchOperation) (IValue, *JSONError) {
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, op.Path, patchValue(op.Value))
+	case "remove":
+		return patchRemove(doc, op.Path)
+	case "replace":
+		return patchReplace(doc, op.Path, patchValue(op.Value))
+	case "move":
+		if op.From == op.Path {
+			_, err := patchGet(doc, op.From)
+			return doc, err
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, NewJSONError(ErrInvalidOperation, "cannot move a value into one of its children", nil)
+		}
+		value, err := patchGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = patchRemove(doc, op.From); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, value)
+	case "copy":
+		value, err := patchGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, value.Clone())
+	case "test":
+		value, err := patchGet(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(value, op.Value) {
+			return nil, NewJSONError(ErrInvalidOperation, "test failed", nil)
+		}
+		return doc, nil
+	default:
+		return nil, NewJSONError(ErrInvalidOperation, "unknown patch operation '"+op.Op+"'", nil)
+	}
+}
+
+// patchValue 返回操作值的拷贝，nil视为JSON null
+// patchValue returns a copy of an operation value, nil is treated as JSON null
+func patchValue(value IValue) IValue {
+	return journalValue(defaultFactory(), value)
+}
+
+// splitPointer 将JSON Pointer拆分为父级引用段和最后一个引用段
+// splitPointer splits a JSON Pointer into its parent tokens and its last token
+func splitPointer(pointer string) ([]string, string, *JSONError) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, "", NewJSONError(ErrInvalidPath, "invalid JSON Pointer '"+pointer+"'", nil)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	return tokens[:len(tokens)-1], tokens[len(tokens)-1], nil
+}
+
+// patchIndex 解析数组引用段，只接受没有前导零的十进制数
+// patchIndex parses an array token, accepting only decimal numbers without leading zeros
+func patchIndex(token string, length int, allowEnd bool) (int, *JSONError) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || token != strconv.Itoa(index) {
+		return 0, NewJSONError(ErrInvalidPath, "invalid array index '"+token+"'", nil)
+	}
+	if index > length || index == length && !allowEnd {
+		return 0, NewIndexOutOfRangeError(index, length, "")
+	}
+	return index, nil
+}
+
+// patchParent 返回引用段指向的容器
+// patchParent returns the container the tokens refer to
+func patchParent(doc IValue, tokens []string) (IValue, *JSONError) {
+	node := doc
+	for _, token := range tokens {
+		switch node.Type() {
+		case ObjectValueType:
+			node = node.AsObject().Get(token)
+		case ArrayValueType:
+			index, err := patchIndex(token, node.AsArray().Length(), false)
+			if err != nil {
+				return nil, err
+			}
+			node = node.AsArray().Get(index)
+		default:
+			node = nil
+		}
+		if node == nil {
+			return nil, NewJSONError(ErrPathNotFound, "path '/"+strings.Join(tokens, "/")+"' not found", nil)
+		}
+	}
+	return node, nil
+}
+
+// patchGet 返回JSON Pointer指向的值
+// patchGet returns the value a JSON Pointer refers to
+func patchGet(doc IValue, pointer string) (IValue, *JSONError) {
+	if pointer == "" {
+		return doc, nil
+	}
+	tokens, last, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return patchParent(doc, append(tokens, last))
+}
+
+// patchAdd 在JSON Pointer处添加值，数组中插入元素，对象中设置成员
+// patchAdd adds a value at a JSON Pointer, inserting into arrays and setting object members
+func patchAdd(doc IValue, pointer string, value IValue) (IValue, *JSONError) {
+	if pointer == "" {
+		return value, nil
+	}
+	tokens, last, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := patchParent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent.Type() {
+	case ObjectValueType:
+		parent.AsObject().Set(last, value)
+	case ArrayValueType:
+		arr := parent.AsArray()
+		index, err := patchIndex(last, arr.Length(), true)
+		if err != nil {
+			return nil, err
+		}
+		if index == arr.Length() {
+			arr.Append(value)
+		} else {
+			arr.Insert(index, value)
+		}
+	default:
+		return nil, NewJSONError(ErrTypeMismatch, "cannot add to "+parent.Type().String(), nil)
+	}
+	return doc, nil
+}
+
+// patchReplace 替换JSON Pointer指向的已有值，数组元素保持原位置
+// patchReplace replaces the existing value a JSON Pointer refers to, array elements keep their position
+func patchReplace(doc IValue, pointer string, value IValue) (IValue, *JSONError) {
+	if pointer == "" {
+		return value, nil
+	}
+	tokens, last, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := patchParent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent.Type() {
+	case ObjectValueType:
+		obj := parent.AsObject()
+		if !obj.Has(last) {
+			return nil, NewJSONError(ErrPathNotFound, "path '"+pointer+"' not found", nil)
+		}
+		obj.Set(last, value)
+	case ArrayValueType:
+		arr := parent.AsArray()
+		index, err := patchIndex(last, arr.Length(), false)
+		if err != nil {
+			return nil, err
+		}
+		arr.Set(index, value)
+	default:
+		return nil, NewJSONError(ErrPathNotFound, "path '"+pointer+"' not found", nil)
+	}
+	return doc, nil
+}
+
+// patchRemove 删除JSON Pointer指向的值
+// patchRemove removes the value a JSON Pointer refers to
+func patchRemove(doc IValue, pointer string) (IValue, *JSONError) {
+	if pointer == "" {
+		return nil, NewJSONError(ErrInvalidOperation, "cannot remove the whole document", nil)
+	}
+	tokens, last, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := patchParent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parent.Type() {
+	case ObjectValueType:
+		if !parent.AsObject().Delete(last) {
+			return nil, NewJSONError(ErrPathNotFound, "path '"+pointer+"' not found", nil)
+		}
+	case ArrayValueType:
+		arr := parent.AsArray()
+		index, err := patchIndex(last, arr.Length(), false)
+		if err != nil {
+			return nil, err
+		}
+		arr.Delete(index)
+	default:
+		return nil, NewJSONError(ErrPathNotFound, "path '"+pointer+"' not found", nil)
+	}
+	return doc, nil
+}
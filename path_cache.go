@@ -0,0 +1,137 @@
+package xyJson
+
+import "sync/atomic"
+
+// IPathCache 预编译路径的缓存，CompilePath按路径字符串查找和存放编译结果，实现必须是并发安全的
+// IPathCache caches compiled paths, CompilePath looks up and stores compiled paths by their path string;
+// implementations must be safe for concurrent use
+//
+// 实现可以是任意的淘汰策略或已有的缓存层，例如ristretto。实现了Clear()方法的缓存会被ClearPathCache清空，
+// 注册自定义段和过滤函数时也会调用它。编译结果是不可变的，可以在多个文档和协程之间共享；
+// 需要跨进程共享时可以存放MarshalText的结果，在其它进程中用UnmarshalText恢复
+// An implementation may use any eviction policy or an existing cache layer such as ristretto. A cache with a
+// Clear() method is cleared by ClearPathCache, which also runs when custom segments and filter functions are
+// registered. Compiled paths are immutable and can be shared between documents and goroutines; to share them
+// between processes, store the result of MarshalText and restore it with UnmarshalText in the other process
+//
+// 示例 Example:
+//
+//	type ristrettoPaths struct{ c *ristretto.Cache }
+//
+//	func (r ristrettoPaths) Get(path string) (*xyJson.CompiledPath, bool) {
+//		v, ok := r.c.Get(path)
+//		if !ok {
+//			return nil, false
+//		}
+//		return v.(*xyJson.CompiledPath), true
+//	}
+//	func (r ristrettoPaths) Put(path string, cp *xyJson.CompiledPath) { r.c.Set(path, cp, 1) }
+//	func (r ristrettoPaths) Len() int                                 { return int(r.c.Metrics.KeysAdded() - r.c.Metrics.KeysEvicted()) }
+//	func (r ristrettoPaths) Clear()                                   { r.c.Clear() }
+//
+//	xyJson.SetPathCache(ristrettoPaths{c: cache})
+type IPathCache interface {
+	// Get 返回缓存的编译结果
+	// Get returns the cached compiled path
+	Get(path string) (*CompiledPath, bool)
+
+	// Put 缓存编译结果，缓存可以随时丢弃它
+	// Put caches a compiled path, the cache may drop it at any time
+	Put(path string, compiled *CompiledPath)
+
+	// Len 返回缓存的路径数
+	// Len returns the number of cached paths
+	Len() int
+}
+
+// pathCacheSlot 包装SetPathCache设置的缓存以便原子地替换
+// pathCacheSlot wraps the cache set by SetPathCache so it can be swapped atomically
+type pathCacheSlot struct {
+	cache IPathCache
+}
+
+// customPathCache SetPathCache设置的缓存，nil表示使用内置缓存
+// customPathCache is the cache set by SetPathCache, nil means the built-in cache is used
+var customPathCache atomic.Pointer[pathCacheSlot]
+
+// SetPathCache 替换CompilePath使用的全局路径缓存，nil恢复内置缓存；已缓存的路径不会被迁移
+// SetPathCache replaces the global path cache used by CompilePath, nil restores the built-in cache; paths
+// already cached are not migrated
+func SetPathCache(cache IPathCache) {
+	if cache == nil {
+		customPathCache.Store(nil)
+		return
+	}
+	customPathCache.Store(&pathCacheSlot{cache: cache})
+}
+
+// GetPathCache 返回CompilePath当前使用的路径缓存
+// GetPathCache returns the path cache currently used by CompilePath
+func GetPathCache() IPathCache {
+	if slot := customPathCache.Load(); slot != nil {
+		return slot.cache
+	}
+	return globalPathCache
+}
+
+// Get 返回缓存的编译结果
+// Get returns the cached compiled path
+func (pc *pathCache) Get(path string) (*CompiledPath, bool) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	compiled, ok := pc.cache[path]
+	return compiled, ok
+}
+
+// Put 缓存编译结果，缓存已满时清空一半
+// Put caches a compiled path, half of the cache is cleared when it is full
+func (pc *pathCache) Put(path string, compiled *CompiledPath) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if len(pc.cache) >= pc.maxSize {
+		for k := range pc.cache {
+			delete(pc.cache, k)
+			if len(pc.cache) <= pc.maxSize/2 {
+				break
+			}
+		}
+	}
+	pc.cache[path] = compiled
+}
+
+// Len 返回缓存的路径数
+// Len returns the number of cached paths
+func (pc *pathCache) Len() int {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	return len(pc.cache)
+}
+
+// Clear 清空缓存
+// Clear empties the cache
+func (pc *pathCache) Clear() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.cache = make(map[string]*CompiledPath)
+}
+
+// MarshalText 返回原始路径字符串，用于在进程之间共享的缓存中存放编译结果
+// MarshalText returns the original path string, for storing compiled paths in caches shared between processes
+func (cp *CompiledPath) MarshalText() ([]byte, error) {
+	return []byte(cp.originalPath), nil
+}
+
+// UnmarshalText 不经过路径缓存重新编译MarshalText返回的路径，使用默认值工厂
+// UnmarshalText compiles a path returned by MarshalText again without going through the path cache, using the
+// default value factory
+func (cp *CompiledPath) UnmarshalText(text []byte) error {
+	compiled, err := compilePath(string(text), defaultFactory())
+	if err != nil {
+		return err
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.originalPath, cp.segments, cp.factory = compiled.originalPath, compiled.segments, compiled.factory
+	return nil
+}
@@ -0,0 +1,159 @@
+package xyJson
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// EscapeKey 将键名转换为可安全用于点号表示法的路径片段
+// EscapeKey converts a key into a path fragment that is safe to use with dot notation
+//
+// 由字母、数字、下划线和连字符组成的键原样返回；其他键用单引号包裹，
+// 并将其中的反斜杠和单引号转义，parsePath可以解析这种带引号的点号表示法
+// Keys made of letters, digits, underscores and hyphens are returned unchanged; other keys are wrapped
+// in single quotes with backslashes and single quotes escaped, a quoted dot notation parsePath accepts
+//
+// 示例 Example:
+//
+//	xyJson.EscapeKey("name")       // name
+//	xyJson.EscapeKey("a.b")        // 'a.b'
+//	xyJson.EscapeKey("it's")       // 'it\'s'
+//	path := "$.data." + xyJson.EscapeKey("weird[key]") // $.data.'weird[key]'
+func EscapeKey(key string) string {
+	if isSimplePathKey(key) {
+		return key
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(key) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '\\', '\'':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(key[i])
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// BuildPath 根据键名和索引构建JSONPath字符串
+// BuildPath builds a JSONPath string from keys and indices
+//
+// 参数 Parameters:
+//   - segments: 字符串作为键名，整数作为数组索引 / Strings are keys, integers are array indices
+//
+// 返回值 Returns:
+//   - string: 以$开头的JSONPath，特殊键名已转义 / JSONPath starting with $, special keys escaped
+//
+// 示例 Example:
+//
+//	path := xyJson.BuildPath("users", 0, "a.b") // $.users[0].'a.b'
+//	value, err := xyJson.Get(root, path)
+func BuildPath(segments ...interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, segment := range segments {
+		switch v := segment.(type) {
+		case string:
+			sb.WriteString(".")
+			sb.WriteString(EscapeKey(v))
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			sb.WriteString("[")
+			sb.WriteString(fmt.Sprintf("%d", v))
+			sb.WriteString("]")
+		default:
+			rv := reflect.ValueOf(segment)
+			if rv.IsValid() && rv.Kind() == reflect.String {
+				sb.WriteString(".")
+				sb.WriteString(EscapeKey(rv.String()))
+				continue
+			}
+			sb.WriteString(".")
+			sb.WriteString(EscapeKey(fmt.Sprintf("%v", segment)))
+		}
+	}
+	return sb.String()
+}
+
+// appendPathKey 将键追加到JSONPath
+// appendPathKey appends a key to a JSONPath
+func appendPathKey(path, key string) string {
+	return path + "." + EscapeKey(key)
+}
+
+// isSimplePathKey 检查键是否可以直接使用点号表示法
+// isSimplePathKey checks if a key can be used with plain dot notation
+func isSimplePathKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseQuotedKey 解析以引号开头的键名，支持\\和\'、\"转义
+// parseQuotedKey parses a key starting with a quote, supporting \\, \' and \" escapes
+//
+// 返回值 Returns:
+//   - string: 去除引号和转义后的键名 / Key with quotes and escapes removed
+//   - int: 结束引号之后的位置 / Position after the closing quote
+//   - error: 引号未闭合时返回错误 / Error when the quote is not closed
+func parseQuotedKey(path string, start int) (string, int, error) {
+	quote := path[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(path); i++ {
+		ch := path[i]
+		switch ch {
+		case '\\':
+			if i+1 >= len(path) {
+				return "", start, NewInvalidPathError(path, fmt.Errorf("dangling escape at position %d", i))
+			}
+			i++
+			sb.WriteByte(path[i])
+		case quote:
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return "", start, NewInvalidPathError(path, fmt.Errorf("unterminated quoted key starting at position %d", start))
+}
+
+// findClosingBracket 查找与start处'['匹配的']'，跳过嵌套的方括号，忽略引号内的字符
+// findClosingBracket finds the ']' matching the '[' at start, skipping nested brackets and ignoring characters
+// inside quotes
+//
+// 未找到时返回-1
+// Returns -1 when no closing bracket is found
+func findClosingBracket(path string, start int) int {
+	var quote byte
+	depth := 0
+	for i := start + 1; i < len(path); i++ {
+		ch := path[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']' && depth > 0:
+			depth--
+		case ch == ']':
+			return i
+		}
+	}
+	return -1
+}
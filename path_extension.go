@@ -0,0 +1,282 @@
+package xyJson
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ISegment 自定义路径段，将一个值映射为零个或多个结果值
+// ISegment is a custom path segment mapping one value to zero or more result values
+//
+// 自定义段写作[name(args)]，由RegisterSegment注册的工厂在编译路径时创建，编译后的路径可能被多个协程同时使用，
+// 因此Select必须是并发安全的且不能修改输入值
+// A custom segment is written [name(args)] and created when the path is compiled by the factory registered with
+// RegisterSegment. A compiled path may be used by several goroutines at once, so Select must be safe for
+// concurrent use and must not modify the input value
+type ISegment interface {
+	// Select 返回段作用于value的结果，没有结果时返回nil
+	// Select returns the results of applying the segment to value, nil when there are none
+	Select(value IValue) []IValue
+}
+
+// SegmentFactory 根据路径中的参数创建自定义段，返回的错误使路径编译失败
+// SegmentFactory creates a custom segment from the arguments in the path, a returned error fails compilation
+type SegmentFactory func(args []IValue) (ISegment, error)
+
+// IFilterEvaluator 过滤器求值器，判断数组元素是否通过过滤器
+// IFilterEvaluator is a filter evaluator deciding whether an array element passes the filter
+type IFilterEvaluator interface {
+	// Evaluate 报告value是否通过过滤器
+	// Evaluate reports whether value passes the filter
+	Evaluate(value IValue) bool
+}
+
+// FilterFunction 过滤函数，target为调用目标，目标不存在时为nil；args不能被修改
+// FilterFunction is a filter function, target is the call target and nil when it does not exist; args must not
+// be modified
+type FilterFunction func(target IValue, args []IValue) bool
+
+var (
+	// segmentFactories 已注册的自定义段
+	// segmentFactories holds the registered custom segments
+	segmentFactories = map[string]SegmentFactory{}
+	// filterFunctions 已注册的过滤函数
+	// filterFunctions holds the registered filter functions
+	filterFunctions = map[string]FilterFunction{}
+	extensionMutex  sync.RWMutex
+
+	// extensionName 自定义段和过滤函数的名称
+	// extensionName matches the name of a custom segment or filter function
+	extensionName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// segmentCall 匹配方括号内的name(args)
+	// segmentCall matches name(args) inside brackets
+	segmentCall = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\((.*)\)$`)
+	// filterCall 匹配过滤器中的!@.path.name(args)
+	// filterCall matches !@.path.name(args) in a filter
+	filterCall = regexp.MustCompile(`^(!?)@((?:[.\[][^()\s]*?)?)\.([A-Za-z_][A-Za-z0-9_]*)\((.*)\)$`)
+)
+
+// RegisterSegment 注册或替换自定义路径段，路径中的[name(args)]由factory创建；参数是逗号分隔的JSON值，字符串也可以使用单引号
+// RegisterSegment registers or replaces a custom path segment, [name(args)] in a path is created by factory; the
+// arguments are comma separated JSON values, strings may also use single quotes
+//
+// 注册会清空路径缓存，使替换立即对所有路径生效
+// Registering clears the path cache so a replacement takes effect for every path immediately
+//
+// 示例 Example:
+//
+//	// $.orders[top(3)] 选择数组的前3个元素
+//	// $.orders[top(3)] selects the first 3 elements of an array
+//	xyJson.RegisterSegment("top", func(args []xyJson.IValue) (xyJson.ISegment, error) {
+//		if len(args) != 1 {
+//			return nil, errors.New("top expects one argument")
+//		}
+//		n, ok := args[0].AsInt64OK()
+//		if !ok {
+//			return nil, errors.New("top expects an integer")
+//		}
+//		return topSegment(n), nil
+//	})
+func RegisterSegment(name string, factory SegmentFactory) error {
+	if !extensionName.MatchString(name) {
+		return NewInvalidOperationError("register segment", fmt.Sprintf("invalid segment name '%s'", name))
+	}
+	if factory == nil {
+		return NewNullPointerError("segment factory cannot be nil")
+	}
+
+	extensionMutex.Lock()
+	segmentFactories[name] = factory
+	extensionMutex.Unlock()
+	ClearPathCache()
+	return nil
+}
+
+// RegisterFilterFunction 注册或替换过滤函数，过滤器中的@.path.name(args)以path选择的值和参数调用fn，
+// 省略path时目标为元素本身，前缀!表示取反
+// RegisterFilterFunction registers or replaces a filter function, @.path.name(args) in a filter calls fn with
+// the value selected by path and the arguments; the target is the element itself when path is omitted, and a
+// leading ! negates the result
+//
+// 参数的写法与RegisterSegment相同，注册同样会清空路径缓存
+// Arguments are written as for RegisterSegment, and registering clears the path cache as well
+//
+// 示例 Example:
+//
+//	xyJson.RegisterFilterFunction("geoWithin", func(target xyJson.IValue, args []xyJson.IValue) bool {
+//		return inBox(target, args)
+//	})
+//	shops, _ := xyJson.GetAll(root, "$.shops[?(@.location.geoWithin([48.8,2.2],[48.9,2.4]))]")
+func RegisterFilterFunction(name string, fn FilterFunction) error {
+	if !extensionName.MatchString(name) {
+		return NewInvalidOperationError("register filter function", fmt.Sprintf("invalid function name '%s'", name))
+	}
+	if fn == nil {
+		return NewNullPointerError("filter function cannot be nil")
+	}
+
+	extensionMutex.Lock()
+	filterFunctions[name] = fn
+	extensionMutex.Unlock()
+	ClearPathCache()
+	return nil
+}
+
+// parseCustomSegment 解析方括号内的name(args)，ok为false表示不是自定义段的写法
+// parseCustomSegment parses name(args) inside brackets, ok is false when expr is not written as a custom segment
+func parseCustomSegment(expr string) (segment ISegment, ok bool, err error) {
+	match := segmentCall.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, false, nil
+	}
+
+	extensionMutex.RLock()
+	factory := segmentFactories[match[1]]
+	extensionMutex.RUnlock()
+	if factory == nil {
+		return nil, true, NewInvalidJSONError("unknown segment '"+match[1]+"'", nil)
+	}
+
+	args, err := parseExtensionArgs(match[2])
+	if err != nil {
+		return nil, true, err
+	}
+	if segment, err = factory(args); err != nil {
+		return nil, true, NewInvalidJSONError("invalid segment '"+expr+"'", err)
+	}
+	if segment == nil {
+		return nil, true, NewNullPointerError("segment factory for '" + match[1] + "' returned nil")
+	}
+	return segment, true, nil
+}
+
+// filterCallEvaluator 过滤函数调用的求值器
+// filterCallEvaluator evaluates a filter function call
+type filterCallEvaluator struct {
+	pq     *pathQuery
+	target []*pathSegment
+	fn     FilterFunction
+	args   []IValue
+	negate bool
+}
+
+// Evaluate 以目标值和参数调用过滤函数
+// Evaluate calls the filter function with the target value and the arguments
+func (e *filterCallEvaluator) Evaluate(value IValue) bool {
+	target := value
+	if len(e.target) > 0 {
+		target = nil
+		if results := e.pq.executeQuery(value, e.target, false); len(results) > 0 {
+			target = results[0]
+		}
+	}
+	return e.fn(target, e.args) != e.negate
+}
+
+// parseFilterCall 解析过滤器中的函数调用，ok为false表示不是函数调用的写法
+// parseFilterCall parses a function call in a filter, ok is false when expr is not written as a function call
+func (pq *pathQuery) parseFilterCall(expr string) (evaluator IFilterEvaluator, ok bool, err error) {
+	match := filterCall.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, false, nil
+	}
+
+	extensionMutex.RLock()
+	fn := filterFunctions[match[3]]
+	extensionMutex.RUnlock()
+	if fn == nil {
+		return nil, true, NewInvalidJSONError("unknown filter function '"+match[3]+"'", nil)
+	}
+
+	target, err := pq.parsePath("$" + match[2])
+	if err != nil {
+		return nil, true, err
+	}
+	args, err := parseExtensionArgs(match[4])
+	if err != nil {
+		return nil, true, err
+	}
+	return &filterCallEvaluator{
+		pq:     &pathQuery{factory: defaultFactory()},
+		target: target,
+		fn:     fn,
+		args:   args,
+		negate: match[1] == "!",
+	}, true, nil
+}
+
+// parseExtensionArgs 解析逗号分隔的参数，单引号字符串按路径键名的规则转义，其余参数按JSON解析
+// parseExtensionArgs parses comma separated arguments, single quoted strings are unescaped like path keys and
+// the other arguments are parsed as JSON
+func parseExtensionArgs(text string) ([]IValue, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+	var args []IValue
+	for _, raw := range splitExtensionArgs(text) {
+		raw = strings.TrimSpace(raw)
+		if strings.HasPrefix(raw, "'") {
+			key, next, err := parseQuotedKey(raw, 0)
+			if err == nil && next != len(raw) {
+				err = fmt.Errorf("unexpected characters after quoted string")
+			}
+			if err != nil {
+				return nil, NewInvalidJSONError("invalid argument: "+raw, err)
+			}
+			args = append(args, defaultFactory().CreateString(key))
+			continue
+		}
+		// 内部解析方法不会将参数错误记录为解析失败
+		// The internal parse method does not record argument errors as parse failures
+		arg, err := p.parse([]byte(raw))
+		if err != nil {
+			return nil, NewInvalidJSONError("invalid argument: "+raw, err)
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// splitExtensionArgs 在括号和引号之外的逗号处拆分参数
+// splitExtensionArgs splits arguments at commas outside brackets and quotes
+func splitExtensionArgs(text string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '[' || ch == '{':
+			depth++
+		case ch == ']' || ch == '}':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// selectCustom 对值应用自定义段，只需要一个结果时截断
+// selectCustom applies a custom segment to a value, truncating when only one result is needed
+func (pq *pathQuery) selectCustom(value IValue, segment *pathSegment, selectAll bool) []IValue {
+	pq.visit(1)
+	results := segment.Custom.Select(value)
+	if !selectAll && len(results) > 1 {
+		results = results[:1]
+	}
+	return results
+}
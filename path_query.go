@@ -2,6 +2,8 @@ package xyJson
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,6 +14,28 @@ import (
 // pathQuery implements JSONPath query functionality
 type pathQuery struct {
 	factory IValueFactory
+
+	// trace 调试模式下的执行统计，通常为nil
+	// trace holds execution statistics in debug mode, usually nil
+	trace *queryTrace
+
+	// yield 按QueryOptions.YieldInterval让出处理器的计数器，通常为nil
+	// yield is the counter yielding the processor by QueryOptions.YieldInterval, usually nil
+	yield *yielder
+
+	// tolerance 过滤器中数字比较的容差，来自QueryOptions.Tolerance，零值表示精确比较
+	// tolerance is the tolerance of numeric comparisons in filters, from QueryOptions.Tolerance, the zero value
+	// means exact comparison
+	tolerance NumericTolerance
+
+	// numberFormat 过滤器中字符串转换为数字时使用的格式，来自QueryOptions.NumberFormat，零值表示只接受strconv格式
+	// numberFormat is the format used when converting strings to numbers in filters, from
+	// QueryOptions.NumberFormat, the zero value accepts only the strconv syntax
+	numberFormat NumberFormat
+
+	// strictArrayIndex 非nil时取代全局配置的JSONPath.StrictArrayIndex，由Engine设置
+	// strictArrayIndex, when non-nil, replaces JSONPath.StrictArrayIndex of the global config, set by Engine
+	strictArrayIndex *bool
 }
 
 // pathSegment 路径段
@@ -23,6 +47,12 @@ type pathSegment struct {
 	Filter    *pathFilter
 	Wildcard  bool
 	Recursive bool
+	// Append 追加段[+]或[-1:]：读取和删除时指向最后一个元素，Set时在末尾追加
+	// Append marks a [+] or [-1:] segment: it refers to the last element on read and delete, and appends on Set
+	Append bool
+	// Custom 自定义段[name(args)]的实现，此时Key保存方括号内的原始表达式
+	// Custom implements a custom [name(args)] segment, Key then holds the original expression inside the brackets
+	Custom ISegment
 }
 
 // pathFilter 路径过滤器
@@ -32,6 +62,10 @@ type pathFilter struct {
 	Operator   string
 	Value      interface{}
 	Compiled   *regexp.Regexp
+	// Evaluator 过滤函数调用的求值器，非nil时Expression保存完整表达式，Operator和Value不使用
+	// Evaluator evaluates a filter function call; when non-nil Expression holds the whole expression and Operator
+	// and Value are unused
+	Evaluator IFilterEvaluator
 }
 
 // CompiledPath 预编译的JSONPath路径
@@ -41,6 +75,10 @@ type CompiledPath struct {
 	segments     []*pathSegment
 	factory      IValueFactory
 	mu           sync.RWMutex
+
+	// debug 非nil时每次查询后写出调试统计
+	// debug, when non-nil, receives debug statistics after every query
+	debug io.Writer
 }
 
 // pathCache 路径缓存
@@ -212,13 +250,24 @@ func CompilePathWithFactory(path string, factory IValueFactory) (*CompiledPath,
 	}
 
 	// 检查缓存
-	globalPathCache.mu.RLock()
-	if cached, exists := globalPathCache.cache[path]; exists {
-		globalPathCache.mu.RUnlock()
+	cache := GetPathCache()
+	if cached, exists := cache.Get(path); exists {
 		return cached, nil
 	}
-	globalPathCache.mu.RUnlock()
 
+	compiled, err := compilePath(path, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	// 添加到缓存
+	cache.Put(path, compiled)
+	return compiled, nil
+}
+
+// compilePath 不经过缓存编译路径
+// compilePath compiles a path without going through the cache
+func compilePath(path string, factory IValueFactory) (*CompiledPath, error) {
 	// 处理特殊情况：空路径或根路径
 	var segments []*pathSegment
 	var err error
@@ -236,29 +285,11 @@ func CompilePathWithFactory(path string, factory IValueFactory) (*CompiledPath,
 	}
 
 	// 创建预编译路径
-	compiled := &CompiledPath{
+	return &CompiledPath{
 		originalPath: path,
 		segments:     segments,
 		factory:      factory,
-	}
-
-	// 添加到缓存
-	globalPathCache.mu.Lock()
-	defer globalPathCache.mu.Unlock()
-
-	// 检查缓存大小限制
-	if len(globalPathCache.cache) >= globalPathCache.maxSize {
-		// 简单的LRU策略：清空一半缓存
-		for k := range globalPathCache.cache {
-			delete(globalPathCache.cache, k)
-			if len(globalPathCache.cache) <= globalPathCache.maxSize/2 {
-				break
-			}
-		}
-	}
-
-	globalPathCache.cache[path] = compiled
-	return compiled, nil
+	}, nil
 }
 
 // Query 使用预编译路径查询单个值
@@ -275,8 +306,7 @@ func (cp *CompiledPath) Query(root IValue) (IValue, error) {
 	cp.mu.RLock()
 	defer cp.mu.RUnlock()
 
-	pq := &pathQuery{factory: cp.factory}
-	results := pq.executeQuery(root, cp.segments, false)
+	results := cp.execute(root, false, NumericTolerance{})
 	if len(results) == 0 {
 		return nil, NewPathNotFoundError(cp.originalPath)
 	}
@@ -298,8 +328,7 @@ func (cp *CompiledPath) QueryAll(root IValue) ([]IValue, error) {
 	cp.mu.RLock()
 	defer cp.mu.RUnlock()
 
-	pq := &pathQuery{factory: cp.factory}
-	return pq.executeQuery(root, cp.segments, true), nil
+	return cp.execute(root, true, NumericTolerance{}), nil
 }
 
 // Set 使用预编译路径设置值
@@ -352,8 +381,7 @@ func (cp *CompiledPath) Exists(root IValue) bool {
 	cp.mu.RLock()
 	defer cp.mu.RUnlock()
 
-	pq := &pathQuery{factory: cp.factory}
-	results := pq.executeQuery(root, cp.segments, false)
+	results := cp.execute(root, false, NumericTolerance{})
 	return len(results) > 0
 }
 
@@ -371,8 +399,7 @@ func (cp *CompiledPath) Count(root IValue) int {
 	cp.mu.RLock()
 	defer cp.mu.RUnlock()
 
-	pq := &pathQuery{factory: cp.factory}
-	results := pq.executeQuery(root, cp.segments, true)
+	results := cp.execute(root, true, NumericTolerance{})
 	return len(results)
 }
 
@@ -382,24 +409,31 @@ func (cp *CompiledPath) Path() string {
 	return cp.originalPath
 }
 
-// ClearPathCache 清空路径缓存
-// ClearPathCache clears the path cache
+// ClearPathCache 清空内置路径缓存，SetPathCache设置的缓存实现了Clear方法时一并清空
+// ClearPathCache clears the built-in path cache, and the cache set by SetPathCache as well when it implements a
+// Clear method
 func ClearPathCache() {
-	globalPathCache.mu.Lock()
-	defer globalPathCache.mu.Unlock()
-	globalPathCache.cache = make(map[string]*CompiledPath)
+	globalPathCache.Clear()
+	if slot := customPathCache.Load(); slot != nil {
+		if cache, ok := slot.cache.(interface{ Clear() }); ok {
+			cache.Clear()
+		}
+	}
 }
 
-// GetPathCacheStats 获取路径缓存统计信息
-// GetPathCacheStats returns path cache statistics
+// GetPathCacheStats 获取当前路径缓存的统计信息，SetPathCache设置的缓存的maxSize为0
+// GetPathCacheStats returns statistics of the current path cache, maxSize is 0 for a cache set by SetPathCache
 func GetPathCacheStats() (size int, maxSize int) {
+	if slot := customPathCache.Load(); slot != nil {
+		return slot.cache.Len(), 0
+	}
 	globalPathCache.mu.RLock()
 	defer globalPathCache.mu.RUnlock()
 	return len(globalPathCache.cache), globalPathCache.maxSize
 }
 
-// SetPathCacheMaxSize 设置路径缓存最大大小
-// SetPathCacheMaxSize sets the maximum size of path cache
+// SetPathCacheMaxSize 设置内置路径缓存最大大小
+// SetPathCacheMaxSize sets the maximum size of the built-in path cache
 func SetPathCacheMaxSize(maxSize int) {
 	if maxSize <= 0 {
 		maxSize = DefaultPathCacheSize
@@ -445,6 +479,18 @@ func (pq *pathQuery) parsePath(path string) ([]*pathSegment, error) {
 							Recursive: true,
 							Wildcard:  true,
 						})
+					} else if path[i] == '\'' || path[i] == '"' {
+						// $..'property' 带引号的递归属性查找
+						key, next, err := parseQuotedKey(path, i)
+						if err != nil {
+							return nil, err
+						}
+						i = next
+						segments = append(segments, &pathSegment{
+							Type:      PropertySegmentType,
+							Key:       key,
+							Recursive: true,
+						})
 					} else {
 						// $..property 递归属性查找
 						start := i
@@ -481,6 +527,20 @@ func (pq *pathQuery) parsePath(path string) ([]*pathSegment, error) {
 				continue
 			}
 
+			// 带引号的属性名 .'a.b' 或 ."a.b"
+			if path[i] == '\'' || path[i] == '"' {
+				key, next, err := parseQuotedKey(path, i)
+				if err != nil {
+					return nil, err
+				}
+				i = next
+				segments = append(segments, &pathSegment{
+					Type: PropertySegmentType,
+					Key:  key,
+				})
+				continue
+			}
+
 			// 解析属性名
 			start := i
 			for i < len(path) && path[i] != '.' && path[i] != '[' {
@@ -527,11 +587,25 @@ func (pq *pathQuery) parseBracketExpression(path string, start int) (*pathSegmen
 		return nil, start, NewInvalidJSONError("expected '['", nil)
 	}
 
-	end := strings.Index(path[start:], "]")
+	// 带引号的键名，支持转义序列 ['a.b'] ["it's"] ['it\'s']
+	if start+1 < len(path) && (path[start+1] == '\'' || path[start+1] == '"') {
+		key, next, err := parseQuotedKey(path, start+1)
+		if err != nil {
+			return nil, start, err
+		}
+		if next >= len(path) || path[next] != ']' {
+			return nil, start, NewInvalidJSONError("expected ']' after quoted key", nil)
+		}
+		return &pathSegment{
+			Type: PropertySegmentType,
+			Key:  key,
+		}, next + 1, nil
+	}
+
+	end := findClosingBracket(path, start)
 	if end == -1 {
 		return nil, start, NewInvalidJSONError("unclosed bracket", nil)
 	}
-	end += start
 
 	expr := path[start+1 : end]
 	segment := &pathSegment{}
@@ -543,6 +617,23 @@ func (pq *pathQuery) parseBracketExpression(path string, start int) (*pathSegmen
 		return segment, end + 1, nil
 	}
 
+	// last关键字等价于-1
+	// The last keyword is equivalent to -1
+	if expr == "last" {
+		segment.Type = IndexSegmentType
+		segment.Index = -1
+		return segment, end + 1, nil
+	}
+
+	// 追加表达式[-1:]或[+]
+	// Append expression [-1:] or [+]
+	if expr == "-1:" || expr == "+" {
+		segment.Type = IndexSegmentType
+		segment.Index = -1
+		segment.Append = true
+		return segment, end + 1, nil
+	}
+
 	// 数字索引
 	if index, err := strconv.Atoi(expr); err == nil {
 		segment.Type = IndexSegmentType
@@ -570,6 +661,18 @@ func (pq *pathQuery) parseBracketExpression(path string, start int) (*pathSegmen
 		return segment, end + 1, nil
 	}
 
+	// 自定义段 name(args)
+	// Custom segment name(args)
+	if custom, ok, err := parseCustomSegment(expr); ok {
+		if err != nil {
+			return nil, start, err
+		}
+		segment.Type = CustomSegmentType
+		segment.Key = expr
+		segment.Custom = custom
+		return segment, end + 1, nil
+	}
+
 	// 切片表达式（简化实现）
 	if strings.Contains(expr, ":") {
 		// 暂时不支持切片，返回通配符
@@ -591,6 +694,15 @@ func (pq *pathQuery) parseFilter(expr string) (*pathFilter, error) {
 		expr = strings.TrimSpace(expr)
 	}
 
+	// 过滤函数调用 @.path.name(args)
+	// Filter function call @.path.name(args)
+	if evaluator, ok, err := pq.parseFilterCall(expr); ok {
+		if err != nil {
+			return nil, err
+		}
+		return &pathFilter{Expression: expr, Evaluator: evaluator}, nil
+	}
+
 	// 简化的过滤器解析
 	// 支持基本的比较操作：==, !=, <, >, <=, >=
 	operators := []string{"==", "!=", "<=", ">=", "<", ">"}
@@ -636,38 +748,61 @@ func (pq *pathQuery) executeQuery(root IValue, segments []*pathSegment, selectAl
 		return []IValue{root}
 	}
 
+	// 查询路径以虚拟路径开头时由虚拟路径求值
+	// Queries starting with a virtual path are evaluated through it
+	if root != nil {
+		if results, ok := pq.selectVirtual(root, segments, selectAll); ok {
+			return results
+		}
+	}
+
 	current := []IValue{root}
 
 	for _, segment := range segments {
 		var next []IValue
+		if pq.trace != nil {
+			pq.trace.visited = 0
+		}
 
 		for _, value := range current {
 			if value == nil {
 				continue
 			}
 
-			// 如果是递归下降，直接调用selectRecursive
-			if segment.Recursive {
-				next = append(next, pq.selectRecursive(value, segment, selectAll)...)
-			} else {
-				// 普通的路径段处理
-				switch segment.Type {
-				case PropertySegmentType:
-					next = append(next, pq.selectProperty(value, segment, selectAll)...)
-				case IndexSegmentType:
-					next = append(next, pq.selectIndex(value, segment, selectAll)...)
-				case FilterSegmentType:
-					next = append(next, pq.selectFilter(value, segment, selectAll)...)
-				}
-			}
+			next = append(next, pq.selectSegment(value, segment, selectAll)...)
 		}
 
+		if pq.trace != nil {
+			pq.trace.stats = append(pq.trace.stats, segmentStats{inputs: len(current), visited: pq.trace.visited, matched: len(next)})
+		}
 		current = next
 	}
 
 	return current
 }
 
+// selectSegment 对单个值应用一个路径段
+// selectSegment applies a single path segment to a value
+func (pq *pathQuery) selectSegment(value IValue, segment *pathSegment, selectAll bool) []IValue {
+	// 如果是递归下降，直接调用selectRecursive
+	if segment.Recursive {
+		return pq.selectRecursive(value, segment, selectAll)
+	}
+
+	// 普通的路径段处理
+	switch segment.Type {
+	case PropertySegmentType:
+		return pq.selectProperty(value, segment, selectAll)
+	case IndexSegmentType:
+		return pq.selectIndex(value, segment, selectAll)
+	case FilterSegmentType:
+		return pq.selectFilter(value, segment, selectAll)
+	case CustomSegmentType:
+		return pq.selectCustom(value, segment, selectAll)
+	}
+	return nil
+}
+
 // selectProperty 选择属性
 // selectProperty selects properties
 func (pq *pathQuery) selectProperty(value IValue, segment *pathSegment, selectAll bool) []IValue {
@@ -676,6 +811,7 @@ func (pq *pathQuery) selectProperty(value IValue, segment *pathSegment, selectAl
 		switch v := value.(type) {
 		case IObject:
 			var results []IValue
+			pq.visit(v.Size())
 			for _, key := range v.Keys() {
 				if val := v.Get(key); val != nil {
 					results = append(results, val)
@@ -687,6 +823,7 @@ func (pq *pathQuery) selectProperty(value IValue, segment *pathSegment, selectAl
 			return results
 		case IArray:
 			var results []IValue
+			pq.visit(v.Length())
 			for i := 0; i < v.Length(); i++ {
 				if val := v.Get(i); val != nil {
 					results = append(results, val)
@@ -700,6 +837,7 @@ func (pq *pathQuery) selectProperty(value IValue, segment *pathSegment, selectAl
 	} else {
 		// 具体属性名
 		if obj, ok := value.(IObject); ok {
+			pq.visit(1)
 			if val := obj.Get(segment.Key); val != nil {
 				return []IValue{val}
 			}
@@ -720,6 +858,7 @@ func (pq *pathQuery) selectIndex(value IValue, segment *pathSegment, selectAll b
 	if segment.Wildcard {
 		// 通配符：选择所有元素
 		var results []IValue
+		pq.visit(arr.Length())
 		for i := 0; i < arr.Length(); i++ {
 			if val := arr.Get(i); val != nil {
 				results = append(results, val)
@@ -731,11 +870,8 @@ func (pq *pathQuery) selectIndex(value IValue, segment *pathSegment, selectAll b
 		return results
 	} else {
 		// 具体索引
-		index := segment.Index
-		if index < 0 {
-			index = arr.Length() + index // 负索引
-		}
-		if index >= 0 && index < arr.Length() {
+		pq.visit(1)
+		if index, ok := normalizeIndex(segment.Index, arr.Length()); ok {
 			if val := arr.Get(index); val != nil {
 				return []IValue{val}
 			}
@@ -754,6 +890,7 @@ func (pq *pathQuery) selectFilter(value IValue, segment *pathSegment, selectAll
 	}
 
 	var results []IValue
+	pq.visit(arr.Length())
 	for i := 0; i < arr.Length(); i++ {
 		elem := arr.Get(i)
 		if elem != nil && pq.evaluateFilter(elem, segment.Filter) {
@@ -771,6 +908,7 @@ func (pq *pathQuery) selectFilter(value IValue, segment *pathSegment, selectAll
 // selectRecursive recursively selects values
 func (pq *pathQuery) selectRecursive(value IValue, segment *pathSegment, selectAll bool) []IValue {
 	var results []IValue
+	pq.visit(1)
 
 	// 首先检查当前节点是否匹配
 	if segment.Key != "" {
@@ -840,6 +978,9 @@ func (pq *pathQuery) evaluateFilter(value IValue, filter *pathFilter) bool {
 	if filter == nil {
 		return true
 	}
+	if filter.Evaluator != nil {
+		return filter.Evaluator.Evaluate(value)
+	}
 
 	// 获取要比较的值
 	var compareValue interface{}
@@ -892,6 +1033,19 @@ func (pq *pathQuery) valuesEqual(left, right interface{}) bool {
 		return false
 	}
 
+	// 指定数字格式后，任一侧为字符串时两侧都按格式转换，都是数字时按数值比较
+	// With a number format, when either side is a string both sides are converted with the format and compare
+	// numerically when both are numbers
+	_, leftString := left.(string)
+	_, rightString := right.(string)
+	if pq.numberFormat.DecimalSeparator != 0 && (leftString || rightString) {
+		if lf, ok := pq.toFloat64(left); ok {
+			if rf, ok := pq.toFloat64(right); ok {
+				return pq.tolerance.Equal(lf, rf)
+			}
+		}
+	}
+
 	// 类型转换和比较
 	switch l := left.(type) {
 	case string:
@@ -900,17 +1054,17 @@ func (pq *pathQuery) valuesEqual(left, right interface{}) bool {
 		}
 	case float64:
 		if r, ok := right.(float64); ok {
-			return l == r
+			return pq.tolerance.Equal(l, r)
 		}
 		if r, ok := right.(int64); ok {
-			return l == float64(r)
+			return pq.tolerance.Equal(l, float64(r))
 		}
 	case int64:
 		if r, ok := right.(int64); ok {
-			return l == r
+			return l == r || !pq.tolerance.isExact() && pq.tolerance.Equal(float64(l), float64(r))
 		}
 		if r, ok := right.(float64); ok {
-			return float64(l) == r
+			return pq.tolerance.Equal(float64(l), r)
 		}
 	case bool:
 		if r, ok := right.(bool); ok {
@@ -932,15 +1086,19 @@ func (pq *pathQuery) compareNumeric(left interface{}, operator string, right int
 		return false
 	}
 
+	if math.IsNaN(leftNum) || math.IsNaN(rightNum) {
+		return false
+	}
+	cmp := pq.tolerance.Compare(leftNum, rightNum)
 	switch operator {
 	case "<":
-		return leftNum < rightNum
+		return cmp < 0
 	case "<=":
-		return leftNum <= rightNum
+		return cmp <= 0
 	case ">":
-		return leftNum > rightNum
+		return cmp > 0
 	case ">=":
-		return leftNum >= rightNum
+		return cmp >= 0
 	default:
 		return false
 	}
@@ -957,6 +1115,9 @@ func (pq *pathQuery) toFloat64(value interface{}) (float64, bool) {
 	case int:
 		return float64(v), true
 	case string:
+		if normalized, ok := pq.numberFormat.normalize(v); ok {
+			v = normalized
+		}
 		if num, err := strconv.ParseFloat(v, 64); err == nil {
 			return num, true
 		}
@@ -1024,14 +1185,15 @@ func (pq *pathQuery) navigateSegment(value IValue, segment *pathSegment) (IValue
 		return nil, NewTypeMismatchError(ObjectValueType, value.Type(), "")
 	case IndexSegmentType:
 		if arr, ok := value.(IArray); ok {
-			index := segment.Index
-			if index < 0 {
-				index = arr.Length() + index
+			if segment.Append {
+				// 追加段总是指向新元素，由createIntermediatePath创建
+				// An append segment always refers to a new element created by createIntermediatePath
+				return nil, nil
 			}
-			if index >= 0 && index < arr.Length() {
+			if index, ok := normalizeIndex(segment.Index, arr.Length()); ok {
 				return arr.Get(index), nil
 			}
-			return nil, NewIndexOutOfRangeError(index, arr.Length(), "array index out of range")
+			return nil, NewIndexOutOfRangeError(segment.Index, arr.Length(), "array index out of range")
 		}
 		return nil, NewTypeMismatchError(ArrayValueType, value.Type(), "")
 	default:
@@ -1060,13 +1222,14 @@ func (pq *pathQuery) createIntermediatePath(parent IValue, current *pathSegment,
 		return nil, NewTypeMismatchError(ObjectValueType, parent.Type(), "")
 	case IndexSegmentType:
 		if arr, ok := parent.(IArray); ok {
-			// 扩展数组到所需大小
-			for arr.Length() <= current.Index {
-				if err := arr.Append(pq.factory.CreateNull()); err != nil {
-					return nil, err
-				}
+			if current.Append {
+				return newValue, arr.Append(newValue)
+			}
+			index, err := pq.growArray(arr, current.Index)
+			if err != nil {
+				return nil, err
 			}
-			return newValue, arr.Set(current.Index, newValue)
+			return newValue, arr.Set(index, newValue)
 		}
 		return nil, NewTypeMismatchError(ArrayValueType, parent.Type(), "")
 	default:
@@ -1085,15 +1248,12 @@ func (pq *pathQuery) setFinalValue(parent IValue, segment *pathSegment, value IV
 		return NewTypeMismatchError(ObjectValueType, parent.Type(), "")
 	case IndexSegmentType:
 		if arr, ok := parent.(IArray); ok {
-			index := segment.Index
-			if index < 0 {
-				index = arr.Length() + index
+			if segment.Append {
+				return arr.Append(value)
 			}
-			// 扩展数组到所需大小
-			for arr.Length() <= index {
-				if err := arr.Append(pq.factory.CreateNull()); err != nil {
-					return err
-				}
+			index, err := pq.growArray(arr, segment.Index)
+			if err != nil {
+				return err
 			}
 			return arr.Set(index, value)
 		}
@@ -1117,18 +1277,58 @@ func (pq *pathQuery) deleteFinalValue(parent IValue, segment *pathSegment) error
 		return NewTypeMismatchError(ObjectValueType, parent.Type(), "")
 	case IndexSegmentType:
 		if arr, ok := parent.(IArray); ok {
-			index := segment.Index
-			if index < 0 {
-				index = arr.Length() + index
-			}
-			if index >= 0 && index < arr.Length() {
+			if index, ok := normalizeIndex(segment.Index, arr.Length()); ok {
 				arr.Delete(index)
 				return nil
 			}
-			return NewIndexOutOfRangeError(index, arr.Length(), "array index out of range")
+			return NewIndexOutOfRangeError(segment.Index, arr.Length(), "array index out of range")
 		}
 		return NewTypeMismatchError(ArrayValueType, parent.Type(), "")
 	default:
 		return NewInvalidJSONError("unsupported segment type", nil)
 	}
 }
+
+// strictIndex 返回Set是否拒绝超出数组长度的索引
+// strictIndex reports whether Set rejects indices beyond the array length
+func (pq *pathQuery) strictIndex() bool {
+	if pq.strictArrayIndex != nil {
+		return *pq.strictArrayIndex
+	}
+	return GetGlobalConfig().JSONPath.StrictArrayIndex
+}
+
+// growArray 规范化索引并在需要时用null扩展数组，返回实际索引
+// growArray normalizes the index and pads the array with nulls when needed, returning the actual index
+//
+// 负索引从末尾计数且只能指向已有元素；启用StrictArrayIndex时，
+// 只允许覆盖已有元素或在末尾追加一个元素
+// Negative indices count from the end and may only refer to existing elements; with StrictArrayIndex
+// enabled, only existing elements may be overwritten or a single element appended at the end
+func (pq *pathQuery) growArray(arr IArray, index int) (int, error) {
+	if index < 0 {
+		normalized, ok := normalizeIndex(index, arr.Length())
+		if !ok {
+			return 0, NewIndexOutOfRangeError(index, arr.Length(), "array index out of range")
+		}
+		return normalized, nil
+	}
+	if index > arr.Length() && pq.strictIndex() {
+		return 0, NewIndexOutOfRangeError(index, arr.Length(), "array index out of range")
+	}
+	for arr.Length() <= index {
+		if err := arr.Append(pq.factory.CreateNull()); err != nil {
+			return 0, err
+		}
+	}
+	return index, nil
+}
+
+// normalizeIndex 将负索引转换为从头计数的索引并检查范围
+// normalizeIndex converts a negative index into an index from the start and checks the range
+func normalizeIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
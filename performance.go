@@ -17,6 +17,9 @@ type PerformanceMonitor struct {
 	serializeTime      int64 // 纳秒
 	allocCount         int64
 	allocBytes         int64
+	allocObjects       int64
+	allocSampleRate    int64
+	allocSampleCounter int64
 	gcCount            uint32
 	maxMemoryUsage     int64
 	currentMemoryUsage int64
@@ -36,6 +39,8 @@ type PerformanceStats struct {
 	TotalSerializeTime time.Duration `json:"total_serialize_time"`
 	AllocCount         int64         `json:"alloc_count"`
 	AllocBytes         int64         `json:"alloc_bytes"`
+	AllocObjects       int64         `json:"alloc_objects"`
+	AllocSampleRate    int64         `json:"alloc_sample_rate"`
 	GCCount            uint32        `json:"gc_count"`
 	MaxMemoryUsage     int64         `json:"max_memory_usage"`
 	CurrentMemoryUsage int64         `json:"current_memory_usage"`
@@ -64,11 +69,53 @@ func GetGlobalMonitor() *PerformanceMonitor {
 // NewPerformanceMonitor creates a new performance monitor
 func NewPerformanceMonitor() *PerformanceMonitor {
 	return &PerformanceMonitor{
-		lastResetTime: time.Now(),
-		enabled:       true,
+		lastResetTime:   time.Now(),
+		enabled:         true,
+		allocSampleRate: DefaultAllocSampleRate,
 	}
 }
 
+// SetAllocSampleRate 设置计时操作测量内存分配的采样率：0表示不测量，1表示测量每个操作，
+// n表示每n个操作测量一个，并将测得的分配量乘以n作为估算值
+// SetAllocSampleRate sets the sampling rate at which timed operations measure their allocations: 0 disables
+// the measurement, 1 measures every operation and n measures one operation in n, scaling the measured
+// allocations by n as an estimate
+//
+// 测量基于runtime.MemStats中TotalAlloc和Mallocs的差值，每次采样需要两次ReadMemStats（会短暂停止所有goroutine），
+// 并且统计的是整个进程的分配，并发操作时结果会偏大。负数按0处理
+// The measurement is the difference of TotalAlloc and Mallocs in runtime.MemStats, costing two ReadMemStats
+// calls (which briefly stop the world) per sample, and counts the allocations of the whole process, so
+// concurrent operations inflate the result. Negative rates are treated as 0
+//
+// 示例 Example:
+//
+//	xyJson.GetGlobalMonitor().SetAllocSampleRate(1) // 测量每次解析 measure every parse
+func (pm *PerformanceMonitor) SetAllocSampleRate(rate int) {
+	if rate < 0 {
+		rate = 0
+	}
+	atomic.StoreInt64(&pm.allocSampleRate, int64(rate))
+}
+
+// AllocSampleRate 返回当前的分配采样率
+// AllocSampleRate returns the current allocation sampling rate
+func (pm *PerformanceMonitor) AllocSampleRate() int {
+	return int(atomic.LoadInt64(&pm.allocSampleRate))
+}
+
+// sampleAlloc 判断下一个操作是否测量分配，返回采样率，0表示不测量
+// sampleAlloc decides whether the next operation measures its allocations, returning the rate or 0 when not
+func (pm *PerformanceMonitor) sampleAlloc() int64 {
+	rate := atomic.LoadInt64(&pm.allocSampleRate)
+	if rate <= 0 {
+		return 0
+	}
+	if rate > 1 && (atomic.AddInt64(&pm.allocSampleCounter, 1)-1)%rate != 0 {
+		return 0
+	}
+	return rate
+}
+
 // Enable 启用性能监控
 // Enable enables performance monitoring
 func (pm *PerformanceMonitor) Enable() {
@@ -96,35 +143,53 @@ func (pm *PerformanceMonitor) IsEnabled() bool {
 // RecordParse 记录解析操作
 // RecordParse records a parse operation
 func (pm *PerformanceMonitor) RecordParse(duration time.Duration, allocBytes int64) {
-	if !pm.IsEnabled() {
-		return
-	}
-
-	atomic.AddInt64(&pm.parseCount, 1)
-	atomic.AddInt64(&pm.parseTime, int64(duration))
-	if allocBytes > 0 {
-		atomic.AddInt64(&pm.allocCount, 1)
-		atomic.AddInt64(&pm.allocBytes, allocBytes)
-	}
-
-	pm.updateMemoryUsage()
+	pm.record(&pm.parseCount, &pm.parseTime, duration, allocBytes, 0, pm.sampleMemStats())
 }
 
 // RecordSerialize 记录序列化操作
 // RecordSerialize records a serialize operation
 func (pm *PerformanceMonitor) RecordSerialize(duration time.Duration, allocBytes int64) {
+	pm.record(&pm.serializeCount, &pm.serializeTime, duration, allocBytes, 0, pm.sampleMemStats())
+}
+
+// record 记录一次操作，m为操作结束时读取的内存统计，为nil时不更新内存使用情况
+// record records an operation, m being the memory statistics read at its end, memory usage is left as is when nil
+func (pm *PerformanceMonitor) record(count, total *int64, duration time.Duration, allocBytes, allocObjects int64, m *runtime.MemStats) {
 	if !pm.IsEnabled() {
 		return
 	}
 
-	atomic.AddInt64(&pm.serializeCount, 1)
-	atomic.AddInt64(&pm.serializeTime, int64(duration))
+	atomic.AddInt64(count, 1)
+	atomic.AddInt64(total, int64(duration))
 	if allocBytes > 0 {
 		atomic.AddInt64(&pm.allocCount, 1)
 		atomic.AddInt64(&pm.allocBytes, allocBytes)
 	}
+	if allocObjects > 0 {
+		atomic.AddInt64(&pm.allocObjects, allocObjects)
+	}
 
-	pm.updateMemoryUsage()
+	if m != nil {
+		pm.updateMemoryUsage(m)
+	}
+}
+
+// sampleMemStats 监控已启用且按分配采样率选中时读取内存统计，否则返回nil，避免每次记录都停止所有goroutine
+// sampleMemStats reads the memory statistics when monitoring is enabled and the allocation sampling rate picks
+// this record, returning nil otherwise so not every record stops the world
+func (pm *PerformanceMonitor) sampleMemStats() *runtime.MemStats {
+	if !pm.IsEnabled() || pm.sampleAlloc() == 0 {
+		return nil
+	}
+	return readMemStats()
+}
+
+// readMemStats 读取当前的内存统计
+// readMemStats reads the current memory statistics
+func readMemStats() *runtime.MemStats {
+	m := new(runtime.MemStats)
+	runtime.ReadMemStats(m)
+	return m
 }
 
 // RecordError 记录错误
@@ -139,10 +204,7 @@ func (pm *PerformanceMonitor) RecordError() {
 
 // updateMemoryUsage 更新内存使用情况
 // updateMemoryUsage updates memory usage
-func (pm *PerformanceMonitor) updateMemoryUsage() {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
+func (pm *PerformanceMonitor) updateMemoryUsage(m *runtime.MemStats) {
 	currentUsage := int64(m.Alloc)
 	atomic.StoreInt64(&pm.currentMemoryUsage, currentUsage)
 
@@ -189,6 +251,8 @@ func (pm *PerformanceMonitor) GetStats() PerformanceStats {
 		TotalSerializeTime: totalSerializeTime,
 		AllocCount:         atomic.LoadInt64(&pm.allocCount),
 		AllocBytes:         atomic.LoadInt64(&pm.allocBytes),
+		AllocObjects:       atomic.LoadInt64(&pm.allocObjects),
+		AllocSampleRate:    atomic.LoadInt64(&pm.allocSampleRate),
 		GCCount:            atomic.LoadUint32(&pm.gcCount),
 		MaxMemoryUsage:     atomic.LoadInt64(&pm.maxMemoryUsage),
 		CurrentMemoryUsage: atomic.LoadInt64(&pm.currentMemoryUsage),
@@ -210,6 +274,8 @@ func (pm *PerformanceMonitor) Reset() {
 	atomic.StoreInt64(&pm.serializeTime, 0)
 	atomic.StoreInt64(&pm.allocCount, 0)
 	atomic.StoreInt64(&pm.allocBytes, 0)
+	atomic.StoreInt64(&pm.allocObjects, 0)
+	atomic.StoreInt64(&pm.allocSampleCounter, 0)
 	atomic.StoreUint32(&pm.gcCount, 0)
 	atomic.StoreInt64(&pm.maxMemoryUsage, 0)
 	atomic.StoreInt64(&pm.currentMemoryUsage, 0)
@@ -220,50 +286,49 @@ func (pm *PerformanceMonitor) Reset() {
 // TimedOperation 计时操作包装器
 // TimedOperation is a wrapper for timed operations
 type TimedOperation struct {
-	monitor   *PerformanceMonitor
-	startTime time.Time
-	startMem  int64
-	opType    string
+	monitor         *PerformanceMonitor
+	startTime       time.Time
+	startTotalAlloc uint64
+	startMallocs    uint64
+	sampleRate      int64
+	opType          string
 }
 
 // StartParseTimer 开始解析计时
 // StartParseTimer starts a parse timer
 func (pm *PerformanceMonitor) StartParseTimer() *TimedOperation {
-	if !pm.IsEnabled() {
-		return nil
-	}
-
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	return &TimedOperation{
-		monitor:   pm,
-		startTime: time.Now(),
-		startMem:  int64(m.Alloc),
-		opType:    "parse",
-	}
+	return pm.startTimer("parse")
 }
 
 // StartSerializeTimer 开始序列化计时
 // StartSerializeTimer starts a serialize timer
 func (pm *PerformanceMonitor) StartSerializeTimer() *TimedOperation {
+	return pm.startTimer("serialize")
+}
+
+// startTimer 开始计时，按采样率决定是否测量内存分配
+// startTimer starts a timer, deciding by the sampling rate whether allocations are measured
+func (pm *PerformanceMonitor) startTimer(opType string) *TimedOperation {
 	if !pm.IsEnabled() {
 		return nil
 	}
 
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	return &TimedOperation{
-		monitor:   pm,
-		startTime: time.Now(),
-		startMem:  int64(m.Alloc),
-		opType:    "serialize",
+	to := &TimedOperation{
+		monitor:    pm,
+		sampleRate: pm.sampleAlloc(),
+		opType:     opType,
+	}
+	if to.sampleRate > 0 {
+		m := readMemStats()
+		to.startTotalAlloc = m.TotalAlloc
+		to.startMallocs = m.Mallocs
 	}
+	to.startTime = time.Now()
+	return to
 }
 
-// End 结束计时
-// End ends the timing
+// End 结束计时，采样的操作同时记录估算的分配字节数和对象数
+// End ends the timing, sampled operations also record their estimated allocated bytes and objects
 func (to *TimedOperation) End() {
 	if to == nil || to.monitor == nil {
 		return
@@ -271,18 +336,20 @@ func (to *TimedOperation) End() {
 
 	duration := time.Since(to.startTime)
 
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	allocBytes := int64(m.Alloc) - to.startMem
-	if allocBytes < 0 {
-		allocBytes = 0
+	var allocBytes, allocObjects int64
+	var m *runtime.MemStats
+	if to.sampleRate > 0 {
+		m = readMemStats()
+		allocBytes = int64(m.TotalAlloc-to.startTotalAlloc) * to.sampleRate
+		allocObjects = int64(m.Mallocs-to.startMallocs) * to.sampleRate
 	}
 
+	pm := to.monitor
 	switch to.opType {
 	case "parse":
-		to.monitor.RecordParse(duration, allocBytes)
+		pm.record(&pm.parseCount, &pm.parseTime, duration, allocBytes, allocObjects, m)
 	case "serialize":
-		to.monitor.RecordSerialize(duration, allocBytes)
+		pm.record(&pm.serializeCount, &pm.serializeTime, duration, allocBytes, allocObjects, m)
 	}
 }
 
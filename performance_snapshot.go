@@ -0,0 +1,106 @@
+package xyJson
+
+import "time"
+
+// StatsSnapshot 某一时刻性能统计的不可变快照
+// StatsSnapshot is an immutable snapshot of the performance statistics at a point in time
+type StatsSnapshot struct {
+	stats   PerformanceStats
+	takenAt time.Time
+	resetAt time.Time
+}
+
+// StatsDelta 两个快照之间的统计差值
+// StatsDelta is the difference of the statistics between two snapshots
+type StatsDelta struct {
+	ParseCount         int64         `json:"parse_count"`
+	SerializeCount     int64         `json:"serialize_count"`
+	AvgParseTime       time.Duration `json:"avg_parse_time"`
+	AvgSerializeTime   time.Duration `json:"avg_serialize_time"`
+	TotalParseTime     time.Duration `json:"total_parse_time"`
+	TotalSerializeTime time.Duration `json:"total_serialize_time"`
+	AllocCount         int64         `json:"alloc_count"`
+	AllocBytes         int64         `json:"alloc_bytes"`
+	AllocObjects       int64         `json:"alloc_objects"`
+	GCCount            uint32        `json:"gc_count"`
+	ErrorCount         int64         `json:"error_count"`
+	Elapsed            time.Duration `json:"elapsed"`
+
+	// Reset 两个快照之间统计被重置过，此时差值只包含重置之后的部分
+	// Reset reports that the statistics were reset between the snapshots, the delta then only covers the part
+	// after the reset
+	Reset bool `json:"reset"`
+}
+
+// Snapshot 获取当前统计的不可变快照，不会重置计数器
+// Snapshot takes an immutable snapshot of the current statistics without resetting the counters
+//
+// 配合Delta可以计算一段时间内的开销而不影响依赖全局计数器的其它组件。
+// 计数器是全局的，差值包含这段时间内所有goroutine的操作
+// Combined with Delta it measures the cost of a time span without disturbing other components that rely on
+// the global counters. The counters are global, so the delta includes operations of every goroutine during
+// the span
+//
+// 示例 Example:
+//
+//	before := xyJson.GetGlobalMonitor().Snapshot()
+//	next.ServeHTTP(w, r)
+//	cost := xyJson.Delta(before, xyJson.GetGlobalMonitor().Snapshot())
+//	log.Printf("parses=%d parse_time=%s", cost.ParseCount, cost.TotalParseTime)
+func (pm *PerformanceMonitor) Snapshot() StatsSnapshot {
+	stats := pm.GetStats()
+
+	pm.mu.RLock()
+	resetAt := pm.lastResetTime
+	pm.mu.RUnlock()
+
+	return StatsSnapshot{stats: stats, takenAt: time.Now(), resetAt: resetAt}
+}
+
+// Stats 返回快照中的统计信息
+// Stats returns the statistics in the snapshot
+func (s StatsSnapshot) Stats() PerformanceStats {
+	return s.stats
+}
+
+// TakenAt 返回快照的时间
+// TakenAt returns when the snapshot was taken
+func (s StatsSnapshot) TakenAt() time.Time {
+	return s.takenAt
+}
+
+// Delta 计算从快照a到快照b的统计差值
+// Delta computes the difference of the statistics from snapshot a to snapshot b
+//
+// 两个快照之间统计被重置过时，差值取b中重置之后的计数并设置Reset
+// When the statistics were reset between the snapshots, the delta takes the counts after the reset from b and
+// sets Reset
+func Delta(a, b StatsSnapshot) StatsDelta {
+	delta := StatsDelta{Elapsed: b.takenAt.Sub(a.takenAt)}
+
+	base := a.stats
+	if !a.resetAt.Equal(b.resetAt) {
+		base = PerformanceStats{}
+		delta.Reset = true
+	}
+
+	delta.ParseCount = b.stats.ParseCount - base.ParseCount
+	delta.SerializeCount = b.stats.SerializeCount - base.SerializeCount
+	delta.TotalParseTime = b.stats.TotalParseTime - base.TotalParseTime
+	delta.TotalSerializeTime = b.stats.TotalSerializeTime - base.TotalSerializeTime
+	delta.AllocCount = b.stats.AllocCount - base.AllocCount
+	delta.AllocBytes = b.stats.AllocBytes - base.AllocBytes
+	delta.AllocObjects = b.stats.AllocObjects - base.AllocObjects
+	delta.ErrorCount = b.stats.ErrorCount - base.ErrorCount
+	if b.stats.GCCount >= base.GCCount {
+		delta.GCCount = b.stats.GCCount - base.GCCount
+	}
+
+	if delta.ParseCount > 0 {
+		delta.AvgParseTime = delta.TotalParseTime / time.Duration(delta.ParseCount)
+	}
+	if delta.SerializeCount > 0 {
+		delta.AvgSerializeTime = delta.TotalSerializeTime / time.Duration(delta.SerializeCount)
+	}
+	return delta
+}
@@ -0,0 +1,60 @@
+package xyJson
+
+import (
+	"sync/atomic"
+)
+
+// Warm 预分配nValues个标量值、nObjects个对象和nArrays个数组放入池中，使部署后的第一波流量不必承担分配高峰
+// Warm preallocates nValues scalar values, nObjects objects and nArrays arrays into the pool, so the first
+// traffic burst after a deploy does not pay the allocation spike
+//
+// 每种数量受MaxPoolSize限制，负数按0处理，池未启用时不做任何事。预热的对象不计入TotalAllocated，
+// 进度通过PoolStats的WarmTarget和WarmCompleted报告，可以在另一个协程中调用。
+// 池基于sync.Pool，未使用的对象在两次GC后会被回收，因此预热应在流量到来前不久进行
+// Each count is capped at MaxPoolSize and negative counts are treated as 0, a disabled pool does nothing.
+// Warmed objects are not counted in TotalAllocated, the progress is reported by WarmTarget and WarmCompleted
+// of PoolStats, and Warm may be called from another goroutine. The pool is backed by sync.Pool, which
+// reclaims unused objects after two garbage collections, so warm-up should happen shortly before traffic
+// arrives
+//
+// 示例 Example:
+//
+//	pool := xyJson.NewObjectPool()
+//	go pool.Warm(10000, 2000, 2000)
+func (p *objectPool) Warm(nValues, nObjects, nArrays int) {
+	if !p.enabled {
+		return
+	}
+
+	nValues = p.warmCount(nValues)
+	nObjects = p.warmCount(nObjects)
+	nArrays = p.warmCount(nArrays)
+	atomic.AddInt64(&p.stats.warmTarget, int64(nValues+nObjects+nArrays))
+	p.used.Store(true)
+	set := p.pools.Load()
+
+	for i := 0; i < nValues; i++ {
+		set.valuePool.Put(&scalarValue{})
+		atomic.AddInt64(&p.stats.warmCompleted, 1)
+	}
+	for i := 0; i < nObjects; i++ {
+		set.objectPool.Put(NewObject())
+		atomic.AddInt64(&p.stats.warmCompleted, 1)
+	}
+	for i := 0; i < nArrays; i++ {
+		set.arrayPool.Put(NewArray())
+		atomic.AddInt64(&p.stats.warmCompleted, 1)
+	}
+}
+
+// warmCount 返回受MaxPoolSize限制的预热数量
+// warmCount returns the warm-up count capped at MaxPoolSize
+func (p *objectPool) warmCount(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if p.maxPoolSize > 0 && n > p.maxPoolSize {
+		return p.maxPoolSize
+	}
+	return n
+}
@@ -0,0 +1,791 @@
+package xyJson
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Predicate 编译后的布尔谓词表达式，创建后不可修改，可以被多个goroutine并发使用
+// Predicate is a compiled boolean predicate expression, immutable once compiled and safe for concurrent use
+type Predicate struct {
+	expr string
+	root predicateNode
+}
+
+var (
+	// predicateCache EvalPredicate使用的编译缓存
+	// predicateCache is the compile cache used by EvalPredicate
+	predicateCache = make(map[string]*Predicate)
+	predicateMutex sync.RWMutex
+)
+
+// CompilePredicate 编译谓词表达式，用于对多个文档重复求值
+// CompilePredicate compiles a predicate expression for evaluating it repeatedly against many documents
+//
+// 语法 Syntax:
+//   - 路径 Paths: $.user.age、$.items[0].sku、$['a b']，取第一个匹配值 / the first match is used
+//   - 字面量 Literals: 数字 numbers, 'single' 或 "double" 引号字符串 quoted strings, true, false, null
+//   - 比较 Comparisons: == != < <= > >=
+//   - 逻辑 Logic: && || ! 和括号 and parentheses
+//   - 函数 Functions: exists(path), count(path), sum(path), min(path), max(path), length(x), lower(x),
+//     upper(x), contains(x, y), startsWith(s, prefix), endsWith(s, suffix), matches(s, 'regexp')
+//
+// 数字按数值比较，字符串按字典序比较，对象和数组按内容判断相等。不存在的路径与任何值（包括null）都不相等，
+// 检查字段是否存在应使用exists()。只有布尔值true被视为真，其它值和不存在的路径都为假。
+// count、sum、min和max作用于路径的所有匹配值，sum、min和max忽略非数字
+// Numbers compare numerically, strings lexicographically, and objects and arrays are equal by content.
+// Missing paths are unequal to every value, null included; use exists() to test for a field. Only the
+// boolean true counts as true, every other value and missing paths are false. count, sum, min and max
+// apply to every match of their path, sum, min and max skip non-numbers
+//
+// 示例 Example:
+//
+//	adult, err := xyJson.CompilePredicate(`$.user.age >= 18 && exists($.user.email)`)
+//	if err != nil {
+//		return err
+//	}
+//	for _, doc := range docs {
+//		if ok, _ := adult.Eval(doc); ok {
+//			route(doc)
+//		}
+//	}
+func CompilePredicate(expr string) (*Predicate, error) {
+	p := &predicateParser{expr: expr}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos < len(expr) {
+		return nil, p.fail("unexpected " + strconv.Quote(string(expr[p.pos])))
+	}
+	return &Predicate{expr: expr, root: root}, nil
+}
+
+// MustCompilePredicate 编译谓词表达式，失败时panic
+// MustCompilePredicate compiles a predicate expression, panics on failure
+func MustCompilePredicate(expr string) *Predicate {
+	predicate, err := CompilePredicate(expr)
+	if err != nil {
+		panic(err)
+	}
+	return predicate
+}
+
+// EvalPredicate 对文档求值谓词表达式，编译结果会被缓存
+// EvalPredicate evaluates a predicate expression against a document, caching the compiled form
+//
+// 示例 Example:
+//
+//	ok, err := xyJson.EvalPredicate(doc, `$.user.age >= 18 && exists($.user.email)`)
+func EvalPredicate(root IValue, expr string) (_ bool, err error) {
+	defer recoverPanic("eval predicate", &err)
+
+	predicateMutex.RLock()
+	predicate, exists := predicateCache[expr]
+	predicateMutex.RUnlock()
+
+	if !exists {
+		if predicate, err = CompilePredicate(expr); err != nil {
+			return false, err
+		}
+		predicateMutex.Lock()
+		if len(predicateCache) < DefaultPathCacheSize {
+			predicateCache[expr] = predicate
+		}
+		predicateMutex.Unlock()
+	}
+	return predicate.Eval(root)
+}
+
+// PredicateOptions 谓词求值选项
+// PredicateOptions holds the options of predicate evaluation
+type PredicateOptions struct {
+	// Tolerance 数字比较的容差，同时用于表达式中路径的过滤器；零值表示精确比较
+	// Tolerance is the tolerance of numeric comparisons, also used by the filters of paths in the expression;
+	// the zero value means exact comparison
+	Tolerance NumericTolerance
+}
+
+// DefaultPredicateOptions 返回默认的谓词求值选项（精确比较）
+// DefaultPredicateOptions returns the default predicate evaluation options (exact comparison)
+func DefaultPredicateOptions() *PredicateOptions {
+	return &PredicateOptions{
+		Tolerance: NumericTolerance{},
+	}
+}
+
+// Eval 对文档求值
+// Eval evaluates the predicate against a document
+func (p *Predicate) Eval(root IValue) (bool, error) {
+	return p.EvalWithOptions(root, nil)
+}
+
+// EvalWithOptions 使用指定选项对文档求值，options为nil时使用默认选项
+// EvalWithOptions evaluates the predicate against a document with the specified options, nil options means
+// the defaults
+//
+// 示例 Example:
+//
+//	ok, err := predicate.EvalWithOptions(doc, &xyJson.PredicateOptions{
+//		Tolerance: xyJson.NumericTolerance{Absolute: 1e-9},
+//	})
+func (p *Predicate) EvalWithOptions(root IValue, options *PredicateOptions) (bool, error) {
+	if root == nil {
+		return false, NewNullPointerError("root cannot be nil")
+	}
+	if options == nil {
+		options = DefaultPredicateOptions()
+	}
+	value, err := p.root.eval(&predicateEnv{root: root, tolerance: options.Tolerance})
+	if err != nil {
+		return false, err
+	}
+	return predicateTruthy(value), nil
+}
+
+// String 返回原始表达式
+// String returns the original expression
+func (p *Predicate) String() string {
+	return p.expr
+}
+
+// predicateEnv 单次求值的文档和选项
+// predicateEnv holds the document and options of a single evaluation
+type predicateEnv struct {
+	root      IValue
+	tolerance NumericTolerance
+}
+
+// query 按求值选项查询路径的匹配值，selectAll为false时最多返回一个
+// query queries the matches of a path with the evaluation options, returning at most one when selectAll is false
+func (env *predicateEnv) query(path *CompiledPath, selectAll bool) []IValue {
+	if path.originalPath == "" || path.originalPath == "$" {
+		return []IValue{env.root}
+	}
+
+	path.mu.RLock()
+	defer path.mu.RUnlock()
+	return path.execute(env.root, selectAll, env.tolerance)
+}
+
+// predicateNode 表达式树节点，求值结果为nil表示不存在
+// predicateNode is an expression tree node, a nil result means missing
+type predicateNode interface {
+	eval(env *predicateEnv) (IValue, error)
+}
+
+// literalNode 字面量
+// literalNode is a literal
+type literalNode struct {
+	value IValue
+}
+
+func (n *literalNode) eval(*predicateEnv) (IValue, error) {
+	return n.value, nil
+}
+
+// pathNode 路径，取第一个匹配值
+// pathNode is a path, yielding its first match
+type pathNode struct {
+	path *CompiledPath
+}
+
+func (n *pathNode) eval(env *predicateEnv) (IValue, error) {
+	if matches := env.query(n.path, false); len(matches) > 0 {
+		return matches[0], nil
+	}
+	return nil, nil
+}
+
+// notNode 逻辑非
+// notNode is a logical not
+type notNode struct {
+	operand predicateNode
+}
+
+func (n *notNode) eval(env *predicateEnv) (IValue, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return CreateBool(!predicateTruthy(value)), nil
+}
+
+// logicalNode 短路求值的&&和||
+// logicalNode is a short-circuiting && or ||
+type logicalNode struct {
+	and         bool
+	left, right predicateNode
+}
+
+func (n *logicalNode) eval(env *predicateEnv) (IValue, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if predicateTruthy(left) != n.and {
+		return CreateBool(!n.and), nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return CreateBool(predicateTruthy(right)), nil
+}
+
+// compareNode 比较
+// compareNode is a comparison
+type compareNode struct {
+	operator    string
+	left, right predicateNode
+}
+
+func (n *compareNode) eval(env *predicateEnv) (IValue, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return CreateBool(predicateCompare(left, n.operator, right, env.tolerance)), nil
+}
+
+// callNode 函数调用；路径函数的参数为路径的所有匹配值，其它函数的参数为各参数的值
+// callNode is a function call; path functions receive every match of their path, other functions receive
+// the value of each argument
+type callNode struct {
+	fn    *predicateFunc
+	args  []predicateNode
+	regex *regexp.Regexp
+}
+
+func (n *callNode) eval(env *predicateEnv) (IValue, error) {
+	if n.fn.path {
+		return n.fn.call(env, env.query(n.args[0].(*pathNode).path, true), nil), nil
+	}
+
+	args := make([]IValue, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return n.fn.call(env, args, n.regex), nil
+}
+
+// predicateFunc 内置函数
+// predicateFunc is a built-in function
+type predicateFunc struct {
+	// path 参数必须是路径，函数接收所有匹配值
+	// path means the argument must be a path and the function receives every match
+	path bool
+	// arity 参数个数
+	// arity is the number of arguments
+	arity int
+	call  func(env *predicateEnv, args []IValue, regex *regexp.Regexp) IValue
+}
+
+// predicateFuncs 内置函数表
+// predicateFuncs is the table of built-in functions
+var predicateFuncs = map[string]*predicateFunc{
+	"exists": {path: true, arity: 1, call: func(_ *predicateEnv, matches []IValue, _ *regexp.Regexp) IValue {
+		return CreateBool(len(matches) > 0)
+	}},
+	"count": {path: true, arity: 1, call: func(_ *predicateEnv, matches []IValue, _ *regexp.Regexp) IValue {
+		return CreateNumber(len(matches))
+	}},
+	"sum": {path: true, arity: 1, call: func(_ *predicateEnv, matches []IValue, _ *regexp.Regexp) IValue {
+		sum := 0.0
+		for _, match := range matches {
+			if f, ok := match.AsFloat64OK(); ok {
+				sum += f
+			}
+		}
+		return CreateNumber(sum)
+	}},
+	"min": {path: true, arity: 1, call: func(_ *predicateEnv, matches []IValue, _ *regexp.Regexp) IValue {
+		return predicateExtreme(matches, math.Min)
+	}},
+	"max": {path: true, arity: 1, call: func(_ *predicateEnv, matches []IValue, _ *regexp.Regexp) IValue {
+		return predicateExtreme(matches, math.Max)
+	}},
+	"length": {arity: 1, call: func(_ *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		switch v := args[0].(type) {
+		case IObject:
+			return CreateNumber(v.Size())
+		case IArray:
+			return CreateNumber(v.Length())
+		}
+		if s, ok := predicateString(args[0]); ok {
+			return CreateNumber(utf8.RuneCountInString(s))
+		}
+		return nil
+	}},
+	"lower": {arity: 1, call: func(_ *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		if s, ok := predicateString(args[0]); ok {
+			return CreateString(strings.ToLower(s))
+		}
+		return nil
+	}},
+	"upper": {arity: 1, call: func(_ *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		if s, ok := predicateString(args[0]); ok {
+			return CreateString(strings.ToUpper(s))
+		}
+		return nil
+	}},
+	"contains": {arity: 2, call: func(env *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		if arr, ok := args[0].(IArray); ok && args[0].Type() == ArrayValueType {
+			found := false
+			arr.Range(func(_ int, element IValue) bool {
+				found = predicateEqual(element, args[1], env.tolerance)
+				return !found
+			})
+			return CreateBool(found)
+		}
+		s, ok1 := predicateString(args[0])
+		sub, ok2 := predicateString(args[1])
+		return CreateBool(ok1 && ok2 && strings.Contains(s, sub))
+	}},
+	"startsWith": {arity: 2, call: func(_ *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		s, ok1 := predicateString(args[0])
+		prefix, ok2 := predicateString(args[1])
+		return CreateBool(ok1 && ok2 && strings.HasPrefix(s, prefix))
+	}},
+	"endsWith": {arity: 2, call: func(_ *predicateEnv, args []IValue, _ *regexp.Regexp) IValue {
+		s, ok1 := predicateString(args[0])
+		suffix, ok2 := predicateString(args[1])
+		return CreateBool(ok1 && ok2 && strings.HasSuffix(s, suffix))
+	}},
+	"matches": {arity: 2, call: func(_ *predicateEnv, args []IValue, regex *regexp.Regexp) IValue {
+		s, ok := predicateString(args[0])
+		return CreateBool(ok && regex.MatchString(s))
+	}},
+}
+
+// predicateExtreme 返回匹配值中数字的最小值或最大值，没有数字时为nil
+// predicateExtreme returns the minimum or maximum of the numeric matches, nil when there are none
+func predicateExtreme(matches []IValue, pick func(a, b float64) float64) IValue {
+	var result float64
+	found := false
+	for _, match := range matches {
+		if f, ok := match.AsFloat64OK(); ok {
+			if found {
+				result = pick(result, f)
+			} else {
+				result, found = f, true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return CreateNumber(result)
+}
+
+// predicateString 返回字符串值
+// predicateString returns the string value
+func predicateString(value IValue) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	return value.AsStringOK()
+}
+
+// predicateTruthy 只有布尔值true为真
+// predicateTruthy treats only the boolean true as true
+func predicateTruthy(value IValue) bool {
+	if value == nil {
+		return false
+	}
+	b, ok := value.AsBoolOK()
+	return ok && b
+}
+
+// predicateEqual 判断两个值是否相等，数字按数值比较，不存在的值与任何值都不相等
+// predicateEqual checks whether two values are equal, numbers numerically; a missing value equals nothing
+func predicateEqual(left, right IValue, tolerance NumericTolerance) bool {
+	if left == nil || right == nil {
+		return false
+	}
+	if l, ok := left.AsFloat64OK(); ok {
+		r, ok := right.AsFloat64OK()
+		return ok && tolerance.Equal(l, r)
+	}
+	return left.Type() == right.Type() && left.Equals(right)
+}
+
+// predicateCompare 按运算符比较两个值
+// predicateCompare compares two values with the operator
+func predicateCompare(left IValue, operator string, right IValue, tolerance NumericTolerance) bool {
+	switch operator {
+	case "==":
+		return predicateEqual(left, right, tolerance)
+	case "!=":
+		return !predicateEqual(left, right, tolerance)
+	}
+	if left == nil || right == nil {
+		return false
+	}
+
+	var cmp int
+	if l, ok := left.AsFloat64OK(); ok {
+		r, ok := right.AsFloat64OK()
+		if !ok || math.IsNaN(l) || math.IsNaN(r) {
+			return false
+		}
+		cmp = tolerance.Compare(l, r)
+	} else if l, ok := left.AsStringOK(); ok {
+		r, ok := right.AsStringOK()
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(l, r)
+	} else {
+		return false
+	}
+
+	switch operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// predicateParser 谓词表达式的递归下降解析器
+// predicateParser is a recursive descent parser for predicate expressions
+type predicateParser struct {
+	expr  string
+	pos   int
+	depth int
+}
+
+// fail 创建当前位置的表达式错误
+// fail creates an expression error at the current position
+func (p *predicateParser) fail(message string) error {
+	return NewInvalidExpressionError(p.expr, p.pos, message)
+}
+
+// parseOr 解析||
+// parseOr parses ||
+func (p *predicateParser) parseOr() (predicateNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd 解析&&
+// parseAnd parses &&
+func (p *predicateParser) parseAnd() (predicateNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary 解析!和比较
+// parseUnary parses ! and comparisons
+func (p *predicateParser) parseUnary() (predicateNode, error) {
+	p.skipWhitespace()
+	if p.pos < len(p.expr) && p.expr[p.pos] == '!' && !strings.HasPrefix(p.expr[p.pos:], "!=") {
+		p.pos++
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(operator) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return &compareNode{operator: operator, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parseOperand 解析括号表达式、路径、字面量和函数调用
+// parseOperand parses parenthesized expressions, paths, literals and function calls
+func (p *predicateParser) parseOperand() (predicateNode, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.expr) {
+		return nil, p.fail("unexpected end of expression")
+	}
+
+	switch ch := p.expr[p.pos]; {
+	case ch == '(':
+		p.pos++
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.fail("expected ')'")
+		}
+		return node, nil
+	case ch == '$':
+		return p.parsePath()
+	case ch == '\'' || ch == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &literalNode{value: CreateString(s)}, nil
+	case ch == '-' || ch >= '0' && ch <= '9':
+		return p.parseNumber()
+	case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+		return p.parseIdentifier()
+	default:
+		return nil, p.fail("unexpected " + strconv.Quote(string(ch)))
+	}
+}
+
+// parsePath 解析路径，方括号和引号内的字符属于路径
+// parsePath parses a path, characters inside brackets and quotes belong to the path
+func (p *predicateParser) parsePath() (predicateNode, error) {
+	start := p.pos
+	brackets := 0
+	var quote byte
+	for ; p.pos < len(p.expr); p.pos++ {
+		ch := p.expr[p.pos]
+		if quote != 0 {
+			if ch == '\\' {
+				p.pos++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		if brackets > 0 && (ch == '\'' || ch == '"') {
+			quote = ch
+			continue
+		}
+		if ch == '[' {
+			brackets++
+			continue
+		}
+		if ch == ']' {
+			brackets--
+			continue
+		}
+		if brackets == 0 && strings.IndexByte(" \t\r\n()!=<>&|,", ch) >= 0 {
+			break
+		}
+	}
+
+	path, err := CompilePath(p.expr[start:p.pos])
+	if err != nil {
+		return nil, NewInvalidExpressionError(p.expr, start, "invalid path "+strconv.Quote(p.expr[start:p.pos]))
+	}
+	return &pathNode{path: path}, nil
+}
+
+// parseString 解析单引号或双引号字符串
+// parseString parses a single- or double-quoted string
+func (p *predicateParser) parseString() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.expr) {
+		ch := p.expr[p.pos]
+		p.pos++
+		switch {
+		case ch == quote:
+			return sb.String(), nil
+		case ch == '\\' && p.pos < len(p.expr):
+			escaped := p.expr[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(escaped)
+			}
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return "", p.fail("unterminated string")
+}
+
+// parseNumber 解析数字
+// parseNumber parses a number
+func (p *predicateParser) parseNumber() (predicateNode, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.expr) && isLiteralByte(p.expr[p.pos]) {
+		p.pos++
+	}
+	text := p.expr[start:p.pos]
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return &literalNode{value: CreateNumber(i)}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return nil, NewInvalidExpressionError(p.expr, start, "invalid number "+strconv.Quote(text))
+	}
+	return &literalNode{value: CreateNumber(f)}, nil
+}
+
+// parseIdentifier 解析true、false、null和函数调用
+// parseIdentifier parses true, false, null and function calls
+func (p *predicateParser) parseIdentifier() (predicateNode, error) {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		ch := p.expr[p.pos]
+		if ch != '_' && !(ch >= 'a' && ch <= 'z') && !(ch >= 'A' && ch <= 'Z') && !(ch >= '0' && ch <= '9') {
+			break
+		}
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+
+	switch name {
+	case "true":
+		return &literalNode{value: CreateBool(true)}, nil
+	case "false":
+		return &literalNode{value: CreateBool(false)}, nil
+	case "null":
+		return &literalNode{value: CreateNull()}, nil
+	}
+
+	fn, exists := predicateFuncs[name]
+	if !exists {
+		return nil, NewInvalidExpressionError(p.expr, start, "unknown function "+strconv.Quote(name))
+	}
+	if !p.consume("(") {
+		return nil, p.fail("expected '(' after " + name)
+	}
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	node := &callNode{fn: fn}
+	for len(node.args) < fn.arity {
+		if len(node.args) > 0 && !p.consume(",") {
+			return nil, p.fail("expected ','")
+		}
+		argStart := p.pos
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, isPath := arg.(*pathNode); fn.path && !isPath {
+			return nil, NewInvalidExpressionError(p.expr, argStart, name+" expects a path")
+		}
+		node.args = append(node.args, arg)
+	}
+	if !p.consume(")") {
+		return nil, p.fail("expected ')'")
+	}
+
+	// 正则表达式必须是字面量，在编译时检查
+	// The regular expression must be a literal, checked at compile time
+	if name == "matches" {
+		literal, ok := node.args[1].(*literalNode)
+		pattern, isString := "", false
+		if ok {
+			pattern, isString = literal.value.AsStringOK()
+		}
+		if !isString {
+			return nil, p.fail("matches expects a string literal pattern")
+		}
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, p.fail("invalid pattern: " + err.Error())
+		}
+		node.regex = regex
+	}
+	return node, nil
+}
+
+// consume 跳过空白后匹配token
+// consume matches a token after skipping whitespace
+func (p *predicateParser) consume(token string) bool {
+	p.skipWhitespace()
+	if strings.HasPrefix(p.expr[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// skipWhitespace 跳过空白字符
+// skipWhitespace skips whitespace
+func (p *predicateParser) skipWhitespace() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// enter 进入嵌套并检查深度
+// enter enters a nesting level and checks the depth
+func (p *predicateParser) enter() error {
+	p.depth++
+	if p.depth > MaxNestingDepth {
+		return NewMaxDepthExceededError(MaxNestingDepth)
+	}
+	return nil
+}
+
+// leave 离开嵌套
+// leave leaves a nesting level
+func (p *predicateParser) leave() {
+	p.depth--
+}
@@ -0,0 +1,187 @@
+package xyJson
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+)
+
+const (
+	// ProfileLabelOperation 标记操作类型（parse或serialize）的pprof标签键
+	// ProfileLabelOperation is the pprof label key marking the operation (parse or serialize)
+	ProfileLabelOperation = "xyjson.op"
+
+	// ProfileLabelSize 标记文档大小等级（small、medium、large、huge）的pprof标签键
+	// ProfileLabelSize is the pprof label key marking the document size class (small, medium, large, huge)
+	ProfileLabelSize = "xyjson.size"
+)
+
+var (
+	// profileLabelsEnabled 是否为解析和序列化打pprof标签
+	// profileLabelsEnabled indicates whether parse and serialize are tagged with pprof labels
+	profileLabelsEnabled atomic.Bool
+
+	// profileExtraLabels 附加到每个操作的用户标签键值对
+	// profileExtraLabels holds the user label key/value pairs attached to every operation
+	profileExtraLabels atomic.Pointer[[]string]
+)
+
+// StartMemoryProfilingWithLabels 开始内存分析，并为Parse、ParseString、Serialize、SerializeToString
+// 及其Context变体所在的goroutine打上pprof标签xyjson.op和xyjson.size，labels为附加的键值对；StopMemoryProfiling同时停止打标签
+// StartMemoryProfilingWithLabels starts memory profiling and tags the goroutines running Parse, ParseString,
+// Serialize, SerializeToString and their Context variants with the pprof labels xyjson.op and xyjson.size, labels being extra
+// key/value pairs; StopMemoryProfiling stops the tagging as well
+//
+// 标签出现在CPU和goroutine profile中（例如go tool pprof -tagfocus=xyjson.op=parse），
+// 可以区分各操作和文档大小的耗时以及阻塞在其中的goroutine；Go的heap profile不记录标签，
+// 堆分配仍需通过分配栈区分调用点。解析按输入字节数分级，序列化按顶层抽样估算的大小分级。
+// 标签建立在ctx的标签之上，结束后goroutine恢复为ctx的标签：ParseContext和SerializeContext保留调用方
+// 通过pprof.Do设置的标签，不带ctx的函数以context.Background()为基础，结束后清除标签
+// The labels appear in CPU and goroutine profiles (for example go tool pprof -tagfocus=xyjson.op=parse),
+// separating the time spent per operation and document size and the goroutines blocked in them; Go heap
+// profiles do not record labels, so heap allocations are still told apart by their allocation stacks. Parses
+// are classed by input bytes, serializations by a size estimated by sampling the top levels. The labels are
+// built on top of the labels of ctx and the goroutine goes back to the labels of ctx afterwards: ParseContext
+// and SerializeContext keep the labels the caller set with pprof.Do, the functions without ctx are based on
+// context.Background() and clear the labels afterwards
+//
+// 示例 Example:
+//
+//	if err := xyJson.StartMemoryProfilingWithLabels("service", "orders"); err != nil {
+//		log.Fatal(err)
+//	}
+//	defer xyJson.StopMemoryProfiling()
+func StartMemoryProfilingWithLabels(labels ...string) error {
+	if len(labels)%2 != 0 {
+		return NewInvalidOperationError("start memory profiling", "labels must be key/value pairs")
+	}
+	extra := append([]string(nil), labels...)
+	profileExtraLabels.Store(&extra)
+	profileLabelsEnabled.Store(true)
+	GetGlobalProfiler().Start()
+	return nil
+}
+
+// noLabels 未启用标签时返回的空恢复函数
+// noLabels is the empty restore function returned when labels are disabled
+func noLabels() {}
+
+// labelParse 在ctx的标签之上为解析size字节的操作打标签，返回恢复ctx标签的函数
+// labelParse tags a parse of size bytes on top of the labels of ctx, returning the function that restores the
+// labels of ctx
+func labelParse(ctx context.Context, size int) func() {
+	if !profileLabelsEnabled.Load() {
+		return noLabels
+	}
+	return setOperationLabels(ctx, "parse", size)
+}
+
+// labelSerialize 在ctx的标签之上为序列化value的操作打标签，返回恢复ctx标签的函数
+// labelSerialize tags a serialization of value on top of the labels of ctx, returning the function that
+// restores the labels of ctx
+func labelSerialize(ctx context.Context, value IValue) func() {
+	if !profileLabelsEnabled.Load() {
+		return noLabels
+	}
+	return setOperationLabels(ctx, "serialize", estimateSize(value, 0))
+}
+
+// setOperationLabels 在ctx的标签之上设置当前goroutine的操作标签
+// setOperationLabels sets the operation labels of the current goroutine on top of the labels of ctx
+func setOperationLabels(ctx context.Context, op string, size int) func() {
+	labels := []string{ProfileLabelOperation, op, ProfileLabelSize, sizeClass(size)}
+	if extra := profileExtraLabels.Load(); extra != nil {
+		labels = append(labels, *extra...)
+	}
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(labels...)))
+	return func() { pprof.SetGoroutineLabels(ctx) }
+}
+
+// 文档大小等级的上限
+// Upper bounds of the document size classes
+const (
+	smallDocumentSize  = 4 << 10
+	mediumDocumentSize = 256 << 10
+	hugeDocumentSize   = 16 << 20
+)
+
+// sizeClass 返回字节数对应的大小等级
+// sizeClass returns the size class of a byte count
+func sizeClass(size int) string {
+	switch {
+	case size < smallDocumentSize:
+		return "small"
+	case size < mediumDocumentSize:
+		return "medium"
+	case size < hugeDocumentSize:
+		return "large"
+	default:
+		return "huge"
+	}
+}
+
+// 大小估算的抽样参数
+// Sampling parameters of the size estimate
+const (
+	// estimateSamples 每个容器抽样的子值数
+	// estimateSamples is the number of children sampled per container
+	estimateSamples = 8
+
+	// estimateDepth 抽样的最大深度，更深的容器按长度估算
+	// estimateDepth is the maximum sampling depth, deeper containers are estimated from their length
+	estimateDepth = 2
+
+	// estimateItemSize 未抽样的子值的估算字节数
+	// estimateItemSize is the estimated number of bytes of a child that is not sampled
+	estimateItemSize = 16
+)
+
+// estimateSize 抽样估算值序列化后的字节数：每个容器只检查前几个子值并按长度外推，
+// 因此无论文档多大都只访问常数个节点
+// estimateSize estimates the serialized size of a value by sampling: each container only looks at its first
+// few children and extrapolates by its length, so a constant number of nodes is visited however large the
+// document is
+func estimateSize(value IValue, depth int) int {
+	switch t := value.(type) {
+	case IObject:
+		n := t.Size()
+		if depth >= estimateDepth {
+			return 2 + n*estimateItemSize
+		}
+		sampled, size := 0, 0
+		t.Range(func(key string, child IValue) bool {
+			size += len(key) + 4 + estimateSize(child, depth+1)
+			sampled++
+			return sampled < estimateSamples
+		})
+		return 2 + extrapolate(size, sampled, n)
+	case IArray:
+		n := t.Length()
+		if depth >= estimateDepth {
+			return 2 + n*estimateItemSize
+		}
+		sampled, size := 0, 0
+		t.Range(func(_ int, child IValue) bool {
+			size += 1 + estimateSize(child, depth+1)
+			sampled++
+			return sampled < estimateSamples
+		})
+		return 2 + extrapolate(size, sampled, n)
+	case nil:
+		return 4
+	default:
+		if t.Type() == StringValueType {
+			return len(t.AsString()) + 2
+		}
+		return 8
+	}
+}
+
+// extrapolate 将sampled个子值的总大小外推到n个子值
+// extrapolate scales the total size of sampled children up to n children
+func extrapolate(size, sampled, n int) int {
+	if sampled == 0 {
+		return 0
+	}
+	return size / sampled * n
+}
@@ -0,0 +1,386 @@
+package xyJson
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Projection 描述解析时需要保留的字段树，其余字段在扫描时直接跳过而不分配内存
+// Projection describes the tree of fields to keep while parsing, all other fields are skipped during scanning
+// without allocating
+//
+// 每个节点把对象键映射到子投影；子投影为nil表示保留该键下的整个值。
+// 数组对投影透明：投影作用于数组的每个元素
+// Each node maps object keys to child projections; a nil child keeps the whole value under that key.
+// Arrays are transparent to projections: the projection applies to every element
+type Projection struct {
+	fields map[string]*Projection
+}
+
+// NewProjection 从点分隔的路径列表创建投影
+// NewProjection creates a projection from a list of dot-separated paths
+//
+// 路径可以带"$."前缀，"[*]"段会被忽略，因此"$.items[*].id"与"items.id"等价。
+// 一个路径是另一个路径的前缀时，较短的路径生效（保留整个子树）
+// Paths may carry a "$." prefix and "[*]" segments are ignored, so "$.items[*].id" is equivalent to "items.id".
+// When one path is a prefix of another the shorter one wins (the whole subtree is kept)
+//
+// 示例 Example:
+//
+//	proj, err := xyJson.NewProjection("id", "user.name", "items.price")
+//	root, err := xyJson.ParseProjected(data, proj)
+func NewProjection(paths ...string) (*Projection, error) {
+	root := &Projection{fields: make(map[string]*Projection)}
+
+	for _, path := range paths {
+		segments, err := splitProjectionPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		node := root
+		for i, segment := range segments {
+			child, exists := node.fields[segment]
+			if exists && child == nil {
+				// 已保留整个子树
+				// The whole subtree is already kept
+				break
+			}
+			if i == len(segments)-1 {
+				node.fields[segment] = nil
+				break
+			}
+			if !exists {
+				child = &Projection{fields: make(map[string]*Projection)}
+				node.fields[segment] = child
+			}
+			node = child
+		}
+	}
+
+	return root, nil
+}
+
+// ProjectionOf 从结构体类型推导投影，只保留结构体（递归地）声明的字段
+// ProjectionOf derives a projection from a struct type, keeping only the fields the struct declares (recursively)
+//
+// target可以是结构体、结构体指针或reflect.Type
+// target may be a struct, a pointer to a struct or a reflect.Type
+func ProjectionOf(target interface{}) (*Projection, error) {
+	if target == nil {
+		return nil, NewNullPointerError("target cannot be nil")
+	}
+
+	t, ok := target.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(target)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, NewInvalidOperationError("projection of", "target must be a struct, got "+t.Kind().String())
+	}
+
+	return structProjection(t, make(map[reflect.Type]bool)), nil
+}
+
+// Paths 返回投影中所有叶子路径（排序后）
+// Paths returns all leaf paths of the projection (sorted)
+func (p *Projection) Paths() []string {
+	var paths []string
+	p.collectPaths("", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+// collectPaths 递归收集叶子路径
+// collectPaths collects leaf paths recursively
+func (p *Projection) collectPaths(prefix string, paths *[]string) {
+	for key, child := range p.fields {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if child == nil || len(child.fields) == 0 {
+			*paths = append(*paths, path)
+			continue
+		}
+		child.collectPaths(path, paths)
+	}
+}
+
+// ParseProjected 按投影解析JSON，未投影的键被跳过，结果中只包含投影到的字段
+// ParseProjected parses JSON according to a projection, unprojected keys are skipped and the result only
+// contains the projected fields
+//
+// 投影要求对象但实际是标量的值会被省略，根值被省略时返回null。projection为nil时等价于Parse
+// Values where the projection expects an object but finds a scalar are omitted, an omitted root yields null.
+// A nil projection is equivalent to Parse
+func ParseProjected(data []byte, projection *Projection) (IValue, error) {
+	if projection == nil {
+		return Parse(data)
+	}
+
+	timer := GetGlobalMonitor().StartParseTimer()
+	pp := &projectedParser{
+		customParser: customParser{maxDepth: DefaultMaxDepth},
+		factory:      defaultFactory(),
+	}
+	pp.reset(data)
+
+	root, _, err := pp.parseProjected(projection, 0)
+	if err == nil {
+		pp.skipWhitespace()
+		if pp.pos < pp.length {
+			err = NewInvalidJSONError("unexpected data after JSON value", nil)
+		}
+	}
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+
+	if root == nil {
+		root = pp.factory.CreateNull()
+	}
+	return root, nil
+}
+
+// UnmarshalProjected 按投影直接解码到结构体，结构体中未投影的字段保持不变
+// UnmarshalProjected decodes directly into a struct according to a projection, unprojected struct fields are
+// left untouched
+//
+// 与DecodeDirect一样只支持简单结构体；projection为nil时按结构体本身的字段解码
+// Like DecodeDirect only plain structs are supported; a nil projection decodes the struct's own fields
+func UnmarshalProjected(data []byte, target interface{}, projection *Projection) error {
+	cp := NewCustomParser().(*customParser)
+	cp.projection = projection
+	return cp.UnmarshalDirect(data, target)
+}
+
+// projectedParser 在自定义解析器的扫描能力之上构建投影后的IValue树
+// projectedParser builds projected IValue trees on top of the custom parser's scanning
+type projectedParser struct {
+	customParser
+	factory IValueFactory
+}
+
+// parseProjected 解析一个值，返回的ok为false表示该值被投影省略
+// parseProjected parses one value, ok is false when the projection omits the value
+func (pp *projectedParser) parseProjected(projection *Projection, depth int) (value IValue, ok bool, err error) {
+	if depth > DefaultMaxDepth {
+		return nil, false, NewMaxDepthExceededError(depth)
+	}
+
+	pp.skipWhitespace()
+	if pp.pos >= pp.length {
+		return nil, false, NewInvalidJSONError("unexpected end of input", nil)
+	}
+
+	// 整个子树都需要保留时交给完整解析器
+	// Hand whole subtrees to the full parser
+	if projection == nil {
+		value, err := pp.parseRaw()
+		return value, err == nil, err
+	}
+
+	switch pp.data[pp.pos] {
+	case CharLeftBrace:
+		return pp.parseProjectedObject(projection, depth)
+	case CharLeftBracket:
+		return pp.parseProjectedArray(projection, depth)
+	default:
+		return nil, false, pp.skipValue()
+	}
+}
+
+// parseProjectedObject 解析对象，只保留投影中的键
+// parseProjectedObject parses an object, keeping only the keys in the projection
+func (pp *projectedParser) parseProjectedObject(projection *Projection, depth int) (IValue, bool, error) {
+	obj := pp.factory.CreateObject()
+
+	pp.pos++ // 跳过 '{'
+	pp.skipWhitespace()
+	if pp.pos < pp.length && pp.data[pp.pos] == CharRightBrace {
+		pp.pos++
+		return obj, true, nil
+	}
+
+	for {
+		pp.skipWhitespace()
+		if pp.pos >= pp.length || pp.data[pp.pos] != CharQuote {
+			return nil, false, NewInvalidJSONError("expected string key", nil)
+		}
+		key, err := pp.parseKey()
+		if err != nil {
+			return nil, false, err
+		}
+
+		pp.skipWhitespace()
+		if pp.pos >= pp.length || pp.data[pp.pos] != CharColon {
+			return nil, false, NewInvalidJSONError("expected ':'", nil)
+		}
+		pp.pos++
+
+		if child, exists := projection.fields[key]; exists {
+			value, ok, err := pp.parseProjected(child, depth+1)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				obj.Set(key, value)
+			}
+		} else if err := pp.skipValue(); err != nil {
+			return nil, false, err
+		}
+
+		pp.skipWhitespace()
+		if pp.pos >= pp.length {
+			return nil, false, NewInvalidJSONError("unexpected end of object", nil)
+		}
+		if pp.data[pp.pos] == CharRightBrace {
+			pp.pos++
+			return obj, true, nil
+		}
+		if pp.data[pp.pos] != CharComma {
+			return nil, false, NewInvalidJSONError("expected ',' or '}'", nil)
+		}
+		pp.pos++
+	}
+}
+
+// parseProjectedArray 解析数组，对每个元素应用同一投影
+// parseProjectedArray parses an array, applying the same projection to every element
+func (pp *projectedParser) parseProjectedArray(projection *Projection, depth int) (IValue, bool, error) {
+	arr := pp.factory.CreateArray()
+
+	pp.pos++ // 跳过 '['
+	pp.skipWhitespace()
+	if pp.pos < pp.length && pp.data[pp.pos] == CharRightBracket {
+		pp.pos++
+		return arr, true, nil
+	}
+
+	for {
+		value, ok, err := pp.parseProjected(projection, depth+1)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			arr.Append(value)
+		}
+
+		pp.skipWhitespace()
+		if pp.pos >= pp.length {
+			return nil, false, NewInvalidJSONError("unexpected end of array", nil)
+		}
+		if pp.data[pp.pos] == CharRightBracket {
+			pp.pos++
+			return arr, true, nil
+		}
+		if pp.data[pp.pos] != CharComma {
+			return nil, false, NewInvalidJSONError("expected ',' or ']'", nil)
+		}
+		pp.pos++
+	}
+}
+
+// parseKey 解析对象键，只有含转义的键才交给完整解析器解码
+// parseKey parses an object key, only keys containing escapes are decoded by the full parser
+func (pp *projectedParser) parseKey() (string, error) {
+	start := pp.pos
+	if err := pp.skipString(); err != nil {
+		return "", err
+	}
+
+	raw := pp.data[start+1 : pp.pos-1]
+	if bytes.IndexByte(raw, CharBackslash) < 0 {
+		return string(raw), nil
+	}
+
+	value, err := NewParserWithFactory(pp.factory).Parse(pp.data[start:pp.pos])
+	if err != nil {
+		return "", err
+	}
+	return value.String(), nil
+}
+
+// parseRaw 定位当前值的范围并用完整解析器解析
+// parseRaw locates the extent of the current value and parses it with the full parser
+func (pp *projectedParser) parseRaw() (IValue, error) {
+	start := pp.pos
+	if err := pp.skipValue(); err != nil {
+		return nil, err
+	}
+	return NewParserWithFactory(pp.factory).Parse(pp.data[start:pp.pos])
+}
+
+// splitProjectionPath 将投影路径拆分为键段
+// splitProjectionPath splits a projection path into key segments
+func splitProjectionPath(path string) ([]string, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	trimmed = strings.ReplaceAll(trimmed, "[*]", "")
+	if trimmed == "" {
+		return nil, NewInvalidPathError(path, nil)
+	}
+
+	segments := strings.Split(trimmed, ".")
+	for _, segment := range segments {
+		if segment == "" || strings.ContainsAny(segment, "[]") {
+			return nil, NewInvalidPathError(path, nil)
+		}
+	}
+	return segments, nil
+}
+
+// structProjection 递归地从结构体类型构建投影
+// structProjection builds a projection from a struct type recursively
+func structProjection(t reflect.Type, visiting map[reflect.Type]bool) *Projection {
+	if visiting[t] {
+		// 递归类型保留整个值
+		// Recursive types keep the whole value
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	projection := &Projection{fields: make(map[string]*Projection)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := parseJSONTag(field.Tag.Get("json"))
+		if tag.Skip {
+			continue
+		}
+
+		name := field.Name
+		if tag.Name != "" {
+			name = tag.Name
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !tag.AsString && !customUnmarshaler(fieldType) {
+			projection.fields[name] = structProjection(fieldType, visiting)
+		} else {
+			projection.fields[name] = nil
+		}
+	}
+	return projection
+}
+
+// customUnmarshaler 检查类型是否自行实现JSON或文本解码
+// customUnmarshaler checks whether a type implements its own JSON or text decoding
+func customUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonUnmarshalerType) || pt.Implements(jsonUnmarshalerType) ||
+		t.Implements(textUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
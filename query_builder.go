@@ -0,0 +1,383 @@
+package xyJson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder 编程式JSONPath查询构建器
+// QueryBuilder is a programmatic JSONPath query builder
+//
+// 构建器直接生成与parsePath相同的路径段，键名不经过字符串解析，
+// 因此可以安全地使用来自用户输入的键名而无需拼接或转义
+// The builder produces the same segments as parsePath; keys never go through string parsing,
+// so keys taken from user input can be used safely without concatenation or escaping
+//
+// 使用示例 Usage Example:
+//
+//	title, err := xyJson.Q().Key("store").Key("book").Index(0).Key("title").Get(root)
+//	adults, err := xyJson.Q().Key("users").Filter(xyJson.Field("age").Gt(25)).GetAll(root)
+type QueryBuilder struct {
+	segments []*pathSegment
+	err      error
+}
+
+// FieldRef 过滤条件中引用的字段
+// FieldRef is a field referenced in a filter condition
+type FieldRef struct {
+	expression string
+}
+
+// FilterCondition 查询构建器的过滤条件
+// FilterCondition is a filter condition for the query builder
+type FilterCondition struct {
+	filter *pathFilter
+}
+
+// Q 创建新的查询构建器，起点为根节点$
+// Q creates a new query builder starting at the root $
+func Q() *QueryBuilder {
+	return &QueryBuilder{
+		segments: make([]*pathSegment, 0, 4),
+	}
+}
+
+// Key 选择对象的属性
+// Key selects an object property
+func (qb *QueryBuilder) Key(key string) *QueryBuilder {
+	if key == "" {
+		return qb.fail(NewInvalidPathError(qb.String(), fmt.Errorf("key cannot be empty")))
+	}
+	qb.segments = append(qb.segments, &pathSegment{
+		Type: PropertySegmentType,
+		Key:  key,
+	})
+	return qb
+}
+
+// Index 选择数组元素，负数表示从末尾开始计数
+// Index selects an array element, negative values count from the end
+func (qb *QueryBuilder) Index(index int) *QueryBuilder {
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:  IndexSegmentType,
+		Index: index,
+	})
+	return qb
+}
+
+// Last 选择数组的最后一个元素，等价于[last]或[-1]
+// Last selects the last array element, equivalent to [last] or [-1]
+func (qb *QueryBuilder) Last() *QueryBuilder {
+	return qb.Index(-1)
+}
+
+// Append 在数组末尾追加新元素，等价于[+]，用于Set
+// Append appends a new element at the end of the array, equivalent to [+], for use with Set
+func (qb *QueryBuilder) Append() *QueryBuilder {
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:   IndexSegmentType,
+		Index:  -1,
+		Append: true,
+	})
+	return qb
+}
+
+// All 选择数组的所有元素，等价于[*]
+// All selects all array elements, equivalent to [*]
+func (qb *QueryBuilder) All() *QueryBuilder {
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:     IndexSegmentType,
+		Wildcard: true,
+	})
+	return qb
+}
+
+// AnyKey 选择对象的所有属性值，等价于.*
+// AnyKey selects all property values of an object, equivalent to .*
+func (qb *QueryBuilder) AnyKey() *QueryBuilder {
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:     PropertySegmentType,
+		Wildcard: true,
+	})
+	return qb
+}
+
+// Descendant 递归查找指定键，等价于..key
+// Descendant recursively searches for the key, equivalent to ..key
+func (qb *QueryBuilder) Descendant(key string) *QueryBuilder {
+	if key == "" {
+		return qb.fail(NewInvalidPathError(qb.String(), fmt.Errorf("key cannot be empty")))
+	}
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:      PropertySegmentType,
+		Key:       key,
+		Recursive: true,
+	})
+	return qb
+}
+
+// Filter 使用条件过滤数组元素，等价于[?(...)]
+// Filter filters array elements by condition, equivalent to [?(...)]
+func (qb *QueryBuilder) Filter(cond *FilterCondition) *QueryBuilder {
+	if cond == nil || cond.filter == nil {
+		return qb.fail(NewInvalidPathError(qb.String(), fmt.Errorf("filter condition cannot be nil")))
+	}
+	qb.segments = append(qb.segments, &pathSegment{
+		Type:   FilterSegmentType,
+		Filter: cond.filter,
+	})
+	return qb
+}
+
+// Err 返回构建过程中的第一个错误
+// Err returns the first error encountered while building
+func (qb *QueryBuilder) Err() error {
+	return qb.err
+}
+
+// String 返回等价的JSONPath字符串
+// String returns the equivalent JSONPath string
+func (qb *QueryBuilder) String() string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, segment := range qb.segments {
+		sb.WriteString(formatSegment(segment))
+	}
+	return sb.String()
+}
+
+// Compile 将构建器编译为预编译路径
+// Compile compiles the builder into a compiled path
+//
+// 返回的CompiledPath不会写入全局路径缓存
+// The returned CompiledPath is not stored in the global path cache
+func (qb *QueryBuilder) Compile() (*CompiledPath, error) {
+	if qb.err != nil {
+		return nil, qb.err
+	}
+	segments := make([]*pathSegment, len(qb.segments))
+	copy(segments, qb.segments)
+	return &CompiledPath{
+		originalPath: qb.String(),
+		segments:     segments,
+		factory:      defaultFactory(),
+	}, nil
+}
+
+// MustCompile 编译构建器，失败时panic
+// MustCompile compiles the builder, panics on failure
+func (qb *QueryBuilder) MustCompile() *CompiledPath {
+	compiled, err := qb.Compile()
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// Get 查询单个值
+// Get queries a single value
+func (qb *QueryBuilder) Get(root IValue) (IValue, error) {
+	compiled, err := qb.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Query(root)
+}
+
+// GetAll 查询所有匹配的值
+// GetAll queries all matching values
+func (qb *QueryBuilder) GetAll(root IValue) ([]IValue, error) {
+	compiled, err := qb.Compile()
+	if err != nil {
+		return nil, err
+	}
+	return compiled.QueryAll(root)
+}
+
+// Set 在构建的路径上设置值
+// Set sets a value at the built path
+func (qb *QueryBuilder) Set(root IValue, value interface{}) error {
+	compiled, err := qb.Compile()
+	if err != nil {
+		return err
+	}
+	v, err := defaultFactory().CreateFromRaw(value)
+	if err != nil {
+		return err
+	}
+	return compiled.Set(root, v)
+}
+
+// Delete 删除构建的路径上的值
+// Delete deletes the value at the built path
+func (qb *QueryBuilder) Delete(root IValue) error {
+	compiled, err := qb.Compile()
+	if err != nil {
+		return err
+	}
+	return compiled.Delete(root)
+}
+
+// Exists 检查构建的路径是否存在
+// Exists checks if the built path exists
+func (qb *QueryBuilder) Exists(root IValue) bool {
+	compiled, err := qb.Compile()
+	if err != nil {
+		return false
+	}
+	return compiled.Exists(root)
+}
+
+// fail 记录第一个错误
+// fail records the first error
+func (qb *QueryBuilder) fail(err error) *QueryBuilder {
+	if qb.err == nil {
+		qb.err = err
+	}
+	return qb
+}
+
+// Field 引用当前元素的属性，用于构建过滤条件
+// Field references a property of the current element for building filter conditions
+//
+// 空名称引用当前元素本身（@）
+// An empty name references the current element itself (@)
+func Field(name string) *FieldRef {
+	if name == "" {
+		return &FieldRef{expression: "@"}
+	}
+	return &FieldRef{expression: "@." + name}
+}
+
+// Eq 等于条件
+// Eq builds an equality condition
+func (f *FieldRef) Eq(value interface{}) *FilterCondition {
+	return f.condition("==", value)
+}
+
+// Ne 不等于条件
+// Ne builds an inequality condition
+func (f *FieldRef) Ne(value interface{}) *FilterCondition {
+	return f.condition("!=", value)
+}
+
+// Gt 大于条件
+// Gt builds a greater-than condition
+func (f *FieldRef) Gt(value interface{}) *FilterCondition {
+	return f.condition(">", value)
+}
+
+// Ge 大于等于条件
+// Ge builds a greater-than-or-equal condition
+func (f *FieldRef) Ge(value interface{}) *FilterCondition {
+	return f.condition(">=", value)
+}
+
+// Lt 小于条件
+// Lt builds a less-than condition
+func (f *FieldRef) Lt(value interface{}) *FilterCondition {
+	return f.condition("<", value)
+}
+
+// Le 小于等于条件
+// Le builds a less-than-or-equal condition
+func (f *FieldRef) Le(value interface{}) *FilterCondition {
+	return f.condition("<=", value)
+}
+
+// condition 创建过滤条件，值的表示与parseFilter保持一致
+// condition creates a filter condition, values are normalized the same way as parseFilter
+func (f *FieldRef) condition(operator string, value interface{}) *FilterCondition {
+	return &FilterCondition{
+		filter: &pathFilter{
+			Expression: f.expression,
+			Operator:   operator,
+			Value:      normalizeFilterValue(value),
+		},
+	}
+}
+
+// normalizeFilterValue 将Go值转换为过滤器比较使用的表示
+// normalizeFilterValue converts a Go value into the representation used by filter comparisons
+func normalizeFilterValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case IValue:
+		return v.Raw()
+	default:
+		return v
+	}
+}
+
+// formatSegment 将路径段格式化为JSONPath片段
+// formatSegment formats a path segment as a JSONPath fragment
+func formatSegment(segment *pathSegment) string {
+	switch segment.Type {
+	case IndexSegmentType:
+		if segment.Wildcard {
+			return "[*]"
+		}
+		if segment.Append {
+			return "[+]"
+		}
+		return "[" + strconv.Itoa(segment.Index) + "]"
+	case FilterSegmentType:
+		return "[?(" + formatFilter(segment.Filter) + ")]"
+	case CustomSegmentType:
+		return "[" + segment.Key + "]"
+	default:
+		prefix := ""
+		if segment.Recursive {
+			prefix = "."
+		}
+		if segment.Wildcard {
+			return prefix + ".*"
+		}
+		return prefix + appendPathKey("", segment.Key)
+	}
+}
+
+// formatFilter 将过滤器格式化为表达式字符串
+// formatFilter formats a filter as an expression string
+func formatFilter(filter *pathFilter) string {
+	if filter == nil {
+		return ""
+	}
+	if filter.Evaluator != nil {
+		return filter.Expression
+	}
+	var value string
+	switch v := filter.Value.(type) {
+	case nil:
+		value = "null"
+	case string:
+		value = "'" + v + "'"
+	case float64:
+		value = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		value = fmt.Sprintf("%v", v)
+	}
+	return filter.Expression + " " + filter.Operator + " " + value
+}
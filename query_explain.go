@@ -0,0 +1,273 @@
+package xyJson
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryCost 路径查询的代价等级
+// QueryCost is the cost class of a path query
+type QueryCost int
+
+const (
+	// QueryCostConstant 只有直接的键名和索引查找，与文档大小无关
+	// QueryCostConstant means only direct key and index lookups, independent of the document size
+	QueryCostConstant QueryCost = iota
+	// QueryCostLinear 通配符或过滤器，与被展开容器的大小成正比
+	// QueryCostLinear means wildcards or filters, proportional to the size of the expanded containers
+	QueryCostLinear
+	// QueryCostSubtree 递归下降，访问起点以下的每个节点
+	// QueryCostSubtree means recursive descent, visiting every node below the starting point
+	QueryCostSubtree
+)
+
+// String 返回代价等级的字符串表示
+// String returns the string representation of the cost class
+func (qc QueryCost) String() string {
+	switch qc {
+	case QueryCostConstant:
+		return "constant"
+	case QueryCostLinear:
+		return "linear"
+	case QueryCostSubtree:
+		return "subtree"
+	default:
+		return "unknown"
+	}
+}
+
+// SegmentPlan 查询计划中的一个路径段
+// SegmentPlan is a path segment of a query plan
+type SegmentPlan struct {
+	// Text 路径段的写法，如".name"、"[0]"、"..book"或"[?(@.price < 10)]"
+	// Text is how the segment is written, such as ".name", "[0]", "..book" or "[?(@.price < 10)]"
+	Text string
+
+	// Kind 段的种类：property、index、wildcard、filter或recursive
+	// Kind is the kind of the segment: property, index, wildcard, filter or recursive
+	Kind string
+
+	// Cost 段的代价等级
+	// Cost is the cost class of the segment
+	Cost QueryCost
+}
+
+// QueryPlan 预编译路径的查询计划
+// QueryPlan is the query plan of a compiled path
+type QueryPlan struct {
+	// Path 原始路径
+	// Path is the original path
+	Path string
+
+	// Segments 按执行顺序排列的路径段
+	// Segments are the path segments in execution order
+	Segments []SegmentPlan
+
+	// Recursive 是否包含递归下降
+	// Recursive indicates whether the path contains recursive descent
+	Recursive bool
+
+	// Filtered 是否包含过滤器
+	// Filtered indicates whether the path contains filters
+	Filtered bool
+
+	// Cost 整个查询的代价等级，即各段中最高的等级
+	// Cost is the cost class of the whole query, the highest class among the segments
+	Cost QueryCost
+}
+
+// String 返回查询计划的可读表示
+// String returns a readable representation of the query plan
+func (qp *QueryPlan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "path: %s\n", qp.Path)
+	fmt.Fprintf(&sb, "cost: %s\n", qp.Cost)
+	fmt.Fprintf(&sb, "recursive descent: %s, filters: %s\n", yesNo(qp.Recursive), yesNo(qp.Filtered))
+
+	width := 0
+	for _, segment := range qp.Segments {
+		if len(segment.Text) > width {
+			width = len(segment.Text)
+		}
+	}
+	for i, segment := range qp.Segments {
+		fmt.Fprintf(&sb, "  %d. %-*s  %-9s  %s\n", i+1, width, segment.Text, segment.Kind, segment.Cost)
+	}
+	return sb.String()
+}
+
+// Explain 返回路径的查询计划，说明每个路径段、是否包含递归下降或过滤器以及估计的代价等级
+// Explain returns the query plan of the path, describing every segment, whether recursive descent or filters
+// are involved and the estimated cost class
+//
+// 示例 Example:
+//
+//	path, _ := xyJson.CompilePath("$..book[?(@.price < 10)].title")
+//	fmt.Print(path.Explain())
+//	// path: $..book[?(@.price < 10)].title
+//	// cost: subtree
+//	// recursive descent: yes, filters: yes
+//	//   1. ..book               recursive  subtree
+//	//   2. [?(@.price < 10)]    filter     linear
+//	//   3. .title               property   constant
+func (cp *CompiledPath) Explain() *QueryPlan {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	plan := &QueryPlan{Path: cp.originalPath, Segments: make([]SegmentPlan, 0, len(cp.segments))}
+	for _, segment := range cp.segments {
+		sp := SegmentPlan{Text: segmentText(segment)}
+		switch {
+		case segment.Recursive:
+			sp.Kind, sp.Cost = "recursive", QueryCostSubtree
+			plan.Recursive = true
+		case segment.Type == FilterSegmentType:
+			sp.Kind, sp.Cost = "filter", QueryCostLinear
+			plan.Filtered = true
+		case segment.Type == CustomSegmentType:
+			sp.Kind, sp.Cost = "custom", QueryCostLinear
+		case segment.Wildcard:
+			sp.Kind, sp.Cost = "wildcard", QueryCostLinear
+		case segment.Type == IndexSegmentType:
+			sp.Kind, sp.Cost = "index", QueryCostConstant
+		default:
+			sp.Kind, sp.Cost = "property", QueryCostConstant
+		}
+		if sp.Cost > plan.Cost {
+			plan.Cost = sp.Cost
+		}
+		plan.Segments = append(plan.Segments, sp)
+	}
+	return plan
+}
+
+// WithDebug 返回输出调试信息的路径副本：每次查询后向w写入耗时以及每个路径段的输入节点数、访问节点数和匹配数
+// WithDebug returns a copy of the path that writes debug output: after every query it writes the elapsed time
+// and, for every segment, the number of input nodes, visited nodes and matches to w
+//
+// 原路径不受影响，因此可以安全地用于CompilePath返回的缓存实例。每次查询的输出通过一次Write写入
+// The original path is not affected, so it is safe to use on cached instances returned by CompilePath. The
+// output of each query is written with a single Write
+//
+// 示例 Example:
+//
+//	path, _ := xyJson.CompilePath("$..price")
+//	prices, _ := path.WithDebug(os.Stderr).QueryAll(doc)
+//	// query $..price: 12 matches in 41µs
+//	//   1. ..price  inputs=1 visited=230 matched=12
+func (cp *CompiledPath) WithDebug(w io.Writer) *CompiledPath {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	return &CompiledPath{
+		originalPath: cp.originalPath,
+		segments:     cp.segments,
+		factory:      cp.factory,
+		debug:        w,
+	}
+}
+
+// segmentStats 单个路径段的执行统计
+// segmentStats holds the execution statistics of a single segment
+type segmentStats struct {
+	inputs  int
+	visited int
+	matched int
+}
+
+// queryTrace 调试模式下记录查询中每个路径段访问的节点数
+// queryTrace records the nodes visited by every segment of a query in debug mode
+type queryTrace struct {
+	visited int
+	stats   []segmentStats
+}
+
+// visit 记录访问的节点数，未开启调试时不做任何事
+// visit records visited nodes, it does nothing when debugging is off
+func (pq *pathQuery) visit(n int) {
+	if pq.trace != nil {
+		pq.trace.visited += n
+	}
+	pq.yield.step(n)
+}
+
+// execute 执行预编译路径，开启调试时写出统计
+// execute runs the compiled path, writing statistics when debugging is on
+func (cp *CompiledPath) execute(root IValue, selectAll bool, tolerance NumericTolerance) []IValue {
+	pq := &pathQuery{factory: cp.factory, tolerance: tolerance}
+	if cp.debug == nil {
+		return pq.executeQuery(root, cp.segments, selectAll)
+	}
+
+	pq.trace = &queryTrace{stats: make([]segmentStats, 0, len(cp.segments))}
+	start := time.Now()
+	results := pq.executeQuery(root, cp.segments, selectAll)
+	elapsed := time.Since(start)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "query %s: %d matches in %s\n", cp.originalPath, len(results), elapsed)
+	for i, stats := range pq.trace.stats {
+		fmt.Fprintf(&sb, "  %d. %s  inputs=%d visited=%d matched=%d\n",
+			i+1, segmentText(cp.segments[i]), stats.inputs, stats.visited, stats.matched)
+	}
+	io.WriteString(cp.debug, sb.String())
+	return results
+}
+
+// segmentText 返回路径段的写法
+// segmentText returns how a segment is written
+func segmentText(segment *pathSegment) string {
+	switch {
+	case segment.Recursive && segment.Wildcard:
+		return "..*"
+	case segment.Recursive:
+		return ".." + EscapeKey(segment.Key)
+	case segment.Type == FilterSegmentType:
+		return "[?(" + filterText(segment.Filter) + ")]"
+	case segment.Type == CustomSegmentType:
+		return "[" + segment.Key + "]"
+	case segment.Append:
+		return "[+]"
+	case segment.Type == IndexSegmentType && segment.Wildcard:
+		return "[*]"
+	case segment.Type == IndexSegmentType:
+		return "[" + strconv.Itoa(segment.Index) + "]"
+	case segment.Wildcard:
+		return ".*"
+	default:
+		return "." + EscapeKey(segment.Key)
+	}
+}
+
+// filterText 返回过滤器表达式的写法
+// filterText returns how a filter expression is written
+func filterText(filter *pathFilter) string {
+	if filter == nil {
+		return ""
+	}
+	if filter.Evaluator != nil {
+		return filter.Expression
+	}
+	var value string
+	switch v := filter.Value.(type) {
+	case string:
+		value = "'" + v + "'"
+	case nil:
+		value = "null"
+	default:
+		value = fmt.Sprint(v)
+	}
+	return filter.Expression + " " + filter.Operator + " " + value
+}
+
+// yesNo 将布尔值转换为yes或no
+// yesNo converts a boolean to yes or no
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
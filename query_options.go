@@ -0,0 +1,301 @@
+package xyJson
+
+// QueryOptions 多值查询的分页选项
+// QueryOptions holds the paging options of multi-value queries
+type QueryOptions struct {
+	// Limit 最多返回的匹配数，0表示不限制
+	// Limit is the maximum number of matches returned, 0 means unlimited
+	Limit int
+
+	// Offset 返回结果前跳过的匹配数
+	// Offset is the number of matches skipped before results are returned
+	Offset int
+
+	// YieldInterval 每访问多少个节点调用一次runtime.Gosched，避免对巨大文档的递归查询饿死同一P上的其它协程；0表示从不让出
+	// YieldInterval is the number of nodes visited between calls to runtime.Gosched, so a recursive query over a
+	// huge document does not starve other goroutines on the same P; 0 never yields
+	YieldInterval int
+
+	// Tolerance 过滤器中数字比较（==、!=、<、<=、>、>=）的容差，零值表示精确比较；
+	// 适合比较计算得到的浮点值，例如0.1+0.2与0.3
+	// Tolerance is the tolerance of numeric comparisons (==, !=, <, <=, >, >=) in filters, the zero value means
+	// exact comparison; it suits comparing computed floats, such as 0.1+0.2 and 0.3
+	Tolerance NumericTolerance
+
+	// NumberFormat 过滤器中字符串转换为数字时使用的格式，零值表示只接受strconv格式；字符串先按格式解析，
+	// 失败时再按strconv解析。==和!=的任一侧为字符串时两侧都按格式转换，因此字段和字面量可以各自是数字或
+	// 格式化的字符串
+	// NumberFormat is the format used when converting strings to numbers in filters, the zero value accepts only
+	// the strconv syntax; strings are parsed with the format first and with strconv on failure. When either side
+	// of == and != is a string both sides are converted with the format, so the field and the literal may each
+	// be a number or a formatted string
+	NumberFormat NumberFormat
+}
+
+// DefaultQueryOptions 返回默认查询选项（不分页）
+// DefaultQueryOptions returns default query options (no paging)
+func DefaultQueryOptions() *QueryOptions {
+	return &QueryOptions{
+		Limit:         0,
+		Offset:        0,
+		YieldInterval: 0,
+		Tolerance:     NumericTolerance{},
+		NumberFormat:  NumberFormat{},
+	}
+}
+
+// SelectAllWithOptions 根据路径分页选择匹配的值
+// SelectAllWithOptions selects a page of matching values by path
+//
+// 匹配按深度优先顺序逐个产生，顺序与SelectAll相同；取满Offset+Limit个匹配后立即停止，
+// 不会展开剩余的文档，递归下降也是如此；只有自定义段的结果由段本身一次性返回
+// Matches are produced one by one in depth-first order, the same order as SelectAll; the query stops as soon
+// as Offset+Limit matches are collected without expanding the rest of the document, recursive descent
+// included; only custom segments return their results at once from the segment itself
+//
+// 示例 Example:
+//
+//	page, err := xyJson.SelectAllWithOptions(root, "$.events[*]", &xyJson.QueryOptions{Limit: 100, Offset: 200})
+func (pq *pathQuery) SelectAllWithOptions(root IValue, path string, options *QueryOptions) ([]IValue, error) {
+	if root == nil {
+		return nil, NewPathNotFoundError(path)
+	}
+
+	var segments []*pathSegment
+	if path != "" && path != "$" {
+		var err error
+		if segments, err = pq.parsePath(path); err != nil {
+			return nil, err
+		}
+	}
+	return pq.selectPage(root, segments, options)
+}
+
+// SelectAllWithOptions 使用默认路径查询器分页选择匹配的值
+// SelectAllWithOptions selects a page of matching values using the default path query
+//
+// 默认路径查询器被WithPathQuery替换为其它实现时，先查询全部匹配再分页
+// When the default path query has been replaced through WithPathQuery by another implementation, all matches
+// are queried before paging
+func SelectAllWithOptions(root IValue, path string, options *QueryOptions) ([]IValue, error) {
+	query := defaultPathQuery()
+	if pq, ok := query.(*pathQuery); ok {
+		return pq.SelectAllWithOptions(root, path, options)
+	}
+
+	if err := validateQueryOptions(options); err != nil {
+		return nil, err
+	}
+	results, err := query.SelectAll(root, path)
+	if err != nil {
+		return nil, err
+	}
+	return pageResults(results, options), nil
+}
+
+// QueryAllWithOptions 使用预编译路径分页查询匹配的值
+// QueryAllWithOptions queries a page of matching values using the compiled path
+func (cp *CompiledPath) QueryAllWithOptions(root IValue, options *QueryOptions) ([]IValue, error) {
+	if root == nil {
+		return nil, NewPathNotFoundError(cp.originalPath)
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	pq := &pathQuery{factory: cp.factory}
+	return pq.selectPage(root, cp.segments, options)
+}
+
+// selectPage 深度优先执行查询并在取满一页后停止
+// selectPage runs the query depth-first and stops once the page is full
+func (pq *pathQuery) selectPage(root IValue, segments []*pathSegment, options *QueryOptions) ([]IValue, error) {
+	if err := validateQueryOptions(options); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = DefaultQueryOptions()
+	}
+	if options.YieldInterval > 0 || !options.Tolerance.isExact() || options.NumberFormat.DecimalSeparator != 0 {
+		// 计数器、容差和数字格式属于单次查询，查询器本身可以被多个协程共享
+		// The counter, tolerance and number format belong to this query, the query itself may be shared between
+		// goroutines
+		state := *pq
+		state.yield = newYielder(options.YieldInterval)
+		state.tolerance = options.Tolerance
+		state.numberFormat = options.NumberFormat
+		pq = &state
+	}
+
+	results := []IValue{}
+	skipped := 0
+	pq.walkQuery(root, segments, func(value IValue) bool {
+		if skipped < options.Offset {
+			skipped++
+			return true
+		}
+		results = append(results, value)
+		return options.Limit == 0 || len(results) < options.Limit
+	})
+	return results, nil
+}
+
+// walkQuery 深度优先地对每个匹配调用fn，fn返回false时停止并返回false
+// walkQuery calls fn for every match depth-first, stopping and returning false when fn returns false
+func (pq *pathQuery) walkQuery(value IValue, segments []*pathSegment, fn func(IValue) bool) bool {
+	if len(segments) == 0 {
+		return fn(value)
+	}
+	return pq.streamSegment(value, segments[0], func(match IValue) bool {
+		return pq.walkQuery(match, segments[1:], fn)
+	})
+}
+
+// streamSegment 按selectSegment的顺序逐个产生段的匹配，fn返回false时停止并返回false；
+// 自定义段一次性返回全部结果，因此先取得结果再逐个产生
+// streamSegment yields the matches of a segment one by one in the order of selectSegment, stopping and
+// returning false when fn returns false; custom segments return all their results at once, so those are
+// collected first and then yielded
+func (pq *pathQuery) streamSegment(value IValue, segment *pathSegment, fn func(IValue) bool) bool {
+	if segment.Recursive {
+		return pq.streamRecursive(value, segment, fn)
+	}
+
+	switch segment.Type {
+	case PropertySegmentType:
+		if !segment.Wildcard {
+			obj, ok := value.(IObject)
+			if !ok {
+				return true
+			}
+			pq.visit(1)
+			if val := obj.Get(segment.Key); val != nil {
+				return fn(val)
+			}
+			return true
+		}
+		return pq.streamChildren(value, fn)
+	case IndexSegmentType:
+		arr, ok := value.(IArray)
+		if !ok {
+			return true
+		}
+		if segment.Wildcard {
+			return pq.streamChildren(arr, fn)
+		}
+		pq.visit(1)
+		if index, ok := normalizeIndex(segment.Index, arr.Length()); ok {
+			if val := arr.Get(index); val != nil {
+				return fn(val)
+			}
+		}
+		return true
+	case FilterSegmentType:
+		arr, ok := value.(IArray)
+		if !ok {
+			return true
+		}
+		for i := 0; i < arr.Length(); i++ {
+			pq.visit(1)
+			if elem := arr.Get(i); elem != nil && pq.evaluateFilter(elem, segment.Filter) && !fn(elem) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, match := range pq.selectSegment(value, segment, true) {
+		if match != nil && !fn(match) {
+			return false
+		}
+	}
+	return true
+}
+
+// streamChildren 逐个产生对象或数组的直接子值
+// streamChildren yields the direct children of an object or array one by one
+func (pq *pathQuery) streamChildren(value IValue, fn func(IValue) bool) bool {
+	switch v := value.(type) {
+	case IObject:
+		for _, key := range v.Keys() {
+			pq.visit(1)
+			if val := v.Get(key); val != nil && !fn(val) {
+				return false
+			}
+		}
+	case IArray:
+		for i := 0; i < v.Length(); i++ {
+			pq.visit(1)
+			if val := v.Get(i); val != nil && !fn(val) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// streamRecursive 按selectRecursive的顺序逐个产生递归下降的匹配：先是当前节点的匹配，再依次递归每个子节点
+// streamRecursive yields the matches of a recursive descent one by one in the order of selectRecursive: the
+// matches of the current node first, then each child recursively
+func (pq *pathQuery) streamRecursive(value IValue, segment *pathSegment, fn func(IValue) bool) bool {
+	pq.visit(1)
+	if segment.Key != "" {
+		if obj, ok := value.(IObject); ok {
+			if val := obj.Get(segment.Key); val != nil && !fn(val) {
+				return false
+			}
+		}
+	} else if segment.Wildcard && !pq.streamChildren(value, fn) {
+		return false
+	}
+
+	switch v := value.(type) {
+	case IObject:
+		for _, key := range v.Keys() {
+			if val := v.Get(key); val != nil && !pq.streamRecursive(val, segment, fn) {
+				return false
+			}
+		}
+	case IArray:
+		for i := 0; i < v.Length(); i++ {
+			if val := v.Get(i); val != nil && !pq.streamRecursive(val, segment, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validateQueryOptions 校验查询选项
+// validateQueryOptions validates query options
+func validateQueryOptions(options *QueryOptions) error {
+	if options == nil {
+		return nil
+	}
+	if options.Limit < 0 {
+		return NewInvalidOperationError("select with options", "limit cannot be negative")
+	}
+	if options.Offset < 0 {
+		return NewInvalidOperationError("select with options", "offset cannot be negative")
+	}
+	if options.YieldInterval < 0 {
+		return NewInvalidOperationError("select with options", "yield interval cannot be negative")
+	}
+	return nil
+}
+
+// pageResults 对已取得的全部结果分页
+// pageResults pages results that were fully materialized
+func pageResults(results []IValue, options *QueryOptions) []IValue {
+	if options == nil {
+		return results
+	}
+	if options.Offset >= len(results) {
+		return []IValue{}
+	}
+	results = results[options.Offset:]
+	if options.Limit > 0 && options.Limit < len(results) {
+		results = results[:options.Limit]
+	}
+	return results
+}
@@ -0,0 +1,384 @@
+package xyJson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SetRawBytes 将rawValue写入原始JSON中path指向的位置并返回新的字节数组，不解析和序列化整个文档
+// SetRawBytes splices rawValue into raw JSON at path and returns the new byte array, without parsing or
+// serializing the whole document
+//
+// 只扫描到达目标所需的部分，其余字节原样复制，适合对大文档做单字段改写的热点路径。path只能由
+// 属性和索引组成（包括负索引和[+]），不支持通配符、过滤器和递归下降。缺失的属性和中间容器会被创建，
+// 超出数组长度的索引用null填充，与Set的行为相同（包括StrictArrayIndex）；新增的内容为紧凑格式。
+// rawValue必须是一个有效的JSON值；data假定为有效JSON，只有扫描过的部分会被检查。data不会被修改
+// Only the parts needed to reach the target are scanned and all other bytes are copied verbatim, suited to
+// hot paths doing single-field rewrites on large documents. path may only consist of properties and indices
+// (including negative indices and [+]); wildcards, filters and recursive descent are not supported. Missing
+// properties and intermediate containers are created and indices past the end of an array are padded with
+// nulls, the same as Set (including StrictArrayIndex); added content is compact. rawValue must be a single
+// valid JSON value; data is assumed to be valid JSON and only the scanned parts are checked. data is not
+// modified
+//
+// 示例 Example:
+//
+//	updated, err := xyJson.SetRawBytes(payload, "$.order.status", []byte(`"shipped"`))
+//	updated, err = xyJson.SetRawBytes(updated, "$.order.events[+]", []byte(`{"at":1700000000}`))
+func SetRawBytes(data []byte, path string, rawValue []byte) ([]byte, error) {
+	rawValue = bytes.TrimSpace(rawValue)
+	if !json.Valid(rawValue) {
+		return nil, NewInvalidJSONError("invalid raw value", nil)
+	}
+
+	compiled, err := CompilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	compiled.mu.RLock()
+	segments := compiled.segments
+	compiled.mu.RUnlock()
+
+	for _, segment := range segments {
+		if segment.Recursive || segment.Wildcard || segment.Filter != nil ||
+			(segment.Type != PropertySegmentType && segment.Type != IndexSegmentType) {
+			return nil, NewInvalidOperationError("set raw bytes", "only properties and indices are supported: "+path)
+		}
+	}
+
+	rs := &rawScanner{data: data}
+	rs.skipWhitespace()
+	if rs.pos >= len(data) {
+		return nil, NewInvalidJSONError("unexpected end of input", nil)
+	}
+
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		start, end, insertAt, prefix, err := rs.locate(segment, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if insertAt < 0 {
+			if last {
+				return splice(data, start, end, rawValue), nil
+			}
+			rs.pos = start
+			continue
+		}
+
+		// 目标不存在，在容器末尾插入
+		// The target does not exist, insert it at the end of the container
+		value, err := buildRawValue(segments[i+1:], rawValue, path)
+		if err != nil {
+			return nil, err
+		}
+		return splice(data, insertAt, insertAt, append(prefix, value...)), nil
+	}
+
+	// 根路径替换整个文档
+	// The root path replaces the whole document
+	return append([]byte(nil), rawValue...), nil
+}
+
+// splice 返回data[start:end]被替换为insert的副本
+// splice returns a copy of data with data[start:end] replaced by insert
+func splice(data []byte, start, end int, insert []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(insert))
+	out = append(out, data[:start]...)
+	out = append(out, insert...)
+	return append(out, data[end:]...)
+}
+
+// buildRawValue 为剩余的路径段构造嵌套容器，最内层为rawValue
+// buildRawValue builds nested containers for the remaining path segments, with rawValue innermost
+func buildRawValue(segments []*pathSegment, rawValue []byte, path string) ([]byte, error) {
+	value := rawValue
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		var buf bytes.Buffer
+		if segment.Type == PropertySegmentType {
+			buf.WriteByte('{')
+			if err := mappingSerializer.serializeString(segment.Key, &buf); err != nil {
+				return nil, err
+			}
+			buf.WriteByte(':')
+			buf.Write(value)
+			buf.WriteByte('}')
+		} else {
+			index := segment.Index
+			if segment.Append {
+				index = 0
+			}
+			if index < 0 || index > 0 && GetGlobalConfig().JSONPath.StrictArrayIndex {
+				return nil, NewIndexOutOfRangeError(segment.Index, 0, path)
+			}
+			buf.WriteByte('[')
+			buf.WriteString(strings.Repeat("null,", index))
+			buf.Write(value)
+			buf.WriteByte(']')
+		}
+		value = buf.Bytes()
+	}
+	return value, nil
+}
+
+// rawScanner 在原始JSON字节上定位值，不构造IValue
+// rawScanner locates values in raw JSON bytes without building IValues
+type rawScanner struct {
+	data []byte
+	pos  int
+}
+
+// locate 在当前位置的容器中查找段指向的值。找到时返回值的范围[start, end)且insertAt为-1；
+// 否则返回插入新内容的位置insertAt以及插入内容前需要的前缀（逗号、键和填充的null）
+// locate finds the value a segment refers to in the container at the current position. When found it
+// returns the value's range [start, end) with insertAt -1; otherwise it returns the position insertAt where
+// new content goes and the prefix needed before it (comma, key and null padding)
+func (rs *rawScanner) locate(segment *pathSegment, path string) (start, end, insertAt int, prefix []byte, err error) {
+	if segment.Type == PropertySegmentType {
+		if rs.data[rs.pos] != '{' {
+			return 0, 0, 0, nil, NewTypeMismatchError(ObjectValueType, rawValueType(rs.data[rs.pos]), path)
+		}
+		return rs.locateMember(segment.Key)
+	}
+	if rs.data[rs.pos] != '[' {
+		return 0, 0, 0, nil, NewTypeMismatchError(ArrayValueType, rawValueType(rs.data[rs.pos]), path)
+	}
+	return rs.locateElement(segment, path)
+}
+
+// locateMember 在对象中查找键，重复键时使用第一个
+// locateMember finds a key in an object, using the first one for duplicate keys
+func (rs *rawScanner) locateMember(key string) (start, end, insertAt int, prefix []byte, err error) {
+	rs.pos++ // 跳过 '{'
+	rs.skipWhitespace()
+	empty := rs.pos < len(rs.data) && rs.data[rs.pos] == '}'
+
+	for !empty {
+		rs.skipWhitespace()
+		if rs.pos >= len(rs.data) || rs.data[rs.pos] != '"' {
+			return 0, 0, 0, nil, rs.syntaxError("expected string key in object")
+		}
+		keyStart := rs.pos + 1
+		if err := rs.skipString(); err != nil {
+			return 0, 0, 0, nil, err
+		}
+		matched, err := rawKeyEquals(rs.data[keyStart:rs.pos-1], key)
+		if err != nil {
+			return 0, 0, 0, nil, err
+		}
+
+		rs.skipWhitespace()
+		if rs.pos >= len(rs.data) || rs.data[rs.pos] != ':' {
+			return 0, 0, 0, nil, rs.syntaxError("expected ':'")
+		}
+		rs.pos++
+		rs.skipWhitespace()
+		start = rs.pos
+		if err := rs.skipValue(); err != nil {
+			return 0, 0, 0, nil, err
+		}
+		if matched {
+			return start, rs.pos, -1, nil, nil
+		}
+
+		rs.skipWhitespace()
+		if rs.pos >= len(rs.data) {
+			return 0, 0, 0, nil, rs.syntaxError("unexpected end of input in object")
+		}
+		if rs.data[rs.pos] == '}' {
+			break
+		}
+		if rs.data[rs.pos] != ',' {
+			return 0, 0, 0, nil, rs.syntaxError("expected ',' or '}'")
+		}
+		rs.pos++
+	}
+
+	var buf bytes.Buffer
+	if !empty {
+		buf.WriteByte(',')
+	}
+	if err := mappingSerializer.serializeString(key, &buf); err != nil {
+		return 0, 0, 0, nil, err
+	}
+	buf.WriteByte(':')
+	return 0, 0, rs.pos, buf.Bytes(), nil
+}
+
+// locateElement 在数组中查找索引；负索引和[+]需要扫描整个数组
+// locateElement finds an index in an array; negative indices and [+] need the whole array scanned
+func (rs *rawScanner) locateElement(segment *pathSegment, path string) (start, end, insertAt int, prefix []byte, err error) {
+	rs.pos++ // 跳过 '['
+	rs.skipWhitespace()
+
+	var starts, ends []int
+	for rs.pos < len(rs.data) && rs.data[rs.pos] != ']' {
+		rs.skipWhitespace()
+		start = rs.pos
+		if err := rs.skipValue(); err != nil {
+			return 0, 0, 0, nil, err
+		}
+		if !segment.Append && segment.Index == len(starts) {
+			return start, rs.pos, -1, nil, nil
+		}
+		starts, ends = append(starts, start), append(ends, rs.pos)
+
+		rs.skipWhitespace()
+		if rs.pos >= len(rs.data) {
+			return 0, 0, 0, nil, rs.syntaxError("unexpected end of input in array")
+		}
+		if rs.data[rs.pos] == ']' {
+			break
+		}
+		if rs.data[rs.pos] != ',' {
+			return 0, 0, 0, nil, rs.syntaxError("expected ',' or ']'")
+		}
+		rs.pos++
+		rs.skipWhitespace()
+	}
+	if rs.pos >= len(rs.data) {
+		return 0, 0, 0, nil, rs.syntaxError("unexpected end of input in array")
+	}
+
+	length := len(starts)
+	index := segment.Index
+	switch {
+	case segment.Append:
+		index = length
+	case index < 0:
+		normalized, ok := normalizeIndex(index, length)
+		if !ok {
+			return 0, 0, 0, nil, NewIndexOutOfRangeError(index, length, path)
+		}
+		return starts[normalized], ends[normalized], -1, nil, nil
+	case index > length && GetGlobalConfig().JSONPath.StrictArrayIndex:
+		return 0, 0, 0, nil, NewIndexOutOfRangeError(index, length, path)
+	}
+
+	prefix = []byte(strings.Repeat("null,", index-length))
+	if length > 0 {
+		prefix = append([]byte{','}, prefix...)
+	}
+	return 0, 0, rs.pos, prefix, nil
+}
+
+// skipValue 跳过一个完整的JSON值
+// skipValue skips one complete JSON value
+func (rs *rawScanner) skipValue() error {
+	nesting := 0
+	for {
+		if nesting > 0 {
+			rs.skipWhitespace()
+		}
+		if rs.pos >= len(rs.data) {
+			return rs.syntaxError("unexpected end of input")
+		}
+
+		switch b := rs.data[rs.pos]; b {
+		case '{', '[':
+			nesting++
+			if nesting > MaxNestingDepth {
+				return NewMaxDepthExceededError(MaxNestingDepth)
+			}
+			rs.pos++
+			continue
+		case '}', ']':
+			nesting--
+			if nesting < 0 {
+				return rs.syntaxError("unexpected " + string(b))
+			}
+			rs.pos++
+		case ',', ':':
+			rs.pos++
+			continue
+		case '"':
+			if err := rs.skipString(); err != nil {
+				return err
+			}
+		default:
+			if !isLiteralByte(b) {
+				return rs.syntaxError("unexpected character: " + string(b))
+			}
+			for rs.pos < len(rs.data) && isLiteralByte(rs.data[rs.pos]) {
+				rs.pos++
+			}
+		}
+
+		if nesting == 0 {
+			return nil
+		}
+	}
+}
+
+// skipString 跳过字符串，包括引号
+// skipString skips a string, including its quotes
+func (rs *rawScanner) skipString() error {
+	rs.pos++ // 跳过开始引号
+	for rs.pos < len(rs.data) {
+		switch rs.data[rs.pos] {
+		case '\\':
+			rs.pos += 2
+			continue
+		case '"':
+			rs.pos++
+			return nil
+		}
+		rs.pos++
+	}
+	return rs.syntaxError("unexpected end of input in string")
+}
+
+// skipWhitespace 跳过空白字符
+// skipWhitespace skips whitespace
+func (rs *rawScanner) skipWhitespace() {
+	for rs.pos < len(rs.data) {
+		switch rs.data[rs.pos] {
+		case ' ', '\t', '\n', '\r':
+			rs.pos++
+		default:
+			return
+		}
+	}
+}
+
+// syntaxError 创建带偏移量的语法错误
+// syntaxError creates a syntax error carrying the byte offset
+func (rs *rawScanner) syntaxError(message string) error {
+	return NewInvalidJSONError(fmt.Sprintf("%s at offset %d", message, rs.pos), nil)
+}
+
+// rawKeyEquals 比较原始（可能含转义的）键与key
+// rawKeyEquals compares a raw, possibly escaped key with key
+func rawKeyEquals(raw []byte, key string) (bool, error) {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw) == key, nil
+	}
+	unescaped, err := (&parser{}).unescapeString(string(raw))
+	if err != nil {
+		return false, err
+	}
+	return unescaped == key, nil
+}
+
+// rawValueType 根据首字节判断原始值的类型
+// rawValueType determines the type of a raw value from its first byte
+func rawValueType(b byte) ValueType {
+	switch b {
+	case '{':
+		return ObjectValueType
+	case '[':
+		return ArrayValueType
+	case '"':
+		return StringValueType
+	case 't', 'f':
+		return BoolValueType
+	case 'n':
+		return NullValueType
+	default:
+		return NumberValueType
+	}
+}
@@ -0,0 +1,432 @@
+package xyJson
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RefLoader 按URI加载远程文档，URI已相对于引用所在文档解析，不含#片段
+// RefLoader loads a remote document by URI, the URI is already resolved against the referencing document and
+// carries no # fragment
+type RefLoader func(uri string) (IValue, error)
+
+// ResolveRefs 返回将所有$ref内联后的文档副本，原文档不会被修改
+// ResolveRefs returns a copy of the document with every $ref inlined, the original document is left untouched
+//
+// 本地引用（#/definitions/X）相对于引用所在的文档解析，远程引用（other.json#/X）通过loader加载，
+// 相对URI相对于引用所在文档的URI解析，每个URI只加载一次。与$ref并列的其它成员被忽略。
+// 引用自身展开中再次出现时返回ErrCircularReference错误；loader为nil时远程引用返回错误
+// Local references (#/definitions/X) resolve against the document they appear in, remote references
+// (other.json#/X) are loaded through loader, relative URIs resolve against the URI of the referencing document
+// and every URI is loaded once. Members next to $ref are ignored. A reference that reappears inside its own
+// expansion returns an ErrCircularReference error; remote references return an error when loader is nil
+//
+// 示例 Example:
+//
+//	schema := xyJson.MustParseString(`{"properties":{"user":{"$ref":"user.json"}}}`)
+//	resolved, err := xyJson.ResolveRefs(schema, func(uri string) (xyJson.IValue, error) {
+//		return xyJson.ParseFile(filepath.Join("schemas", uri))
+//	})
+func ResolveRefs(root IValue, loader RefLoader) (_ IValue, err error) {
+	defer recoverPanic("resolve refs", &err)
+	if root == nil {
+		return nil, NewNullPointerError("root cannot be nil")
+	}
+	r := newRefResolver(root, loader)
+	return r.inline(root, "", "$", make(map[string]bool), 0)
+}
+
+// Bundle 返回将远程$ref改写为本地引用后的文档副本，被引用的远程值复制到根对象的$defs中
+// Bundle returns a copy of the document with remote $refs rewritten into local references, the referenced remote
+// values are copied into $defs of the root object
+//
+// 根对象已有definitions而没有$defs时使用definitions。名称取自引用的最后一个指针段或文件名，重名时添加数字后缀。
+// 远程文档中的本地引用同样被改写，因此打包后的文档不再依赖loader；根文档中的本地引用保持不变，递归引用被保留为引用
+// definitions is used when the root object has it and no $defs. Names come from the last pointer token or the
+// file name of the reference, with a numeric suffix on collisions. Local references inside remote documents are
+// rewritten as well, so the bundled document no longer depends on loader; local references of the root
+// document are kept and recursive references stay references
+//
+// 示例 Example:
+//
+//	bundled, err := xyJson.Bundle(schema, loader)
+//	// {"properties":{"user":{"$ref":"#/$defs/user"}},"$defs":{"user":{...}}}
+func Bundle(root IValue, loader RefLoader) (_ IValue, err error) {
+	defer recoverPanic("bundle", &err)
+	if root == nil {
+		return nil, NewNullPointerError("root cannot be nil")
+	}
+
+	b := &refBundler{refResolver: newRefResolver(root, loader), names: make(map[string]string)}
+	out, err := b.copy(root, "", "$", 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.pending) == 0 {
+		return out, nil
+	}
+
+	obj, ok := out.(IObject)
+	if !ok || out.Type() != ObjectValueType {
+		return nil, NewInvalidOperationError("bundle", "remote references need an object root to hold the bundled definitions")
+	}
+	var defs IObject
+	if existing := obj.Get(b.defsKey); existing == nil {
+		defs = NewObject()
+		obj.Set(b.defsKey, defs)
+	} else if defs = existing.AsObject(); defs == nil {
+		return nil, NewInvalidOperationError("bundle", b.defsKey+" of the root must be an object")
+	}
+
+	// 复制目标时可能发现新的远程引用，pending会继续增长
+	// Copying a target may discover further remote references, so pending keeps growing
+	for i := 0; i < len(b.pending); i++ {
+		target := b.pending[i]
+		value, err := b.copy(target.value, target.base, "$", 0)
+		if err != nil {
+			return nil, err
+		}
+		defs.Set(target.name, value)
+	}
+	return out, nil
+}
+
+// refResolver 解析$ref的目标，缓存已加载的远程文档
+// refResolver resolves the targets of $refs, caching the loaded remote documents
+type refResolver struct {
+	root   IValue
+	loader RefLoader
+	docs   map[string]IValue
+}
+
+// newRefResolver 创建解析器，根文档的URI为空字符串
+// newRefResolver creates a resolver, the root document has an empty URI
+func newRefResolver(root IValue, loader RefLoader) *refResolver {
+	return &refResolver{root: root, loader: loader, docs: map[string]IValue{"": root}}
+}
+
+// target 解析base文档中的引用，返回目标的规范键、所在文档的URI、指针和值
+// target resolves a reference in the base document, returning the canonical key of the target, the URI of its
+// document, its pointer and its value
+func (r *refResolver) target(base, ref, at string) (key, uri, pointer string, value IValue, err error) {
+	uri, fragment, _ := strings.Cut(ref, "#")
+	if uri == "" {
+		uri = base
+	} else if uri, err = resolveRefURI(base, uri); err != nil {
+		return "", "", "", nil, NewInvalidOperationError("resolve refs", "invalid $ref "+strconv.Quote(ref)+" at "+at)
+	}
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		return "", "", "", nil, NewInvalidOperationError("resolve refs", "only JSON Pointer fragments are supported: "+strconv.Quote(ref)+" at "+at)
+	}
+
+	doc, err := r.load(uri, at)
+	if err != nil {
+		return "", "", "", nil, err
+	}
+	value = lookupPointer(doc, fragment)
+	if value == nil {
+		return "", "", "", nil, NewJSONError(ErrPathNotFound, "unresolvable $ref "+strconv.Quote(ref), nil).WithPath(at)
+	}
+	return uri + "#" + fragment, uri, fragment, value, nil
+}
+
+// load 返回URI对应的文档，每个URI只调用loader一次
+// load returns the document of the URI, calling loader once per URI
+func (r *refResolver) load(uri, at string) (IValue, error) {
+	if doc, ok := r.docs[uri]; ok {
+		return doc, nil
+	}
+	if r.loader == nil {
+		return nil, NewInvalidOperationError("resolve refs", "remote reference "+strconv.Quote(uri)+" at "+at+" needs a loader")
+	}
+	doc, err := r.loader(uri)
+	if err != nil {
+		return nil, NewJSONError(ErrInvalidOperation, "cannot load "+strconv.Quote(uri), err).WithPath(at)
+	}
+	if doc == nil {
+		return nil, NewNullPointerError("loader returned nil for " + strconv.Quote(uri))
+	}
+	r.docs[uri] = doc
+	return doc, nil
+}
+
+// inline 复制值并递归展开其中的$ref，stack记录正在展开的引用用于检测循环
+// inline copies the value and recursively expands the $refs in it, stack records the references being expanded
+// to detect cycles
+func (r *refResolver) inline(value IValue, base, at string, stack map[string]bool, depth int) (IValue, error) {
+	if depth > MaxNestingDepth {
+		return nil, NewMaxDepthExceededError(MaxNestingDepth)
+	}
+
+	switch value.Type() {
+	case ObjectValueType:
+		obj := value.(IObject)
+		if ref, ok := refOf(obj); ok {
+			key, uri, _, target, err := r.target(base, ref, at)
+			if err != nil {
+				return nil, err
+			}
+			if stack[key] {
+				return nil, NewJSONError(ErrCircularReference, "circular $ref "+strconv.Quote(ref), nil).WithPath(at)
+			}
+			stack[key] = true
+			defer delete(stack, key)
+			return r.inline(target, uri, at, stack, depth+1)
+		}
+
+		out := NewObjectWithCapacity(obj.Size())
+		var err error
+		obj.Range(func(key string, child IValue) bool {
+			var copied IValue
+			if copied, err = r.inline(child, base, appendPathKey(at, key), stack, depth+1); err != nil {
+				return false
+			}
+			out.Set(key, copied)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	case ArrayValueType:
+		arr := value.(IArray)
+		out := NewArray()
+		var err error
+		arr.Range(func(index int, child IValue) bool {
+			var copied IValue
+			if copied, err = r.inline(child, base, fmt.Sprintf("%s[%d]", at, index), stack, depth+1); err != nil {
+				return false
+			}
+			out.Append(copied)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	default:
+		return value.Clone(), nil
+	}
+}
+
+// bundledTarget 等待复制到定义中的远程目标
+// bundledTarget is a remote target waiting to be copied into the definitions
+type bundledTarget struct {
+	name  string
+	base  string
+	value IValue
+}
+
+// refBundler 将远程引用改写为指向根文档定义的本地引用
+// refBundler rewrites remote references into local references to the definitions of the root document
+type refBundler struct {
+	*refResolver
+	defsKey string
+	names   map[string]string
+	used    map[string]bool
+	pending []bundledTarget
+}
+
+// copy 复制值并改写其中的远程引用，根文档中的本地引用保持不变
+// copy copies the value and rewrites the remote references in it, local references of the root document are kept
+func (b *refBundler) copy(value IValue, base, at string, depth int) (IValue, error) {
+	if depth > MaxNestingDepth {
+		return nil, NewMaxDepthExceededError(MaxNestingDepth)
+	}
+
+	switch value.Type() {
+	case ObjectValueType:
+		obj := value.(IObject)
+		ref, isRef := refOf(obj)
+		rewrite := isRef && (base != "" || !strings.HasPrefix(ref, "#"))
+		out := NewObjectWithCapacity(obj.Size())
+		var err error
+		obj.Range(func(key string, child IValue) bool {
+			var copied IValue
+			if rewrite && key == "$ref" {
+				var local string
+				if local, err = b.local(base, ref, at); err != nil {
+					return false
+				}
+				copied = CreateString(local)
+			} else if copied, err = b.copy(child, base, appendPathKey(at, key), depth+1); err != nil {
+				return false
+			}
+			out.Set(key, copied)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	case ArrayValueType:
+		arr := value.(IArray)
+		out := NewArray()
+		var err error
+		arr.Range(func(index int, child IValue) bool {
+			var copied IValue
+			if copied, err = b.copy(child, base, fmt.Sprintf("%s[%d]", at, index), depth+1); err != nil {
+				return false
+			}
+			out.Append(copied)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	default:
+		return value.Clone(), nil
+	}
+}
+
+// local 返回远程引用改写后的本地引用，首次遇到的目标加入待复制队列
+// local returns the local reference a remote reference is rewritten to, queueing targets seen for the first time
+func (b *refBundler) local(base, ref, at string) (string, error) {
+	key, uri, pointer, value, err := b.target(base, ref, at)
+	if err != nil {
+		return "", err
+	}
+	if uri == "" {
+		// 远程文档引用回根文档
+		// A remote document referring back into the root document
+		return "#" + pointer, nil
+	}
+
+	if b.defsKey == "" {
+		b.defsKey = "$defs"
+		if obj, ok := b.root.(IObject); ok && b.root.Type() == ObjectValueType && !obj.Has("$defs") && obj.Has("definitions") {
+			b.defsKey = "definitions"
+		}
+	}
+
+	name, ok := b.names[key]
+	if !ok {
+		name = b.uniqueName(refName(uri, pointer))
+		b.names[key] = name
+		b.pending = append(b.pending, bundledTarget{name: name, base: uri, value: value})
+	}
+	return "#/" + escapePointerToken(b.defsKey) + "/" + escapePointerToken(name), nil
+}
+
+// uniqueName 返回与根文档已有定义和已打包名称都不冲突的名称
+// uniqueName returns a name that collides neither with existing definitions of the root nor with bundled names
+func (b *refBundler) uniqueName(name string) string {
+	if b.used == nil {
+		b.used = make(map[string]bool)
+		if obj, ok := b.root.(IObject); ok && b.root.Type() == ObjectValueType {
+			if defs, ok := obj.Get(b.defsKey).(IObject); ok {
+				for _, key := range defs.Keys() {
+					b.used[key] = true
+				}
+			}
+		}
+	}
+
+	candidate := name
+	for i := 2; b.used[candidate]; i++ {
+		candidate = name + "_" + strconv.Itoa(i)
+	}
+	b.used[candidate] = true
+	return candidate
+}
+
+// refOf 返回对象的$ref字符串
+// refOf returns the $ref string of an object
+func refOf(obj IObject) (string, bool) {
+	ref := obj.Get("$ref")
+	if ref == nil || ref.Type() != StringValueType {
+		return "", false
+	}
+	return ref.AsString(), true
+}
+
+// refName 由指针的最后一段或文件名生成定义名称
+// refName derives a definition name from the last pointer token or the file name
+func refName(uri, pointer string) string {
+	if i := strings.LastIndexByte(pointer, '/'); i >= 0 && i < len(pointer)-1 {
+		return unescapePointerToken(pointer[i+1:])
+	}
+	name := path.Base(uri)
+	if ext := path.Ext(name); ext != "" && ext != name {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// resolveRefURI 相对于base解析引用中的URI
+// resolveRefURI resolves the URI of a reference against base
+func resolveRefURI(base, uri string) (string, error) {
+	ref, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if base == "" {
+		return ref.String(), nil
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	// ResolveReference会把相对的base视为绝对路径，相对文件路径按目录拼接
+	// ResolveReference treats a relative base as absolute, relative file paths are joined by directory
+	if baseURL.Scheme == "" && baseURL.Host == "" && !strings.HasPrefix(base, "/") {
+		if ref.Scheme != "" || ref.Host != "" || strings.HasPrefix(ref.Path, "/") {
+			return ref.String(), nil
+		}
+		return path.Join(path.Dir(baseURL.Path), ref.Path), nil
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
+
+// lookupPointer 按JSON Pointer（RFC 6901）查找值，空指针返回doc本身，不存在时返回nil
+// lookupPointer looks up a value by JSON Pointer (RFC 6901), an empty pointer returns doc itself and nil when absent
+func lookupPointer(doc IValue, pointer string) IValue {
+	if pointer == "" {
+		return doc
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil
+	}
+
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescapePointerToken(token)
+		switch node.Type() {
+		case ObjectValueType:
+			node = node.AsObject().Get(token)
+		case ArrayValueType:
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return nil
+			}
+			node = node.AsArray().Get(index)
+		default:
+			node = nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// escapePointerToken 按JSON Pointer规则转义一个引用段
+// escapePointerToken escapes a reference token by the JSON Pointer rules
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// unescapePointerToken 还原转义后的引用段
+// unescapePointerToken restores an escaped reference token
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
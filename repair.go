@@ -0,0 +1,312 @@
+package xyJson
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FixKind Repair应用的修复类型
+// FixKind is the kind of fix applied by Repair
+type FixKind int
+
+const (
+	// FixSingleQuotes 单引号字符串改为双引号字符串
+	// FixSingleQuotes turns a single-quoted string into a double-quoted one
+	FixSingleQuotes FixKind = iota
+	// FixUnquotedKey 为未加引号的对象键加上双引号
+	// FixUnquotedKey adds double quotes around an unquoted object key
+	FixUnquotedKey
+	// FixTrailingComma 删除右括号或输入结尾前多余的逗号
+	// FixTrailingComma removes a superfluous comma before a closing bracket or the end of input
+	FixTrailingComma
+	// FixMissingClose 补上缺失的右括号
+	// FixMissingClose adds a missing closing bracket
+	FixMissingClose
+	// FixUnterminatedString 在输入结尾补上字符串的右引号
+	// FixUnterminatedString adds the closing quote of a string cut off by the end of input
+	FixUnterminatedString
+)
+
+// String 返回修复类型的字符串表示
+// String returns the string representation of the fix kind
+func (fk FixKind) String() string {
+	switch fk {
+	case FixSingleQuotes:
+		return "single quotes"
+	case FixUnquotedKey:
+		return "unquoted key"
+	case FixTrailingComma:
+		return "trailing comma"
+	case FixMissingClose:
+		return "missing close"
+	case FixUnterminatedString:
+		return "unterminated string"
+	default:
+		return "unknown"
+	}
+}
+
+// Fix Repair应用的一处修复
+// Fix is one fix applied by Repair
+type Fix struct {
+	// Kind 修复类型
+	// Kind is the kind of fix
+	Kind FixKind
+
+	// Offset 修复在原始输入中的字节偏移
+	// Offset is the byte offset of the fix in the original input
+	Offset int
+}
+
+// String 返回修复的描述
+// String returns a description of the fix
+func (f Fix) String() string {
+	return f.Kind.String() + " at offset " + strconv.Itoa(f.Offset)
+}
+
+// Repair 尽力修复常见的JSON格式错误，返回修复后的JSON和按输入顺序列出的修复
+// Repair fixes common JSON defects on a best-effort basis, returning the repaired JSON and the fixes in input
+// order
+//
+// 修复单引号字符串、未加引号的键（由字母、数字、_、$和-组成）、多余的尾随逗号，以及输入结尾处缺失的
+// 右括号和右引号；嵌套内层未关闭就遇到外层右括号时也会补上内层的右括号。其余内容原样保留。
+// 修复后的结果仍不是合法JSON时返回错误以及已应用的修复
+// Single-quoted strings, unquoted keys (made of letters, digits, _, $ and -), superfluous trailing commas and
+// closing brackets and quotes missing at the end of input are fixed; closing brackets of inner containers are
+// also added when an outer closing bracket arrives first. Everything else is kept as is. An error is returned
+// along with the fixes applied when the result is still not valid JSON
+//
+// 示例 Example:
+//
+//	data, fixes, err := xyJson.Repair([]byte(`{name: 'feed', items: [1, 2,]`))
+//	// data: {"name": "feed", "items": [1, 2]}
+//	for _, fix := range fixes {
+//		log.Println(fix) // unquoted key at offset 1 ...
+//	}
+func Repair(data []byte) ([]byte, []Fix, error) {
+	r := &repairer{in: data, out: make([]byte, 0, len(data)+16), commaAt: -1}
+	if err := r.repair(); err != nil {
+		return nil, r.fixes, err
+	}
+
+	// 内部解析方法不会将校验失败记录为解析失败
+	// The internal parse method does not record the validation failure as a parse failure
+	if _, err := NewParserWithFactory(defaultFactory()).(*parser).parse(r.out); err != nil {
+		return nil, r.fixes, NewInvalidJSONError("cannot repair JSON", err)
+	}
+	return r.out, r.fixes, nil
+}
+
+// repairer 单遍扫描输入并写出修复后的JSON
+// repairer scans the input in a single pass and writes the repaired JSON
+type repairer struct {
+	in    []byte
+	pos   int
+	out   []byte
+	stack []byte
+	fixes []Fix
+
+	// commaAt、commaIn 最近的有效字符是逗号时它在输出和输入中的位置，否则commaAt为-1
+	// commaAt and commaIn are the output and input positions of the last significant character when it is a
+	// comma, commaAt is -1 otherwise
+	commaAt, commaIn int
+}
+
+// repair 扫描整个输入
+// repair scans the whole input
+func (r *repairer) repair() error {
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			r.out = append(r.out, c)
+			r.pos++
+			continue
+		case c == '"' || c == '\'':
+			r.repairString(c)
+		case c == '{' || c == '[':
+			r.stack = append(r.stack, c)
+			r.out = append(r.out, c)
+			r.pos++
+		case c == '}' || c == ']':
+			if err := r.close(c); err != nil {
+				return err
+			}
+			r.pos++
+		case c == ',':
+			r.out = append(r.out, c)
+			r.commaAt, r.commaIn = len(r.out)-1, r.pos
+			r.pos++
+			continue
+		case c == '-' || '0' <= c && c <= '9':
+			// 数字原样复制，指数中的字母不作为单词
+			// Numbers are copied as is, letters of exponents are not words
+			for r.pos < len(r.in) && isRepairNumberPart(r.in[r.pos]) {
+				r.out = append(r.out, r.in[r.pos])
+				r.pos++
+			}
+		case isRepairWordStart(c):
+			if err := r.repairWord(); err != nil {
+				return err
+			}
+		default:
+			r.out = append(r.out, c)
+			r.pos++
+		}
+		r.commaAt = -1
+	}
+
+	r.dropTrailingComma()
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		r.out = append(r.out, closerOf(r.stack[i]))
+		r.fix(FixMissingClose, len(r.in))
+	}
+	r.stack = r.stack[:0]
+	return nil
+}
+
+// repairString 复制字符串，单引号字符串转为双引号字符串，输入结尾处补上右引号
+// repairString copies a string, turning single-quoted strings into double-quoted ones and adding the closing
+// quote at the end of input
+func (r *repairer) repairString(quote byte) {
+	if quote == '\'' {
+		r.fix(FixSingleQuotes, r.pos)
+	}
+	start := r.pos
+	r.out = append(r.out, '"')
+	r.pos++
+
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		switch {
+		case c == quote:
+			r.out = append(r.out, '"')
+			r.pos++
+			return
+		case c == '\\':
+			if r.pos+1 >= len(r.in) {
+				// 丢弃结尾处不完整的转义 Drop an incomplete escape at the end
+				r.pos++
+				continue
+			}
+			next := r.in[r.pos+1]
+			if next == '\'' {
+				r.out = append(r.out, next)
+			} else {
+				r.out = append(r.out, c, next)
+			}
+			r.pos += 2
+		case c == '"':
+			r.out = append(r.out, '\\', c)
+			r.pos++
+		default:
+			r.out = append(r.out, c)
+			r.pos++
+		}
+	}
+
+	r.out = append(r.out, '"')
+	r.fix(FixUnterminatedString, start)
+}
+
+// repairWord 处理未加引号的单词：对象中后跟冒号的单词作为键加上引号，true、false和null原样保留
+// repairWord handles an unquoted word: a word followed by a colon inside an object is quoted as a key, true,
+// false and null are kept as is
+func (r *repairer) repairWord() error {
+	start := r.pos
+	for r.pos < len(r.in) && isRepairWordPart(r.in[r.pos]) {
+		r.pos++
+	}
+	word := r.in[start:r.pos]
+
+	next := r.pos
+	for next < len(r.in) && (r.in[next] == ' ' || r.in[next] == '\t' || r.in[next] == '\n' || r.in[next] == '\r') {
+		next++
+	}
+	if next < len(r.in) && r.in[next] == ':' && len(r.stack) > 0 && r.stack[len(r.stack)-1] == '{' {
+		r.out = append(r.out, '"')
+		r.out = append(r.out, word...)
+		r.out = append(r.out, '"')
+		r.fix(FixUnquotedKey, start)
+		return nil
+	}
+
+	switch string(word) {
+	case "true", "false", "null":
+		r.out = append(r.out, word...)
+		return nil
+	}
+	return NewInvalidJSONError(fmt.Sprintf("cannot repair unexpected token %q at offset %d", word, start), nil)
+}
+
+// close 处理右括号，补上尚未关闭的内层容器
+// close handles a closing bracket, adding the closers of inner containers still open
+func (r *repairer) close(c byte) error {
+	opener := byte('{')
+	if c == ']' {
+		opener = '['
+	}
+	match := -1
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		if r.stack[i] == opener {
+			match = i
+			break
+		}
+	}
+	if match < 0 {
+		return NewInvalidJSONError(fmt.Sprintf("cannot repair unmatched %q at offset %d", c, r.pos), nil)
+	}
+
+	r.dropTrailingComma()
+	for i := len(r.stack) - 1; i > match; i-- {
+		r.out = append(r.out, closerOf(r.stack[i]))
+		r.fix(FixMissingClose, r.pos)
+	}
+	r.stack = r.stack[:match]
+	r.out = append(r.out, c)
+	return nil
+}
+
+// dropTrailingComma 最近的有效字符是逗号时将其删除
+// dropTrailingComma removes the last significant character when it is a comma
+func (r *repairer) dropTrailingComma() {
+	if r.commaAt < 0 {
+		return
+	}
+	r.out = append(r.out[:r.commaAt], r.out[r.commaAt+1:]...)
+	r.fix(FixTrailingComma, r.commaIn)
+	r.commaAt = -1
+}
+
+// fix 记录一处修复
+// fix records a fix
+func (r *repairer) fix(kind FixKind, offset int) {
+	r.fixes = append(r.fixes, Fix{Kind: kind, Offset: offset})
+}
+
+// closerOf 返回开括号对应的右括号
+// closerOf returns the closing bracket of an opening bracket
+func closerOf(opener byte) byte {
+	if opener == '[' {
+		return ']'
+	}
+	return '}'
+}
+
+// isRepairWordStart 检查字节是否可以开始一个未加引号的单词
+// isRepairWordStart checks if a byte can start an unquoted word
+func isRepairWordStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$'
+}
+
+// isRepairWordPart 检查字节是否可以出现在未加引号的单词中
+// isRepairWordPart checks if a byte can appear in an unquoted word
+func isRepairWordPart(c byte) bool {
+	return isRepairWordStart(c) || '0' <= c && c <= '9' || c == '-'
+}
+
+// isRepairNumberPart 检查字节是否可以出现在数字中
+// isRepairNumberPart checks if a byte can appear in a number
+func isRepairNumberPart(c byte) bool {
+	return '0' <= c && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
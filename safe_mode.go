@@ -0,0 +1,53 @@
+package xyJson
+
+// WithSafeMode 返回开启或关闭安全模式的副本
+// WithSafeMode returns a copy with safe mode enabled or disabled
+//
+// 安全模式下，返回error的公共入口函数（Parse、Serialize、Get、Set、Unmarshal、To*等）会恢复内部的panic，
+// 例如反射赋值失败或自定义IValue实现的panic，并以ErrPanic错误码的*JSONError返回，适合不能因为畸形数据崩溃的服务。
+// Must*函数按约定仍然panic。默认关闭，此时panic照常向上传播
+// In safe mode the public entry points that return an error (Parse, Serialize, Get, Set, Unmarshal, To* and
+// so on) recover internal panics, such as failed reflect assignments or panics from custom IValue
+// implementations, and return them as a *JSONError with code ErrPanic; this suits services that cannot
+// tolerate crashes from malformed data. Must* functions still panic by contract. Safe mode is off by default,
+// in which case panics propagate as usual
+//
+// 示例 Example:
+//
+//	func main() {
+//		xyJson.WithDefaults(xyJson.CurrentDefaults().WithSafeMode(true))
+//		...
+//	}
+//
+//	var jsonErr *xyJson.JSONError
+//	if errors.As(err, &jsonErr) && jsonErr.Code == xyJson.ErrPanic {
+//		metrics.Inc("json_panics")
+//	}
+func (d *Defaults) WithSafeMode(enabled bool) *Defaults {
+	next := *d
+	next.safeMode = enabled
+	return &next
+}
+
+// SafeMode 返回是否开启了安全模式
+// SafeMode reports whether safe mode is enabled
+func (d *Defaults) SafeMode() bool {
+	return d.safeMode
+}
+
+// IsSafeMode 返回当前默认快照是否开启了安全模式
+// IsSafeMode reports whether safe mode is enabled in the current defaults snapshot
+func IsSafeMode() bool {
+	return currentDefaults.Load().safeMode
+}
+
+// recoverPanic 安全模式下将panic转换为错误写入err，必须直接用defer调用
+// recoverPanic converts a panic into an error stored in err in safe mode, it must be deferred directly
+func recoverPanic(operation string, err *error) {
+	if !currentDefaults.Load().safeMode {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = NewPanicError(operation, r)
+	}
+}
@@ -0,0 +1,320 @@
+package xyJson
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeReason 值在清洗时被移除的原因
+// SanitizeReason is the reason a value was removed during sanitization
+type SanitizeReason int
+
+const (
+	// SanitizeNotAllowed 键不在允许列表中
+	// SanitizeNotAllowed means the key is not on the allowlist
+	SanitizeNotAllowed SanitizeReason = iota
+	// SanitizeDenied 键在拒绝列表中
+	// SanitizeDenied means the key is on the denylist
+	SanitizeDenied
+	// SanitizeTooLong 键、字符串或数组超出长度限制
+	// SanitizeTooLong means the key, string or array exceeds a length limit
+	SanitizeTooLong
+	// SanitizeTooDeep 容器超出嵌套深度限制
+	// SanitizeTooDeep means the container exceeds the nesting depth limit
+	SanitizeTooDeep
+	// SanitizeDuplicateKey 规范化后的键与另一个键相同
+	// SanitizeDuplicateKey means the normalized key equals another key
+	SanitizeDuplicateKey
+)
+
+// String 返回移除原因的字符串表示
+// String returns the string representation of the removal reason
+func (sr SanitizeReason) String() string {
+	switch sr {
+	case SanitizeNotAllowed:
+		return "not allowed"
+	case SanitizeDenied:
+		return "denied"
+	case SanitizeTooLong:
+		return "too long"
+	case SanitizeTooDeep:
+		return "too deep"
+	case SanitizeDuplicateKey:
+		return "duplicate key"
+	default:
+		return "unknown"
+	}
+}
+
+// SanitizePolicy 入站文档的清洗策略，零值不做任何修改，长度限制为0表示不限制
+// SanitizePolicy is the sanitization policy for inbound documents, the zero value changes nothing and a length
+// limit of 0 means unlimited
+type SanitizePolicy struct {
+	// Allow 允许的字段树，不在其中的键被移除；nil表示允许所有键。可以由NewProjection或ProjectionOf创建
+	// Allow is the tree of allowed fields, keys outside it are removed; nil allows every key. It can be
+	// created with NewProjection or ProjectionOf
+	Allow *Projection
+
+	// Deny 在任意深度都被移除的键名
+	// Deny lists key names removed at any depth
+	Deny []string
+
+	// MaxKeyLength 键名的最大字节数，更长的键及其值被移除
+	// MaxKeyLength is the maximum number of bytes of a key, longer keys are removed with their values
+	MaxKeyLength int
+
+	// MaxStringLength 字符串的最大字节数，更长的字符串被移除
+	// MaxStringLength is the maximum number of bytes of a string, longer strings are removed
+	MaxStringLength int
+
+	// MaxArrayLength 数组的最大元素数，更长的数组被移除
+	// MaxArrayLength is the maximum number of items of an array, longer arrays are removed
+	MaxArrayLength int
+
+	// MaxDepth 容器的最大嵌套深度，更深的容器被移除
+	// MaxDepth is the maximum nesting depth of containers, deeper containers are removed
+	MaxDepth int
+
+	// NormalizeUnicode 规范化键和字符串：非法UTF-8替换为U+FFFD，移除除\t、\n、\r外的控制字符和
+	// 零宽、双向覆盖等不可见格式字符
+	// NormalizeUnicode normalizes keys and strings: invalid UTF-8 is replaced by U+FFFD, control characters
+	// other than \t, \n and \r and invisible format characters such as zero-width and bidi overrides are
+	// removed
+	NormalizeUnicode bool
+
+	// Normalizer 在NormalizeUnicode之后应用于键和字符串的额外规范化，例如golang.org/x/text/unicode/norm的NFC
+	// Normalizer is an extra normalization applied to keys and strings after NormalizeUnicode, such as NFC
+	// from golang.org/x/text/unicode/norm
+	Normalizer func(string) string
+}
+
+// SanitizeRemoval 一次移除的记录
+// SanitizeRemoval records one removal
+type SanitizeRemoval struct {
+	// Path 被移除值在原文档中的JSONPath
+	// Path is the JSONPath of the removed value in the original document
+	Path string
+
+	// Reason 移除原因
+	// Reason is why the value was removed
+	Reason SanitizeReason
+}
+
+// SanitizeReport 清洗报告
+// SanitizeReport is the sanitization report
+type SanitizeReport struct {
+	// Removed 按文档顺序列出的移除记录
+	// Removed lists the removals in document order
+	Removed []SanitizeRemoval
+
+	// Normalized 键名或字符串内容被规范化修改的值在原文档中的JSONPath
+	// Normalized lists the JSONPaths, in the original document, of values whose key or string content was
+	// changed by normalization
+	Normalized []string
+}
+
+// Clean 报告清洗是否没有做任何修改
+// Clean reports whether sanitization changed nothing
+func (sr *SanitizeReport) Clean() bool {
+	return len(sr.Removed) == 0 && len(sr.Normalized) == 0
+}
+
+// Sanitize 按策略清洗入站文档，返回清洗后的副本和记录移除内容的报告
+// Sanitize sanitizes an inbound document according to the policy, returning a sanitized copy and a report of
+// what was removed
+//
+// 键先规范化再与允许和拒绝列表比较，因此插入不可见字符无法绕过策略。数组中被移除的元素不留空位，
+// 报告中的路径使用原文档的索引。root本身不会被修改，根值被移除时返回null
+// Keys are normalized before they are checked against the allow and deny lists, so inserting invisible
+// characters cannot bypass the policy. Items removed from arrays leave no gap, the report uses the indices of
+// the original document. root itself is not modified, a removed root yields null
+//
+// 示例 Example:
+//
+//	allow, _ := xyJson.NewProjection("user.name", "items.sku", "items.qty")
+//	clean, report, err := xyJson.Sanitize(body, xyJson.SanitizePolicy{
+//		Allow:            allow,
+//		MaxStringLength:  256,
+//		NormalizeUnicode: true,
+//	})
+//	for _, removed := range report.Removed {
+//		log.Printf("dropped %s: %s", removed.Path, removed.Reason)
+//	}
+func Sanitize(root IValue, policy SanitizePolicy) (IValue, *SanitizeReport, error) {
+	if root == nil {
+		return nil, nil, NewNullPointerError("value cannot be nil")
+	}
+
+	s := &sanitizer{
+		policy:   policy,
+		deny:     make(map[string]bool, len(policy.Deny)),
+		maxDepth: policy.MaxDepth,
+		factory:  defaultFactory(),
+		report:   &SanitizeReport{},
+	}
+	for _, key := range policy.Deny {
+		s.deny[key] = true
+	}
+	if s.maxDepth <= 0 {
+		s.maxDepth = DefaultMaxDepth
+	}
+
+	result, ok := s.sanitize(root, "$", policy.Allow, policy.Allow == nil, 0)
+	if !ok {
+		result = s.factory.CreateNull()
+	}
+	return result, s.report, nil
+}
+
+// sanitizer 按策略递归构建清洗后的副本并填写报告
+// sanitizer builds the sanitized copy recursively according to the policy and fills in the report
+type sanitizer struct {
+	policy   SanitizePolicy
+	deny     map[string]bool
+	maxDepth int
+	factory  IValueFactory
+	report   *SanitizeReport
+}
+
+// sanitize 返回value清洗后的副本，value应被移除时ok为false；allowAll表示allow之下的所有键都被允许
+// sanitize returns the sanitized copy of value, ok is false when value must be removed; allowAll means every
+// key under allow is allowed
+func (s *sanitizer) sanitize(value IValue, path string, allow *Projection, allowAll bool, depth int) (IValue, bool) {
+	switch v := value.(type) {
+	case IObject:
+		if depth >= s.maxDepth {
+			return nil, s.remove(path, SanitizeTooDeep)
+		}
+		obj := s.factory.CreateObject()
+		for _, key := range v.Keys() {
+			childPath := appendPathKey(path, key)
+			if s.policy.MaxKeyLength > 0 && len(key) > s.policy.MaxKeyLength {
+				s.remove(childPath, SanitizeTooLong)
+				continue
+			}
+			normalized := s.normalize(key)
+			if normalized != key {
+				s.report.Normalized = append(s.report.Normalized, childPath)
+			}
+			if s.deny[normalized] {
+				s.remove(childPath, SanitizeDenied)
+				continue
+			}
+
+			childAllow, childAll := allow, allowAll
+			if !allowAll {
+				var listed bool
+				childAllow, listed = allow.fields[normalized]
+				if !listed {
+					s.remove(childPath, SanitizeNotAllowed)
+					continue
+				}
+				childAll = childAllow == nil
+			}
+
+			child, ok := s.sanitize(v.Get(key), childPath, childAllow, childAll, depth+1)
+			if !ok {
+				continue
+			}
+			if obj.Has(normalized) {
+				s.remove(childPath, SanitizeDuplicateKey)
+				continue
+			}
+			obj.Set(normalized, child)
+		}
+		return obj, true
+
+	case IArray:
+		if depth >= s.maxDepth {
+			return nil, s.remove(path, SanitizeTooDeep)
+		}
+		if s.policy.MaxArrayLength > 0 && v.Length() > s.policy.MaxArrayLength {
+			return nil, s.remove(path, SanitizeTooLong)
+		}
+		arr := s.factory.CreateArray()
+		for i := 0; i < v.Length(); i++ {
+			// 数组对允许列表透明 Arrays are transparent to the allowlist
+			if child, ok := s.sanitize(v.Get(i), path+"["+strconv.Itoa(i)+"]", allow, allowAll, depth+1); ok {
+				arr.Append(child)
+			}
+		}
+		return arr, true
+
+	case nil:
+		return s.factory.CreateNull(), true
+
+	default:
+		if !allowAll && allow != nil && len(allow.fields) > 0 {
+			// 允许列表要求对象 The allowlist expects an object here
+			return nil, s.remove(path, SanitizeNotAllowed)
+		}
+		if v.Type() != StringValueType {
+			return v, true
+		}
+		str := v.AsString()
+		normalized := s.normalize(str)
+		if s.policy.MaxStringLength > 0 && len(normalized) > s.policy.MaxStringLength {
+			return nil, s.remove(path, SanitizeTooLong)
+		}
+		if normalized == str {
+			return v, true
+		}
+		s.report.Normalized = append(s.report.Normalized, path)
+		return s.factory.CreateString(normalized), true
+	}
+}
+
+// remove 记录一次移除，始终返回false
+// remove records a removal and always returns false
+func (s *sanitizer) remove(path string, reason SanitizeReason) bool {
+	s.report.Removed = append(s.report.Removed, SanitizeRemoval{Path: path, Reason: reason})
+	return false
+}
+
+// normalize 按策略规范化键或字符串
+// normalize normalizes a key or string according to the policy
+func (s *sanitizer) normalize(str string) string {
+	if s.policy.NormalizeUnicode {
+		str = normalizeUnicode(str)
+	}
+	if s.policy.Normalizer != nil {
+		str = s.policy.Normalizer(str)
+	}
+	return str
+}
+
+// normalizeUnicode 替换非法UTF-8并移除控制字符和不可见格式字符，无需修改时返回原字符串
+// normalizeUnicode replaces invalid UTF-8 and removes control and invisible format characters, returning the
+// original string when nothing changes
+func normalizeUnicode(str string) string {
+	clean := true
+	for _, r := range str {
+		if r == utf8.RuneError || strippedRune(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return str
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(str))
+	for _, r := range str {
+		if !strippedRune(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// strippedRune 报告规范化时是否移除该字符
+// strippedRune reports whether normalization removes the rune
+func strippedRune(r rune) bool {
+	if r == '\t' || r == '\n' || r == '\r' {
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
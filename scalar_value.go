@@ -10,6 +10,7 @@ import (
 // scalarValue 标量值实现（字符串、数字、布尔值、null）
 // scalarValue implements scalar values (string, number, boolean, null)
 type scalarValue struct {
+	valueMeta
 	valueType ValueType
 	rawData   interface{}
 }
@@ -63,10 +64,12 @@ func (sv *scalarValue) IsNull() bool {
 // Clone 创建值的深拷贝
 // Clone creates a deep copy of the value
 func (sv *scalarValue) Clone() IValue {
-	return &scalarValue{
+	clone := &scalarValue{
 		valueType: sv.valueType,
 		rawData:   sv.rawData,
 	}
+	sv.copyMetaTo(&clone.valueMeta)
+	return clone
 }
 
 // Equals 比较两个值是否相等
@@ -101,30 +104,11 @@ func (sv *scalarValue) Int() (int, error) {
 
 	switch sv.valueType {
 	case NumberValueType:
-		switch v := sv.rawData.(type) {
-		case int64:
-			// 检查int64到int的溢出
-			const maxInt = int64(^uint(0) >> 1)
-			const minInt = -maxInt - 1
-			if v > maxInt || v < minInt {
-				return 0, NewInvalidOperationError("int conversion", fmt.Sprintf("value %d overflows int", v))
-			}
-			return int(v), nil
-		case float64:
-			// 检查浮点数精度丢失和溢出
-			if v != float64(int64(v)) {
-				return 0, NewInvalidOperationError("int conversion", fmt.Sprintf("float64 %g has fractional part", v))
-			}
-			intVal := int64(v)
-			const maxInt = int64(^uint(0) >> 1)
-			const minInt = -maxInt - 1
-			if intVal > maxInt || intVal < minInt {
-				return 0, NewInvalidOperationError("int conversion", fmt.Sprintf("value %g overflows int", v))
-			}
-			return int(intVal), nil
-		default:
-			return 0, NewInvalidOperationError("int conversion", fmt.Sprintf("unexpected number type: %T", v))
+		i, err := convertToInt(sv.rawData, 0, &defaultNumberConversion)
+		if err != nil {
+			return 0, err
 		}
+		return int(i), nil
 	case StringValueType:
 		if str, ok := sv.rawData.(string); ok {
 			if i, err := strconv.Atoi(str); err == nil {
@@ -155,22 +139,11 @@ func (sv *scalarValue) Int64() (int64, error) {
 
 	switch sv.valueType {
 	case NumberValueType:
-		switch v := sv.rawData.(type) {
-		case int64:
-			return v, nil
-		case float64:
-			// 检查浮点数精度丢失
-			if v != float64(int64(v)) {
-				return 0, NewInvalidOperationError("int64 conversion", fmt.Sprintf("float64 %g has fractional part", v))
-			}
-			// 检查溢出
-			if v > 9223372036854775807 || v < -9223372036854775808 {
-				return 0, NewInvalidOperationError("int64 conversion", fmt.Sprintf("value %g overflows int64", v))
-			}
-			return int64(v), nil
-		default:
-			return 0, NewInvalidOperationError("int64 conversion", fmt.Sprintf("unexpected number type: %T", v))
+		i, err := convertToInt(sv.rawData, 64, &defaultNumberConversion)
+		if err != nil {
+			return 0, err
 		}
+		return i, nil
 	case StringValueType:
 		if str, ok := sv.rawData.(string); ok {
 			if i, err := strconv.ParseInt(str, 10, 64); err == nil {
@@ -421,10 +394,60 @@ func (sv *scalarValue) AsArray() IArray {
 	return nil
 }
 
+// AsInt64OK 返回整数值，仅当值是可无损表示为int64的数字时ok为true，不分配内存
+// AsInt64OK returns the integer value, ok is true only for numbers exactly representable as int64, without
+// allocating
+func (sv *scalarValue) AsInt64OK() (int64, bool) {
+	switch v := sv.rawData.(type) {
+	case int64:
+		return v, true
+	case float64:
+		// 2^63本身超出int64范围，因此上界用>=
+		// 2^63 itself overflows int64, hence >= for the upper bound
+		if v >= 9223372036854775808.0 || v < -9223372036854775808.0 || v != float64(int64(v)) {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// AsFloat64OK 返回浮点数值，仅当值是数字时ok为true，不分配内存
+// AsFloat64OK returns the float value, ok is true only for numbers, without allocating
+func (sv *scalarValue) AsFloat64OK() (float64, bool) {
+	switch v := sv.rawData.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// AsStringOK 返回字符串值，仅当值是字符串时ok为true，不分配内存
+// AsStringOK returns the string value, ok is true only for strings, without allocating
+func (sv *scalarValue) AsStringOK() (string, bool) {
+	s, ok := sv.rawData.(string)
+	return s, ok
+}
+
+// AsBoolOK 返回布尔值，仅当值是布尔值时ok为true，不分配内存
+// AsBoolOK returns the boolean value, ok is true only for booleans, without allocating
+func (sv *scalarValue) AsBoolOK() (bool, bool) {
+	b, ok := sv.rawData.(bool)
+	return b, ok
+}
+
 // numberToString 将数字转换为字符串
 // numberToString converts a number to string
 func (sv *scalarValue) numberToString() string {
-	switch v := sv.rawData.(type) {
+	return formatNumber(sv.rawData)
+}
+
+// formatNumber 将原始数字转换为字符串
+// formatNumber converts a raw number to string
+func formatNumber(raw any) string {
+	switch v := raw.(type) {
 	case int64:
 		return strconv.FormatInt(v, 10)
 	case float64:
@@ -0,0 +1,259 @@
+package xyJson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// JSONPathTagName ScanPaths使用的结构体标签名
+// JSONPathTagName is the struct tag name used by ScanPaths
+const JSONPathTagName = "jsonpath"
+
+// scanTarget 单个带路径标签的字段
+// scanTarget is a single field carrying a path tag
+type scanTarget struct {
+	index    []int
+	name     string
+	path     *CompiledPath
+	required bool
+	multi    bool
+}
+
+// scanPlan 结构体的扫描计划
+// scanPlan is the scan plan for a struct type
+type scanPlan struct {
+	fields []*scanTarget
+	err    error
+}
+
+var (
+	// scanPlanCache 扫描计划缓存
+	// scanPlanCache caches scan plans by struct type
+	scanPlanCache = make(map[reflect.Type]*scanPlan)
+	scanPlanMutex sync.RWMutex
+
+	// mappingSerializer 用于将IValue写入任意Go值
+	// mappingSerializer is used to write IValues into arbitrary Go values
+	mappingSerializer = &serializer{options: &SerializeOptions{MaxDepth: DefaultMaxDepth}}
+)
+
+// ScanPaths 根据jsonpath标签从文档中提取字段值到结构体
+// ScanPaths fills struct fields from a document according to their jsonpath tags
+//
+// 每个字段的标签是一个以$开头的JSONPath，可选地附加",required"；路径不存在的字段保持原值，
+// 除非标记为required。切片字段配合通配符、过滤器或递归路径时接收所有匹配结果。
+// 没有标签的嵌套结构体字段会被递归扫描，其中的路径同样相对于根节点
+// Each tag is a JSONPath starting with $, optionally followed by ",required"; fields whose path is missing
+// keep their value unless marked required. Slice fields combined with wildcard, filter or recursive paths
+// receive all matches. Untagged nested struct fields are scanned recursively, with paths still relative to root
+//
+// 示例 Example:
+//
+//	type UserDTO struct {
+//		Name   string   `jsonpath:"$.user.profile.name,required"`
+//		City   string   `jsonpath:"$.user.addresses[0].city"`
+//		Emails []string `jsonpath:"$.user.contacts[*].email"`
+//	}
+//
+//	var dto UserDTO
+//	err := xyJson.ScanPaths(root, &dto)
+func ScanPaths(root IValue, target interface{}) error {
+	if root == nil {
+		return NewNullPointerError("root cannot be nil")
+	}
+	if target == nil {
+		return NewNullPointerError("target cannot be nil")
+	}
+
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr {
+		return NewJSONError(ErrInvalidOperation, "target must be a pointer", nil)
+	}
+	if rv.IsNil() {
+		return NewNullPointerError("target pointer cannot be nil")
+	}
+	elem := rv.Elem()
+	if elem.Kind() != reflect.Struct {
+		return NewJSONError(ErrInvalidOperation, "target must be a pointer to struct", nil)
+	}
+
+	plan := getScanPlan(elem.Type())
+	if plan.err != nil {
+		return plan.err
+	}
+
+	for _, field := range plan.fields {
+		if err := scanField(root, elem, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustScanPaths 根据jsonpath标签提取字段值，失败时panic
+// MustScanPaths fills struct fields according to their jsonpath tags, panics on failure
+func MustScanPaths(root IValue, target interface{}) {
+	if err := ScanPaths(root, target); err != nil {
+		panic(err)
+	}
+}
+
+// scanField 查询单个字段的路径并写入字段
+// scanField queries the path of a single field and writes it into the field
+func scanField(root IValue, elem reflect.Value, field *scanTarget) error {
+	var value IValue
+	if field.multi {
+		matches, err := field.path.QueryAll(root)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return scanMissing(field)
+		}
+		arr := defaultFactory().CreateArray()
+		for _, match := range matches {
+			if err := arr.Append(match); err != nil {
+				return err
+			}
+		}
+		value = arr
+	} else {
+		result, err := field.path.Query(root)
+		if err != nil {
+			return scanMissing(field)
+		}
+		value = result
+	}
+
+	fieldValue := fieldByIndexAlloc(elem, field.index)
+	visited := make(map[IValue]bool)
+	if err := mappingSerializer.setValueByType(fieldValue, value, fieldValue.Type(), visited, 0); err != nil {
+		var je *JSONError
+		if errors.As(err, &je) {
+			return je.WithPath(field.path.Path()).WithContext("field " + field.name)
+		}
+		return err
+	}
+	return nil
+}
+
+// scanMissing 处理路径不存在的字段
+// scanMissing handles a field whose path does not exist
+func scanMissing(field *scanTarget) error {
+	if !field.required {
+		return nil
+	}
+	return NewPathNotFoundError(field.path.Path()).WithContext("required field " + field.name)
+}
+
+// fieldByIndexAlloc 按索引链获取字段，沿途分配nil的结构体指针
+// fieldByIndexAlloc gets a field by index chain, allocating nil struct pointers on the way
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// getScanPlan 获取或创建结构体的扫描计划
+// getScanPlan gets or creates the scan plan for a struct type
+func getScanPlan(t reflect.Type) *scanPlan {
+	scanPlanMutex.RLock()
+	if plan, exists := scanPlanCache[t]; exists {
+		scanPlanMutex.RUnlock()
+		return plan
+	}
+	scanPlanMutex.RUnlock()
+
+	plan := &scanPlan{}
+	plan.err = buildScanPlan(t, nil, plan, map[reflect.Type]bool{t: true})
+
+	scanPlanMutex.Lock()
+	defer scanPlanMutex.Unlock()
+	if len(scanPlanCache) < StructCacheSize {
+		scanPlanCache[t] = plan
+	}
+	return plan
+}
+
+// buildScanPlan 收集结构体中带jsonpath标签的字段
+// buildScanPlan collects the fields carrying jsonpath tags in a struct
+//
+// seen记录当前递归链上的类型，自引用的结构体不会被重复展开
+// seen records the types on the current recursion chain so self-referencing structs are not expanded again
+func buildScanPlan(t reflect.Type, prefix []int, plan *scanPlan, seen map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		index := make([]int, len(prefix)+1)
+		copy(index, prefix)
+		index[len(prefix)] = i
+
+		tag, ok := field.Tag.Lookup(JSONPathTagName)
+		if !ok || tag == "-" {
+			// 递归扫描未标记的嵌套结构体
+			// Recurse into untagged nested structs
+			nested := field.Type
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+			if !ok && nested.Kind() == reflect.Struct && nested != timeType && !seen[nested] {
+				seen[nested] = true
+				err := buildScanPlan(nested, index, plan, seen)
+				delete(seen, nested)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		path := strings.TrimSpace(parts[0])
+		compiled, err := CompilePath(path)
+		if err != nil {
+			return NewInvalidPathError(path, fmt.Errorf("field %s: %w", field.Name, err))
+		}
+
+		entry := &scanTarget{
+			index: index,
+			name:  field.Name,
+			path:  compiled,
+		}
+		for _, opt := range parts[1:] {
+			if strings.TrimSpace(opt) == "required" {
+				entry.required = true
+			}
+		}
+
+		kind := field.Type.Kind()
+		if (kind == reflect.Slice || kind == reflect.Array) && field.Type.Elem().Kind() != reflect.Uint8 {
+			entry.multi = compiled.matchesMany()
+		}
+		plan.fields = append(plan.fields, entry)
+	}
+	return nil
+}
+
+// matchesMany 检查路径是否可能匹配多个值
+// matchesMany checks if the path may match multiple values
+func (cp *CompiledPath) matchesMany() bool {
+	for _, segment := range cp.segments {
+		if segment.Wildcard || segment.Recursive || segment.Type == FilterSegmentType || segment.Type == CustomSegmentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+package xyJson
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// JSON Schema 相关常量
+// JSON Schema related constants
+const (
+	// SchemaDraft 生成的JSON Schema所使用的草案版本
+	// SchemaDraft is the JSON Schema draft used by generated documents
+	SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// schemaDefinitionsPrefix 定义引用前缀
+	// schemaDefinitionsPrefix is the reference prefix for definitions
+	schemaDefinitionsPrefix = "#/definitions/"
+)
+
+var (
+	// byteSliceType 预缓存的[]byte类型
+	// byteSliceType is the cached []byte type
+	byteSliceType = reflect.TypeOf([]byte(nil))
+
+	// ivalueType 预缓存的IValue接口类型
+	// ivalueType is the cached IValue interface type
+	ivalueType = reflect.TypeOf((*IValue)(nil)).Elem()
+)
+
+// schemaGenerator 结构体到JSON Schema的生成器
+// schemaGenerator generates JSON Schema documents from Go types
+type schemaGenerator struct {
+	factory     IValueFactory
+	definitions IObject
+	// root 根结构体类型，对它的引用指向文档根
+	// root is the root struct type, references to it point at the document root
+	root reflect.Type
+	// names 已放入definitions的结构体及其定义名，不同包中的同名类型以数字后缀区分
+	// names maps the structs placed in definitions to their definition names, same-named types from different
+	// packages are told apart by a numeric suffix
+	names map[reflect.Type]string
+}
+
+// GenerateSchema 根据Go结构体生成JSON Schema文档
+// GenerateSchema generates a JSON Schema document from a Go struct
+//
+// 参数 Parameters:
+//   - v: 结构体值、结构体指针或reflect.Type / Struct value, struct pointer or reflect.Type
+//
+// 返回值 Returns:
+//   - IValue: JSON Schema文档 / JSON Schema document
+//   - error: 输入不是结构体时返回错误 / Error when the input is not a struct
+//
+// 注意 Notes:
+//   - 字段名和忽略规则与SerializeToStruct一致（复用json标签）/ Field names and skip rules match SerializeToStruct (json tags)
+//   - 非指针且未标记omitempty的字段被视为必填 / Non-pointer fields without omitempty are required
+//   - 嵌套的具名结构体放入definitions并通过$ref引用 / Named nested structs go to definitions and are referenced via $ref
+//
+// 示例 Example:
+//
+//	type User struct {
+//		Name  string   `json:"name"`
+//		Email *string  `json:"email"`
+//		Tags  []string `json:"tags,omitempty"`
+//	}
+//	schema, err := xyJson.GenerateSchema(User{})
+//	if err == nil {
+//		fmt.Println(xyJson.MustPretty(schema))
+//	}
+func GenerateSchema(v interface{}) (IValue, error) {
+	if v == nil {
+		return nil, NewNullPointerError("schema source cannot be nil")
+	}
+
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, NewInvalidOperationError("generate schema", "source must be a struct, got "+t.Kind().String())
+	}
+
+	gen := &schemaGenerator{
+		factory:     defaultFactory(),
+		definitions: defaultFactory().CreateObject(),
+		root:        t,
+		names:       make(map[reflect.Type]string),
+	}
+
+	root, err := gen.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := root.Set("$schema", SchemaDraft); err != nil {
+		return nil, err
+	}
+	if t.Name() != "" {
+		if err := root.Set("title", t.Name()); err != nil {
+			return nil, err
+		}
+	}
+	if gen.definitions.Size() > 0 {
+		if err := root.Set("definitions", gen.definitions); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// MustGenerateSchema 生成JSON Schema，失败时panic
+// MustGenerateSchema generates a JSON Schema, panics on failure
+func MustGenerateSchema(v interface{}) IValue {
+	schema, err := GenerateSchema(v)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// structSchema 生成结构体的对象Schema
+// structSchema generates the object schema of a struct
+func (g *schemaGenerator) structSchema(t reflect.Type) (IObject, error) {
+	info := getStructInfo(t)
+
+	// 按字段声明顺序输出，保证required列表稳定
+	// Emit fields in declaration order so the required list is stable
+	fields := make([]*fieldInfo, 0, len(info.Fields))
+	for _, field := range info.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Index < fields[j].Index
+	})
+
+	schema := g.factory.CreateObject()
+	properties := g.factory.CreateObject()
+	required := g.factory.CreateArray()
+
+	for _, field := range fields {
+		fieldSchema, err := g.typeSchema(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		if field.IsPtr {
+			fieldSchema = g.nullable(fieldSchema)
+		}
+		if err := properties.Set(field.Name, fieldSchema); err != nil {
+			return nil, err
+		}
+		if !field.IsPtr && !field.Tag.OmitEmpty {
+			if err := required.Append(field.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := schema.Set("type", "object"); err != nil {
+		return nil, err
+	}
+	if err := schema.Set("properties", properties); err != nil {
+		return nil, err
+	}
+	if required.Length() > 0 {
+		if err := schema.Set("required", required); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// typeSchema 生成任意Go类型的Schema
+// typeSchema generates the schema of an arbitrary Go type
+func (g *schemaGenerator) typeSchema(t reflect.Type) (IValue, error) {
+	if t == timeType {
+		return g.simpleSchema("string", "date-time")
+	}
+	if t == byteSliceType {
+		return g.simpleSchema("string", "")
+	}
+	if t == ivalueType {
+		return g.factory.CreateObject(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		inner, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return g.nullable(inner), nil
+	case reflect.String:
+		return g.simpleSchema("string", "")
+	case reflect.Bool:
+		return g.simpleSchema("boolean", "")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return g.simpleSchema("integer", "")
+	case reflect.Float32, reflect.Float64:
+		return g.simpleSchema("number", "")
+	case reflect.Slice, reflect.Array:
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := g.factory.CreateObject()
+		if err := schema.Set("type", "array"); err != nil {
+			return nil, err
+		}
+		if err := schema.Set("items", items); err != nil {
+			return nil, err
+		}
+		if t.Kind() == reflect.Array {
+			if err := schema.Set("maxItems", t.Len()); err != nil {
+				return nil, err
+			}
+		}
+		return schema, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, NewInvalidOperationError("generate schema", "map key must be string, got "+t.Key().Kind().String())
+		}
+		values, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := g.factory.CreateObject()
+		if err := schema.Set("type", "object"); err != nil {
+			return nil, err
+		}
+		if err := schema.Set("additionalProperties", values); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	case reflect.Struct:
+		return g.structRef(t)
+	case reflect.Interface:
+		// 任意值，空Schema
+		// Any value, empty schema
+		return g.factory.CreateObject(), nil
+	default:
+		return nil, NewInvalidOperationError("generate schema", "unsupported type "+t.String())
+	}
+}
+
+// structRef 生成结构体引用，具名结构体放入definitions，与已有定义同名时加上数字后缀
+// structRef generates a struct reference, named structs are placed in definitions with a numeric suffix when
+// the name is already taken
+func (g *schemaGenerator) structRef(t reflect.Type) (IValue, error) {
+	if t == g.root {
+		return g.refSchema("#")
+	}
+
+	if name, ok := g.names[t]; ok {
+		return g.refSchema(schemaDefinitionsPrefix + name)
+	}
+	if t.Name() == "" {
+		// 匿名结构体直接内联
+		// Anonymous structs are inlined
+		return g.structSchema(t)
+	}
+
+	name := t.Name()
+	for i := 2; g.definitions.Has(name); i++ {
+		name = t.Name() + strconv.Itoa(i)
+	}
+	g.names[t] = name
+
+	// 先占位，防止自引用类型无限递归
+	// Reserve the slot first so self-referencing types do not recurse forever
+	if err := g.definitions.Set(name, g.factory.CreateObject()); err != nil {
+		return nil, err
+	}
+	schema, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.definitions.Set(name, schema); err != nil {
+		return nil, err
+	}
+
+	return g.refSchema(schemaDefinitionsPrefix + name)
+}
+
+// refSchema 创建$ref引用
+// refSchema creates a $ref reference
+func (g *schemaGenerator) refSchema(ref string) (IValue, error) {
+	schema := g.factory.CreateObject()
+	if err := schema.Set("$ref", ref); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// simpleSchema 创建只包含type和可选format的Schema
+// simpleSchema creates a schema with a type and an optional format
+func (g *schemaGenerator) simpleSchema(typeName, format string) (IValue, error) {
+	schema := g.factory.CreateObject()
+	if err := schema.Set("type", typeName); err != nil {
+		return nil, err
+	}
+	if format != "" {
+		if err := schema.Set("format", format); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// nullable 将Schema包装为允许null
+// nullable wraps a schema so that null is also accepted
+func (g *schemaGenerator) nullable(schema IValue) IValue {
+	variants := g.factory.CreateArray()
+	variants.Append(schema)
+	nullSchema := g.factory.CreateObject()
+	nullSchema.Set("type", "null")
+	variants.Append(nullSchema)
+
+	wrapper := g.factory.CreateObject()
+	wrapper.Set("anyOf", variants)
+	return wrapper
+}
@@ -0,0 +1,858 @@
+package xyJson
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// schemaType JSON Schema类型位集
+// schemaType is a bit set of JSON Schema types
+type schemaType uint8
+
+const (
+	schemaNull schemaType = 1 << iota
+	schemaBoolean
+	schemaObject
+	schemaArray
+	schemaNumber
+	schemaString
+	schemaInteger
+)
+
+// schemaTypeNames JSON Schema类型名到位集的映射
+// schemaTypeNames maps JSON Schema type names to bits
+var schemaTypeNames = map[string]schemaType{
+	"null":    schemaNull,
+	"boolean": schemaBoolean,
+	"object":  schemaObject,
+	"array":   schemaArray,
+	"number":  schemaNumber,
+	"string":  schemaString,
+	"integer": schemaInteger,
+}
+
+// String 返回类型位集的可读表示
+// String returns a readable representation of the type set
+func (st schemaType) String() string {
+	var names []string
+	for name, bit := range schemaTypeNames {
+		if st&bit != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "|")
+}
+
+// Schema 编译后的JSON Schema，支持draft-07的常用校验关键字
+// Schema is a compiled JSON Schema supporting the common draft-07 validation keywords
+//
+// 支持的关键字：type、enum、const、properties、required、additionalProperties、minProperties、
+// maxProperties、items（单个或元组）、minItems、maxItems、uniqueItems、minLength、maxLength、pattern、
+// minimum、maximum、exclusiveMinimum、exclusiveMaximum、multipleOf、allOf、anyOf、oneOf、not以及
+// 指向文档内部的$ref。format只在Coerce中使用，其它关键字被忽略
+// Supported keywords: type, enum, const, properties, required, additionalProperties, minProperties,
+// maxProperties, items (single or tuple), minItems, maxItems, uniqueItems, minLength, maxLength, pattern,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and $ref into
+// the same document. format is only used by Coerce, other keywords are ignored
+//
+// Schema创建后不可修改，可以被多个goroutine并发使用
+// A Schema is immutable once compiled and safe for concurrent use
+type Schema struct {
+	// reject 布尔Schema false，拒绝任何值
+	// reject is the boolean schema false, rejecting every value
+	reject bool
+	// ref $ref指向的Schema，非nil时忽略其它关键字
+	// ref is the schema $ref points at, other keywords are ignored when non-nil
+	ref *Schema
+
+	types      schemaType
+	enum       []IValue
+	constValue IValue
+
+	properties    map[string]*Schema
+	required      []string
+	additional    *Schema
+	minProperties int
+	maxProperties int
+
+	items       *Schema
+	tupleItems  []*Schema
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       float64
+	// multipleOfRat multipleOf的十进制精确值
+	// multipleOfRat is the exact decimal value of multipleOf
+	multipleOfRat *big.Rat
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// schemaCompiler 将Schema文档编译为Schema
+// schemaCompiler compiles schema documents into Schemas
+type schemaCompiler struct {
+	root IValue
+	refs map[string]*Schema
+}
+
+// CompileSchema 编译JSON Schema文档
+// CompileSchema compiles a JSON Schema document
+//
+// 示例 Example:
+//
+//	schema, err := xyJson.CompileSchema(xyJson.MustGenerateSchema(User{}))
+//	if err != nil {
+//		return err
+//	}
+//	if err := schema.Validate(doc); err != nil {
+//		fmt.Println(err) // [SCHEMA_VIOLATION] missing required property 'name' at path '$'
+//	}
+func CompileSchema(doc IValue) (*Schema, error) {
+	if doc == nil {
+		return nil, NewNullPointerError("schema cannot be nil")
+	}
+
+	c := &schemaCompiler{root: doc, refs: make(map[string]*Schema)}
+	root := &Schema{}
+	c.refs["#"] = root
+	if err := c.compileInto(doc, root, "#"); err != nil {
+		return nil, err
+	}
+
+	// 只由$ref组成的环永远无法得到具体的Schema
+	// A cycle made only of $refs can never reach a concrete schema
+	for ref, schema := range c.refs {
+		seen := make(map[*Schema]bool)
+		for s := schema; s.ref != nil; s = s.ref {
+			if seen[s] {
+				return nil, NewInvalidOperationError("compile schema", "circular $ref "+ref)
+			}
+			seen[s] = true
+		}
+	}
+	return root, nil
+}
+
+// MustCompileSchema 编译JSON Schema文档，失败时panic
+// MustCompileSchema compiles a JSON Schema document, panics on failure
+func MustCompileSchema(doc IValue) *Schema {
+	schema, err := CompileSchema(doc)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// compile 编译子Schema
+// compile compiles a subschema
+func (c *schemaCompiler) compile(doc IValue, location string) (*Schema, error) {
+	schema := &Schema{}
+	if err := c.compileInto(doc, schema, location); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// compileInto 将Schema文档编译到dst
+// compileInto compiles a schema document into dst
+func (c *schemaCompiler) compileInto(doc IValue, dst *Schema, location string) error {
+	dst.minProperties, dst.maxProperties = -1, -1
+	dst.minItems, dst.maxItems = -1, -1
+	dst.minLength, dst.maxLength = -1, -1
+
+	if doc.Type() == BoolValueType {
+		dst.reject = !doc.AsBool()
+		return nil
+	}
+	obj := doc.AsObject()
+	if obj == nil {
+		return c.invalid(location, "schema must be an object or boolean")
+	}
+
+	if ref := obj.Get("$ref"); ref != nil {
+		target, err := c.resolve(ref.AsString())
+		if err != nil {
+			return err
+		}
+		dst.ref = target
+		return nil
+	}
+
+	if err := c.compileType(obj, dst, location); err != nil {
+		return err
+	}
+	if enum := obj.Get("enum"); enum != nil {
+		arr := enum.AsArray()
+		if arr == nil {
+			return c.invalid(location, "enum must be an array")
+		}
+		arr.Range(func(_ int, value IValue) bool {
+			dst.enum = append(dst.enum, value)
+			return true
+		})
+	}
+	dst.constValue = obj.Get("const")
+
+	var err error
+	if err = c.compileObjectKeywords(obj, dst, location); err != nil {
+		return err
+	}
+	if err = c.compileArrayKeywords(obj, dst, location); err != nil {
+		return err
+	}
+	if err = c.compileScalarKeywords(obj, dst, location); err != nil {
+		return err
+	}
+
+	if dst.allOf, err = c.compileList(obj, "allOf", location); err != nil {
+		return err
+	}
+	if dst.anyOf, err = c.compileList(obj, "anyOf", location); err != nil {
+		return err
+	}
+	if dst.oneOf, err = c.compileList(obj, "oneOf", location); err != nil {
+		return err
+	}
+	if not := obj.Get("not"); not != nil {
+		if dst.not, err = c.compile(not, location+"/not"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileType 编译type关键字
+// compileType compiles the type keyword
+func (c *schemaCompiler) compileType(obj IObject, dst *Schema, location string) error {
+	value := obj.Get("type")
+	if value == nil {
+		return nil
+	}
+
+	var names []string
+	if arr := value.AsArray(); arr != nil {
+		arr.Range(func(_ int, item IValue) bool {
+			names = append(names, item.AsString())
+			return true
+		})
+	} else {
+		names = append(names, value.AsString())
+	}
+
+	for _, name := range names {
+		bit, ok := schemaTypeNames[name]
+		if !ok {
+			return c.invalid(location, "unknown type '"+name+"'")
+		}
+		dst.types |= bit
+	}
+	return nil
+}
+
+// compileObjectKeywords 编译对象相关关键字
+// compileObjectKeywords compiles the object keywords
+func (c *schemaCompiler) compileObjectKeywords(obj IObject, dst *Schema, location string) error {
+	if properties := obj.Get("properties"); properties != nil {
+		props := properties.AsObject()
+		if props == nil {
+			return c.invalid(location, "properties must be an object")
+		}
+		dst.properties = make(map[string]*Schema, props.Size())
+		for _, key := range props.Keys() {
+			schema, err := c.compile(props.Get(key), location+"/properties/"+key)
+			if err != nil {
+				return err
+			}
+			dst.properties[key] = schema
+		}
+	}
+
+	if required := obj.Get("required"); required != nil {
+		arr := required.AsArray()
+		if arr == nil {
+			return c.invalid(location, "required must be an array")
+		}
+		arr.Range(func(_ int, item IValue) bool {
+			dst.required = append(dst.required, item.AsString())
+			return true
+		})
+	}
+
+	if additional := obj.Get("additionalProperties"); additional != nil {
+		schema, err := c.compile(additional, location+"/additionalProperties")
+		if err != nil {
+			return err
+		}
+		dst.additional = schema
+	}
+
+	var err error
+	if dst.minProperties, err = c.intKeyword(obj, "minProperties", location); err != nil {
+		return err
+	}
+	dst.maxProperties, err = c.intKeyword(obj, "maxProperties", location)
+	return err
+}
+
+// compileArrayKeywords 编译数组相关关键字
+// compileArrayKeywords compiles the array keywords
+func (c *schemaCompiler) compileArrayKeywords(obj IObject, dst *Schema, location string) error {
+	if items := obj.Get("items"); items != nil {
+		if arr := items.AsArray(); arr != nil {
+			for i := 0; i < arr.Length(); i++ {
+				schema, err := c.compile(arr.Get(i), location+"/items/"+strconv.Itoa(i))
+				if err != nil {
+					return err
+				}
+				dst.tupleItems = append(dst.tupleItems, schema)
+			}
+		} else {
+			schema, err := c.compile(items, location+"/items")
+			if err != nil {
+				return err
+			}
+			dst.items = schema
+		}
+	}
+
+	if unique := obj.Get("uniqueItems"); unique != nil {
+		dst.uniqueItems = unique.AsBool()
+	}
+
+	var err error
+	if dst.minItems, err = c.intKeyword(obj, "minItems", location); err != nil {
+		return err
+	}
+	dst.maxItems, err = c.intKeyword(obj, "maxItems", location)
+	return err
+}
+
+// compileScalarKeywords 编译字符串和数字相关关键字
+// compileScalarKeywords compiles the string and number keywords
+func (c *schemaCompiler) compileScalarKeywords(obj IObject, dst *Schema, location string) error {
+	var err error
+	if dst.minLength, err = c.intKeyword(obj, "minLength", location); err != nil {
+		return err
+	}
+	if dst.maxLength, err = c.intKeyword(obj, "maxLength", location); err != nil {
+		return err
+	}
+	if format := obj.Get("format"); format != nil && format.Type() == StringValueType {
+		dst.format = format.AsString()
+	}
+	if pattern := obj.Get("pattern"); pattern != nil {
+		if dst.pattern, err = regexp.Compile(pattern.AsString()); err != nil {
+			return c.invalid(location, "invalid pattern: "+err.Error())
+		}
+	}
+
+	if dst.minimum, err = c.numberKeyword(obj, "minimum", location); err != nil {
+		return err
+	}
+	if dst.maximum, err = c.numberKeyword(obj, "maximum", location); err != nil {
+		return err
+	}
+
+	// draft-04使用布尔值修饰minimum/maximum
+	// draft-04 uses booleans that modify minimum/maximum
+	if exclusive := obj.Get("exclusiveMinimum"); exclusive != nil && exclusive.Type() == BoolValueType {
+		if exclusive.AsBool() {
+			dst.exclusiveMinimum, dst.minimum = dst.minimum, nil
+		}
+	} else if dst.exclusiveMinimum, err = c.numberKeyword(obj, "exclusiveMinimum", location); err != nil {
+		return err
+	}
+	if exclusive := obj.Get("exclusiveMaximum"); exclusive != nil && exclusive.Type() == BoolValueType {
+		if exclusive.AsBool() {
+			dst.exclusiveMaximum, dst.maximum = dst.maximum, nil
+		}
+	} else if dst.exclusiveMaximum, err = c.numberKeyword(obj, "exclusiveMaximum", location); err != nil {
+		return err
+	}
+
+	multipleOf, err := c.numberKeyword(obj, "multipleOf", location)
+	if err != nil {
+		return err
+	}
+	if multipleOf != nil {
+		if *multipleOf <= 0 {
+			return c.invalid(location, "multipleOf must be greater than 0")
+		}
+		dst.multipleOf = *multipleOf
+		dst.multipleOfRat = decimalRat(*multipleOf)
+	}
+	return nil
+}
+
+// compileList 编译Schema数组关键字
+// compileList compiles a keyword holding an array of schemas
+func (c *schemaCompiler) compileList(obj IObject, keyword, location string) ([]*Schema, error) {
+	value := obj.Get(keyword)
+	if value == nil {
+		return nil, nil
+	}
+	arr := value.AsArray()
+	if arr == nil || arr.Length() == 0 {
+		return nil, c.invalid(location, keyword+" must be a non-empty array")
+	}
+
+	schemas := make([]*Schema, 0, arr.Length())
+	for i := 0; i < arr.Length(); i++ {
+		schema, err := c.compile(arr.Get(i), location+"/"+keyword+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+// resolve 解析文档内部的$ref，同一引用只编译一次以支持递归Schema
+// resolve resolves a $ref into the same document, compiling each reference once to support recursive schemas
+func (c *schemaCompiler) resolve(ref string) (*Schema, error) {
+	if schema, exists := c.refs[ref]; exists {
+		return schema, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, c.invalid(ref, "only references into the same document are supported")
+	}
+
+	node := lookupPointer(c.root, ref[1:])
+	if node == nil {
+		return nil, c.invalid(ref, "unresolvable $ref")
+	}
+
+	schema := &Schema{}
+	c.refs[ref] = schema
+	if err := c.compileInto(node, schema, ref); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// intKeyword 读取非负整数关键字，不存在时返回-1
+// intKeyword reads a non-negative integer keyword, -1 when absent
+func (c *schemaCompiler) intKeyword(obj IObject, keyword, location string) (int, error) {
+	value := obj.Get(keyword)
+	if value == nil {
+		return -1, nil
+	}
+	if value.Type() != NumberValueType || value.AsInt() < 0 {
+		return -1, c.invalid(location, keyword+" must be a non-negative integer")
+	}
+	return value.AsInt(), nil
+}
+
+// numberKeyword 读取数字关键字，不存在时返回nil
+// numberKeyword reads a number keyword, nil when absent
+func (c *schemaCompiler) numberKeyword(obj IObject, keyword, location string) (*float64, error) {
+	value := obj.Get(keyword)
+	if value == nil {
+		return nil, nil
+	}
+	if value.Type() != NumberValueType {
+		return nil, c.invalid(location, keyword+" must be a number")
+	}
+	number := value.AsFloat64()
+	return &number, nil
+}
+
+// invalid 创建Schema文档无效的错误
+// invalid creates an invalid schema document error
+func (c *schemaCompiler) invalid(location, message string) error {
+	return NewInvalidOperationError("compile schema", message+" at "+location)
+}
+
+// Validate 校验值是否符合Schema，返回第一个违反项
+// Validate checks whether a value conforms to the schema, returning the first violation
+//
+// 违反项是Code为ErrSchemaViolation的*JSONError，Path为出错值的JSONPath
+// A violation is a *JSONError with Code ErrSchemaViolation whose Path is the JSONPath of the offending value
+func (s *Schema) Validate(value IValue) error {
+	if value == nil {
+		return NewNullPointerError("value cannot be nil")
+	}
+	return s.validate(value, "$", 0)
+}
+
+// resolved 沿$ref找到具体的Schema
+// resolved follows $refs to the concrete schema
+func (s *Schema) resolved() *Schema {
+	for s.ref != nil {
+		s = s.ref
+	}
+	return s
+}
+
+// validate 递归校验值
+// validate validates a value recursively
+func (s *Schema) validate(value IValue, path string, depth int) error {
+	s = s.resolved()
+	if depth > MaxNestingDepth {
+		return NewMaxDepthExceededError(MaxNestingDepth)
+	}
+	if s.reject {
+		return NewSchemaViolationError(path, "value is not allowed")
+	}
+
+	if err := s.checkType(value, path); err != nil {
+		return err
+	}
+	if err := s.checkEnum(value, path); err != nil {
+		return err
+	}
+
+	var err error
+	switch value.Type() {
+	case StringValueType:
+		err = s.checkString(value.AsString(), path)
+	case NumberValueType:
+		err = s.checkNumber(value.AsFloat64(), path)
+	case ObjectValueType:
+		err = s.checkObject(value.AsObject(), path, depth)
+	case ArrayValueType:
+		err = s.checkArray(value.AsArray(), path, depth)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.checkCombinators(value, path, depth)
+}
+
+// checkType 校验type关键字
+// checkType checks the type keyword
+func (s *Schema) checkType(value IValue, path string) error {
+	if s.types == 0 {
+		return nil
+	}
+	actual := valueSchemaType(value)
+	if s.types&actual != 0 {
+		return nil
+	}
+	if actual == schemaNumber && s.types&schemaInteger != 0 && isWholeNumber(value.AsFloat64()) {
+		return nil
+	}
+	return NewSchemaViolationError(path, fmt.Sprintf("expected type %s but got %s", s.types, actual))
+}
+
+// checkEnum 校验enum和const关键字
+// checkEnum checks the enum and const keywords
+func (s *Schema) checkEnum(value IValue, path string) error {
+	if s.constValue != nil && !schemaValuesEqual(value, s.constValue) {
+		return NewSchemaViolationError(path, "value does not match const")
+	}
+	if s.enum == nil {
+		return nil
+	}
+	for _, candidate := range s.enum {
+		if schemaValuesEqual(value, candidate) {
+			return nil
+		}
+	}
+	return NewSchemaViolationError(path, "value is not one of the enum values")
+}
+
+// checkString 校验字符串关键字
+// checkString checks the string keywords
+func (s *Schema) checkString(str, path string) error {
+	if s.minLength >= 0 || s.maxLength >= 0 {
+		length := utf8.RuneCountInString(str)
+		if s.minLength >= 0 && length < s.minLength {
+			return NewSchemaViolationError(path, fmt.Sprintf("string length %d is less than minLength %d", length, s.minLength))
+		}
+		if s.maxLength >= 0 && length > s.maxLength {
+			return NewSchemaViolationError(path, fmt.Sprintf("string length %d exceeds maxLength %d", length, s.maxLength))
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		return NewSchemaViolationError(path, "string does not match pattern "+s.pattern.String())
+	}
+	return nil
+}
+
+// checkNumber 校验数字关键字
+// checkNumber checks the number keywords
+func (s *Schema) checkNumber(n float64, path string) error {
+	switch {
+	case s.minimum != nil && n < *s.minimum:
+		return NewSchemaViolationError(path, fmt.Sprintf("%v is less than minimum %v", n, *s.minimum))
+	case s.maximum != nil && n > *s.maximum:
+		return NewSchemaViolationError(path, fmt.Sprintf("%v exceeds maximum %v", n, *s.maximum))
+	case s.exclusiveMinimum != nil && n <= *s.exclusiveMinimum:
+		return NewSchemaViolationError(path, fmt.Sprintf("%v is not greater than exclusiveMinimum %v", n, *s.exclusiveMinimum))
+	case s.exclusiveMaximum != nil && n >= *s.exclusiveMaximum:
+		return NewSchemaViolationError(path, fmt.Sprintf("%v is not less than exclusiveMaximum %v", n, *s.exclusiveMaximum))
+	case s.multipleOf > 0 && !s.isMultiple(n):
+		return NewSchemaViolationError(path, fmt.Sprintf("%v is not a multiple of %v", n, s.multipleOf))
+	}
+	return nil
+}
+
+// isMultiple 检查n是否为multipleOf的整数倍，按十进制精确计算，因此0.3是0.1的倍数
+// isMultiple checks whether n is an integer multiple of multipleOf, computed exactly in decimal so 0.3 is a
+// multiple of 0.1
+func (s *Schema) isMultiple(n float64) bool {
+	if isWholeNumber(n) && isWholeNumber(s.multipleOf) && math.Abs(n) < 1<<53 {
+		return math.Mod(n, s.multipleOf) == 0
+	}
+	r := decimalRat(n)
+	if r == nil || s.multipleOfRat == nil {
+		return isWholeNumber(n / s.multipleOf)
+	}
+	return r.Quo(r, s.multipleOfRat).IsInt()
+}
+
+// checkObject 校验对象关键字
+// checkObject checks the object keywords
+func (s *Schema) checkObject(obj IObject, path string, depth int) error {
+	if err := s.checkPropertyCount(obj.Size(), path); err != nil {
+		return err
+	}
+	for _, name := range s.required {
+		if !obj.Has(name) {
+			return s.missingProperty(name, path)
+		}
+	}
+
+	keys := obj.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := s.propertySchema(key)
+		if child == nil {
+			continue
+		}
+		childPath := appendPathKey(path, key)
+		if child.resolved().reject {
+			return s.forbiddenProperty(key, childPath)
+		}
+		if err := child.validate(obj.Get(key), childPath, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkArray 校验数组关键字
+// checkArray checks the array keywords
+func (s *Schema) checkArray(arr IArray, path string, depth int) error {
+	length := arr.Length()
+	if err := s.checkItemCount(length, path); err != nil {
+		return err
+	}
+
+	for i := 0; i < length; i++ {
+		child := s.itemSchema(i)
+		if child == nil {
+			continue
+		}
+		if err := child.validate(arr.Get(i), path+"["+strconv.Itoa(i)+"]", depth+1); err != nil {
+			return err
+		}
+	}
+
+	if s.uniqueItems {
+		for i := 0; i < length; i++ {
+			for j := i + 1; j < length; j++ {
+				if schemaValuesEqual(arr.Get(i), arr.Get(j)) {
+					return NewSchemaViolationError(path, fmt.Sprintf("items %d and %d are not unique", i, j))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkCombinators 校验allOf、anyOf、oneOf和not
+// checkCombinators checks allOf, anyOf, oneOf and not
+func (s *Schema) checkCombinators(value IValue, path string, depth int) error {
+	for _, schema := range s.allOf {
+		if err := schema.validate(value, path, depth); err != nil {
+			return err
+		}
+	}
+
+	if s.anyOf != nil {
+		matched := false
+		for _, schema := range s.anyOf {
+			if schema.validate(value, path, depth) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return NewSchemaViolationError(path, "value does not match any schema in anyOf")
+		}
+	}
+
+	if s.oneOf != nil {
+		matches := 0
+		for _, schema := range s.oneOf {
+			if schema.validate(value, path, depth) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return NewSchemaViolationError(path, fmt.Sprintf("value matches %d schemas in oneOf, expected exactly 1", matches))
+		}
+	}
+
+	if s.not != nil && s.not.validate(value, path, depth) == nil {
+		return NewSchemaViolationError(path, "value must not match the schema in not")
+	}
+	return nil
+}
+
+// checkPropertyCount 校验对象成员数量
+// checkPropertyCount checks the number of object members
+func (s *Schema) checkPropertyCount(count int, path string) error {
+	if s.minProperties >= 0 && count < s.minProperties {
+		return NewSchemaViolationError(path, fmt.Sprintf("object has %d properties, less than minProperties %d", count, s.minProperties))
+	}
+	if s.maxProperties >= 0 && count > s.maxProperties {
+		return NewSchemaViolationError(path, fmt.Sprintf("object has more than maxProperties %d properties", s.maxProperties))
+	}
+	return nil
+}
+
+// checkItemCount 校验数组元素数量
+// checkItemCount checks the number of array items
+func (s *Schema) checkItemCount(count int, path string) error {
+	if s.minItems >= 0 && count < s.minItems {
+		return NewSchemaViolationError(path, fmt.Sprintf("array has %d items, less than minItems %d", count, s.minItems))
+	}
+	if s.maxItems >= 0 && count > s.maxItems {
+		return NewSchemaViolationError(path, fmt.Sprintf("array has more than maxItems %d items", s.maxItems))
+	}
+	return nil
+}
+
+// propertySchema 返回对象成员的Schema，nil表示不受约束
+// propertySchema returns the schema of an object member, nil means unconstrained
+func (s *Schema) propertySchema(key string) *Schema {
+	if schema, exists := s.properties[key]; exists {
+		return schema
+	}
+	return s.additional
+}
+
+// itemSchema 返回数组元素的Schema，nil表示不受约束
+// itemSchema returns the schema of an array item, nil means unconstrained
+func (s *Schema) itemSchema(index int) *Schema {
+	if s.tupleItems != nil {
+		if index < len(s.tupleItems) {
+			return s.tupleItems[index]
+		}
+		return nil
+	}
+	return s.items
+}
+
+// missingProperty 创建缺少必填成员的错误
+// missingProperty creates the error for a missing required member
+func (s *Schema) missingProperty(name, path string) error {
+	return NewSchemaViolationError(path, "missing required property '"+name+"'")
+}
+
+// forbiddenProperty 创建不允许的成员的错误
+// forbiddenProperty creates the error for a member that is not allowed
+func (s *Schema) forbiddenProperty(key, path string) error {
+	return NewSchemaViolationError(path, "property '"+key+"' is not allowed")
+}
+
+// valueSchemaType 返回值对应的Schema类型
+// valueSchemaType returns the schema type of a value
+func valueSchemaType(value IValue) schemaType {
+	switch value.Type() {
+	case NullValueType:
+		return schemaNull
+	case BoolValueType:
+		return schemaBoolean
+	case ObjectValueType:
+		return schemaObject
+	case ArrayValueType:
+		return schemaArray
+	case NumberValueType:
+		return schemaNumber
+	default:
+		return schemaString
+	}
+}
+
+// isWholeNumber 检查浮点数是否为整数
+// isWholeNumber checks whether a float is a whole number
+func isWholeNumber(n float64) bool {
+	return !math.IsInf(n, 0) && n == math.Trunc(n)
+}
+
+// decimalRat 返回浮点数最短十进制表示的精确值，非有限数返回nil
+// decimalRat returns the exact value of the shortest decimal representation of a float, nil for non-finite
+// numbers
+func decimalRat(f float64) *big.Rat {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// schemaValuesEqual 按JSON Schema语义比较两个值，数字按数值比较
+// schemaValuesEqual compares two values with JSON Schema semantics, numbers compare by value
+func schemaValuesEqual(a, b IValue) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Type() {
+	case NumberValueType:
+		return a.AsFloat64() == b.AsFloat64()
+	case ObjectValueType:
+		objA, objB := a.AsObject(), b.AsObject()
+		if objA.Size() != objB.Size() {
+			return false
+		}
+		for _, key := range objA.Keys() {
+			other := objB.Get(key)
+			if other == nil || !schemaValuesEqual(objA.Get(key), other) {
+				return false
+			}
+		}
+		return true
+	case ArrayValueType:
+		arrA, arrB := a.AsArray(), b.AsArray()
+		if arrA.Length() != arrB.Length() {
+			return false
+		}
+		for i := 0; i < arrA.Length(); i++ {
+			if !schemaValuesEqual(arrA.Get(i), arrB.Get(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Equals(b)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 	"unicode/utf8"
+	"unsafe"
 )
 
 // 性能优化相关常量
@@ -21,8 +22,8 @@ const (
 	// MaxStructDepth maximum struct nesting depth
 	MaxStructDepth = 100
 
-	// StructCacheSize 结构体信息缓存大小
-	// StructCacheSize struct info cache size
+	// StructCacheSize 结构体信息缓存的默认大小，见SetStructCacheSize
+	// StructCacheSize is the default size of the struct info cache, see SetStructCacheSize
 	StructCacheSize = 1000
 	// ReflectValuePoolSize reflect.Value对象池大小
 	// ReflectValuePoolSize is the size of reflect.Value object pool
@@ -74,6 +75,14 @@ type fieldInfo struct {
 	// IsPtr 是否为指针类型
 	// IsPtr whether it's a pointer type
 	IsPtr bool
+
+	// Offset 字段在结构体中的偏移量
+	// Offset field offset within the struct
+	Offset uintptr
+
+	// Setter 标量字段的直接写入函数，其它字段为nil
+	// Setter direct setter for scalar fields, nil for other fields
+	Setter fieldSetter
 }
 
 // structInfo 结构体信息缓存
@@ -84,12 +93,7 @@ type structInfo struct {
 	Fields map[string]*fieldInfo
 }
 
-// 全局结构体信息缓存
-// Global struct info cache
 var (
-	structCache = make(map[reflect.Type]*structInfo)
-	cacheMutex  sync.RWMutex
-
 	// 对象池用于复用常用对象
 	// Object pools for reusing common objects
 	visitedMapPool = sync.Pool{
@@ -111,6 +115,22 @@ var (
 // serializer implements the JSON serializer
 type serializer struct {
 	options *SerializeOptions
+
+	// arrayOrder 单次序列化中按ArraySort计算出的数组元素顺序
+	// arrayOrder is the element order of arrays computed from ArraySort for a single serialization
+	arrayOrder map[IValue][]int
+
+	// monitor 绑定到该序列化器的性能监控器，为nil时不计时
+	// monitor is the performance monitor bound to this serializer, no timing when nil
+	monitor *PerformanceMonitor
+
+	// yield 单次序列化中按YieldInterval让出处理器的计数器
+	// yield is the counter yielding the processor by YieldInterval for a single serialization
+	yield *yielder
+
+	// numbers 映射到Go值时的数字转换选项，nil表示默认选项
+	// numbers are the number conversion options when mapping to Go values, nil means the defaults
+	numbers *NumberConversionOptions
 }
 
 // NewSerializer 创建新的JSON序列化器
@@ -149,18 +169,54 @@ func NewSerializerWithOptions(options *SerializeOptions) ISerializer {
 // Serialize 序列化JSON值到字节数组
 // Serialize serializes JSON value to byte array
 func (s *serializer) Serialize(value IValue) ([]byte, error) {
+	if s.monitor == nil {
+		return s.serialize(value)
+	}
+
+	timer := s.monitor.StartSerializeTimer()
+	data, err := s.serialize(value)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+	return data, nil
+}
+
+// serialize 序列化JSON值到字节数组，不计时
+// serialize serializes a JSON value to a byte array without timing
+func (s *serializer) serialize(value IValue) ([]byte, error) {
 	if value == nil {
 		return nil, NewInvalidJSONError("cannot serialize nil value", nil)
 	}
 
+	if len(s.options.ArraySort) > 0 {
+		ordered, err := s.withArrayOrder(value)
+		if err != nil {
+			return nil, err
+		}
+		s = ordered
+	}
+	if s.options.YieldInterval > 0 {
+		// 计数器属于单次调用，序列化器本身可以被多个协程共享
+		// The counter belongs to this call, the serializer itself may be shared between goroutines
+		state := *s
+		state.yield = newYielder(s.options.YieldInterval)
+		s = &state
+	}
+
 	var buf bytes.Buffer
-	visited := make(map[IValue]bool)
-	err := s.serializeValue(value, &buf, 0, visited)
+	var err error
+	if s.options.Iterative {
+		err = s.serializeIterative(value, &buf)
+	} else {
+		err = s.serializeValue(value, &buf, 0, make(map[IValue]bool))
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return buf.Bytes(), nil
+	return s.options.EscapeContext.encode(buf.Bytes()), nil
 }
 
 // SerializeToString 序列化JSON值到字符串
@@ -304,7 +360,21 @@ func (s *serializer) serializeValue(value IValue, buf *bytes.Buffer, depth int,
 	}
 	visited[value] = true
 	defer delete(visited, value)
+	s.yield.step(1)
+
+	switch value.Type() {
+	case ObjectValueType:
+		return s.serializeObject(value.(IObject), buf, depth, visited)
+	case ArrayValueType:
+		return s.serializeArray(value.(IArray), buf, depth, visited)
+	default:
+		return s.serializeScalar(value, buf)
+	}
+}
 
+// serializeScalar 序列化对象和数组以外的值
+// serializeScalar serializes a value other than an object or array
+func (s *serializer) serializeScalar(value IValue, buf *bytes.Buffer) error {
 	switch value.Type() {
 	case NullValueType:
 		buf.WriteString("null")
@@ -326,10 +396,6 @@ func (s *serializer) serializeValue(value IValue, buf *bytes.Buffer, depth int,
 		} else {
 			return NewTypeMismatchError(BoolValueType, value.Type(), "")
 		}
-	case ObjectValueType:
-		return s.serializeObject(value.(IObject), buf, depth, visited)
-	case ArrayValueType:
-		return s.serializeArray(value.(IArray), buf, depth, visited)
 	default:
 		return NewInvalidJSONError("unknown value type", nil)
 	}
@@ -360,7 +426,15 @@ func (s *serializer) serializeString(str string, buf *bytes.Buffer) error {
 		case '\t':
 			buf.WriteString(`\t`)
 		case '<', '>', '&':
-			if s.options.EscapeHTML {
+			if s.options.EscapeHTML || s.options.EscapeContext != EscapeContextJSON {
+				buf.WriteString(fmt.Sprintf("\\u%04x", r))
+			} else {
+				buf.WriteRune(r)
+			}
+		case '\'', '\u2028', '\u2029':
+			// 嵌入脚本时会结束JS字符串字面量或行
+			// These end JS string literals or lines when embedded in scripts
+			if s.options.EscapeContext != EscapeContextJSON {
 				buf.WriteString(fmt.Sprintf("\\u%04x", r))
 			} else {
 				buf.WriteRune(r)
@@ -385,6 +459,13 @@ func (s *serializer) serializeString(str string, buf *bytes.Buffer) error {
 // serializeNumber 序列化数字
 // serializeNumber serializes a number
 func (s *serializer) serializeNumber(value IValue, buf *bytes.Buffer) error {
+	// 保留原始文本的数字原样输出
+	// Numbers keeping their original text are written verbatim
+	if tn, ok := value.(*textNumber); ok {
+		buf.WriteString(tn.text)
+		return nil
+	}
+
 	scalar, ok := value.(IScalarValue)
 	if !ok {
 		return NewTypeMismatchError(NumberValueType, value.Type(), "")
@@ -403,12 +484,12 @@ func (s *serializer) serializeNumber(value IValue, buf *bytes.Buffer) error {
 	}
 
 	// 检查特殊值
-	if math.IsNaN(floatVal) { // NaN
-		buf.WriteString("null")
-		return nil
-	}
-	if math.IsInf(floatVal, 0) { // Infinity
-		buf.WriteString("null")
+	if math.IsNaN(floatVal) || math.IsInf(floatVal, 0) {
+		if s.options.AllowNonFinite {
+			buf.WriteString(nonFiniteLiteral(floatVal))
+		} else {
+			buf.WriteString("null")
+		}
 		return nil
 	}
 
@@ -454,7 +535,7 @@ func (s *serializer) serializeObject(obj IObject, buf *bytes.Buffer, depth int,
 		}
 
 		// 序列化键
-		if err := s.serializeString(key, buf); err != nil {
+		if err := s.serializeString(s.options.KeyTransform.Apply(key), buf); err != nil {
 			return err
 		}
 
@@ -492,9 +573,15 @@ func (s *serializer) serializeArray(arr IArray, buf *bytes.Buffer, depth int, vi
 		return nil
 	}
 
+	order := s.arrayOrder[arr]
+
 	first := true
 	for i := 0; i < length; i++ {
-		value := arr.Get(i)
+		index := i
+		if order != nil {
+			index = order[i]
+		}
+		value := arr.Get(index)
 		if value == nil {
 			continue
 		}
@@ -655,11 +742,18 @@ func (s *serializer) mapValueToStruct(value IValue, rv reflect.Value, visited ma
 // mapObjectToStruct maps IObject to struct
 func (s *serializer) mapObjectToStruct(obj IObject, rv reflect.Value, visited map[IValue]bool, depth int) error {
 	if rv.Kind() != reflect.Struct {
-		return NewTypeMismatchError(ObjectValueType, ValueType(rv.Kind()), "")
+		return NewTypeMismatchError(ObjectValueType, ValueTypeFromKind(rv.Kind()), "")
 	}
 
 	structInfo := getStructInfo(rv.Type())
 
+	// 可寻址的结构体通过字段偏移量直接写入标量字段，避免逐字段的反射调用
+	// Addressable structs write scalar fields directly through field offsets, avoiding per-field reflection
+	var base unsafe.Pointer
+	if rv.CanAddr() {
+		base = unsafe.Pointer(rv.UnsafeAddr())
+	}
+
 	// 遍历JSON对象的所有字段
 	var lastErr error
 	obj.Range(func(key string, value IValue) bool {
@@ -668,6 +762,10 @@ func (s *serializer) mapObjectToStruct(obj IObject, rv reflect.Value, visited ma
 			return true // 继续遍历
 		}
 
+		if base != nil && fieldInfo.Setter != nil && s.numbers == nil && fieldInfo.Setter(unsafe.Add(base, fieldInfo.Offset), value) {
+			return true
+		}
+
 		fieldValue := rv.Field(fieldInfo.Index)
 		if !fieldValue.CanSet() {
 			return true // 跳过不可设置的字段
@@ -693,7 +791,7 @@ func (s *serializer) mapArrayToStruct(arr IArray, rv reflect.Value, visited map[
 	case reflect.Array:
 		return s.mapArrayToArray(arr, rv, visited, depth)
 	default:
-		return NewTypeMismatchError(ArrayValueType, ValueType(rv.Kind()), "")
+		return NewTypeMismatchError(ArrayValueType, ValueTypeFromKind(rv.Kind()), "")
 	}
 }
 
@@ -752,7 +850,7 @@ func (s *serializer) mapArrayToArray(arr IArray, rv reflect.Value, visited map[I
 // mapScalarToStruct 将标量值映射到结构体（通常不支持）
 // mapScalarToStruct maps scalar value to struct (usually not supported)
 func (s *serializer) mapScalarToStruct(value IValue, rv reflect.Value) error {
-	return NewTypeMismatchError(value.Type(), ValueType(rv.Kind()), "")
+	return NewTypeMismatchError(value.Type(), ValueTypeFromKind(rv.Kind()), "")
 }
 
 // setFieldValue 设置字段值
@@ -780,6 +878,11 @@ func (s *serializer) setFieldValue(fieldValue reflect.Value, value IValue, field
 // setValueByType 根据类型设置值
 // setValueByType sets value by type
 func (s *serializer) setValueByType(rv reflect.Value, value IValue, targetType reflect.Type, visited map[IValue]bool, depth int) error {
+	// IValue、IObject和IArray直接接收子树
+	if isDocumentType(targetType) {
+		return setDocumentValue(rv, value, targetType)
+	}
+
 	if value.IsNull() {
 		rv.Set(reflect.Zero(targetType))
 		return nil
@@ -810,22 +913,11 @@ func (s *serializer) setValueByType(rv reflect.Value, value IValue, targetType r
 		}
 		return NewTypeMismatchError(valueType, BoolValueType, "")
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
 		if valueType == NumberValueType {
-			return s.setIntValueFast(rv, value, kind)
-		}
-		return NewTypeMismatchError(valueType, NumberValueType, "")
-
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if valueType == NumberValueType {
-			return s.setUintValueFast(rv, value, kind)
-		}
-		return NewTypeMismatchError(valueType, NumberValueType, "")
-
-	case reflect.Float32, reflect.Float64:
-		if valueType == NumberValueType {
-			rv.SetFloat(value.AsFloat64())
-			return nil
+			return s.setNumberValue(rv, value)
 		}
 		return NewTypeMismatchError(valueType, NumberValueType, "")
 
@@ -863,6 +955,20 @@ func (s *serializer) setValueByType(rv reflect.Value, value IValue, targetType r
 		return nil
 
 	case reflect.Interface:
+		// 注册了判别字段的接口解码为具体类型
+		if d := lookupDiscriminator(targetType); d != nil {
+			concreteType, err := d.resolve(value)
+			if err != nil {
+				return err
+			}
+			concrete := reflect.New(concreteType).Elem()
+			if err := s.setValueByType(concrete, value, concreteType, visited, depth+1); err != nil {
+				return err
+			}
+			d.assign(rv, concrete)
+			return nil
+		}
+
 		// 设置为原始值
 		rv.Set(reflect.ValueOf(value.Raw()))
 		return nil
@@ -894,123 +1000,16 @@ func (s *serializer) setBoolValue(rv reflect.Value, value IValue) error {
 	return NewTypeMismatchError(value.Type(), BoolValueType, "")
 }
 
-// setIntValue 设置整数值
-// setIntValue sets integer value
-func (s *serializer) setIntValue(rv reflect.Value, value IValue, targetType reflect.Type) error {
-	if value.Type() == NumberValueType {
-		intVal := value.AsInt64()
-		// 检查范围
-		switch targetType.Kind() {
-		case reflect.Int8:
-			if intVal < -128 || intVal > 127 {
-				return NewJSONError(ErrTypeMismatch, "value out of int8 range", nil)
-			}
-		case reflect.Int16:
-			if intVal < -32768 || intVal > 32767 {
-				return NewJSONError(ErrTypeMismatch, "value out of int16 range", nil)
-			}
-		case reflect.Int32:
-			if intVal < -2147483648 || intVal > 2147483647 {
-				return NewJSONError(ErrTypeMismatch, "value out of int32 range", nil)
-			}
-		}
-		rv.SetInt(intVal)
-		return nil
-	}
-	return NewTypeMismatchError(value.Type(), NumberValueType, "")
-}
-
-// setIntValueFast 快速设置整数值（已知类型匹配）
-// setIntValueFast sets integer value fast (type already matched)
-func (s *serializer) setIntValueFast(rv reflect.Value, value IValue, kind reflect.Kind) error {
-	intVal := value.AsInt64()
-	// 优化的范围检查
-	switch kind {
-	case reflect.Int8:
-		if intVal < -128 || intVal > 127 {
-			return NewJSONError(ErrTypeMismatch, "value out of int8 range", nil)
-		}
-	case reflect.Int16:
-		if intVal < -32768 || intVal > 32767 {
-			return NewJSONError(ErrTypeMismatch, "value out of int16 range", nil)
-		}
-	case reflect.Int32:
-		if intVal < -2147483648 || intVal > 2147483647 {
-			return NewJSONError(ErrTypeMismatch, "value out of int32 range", nil)
-		}
-	}
-	rv.SetInt(intVal)
-	return nil
-}
-
-// setUintValue 设置无符号整数值
-// setUintValue sets unsigned integer value
-func (s *serializer) setUintValue(rv reflect.Value, value IValue, targetType reflect.Type) error {
-	if value.Type() == NumberValueType {
-		intVal := value.AsInt64()
-		if intVal < 0 {
-			return NewJSONError(ErrTypeMismatch, "negative value for unsigned integer", nil)
-		}
-		uintVal := uint64(intVal)
-		// 检查范围
-		switch targetType.Kind() {
-		case reflect.Uint8:
-			if uintVal > 255 {
-				return NewJSONError(ErrTypeMismatch, "value out of uint8 range", nil)
-			}
-		case reflect.Uint16:
-			if uintVal > 65535 {
-				return NewJSONError(ErrTypeMismatch, "value out of uint16 range", nil)
-			}
-		case reflect.Uint32:
-			if uintVal > 4294967295 {
-				return NewJSONError(ErrTypeMismatch, "value out of uint32 range", nil)
-			}
-		}
-		rv.SetUint(uintVal)
-		return nil
-	}
-	return NewTypeMismatchError(value.Type(), NumberValueType, "")
-}
-
-// setUintValueFast 快速设置无符号整数值（已知类型匹配）
-// setUintValueFast sets unsigned integer value fast (type already matched)
-func (s *serializer) setUintValueFast(rv reflect.Value, value IValue, kind reflect.Kind) error {
-	intVal := value.AsInt64()
-	if intVal < 0 {
-		return NewJSONError(ErrTypeMismatch, "negative value for unsigned integer", nil)
-	}
-	uintVal := uint64(intVal)
-	// 优化的范围检查
-	switch kind {
-	case reflect.Uint8:
-		if uintVal > 255 {
-			return NewJSONError(ErrTypeMismatch, "value out of uint8 range", nil)
-		}
-	case reflect.Uint16:
-		if uintVal > 65535 {
-			return NewJSONError(ErrTypeMismatch, "value out of uint16 range", nil)
-		}
-	case reflect.Uint32:
-		if uintVal > 4294967295 {
-			return NewJSONError(ErrTypeMismatch, "value out of uint32 range", nil)
-		}
+// setNumberValue 通过统一的数字转换设置整数或浮点数值，超出目标类型范围时返回错误
+// setNumberValue sets an integer or float value through the shared number conversion, returning an error when
+// the number is outside the range of the target type
+func (s *serializer) setNumberValue(rv reflect.Value, value IValue) error {
+	if err := setReflectNumber(rv, value.Raw(), conversionOptions(s.numbers)); err != nil {
+		return err
 	}
-	rv.SetUint(uintVal)
 	return nil
 }
 
-// setFloatValue 设置浮点数值
-// setFloatValue sets float value
-func (s *serializer) setFloatValue(rv reflect.Value, value IValue, targetType reflect.Type) error {
-	if value.Type() == NumberValueType {
-		floatVal := value.AsFloat64()
-		rv.SetFloat(floatVal)
-		return nil
-	}
-	return NewTypeMismatchError(value.Type(), NumberValueType, "")
-}
-
 // setTimeValue 设置时间值
 // setTimeValue sets time value
 func (s *serializer) setTimeValue(rv reflect.Value, value IValue) error {
@@ -1103,28 +1102,17 @@ func parseJSONTag(tag string) jsonTag {
 // getStructInfo 获取或创建结构体信息
 // getStructInfo gets or creates struct info
 func getStructInfo(t reflect.Type) *structInfo {
-	cacheMutex.RLock()
-	if info, exists := structCache[t]; exists {
-		cacheMutex.RUnlock()
+	if info := globalStructCache.get(t); info != nil {
 		return info
 	}
-	cacheMutex.RUnlock()
 
-	// 创建新的结构体信息
-	return createStructInfo(t)
+	// 创建新的结构体信息，并发创建时以先缓存的为准
+	return globalStructCache.add(t, createStructInfo(t))
 }
 
 // createStructInfo 创建结构体信息
 // createStructInfo creates struct info
 func createStructInfo(t reflect.Type) *structInfo {
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-
-	// 双重检查
-	if info, exists := structCache[t]; exists {
-		return info
-	}
-
 	info := &structInfo{
 		Fields: make(map[string]*fieldInfo),
 	}
@@ -1159,18 +1147,18 @@ func createStructInfo(t reflect.Type) *structInfo {
 			fieldType = field.Type.Elem()
 		}
 
-		info.Fields[fieldName] = &fieldInfo{
-			Index: i,
-			Name:  fieldName,
-			Type:  fieldType,
-			Tag:   tag,
-			IsPtr: isPtr,
+		entry := &fieldInfo{
+			Index:  i,
+			Name:   fieldName,
+			Type:   fieldType,
+			Tag:    tag,
+			IsPtr:  isPtr,
+			Offset: field.Offset,
 		}
-	}
-
-	// 缓存结构体信息
-	if len(structCache) < StructCacheSize {
-		structCache[t] = info
+		if !isPtr && !tag.AsString {
+			entry.Setter = newFieldSetter(fieldType)
+		}
+		info.Fields[fieldName] = entry
 	}
 
 	return info
@@ -0,0 +1,93 @@
+package xyJson
+
+// shape 某个位置上对象的键布局和数组的元素形状，从之前的消息中学习得到
+// shape is the key layout of the object and the element shape of the array at a position, learned from
+// previous messages
+type shape struct {
+	// keys 按出现顺序记录的键，值的键直接引用这些字符串
+	// keys are the keys in order of appearance, the parsed keys reference these strings directly
+	keys []string
+
+	// children 每个键对应的值的形状，按需创建
+	// children are the shapes of the values of each key, created on demand
+	children []*shape
+
+	// elem 数组元素共用的形状
+	// elem is the shape shared by the array elements
+	elem *shape
+
+	// length 上一次在该位置看到的数组长度
+	// length is the array length last seen at this position
+	length int
+}
+
+// NewParserWithShapeCache 创建带形状缓存的解析器，适合反复解析结构相同的消息（如同一个Kafka主题）
+// NewParserWithShapeCache creates a parser with a shape cache, suited to parsing many messages of the same
+// structure (such as one Kafka topic)
+//
+// 解析器记录第一条消息中每个对象的键顺序和每个数组的长度。之后的消息中键与记录的位置一致时直接复用已有的键字符串，
+// 跳过键的分配和重复键检查，并按记录的大小预留对象和数组的容量；键不一致时回退到普通路径，
+// 并以当前消息的布局重新学习。结果与NewParser完全相同
+// The parser records the key order of every object and the length of every array in the first message. In
+// later messages, keys found at their recorded position reuse the existing key strings, skipping the key
+// allocation and the duplicate key check, and objects and arrays reserve room for the recorded size; on a
+// mismatch it falls back to the regular path and relearns the layout from the current message. The results
+// are identical to NewParser
+//
+// 形状缓存属于该解析器，与解析器一样不能并发使用；每个消费协程使用自己的解析器
+// The shape cache belongs to the parser and, like the parser, must not be used concurrently; use one parser
+// per consumer goroutine
+//
+// 示例 Example:
+//
+//	parser := xyJson.NewParserWithShapeCache()
+//	for msg := range messages {
+//		value, err := parser.Parse(msg.Value)
+//		if err != nil {
+//			return err
+//		}
+//		handle(value)
+//	}
+func NewParserWithShapeCache() IParser {
+	p := NewParser().(*parser)
+	p.shapes = &shape{}
+	return p
+}
+
+// child 返回第i个键的值的形状
+// child returns the shape of the value of the i-th key
+func (s *shape) child(i int) *shape {
+	if s.children[i] == nil {
+		s.children[i] = &shape{}
+	}
+	return s.children[i]
+}
+
+// element 返回数组元素的形状
+// element returns the shape of the array elements
+func (s *shape) element() *shape {
+	if s.elem == nil {
+		s.elem = &shape{}
+	}
+	return s.elem
+}
+
+// learn 将第i个键记录为key，丢弃从i开始的旧布局
+// learn records key as the i-th key, discarding the old layout from i on
+func (s *shape) learn(i int, key string) {
+	s.truncate(i)
+	s.keys = append(s.keys, key)
+	s.children = append(s.children, nil)
+}
+
+// truncate 丢弃从i开始的布局
+// truncate discards the layout from i on
+func (s *shape) truncate(i int) {
+	if i >= len(s.keys) {
+		return
+	}
+	clear(s.keys[i:])
+	clear(s.children[i:])
+	s.keys = s.keys[:i]
+	s.children = s.children[:i]
+}
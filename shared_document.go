@@ -0,0 +1,253 @@
+package xyJson
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// SharedDocumentOptions 共享文档的选项
+// SharedDocumentOptions holds the options of a shared document
+type SharedDocumentOptions struct {
+	// Stripes 锁条带数，顶层键按哈希分配到条带；条带越多，不同键之间的冲突越少
+	// Stripes is the number of lock stripes, top-level keys are hashed onto them; more stripes mean fewer
+	// collisions between different keys
+	Stripes int
+}
+
+// DefaultSharedDocumentOptions 返回默认共享文档选项
+// DefaultSharedDocumentOptions returns the default shared document options
+func DefaultSharedDocumentOptions() *SharedDocumentOptions {
+	return &SharedDocumentOptions{
+		Stripes: 64,
+	}
+}
+
+// SharedDocument 由多个协程并发读写的文档，按顶层键分条带加锁，对$.users的写入不会阻塞对$.settings的读取
+// SharedDocument is a document read and written by many goroutines concurrently, locked in stripes by top-level
+// key, so writes to $.users do not block reads of $.settings
+//
+// 路径以普通的顶层属性开头时只锁定该键所在的条带，否则（$、$.*、$..name等）锁定全部条带。
+// 需要多个键的操作按条带下标升序加锁，因此任意并发操作都不会死锁；回调中不能再调用同一个文档的方法，
+// 也不能把取得的值带出回调使用
+// A path starting with a plain top-level property locks only the stripe of that key, otherwise ($, $.*,
+// $..name and so on) all stripes are locked. Operations on several keys lock their stripes in ascending
+// stripe order, so no combination of concurrent operations can deadlock; callbacks must not call methods of
+// the same document, nor use the values they receive after returning
+//
+// 示例 Example:
+//
+//	doc, _ := xyJson.NewSharedDocument(xyJson.MustParseString(`{"users": [], "settings": {}}`))
+//	err := doc.Update("$.users", func(users xyJson.IValue) error {
+//		return users.(xyJson.IArray).Append(newUser)
+//	})
+type SharedDocument struct {
+	root    IObject
+	stripes []sync.RWMutex
+}
+
+// NewSharedDocument 使用默认选项创建共享文档，root必须是对象
+// NewSharedDocument creates a shared document with the default options, root must be an object
+func NewSharedDocument(root IValue) (*SharedDocument, error) {
+	return NewSharedDocumentWithOptions(root, nil)
+}
+
+// NewSharedDocumentWithOptions 使用指定选项创建共享文档，options为nil时使用默认选项；
+// 创建后root只能通过共享文档访问
+// NewSharedDocumentWithOptions creates a shared document with the given options, nil options means the
+// defaults; after creation root must only be accessed through the shared document
+func NewSharedDocumentWithOptions(root IValue, options *SharedDocumentOptions) (*SharedDocument, error) {
+	if root == nil {
+		return nil, NewNullPointerError("shared document root")
+	}
+	obj, ok := root.(IObject)
+	if !ok {
+		return nil, NewTypeMismatchError(ObjectValueType, root.Type(), "$")
+	}
+	if options == nil {
+		options = DefaultSharedDocumentOptions()
+	}
+	if options.Stripes <= 0 {
+		return nil, NewInvalidOperationError("create shared document", "stripes must be positive")
+	}
+
+	return &SharedDocument{
+		root:    obj,
+		stripes: make([]sync.RWMutex, options.Stripes),
+	}, nil
+}
+
+// Read 持有path所在条带的读锁，对path处的值调用fn
+// Read calls fn with the value at path while holding the read lock of its stripe
+func (d *SharedDocument) Read(path string, fn func(value IValue) error) error {
+	stripes, err := d.pathStripes(path)
+	if err != nil {
+		return err
+	}
+	defer d.lock(stripes, false)()
+
+	value, err := Get(d.root, path)
+	if err != nil {
+		return err
+	}
+	return fn(value)
+}
+
+// Update 持有path所在条带的写锁，对path处的值调用fn，fn可以修改该值
+// Update calls fn with the value at path while holding the write lock of its stripe, fn may modify the value
+func (d *SharedDocument) Update(path string, fn func(value IValue) error) error {
+	stripes, err := d.pathStripes(path)
+	if err != nil {
+		return err
+	}
+	defer d.lock(stripes, true)()
+
+	value, err := Get(d.root, path)
+	if err != nil {
+		return err
+	}
+	return fn(value)
+}
+
+// ReadKeys 持有keys所在全部条带的读锁，对根对象调用fn，fn只能读取这些顶层键
+// ReadKeys calls fn with the root object while holding the read locks of the stripes of keys, fn must only
+// read those top-level keys
+func (d *SharedDocument) ReadKeys(keys []string, fn func(root IObject) error) error {
+	defer d.lock(d.keyStripes(keys), false)()
+	return fn(d.root)
+}
+
+// UpdateKeys 持有keys所在全部条带的写锁，对根对象调用fn，用于原子地修改多个顶层键
+// UpdateKeys calls fn with the root object while holding the write locks of the stripes of keys, for
+// modifying several top-level keys atomically
+//
+// 示例 Example:
+//
+//	err := doc.UpdateKeys([]string{"accounts", "ledger"}, func(root xyJson.IObject) error {
+//		// 转账 transfer
+//		return nil
+//	})
+func (d *SharedDocument) UpdateKeys(keys []string, fn func(root IObject) error) error {
+	defer d.lock(d.keyStripes(keys), true)()
+	return fn(d.root)
+}
+
+// Get 返回path处的值的深拷贝，拷贝可以在锁外安全使用
+// Get returns a deep copy of the value at path, which is safe to use outside the lock
+func (d *SharedDocument) Get(path string) (IValue, error) {
+	var clone IValue
+	err := d.Read(path, func(value IValue) error {
+		clone = value.Clone()
+		return nil
+	})
+	return clone, err
+}
+
+// Set 持有path所在条带的写锁设置值，语义同包级Set
+// Set sets a value while holding the write lock of the stripe of path, with the semantics of the package-level Set
+func (d *SharedDocument) Set(path string, value any) error {
+	stripes, err := d.pathStripes(path)
+	if err != nil {
+		return err
+	}
+	defer d.lock(stripes, true)()
+	return Set(d.root, path, value)
+}
+
+// Delete 持有path所在条带的写锁删除值，语义同包级Delete
+// Delete deletes a value while holding the write lock of the stripe of path, with the semantics of the
+// package-level Delete
+func (d *SharedDocument) Delete(path string) error {
+	stripes, err := d.pathStripes(path)
+	if err != nil {
+		return err
+	}
+	defer d.lock(stripes, true)()
+	return Delete(d.root, path)
+}
+
+// Serialize 持有全部条带的读锁序列化整个文档
+// Serialize serializes the whole document while holding the read locks of all stripes
+func (d *SharedDocument) Serialize() ([]byte, error) {
+	defer d.lock(d.allStripes(), false)()
+	return Serialize(d.root)
+}
+
+// Snapshot 持有全部条带的读锁建立整个文档的快照，快照在所有顶层键之间是原子的
+// Snapshot takes a snapshot of the whole document while holding the read locks of all stripes, atomic across
+// all top-level keys
+func (d *SharedDocument) Snapshot() (*DocumentSnapshot, error) {
+	defer d.lock(d.allStripes(), false)()
+	return d.root.Snapshot()
+}
+
+// pathStripes 返回path需要锁定的条带，按下标升序
+// pathStripes returns the stripes path needs locked, in ascending order
+func (d *SharedDocument) pathStripes(path string) ([]int, error) {
+	segments, err := (&pathQuery{}).parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 || segments[0].Type != PropertySegmentType || !plainSegment(segments[0]) {
+		return d.allStripes(), nil
+	}
+	return []int{d.stripeOf(segments[0].Key)}, nil
+}
+
+// keyStripes 返回keys所在的去重条带，按下标升序
+// keyStripes returns the deduplicated stripes of keys in ascending order
+func (d *SharedDocument) keyStripes(keys []string) []int {
+	stripes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		stripes = append(stripes, d.stripeOf(key))
+	}
+	sort.Ints(stripes)
+
+	unique := stripes[:0]
+	for i, stripe := range stripes {
+		if i == 0 || stripe != stripes[i-1] {
+			unique = append(unique, stripe)
+		}
+	}
+	return unique
+}
+
+// allStripes 返回全部条带的下标
+// allStripes returns the indexes of all stripes
+func (d *SharedDocument) allStripes() []int {
+	stripes := make([]int, len(d.stripes))
+	for i := range stripes {
+		stripes[i] = i
+	}
+	return stripes
+}
+
+// stripeOf 返回顶层键所在的条带
+// stripeOf returns the stripe of a top-level key
+func (d *SharedDocument) stripeOf(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.stripes)))
+}
+
+// lock 按升序锁定条带，返回按相反顺序解锁的函数；统一的加锁顺序保证不会死锁
+// lock locks the stripes in ascending order, returning the function that unlocks them in reverse; the single
+// locking order guarantees freedom from deadlock
+func (d *SharedDocument) lock(stripes []int, write bool) func() {
+	for _, i := range stripes {
+		if write {
+			d.stripes[i].Lock()
+		} else {
+			d.stripes[i].RLock()
+		}
+	}
+	return func() {
+		for j := len(stripes) - 1; j >= 0; j-- {
+			if write {
+				d.stripes[stripes[j]].Unlock()
+			} else {
+				d.stripes[stripes[j]].RUnlock()
+			}
+		}
+	}
+}
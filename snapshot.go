@@ -0,0 +1,227 @@
+package xyJson
+
+// DocumentSnapshot 文档的不可变视图，多次读取之间保持一致，即使原文档同时被其他协程修改
+// DocumentSnapshot is an immutable view of a document, consistent across reads even while other goroutines
+// modify the original
+//
+// 冻结时逐个容器加锁读取，每个容器反映其被读取时的状态；与修改并发时不同容器可能来自不同时刻，
+// 因此快照不是整个文档的原子时间点，需要跨容器原子的快照时使用SharedDocument.Snapshot
+// Freezing locks and reads one container at a time, each container reflecting its state when it was read;
+// concurrently with modifications different containers may come from different moments, so the snapshot is
+// not an atomic point in time of the whole document, use SharedDocument.Snapshot for a snapshot that is atomic
+// across containers
+//
+// 快照内部的树不会被修改，读取方法返回的值都是拷贝（copy-on-read），调用方可以任意使用。
+// 快照不包含元数据
+// The tree inside the snapshot is never modified, values returned by the read methods are copies
+// (copy-on-read) that callers may use freely. Snapshots carry no metadata
+type DocumentSnapshot struct {
+	root IValue
+}
+
+// frozenView 容器上一次被冻结的拷贝以及冻结时的纪元
+// frozenView is the copy a container was last frozen into and the epoch it was frozen at
+type frozenView struct {
+	value IValue
+	epoch uint64
+}
+
+// Snapshot 返回对象的快照，见IValue.Snapshot
+// Snapshot returns a snapshot of the object, see IValue.Snapshot
+func (ov *objectValue) Snapshot() (*DocumentSnapshot, error) {
+	return takeSnapshot(ov)
+}
+
+// Snapshot 返回数组的快照，见IValue.Snapshot
+// Snapshot returns a snapshot of the array, see IValue.Snapshot
+func (av *arrayValue) Snapshot() (*DocumentSnapshot, error) {
+	return takeSnapshot(av)
+}
+
+// Snapshot 返回标量的快照
+// Snapshot returns a snapshot of the scalar
+func (sv *scalarValue) Snapshot() (*DocumentSnapshot, error) {
+	return takeSnapshot(sv)
+}
+
+// takeSnapshot 冻结root并返回快照
+// takeSnapshot freezes root and returns a snapshot of it
+func takeSnapshot(root IValue) (*DocumentSnapshot, error) {
+	f := &freezer{epoch: mutationEpoch.Add(1)}
+	frozen, _, err := f.freeze(root, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentSnapshot{root: frozen}, nil
+}
+
+// freezer 一次快照的冻结状态，stack是各层容器共用的子值栈，避免每个容器分配临时切片
+// freezer is the freezing state of one snapshot, stack is the child stack shared by the containers at every
+// level, so containers do not allocate scratch slices of their own
+type freezer struct {
+	epoch uint64
+	stack []objectEntry
+}
+
+// freeze 返回value的冻结拷贝，自上次冻结以来未修改的容器复用上一次的拷贝，reused表示是否复用
+// freeze returns the frozen copy of value, containers unmodified since they were last frozen reuse their
+// previous copy, reused reporting whether it was reused
+//
+// 容器的修改戳不大于上次冻结的纪元说明其键集合和子值的身份都没有改变，再加上所有子容器都被复用，
+// 整个子树就与上次冻结时相同。标量只在所属容器重新冻结时拷贝，因为ParseInto会原地覆盖标量
+// A container whose stamp is not greater than the epoch it was last frozen at has the same keys and the same
+// child identities, and when every child container was reused as well the whole subtree equals the previous
+// freeze. Scalars are copied only when their container is frozen again, as ParseInto overwrites scalars in
+// place
+func (f *freezer) freeze(value IValue, depth int) (_ IValue, reused bool, err error) {
+	if depth > DefaultMaxDepth {
+		return nil, false, NewMaxDepthExceededError(DefaultMaxDepth)
+	}
+
+	base := len(f.stack)
+	defer func() {
+		clear(f.stack[base:])
+		f.stack = f.stack[:base]
+	}()
+
+	switch v := value.(type) {
+	case *objectValue:
+		view := v.view.Load()
+		v.mu.RLock()
+		reused = view != nil && v.stamp.Load() <= view.epoch
+		v.each(func(key string, child IValue) {
+			f.stack = append(f.stack, objectEntry{key: key, value: child})
+		})
+		v.mu.RUnlock()
+
+		if reused, err = f.freezeChildren(base, reused, depth); err != nil || reused {
+			return view.valueOrNil(), reused, err
+		}
+		obj := NewObjectWithCapacity(len(f.stack) - base).(*objectValue)
+		for _, entry := range f.stack[base:] {
+			obj.store(entry.key, entry.value)
+		}
+		v.view.Store(&frozenView{value: obj, epoch: f.epoch})
+		return obj, false, nil
+
+	case *arrayValue:
+		view := v.view.Load()
+		v.mu.RLock()
+		reused = view != nil && v.stamp.Load() <= view.epoch
+		for _, child := range v.data {
+			f.stack = append(f.stack, objectEntry{value: child})
+		}
+		v.mu.RUnlock()
+
+		if reused, err = f.freezeChildren(base, reused, depth); err != nil || reused {
+			return view.valueOrNil(), reused, err
+		}
+		arr := newArrayValue()
+		arr.grow(len(f.stack) - base)
+		for _, entry := range f.stack[base:] {
+			arr.data = append(arr.data, entry.value)
+		}
+		v.view.Store(&frozenView{value: arr, epoch: f.epoch})
+		return arr, false, nil
+
+	default:
+		return frozenScalar(v), false, nil
+	}
+}
+
+// freezeChildren 冻结栈中从base开始的子容器，返回容器能否复用上一次的拷贝；不能复用时将这些子值替换为冻结的拷贝
+// freezeChildren freezes the child containers on the stack from base on, returning whether the container can
+// reuse its previous copy; when it cannot, those children are replaced with their frozen copies
+func (f *freezer) freezeChildren(base int, reused bool, depth int) (bool, error) {
+	// 递归会在栈顶压入并弹出子值，栈可能被重新分配，因此每次都按下标访问
+	// Recursion pushes and pops children on top of the stack, which may reallocate it, so index it every time
+	end := len(f.stack)
+	for i := base; i < end; i++ {
+		switch child := f.stack[i].value.(type) {
+		case *objectValue, *arrayValue:
+			frozen, childReused, err := f.freeze(child, depth+1)
+			if err != nil {
+				return false, err
+			}
+			f.stack[i].value = frozen
+			reused = reused && childReused
+		}
+	}
+	if reused {
+		return true, nil
+	}
+
+	for i := base; i < end; i++ {
+		switch child := f.stack[i].value.(type) {
+		case *objectValue, *arrayValue:
+		default:
+			f.stack[i].value = frozenScalar(child)
+		}
+	}
+	return false, nil
+}
+
+// frozenScalar 返回标量不带元数据的拷贝
+// frozenScalar returns a copy of a scalar without its metadata
+func frozenScalar(value IValue) IValue {
+	if sv, ok := value.(*scalarValue); ok {
+		return &scalarValue{valueType: sv.valueType, rawData: sv.rawData}
+	}
+	if tn, ok := value.(*textNumber); ok {
+		return &textNumber{scalarValue: scalarValue{valueType: tn.valueType, rawData: tn.rawData}, text: tn.text, inexact: tn.inexact}
+	}
+	if value == nil {
+		return defaultFactory().CreateNull()
+	}
+	return value.Clone()
+}
+
+// valueOrNil 返回视图中的值，视图为nil时返回nil
+// valueOrNil returns the value of the view, nil when the view is nil
+func (fv *frozenView) valueOrNil() IValue {
+	if fv == nil {
+		return nil
+	}
+	return fv.value
+}
+
+// Get 返回快照中path处的值的拷贝
+// Get returns a copy of the value at path in the snapshot
+func (s *DocumentSnapshot) Get(path string) (IValue, error) {
+	value, err := defaultPathQuery().SelectOne(s.root, path)
+	if err != nil {
+		return nil, err
+	}
+	return value.Clone(), nil
+}
+
+// GetAll 返回快照中与path匹配的所有值的拷贝
+// GetAll returns copies of all the values matching path in the snapshot
+func (s *DocumentSnapshot) GetAll(path string) ([]IValue, error) {
+	values, err := defaultPathQuery().SelectAll(s.root, path)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		values[i] = value.Clone()
+	}
+	return values, nil
+}
+
+// Exists 检查快照中是否存在path
+// Exists checks whether path exists in the snapshot
+func (s *DocumentSnapshot) Exists(path string) bool {
+	return defaultPathQuery().Exists(s.root, path)
+}
+
+// Serialize 序列化快照，结果在多次调用间缓存，不能修改
+// Serialize serializes the snapshot, the result is cached across calls and must not be modified
+func (s *DocumentSnapshot) Serialize() ([]byte, error) {
+	return s.root.CachedJSON()
+}
+
+// Value 返回整个快照的拷贝
+// Value returns a copy of the whole snapshot
+func (s *DocumentSnapshot) Value() IValue {
+	return s.root.Clone()
+}
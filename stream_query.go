@@ -0,0 +1,501 @@
+package xyJson
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errStopStream 回调要求停止时用于结束遍历的内部错误
+// errStopStream is the internal error used to end the walk when the callback asks to stop
+var errStopStream = errors.New("stream query stopped")
+
+// streamState 流式匹配状态：文档当前位置上第query个查询已消耗的路径段数量
+// streamState is a streaming match state: the number of path segments of the query-th query consumed at the
+// current position
+type streamState struct {
+	query int
+	pos   int
+	// filter 表示当前元素需要先通过segments[pos]的过滤器
+	// filter means the current element must first pass the filter of segments[pos]
+	filter bool
+}
+
+// streamQuery 基于io.Reader的流式JSONPath查询，一次遍历可以同时匹配多个查询
+// streamQuery is a streaming JSONPath query over an io.Reader, a single pass may match several queries at once
+type streamQuery struct {
+	reader  *bufio.Reader
+	queries [][]*pathSegment
+	pq      *pathQuery
+	fn      func(query int, value IValue) bool
+	buf     []byte
+	offset  int64
+	depth   int
+}
+
+// QueryStream 在不完整解析文档的情况下对io.Reader执行JSONPath查询
+// QueryStream runs a JSONPath query over an io.Reader without parsing the whole document
+//
+// 只有可能匹配的子树会被解析为IValue，其余内容在扫描时直接丢弃，匹配结果按文档顺序在解析完成后
+// 立即传给fn；fn返回false时停止读取。输入可以包含多个连续的顶层JSON值（如NDJSON），每个值分别匹配。
+// 过滤器和负索引需要看到完整的元素或数组，这些位置会局部解析后在内存中求值
+// Only subtrees that can match are parsed into IValues, everything else is discarded while scanning.
+// Matches are passed to fn in document order as soon as they are parsed; returning false from fn stops reading.
+// The input may contain several consecutive top-level JSON values (such as NDJSON), each matched separately.
+// Filters and negative indices need the complete element or array, so those positions are parsed locally
+// and evaluated in memory
+//
+// 示例 Example:
+//
+//	f, _ := os.Open("export.json")
+//	defer f.Close()
+//	err := xyJson.QueryStream(f, "$..items[*].id", func(id xyJson.IValue) bool {
+//		fmt.Println(id.String())
+//		return true
+//	})
+func QueryStream(r io.Reader, path string, fn func(IValue) bool) error {
+	compiled, err := CompilePath(path)
+	if err != nil {
+		return err
+	}
+	return compiled.QueryStream(r, fn)
+}
+
+// QueryStream 使用预编译路径对io.Reader执行流式查询
+// QueryStream runs a streaming query over an io.Reader using the compiled path
+func (cp *CompiledPath) QueryStream(r io.Reader, fn func(IValue) bool) error {
+	if r == nil {
+		return NewNullPointerError("reader cannot be nil")
+	}
+	if fn == nil {
+		return NewNullPointerError("callback cannot be nil")
+	}
+
+	cp.mu.RLock()
+	segments := cp.segments
+	cp.mu.RUnlock()
+
+	sq := newStreamQuery(r, [][]*pathSegment{segments}, func(_ int, value IValue) bool {
+		return fn(value)
+	})
+	return sq.run()
+}
+
+// newStreamQuery 创建同时匹配多个查询的流式查询
+// newStreamQuery creates a streaming query matching several queries at once
+func newStreamQuery(r io.Reader, queries [][]*pathSegment, fn func(query int, value IValue) bool) *streamQuery {
+	return &streamQuery{
+		reader:  bufio.NewReaderSize(r, DefaultParserBufferSize),
+		queries: queries,
+		pq:      &pathQuery{factory: defaultFactory()},
+		fn:      fn,
+	}
+}
+
+// run 依次匹配输入中的每个顶层值，直到输入结束或回调要求停止
+// run matches every top-level value of the input in turn, until the input ends or the callback asks to stop
+func (sq *streamQuery) run() error {
+	initial := make([]streamState, len(sq.queries))
+	for i := range initial {
+		initial[i].query = i
+	}
+
+	for {
+		if err := sq.skipWhitespace(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		err := sq.walk(initial)
+		if err == errStopStream {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// walk 处理当前位置的值
+// walk processes the value at the current position
+func (sq *streamQuery) walk(states []streamState) error {
+	if len(states) == 0 {
+		return sq.scanValue(false)
+	}
+	if sq.needsValue(states) {
+		return sq.evaluate(states)
+	}
+
+	ch, err := sq.peek()
+	if err != nil {
+		return err
+	}
+
+	switch ch {
+	case '{':
+		return sq.walkObject(states)
+	case '[':
+		return sq.walkArray(states)
+	default:
+		// 标量没有子节点，剩余路径段无法匹配
+		// Scalars have no children, the remaining segments cannot match
+		return sq.scanValue(false)
+	}
+}
+
+// walkObject 流式遍历对象的成员
+// walkObject walks the members of an object in streaming fashion
+func (sq *streamQuery) walkObject(states []streamState) error {
+	if err := sq.enter(); err != nil {
+		return err
+	}
+	sq.readByte()
+
+	first := true
+	for {
+		if err := sq.skipWhitespace(); err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		ch, _ := sq.peek()
+		if ch == '}' {
+			sq.readByte()
+			sq.depth--
+			return nil
+		}
+		if !first {
+			if ch != ',' {
+				return sq.syntaxError("expected ',' or '}' in object")
+			}
+			sq.readByte()
+			if err := sq.skipWhitespace(); err != nil {
+				return sq.unexpectedEOF(err)
+			}
+		}
+		first = false
+
+		key, err := sq.readKey()
+		if err != nil {
+			return err
+		}
+		if err := sq.skipWhitespace(); err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		if b, _ := sq.readByte(); b != ':' {
+			return sq.syntaxError("expected ':' after object key")
+		}
+		if err := sq.skipWhitespace(); err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		if err := sq.walk(sq.advance(states, key, -1)); err != nil {
+			return err
+		}
+	}
+}
+
+// walkArray 流式遍历数组的元素
+// walkArray walks the elements of an array in streaming fashion
+func (sq *streamQuery) walkArray(states []streamState) error {
+	if err := sq.enter(); err != nil {
+		return err
+	}
+	sq.readByte()
+
+	for index := 0; ; index++ {
+		if err := sq.skipWhitespace(); err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		ch, _ := sq.peek()
+		if ch == ']' {
+			sq.readByte()
+			sq.depth--
+			return nil
+		}
+		if index > 0 {
+			if ch != ',' {
+				return sq.syntaxError("expected ',' or ']' in array")
+			}
+			sq.readByte()
+			if err := sq.skipWhitespace(); err != nil {
+				return sq.unexpectedEOF(err)
+			}
+		}
+		if err := sq.walk(sq.advance(states, "", index)); err != nil {
+			return err
+		}
+	}
+}
+
+// advance 计算子节点的匹配状态，index为-1表示对象成员
+// advance computes the match states of a child, index -1 denotes an object member
+func (sq *streamQuery) advance(states []streamState, key string, index int) []streamState {
+	var next []streamState
+	add := func(state streamState) {
+		for _, existing := range next {
+			if existing == state {
+				return
+			}
+		}
+		next = append(next, state)
+	}
+
+	inObject := index < 0
+	for _, state := range states {
+		segment := sq.queries[state.query][state.pos]
+		if segment.Recursive {
+			add(state)
+			if segment.Wildcard || (inObject && key == segment.Key) {
+				add(streamState{query: state.query, pos: state.pos + 1})
+			}
+			continue
+		}
+
+		switch segment.Type {
+		case PropertySegmentType:
+			if segment.Wildcard || (inObject && key == segment.Key) {
+				add(streamState{query: state.query, pos: state.pos + 1})
+			}
+		case IndexSegmentType:
+			if !inObject && (segment.Wildcard || segment.Index == index) {
+				add(streamState{query: state.query, pos: state.pos + 1})
+			}
+		case FilterSegmentType:
+			if !inObject {
+				add(streamState{query: state.query, pos: state.pos, filter: true})
+			}
+		}
+	}
+	return next
+}
+
+// needsValue 检查当前值是否需要完整解析：已完成匹配、等待过滤、使用负索引或自定义段
+// needsValue checks if the current value must be parsed: a completed match, a pending filter, a negative index
+// or a custom segment
+func (sq *streamQuery) needsValue(states []streamState) bool {
+	for _, state := range states {
+		segments := sq.queries[state.query]
+		if state.filter || state.pos == len(segments) {
+			return true
+		}
+		segment := segments[state.pos]
+		if segment.Type == IndexSegmentType && !segment.Wildcard && !segment.Recursive && segment.Index < 0 {
+			return true
+		}
+		if segment.Type == CustomSegmentType {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate 解析当前值并在内存中求值所有状态
+// evaluate parses the current value and evaluates all states in memory
+func (sq *streamQuery) evaluate(states []streamState) error {
+	sq.buf = sq.buf[:0]
+	if err := sq.scanValue(true); err != nil {
+		return err
+	}
+	value, err := Parse(sq.buf)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		segments := sq.queries[state.query]
+		var results []IValue
+		switch {
+		case state.filter:
+			if sq.pq.evaluateFilter(value, segments[state.pos].Filter) {
+				results = sq.pq.executeQuery(value, segments[state.pos+1:], true)
+			}
+		default:
+			results = sq.pq.executeQuery(value, segments[state.pos:], true)
+		}
+		for _, result := range results {
+			if !sq.fn(state.query, result) {
+				return errStopStream
+			}
+		}
+	}
+	return nil
+}
+
+// scanValue 扫描一个完整的JSON值，capture为true时将原始字节追加到缓冲区
+// scanValue scans one complete JSON value, appending the raw bytes to the buffer when capture is true
+func (sq *streamQuery) scanValue(capture bool) error {
+	nesting := 0
+	for {
+		if nesting > 0 {
+			if err := sq.skipWhitespace(); err != nil {
+				return sq.unexpectedEOF(err)
+			}
+		}
+		b, err := sq.readByte()
+		if err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		if capture {
+			sq.buf = append(sq.buf, b)
+		}
+
+		switch b {
+		case '{', '[':
+			nesting++
+			if sq.depth+nesting > MaxNestingDepth {
+				return NewMaxDepthExceededError(MaxNestingDepth)
+			}
+			continue
+		case '}', ']':
+			nesting--
+			if nesting < 0 {
+				return sq.syntaxError("unexpected " + string(b))
+			}
+		case ',', ':':
+			continue
+		case '"':
+			if err := sq.scanString(capture); err != nil {
+				return err
+			}
+		default:
+			if !isLiteralByte(b) {
+				return sq.syntaxError("unexpected character: " + string(b))
+			}
+			if err := sq.scanLiteral(capture); err != nil {
+				return err
+			}
+		}
+
+		if nesting == 0 {
+			return nil
+		}
+	}
+}
+
+// scanString 扫描字符串的剩余部分（开始引号已读取）
+// scanString scans the rest of a string (the opening quote has been read)
+func (sq *streamQuery) scanString(capture bool) error {
+	escaped := false
+	for {
+		b, err := sq.readByte()
+		if err != nil {
+			return sq.unexpectedEOF(err)
+		}
+		if capture {
+			sq.buf = append(sq.buf, b)
+		}
+		switch {
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+		case b == '"':
+			return nil
+		}
+	}
+}
+
+// scanLiteral 扫描数字、true、false或null的剩余部分
+// scanLiteral scans the rest of a number, true, false or null
+func (sq *streamQuery) scanLiteral(capture bool) error {
+	for {
+		b, err := sq.peek()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !isLiteralByte(b) {
+			return nil
+		}
+		sq.readByte()
+		if capture {
+			sq.buf = append(sq.buf, b)
+		}
+	}
+}
+
+// readKey 读取并反转义对象键
+// readKey reads and unescapes an object key
+func (sq *streamQuery) readKey() (string, error) {
+	if b, _ := sq.readByte(); b != '"' {
+		return "", sq.syntaxError("expected string key in object")
+	}
+	sq.buf = sq.buf[:0]
+	if err := sq.scanString(true); err != nil {
+		return "", err
+	}
+	raw := string(sq.buf[:len(sq.buf)-1])
+	key, err := (&parser{}).unescapeString(raw)
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// enter 进入容器并检查嵌套深度
+// enter enters a container and checks the nesting depth
+func (sq *streamQuery) enter() error {
+	sq.depth++
+	if sq.depth > MaxNestingDepth {
+		return NewMaxDepthExceededError(MaxNestingDepth)
+	}
+	return nil
+}
+
+// skipWhitespace 跳过空白字符，输入结束时返回io.EOF
+// skipWhitespace skips whitespace, returns io.EOF at the end of input
+func (sq *streamQuery) skipWhitespace() error {
+	for {
+		b, err := sq.peek()
+		if err != nil {
+			return err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return nil
+		}
+		sq.readByte()
+	}
+}
+
+// peek 查看下一个字节
+// peek returns the next byte without consuming it
+func (sq *streamQuery) peek() (byte, error) {
+	b, err := sq.reader.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readByte 读取一个字节
+// readByte reads one byte
+func (sq *streamQuery) readByte() (byte, error) {
+	b, err := sq.reader.ReadByte()
+	if err == nil {
+		sq.offset++
+	}
+	return b, err
+}
+
+// syntaxError 创建带偏移量的语法错误
+// syntaxError creates a syntax error carrying the byte offset
+func (sq *streamQuery) syntaxError(message string) error {
+	return NewInvalidJSONError(fmt.Sprintf("%s at offset %d", message, sq.offset), nil)
+}
+
+// unexpectedEOF 将io.EOF转换为语法错误
+// unexpectedEOF converts io.EOF into a syntax error
+func (sq *streamQuery) unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return sq.syntaxError("unexpected end of input")
+	}
+	return err
+}
+
+// isLiteralByte 检查字节是否可以出现在数字或字面量中
+// isLiteralByte checks if a byte may appear in a number or literal
+func isLiteralByte(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || b == '-' || b == '+' || b == '.' || b == 'E'
+}
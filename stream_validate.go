@@ -0,0 +1,278 @@
+package xyJson
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// streamValidator 基于io.Reader的流式Schema校验
+// streamValidator validates an io.Reader against a schema in streaming fashion
+type streamValidator struct {
+	streamQuery
+}
+
+// ValidateStream 在扫描io.Reader的同时按Schema校验，不构建完整文档，遇到第一个违反项立即返回
+// ValidateStream validates an io.Reader against a schema while scanning, without building the document,
+// returning as soon as the first violation is found
+//
+// 对象和数组逐个成员流式校验；标量以及需要看到完整值的关键字（enum、const、allOf、oneOf、not、
+// uniqueItems，以及无法按类型确定分支的anyOf）所在的值会局部解析后在内存中校验。
+// 输入必须是单个JSON值
+// Objects and arrays are validated member by member; scalars and values under keywords that need the whole
+// value (enum, const, allOf, oneOf, not, uniqueItems, and anyOf whose branch cannot be picked by type) are
+// parsed locally and validated in memory. The input must be a single JSON value
+//
+// 示例 Example:
+//
+//	schema := xyJson.MustCompileSchema(xyJson.MustGenerateSchema(Upload{}))
+//	if err := xyJson.ValidateStream(r.Body, schema); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+func ValidateStream(r io.Reader, schema *Schema) error {
+	if r == nil {
+		return NewNullPointerError("reader cannot be nil")
+	}
+	if schema == nil {
+		return NewNullPointerError("schema cannot be nil")
+	}
+
+	sv := &streamValidator{}
+	sv.reader = bufio.NewReaderSize(r, DefaultParserBufferSize)
+
+	if err := sv.skipWhitespace(); err != nil {
+		return sv.unexpectedEOF(err)
+	}
+	if err := sv.validate(schema, "$"); err != nil {
+		return err
+	}
+
+	err := sv.skipWhitespace()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return sv.syntaxError("unexpected data after JSON value")
+}
+
+// validate 校验当前位置的值
+// validate validates the value at the current position
+func (sv *streamValidator) validate(schema *Schema, path string) error {
+	s := schema.resolved()
+
+	ch, err := sv.peek()
+	if err != nil {
+		return sv.unexpectedEOF(err)
+	}
+	var kind schemaType
+	switch ch {
+	case '{':
+		kind = schemaObject
+	case '[':
+		kind = schemaArray
+	default:
+		return sv.buffered(s, path)
+	}
+
+	if s.reject {
+		return NewSchemaViolationError(path, "value is not allowed")
+	}
+	if s.types != 0 && s.types&kind == 0 {
+		return NewSchemaViolationError(path, "expected type "+s.types.String()+" but got "+kind.String())
+	}
+	if s.enum != nil || s.constValue != nil || s.allOf != nil || s.oneOf != nil || s.not != nil ||
+		(kind == schemaArray && s.uniqueItems) {
+		return sv.buffered(s, path)
+	}
+
+	if s.anyOf != nil {
+		branch := s.anyOfBranch(kind)
+		if branch == nil {
+			return sv.buffered(s, path)
+		}
+		return sv.validate(branch, path)
+	}
+
+	if kind == schemaObject {
+		return sv.validateObject(s, path)
+	}
+	return sv.validateArray(s, path)
+}
+
+// anyOfBranch 返回唯一能接受该容器类型的anyOf分支；Schema自身还有容器约束或分支不唯一时返回nil
+// anyOfBranch returns the only anyOf branch accepting the container type; nil when the schema has its own
+// container constraints or the branch is not unique
+func (s *Schema) anyOfBranch(kind schemaType) *Schema {
+	if s.properties != nil || s.required != nil || s.additional != nil || s.minProperties >= 0 || s.maxProperties >= 0 ||
+		s.items != nil || s.tupleItems != nil || s.minItems >= 0 || s.maxItems >= 0 {
+		return nil
+	}
+
+	var branch *Schema
+	for _, candidate := range s.anyOf {
+		resolved := candidate.resolved()
+		if resolved.reject || (resolved.types != 0 && resolved.types&kind == 0) {
+			continue
+		}
+		if branch != nil {
+			return nil
+		}
+		branch = candidate
+	}
+	if branch == nil {
+		// 没有分支接受该类型，交给内存校验报告错误
+		// No branch accepts the type, let in-memory validation report the error
+		return nil
+	}
+	return branch
+}
+
+// validateObject 流式校验对象成员
+// validateObject validates the members of an object in streaming fashion
+func (sv *streamValidator) validateObject(s *Schema, path string) error {
+	if err := sv.enter(); err != nil {
+		return err
+	}
+	sv.readByte()
+
+	var seen map[string]bool
+	if s.required != nil {
+		seen = make(map[string]bool, len(s.required))
+	}
+
+	count := 0
+	for {
+		if err := sv.skipWhitespace(); err != nil {
+			return sv.unexpectedEOF(err)
+		}
+		ch, _ := sv.peek()
+		if ch == '}' {
+			sv.readByte()
+			sv.depth--
+			break
+		}
+		if count > 0 {
+			if ch != ',' {
+				return sv.syntaxError("expected ',' or '}' in object")
+			}
+			sv.readByte()
+			if err := sv.skipWhitespace(); err != nil {
+				return sv.unexpectedEOF(err)
+			}
+		}
+
+		key, err := sv.readKey()
+		if err != nil {
+			return err
+		}
+		if err := sv.skipWhitespace(); err != nil {
+			return sv.unexpectedEOF(err)
+		}
+		if b, _ := sv.readByte(); b != ':' {
+			return sv.syntaxError("expected ':' after object key")
+		}
+
+		// 成员数量和不允许的键在读取值之前就能判断
+		// The member count and forbidden keys are known before reading the value
+		count++
+		if s.maxProperties >= 0 && count > s.maxProperties {
+			return s.checkPropertyCount(count, path)
+		}
+		child := s.propertySchema(key)
+		childPath := appendPathKey(path, key)
+		if child != nil && child.resolved().reject {
+			return s.forbiddenProperty(key, childPath)
+		}
+		if seen != nil {
+			seen[key] = true
+		}
+
+		if err := sv.skipWhitespace(); err != nil {
+			return sv.unexpectedEOF(err)
+		}
+		if child == nil {
+			err = sv.scanValue(false)
+		} else {
+			err = sv.validate(child, childPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.checkPropertyCount(count, path); err != nil {
+		return err
+	}
+	for _, name := range s.required {
+		if !seen[name] {
+			return s.missingProperty(name, path)
+		}
+	}
+	return nil
+}
+
+// validateArray 流式校验数组元素
+// validateArray validates the items of an array in streaming fashion
+func (sv *streamValidator) validateArray(s *Schema, path string) error {
+	if err := sv.enter(); err != nil {
+		return err
+	}
+	sv.readByte()
+
+	index := 0
+	for ; ; index++ {
+		if err := sv.skipWhitespace(); err != nil {
+			return sv.unexpectedEOF(err)
+		}
+		ch, _ := sv.peek()
+		if ch == ']' {
+			sv.readByte()
+			sv.depth--
+			break
+		}
+		if index > 0 {
+			if ch != ',' {
+				return sv.syntaxError("expected ',' or ']' in array")
+			}
+			sv.readByte()
+		}
+
+		// 逗号之后必然还有元素，无需继续读取即可判断超出maxItems
+		// A comma means another item follows, so exceeding maxItems is known without reading further
+		if s.maxItems >= 0 && index >= s.maxItems {
+			return s.checkItemCount(index+1, path)
+		}
+		if err := sv.skipWhitespace(); err != nil {
+			return sv.unexpectedEOF(err)
+		}
+
+		var err error
+		if child := s.itemSchema(index); child != nil {
+			err = sv.validate(child, path+"["+strconv.Itoa(index)+"]")
+		} else {
+			err = sv.scanValue(false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.checkItemCount(index, path)
+}
+
+// buffered 局部解析当前值并在内存中校验
+// buffered parses the current value locally and validates it in memory
+func (sv *streamValidator) buffered(s *Schema, path string) error {
+	sv.buf = sv.buf[:0]
+	if err := sv.scanValue(true); err != nil {
+		return err
+	}
+	value, err := Parse(sv.buf)
+	if err != nil {
+		return err
+	}
+	return s.validate(value, path, sv.depth)
+}
@@ -0,0 +1,298 @@
+package xyJson
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldError 严格解码时单个值的错误
+// FieldError is the error for a single value during strict decoding
+type FieldError struct {
+	// Path 出错值的JSONPath，如$.items[2].price
+	// Path is the JSONPath of the failing value, such as $.items[2].price
+	Path string
+
+	// GoType 目标Go类型
+	// GoType is the target Go type
+	GoType string
+
+	// Err 错误详情，Path与FieldError.Path相同
+	// Err holds the details, its Path equals FieldError.Path
+	Err *JSONError
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (fe FieldError) Error() string {
+	return fe.Err.Error()
+}
+
+// Unwrap 返回错误详情
+// Unwrap returns the details
+func (fe FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// StrictDecodeError DecodeStrictInto发现的所有字段错误
+// StrictDecodeError holds every field error found by DecodeStrictInto
+type StrictDecodeError struct {
+	// Fields 按遍历顺序排列的字段错误，对象按键排序遍历
+	// Fields are the field errors in traversal order, objects are visited in key order
+	Fields []FieldError
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (se *StrictDecodeError) Error() string {
+	if len(se.Fields) == 1 {
+		return se.Fields[0].Error()
+	}
+	return fmt.Sprintf("%d fields could not be decoded, first: %s", len(se.Fields), se.Fields[0].Error())
+}
+
+// Unwrap 返回所有字段错误，便于errors.Is和errors.As
+// Unwrap returns every field error for errors.Is and errors.As
+func (se *StrictDecodeError) Unwrap() []error {
+	errs := make([]error, len(se.Fields))
+	for i, field := range se.Fields {
+		errs[i] = field.Err
+	}
+	return errs
+}
+
+// DecodeStrictInto 将JSON字节数组严格解码到target，收集所有字段错误而不是在第一个错误处停止
+// DecodeStrictInto strictly decodes a JSON byte array into target, collecting every field error instead of
+// stopping at the first one
+//
+// 类型不匹配、数字超出范围或带小数部分写入整数、时间格式错误都会记录为带JSONPath的FieldError，
+// 并以*StrictDecodeError返回；与宽松的映射不同，数字和布尔值不会被转换为字符串。
+// 有效的字段照常写入，出错的字段保持零值。无效JSON和无效target直接返回对应的错误
+// Type mismatches, numbers out of range or with a fractional part for integers, and bad time formats are
+// each recorded as a FieldError with its JSONPath and returned as a *StrictDecodeError; unlike the lenient
+// mapping, numbers and booleans are not converted to strings. Valid fields are written as usual, failing
+// fields keep their zero value. Invalid JSON and an invalid target return the matching error directly
+//
+// 示例 Example:
+//
+//	var order Order
+//	err := xyJson.DecodeStrictInto(body, &order)
+//	var strictErr *xyJson.StrictDecodeError
+//	if errors.As(err, &strictErr) {
+//		for _, field := range strictErr.Fields {
+//			fmt.Println(field.Path, field.Err.Message)
+//		}
+//	}
+func DecodeStrictInto(data []byte, target interface{}) (err error) {
+	defer recoverPanic("decode strict", &err)
+	if target == nil {
+		return NewNullPointerError("target cannot be nil")
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr {
+		return NewJSONError(ErrInvalidOperation, "target must be a pointer", nil)
+	}
+	if rv.IsNil() {
+		return NewNullPointerError("target pointer cannot be nil")
+	}
+
+	data, err = decodeInput(data)
+	if err != nil {
+		return err
+	}
+	value, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	d := &strictDecoder{}
+	elem := rv.Elem()
+	d.decode(value, elem, elem.Type(), "$", 0)
+	if len(d.fields) > 0 {
+		return &StrictDecodeError{Fields: d.fields}
+	}
+	return nil
+}
+
+// DecodeStrictIntoString 将JSON字符串严格解码到target
+// DecodeStrictIntoString strictly decodes a JSON string into target
+func DecodeStrictIntoString(data string, target interface{}) error {
+	return DecodeStrictInto([]byte(data), target)
+}
+
+// strictDecoder 将IValue写入Go值并收集字段错误
+// strictDecoder writes an IValue into a Go value and collects field errors
+type strictDecoder struct {
+	fields []FieldError
+}
+
+// fail 记录字段错误
+// fail records a field error
+func (d *strictDecoder) fail(path string, t reflect.Type, err *JSONError) {
+	d.fields = append(d.fields, FieldError{Path: path, GoType: t.String(), Err: err.WithPath(path)})
+}
+
+// mismatch 记录类型不匹配错误
+// mismatch records a type mismatch error
+func (d *strictDecoder) mismatch(path string, t reflect.Type, expected ValueType, value IValue) {
+	d.fail(path, t, NewTypeMismatchError(expected, value.Type(), path))
+}
+
+// decode 将value写入rv，rv的类型为t
+// decode writes value into rv, whose type is t
+func (d *strictDecoder) decode(value IValue, rv reflect.Value, t reflect.Type, path string, depth int) {
+	if depth > MaxStructDepth {
+		d.fail(path, t, NewMaxDepthExceededError(MaxStructDepth))
+		return
+	}
+	if isDocumentType(t) {
+		if err := setDocumentValue(rv, value, t); err != nil {
+			d.fail(path, t, err.(*JSONError))
+		}
+		return
+	}
+	if value.IsNull() {
+		rv.Set(reflect.Zero(t))
+		return
+	}
+	if t == timeType {
+		if value.Type() != StringValueType {
+			d.mismatch(path, t, StringValueType, value)
+			return
+		}
+		if err := mappingSerializer.setTimeValue(rv, value); err != nil {
+			d.fail(path, t, NewJSONError(ErrTypeMismatch, "invalid time format", nil).
+				WithContext(fmt.Sprintf("cannot parse %q as a time", value.String())))
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(t.Elem())
+		d.decode(value, ptr.Elem(), t.Elem(), path, depth)
+		rv.Set(ptr)
+
+	case reflect.Struct:
+		obj, ok := value.(IObject)
+		if !ok || value.Type() != ObjectValueType {
+			d.mismatch(path, t, ObjectValueType, value)
+			return
+		}
+		info := getStructInfo(t)
+		obj.Range(func(key string, child IValue) bool {
+			field, exists := info.Fields[key]
+			if !exists || field.Tag.Skip {
+				return true
+			}
+			fieldValue := rv.Field(field.Index)
+			if fieldValue.CanSet() {
+				d.decode(child, fieldValue, fieldValue.Type(), appendPathKey(path, key), depth+1)
+			}
+			return true
+		})
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := value.(IArray)
+		if !ok || value.Type() != ArrayValueType {
+			d.mismatch(path, t, ArrayValueType, value)
+			return
+		}
+		length := arr.Length()
+		target := rv
+		if t.Kind() == reflect.Slice {
+			target = reflect.MakeSlice(t, length, length)
+		} else if length > rv.Len() {
+			d.fail(path, t, NewJSONError(ErrIndexOutOfRange,
+				fmt.Sprintf("array of length %d does not fit %s", length, t), nil))
+			length = rv.Len()
+		}
+		for i := 0; i < length; i++ {
+			if child := arr.Get(i); child != nil {
+				d.decode(child, target.Index(i), t.Elem(), fmt.Sprintf("%s[%d]", path, i), depth+1)
+			}
+		}
+		if t.Kind() == reflect.Slice {
+			rv.Set(target)
+		}
+
+	case reflect.Map:
+		obj, ok := value.(IObject)
+		if !ok || value.Type() != ObjectValueType {
+			d.mismatch(path, t, ObjectValueType, value)
+			return
+		}
+		if t.Key().Kind() != reflect.String {
+			d.fail(path, t, NewJSONError(ErrTypeMismatch, "map key must be string", nil))
+			return
+		}
+		m := reflect.MakeMapWithSize(t, obj.Size())
+		obj.Range(func(key string, child IValue) bool {
+			elem := reflect.New(t.Elem()).Elem()
+			d.decode(child, elem, t.Elem(), appendPathKey(path, key), depth+1)
+			m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+			return true
+		})
+		rv.Set(m)
+
+	case reflect.Interface:
+		if disc := lookupDiscriminator(t); disc != nil {
+			concreteType, err := disc.resolve(value)
+			if err != nil {
+				d.fail(path, t, err)
+				return
+			}
+			concrete := reflect.New(concreteType).Elem()
+			d.decode(value, concrete, concreteType, path, depth+1)
+			disc.assign(rv, concrete)
+			return
+		}
+		raw := reflect.ValueOf(value.Raw())
+		if !raw.IsValid() || !raw.Type().AssignableTo(t) {
+			d.fail(path, t, NewJSONError(ErrTypeMismatch, "cannot assign "+value.Type().String()+" to "+t.String(), nil))
+			return
+		}
+		rv.Set(raw)
+
+	default:
+		d.decodeScalar(value, rv, t, path)
+	}
+}
+
+// decodeScalar 严格写入字符串、布尔值和数字，检查整数的小数部分和目标类型的范围
+// decodeScalar strictly writes strings, booleans and numbers, checking integers for fractional parts and
+// the range of the target type
+func (d *strictDecoder) decodeScalar(value IValue, rv reflect.Value, t reflect.Type, path string) {
+	switch t.Kind() {
+	case reflect.String:
+		s, ok := value.AsStringOK()
+		if !ok {
+			d.mismatch(path, t, StringValueType, value)
+			return
+		}
+		rv.SetString(s)
+
+	case reflect.Bool:
+		b, ok := value.AsBoolOK()
+		if !ok {
+			d.mismatch(path, t, BoolValueType, value)
+			return
+		}
+		rv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if value.Type() != NumberValueType {
+			d.mismatch(path, t, NumberValueType, value)
+			return
+		}
+		// 严格解码从不截断小数，不受全局选项影响
+		// Strict decoding never truncates fractions, regardless of the global options
+		if err := setReflectNumber(rv, value.Raw(), &defaultNumberConversion); err != nil {
+			d.fail(path, t, err)
+		}
+
+	default:
+		d.fail(path, t, NewJSONError(ErrTypeMismatch, fmt.Sprintf("unsupported type: %s", t), nil))
+	}
+}
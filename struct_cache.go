@@ -0,0 +1,190 @@
+package xyJson
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// StructCacheStats 结构体信息缓存的统计信息
+// StructCacheStats contains the statistics of the struct info cache
+type StructCacheStats struct {
+	// Size 当前缓存的类型数
+	// Size is the number of types currently cached
+	Size int
+
+	// MaxSize 缓存的最大类型数
+	// MaxSize is the maximum number of types cached
+	MaxSize int
+
+	// Hits 命中次数
+	// Hits is the number of lookups served from the cache
+	Hits uint64
+
+	// Misses 未命中次数，每次未命中都会重新分析结构体
+	// Misses is the number of lookups that analyzed the struct again
+	Misses uint64
+
+	// Evictions 因缓存已满或缩小而淘汰的类型数，不包括ClearStructCache清除的类型
+	// Evictions is the number of types evicted because the cache was full or shrunk, not counting types removed
+	// by ClearStructCache
+	Evictions uint64
+}
+
+// structCacheEntry 缓存的结构体信息及其时钟淘汰所用的访问位
+// structCacheEntry is cached struct info with the reference bit used by clock eviction
+type structCacheEntry struct {
+	t    reflect.Type
+	info *structInfo
+
+	// referenced 自时钟指针上次经过以来是否被访问过
+	// referenced reports whether the entry was used since the clock hand last passed it
+	referenced atomic.Bool
+
+	// hits 该类型的命中次数，按类型计数使不同类型的解码不会争用同一个计数器
+	// hits is the number of hits of the type, counted per type so decoding different types does not contend on
+	// one counter
+	hits atomic.Uint64
+}
+
+// structInfoCache 按时钟（二次机会）算法淘汰的结构体信息缓存
+// structInfoCache is a struct info cache with clock (second chance) eviction
+//
+// 命中只需读锁，并且只在访问位未设置时写入条目，因此解码的热路径既不竞争写锁也不写共享的计数器；
+// 淘汰时时钟指针跳过并清除被访问过的条目，淘汰第一个未被访问的条目，不需要扫描整个缓存
+// A hit only takes the read lock and writes the entry only when its reference bit is not set yet, so the hot
+// decoding path neither contends for the write lock nor writes a shared counter; on eviction the clock hand
+// skips and clears referenced entries and evicts the first unreferenced one, without scanning the whole cache
+type structInfoCache struct {
+	mu      sync.RWMutex
+	entries map[reflect.Type]*structCacheEntry
+	maxSize int
+
+	// ring 时钟环，hand是时钟指针在ring中的位置
+	// ring is the clock ring, hand is the position of the clock hand in ring
+	ring []*structCacheEntry
+	hand int
+
+	// retiredHits 已移出缓存的条目的命中次数，持有写锁时更新
+	// retiredHits is the number of hits of entries no longer cached, updated under the write lock
+	retiredHits uint64
+
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// globalStructCache 全局结构体信息缓存
+// globalStructCache is the global struct info cache
+var globalStructCache = &structInfoCache{
+	entries: make(map[reflect.Type]*structCacheEntry),
+	maxSize: StructCacheSize,
+}
+
+// get 返回缓存的结构体信息，不存在时返回nil
+// get returns the cached struct info, nil when absent
+func (c *structInfoCache) get(t reflect.Type) *structInfo {
+	c.mu.RLock()
+	entry, ok := c.entries[t]
+	c.mu.RUnlock()
+	if !ok {
+		c.misses.Add(1)
+		return nil
+	}
+	if !entry.referenced.Load() {
+		entry.referenced.Store(true)
+	}
+	entry.hits.Add(1)
+	return entry.info
+}
+
+// add 缓存结构体信息，已被其它协程缓存时返回已有的信息；缓存已满时按时钟算法淘汰一个类型
+// add caches struct info, returning the existing info when another goroutine cached it first; a type is
+// evicted by the clock algorithm when the cache is full
+func (c *structInfoCache) add(t reflect.Type, info *structInfo) *structInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[t]; ok {
+		return entry.info
+	}
+	c.evict(c.maxSize - 1)
+	entry := &structCacheEntry{t: t, info: info}
+	c.entries[t] = entry
+	c.ring = append(c.ring, entry)
+	return info
+}
+
+// evict 按时钟算法淘汰类型，直到缓存不超过size个类型，调用方需持有写锁
+// evict removes types by the clock algorithm until at most size remain, the caller must hold the write lock
+func (c *structInfoCache) evict(size int) {
+	for len(c.ring) > size && len(c.ring) > 0 {
+		if c.hand >= len(c.ring) {
+			c.hand = 0
+		}
+		entry := c.ring[c.hand]
+		if entry.referenced.Swap(false) {
+			c.hand++
+			continue
+		}
+
+		last := len(c.ring) - 1
+		c.ring[c.hand] = c.ring[last]
+		c.ring[last] = nil
+		c.ring = c.ring[:last]
+		delete(c.entries, entry.t)
+		c.retiredHits += entry.hits.Load()
+		c.evictions.Add(1)
+	}
+}
+
+// ClearStructCache 清空全局结构体信息缓存，之后的解码会重新分析结构体；统计计数不会被重置
+// ClearStructCache empties the global struct info cache, later decoding analyzes structs again; the statistics
+// counters are not reset
+func ClearStructCache() {
+	c := globalStructCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.ring {
+		c.retiredHits += entry.hits.Load()
+	}
+	c.entries = make(map[reflect.Type]*structCacheEntry)
+	c.ring = nil
+	c.hand = 0
+}
+
+// SetStructCacheSize 设置全局结构体信息缓存的最大类型数，n<=0时恢复StructCacheSize；缩小时立即淘汰多余的类型
+// SetStructCacheSize sets the maximum number of types in the global struct info cache, n <= 0 restores
+// StructCacheSize; shrinking evicts types immediately
+//
+// 示例 Example:
+//
+//	// 解码大量生成类型的长期运行进程
+//	// A long-running process decoding many generated types
+//	xyJson.SetStructCacheSize(10000)
+func SetStructCacheSize(n int) {
+	if n <= 0 {
+		n = StructCacheSize
+	}
+	globalStructCache.mu.Lock()
+	defer globalStructCache.mu.Unlock()
+	globalStructCache.maxSize = n
+	globalStructCache.evict(n)
+}
+
+// GetStructCacheStats 返回全局结构体信息缓存的统计信息
+// GetStructCacheStats returns the statistics of the global struct info cache
+func GetStructCacheStats() StructCacheStats {
+	c := globalStructCache
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hits := c.retiredHits
+	for _, entry := range c.ring {
+		hits += entry.hits.Load()
+	}
+	return StructCacheStats{
+		Size:      len(c.entries),
+		MaxSize:   c.maxSize,
+		Hits:      hits,
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
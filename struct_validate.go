@@ -0,0 +1,522 @@
+package xyJson
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError 解码后validate标签检查发现的所有字段错误
+// ValidationError holds every field error found by the validate tag checks after decoding
+type ValidationError struct {
+	// Fields 按字段声明顺序排列的错误，map按键排序遍历
+	// Fields are the errors in field declaration order, maps are visited in key order
+	Fields []FieldError
+}
+
+// Error 实现error接口
+// Error implements the error interface
+func (ve *ValidationError) Error() string {
+	if len(ve.Fields) == 1 {
+		return ve.Fields[0].Error()
+	}
+	return fmt.Sprintf("%d fields failed validation, first: %s", len(ve.Fields), ve.Fields[0].Error())
+}
+
+// Unwrap 返回所有字段错误，便于errors.Is和errors.As
+// Unwrap returns every field error for errors.Is and errors.As
+func (ve *ValidationError) Unwrap() []error {
+	errs := make([]error, len(ve.Fields))
+	for i, field := range ve.Fields {
+		errs[i] = field.Err
+	}
+	return errs
+}
+
+// ValidateStruct 按validate标签检查target中的所有字段，返回*ValidationError汇总的字段错误
+// ValidateStruct checks every field of target against its validate tag, returning the field errors aggregated
+// in a *ValidationError
+//
+// Unmarshal在解码成功后自动调用它，SerializeToStruct和旧的解码函数不检查标签，需要时在其后调用ValidateStruct。
+// 支持的规则以逗号分隔：
+// required（非零值）、min=N和max=N（数字比较值，字符串按字符数、切片和map按长度比较）、len=N、
+// oneof=a b c（以空格分隔的候选值）和regexp=PATTERN（仅字符串）。regexp必须是最后一条规则，
+// 之后的内容都属于表达式，因此表达式中可以包含逗号。nil指针只检查required。
+// 嵌套的结构体、切片、数组和map中的结构体会被递归检查，路径使用JSON字段名。其它规则名（如go-playground/validator的
+// email）被忽略，因此已有的标签不会导致错误；支持的规则参数无效时返回ErrInvalidOperation错误
+// Unmarshal calls it automatically after a successful decode, SerializeToStruct and the legacy decoding functions
+// do not check tags, call ValidateStruct after them when needed. Supported rules are
+// comma-separated: required (non-zero), min=N and max=N (numbers compare the value, strings their character
+// count, slices and maps their length), len=N, oneof=a b c (space-separated candidates) and regexp=PATTERN
+// (strings only). regexp must be the last rule, everything after it belongs to the pattern, so patterns may
+// contain commas. A nil pointer is only checked for required. Structs nested in structs, slices, arrays and
+// maps are checked recursively, with paths using the JSON field names. Other rule names (such as email of
+// go-playground/validator) are ignored, so existing tags cause no errors; invalid arguments of supported rules
+// return an ErrInvalidOperation error
+//
+// 示例 Example:
+//
+//	type Signup struct {
+//		Name  string `json:"name" validate:"required,max=32"`
+//		Age   int    `json:"age" validate:"min=13,max=120"`
+//		Email string `json:"email" validate:"regexp=^[^@]+@[^@]+$"`
+//	}
+//
+//	var signup Signup
+//	err := xyJson.UnmarshalString(body, &signup)
+//	var validationErr *xyJson.ValidationError
+//	if errors.As(err, &validationErr) {
+//		for _, field := range validationErr.Fields {
+//			fmt.Println(field.Path, field.Err.Message) // $.age must be at least 13
+//		}
+//	}
+func ValidateStruct(target interface{}) error {
+	if target == nil {
+		return NewNullPointerError("target cannot be nil")
+	}
+	return validateValue(reflect.ValueOf(target))
+}
+
+// validateValue 检查值中的所有validate标签，没有标签的类型直接返回
+// validateValue checks every validate tag in the value, types without tags return immediately
+func validateValue(rv reflect.Value) error {
+	if !rv.IsValid() || !typeNeedsValidation(rv.Type()) {
+		return nil
+	}
+	v := &structValidator{}
+	v.walk(rv, "$", 0)
+	if v.err != nil {
+		return v.err
+	}
+	if len(v.fields) > 0 {
+		return &ValidationError{Fields: v.fields}
+	}
+	return nil
+}
+
+// validateRuleKind validate标签中的规则类型
+// validateRuleKind is the kind of a rule in a validate tag
+type validateRuleKind int
+
+const (
+	ruleRequired validateRuleKind = iota
+	ruleMin
+	ruleMax
+	ruleLen
+	ruleOneOf
+	ruleRegexp
+)
+
+// validateRule 解析后的单条规则
+// validateRule is a single parsed rule
+type validateRule struct {
+	kind    validateRuleKind
+	bound   float64
+	text    string
+	options []string
+	pattern *regexp.Regexp
+}
+
+// validatedField 需要检查的结构体字段
+// validatedField is a struct field that needs checking
+type validatedField struct {
+	index  int
+	name   string
+	rules  []validateRule
+	nested bool
+}
+
+// validationPlan 结构体类型的检查计划
+// validationPlan is the checking plan of a struct type
+type validationPlan struct {
+	fields []validatedField
+	err    error
+}
+
+var (
+	// validationPlanCache 缓存结构体类型的检查计划
+	// validationPlanCache caches the checking plans of struct types
+	validationPlanCache = make(map[reflect.Type]*validationPlan)
+
+	// validationNeedCache 缓存类型中是否能到达validate标签
+	// validationNeedCache caches whether a validate tag is reachable from a type
+	validationNeedCache = make(map[reflect.Type]bool)
+	validationPlanMutex sync.RWMutex
+)
+
+// getValidationPlan 返回结构体类型的检查计划
+// getValidationPlan returns the checking plan of a struct type
+func getValidationPlan(t reflect.Type) *validationPlan {
+	validationPlanMutex.RLock()
+	plan, exists := validationPlanCache[t]
+	validationPlanMutex.RUnlock()
+	if exists {
+		return plan
+	}
+
+	plan = buildValidationPlan(t)
+
+	validationPlanMutex.Lock()
+	defer validationPlanMutex.Unlock()
+	if len(validationPlanCache) < StructCacheSize {
+		validationPlanCache[t] = plan
+	}
+	return plan
+}
+
+// buildValidationPlan 解析结构体字段的validate标签，并标记需要递归检查的字段
+// buildValidationPlan parses the validate tags of the struct fields and marks the fields that need recursive
+// checking
+func buildValidationPlan(t reflect.Type) *validationPlan {
+	plan := &validationPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := parseJSONTag(field.Tag.Get("json"))
+		if tag.Skip {
+			continue
+		}
+
+		name := field.Name
+		if tag.Name != "" {
+			name = tag.Name
+		}
+		rules, err := parseValidateTag(field.Tag.Get("validate"), field.Type)
+		if err != nil {
+			plan.err = NewInvalidOperationError("validate", fmt.Sprintf("field %s.%s: %s", t, field.Name, err.Error()))
+			return plan
+		}
+		nested := typeNeedsValidation(field.Type)
+		if len(rules) > 0 || nested {
+			plan.fields = append(plan.fields, validatedField{index: i, name: name, rules: rules, nested: nested})
+		}
+	}
+	return plan
+}
+
+// typeNeedsValidation 检查类型中是否能到达任何validate标签
+// typeNeedsValidation checks whether any validate tag is reachable from the type
+func typeNeedsValidation(t reflect.Type) bool {
+	validationPlanMutex.RLock()
+	needs, exists := validationNeedCache[t]
+	validationPlanMutex.RUnlock()
+	if exists {
+		return needs
+	}
+
+	needs = reachesValidateTag(t, make(map[reflect.Type]bool))
+
+	validationPlanMutex.Lock()
+	defer validationPlanMutex.Unlock()
+	if len(validationNeedCache) < StructCacheSize {
+		validationNeedCache[t] = needs
+	}
+	return needs
+}
+
+// reachesValidateTag 递归检查类型，visiting用于处理递归类型
+// reachesValidateTag checks a type recursively, visiting handles recursive types
+func reachesValidateTag(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return reachesValidateTag(t.Elem(), visiting)
+	case reflect.Struct:
+		if t == timeType || visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || parseJSONTag(field.Tag.Get("json")).Skip {
+				continue
+			}
+			if field.Tag.Get("validate") != "" || reachesValidateTag(field.Type, visiting) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// parseValidateTag 解析validate标签，regexp之后的内容都属于表达式
+// parseValidateTag parses a validate tag, everything after regexp belongs to the pattern
+func parseValidateTag(tag string, t reflect.Type) ([]validateRule, error) {
+	var rules []validateRule
+	rest := tag
+	for rest != "" {
+		if pattern, ok := strings.CutPrefix(rest, "regexp="); ok {
+			rule, err := newValidateRule("regexp", pattern, t)
+			if err != nil {
+				return nil, err
+			}
+			return append(rules, rule), nil
+		}
+
+		var item string
+		item, rest, _ = strings.Cut(rest, ",")
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(item, "=")
+		if !isValidateRuleName(name) {
+			// 跳过其它校验库的规则 Skip rules of other validation libraries
+			continue
+		}
+		rule, err := newValidateRule(name, arg, t)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// isValidateRuleName 检查规则名是否为本包支持的规则
+// isValidateRuleName checks whether the rule name is one supported by this package
+func isValidateRuleName(name string) bool {
+	switch name {
+	case "required", "min", "max", "len", "oneof", "regexp":
+		return true
+	default:
+		return false
+	}
+}
+
+// newValidateRule 创建单条规则并检查它是否适用于字段类型
+// newValidateRule creates a single rule and checks that it applies to the field type
+func newValidateRule(name, arg string, t reflect.Type) (validateRule, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch name {
+	case "required":
+		return validateRule{kind: ruleRequired}, nil
+
+	case "min", "max", "len":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return validateRule{}, fmt.Errorf("invalid %s bound %q", name, arg)
+		}
+		kind := ruleLen
+		switch name {
+		case "min":
+			kind = ruleMin
+		case "max":
+			kind = ruleMax
+		}
+		if !hasLength(t.Kind()) && (kind == ruleLen || !isNumberKind(t.Kind())) {
+			return validateRule{}, fmt.Errorf("%s does not apply to %s", name, t)
+		}
+		return validateRule{kind: kind, bound: bound, text: arg}, nil
+
+	case "oneof":
+		if t.Kind() != reflect.String && !isNumberKind(t.Kind()) {
+			return validateRule{}, fmt.Errorf("oneof does not apply to %s", t)
+		}
+		return validateRule{kind: ruleOneOf, options: strings.Fields(arg)}, nil
+
+	case "regexp":
+		if t.Kind() != reflect.String {
+			return validateRule{}, fmt.Errorf("regexp does not apply to %s", t)
+		}
+		pattern, err := regexp.Compile(arg)
+		if err != nil {
+			return validateRule{}, fmt.Errorf("invalid regexp %q: %v", arg, err)
+		}
+		return validateRule{kind: ruleRegexp, pattern: pattern, text: arg}, nil
+
+	default:
+		return validateRule{}, fmt.Errorf("unknown rule %q", name)
+	}
+}
+
+// isNumberKind 检查是否为数字类型
+// isNumberKind checks whether the kind is numeric
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasLength 检查类型是否按长度比较
+// hasLength checks whether the kind compares by length
+func hasLength(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// structValidator 遍历Go值并收集validate规则的字段错误
+// structValidator walks a Go value and collects the field errors of validate rules
+type structValidator struct {
+	fields []FieldError
+	err    error
+}
+
+// fail 记录字段错误
+// fail records a field error
+func (v *structValidator) fail(path string, t reflect.Type, message string) {
+	v.fields = append(v.fields, FieldError{
+		Path:   path,
+		GoType: t.String(),
+		Err:    NewJSONError(ErrValidation, message, nil).WithPath(path),
+	})
+}
+
+// walk 递归检查rv中的结构体
+// walk checks the structs in rv recursively
+func (v *structValidator) walk(rv reflect.Value, path string, depth int) {
+	if v.err != nil {
+		return
+	}
+	if depth > MaxStructDepth {
+		v.err = NewMaxDepthExceededError(MaxStructDepth)
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			v.walk(rv.Elem(), path, depth)
+		}
+
+	case reflect.Struct:
+		plan := getValidationPlan(rv.Type())
+		if plan.err != nil {
+			v.err = plan.err
+			return
+		}
+		for _, field := range plan.fields {
+			fv := rv.Field(field.index)
+			fieldPath := appendPathKey(path, field.name)
+			v.check(fv, field.rules, fieldPath)
+			if field.nested {
+				v.walk(fv, fieldPath, depth+1)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if !typeNeedsValidation(rv.Type().Elem()) {
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			v.walk(rv.Index(i), fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || !typeNeedsValidation(rv.Type().Elem()) {
+			return
+		}
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			v.walk(rv.MapIndex(key), appendPathKey(path, key.String()), depth+1)
+		}
+	}
+}
+
+// check 对字段值应用规则，nil指针只检查required
+// check applies the rules to a field value, a nil pointer is only checked for required
+func (v *structValidator) check(fv reflect.Value, rules []validateRule, path string) {
+	t := fv.Type()
+	for _, rule := range rules {
+		if rule.kind == ruleRequired {
+			if fv.IsZero() {
+				v.fail(path, t, "is required")
+			}
+			continue
+		}
+
+		value := fv
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				break
+			}
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if message := rule.violation(value); message != "" {
+			v.fail(path, t, message)
+		}
+	}
+}
+
+// violation 返回值违反规则时的消息，满足规则时返回空字符串
+// violation returns the message when the value breaks the rule, or an empty string when it holds
+func (r *validateRule) violation(value reflect.Value) string {
+	switch r.kind {
+	case ruleMin, ruleMax, ruleLen:
+		if isNumberKind(value.Kind()) {
+			n := numberOf(value)
+			if r.kind == ruleMin && n < r.bound {
+				return "must be at least " + r.text
+			}
+			if r.kind == ruleMax && n > r.bound {
+				return "must be at most " + r.text
+			}
+			return ""
+		}
+		n := float64(value.Len())
+		if value.Kind() == reflect.String {
+			n = float64(utf8.RuneCountInString(value.String()))
+		}
+		switch {
+		case r.kind == ruleMin && n < r.bound:
+			return "length must be at least " + r.text
+		case r.kind == ruleMax && n > r.bound:
+			return "length must be at most " + r.text
+		case r.kind == ruleLen && n != r.bound:
+			return "length must be " + r.text
+		}
+
+	case ruleOneOf:
+		s := fmt.Sprint(value.Interface())
+		for _, option := range r.options {
+			if s == option {
+				return ""
+			}
+		}
+		return "must be one of [" + strings.Join(r.options, " ") + "]"
+
+	case ruleRegexp:
+		if !r.pattern.MatchString(value.String()) {
+			return "must match " + r.text
+		}
+	}
+	return ""
+}
+
+// numberOf 将数字值转换为float64
+// numberOf converts a numeric value to float64
+func numberOf(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -466,3 +467,76 @@ func BenchmarkGetBatchVsIndividual(b *testing.B) {
 		}
 	})
 }
+
+// TestGetBatchWithOptions 测试批量获取的错误汇总
+// TestGetBatchWithOptions tests error aggregation of batch gets
+func TestGetBatchWithOptions(t *testing.T) {
+	root := xyJson.MustParseString(`{"user":{"name":"Alice"},"tags":["a"]}`)
+	paths := []string{"$.user.name", "$.missing", "$.tags[0]", "$.tags[5]"}
+
+	t.Run("汇总所有错误 - Aggregate every error", func(t *testing.T) {
+		results, err := xyJson.GetBatchWithOptions(root, paths, nil)
+		require.Len(t, results, 4)
+		assert.Equal(t, "a", results[2].Value.AsString())
+
+		var multiErr *xyJson.MultiError
+		require.True(t, errors.As(err, &multiErr))
+		failed := multiErr.Failed()
+		require.Len(t, failed, 2)
+		assert.Equal(t, "$.missing", failed[0].Path)
+		assert.Equal(t, "$.tags[5]", failed[1].Path)
+		assert.Len(t, multiErr.Unwrap(), 2)
+		assert.Contains(t, err.Error(), "2 of 4")
+
+		var jsonErr *xyJson.JSONError
+		assert.True(t, errors.As(err, &jsonErr))
+	})
+
+	t.Run("遇错即停 - Stop on first error", func(t *testing.T) {
+		results, err := xyJson.GetBatchWithOptions(root, paths, &xyJson.BatchOptions{StopOnError: true})
+		require.Len(t, results, 2)
+		assert.Error(t, results[1].Error)
+
+		var multiErr *xyJson.MultiError
+		require.True(t, errors.As(err, &multiErr))
+		assert.Len(t, multiErr.Failed(), 1)
+		assert.Contains(t, err.Error(), "$.missing")
+	})
+
+	t.Run("全部成功 - All succeed", func(t *testing.T) {
+		results, err := xyJson.GetBatchWithOptions(root, []string{"$.user.name"}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+}
+
+// TestSetBatchWithOptions 测试批量设置的错误汇总和遇错即停
+// TestSetBatchWithOptions tests error aggregation and stopping on error for batch sets
+func TestSetBatchWithOptions(t *testing.T) {
+	operations := []xyJson.BatchSetOperation{
+		{Path: "$.a", Value: 1},
+		{Path: "$.name.inner", Value: 2},
+		{Path: "$.b", Value: 3},
+	}
+
+	root := xyJson.MustParseString(`{"name":"text"}`)
+	results, err := xyJson.SetBatchWithOptions(root, operations, nil)
+	require.Len(t, results, 3)
+	var multiErr *xyJson.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr.Failed(), 1)
+	assert.Equal(t, "$.name.inner", multiErr.Failed()[0].Path)
+	assert.Nil(t, multiErr.Failed()[0].Value)
+	assert.Equal(t, 3, xyJson.MustGetInt(root, "$.b"))
+
+	root = xyJson.MustParseString(`{"name":"text"}`)
+	results, err = xyJson.SetBatchWithOptions(root, operations, &xyJson.BatchOptions{StopOnError: true})
+	assert.Error(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 1, xyJson.MustGetInt(root, "$.a"))
+	assert.False(t, root.AsObject().Has("b"))
+
+	results, err = xyJson.SetBatchWithOptions(xyJson.CreateObject(), operations[:1], nil)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
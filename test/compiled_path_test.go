@@ -1,12 +1,16 @@
 package test
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
 	"testing"
 
-	xyJson "github.com/ihuem/xyJson"
-
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	xyJson "github.com/ihuem/xyJson"
 )
 
 // TestCompilePath 测试路径预编译功能
@@ -411,3 +415,174 @@ func BenchmarkPathCachePerformance(b *testing.B) {
 		}
 	})
 }
+
+// mapPathCache 记录调用次数的路径缓存
+// mapPathCache is a path cache counting its calls
+type mapPathCache struct {
+	mu      sync.Mutex
+	paths   map[string]*xyJson.CompiledPath
+	gets    int
+	puts    int
+	cleared int
+}
+
+func (c *mapPathCache) Get(path string) (*xyJson.CompiledPath, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	compiled, ok := c.paths[path]
+	return compiled, ok
+}
+
+func (c *mapPathCache) Put(path string, compiled *xyJson.CompiledPath) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.puts++
+	c.paths[path] = compiled
+}
+
+func (c *mapPathCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.paths)
+}
+
+func (c *mapPathCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cleared++
+	c.paths = map[string]*xyJson.CompiledPath{}
+}
+
+// TestSetPathCache 测试替换全局路径缓存
+// TestSetPathCache tests replacing the global path cache
+func TestSetPathCache(t *testing.T) {
+	_, builtinMax := xyJson.GetPathCacheStats()
+	cache := &mapPathCache{paths: map[string]*xyJson.CompiledPath{}}
+	xyJson.SetPathCache(cache)
+	defer xyJson.SetPathCache(nil)
+	assert.Same(t, cache, xyJson.GetPathCache())
+
+	first, err := xyJson.CompilePath("$.store.book[0].title")
+	require.NoError(t, err)
+	second, err := xyJson.CompilePath("$.store.book[0].title")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 2, cache.gets)
+	assert.Equal(t, 1, cache.puts)
+
+	root := xyJson.MustParseString(`{"store":{"book":[{"title":"Go"}]}}`)
+	assert.Equal(t, "Go", xyJson.MustGetString(root, "$.store.book[0].title"))
+	assert.Equal(t, 1, cache.puts, "Get reuses the cached path")
+
+	_, err = xyJson.CompilePath("$.[invalid")
+	assert.Error(t, err)
+	assert.Equal(t, 1, cache.puts, "failed compilations are not cached")
+
+	size, maxSize := xyJson.GetPathCacheStats()
+	assert.Equal(t, 1, size)
+	assert.Equal(t, 0, maxSize)
+
+	xyJson.ClearPathCache()
+	assert.Equal(t, 1, cache.cleared)
+	assert.Equal(t, 0, cache.Len())
+
+	xyJson.SetPathCache(nil)
+	_, maxSize = xyJson.GetPathCacheStats()
+	assert.Equal(t, builtinMax, maxSize)
+	_, err = xyJson.CompilePath("$.store")
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Len(), "the built-in cache is used again")
+}
+
+// TestCompiledPathText 测试预编译路径的文本编解码
+// TestCompiledPathText tests the text encoding of compiled paths
+func TestCompiledPathText(t *testing.T) {
+	compiled, err := xyJson.CompilePath("$.users[?(@.age == 40)].name")
+	require.NoError(t, err)
+	text, err := compiled.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "$.users[?(@.age == 40)].name", string(text))
+
+	var restored xyJson.CompiledPath
+	require.NoError(t, restored.UnmarshalText(text))
+	root := xyJson.MustParseString(`{"users":[{"name":"a","age":20},{"name":"b","age":40}]}`)
+	names, err := restored.QueryAll(root)
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	assert.Equal(t, "b", names[0].String())
+
+	// 可以作为JSON字段在进程之间传递 Travels between processes as a JSON field
+	data, err := json.Marshal(map[string]*xyJson.CompiledPath{"path": compiled})
+	require.NoError(t, err)
+	assert.Equal(t, `{"path":"$.users[?(@.age == 40)].name"}`, string(data))
+	var decoded map[string]*xyJson.CompiledPath
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, compiled.Path(), decoded["path"].Path())
+
+	assert.Error(t, restored.UnmarshalText([]byte("users")))
+}
+
+// TestCompiledPathExplain 测试查询计划的段、递归/过滤标记和代价等级
+// TestCompiledPathExplain tests the segments, recursive/filter flags and cost class of query plans
+func TestCompiledPathExplain(t *testing.T) {
+	path, err := xyJson.CompilePath("$.store..book[?(@.price < 10)].title")
+	require.NoError(t, err)
+
+	plan := path.Explain()
+	assert.Equal(t, "$.store..book[?(@.price < 10)].title", plan.Path)
+	assert.True(t, plan.Recursive)
+	assert.True(t, plan.Filtered)
+	assert.Equal(t, xyJson.QueryCostSubtree, plan.Cost)
+	require.Len(t, plan.Segments, 4)
+	assert.Equal(t, xyJson.SegmentPlan{Text: ".store", Kind: "property", Cost: xyJson.QueryCostConstant}, plan.Segments[0])
+	assert.Equal(t, xyJson.SegmentPlan{Text: "..book", Kind: "recursive", Cost: xyJson.QueryCostSubtree}, plan.Segments[1])
+	assert.Equal(t, xyJson.SegmentPlan{Text: "[?(@.price < 10)]", Kind: "filter", Cost: xyJson.QueryCostLinear}, plan.Segments[2])
+	assert.Equal(t, "title", strings.TrimPrefix(plan.Segments[3].Text, "."))
+
+	text := plan.String()
+	assert.Contains(t, text, "cost: subtree")
+	assert.Contains(t, text, "recursive descent: yes, filters: yes")
+
+	simple, err := xyJson.CompilePath("$.users[0].'a.b'")
+	require.NoError(t, err)
+	plan = simple.Explain()
+	assert.Equal(t, xyJson.QueryCostConstant, plan.Cost)
+	assert.False(t, plan.Recursive)
+	assert.Equal(t, "[0]", plan.Segments[1].Text)
+	assert.Equal(t, ".'a.b'", plan.Segments[2].Text)
+
+	wildcard, err := xyJson.CompilePath("$.users[*].name")
+	require.NoError(t, err)
+	assert.Equal(t, xyJson.QueryCostLinear, wildcard.Explain().Cost)
+}
+
+// TestCompiledPathWithDebug 测试调试输出每个路径段访问的节点数且不影响原路径
+// TestCompiledPathWithDebug tests that debug output reports nodes visited per segment without affecting the original path
+func TestCompiledPathWithDebug(t *testing.T) {
+	doc := xyJson.MustParseString(`{"users":[{"name":"a","age":30},{"name":"b","age":20},{"name":"c","age":40}]}`)
+	path, err := xyJson.CompilePath("$.users[?(@.age > 25)].name")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	debug := path.WithDebug(&buf)
+	results, err := debug.QueryAll(doc)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	out := buf.String()
+	assert.Contains(t, out, "query $.users[?(@.age > 25)].name: 2 matches")
+	assert.Contains(t, out, "1. .users  inputs=1 visited=1 matched=1")
+	assert.Contains(t, out, "2. [?(@.age > 25)]  inputs=1 visited=3 matched=2")
+	assert.Contains(t, out, "3. .name  inputs=2 visited=2 matched=2")
+
+	buf.Reset()
+	_, err = path.QueryAll(doc)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	recursive, err := xyJson.CompilePath("$..name")
+	require.NoError(t, err)
+	assert.Equal(t, 3, recursive.WithDebug(&buf).Count(doc))
+	assert.Contains(t, buf.String(), "1. ..name  inputs=1 visited=11 matched=3")
+}
@@ -1,9 +1,20 @@
 package test
 
 import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	xyJson "github.com/ihuem/xyJson"
+	"github.com/ihuem/xyJson/test/testutil"
 )
 
 // TestConvenienceGetMethods 测试便利的Get方法
@@ -252,3 +263,640 @@ func TestConvenienceAPIComparison(t *testing.T) {
 		}
 	})
 }
+
+// TestWithDefaults 测试临时替换默认实例并恢复
+// TestWithDefaults tests temporarily replacing the default instances and restoring them
+func TestWithDefaults(t *testing.T) {
+	original := xyJson.CurrentDefaults()
+	root := xyJson.MustParseString(`{"b": 1, "a": [1, 2]}`)
+
+	pretty := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{Indent: "  ", MaxDepth: xyJson.DefaultMaxDepth})
+
+	scoped := original.WithSerializer(pretty)
+	assert.Same(t, original.Factory(), scoped.Factory())
+	assert.NotSame(t, original, scoped)
+
+	restore := xyJson.WithDefaults(scoped)
+	assert.Same(t, pretty, xyJson.GetDefaultSerializer())
+	assert.Contains(t, xyJson.MustSerializeToString(root), "\n")
+
+	restore()
+	assert.Same(t, original, xyJson.CurrentDefaults())
+	assert.NotContains(t, xyJson.MustSerializeToString(root), "\n")
+
+	// 新工厂同时重建解析器和路径查询器
+	// A new factory rebuilds the parser and path query as well
+	factory := xyJson.NewValueFactory()
+	withFactory := original.WithFactory(factory)
+	assert.Same(t, factory, withFactory.Factory())
+	assert.NotSame(t, original.Parser(), withFactory.Parser())
+	assert.NotSame(t, original.PathQuery(), withFactory.PathQuery())
+
+	xyJson.WithDefaults(nil)()
+	assert.Same(t, original, xyJson.CurrentDefaults())
+}
+
+// TestWithDefaultsParserAndPool 测试替换解析器和对象池对包级函数生效
+// TestWithDefaultsParserAndPool tests that replacing the parser and the object pool affects package-level functions
+func TestWithDefaultsParserAndPool(t *testing.T) {
+	original := xyJson.CurrentDefaults()
+	deep := `{"a": {"b": {"c": 1}}}`
+
+	shallow := xyJson.NewParser()
+	shallow.SetMaxDepth(2)
+	restore := xyJson.WithDefaults(original.WithParser(shallow))
+	_, err := xyJson.ParseString(deep)
+	assert.Error(t, err)
+	_, err = xyJson.Parse([]byte(deep))
+	assert.Error(t, err)
+	_, err = xyJson.ParseString(`{"a": 1}`)
+	assert.NoError(t, err)
+	restore()
+	_, err = xyJson.ParseString(deep)
+	assert.NoError(t, err)
+
+	pool := xyJson.NewObjectPool()
+	defer pool.Close()
+	withPool := original.WithPool(pool)
+	assert.Same(t, pool, withPool.Pool())
+	assert.NotSame(t, original.Factory(), withPool.Factory())
+	withPool.Factory().CreateObject()
+	assert.Greater(t, pool.GetStats().TotalAllocated, int64(0))
+
+	restore = xyJson.WithDefaults(original)
+	defer restore()
+	xyJson.SetDefaultPool(pool)
+	assert.Same(t, pool, xyJson.GetDefaultPool())
+	assert.Same(t, pool, xyJson.CurrentDefaults().Pool())
+}
+
+// TestSetDefaultsConcurrent 测试替换默认实例与并发解析和序列化之间没有竞争（配合-race运行）
+// TestSetDefaultsConcurrent tests that replacing defaults does not race with concurrent parsing and serialization (run with -race)
+func TestSetDefaultsConcurrent(t *testing.T) {
+	original := xyJson.CurrentDefaults()
+	t.Cleanup(xyJson.WithDefaults(original))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				root, err := xyJson.ParseString(`{"a": [1, 2, 3]}`)
+				require.NoError(t, err)
+				_, err = xyJson.Serialize(root)
+				require.NoError(t, err)
+				_, err = xyJson.Get(root, "$.a[1]")
+				require.NoError(t, err)
+			}
+		}()
+	}
+
+	for j := 0; j < 50; j++ {
+		xyJson.SetDefaultSerializer(xyJson.NewSerializer())
+		xyJson.SetDefaultFactory(xyJson.NewValueFactory())
+		xyJson.SetDefaultPathQuery(xyJson.NewPathQuery())
+		xyJson.SetDefaultParser(xyJson.NewParser())
+		xyJson.SetDefaultPool(xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{Enabled: true}))
+	}
+	wg.Wait()
+}
+
+// TestEngine 测试引擎使用自己的配置且不影响包级默认实例
+// TestEngine tests that engines use their own configuration without affecting the package-level defaults
+func TestEngine(t *testing.T) {
+	pretty := xyJson.NewEngine(&xyJson.EngineOptions{
+		Serialize:        &xyJson.SerializeOptions{Indent: "  ", SortKeys: true, MaxDepth: xyJson.DefaultMaxDepth},
+		EnableMonitoring: true,
+	})
+	shallow := xyJson.NewEngine(&xyJson.EngineOptions{MaxDepth: 2})
+
+	t.Run("independent_serialization", func(t *testing.T) {
+		root := pretty.MustParseString(`{"b":1,"a":2}`)
+
+		out, err := pretty.SerializeToString(root)
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"a\": 2,\n  \"b\": 1\n}", out)
+
+		out, err = shallow.SerializeToString(root)
+		require.NoError(t, err)
+		assert.NotContains(t, out, "\n")
+
+		out, err = xyJson.SerializeToString(root)
+		require.NoError(t, err)
+		assert.NotContains(t, out, "\n")
+	})
+
+	t.Run("independent_depth", func(t *testing.T) {
+		_, err := shallow.ParseString(`[[[1]]]`)
+		assert.Error(t, err)
+
+		_, err = pretty.ParseString(`[[[1]]]`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("independent_monitors", func(t *testing.T) {
+		pretty.Monitor().Reset()
+		before := xyJson.GetGlobalMonitor().GetStats().ParseCount
+
+		_, err := pretty.ParseString(`{}`)
+		require.NoError(t, err)
+		_, err = pretty.ParseString(`{`)
+		require.Error(t, err)
+
+		stats := pretty.Monitor().GetStats()
+		assert.Equal(t, int64(2), stats.ParseCount)
+		assert.Equal(t, int64(1), stats.ErrorCount)
+		assert.Equal(t, before, xyJson.GetGlobalMonitor().GetStats().ParseCount)
+		assert.False(t, shallow.Monitor().IsEnabled())
+	})
+
+	t.Run("path_operations", func(t *testing.T) {
+		root := pretty.CreateObject()
+		require.NoError(t, pretty.Set(root, "$.user.name", "alice"))
+		assert.True(t, pretty.Exists(root, "$.user.name"))
+
+		name, err := pretty.Get(root, "$.user.name")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", name.String())
+
+		require.NoError(t, pretty.Delete(root, "$.user.name"))
+		assert.Equal(t, 0, pretty.Count(root, "$.user.name"))
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var target struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}
+		require.NoError(t, pretty.Unmarshal([]byte(`{"name":"bob","age":3}`), &target))
+		assert.Equal(t, "bob", target.Name)
+		assert.Equal(t, 3, target.Age)
+	})
+
+	t.Run("with_defaults", func(t *testing.T) {
+		restore := xyJson.WithDefaults(pretty.Defaults())
+		out, err := xyJson.SerializeToString(xyJson.MustParseString(`{"b":1,"a":2}`))
+		restore()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out, "{\n  \"a\""))
+	})
+
+	t.Run("strict_array_index", func(t *testing.T) {
+		strict := xyJson.NewEngine(&xyJson.EngineOptions{StrictArrayIndex: true})
+		defer strict.Close()
+
+		// 引擎的选项不受全局配置影响 The engine option is independent of the global config
+		root := strict.MustParseString(`{"a":[1]}`)
+		assert.Error(t, strict.Set(root, "$.a[3]", 4))
+		require.NoError(t, strict.Set(root, "$.a[1]", 2))
+
+		root = shallow.MustParseString(`{"a":[1]}`)
+		require.NoError(t, shallow.Set(root, "$.a[3]", 4))
+		assert.Equal(t, 4, shallow.Count(root, "$.a[*]"))
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					root, err := shallow.ParseString(`{"a":[1,2]}`)
+					if !assert.NoError(t, err) {
+						return
+					}
+					_, err = shallow.Serialize(root)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// brokenValue 未实现任何方法的IValue，调用任何方法都会panic
+// brokenValue is an IValue implementing no methods, calling any of them panics
+type brokenValue struct {
+	xyJson.IValue
+}
+
+// TestSafeMode 测试安全模式将panic转换为错误
+// TestSafeMode tests that safe mode converts panics into errors
+func TestSafeMode(t *testing.T) {
+	defer xyJson.WithDefaults(xyJson.CurrentDefaults().WithSafeMode(true))()
+	require.True(t, xyJson.IsSafeMode())
+	require.True(t, xyJson.CurrentDefaults().SafeMode())
+
+	broken := brokenValue{}
+	calls := map[string]func() error{
+		"Serialize": func() error {
+			_, err := xyJson.Serialize(broken)
+			return err
+		},
+		"Set": func() error {
+			return xyJson.Set(broken, "$.a", 1)
+		},
+		"ToString": func() error {
+			_, err := xyJson.ToString(broken)
+			return err
+		},
+		"GetString": func() error {
+			_, err := xyJson.GetString(xyJson.MustParseString(`{"a":1}`), "$.a")
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			assert.NotPanics(t, func() { err = call() })
+			if name == "GetString" {
+				// 没有panic时行为不变
+				// Behaviour is unchanged when nothing panics
+				assert.NoError(t, err)
+				return
+			}
+
+			var jsonErr *xyJson.JSONError
+			require.True(t, errors.As(err, &jsonErr), "%v", err)
+			assert.Equal(t, xyJson.ErrPanic, jsonErr.Code)
+			assert.Contains(t, err.Error(), "PANIC")
+		})
+	}
+
+	_, err := xyJson.ParseString(`{"a":`)
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr))
+	assert.Equal(t, xyJson.ErrInvalidJSON, jsonErr.Code)
+}
+
+// TestSafeModeDisabled 测试默认情况下panic照常传播
+// TestSafeModeDisabled tests that panics propagate by default
+func TestSafeModeDisabled(t *testing.T) {
+	require.False(t, xyJson.IsSafeMode())
+	assert.Panics(t, func() {
+		xyJson.Serialize(brokenValue{})
+	})
+}
+
+// TestNewPanicError 测试panic错误保留原始错误
+// TestNewPanicError tests that panic errors keep the original error
+func TestNewPanicError(t *testing.T) {
+	cause := errors.New("boom")
+	err := xyJson.NewPanicError("get", cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "[PANIC] recovered panic in get: boom", err.Error())
+	assert.Nil(t, xyJson.NewPanicError("get", "text").Cause)
+}
+
+// TestValueTypeHelpers 测试值类型的名称、解析和分类
+// TestValueTypeHelpers tests the names, parsing and classification of value types
+func TestValueTypeHelpers(t *testing.T) {
+	types := []xyJson.ValueType{
+		xyJson.NullValueType, xyJson.StringValueType, xyJson.NumberValueType,
+		xyJson.BoolValueType, xyJson.ObjectValueType, xyJson.ArrayValueType,
+	}
+	for _, vt := range types {
+		parsed, err := xyJson.ValueTypeFromString(vt.String())
+		require.NoError(t, err)
+		assert.Equal(t, vt, parsed)
+		assert.NotEqual(t, vt.IsContainer(), vt.IsScalar(), vt.String())
+	}
+	assert.True(t, xyJson.ArrayValueType.IsContainer())
+	assert.True(t, xyJson.NullValueType.IsScalar())
+	assert.False(t, xyJson.ValueType(42).IsScalar())
+	assert.False(t, xyJson.ValueType(42).IsContainer())
+
+	vt, err := xyJson.ValueTypeFromString("Bool")
+	require.NoError(t, err)
+	assert.Equal(t, xyJson.BoolValueType, vt)
+	_, err = xyJson.ValueTypeFromString("unknown")
+	assert.Error(t, err)
+
+	assert.Equal(t, xyJson.NumberValueType, xyJson.ValueTypeFromKind(reflect.Uint16))
+	assert.Equal(t, xyJson.ObjectValueType, xyJson.ValueTypeFromKind(reflect.Struct))
+	assert.Equal(t, xyJson.ArrayValueType, xyJson.ValueTypeFromKind(reflect.Slice))
+	assert.Equal(t, xyJson.NullValueType, xyJson.ValueTypeFromKind(reflect.Chan))
+}
+
+// TestSegmentTypeHelpers 测试路径段类型的名称和解析
+// TestSegmentTypeHelpers tests the names and parsing of segment types
+func TestSegmentTypeHelpers(t *testing.T) {
+	names := map[xyJson.SegmentType]string{
+		xyJson.PropertySegmentType: "property",
+		xyJson.IndexSegmentType:    "index",
+		xyJson.FilterSegmentType:   "filter",
+		xyJson.WildcardSegmentType: "wildcard",
+	}
+	for st, name := range names {
+		assert.Equal(t, name, st.String())
+		parsed, err := xyJson.SegmentTypeFromString(name)
+		require.NoError(t, err)
+		assert.Equal(t, st, parsed)
+	}
+	assert.Equal(t, "unknown", xyJson.SegmentType(9).String())
+	_, err := xyJson.SegmentTypeFromString("recursive")
+	assert.Error(t, err)
+}
+
+// TestTypeMismatchUsesKindConversion 测试类型不匹配错误报告Go类型对应的JSON类型
+// TestTypeMismatchUsesKindConversion tests that type mismatch errors report the JSON type matching the Go type
+func TestTypeMismatchUsesKindConversion(t *testing.T) {
+	var target struct{ Items []int }
+	err := xyJson.UnmarshalStringToStruct(`[1]`, &target)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected array but got object")
+}
+
+// TestObjectKeysInOrder 测试键名的规范顺序
+// TestObjectKeysInOrder tests the canonical key order
+func TestObjectKeysInOrder(t *testing.T) {
+	insertions := [][]string{
+		{"é", "b", "B", "a", "_", "10", "9"},
+		{"9", "10", "_", "a", "B", "b", "é"},
+	}
+	for _, keys := range insertions {
+		obj := xyJson.CreateObject()
+		for _, key := range keys {
+			require.NoError(t, obj.Set(key, key))
+		}
+		// 按字节比较 Compared byte-wise
+		assert.Equal(t, []string{"10", "9", "B", "_", "a", "b", "é"}, obj.KeysInOrder())
+		assert.Equal(t, obj.Keys(), obj.KeysInOrder())
+	}
+
+	large := xyJson.CreateObject()
+	for i := 99; i >= 0; i-- {
+		require.NoError(t, large.Set("k"+strconv.Itoa(i), i))
+	}
+	keys := large.KeysInOrder()
+	require.Len(t, keys, 100)
+	assert.Equal(t, "k0", keys[0])
+	assert.Equal(t, "k99", keys[99])
+	assert.Equal(t, keys, large.KeysInOrder(), "the order is stable across calls")
+	assert.Empty(t, xyJson.CreateObject().KeysInOrder())
+}
+
+// TestObjectToOrderedPairs 测试按规范顺序导出键值对
+// TestObjectToOrderedPairs tests exporting the pairs in canonical order
+func TestObjectToOrderedPairs(t *testing.T) {
+	root := xyJson.MustParseString(`{"z":1,"m":{"y":true,"x":null},"a":"first"}`)
+	obj := root.(xyJson.IObject)
+	pairs := obj.ToOrderedPairs()
+	require.Len(t, pairs, 3)
+
+	var parts []string
+	for _, kv := range pairs {
+		parts = append(parts, strconv.Quote(kv.Key)+":"+xyJson.MustSerializeToString(kv.Value))
+	}
+	// 与序列化的顺序相同 The same order as serialization
+	assert.Equal(t, xyJson.MustSerializeToString(root), "{"+strings.Join(parts, ",")+"}")
+	assert.Same(t, obj.Get("m"), pairs[1].Value, "values are not copied")
+
+	// 导出的切片是快照 The exported slice is a snapshot
+	obj.Delete("a")
+	assert.Equal(t, "a", pairs[0].Key)
+	assert.Len(t, obj.ToOrderedPairs(), 2)
+	assert.Empty(t, xyJson.CreateObject().ToOrderedPairs())
+}
+
+// rangeObject 创建包含n个键k0..kn-1的对象，n较大时使用map存储
+// rangeObject creates an object with n keys k0..kn-1, stored in a map when n is large
+func rangeObject(t *testing.T, n int) xyJson.IObject {
+	obj := xyJson.CreateObject()
+	for i := 0; i < n; i++ {
+		require.NoError(t, obj.Set("k"+strconv.Itoa(i), i))
+	}
+	return obj
+}
+
+// TestObjectRangeDeleteDuringRange 测试遍历中删除键
+// TestObjectRangeDeleteDuringRange tests deleting keys during Range
+func TestObjectRangeDeleteDuringRange(t *testing.T) {
+	for _, n := range []int{3, 100} {
+		obj := rangeObject(t, n)
+		var visited []string
+		obj.Range(func(key string, value xyJson.IValue) bool {
+			visited = append(visited, key)
+			// 删除当前键和所有其它键 Delete the current key and every other key
+			for _, k := range obj.Keys() {
+				obj.Delete(k)
+			}
+			return true
+		})
+		assert.Len(t, visited, n, "deleted keys are still visited from the snapshot")
+		assert.True(t, sort.StringsAreSorted(visited))
+		assert.Equal(t, 0, obj.Size())
+	}
+}
+
+// TestObjectRangeMutateDuringRange 测试遍历中添加和替换键
+// TestObjectRangeMutateDuringRange tests adding and replacing keys during Range
+func TestObjectRangeMutateDuringRange(t *testing.T) {
+	obj := rangeObject(t, 4)
+	seen := map[string]string{}
+	obj.Range(func(key string, value xyJson.IValue) bool {
+		seen[key] = value.String()
+		require.NoError(t, obj.Set("added"+key, true))
+		require.NoError(t, obj.Set("k3", "replaced"))
+		return true
+	})
+	assert.Equal(t, map[string]string{"k0": "0", "k1": "1", "k2": "2", "k3": "3"}, seen)
+	assert.Equal(t, 8, obj.Size())
+	assert.Equal(t, "replaced", obj.Get("k3").String())
+
+	count := 0
+	obj.Range(func(string, xyJson.IValue) bool {
+		count++
+		return count < 2
+	})
+	assert.Equal(t, 2, count)
+}
+
+// TestObjectUnsafeRange 测试不复制快照的遍历
+// TestObjectUnsafeRange tests iteration without a snapshot copy
+func TestObjectUnsafeRange(t *testing.T) {
+	for _, n := range []int{3, 100} {
+		obj := rangeObject(t, n)
+		sum := 0
+		obj.UnsafeRange(func(key string, value xyJson.IValue) bool {
+			v, ok := value.AsInt64OK()
+			require.True(t, ok)
+			assert.Equal(t, "k"+strconv.FormatInt(v, 10), key)
+			sum += int(v)
+			return true
+		})
+		assert.Equal(t, n*(n-1)/2, sum)
+
+		count := 0
+		obj.UnsafeRange(func(string, xyJson.IValue) bool {
+			count++
+			return false
+		})
+		assert.Equal(t, 1, count)
+	}
+	xyJson.CreateObject().UnsafeRange(nil)
+}
+
+// TestObjectRangeConcurrentMutation 测试其它协程修改对象时的遍历
+// TestObjectRangeConcurrentMutation tests iteration while other goroutines modify the object
+func TestObjectRangeConcurrentMutation(t *testing.T) {
+	obj := rangeObject(t, 50)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			key := "k" + strconv.Itoa(i%50)
+			if i%2 == 0 {
+				obj.Delete(key)
+			} else {
+				_ = obj.Set(key, i)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			obj.Range(func(key string, value xyJson.IValue) bool {
+				assert.NotNil(t, value, key)
+				return true
+			})
+			obj.UnsafeRange(func(key string, value xyJson.IValue) bool {
+				assert.NotNil(t, value, key)
+				return true
+			})
+		}
+	}()
+	wg.Wait()
+}
+
+// TestDedupeSharesIdenticalSubtrees 测试相同子树被替换为共享引用且内容不变
+// TestDedupeSharesIdenticalSubtrees tests that identical subtrees become shared references without changing content
+func TestDedupeSharesIdenticalSubtrees(t *testing.T) {
+	input := `{"events":[` +
+		`{"meta":{"host":"a","tags":["x","y"]},"v":1},` +
+		`{"meta":{"tags":["x","y"],"host":"a"},"v":2},` +
+		`{"meta":{"host":"b","tags":["x","y"]},"v":1}]}`
+	doc := xyJson.MustParseString(input)
+	before, err := xyJson.SerializeToString(doc)
+	require.NoError(t, err)
+
+	assert.Same(t, doc, xyJson.Dedupe(doc))
+
+	events := doc.AsObject().Get("events").AsArray()
+	meta0 := events.Get(0).AsObject().Get("meta")
+	meta1 := events.Get(1).AsObject().Get("meta")
+	meta2 := events.Get(2).AsObject().Get("meta")
+	assert.Same(t, meta0, meta1)
+	assert.NotSame(t, meta0, meta2)
+	assert.Same(t, meta0.AsObject().Get("tags"), meta2.AsObject().Get("tags"))
+	assert.Same(t, events.Get(0).AsObject().Get("v"), events.Get(2).AsObject().Get("v"))
+
+	after, err := xyJson.SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+// TestDedupeKeepsDistinctValues 测试类型不同的数字和带元数据的值不被合并
+// TestDedupeKeepsDistinctValues tests that numbers of different types and values with metadata are not merged
+func TestDedupeKeepsDistinctValues(t *testing.T) {
+	doc := xyJson.CreateObject()
+	doc.Set("int", xyJson.CreateNumber(int64(1)))
+	doc.Set("float", xyJson.CreateNumber(float64(1)))
+	doc.Set("a", xyJson.MustParseString(`{"k":"v"}`))
+	doc.Set("b", xyJson.MustParseString(`{"k":"v"}`))
+	tagged := xyJson.MustParseString(`{"k":"v"}`)
+	tagged.SetMeta(xyJson.MetaSource, "file.json")
+	doc.Set("c", tagged)
+
+	xyJson.Dedupe(doc)
+
+	assert.NotSame(t, doc.Get("int"), doc.Get("float"))
+	assert.Same(t, doc.Get("a"), doc.Get("b"))
+	assert.Same(t, tagged, doc.Get("c"))
+	assert.Nil(t, xyJson.Dedupe(nil))
+}
+
+// TestValueMeta 测试元数据的读写、克隆传播以及与序列化和比较的隔离
+// TestValueMeta tests metadata access, propagation through Clone and isolation from serialization and comparison
+func TestValueMeta(t *testing.T) {
+	t.Run("set_get_delete", func(t *testing.T) {
+		root := xyJson.MustParseString(`{"a":[1,"x"]}`)
+		assert.Nil(t, root.Meta(xyJson.MetaRequestID))
+		assert.Empty(t, root.MetaKeys())
+
+		root.SetMeta(xyJson.MetaRequestID, "req-1")
+		root.SetMeta(xyJson.MetaSource, "inline")
+		assert.Equal(t, "req-1", root.Meta(xyJson.MetaRequestID))
+		assert.Equal(t, []string{xyJson.MetaRequestID, xyJson.MetaSource}, root.MetaKeys())
+
+		root.SetMeta(xyJson.MetaSource, nil)
+		assert.Nil(t, root.Meta(xyJson.MetaSource))
+		assert.Equal(t, []string{xyJson.MetaRequestID}, root.MetaKeys())
+	})
+
+	t.Run("all_value_types", func(t *testing.T) {
+		for _, value := range []xyJson.IValue{
+			xyJson.CreateObject(), xyJson.CreateArray(), xyJson.CreateString("s"), xyJson.CreateNull(),
+		} {
+			value.SetMeta("k", 1)
+			assert.Equal(t, 1, value.Meta("k"), value.Type().String())
+		}
+	})
+
+	t.Run("clone_propagation", func(t *testing.T) {
+		root := xyJson.MustParseString(`{"items":[{"id":1}]}`)
+		root.SetMeta(xyJson.MetaSource, "a.json")
+		items := xyJson.MustGet(root, "$.items")
+		items.SetMeta("note", "inner")
+
+		clone := root.Clone()
+		assert.Equal(t, "a.json", clone.Meta(xyJson.MetaSource))
+		assert.Equal(t, "inner", xyJson.MustGet(clone, "$.items").Meta("note"))
+
+		clone.SetMeta(xyJson.MetaSource, "b.json")
+		assert.Equal(t, "a.json", root.Meta(xyJson.MetaSource))
+	})
+
+	t.Run("not_serialized_or_compared", func(t *testing.T) {
+		root := xyJson.MustParseString(`{"a":1}`)
+		other := root.Clone()
+		root.SetMeta(xyJson.MetaRequestID, "req-2")
+
+		out, err := xyJson.SerializeToString(root)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, out)
+		assert.True(t, root.Equals(other))
+	})
+
+	t.Run("parse_file", func(t *testing.T) {
+		path := testutil.CreateTempFile(t, `{"name":"file"}`)
+		doc, err := xyJson.ParseFile(path, nil)
+		require.NoError(t, err)
+		defer doc.Close()
+
+		assert.Equal(t, path, doc.Root().Meta(xyJson.MetaSource))
+		_, ok := doc.Root().Meta(xyJson.MetaParseDuration).(time.Duration)
+		assert.True(t, ok)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		root := xyJson.CreateObject()
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					root.SetMeta("k", i)
+					_ = root.Meta("k")
+					_ = root.MetaKeys()
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.NotNil(t, root.Meta("k"))
+	})
+}
@@ -0,0 +1,1172 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	xyJson "github.com/ihuem/xyJson"
+	"github.com/ihuem/xyJson/test/testutil"
+)
+
+// snapshotGet 返回快照中path处的值
+// snapshotGet returns the value at path in a snapshot
+func snapshotGet(t *testing.T, snap *xyJson.DocumentSnapshot, path string) xyJson.IValue {
+	value, err := snap.Get(path)
+	require.NoError(t, err)
+	return value
+}
+
+// TestSnapshot 测试快照不受之后修改的影响
+// TestSnapshot tests that snapshots are unaffected by later modifications
+func TestSnapshot(t *testing.T) {
+	doc := xyJson.MustParseString(`{"limits": {"cpu": 2}, "hosts": ["a", "b"], "name": "svc"}`)
+
+	first, err := doc.Snapshot()
+	require.NoError(t, err)
+
+	require.NoError(t, xyJson.Set(doc, "$.limits.cpu", 4))
+	xyJson.MustGetArray(doc, "$.hosts").Append("c")
+	doc.(xyJson.IObject).Delete("name")
+
+	second, err := doc.Snapshot()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), snapshotGet(t, first, "$.limits.cpu").AsInt64())
+	assert.True(t, first.Exists("$.name"))
+	hosts, err := first.GetAll("$.hosts[*]")
+	require.NoError(t, err)
+	assert.Len(t, hosts, 2)
+
+	assert.Equal(t, int64(4), snapshotGet(t, second, "$.limits.cpu").AsInt64())
+	assert.False(t, second.Exists("$.name"))
+	data, err := second.Serialize()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"limits": {"cpu": 4}, "hosts": ["a", "b", "c"]}`, string(data))
+
+	// 读取的值是拷贝，修改它们不影响快照
+	// Values read are copies, modifying them leaves the snapshot intact
+	snapshotGet(t, first, "$.limits").(xyJson.IObject).Set("cpu", 100)
+	first.Value().(xyJson.IObject).Delete("limits")
+	assert.Equal(t, int64(2), snapshotGet(t, first, "$.limits.cpu").AsInt64())
+
+	scalar, err := xyJson.CreateString("s").Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, "s", snapshotGet(t, scalar, "$").String())
+}
+
+// TestSnapshotParseInto 测试ParseInto原地覆盖标量不影响已有快照
+// TestSnapshotParseInto tests that ParseInto overwriting scalars in place leaves existing snapshots intact
+func TestSnapshotParseInto(t *testing.T) {
+	doc := xyJson.MustParseString(`{"stats": {"requests": 1, "errors": 0}}`)
+	snap, err := doc.Snapshot()
+	require.NoError(t, err)
+
+	parser := xyJson.NewParser()
+	parser.Reset([]byte(`{"stats": {"requests": 7, "errors": 1}}`))
+	_, err = parser.ParseInto(doc)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), snapshotGet(t, snap, "$.stats.requests").AsInt64())
+	next, err := doc.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), snapshotGet(t, next, "$.stats.requests").AsInt64())
+}
+
+// TestSnapshotSharing 测试未修改的文档再次建立快照时不拷贝
+// TestSnapshotSharing tests that snapshotting an unmodified document again copies nothing
+func TestSnapshotSharing(t *testing.T) {
+	doc := xyJson.MustParseString(`{"items": ` + testutil.RepeatArray(`{"id": 1, "tags": ["x"]}`, 500) + `}`)
+	_, err := doc.Snapshot()
+	require.NoError(t, err)
+
+	unmodified := testing.AllocsPerRun(10, func() {
+		_, _ = doc.Snapshot()
+	})
+	require.NoError(t, xyJson.Set(doc, "$.items[3].id", 2))
+	modified := testing.AllocsPerRun(1, func() {
+		require.NoError(t, xyJson.Set(doc, "$.items[3].id", 3))
+		_, _ = doc.Snapshot()
+	})
+
+	// 完整拷贝需要数千次分配；未修改时几乎不分配，修改一个元素只重新拷贝从根到它的路径
+	// A full copy takes thousands of allocations; unmodified hardly anything is allocated, and modifying one
+	// item copies only the path to it
+	assert.Less(t, unmodified, 50.0)
+	assert.Less(t, modified, 100.0)
+}
+
+// TestSnapshotConcurrent 测试与修改并发建立快照
+// TestSnapshotConcurrent tests taking snapshots concurrently with modifications
+func TestSnapshotConcurrent(t *testing.T) {
+	doc := xyJson.MustParseString(`{"counter": 0, "list": []}`)
+	shared, err := xyJson.NewSharedDocument(xyJson.MustParseString(`{"a": 0, "b": 0}`))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			doc.(xyJson.IObject).Set("counter", i)
+			xyJson.MustGetArray(doc, "$.list").Append(i)
+			shared.UpdateKeys([]string{"a", "b"}, func(root xyJson.IObject) error {
+				root.Set("a", i)
+				return root.Set("b", i)
+			})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			_, err := doc.Snapshot()
+			assert.NoError(t, err)
+
+			// 共享文档的快照在顶层键之间是原子的
+			// Shared document snapshots are atomic across top-level keys
+			snap, err := shared.Snapshot()
+			require.NoError(t, err)
+			assert.Equal(t, snapshotGet(t, snap, "$.a").AsInt(), snapshotGet(t, snap, "$.b").AsInt())
+		}
+	}()
+	wg.Wait()
+}
+
+// newSharedTestDocument 创建测试用的共享文档
+// newSharedTestDocument creates the shared document used by the tests
+func newSharedTestDocument(t *testing.T) *xyJson.SharedDocument {
+	doc, err := xyJson.NewSharedDocument(xyJson.MustParseString(`{"users": [], "settings": {"theme": "dark"}, "count": 0}`))
+	require.NoError(t, err)
+	return doc
+}
+
+// TestSharedDocument 测试共享文档的读写
+// TestSharedDocument tests reading and writing a shared document
+func TestSharedDocument(t *testing.T) {
+	doc := newSharedTestDocument(t)
+
+	require.NoError(t, doc.Update("$.users", func(users xyJson.IValue) error {
+		return users.(xyJson.IArray).Append("ann")
+	}))
+	require.NoError(t, doc.Set("$.settings.lang", "en"))
+	require.NoError(t, doc.Delete("$.count"))
+
+	theme, err := doc.Get("$.settings.theme")
+	require.NoError(t, err)
+	assert.Equal(t, "dark", theme.String())
+
+	require.NoError(t, doc.Read("$.users", func(users xyJson.IValue) error {
+		assert.Equal(t, 1, users.(xyJson.IArray).Length())
+		return nil
+	}))
+
+	data, err := doc.Serialize()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"users": ["ann"], "settings": {"theme": "dark", "lang": "en"}}`, string(data))
+
+	assert.Error(t, doc.Read("$.missing", func(xyJson.IValue) error { return nil }))
+	assert.Error(t, doc.Set("users", 1))
+
+	_, err = xyJson.NewSharedDocument(xyJson.MustParseString(`[1]`))
+	assert.Error(t, err)
+	_, err = xyJson.NewSharedDocumentWithOptions(xyJson.CreateObject(), &xyJson.SharedDocumentOptions{Stripes: 0})
+	assert.Error(t, err)
+}
+
+// TestSharedDocumentStripes 测试对一个顶层键的写入不阻塞其他键的读取，整体读取等待写入完成
+// TestSharedDocumentStripes tests that writing one top-level key does not block reads of other keys, while
+// whole-document reads wait for the write
+func TestSharedDocumentStripes(t *testing.T) {
+	// 一个条带时所有键都冲突，这里使用足够多的条带使两个键落在不同条带
+	// With one stripe every key collides, so use enough stripes for the two keys to land on different ones
+	doc, err := xyJson.NewSharedDocumentWithOptions(
+		xyJson.MustParseString(`{"users": [], "settings": {"theme": "dark"}}`),
+		&xyJson.SharedDocumentOptions{Stripes: 1024},
+	)
+	require.NoError(t, err)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	go doc.Update("$.users", func(xyJson.IValue) error {
+		close(entered)
+		<-release
+		return nil
+	})
+	<-entered
+
+	read := make(chan struct{})
+	go func() {
+		doc.Read("$.settings.theme", func(xyJson.IValue) error { return nil })
+		close(read)
+	}()
+	select {
+	case <-read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reading $.settings blocked on a write to $.users")
+	}
+
+	serialized := make(chan struct{})
+	go func() {
+		doc.Serialize()
+		close(serialized)
+	}()
+	select {
+	case <-serialized:
+		t.Fatal("serializing the whole document did not wait for the write")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-serialized
+}
+
+// TestSharedDocumentConcurrent 测试并发读写和多键操作不会死锁
+// TestSharedDocumentConcurrent tests that concurrent reads, writes and multi-key operations do not deadlock
+func TestSharedDocumentConcurrent(t *testing.T) {
+	doc, err := xyJson.NewSharedDocumentWithOptions(xyJson.CreateObject(), &xyJson.SharedDocumentOptions{Stripes: 4})
+	require.NoError(t, err)
+
+	keys := make([]string, 8)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+		require.NoError(t, doc.Set("$."+keys[i], 0))
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				// 以不同顺序请求的键按条带顺序加锁
+				// Keys requested in different orders are locked in stripe order
+				a, b := keys[(g+i)%len(keys)], keys[(g*3+i+1)%len(keys)]
+				assert.NoError(t, doc.UpdateKeys([]string{a, b}, func(root xyJson.IObject) error {
+					root.Set(a, root.Get(a).AsInt()+1)
+					return nil
+				}))
+				assert.NoError(t, doc.Read("$."+b, func(xyJson.IValue) error { return nil }))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	total := 0
+	require.NoError(t, doc.ReadKeys(keys, func(root xyJson.IObject) error {
+		for _, key := range keys {
+			total += root.Get(key).AsInt()
+		}
+		return nil
+	}))
+	assert.Equal(t, 8*200, total)
+}
+
+// BenchmarkSharedDocumentReadersWriters 基准测试共享文档上并发读者与写者的不同比例，
+// 以及写入与读取落在同一顶层键或不同顶层键的情况
+// BenchmarkSharedDocumentReadersWriters benchmarks concurrent readers against writers on a shared document at
+// different write ratios, with writes on the same top-level key as the reads or on other keys
+func BenchmarkSharedDocumentReadersWriters(b *testing.B) {
+	cases := []struct {
+		name       string
+		writeEvery int // 每多少次操作写入一次，0为只读 / One write every this many operations, 0 for read only
+		sameKey    bool
+	}{
+		{"ReadOnly", 0, false},
+		{"Write10Percent/SameKey", 10, true},
+		{"Write10Percent/OtherKeys", 10, false},
+		{"Write50Percent/SameKey", 2, true},
+		{"Write50Percent/OtherKeys", 2, false},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			doc, err := xyJson.NewSharedDocument(xyJson.CreateObject())
+			if err != nil {
+				b.Fatal(err)
+			}
+			keys := make([]string, 16)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("$.k%d", i)
+				if err := doc.Set(keys[i], i); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				n := 0
+				for pb.Next() {
+					n++
+					if tc.writeEvery > 0 && n%tc.writeEvery == 0 {
+						key := keys[0]
+						if !tc.sameKey {
+							key = keys[1+n%(len(keys)-1)]
+						}
+						if err := doc.Set(key, n); err != nil {
+							b.Error(err)
+							return
+						}
+						continue
+					}
+					if _, err := doc.Get(keys[0]); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestJournalRecordsChanges 测试Set、Delete和SetBatch的变更被记录
+// TestJournalRecordsChanges tests that changes through Set, Delete and SetBatch are recorded
+func TestJournalRecordsChanges(t *testing.T) {
+	config := xyJson.MustParseString(`{"limits":{"rps":100},"debug":true}`)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	journal := xyJson.EnableJournalWithOptions(config, &xyJson.JournalOptions{
+		Clock: func() time.Time { return now },
+	})
+	require.NotNil(t, journal)
+	assert.Same(t, journal, xyJson.EnableJournal(config))
+	assert.Same(t, journal, xyJson.JournalOf(config))
+
+	require.NoError(t, xyJson.Set(config, "$.limits.rps", 200))
+	require.NoError(t, xyJson.Delete(config, "$.debug"))
+	results := xyJson.SetBatch(config, []xyJson.BatchSetOperation{
+		{Path: "$.limits.burst", Value: 50},
+	})
+	require.NoError(t, results[0].Error)
+
+	entries := journal.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, xyJson.JournalSet, entries[0].Op)
+	assert.Equal(t, "$.limits.rps", entries[0].Path)
+	assert.Equal(t, 100, entries[0].OldValue.AsInt())
+	assert.Equal(t, 200, entries[0].NewValue.AsInt())
+	assert.Equal(t, now, entries[0].Time)
+
+	assert.Equal(t, xyJson.JournalDelete, entries[1].Op)
+	assert.True(t, entries[1].OldValue.AsBool())
+	assert.Nil(t, entries[1].NewValue)
+
+	assert.Nil(t, entries[2].OldValue)
+	assert.Equal(t, 50, entries[2].NewValue.AsInt())
+
+	data, err := journal.Serialize()
+	require.NoError(t, err)
+	expected := `[` +
+		`{"new":200,"old":100,"op":"set","path":"$.limits.rps","time":"2024-05-01T12:00:00Z"},` +
+		`{"new":null,"old":true,"op":"delete","path":"$.debug","time":"2024-05-01T12:00:00Z"},` +
+		`{"new":50,"old":null,"op":"set","path":"$.limits.burst","time":"2024-05-01T12:00:00Z"}]`
+	assert.True(t, xyJson.MustParseString(expected).Equals(xyJson.MustParse(data)), string(data))
+
+	journal.Clear()
+	assert.Zero(t, journal.Len())
+	require.NoError(t, xyJson.Set(config, "$.limits.rps", 300))
+	assert.Equal(t, 1, journal.Len())
+}
+
+// TestJournalActor 测试从上下文记录操作者
+// TestJournalActor tests recording the actor from the context
+func TestJournalActor(t *testing.T) {
+	config := xyJson.MustParseString(`{"a":1}`)
+	journal := xyJson.EnableJournal(config)
+
+	ctx := xyJson.WithJournalActor(context.Background(), "alice")
+	require.NoError(t, xyJson.SetContext(ctx, config, "$.a", 2))
+	require.NoError(t, xyJson.DeleteContext(ctx, config, "$.a"))
+	require.NoError(t, xyJson.Set(config, "$.b", 3))
+
+	entries := journal.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Equal(t, "alice", entries[1].Actor)
+	assert.Empty(t, entries[2].Actor)
+
+	arr := journal.ToValue()
+	assert.Equal(t, "alice", xyJson.MustGetString(arr, "$[0].actor"))
+	assert.False(t, xyJson.Exists(arr, "$[2].actor"))
+}
+
+// TestJournalEntriesImmutable 测试记录的值不受后续修改影响
+// TestJournalEntriesImmutable tests that recorded values are unaffected by later mutations
+func TestJournalEntriesImmutable(t *testing.T) {
+	config := xyJson.MustParseString(`{"servers":["a"]}`)
+	journal := xyJson.EnableJournal(config)
+
+	require.NoError(t, xyJson.Set(config, "$.servers", []interface{}{"b"}))
+	xyJson.MustGet(config, "$.servers").AsArray().Append(xyJson.CreateString("c"))
+
+	entries := journal.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].OldValue.AsArray().Length())
+	assert.Equal(t, 1, entries[0].NewValue.AsArray().Length())
+}
+
+// TestJournalFailuresAndScope 测试失败的操作、克隆和禁用后不记录
+// TestJournalFailuresAndScope tests that failed operations, clones and disabled journals record nothing
+func TestJournalFailuresAndScope(t *testing.T) {
+	config := xyJson.MustParseString(`{"a":1}`)
+	journal := xyJson.EnableJournal(config)
+
+	assert.Error(t, xyJson.Set(config, "$[", 1))
+	assert.Zero(t, journal.Len())
+
+	clone := config.Clone()
+	assert.Nil(t, xyJson.JournalOf(clone))
+	require.NoError(t, xyJson.Set(clone, "$.a", 2))
+	assert.Zero(t, journal.Len())
+
+	assert.Same(t, journal, xyJson.DisableJournal(config))
+	require.NoError(t, xyJson.Set(config, "$.a", 3))
+	assert.Zero(t, journal.Len())
+	assert.Nil(t, xyJson.JournalOf(config))
+	assert.Nil(t, xyJson.EnableJournal(nil))
+}
+
+// TestJournalMaxEntries 测试超出上限时丢弃最早的记录，并发记录安全
+// TestJournalMaxEntries tests that the oldest entries are dropped beyond the limit and concurrent recording is safe
+func TestJournalMaxEntries(t *testing.T) {
+	config := xyJson.CreateObject()
+	journal := xyJson.EnableJournalWithOptions(config, &xyJson.JournalOptions{MaxEntries: 3})
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, xyJson.Set(config, "$.n", i))
+	}
+	entries := journal.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, 2, entries[0].NewValue.AsInt())
+	assert.Equal(t, 4, entries[2].NewValue.AsInt())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 50; k++ {
+				xyJson.Set(config, "$.n", k)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, journal.Len())
+}
+
+// TestCRDTDocumentEdits 测试本地编辑和物化的文档
+// TestCRDTDocumentEdits tests local edits and the materialized document
+func TestCRDTDocumentEdits(t *testing.T) {
+	doc := xyJson.NewCRDTDocument("a")
+	require.NoError(t, doc.Set("$.title", "list"))
+	require.NoError(t, doc.Set("$.items[+]", "b"))
+	require.NoError(t, doc.Set("$.items[+]", "d"))
+	require.NoError(t, doc.Insert("$.items[0]", "a"))
+	require.NoError(t, doc.Insert("$.items[2]", "c"))
+	require.NoError(t, doc.Set("$.items[-1]", "D"))
+	require.NoError(t, doc.Set("$.meta.tags[1]", "x"))
+	require.NoError(t, doc.Delete("$.title"))
+	assert.Equal(t, `{"items":["a","b","c","D"],"meta":{"tags":[null,"x"]}}`, xyJson.MustSerializeToString(doc.Value()))
+
+	assert.Error(t, doc.Delete("$.title"))
+	assert.Error(t, doc.Delete("$.items[9]"))
+	assert.Error(t, doc.Set("$", 1))
+	assert.Error(t, doc.Set("$.items[*]", 1))
+	assert.Error(t, doc.Set("$.items.name", 1))
+	assert.Error(t, doc.Insert("$.meta.name", 1))
+
+	for i := 0; i < 200; i++ {
+		require.NoError(t, doc.Insert("$.items[1]", i))
+	}
+	assert.Equal(t, 204, xyJson.MustGet(doc.Value(), "$.items").AsArray().Length())
+	assert.Equal(t, 199, xyJson.MustGetInt(doc.Value(), "$.items[1]"))
+}
+
+// TestCRDTDocumentMerge 测试并发编辑以任意顺序合并后收敛
+// TestCRDTDocumentMerge tests that concurrent edits converge when merged in any order
+func TestCRDTDocumentMerge(t *testing.T) {
+	base, err := xyJson.NewCRDTDocumentFrom("server", xyJson.MustParseString(`{"todos":[{"title":"milk"}],"owner":"x"}`))
+	require.NoError(t, err)
+	phone, laptop, tablet := base.Fork("phone"), base.Fork("laptop"), base.Fork("tablet")
+
+	require.NoError(t, phone.Set("$.todos[0].done", true))
+	require.NoError(t, phone.Set("$.owner", "phone"))
+	require.NoError(t, laptop.Set("$.todos[+]", map[string]any{"title": "eggs"}))
+	require.NoError(t, laptop.Set("$.owner", "laptop"))
+	require.NoError(t, tablet.Insert("$.todos[0]", map[string]any{"title": "bread"}))
+	require.NoError(t, tablet.Delete("$.owner"))
+	require.NoError(t, tablet.Set("$.owner", "tablet"))
+
+	orders := [][]*xyJson.CRDTDocument{
+		{phone, laptop, tablet},
+		{tablet, laptop, phone},
+		{laptop, phone, tablet},
+	}
+	var results []string
+	for _, order := range orders {
+		replica := xyJson.NewCRDTDocument("observer")
+		for _, remote := range order {
+			require.NoError(t, replica.Merge(remote))
+			require.NoError(t, replica.Merge(remote), "merging twice has no effect")
+		}
+		results = append(results, xyJson.MustSerializeToString(replica.Value()))
+	}
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, results[0], results[2])
+	// tablet的写入时钟最大 tablet wrote with the highest clock
+	assert.Equal(t, `{"owner":"tablet","todos":[{"title":"bread"},{"done":true,"title":"milk"},{"title":"eggs"}]}`, results[0])
+
+	// 较晚的删除覆盖较早的修改 A later delete wins over an earlier edit
+	require.NoError(t, phone.Merge(laptop))
+	require.NoError(t, laptop.Merge(phone))
+	require.NoError(t, laptop.Set("$.todos[0].title", "oat milk"))
+	require.NoError(t, phone.Delete("$.todos[0]"))
+	require.NoError(t, phone.Delete("$.todos[0]"))
+	require.NoError(t, laptop.Merge(phone))
+	require.NoError(t, phone.Merge(laptop))
+	assert.True(t, phone.Value().Equals(laptop.Value()))
+	assert.Equal(t, 0, xyJson.MustGet(phone.Value(), "$.todos").AsArray().Length())
+}
+
+// TestCRDTDocumentState 测试通过序列化的状态同步
+// TestCRDTDocumentState tests syncing through the serialized state
+func TestCRDTDocumentState(t *testing.T) {
+	phone := xyJson.NewCRDTDocument("phone")
+	laptop := xyJson.NewCRDTDocument("laptop")
+	require.NoError(t, phone.Set("$.a.b[+]", 1))
+	require.NoError(t, laptop.Set("$.c", "x"))
+	require.NoError(t, laptop.Delete("$.c"))
+
+	data, err := phone.MarshalState()
+	require.NoError(t, err)
+	remote, err := xyJson.ParseCRDTState(data)
+	require.NoError(t, err)
+	assert.Equal(t, "phone", remote.Actor())
+	require.NoError(t, laptop.Merge(remote))
+	assert.Equal(t, `{"a":{"b":[1]}}`, xyJson.MustSerializeToString(laptop.Value()))
+
+	// 往返后的状态保留墓碑 The round trip keeps tombstones
+	data, err = laptop.MarshalState()
+	require.NoError(t, err)
+	restored, err := xyJson.ParseCRDTState(data)
+	require.NoError(t, err)
+	assert.True(t, laptop.State().Equals(restored.State()))
+	require.NoError(t, phone.Set("$.c", "stale"))
+	require.NoError(t, restored.Merge(phone))
+	assert.Equal(t, "stale", xyJson.MustGetString(restored.Value(), "$.c"))
+
+	for _, bad := range []string{
+		`[]`,
+		`{"clock":1,"root":{"fields":{}}}`,
+		`{"actor":"a","clock":-1,"root":{"fields":{}}}`,
+		`{"actor":"a","clock":1,"root":{"value":1}}`,
+		`{"actor":"a","clock":1,"root":{"fields":{"k":{"ts":"x"}}}}`,
+		`{"actor":"a","clock":1,"root":{"fields":{"k":{"ts":"1@a","node":{"id":"2@a","elements":{"bad":{"ts":"2@a"}}}}}}}`,
+	} {
+		_, err := xyJson.ParseCRDTState([]byte(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+// TestPatchEncoderFrames 测试将变更转换为精确的JSON Patch
+// TestPatchEncoderFrames tests turning changes into exact JSON Patches
+func TestPatchEncoderFrames(t *testing.T) {
+	doc := xyJson.MustParseString(`{"users":[{"name":"a"},{"name":"b"}],"meta":{"v":1}}`)
+	encoder := xyJson.NewPatchEncoder(doc)
+	assert.Nil(t, encoder.Next())
+
+	require.NoError(t, xyJson.Set(doc, "$.meta.v", 2))
+	require.NoError(t, xyJson.Set(doc, "$.users[+]", map[string]any{"name": "c"}))
+	require.NoError(t, xyJson.Set(doc, "$.users[-1].name", "C"))
+	require.NoError(t, xyJson.Set(doc, "$.deep.er.key", true))
+	require.NoError(t, xyJson.Set(doc, "$['a/b~c']", 1))
+	require.NoError(t, xyJson.Delete(doc, "$.users[0]"))
+	require.NoError(t, xyJson.Set(doc, "$.meta.tags[2]", "x"))
+
+	frame := encoder.Next()
+	require.NotNil(t, frame)
+	first := frame
+	assert.False(t, frame.IsSnapshot())
+	assert.Equal(t, uint64(0), frame.Base)
+	assert.Equal(t, uint64(7), frame.Seq)
+
+	data, err := xyJson.Serialize(xyJson.PatchToValue(frame.Patch))
+	require.NoError(t, err)
+	assert.Equal(t, `[`+
+		`{"op":"replace","path":"/meta/v","value":2},`+
+		`{"op":"add","path":"/users/2","value":{"name":"c"}},`+
+		`{"op":"replace","path":"/users/2/name","value":"C"},`+
+		`{"op":"add","path":"/deep","value":{"er":{"key":true}}},`+
+		`{"op":"add","path":"/a~1b~0c","value":1},`+
+		`{"op":"remove","path":"/users/0"},`+
+		`{"op":"add","path":"/meta/tags","value":[null,null,"x"]}]`, string(data))
+	assert.Nil(t, encoder.Next())
+
+	// 数组填充时替换整个数组
+	// Padding an array replaces the whole array
+	require.NoError(t, xyJson.Set(doc, "$.meta.tags[4]", "y"))
+	frame = encoder.Next()
+	require.Len(t, frame.Patch, 1)
+	assert.Equal(t, "replace", frame.Patch[0].Op)
+	assert.Equal(t, "/meta/tags", frame.Patch[0].Path)
+	assert.Equal(t, uint64(7), frame.Base)
+
+	// 从初始快照开始应用所有帧得到相同的文档
+	// Applying every frame from the initial snapshot yields the same document
+	receiver := xyJson.NewPatchReceiver()
+	require.NoError(t, receiver.Apply(&xyJson.PatchFrame{Snapshot: xyJson.MustParseString(`{"users":[{"name":"a"},{"name":"b"}],"meta":{"v":1}}`)}))
+	require.NoError(t, receiver.Apply(first))
+	require.NoError(t, receiver.Apply(frame))
+	assert.True(t, doc.Equals(receiver.Document()), receiver.Document().String())
+	assert.True(t, doc.Equals(encoder.Snapshot().Snapshot))
+}
+
+// TestPatchEncoderResync 测试日志丢弃记录后改为发送快照
+// TestPatchEncoderResync tests sending a snapshot after the journal dropped entries
+func TestPatchEncoderResync(t *testing.T) {
+	doc := xyJson.MustParseString(`{"n":0}`)
+	xyJson.EnableJournalWithOptions(doc, &xyJson.JournalOptions{MaxEntries: 2})
+	encoder := xyJson.NewPatchEncoder(doc)
+
+	require.NoError(t, xyJson.Set(doc, "$.n", 1))
+	frame := encoder.Next()
+	require.NotNil(t, frame)
+	assert.False(t, frame.IsSnapshot())
+
+	for i := 2; i <= 5; i++ {
+		require.NoError(t, xyJson.Set(doc, "$.n", i))
+	}
+	frame = encoder.Next()
+	require.True(t, frame.IsSnapshot())
+	assert.Equal(t, uint64(5), frame.Seq)
+	assert.Equal(t, 5, xyJson.MustGetInt(frame.Snapshot, "$.n"))
+
+	xyJson.JournalOf(doc).Clear()
+	require.NoError(t, xyJson.Set(doc, "$.n", 6))
+	frame = encoder.Next()
+	assert.False(t, frame.IsSnapshot())
+	assert.Equal(t, uint64(5), frame.Base)
+	assert.Equal(t, uint64(6), frame.Seq)
+
+	entries, complete := xyJson.JournalOf(doc).Since(3)
+	assert.False(t, complete)
+	assert.Len(t, entries, 1)
+	entries, complete = xyJson.JournalOf(doc).Since(6)
+	assert.True(t, complete)
+	assert.Empty(t, entries)
+}
+
+// TestPatchReceiverSSE 测试客户端通过SSE保持文档同步
+// TestPatchReceiverSSE tests a client keeping a document in sync over SSE
+func TestPatchReceiverSSE(t *testing.T) {
+	doc := xyJson.MustParseString(`{"items":[],"count":0}`)
+	encoder := xyJson.NewPatchEncoder(doc)
+
+	var stream bytes.Buffer
+	require.NoError(t, encoder.Snapshot().WriteSSE(&stream))
+	stream.WriteString(": keep-alive\n\nevent: ping\ndata: {}\n\n")
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, xyJson.Set(doc, "$.items[+]", map[string]any{"id": i}))
+		require.NoError(t, xyJson.Set(doc, "$.count", i))
+		require.NoError(t, encoder.Next().WriteSSE(&stream))
+	}
+	require.NoError(t, xyJson.Delete(doc, "$.items[0]"))
+	require.NoError(t, encoder.Next().WriteSSE(&stream))
+	assert.True(t, strings.HasPrefix(stream.String(), "id: 0\nevent: snapshot\ndata: {"))
+
+	receiver := xyJson.NewPatchReceiver()
+	var seqs []uint64
+	err := receiver.ReadSSE(strings.NewReader(strings.ReplaceAll(stream.String(), "\n", "\r\n")), func(current xyJson.IValue, seq uint64) {
+		seqs = append(seqs, seq)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 2, 4, 6, 7}, seqs)
+	assert.True(t, doc.Equals(receiver.Document()), receiver.Document().String())
+	assert.Equal(t, uint64(7), receiver.Seq())
+}
+
+// TestPatchReceiverApply 测试帧的序号检查和WebSocket消息
+// TestPatchReceiverApply tests the sequence checks of frames and WebSocket messages
+func TestPatchReceiverApply(t *testing.T) {
+	doc := xyJson.MustParseString(`{"a":1}`)
+	encoder := xyJson.NewPatchEncoder(doc)
+	receiver := xyJson.NewPatchReceiver()
+
+	require.NoError(t, xyJson.Set(doc, "$.a", 2))
+	first := encoder.Next()
+	var je *xyJson.JSONError
+	require.True(t, errors.As(receiver.Apply(first), &je))
+	assert.Equal(t, xyJson.ErrInvalidOperation, je.Code)
+
+	message, err := (&xyJson.PatchFrame{Seq: 0, Snapshot: xyJson.MustParseString(`{"a":1}`)}).Serialize()
+	require.NoError(t, err)
+	require.NoError(t, receiver.ApplyMessage(message))
+	before := receiver.Document()
+
+	message, err = first.Serialize()
+	require.NoError(t, err)
+	assert.Equal(t, `{"base":0,"patch":[{"op":"replace","path":"/a","value":2}],"seq":1}`, string(message))
+	require.NoError(t, receiver.ApplyMessage(message))
+	assert.Equal(t, 2, xyJson.MustGetInt(receiver.Document(), "$.a"))
+	assert.Equal(t, 1, xyJson.MustGetInt(before, "$.a"), "earlier documents are replaced, not modified")
+
+	// 重复的帧被忽略，跳过的帧返回错误
+	// Duplicate frames are ignored, skipped frames return an error
+	require.NoError(t, receiver.ApplyMessage(message))
+	require.NoError(t, xyJson.Set(doc, "$.a", 3))
+	encoder.Next()
+	require.NoError(t, xyJson.Set(doc, "$.a", 4))
+	require.True(t, errors.As(receiver.Apply(encoder.Next()), &je))
+	assert.Equal(t, uint64(1), receiver.Seq())
+
+	for _, bad := range []string{`[]`, `{"seq":-1,"snapshot":{}}`, `{"seq":1}`, `{"seq":1,"base":0,"patch":{}}`, `{`} {
+		assert.Error(t, receiver.ApplyMessage([]byte(bad)), bad)
+	}
+}
+
+// newTestMigration 创建测试用的三版本迁移
+// newTestMigration creates the three-version migration used by the tests
+func newTestMigration(t *testing.T) *xyJson.Migration {
+	m, err := xyJson.NewMigration(
+		xyJson.MigrationStep{Version: 3, Description: "flag", Func: func(root xyJson.IValue) error {
+			return xyJson.Set(root, "$.migrated", true)
+		}},
+		xyJson.MigrationStep{Version: 2, Description: "nest name", Ops: []xyJson.MigrationOp{
+			{Op: xyJson.MigrationMove, From: "$.firstName", Path: "$.name.first"},
+			{Op: xyJson.MigrationMove, From: "$.middleName", Path: "$.name.middle", Optional: true},
+			{Op: xyJson.MigrationRename, Path: "$.orders[*]", Key: "qty", NewKey: "quantity"},
+			{Op: xyJson.MigrationDelete, Path: "$.legacy", Optional: true},
+			{Op: xyJson.MigrationSet, Path: "$.tags", Value: xyJson.MustParseString(`["new"]`)},
+			{Op: xyJson.MigrationTransformKeys, Path: "$.meta", Transform: xyJson.KeyTransformSnakeCase},
+		}},
+	)
+	require.NoError(t, err)
+	return m
+}
+
+const migrateV1JSON = `{"firstName": "Ann", "orders": [{"qty": 1}, {"qty": 2}], "legacy": 1,` +
+	` "meta": {"createdAt": 1, "sourceInfo": {"hostName": "a"}}}`
+
+// TestMigrate 测试按版本迁移文档
+// TestMigrate tests migrating documents by version
+func TestMigrate(t *testing.T) {
+	m := newTestMigration(t)
+	assert.Equal(t, 3, m.LatestVersion())
+
+	doc := xyJson.MustParseString(migrateV1JSON)
+	report, err := m.MigrateWithOptions(doc, 1, m.LatestVersion(), &xyJson.MigrateOptions{VersionPath: "$.schemaVersion"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": {"first": "Ann"},
+		"orders": [{"quantity": 1}, {"quantity": 2}],
+		"tags": ["new"],
+		"meta": {"created_at": 1, "source_info": {"host_name": "a"}},
+		"migrated": true,
+		"schemaVersion": 3
+	}`, xyJson.MustSerializeToString(doc))
+
+	require.Len(t, report.Steps, 2)
+	assert.Equal(t, 2, report.Steps[0].Version)
+	assert.Equal(t, "nest name", report.Steps[0].Description)
+	assert.NoError(t, report.Steps[1].Err)
+	assert.Same(t, doc, report.Result)
+
+	// 只执行范围内的步骤
+	// Only steps within the range run
+	doc = xyJson.MustParseString(`{"x": 1}`)
+	report, err = m.Migrate(doc, 2, 3)
+	require.NoError(t, err)
+	assert.Len(t, report.Steps, 1)
+	assert.JSONEq(t, `{"x": 1, "migrated": true}`, xyJson.MustSerializeToString(doc))
+
+	report, err = m.Migrate(doc, 3, 3)
+	require.NoError(t, err)
+	assert.Empty(t, report.Steps)
+
+	_, err = m.Migrate(doc, 3, 1)
+	assert.Error(t, err)
+}
+
+// TestMigrateDryRun 测试试运行不修改文档
+// TestMigrateDryRun tests that a dry run leaves the document unchanged
+func TestMigrateDryRun(t *testing.T) {
+	m := newTestMigration(t)
+	doc := xyJson.MustParseString(migrateV1JSON)
+
+	report, err := m.MigrateWithOptions(doc, 1, 3, &xyJson.MigrateOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.JSONEq(t, migrateV1JSON, xyJson.MustSerializeToString(doc))
+	assert.True(t, xyJson.MustGetBool(report.Result, "$.migrated"))
+}
+
+// TestMigrateStepError 测试失败步骤的报告，文档保持不变
+// TestMigrateStepError tests the report of a failed step, leaving the document unchanged
+func TestMigrateStepError(t *testing.T) {
+	m := newTestMigration(t)
+	doc := xyJson.MustParseString(`{"orders": []}`)
+
+	report, err := m.Migrate(doc, 1, 3)
+	require.Error(t, err)
+	var migrationErr *xyJson.MigrationError
+	require.True(t, errors.As(err, &migrationErr))
+	assert.Equal(t, 2, migrationErr.Version)
+	assert.Equal(t, 0, migrationErr.Op)
+	assert.Contains(t, err.Error(), "nest name")
+
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr))
+	assert.Equal(t, xyJson.ErrPathNotFound, jsonErr.Code)
+
+	require.Len(t, report.Steps, 1)
+	assert.Same(t, err, report.Steps[0].Err)
+	assert.Nil(t, report.Result)
+	assert.JSONEq(t, `{"orders": []}`, xyJson.MustSerializeToString(doc))
+
+	// 函数步骤的错误
+	// Errors of function steps
+	failing, err := xyJson.NewMigration(xyJson.MigrationStep{Version: 1, Func: func(xyJson.IValue) error {
+		return errors.New("boom")
+	}})
+	require.NoError(t, err)
+	_, err = failing.Migrate(doc, 0, 1)
+	require.True(t, errors.As(err, &migrationErr))
+	assert.Equal(t, -1, migrationErr.Op)
+}
+
+// TestNewMigrationErrors 测试迁移定义错误
+// TestNewMigrationErrors tests invalid migration definitions
+func TestNewMigrationErrors(t *testing.T) {
+	_, err := xyJson.NewMigration(xyJson.MigrationStep{Version: 1}, xyJson.MigrationStep{Version: 1})
+	assert.Error(t, err)
+	_, err = xyJson.NewMigration(xyJson.MigrationStep{Version: 1, Ops: []xyJson.MigrationOp{{Op: "copy", Path: "$.a"}}})
+	assert.Error(t, err)
+	_, err = xyJson.NewMigration(xyJson.MigrationStep{Version: 1, Ops: []xyJson.MigrationOp{{Op: xyJson.MigrationMove, Path: "$.a"}}})
+	assert.Error(t, err)
+	_, err = xyJson.NewMigration(xyJson.MigrationStep{Version: 1, Ops: []xyJson.MigrationOp{{Op: xyJson.MigrationRename, Key: "a"}}})
+	assert.Error(t, err)
+
+	m, err := xyJson.NewMigration()
+	require.NoError(t, err)
+	assert.Equal(t, 0, m.LatestVersion())
+}
+
+// TestApplyPatch 测试RFC 6902的各种操作
+// TestApplyPatch tests the RFC 6902 operations
+func TestApplyPatch(t *testing.T) {
+	cases := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a":1}`, `[{"op":"add","path":"/b","value":[1]}]`, `{"a":1,"b":[1]}`},
+		{`{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2},{"op":"add","path":"/a/-","value":4}]`, `{"a":[1,2,3,4]}`},
+		{`{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{`{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/0"}]`, `{"a":[2,3]}`},
+		{`{"a":1,"b":2}`, `[{"op":"replace","path":"/a","value":{"x":null}}]`, `{"a":{"x":null},"b":2}`},
+		{`{"a":{"b":1},"c":[]}`, `[{"op":"move","from":"/a/b","path":"/c/0"}]`, `{"a":{},"c":[1]}`},
+		{`{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a/b","path":"/c"}]`, `{"a":{"b":[1]},"c":[1]}`},
+		{`{"a/b":{"~":1}}`, `[{"op":"test","path":"/a~1b/~0","value":1.0},{"op":"remove","path":"/a~1b/~0"}]`, `{"a/b":{}}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":[true]}]`, `[true]`},
+		{`{"a":1}`, `[]`, `{"a":1}`},
+	}
+	for _, c := range cases {
+		patch, err := xyJson.ParsePatch([]byte(c.patch))
+		require.NoError(t, err, c.patch)
+		doc := xyJson.MustParseString(c.doc)
+		result, err := xyJson.ApplyPatch(doc, patch)
+		require.NoError(t, err, c.patch)
+		assert.True(t, xyJson.MustParseString(c.expected).Equals(result), "%s: %s", c.patch, result.String())
+		assert.True(t, xyJson.MustParseString(c.doc).Equals(doc), "root must not change")
+	}
+
+	// replace保持元素在数组中的位置
+	// replace keeps the position of an element in its array
+	result, err := xyJson.ApplyPatch(xyJson.MustParseString(`[1,2,3]`), []xyJson.PatchOperation{
+		{Op: "replace", Path: "/1", Value: xyJson.CreateString("x")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `[1,"x",3]`, xyJson.MustSerializeToString(result))
+}
+
+// TestApplyPatchErrors 测试失败的补丁不生效
+// TestApplyPatchErrors tests that failing patches have no effect
+func TestApplyPatchErrors(t *testing.T) {
+	doc := xyJson.MustParseString(`{"a":[1,2],"b":{"c":1}}`)
+	for _, patch := range []string{
+		`[{"op":"add","path":"/x/y","value":1}]`,
+		`[{"op":"add","path":"/a/3","value":1}]`,
+		`[{"op":"add","path":"/a/01","value":1}]`,
+		`[{"op":"remove","path":"/a/2"}]`,
+		`[{"op":"remove","path":"/zz"}]`,
+		`[{"op":"remove","path":""}]`,
+		`[{"op":"replace","path":"/zz","value":1}]`,
+		`[{"op":"move","from":"/b","path":"/b/c/d"}]`,
+		`[{"op":"copy","from":"/zz","path":"/c"}]`,
+		`[{"op":"add","path":"/ok","value":1},{"op":"test","path":"/b/c","value":"1"}]`,
+		`[{"op":"add","path":"a","value":1}]`,
+		`[{"op":"add","path":"/b/c/d","value":1}]`,
+	} {
+		ops, err := xyJson.ParsePatch([]byte(patch))
+		require.NoError(t, err, patch)
+		_, err = xyJson.ApplyPatch(doc, ops)
+		var je *xyJson.JSONError
+		require.True(t, errors.As(err, &je), patch)
+		assert.Contains(t, je.Context, "patch operation", patch)
+	}
+	assert.False(t, xyJson.Exists(doc, "$.ok"))
+
+	for _, patch := range []string{
+		`{}`,
+		`[1]`,
+		`[{"path":"/a"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"merge","path":"/a"}]`,
+		`[{"op":"remove","path":1}]`,
+	} {
+		_, err := xyJson.ParsePatch([]byte(patch))
+		assert.Error(t, err, patch)
+	}
+}
+
+// TestPatchToValue 测试补丁的序列化往返
+// TestPatchToValue tests the serialization round trip of patches
+func TestPatchToValue(t *testing.T) {
+	patch := []xyJson.PatchOperation{
+		{Op: "add", Path: "/a", Value: xyJson.CreateNumber(1)},
+		{Op: "add", Path: "/n"},
+		{Op: "move", From: "/a", Path: "/b"},
+		{Op: "remove", Path: "/b"},
+	}
+	data, err := xyJson.Serialize(xyJson.PatchToValue(patch))
+	require.NoError(t, err)
+	assert.Equal(t, `[{"op":"add","path":"/a","value":1},{"op":"add","path":"/n","value":null},{"from":"/a","op":"move","path":"/b"},{"op":"remove","path":"/b"}]`, string(data))
+
+	parsed, err := xyJson.ParsePatch(data)
+	require.NoError(t, err)
+	require.Len(t, parsed, 4)
+	assert.Equal(t, "/a", parsed[2].From)
+	assert.Equal(t, xyJson.NullValueType, parsed[1].Value.Type())
+}
+
+// TestMove 测试移动路径
+// TestMove tests moving paths
+func TestMove(t *testing.T) {
+	doc := xyJson.MustParseString(`{"user": {"mail": "a@x.io", "tags": ["x", "y"], "legacy": {"id": 7}}, "list": [1, 2, 3]}`)
+	tags := xyJson.MustGet(doc, "$.user.tags")
+
+	require.NoError(t, xyJson.Move(doc, "$.user.mail", "$.user.contact.email"))
+	require.NoError(t, xyJson.Move(doc, "$.user.tags", "$.meta.tags"))
+	require.NoError(t, xyJson.Move(doc, "$.list[0]", "$.list[+]"))
+	require.NoError(t, xyJson.Move(doc, "$.user.legacy.id", "$.user.legacy"))
+	require.NoError(t, xyJson.Move(doc, "$.list", "$.list"))
+
+	assert.JSONEq(t, `{
+		"user": {"contact": {"email": "a@x.io"}, "legacy": 7},
+		"meta": {"tags": ["x", "y"]},
+		"list": [2, 3, 1]
+	}`, xyJson.MustSerializeToString(doc))
+
+	// 值被移动而不是复制
+	// The value is moved, not copied
+	assert.Same(t, tags, xyJson.MustGet(doc, "$.meta.tags"))
+
+	before := xyJson.MustSerializeToString(doc)
+	assert.Error(t, xyJson.Move(doc, "$.missing", "$.x"))
+	assert.Error(t, xyJson.Move(doc, "$.meta", "$.meta.inner"))
+	assert.Error(t, xyJson.Move(doc, "$.meta", "$"))
+	assert.Error(t, xyJson.Move(doc, "$.meta.tags[*]", "$.x"))
+	assert.Error(t, xyJson.Move(doc, "$.list[+]", "$.x"))
+	assert.Error(t, xyJson.Move(nil, "$.a", "$.b"))
+	assert.Equal(t, before, xyJson.MustSerializeToString(doc))
+}
+
+// TestMoveBatch 测试批量移动
+// TestMoveBatch tests moving several paths
+func TestMoveBatch(t *testing.T) {
+	doc := xyJson.MustParseString(`{"firstName": "Ann", "lastName": "Lee", "age": 3}`)
+	require.NoError(t, xyJson.MoveBatch(doc, map[string]string{
+		"$.firstName": "$.name.first",
+		"$.lastName":  "$.name.last",
+	}))
+	assert.JSONEq(t, `{"name": {"first": "Ann", "last": "Lee"}, "age": 3}`, xyJson.MustSerializeToString(doc))
+
+	assert.Error(t, xyJson.MoveBatch(doc, map[string]string{"$.nothing": "$.x"}))
+}
+
+// TestRename 测试重命名键
+// TestRename tests renaming keys
+func TestRename(t *testing.T) {
+	doc := xyJson.MustParseString(`{"users": [{"mail": "a", "id": 1}, {"id": 2}, {"mail": "c"}, 5]}`)
+
+	n, err := xyJson.Rename(doc, "$.users[*]", "mail", "email")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.JSONEq(t, `{"users": [{"email": "a", "id": 1}, {"id": 2}, {"email": "c"}, 5]}`,
+		xyJson.MustSerializeToString(doc))
+
+	n, err = xyJson.Rename(doc, "$", "users", "accounts")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, xyJson.Exists(doc, "$.accounts[0].email"))
+
+	n, err = xyJson.Rename(doc, "$.nothing", "a", "b")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// TestRenameBatch 测试批量重命名和冲突检查
+// TestRenameBatch tests renaming several keys and conflict checks
+func TestRenameBatch(t *testing.T) {
+	doc := xyJson.MustParseString(`{"orders": [{"qty": 1, "amt": 9, "x": 1, "y": 2}, {"qty": 2}]}`)
+
+	n, err := xyJson.RenameBatch(doc, "$.orders[*]", map[string]string{
+		"qty": "quantity",
+		"amt": "amount",
+		"x":   "y",
+		"y":   "x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.JSONEq(t, `{"orders": [{"quantity": 1, "amount": 9, "x": 2, "y": 1}, {"quantity": 2}]}`,
+		xyJson.MustSerializeToString(doc))
+
+	// 冲突时文档不被修改
+	// The document is left unchanged on conflict
+	before := xyJson.MustSerializeToString(doc)
+	_, err = xyJson.RenameBatch(doc, "$.orders[*]", map[string]string{"quantity": "qty", "amount": "x"})
+	assert.Error(t, err)
+	_, err = xyJson.RenameBatch(doc, "$.orders[*]", map[string]string{"quantity": "n", "amount": "n"})
+	assert.Error(t, err)
+	_, err = xyJson.RenameBatch(doc, "$[", map[string]string{"a": "b"})
+	assert.Error(t, err)
+	assert.Equal(t, before, xyJson.MustSerializeToString(doc))
+}
+
+// newTestCipher 创建测试用AES-GCM加密算法
+// newTestCipher creates the AES-GCM cipher used by the tests
+func newTestCipher(t *testing.T, active string) *xyJson.AESGCMCipher {
+	c, err := xyJson.NewAESGCMCipher(active, map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	})
+	require.NoError(t, err)
+	return c
+}
+
+const encryptRecordJSON = `{"user":{"name":"Alice","ssn":"123-45-6789","card":{"number":"4111","cvv":123}},` +
+	`"tags":["a","b"],"logins":[{"ip":"10.0.0.1"},{"ip":"10.0.0.2"}]}`
+
+// TestEncryptPaths 测试加密和解密字段
+// TestEncryptPaths tests encrypting and decrypting fields
+func TestEncryptPaths(t *testing.T) {
+	c := newTestCipher(t, "k1")
+	paths := []string{"$.user.ssn", "$.user.card", "$.user.card.cvv", "$.logins[*].ip", "$.missing"}
+
+	record := xyJson.MustParseString(encryptRecordJSON)
+	require.NoError(t, xyJson.EncryptPaths(record, paths, c))
+
+	serialized := xyJson.MustSerializeToString(record)
+	assert.NotContains(t, serialized, "123-45-6789")
+	assert.NotContains(t, serialized, "4111")
+	assert.NotContains(t, serialized, "10.0.0.1")
+	assert.Equal(t, "Alice", xyJson.MustGetString(record, "$.user.name"))
+
+	card := xyJson.MustGet(record, "$.user.card")
+	assert.Equal(t, xyJson.StringValueType, card.Type())
+	assert.True(t, strings.HasPrefix(card.AsString(), "xyenc:v1:k1:"))
+	keyID, ok := xyJson.EncryptedKeyID(card)
+	assert.True(t, ok)
+	assert.Equal(t, "k1", keyID)
+	_, ok = xyJson.EncryptedKeyID(xyJson.MustGet(record, "$.user.name"))
+	assert.False(t, ok)
+
+	// 再次加密不会重复加密
+	// Encrypting again does not double-encrypt
+	require.NoError(t, xyJson.EncryptPaths(record, paths, c))
+	assert.Equal(t, card.AsString(), xyJson.MustGetString(record, "$.user.card"))
+
+	require.NoError(t, xyJson.DecryptPaths(record, paths, c))
+	assert.JSONEq(t, encryptRecordJSON, xyJson.MustSerializeToString(record))
+
+	// 解密未加密的值不做任何修改
+	// Decrypting values that are not encrypted changes nothing
+	require.NoError(t, xyJson.DecryptPaths(record, paths, c))
+	assert.JSONEq(t, encryptRecordJSON, xyJson.MustSerializeToString(record))
+}
+
+// TestSerializeEncrypted 测试序列化时加密和解析时解密
+// TestSerializeEncrypted tests encrypting on serialize and decrypting on parse
+func TestSerializeEncrypted(t *testing.T) {
+	c := newTestCipher(t, "k1")
+	paths := []string{"$.user.ssn", "$.tags"}
+
+	record := xyJson.MustParseString(encryptRecordJSON)
+	data, err := xyJson.SerializeEncrypted(record, paths, c)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "123-45-6789")
+	assert.JSONEq(t, encryptRecordJSON, xyJson.MustSerializeToString(record))
+
+	decoded, err := xyJson.ParseDecrypted(data, paths, c)
+	require.NoError(t, err)
+	assert.JSONEq(t, encryptRecordJSON, xyJson.MustSerializeToString(decoded))
+}
+
+// TestEncryptPathsKeyRotation 测试密钥轮换
+// TestEncryptPathsKeyRotation tests key rotation
+func TestEncryptPathsKeyRotation(t *testing.T) {
+	paths := []string{"$.user.ssn"}
+	record := xyJson.MustParseString(encryptRecordJSON)
+	require.NoError(t, xyJson.EncryptPaths(record, paths, newTestCipher(t, "k1")))
+
+	rotated := newTestCipher(t, "k2")
+	require.NoError(t, xyJson.DecryptPaths(record, paths, rotated))
+	require.NoError(t, xyJson.EncryptPaths(record, paths, rotated))
+
+	keyID, ok := xyJson.EncryptedKeyID(xyJson.MustGet(record, "$.user.ssn"))
+	require.True(t, ok)
+	assert.Equal(t, "k2", keyID)
+
+	// 只持有旧密钥的加密算法无法解密
+	// A cipher holding only the old key cannot decrypt
+	old, err := xyJson.NewAESGCMCipher("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+	err = xyJson.DecryptPaths(record, paths, old)
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr))
+	assert.Equal(t, xyJson.ErrEncryption, jsonErr.Code)
+	assert.Equal(t, "$.user.ssn", jsonErr.Path)
+}
+
+// TestEncryptPathsErrors 测试加密错误
+// TestEncryptPathsErrors tests encryption errors
+func TestEncryptPathsErrors(t *testing.T) {
+	c := newTestCipher(t, "k1")
+	record := xyJson.MustParseString(encryptRecordJSON)
+
+	assert.Error(t, xyJson.EncryptPaths(record, []string{"$"}, c))
+	assert.Error(t, xyJson.EncryptPaths(record, []string{"$["}, c))
+	assert.Error(t, xyJson.EncryptPaths(nil, []string{"$.user"}, c))
+	assert.Error(t, xyJson.EncryptPaths(record, []string{"$.user"}, nil))
+
+	_, err := xyJson.NewAESGCMCipher("k3", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	assert.Error(t, err)
+	_, err = xyJson.NewAESGCMCipher("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+
+	// 篡改密文导致解密失败
+	// Tampered ciphertext fails to decrypt
+	require.NoError(t, xyJson.EncryptPaths(record, []string{"$.user.ssn"}, c))
+	envelope := xyJson.MustGetString(record, "$.user.ssn")
+	require.NoError(t, xyJson.Set(record, "$.user.ssn", envelope[:len(envelope)-4]+"AAA="))
+	assert.Error(t, xyJson.DecryptPaths(record, []string{"$.user.ssn"}, c))
+	require.NoError(t, xyJson.Set(record, "$.user.ssn", "xyenc:v1:k1:!!"))
+	assert.Error(t, xyJson.DecryptPaths(record, []string{"$.user.ssn"}, c))
+}
@@ -1,11 +1,15 @@
 package test
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
-	xyJson "github.com/ihuem/xyJson"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	xyJson "github.com/ihuem/xyJson"
 )
 
 // TestFilter 测试Filter函数的基本功能
@@ -276,3 +280,433 @@ func TestFilterComplexData(t *testing.T) {
 		}
 	})
 }
+
+// TestEvalPredicate 测试谓词表达式求值
+// TestEvalPredicate tests evaluating predicate expressions
+func TestEvalPredicate(t *testing.T) {
+	doc := xyJson.MustParseString(`{
+		"user": {"name": "Alice", "age": 30, "email": "alice@example.com", "active": true, "nick": null,
+			"roles": ["admin", "dev"], "address": {"city": "Paris"}},
+		"items": [{"price": 10, "qty": 2}, {"price": 2.5, "qty": 4}, {"price": "n/a"}],
+		"a b": 1
+	}`)
+
+	cases := map[string]bool{
+		`$.user.age >= 18 && exists($.user.email)`:                              true,
+		`$.user.age > 30 || $.user.name == 'Bob'`:                               false,
+		`$.user.age == 30.0`:                                                    true,
+		`$.user.name != "Bob"`:                                                  true,
+		`$.user.name < 'Bob'`:                                                   true,
+		`$.user.age < '40'`:                                                     false,
+		`$.user.active`:                                                         true,
+		`!$.user.active`:                                                        false,
+		`$.user.name`:                                                           false,
+		`!($.user.age < 18 || !exists($.user.email))`:                           true,
+		`exists($.user.phone)`:                                                  false,
+		`exists($.user.nick) && $.user.nick == null`:                            true,
+		`$.user.phone == null`:                                                  false,
+		`$.user.phone != null`:                                                  true,
+		`$.user.phone < 1 || $.user.phone >= 1`:                                 false,
+		`count($.items[*]) == 3 && sum($.items[*].price) == 12.5`:               true,
+		`min($.items[*].price) == 2.5 && max($.items[*].qty) == 4`:              true,
+		`exists($.items[?(@.price > 5)])`:                                       true,
+		`length($.user.roles) == 2 && length($.user.name) == 5`:                 true,
+		`length($.user.address) == 1`:                                           true,
+		`contains($.user.roles, 'admin') && !contains($.user.roles, 'ops')`:     true,
+		`contains($.user.email, '@example.') && endsWith($.user.email, '.com')`: true,
+		`startsWith(lower($.user.name), 'al') && upper($.user.address.city) == 'PARIS'`: true,
+		`matches($.user.email, '^[a-z]+@')`:                                             true,
+		`matches($.user.age, '3')`:                                                      false,
+		`$.user.address == $.user.address && $.user.roles != $.items`:                   true,
+		`$['a b'] == 1 && $.items[1].qty == 4`:                                          true,
+		`-1 < $.user.age && 1e2 > $.user.age`:                                           true,
+		`true && (false || true)`:                                                       true,
+	}
+	for expr, expected := range cases {
+		actual, err := xyJson.EvalPredicate(doc, expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, actual, expr)
+	}
+}
+
+// TestCompilePredicate 测试预编译谓词和编译错误
+// TestCompilePredicate tests compiled predicates and compile errors
+func TestCompilePredicate(t *testing.T) {
+	adult := xyJson.MustCompilePredicate(`$.age >= 18`)
+	assert.Equal(t, `$.age >= 18`, adult.String())
+
+	for age, expected := range map[string]bool{`{"age": 17}`: false, `{"age": 18}`: true, `{}`: false} {
+		ok, err := adult.Eval(xyJson.MustParseString(age))
+		require.NoError(t, err)
+		assert.Equal(t, expected, ok, age)
+	}
+	_, err := adult.Eval(nil)
+	assert.Error(t, err)
+
+	invalid := []string{
+		``, `$.a ==`, `($.a`, `$.a == 'x`, `unknown($.a)`, `exists('a')`, `count($.a`, `contains($.a)`,
+		`matches($.a, $.b)`, `matches($.a, '(')`, `$.a == 1 2`, `$.a * 1 == 4`, `$[`, `@.a`, `1e999 > 0`,
+		strings.Repeat("(", 2000) + "true" + strings.Repeat(")", 2000),
+	}
+	for _, expr := range invalid {
+		_, err := xyJson.CompilePredicate(expr)
+		require.Error(t, err, expr)
+		var jsonErr *xyJson.JSONError
+		require.True(t, errors.As(err, &jsonErr), expr)
+		assert.Contains(t, []xyJson.ErrorCode{xyJson.ErrInvalidExpression, xyJson.ErrMaxDepthExceeded}, jsonErr.Code, expr)
+	}
+
+	_, err = xyJson.CompilePredicate(`$.a == 1 &&`)
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr))
+	assert.Equal(t, xyJson.ErrInvalidExpression, jsonErr.Code)
+	assert.Contains(t, jsonErr.Message, "offset 11")
+}
+
+// TestEvalPredicateVirtual 测试谓词使用虚拟路径
+// TestEvalPredicateVirtual tests predicates over virtual paths
+func TestEvalPredicateVirtual(t *testing.T) {
+	doc := xyJson.MustParseString(`{"items": [{"price": 2.5, "qty": 2}, {"price": 1, "qty": 3}]}`)
+	require.NoError(t, xyJson.DefineVirtual(doc, "$.totalPrice", orderTotal))
+
+	ok, err := xyJson.EvalPredicate(doc, `$.totalPrice > 7.5`)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// computedSum 在运行时计算0.1+0.2，避免常量折叠得到精确的0.3
+// computedSum computes 0.1+0.2 at run time, avoiding constant folding to an exact 0.3
+func computedSum() float64 {
+	a, b := 0.1, 0.2
+	return a + b
+}
+
+// TestNumericToleranceEqual 测试容差比较
+// TestNumericToleranceEqual tests comparisons within a tolerance
+func TestNumericToleranceEqual(t *testing.T) {
+	exact := xyJson.NumericTolerance{}
+	assert.False(t, exact.Equal(computedSum(), 0.3))
+	assert.True(t, exact.Equal(1, 1))
+
+	abs := xyJson.NumericTolerance{Absolute: 1e-9}
+	assert.True(t, abs.Equal(computedSum(), 0.3))
+	assert.False(t, abs.Equal(1e12, 1e12+1))
+	assert.False(t, abs.Equal(math.NaN(), math.NaN()))
+	assert.True(t, abs.Equal(math.Inf(1), math.Inf(1)))
+	assert.False(t, abs.Equal(math.Inf(1), math.MaxFloat64))
+
+	rel := xyJson.NumericTolerance{Relative: 1e-9}
+	assert.True(t, rel.Equal(1e12, 1e12+1))
+	assert.False(t, rel.Equal(0, 1e-12))
+
+	assert.Equal(t, 0, abs.Compare(computedSum(), 0.3))
+	assert.Equal(t, -1, abs.Compare(0.2, 0.3))
+	assert.Equal(t, 1, abs.Compare(0.4, 0.3))
+}
+
+// TestEqualWithOptions 测试带容差的深度比较
+// TestEqualWithOptions tests deep comparison with a tolerance
+func TestEqualWithOptions(t *testing.T) {
+	computed := xyJson.CreateObject()
+	computed.Set("total", xyJson.CreateNumber(computedSum()))
+	computed.Set("items", xyJson.MustParseString(`[1,{"n":2.0000000001}]`))
+	expected := xyJson.MustParseString(`{"items":[1.0,{"n":2}],"total":0.3}`)
+
+	assert.False(t, xyJson.Equal(computed, expected))
+	assert.False(t, computed.Equals(expected))
+	assert.True(t, xyJson.EqualWithOptions(computed, expected, &xyJson.EqualOptions{
+		Tolerance: xyJson.NumericTolerance{Absolute: 1e-6},
+	}))
+
+	// 没有容差时数字按数值比较，1与1.0相等
+	// Without a tolerance numbers compare by value, 1 equals 1.0
+	assert.True(t, xyJson.Equal(xyJson.MustParseString(`[1,"a",null,true]`), xyJson.MustParseString(`[1.0,"a",null,true]`)))
+	assert.False(t, xyJson.Equal(xyJson.MustParseString(`9007199254740993`), xyJson.MustParseString(`9007199254740992`)))
+	assert.False(t, xyJson.Equal(xyJson.MustParseString(`{"a":1}`), xyJson.MustParseString(`{"b":1}`)))
+	assert.False(t, xyJson.Equal(xyJson.MustParseString(`[1]`), xyJson.MustParseString(`[1,2]`)))
+	assert.False(t, xyJson.Equal(xyJson.MustParseString(`"1"`), xyJson.MustParseString(`1`)))
+	assert.True(t, xyJson.Equal(nil, nil))
+	assert.False(t, xyJson.Equal(nil, xyJson.CreateNull()))
+}
+
+// TestFilterTolerance 测试JSONPath过滤器中的数字容差
+// TestFilterTolerance tests the numeric tolerance in JSONPath filters
+func TestFilterTolerance(t *testing.T) {
+	root := xyJson.CreateObject()
+	items := xyJson.CreateArray()
+	for _, price := range []float64{computedSum(), 0.5, 0.29} {
+		item := xyJson.CreateObject()
+		item.Set("price", xyJson.CreateNumber(price))
+		items.Append(item)
+	}
+	root.Set("items", items)
+
+	tolerant := &xyJson.QueryOptions{Tolerance: xyJson.NumericTolerance{Absolute: 1e-9}}
+	count := func(options *xyJson.QueryOptions) func(string) int {
+		return func(path string) int {
+			matches, err := xyJson.SelectAllWithOptions(root, path, options)
+			require.NoError(t, err)
+			return len(matches)
+		}
+	}
+	filters := []string{
+		"$.items[?(@.price == 0.3)]",
+		"$.items[?(@.price <= 0.3)]",
+		"$.items[?(@.price > 0.3)]",
+		"$.items[?(@.price != 0.3)]",
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 3}, countAll(count(nil), filters))
+	assert.Equal(t, []int{1, 2, 1, 2}, countAll(count(tolerant), filters))
+
+	// 容差只作用于传入它的查询
+	// The tolerance only applies to the query it is passed to
+	assert.Equal(t, []int{0, 1, 2, 3}, countAll(count(nil), filters))
+
+	// 谓词表达式通过PredicateOptions设置容差
+	// Predicate expressions take the tolerance from PredicateOptions
+	predicate, err := xyJson.CompilePredicate(`$.items[0].price == 0.3 && $.items[2].price < 0.3 && exists($.items[?(@.price == 0.3)])`)
+	require.NoError(t, err)
+	ok, err := predicate.Eval(root)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = predicate.EvalWithOptions(root, &xyJson.PredicateOptions{Tolerance: tolerant.Tolerance})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// countAll 对每个路径调用count
+// countAll calls count for every path
+func countAll(count func(string) int, paths []string) []int {
+	counts := make([]int, len(paths))
+	for i, path := range paths {
+		counts[i] = count(path)
+	}
+	return counts
+}
+
+// takeSegment 选择数组的前n个元素
+// takeSegment selects the first n elements of an array
+type takeSegment int
+
+func (n takeSegment) Select(value xyJson.IValue) []xyJson.IValue {
+	arr, ok := value.(xyJson.IArray)
+	if !ok {
+		return nil
+	}
+	var results []xyJson.IValue
+	for i := 0; i < arr.Length() && i < int(n); i++ {
+		results = append(results, arr.Get(i))
+	}
+	return results
+}
+
+// TestRegisterSegment 测试注册的自定义段
+// TestRegisterSegment tests registered custom segments
+func TestRegisterSegment(t *testing.T) {
+	require.NoError(t, xyJson.RegisterSegment("take", func(args []xyJson.IValue) (xyJson.ISegment, error) {
+		if len(args) != 1 {
+			return nil, errors.New("take expects one argument")
+		}
+		n, ok := args[0].AsInt64OK()
+		if !ok || n < 0 {
+			return nil, errors.New("take expects a non-negative integer")
+		}
+		return takeSegment(n), nil
+	}))
+
+	root := xyJson.MustParseString(`{"items":[{"id":1},{"id":2},{"id":3}]}`)
+	ids, err := xyJson.GetAll(root, "$.items[take(2)].id")
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	assert.Equal(t, "1", ids[0].String())
+	assert.Equal(t, "2", ids[1].String())
+	assert.Equal(t, 1, xyJson.MustGetInt(root, "$.items[take(3)].id"))
+
+	var streamed []string
+	require.NoError(t, xyJson.QueryStream(strings.NewReader(xyJson.MustSerializeToString(root)), "$.items[take(2)].id", func(v xyJson.IValue) bool {
+		streamed = append(streamed, v.String())
+		return true
+	}))
+	assert.Equal(t, []string{"1", "2"}, streamed)
+
+	compiled, err := xyJson.CompilePath("$.items[take( 2 )]")
+	require.NoError(t, err)
+	plan := compiled.Explain()
+	assert.Equal(t, "custom", plan.Segments[1].Kind)
+	assert.Equal(t, "[take( 2 )]", plan.Segments[1].Text)
+	assert.Error(t, xyJson.Set(root, "$.items[take(1)]", 1))
+
+	for _, bad := range []string{"$.items[take()]", "$.items[take('x')]", "$.items[take(-1)]", "$.items[nothing(1)]", "$.items[take(1,)]"} {
+		_, err := xyJson.CompilePath(bad)
+		assert.Error(t, err, bad)
+	}
+	assert.Error(t, xyJson.RegisterSegment("bad name", func([]xyJson.IValue) (xyJson.ISegment, error) { return nil, nil }))
+	assert.Error(t, xyJson.RegisterSegment("empty", nil))
+}
+
+// TestRegisterFilterFunction 测试注册的过滤函数
+// TestRegisterFilterFunction tests registered filter functions
+func TestRegisterFilterFunction(t *testing.T) {
+	require.NoError(t, xyJson.RegisterFilterFunction("within", func(target xyJson.IValue, args []xyJson.IValue) bool {
+		point, ok := target.(xyJson.IArray)
+		if !ok || point.Length() != 2 || len(args) != 2 {
+			return false
+		}
+		for i := 0; i < 2; i++ {
+			v, _ := point.Get(i).AsFloat64OK()
+			low, _ := args[0].AsArray().Get(i).AsFloat64OK()
+			high, _ := args[1].AsArray().Get(i).AsFloat64OK()
+			if v < low || v > high {
+				return false
+			}
+		}
+		return true
+	}))
+	require.NoError(t, xyJson.RegisterFilterFunction("hasPrefix", func(target xyJson.IValue, args []xyJson.IValue) bool {
+		return target != nil && strings.HasPrefix(target.String(), args[0].String())
+	}))
+
+	root := xyJson.MustParseString(`{"shops":[
+		{"name":"left bank","loc":[48.85,2.33]},
+		{"name":"lyon","loc":[45.76,4.83]},
+		{"name":"louvre","loc":[48.86,2.34]},
+		{"name":"no location"}
+	]}`)
+	names := func(path string) []string {
+		values, err := xyJson.GetAll(root, path)
+		require.NoError(t, err, path)
+		var result []string
+		for _, v := range values {
+			result = append(result, v.String())
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"left bank", "louvre"}, names("$.shops[?(@.loc.within([48.8,2.2],[48.9,2.4]))].name"))
+	assert.Equal(t, []string{"lyon", "no location"}, names("$.shops[?(!@.loc.within([48.8,2.2],[48.9,2.4]))].name"))
+	assert.Equal(t, []string{"louvre"}, names("$.shops[?@.name.hasPrefix('lou')].name"))
+	assert.Equal(t, []string{"45.76"}, names(`$.shops[?(@.loc.within([45,4],[46,5]))].loc[0]`))
+
+	points := xyJson.MustParseString(`[[1,1],[5,5]]`)
+	inside, err := xyJson.GetAll(points, "$[?@.within([0,0],[2,2])]")
+	require.NoError(t, err)
+	require.Len(t, inside, 1)
+	assert.Equal(t, "[1,1]", xyJson.MustSerializeToString(inside[0]))
+
+	compiled, err := xyJson.CompilePath("$.shops[?(@.name.hasPrefix('l'))]")
+	require.NoError(t, err)
+	assert.Equal(t, "[?(@.name.hasPrefix('l'))]", compiled.Explain().Segments[1].Text)
+
+	_, err = xyJson.CompilePath("$.shops[?(@.name.missing(1))]")
+	assert.Error(t, err)
+	_, err = xyJson.CompilePath("$.shops[?(@.name.hasPrefix('unterminated))]")
+	assert.Error(t, err)
+	assert.Error(t, xyJson.RegisterFilterFunction("1st", func(xyJson.IValue, []xyJson.IValue) bool { return true }))
+	assert.Error(t, xyJson.RegisterFilterFunction("none", nil))
+
+	// 比较过滤器不受影响 Comparison filters are unaffected
+	assert.Equal(t, []string{"lyon"}, names("$.shops[?(@.name == 'lyon')].name"))
+}
+
+// TestNumberFormatParse 测试按本地化格式解析数字
+// TestNumberFormatParse tests parsing numbers in localized formats
+func TestNumberFormatParse(t *testing.T) {
+	cases := []struct {
+		format xyJson.NumberFormat
+		input  string
+		want   float64
+	}{
+		{xyJson.NumberFormatEnglish(), "1,234.56", 1234.56},
+		{xyJson.NumberFormatEnglish(), "-12,345,678", -12345678},
+		{xyJson.NumberFormatEnglish(), " 1234.5 ", 1234.5},
+		{xyJson.NumberFormatGerman(), "1.234,56", 1234.56},
+		{xyJson.NumberFormatGerman(), "1.234", 1234},
+		{xyJson.NumberFormatGerman(), "+0,5", 0.5},
+		{xyJson.NumberFormatFrench(), "1 234 567,8", 1234567.8},
+		{xyJson.NumberFormatFrench(), "1\u00a0234,5", 1234.5},
+		{xyJson.NumberFormatSwiss(), "1'234.5", 1234.5},
+	}
+	for _, c := range cases {
+		got, err := c.format.ParseFloat(c.input)
+		require.NoError(t, err, c.input)
+		assert.InDelta(t, c.want, got, 1e-9, c.input)
+	}
+
+	for _, input := range []string{"", "-", "1,23", "12,34.5", "1234,567", ",123", "1,234,", "1.2.3", "1,234.5,6", "1e5", "abc"} {
+		_, err := xyJson.NumberFormatEnglish().ParseFloat(input)
+		assert.Error(t, err, input)
+	}
+
+	i, err := xyJson.NumberFormatGerman().ParseInt("-1.000.000")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1000000), i)
+	_, err = xyJson.NumberFormatGerman().ParseInt("1.000,5")
+	assert.Error(t, err)
+	_, err = xyJson.NumberFormat{}.ParseFloat("1")
+	assert.Error(t, err)
+}
+
+// TestNumberFormatConversions 测试按数字格式转换值
+// TestNumberFormatConversions tests converting values with a number format
+func TestNumberFormatConversions(t *testing.T) {
+	formatted := xyJson.CreateString("1.234,5")
+	_, err := xyJson.ToFloat64(formatted)
+	assert.Error(t, err)
+
+	f, err := xyJson.NumberFormatGerman().ToFloat64(formatted)
+	require.NoError(t, err)
+	assert.Equal(t, 1234.5, f)
+	n, err := xyJson.NumberFormatGerman().ToInt(xyJson.CreateString("2.500"))
+	require.NoError(t, err)
+	assert.Equal(t, 2500, n)
+	i64, err := xyJson.NumberFormatGerman().ToInt64(xyJson.CreateString("-7.000.000"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(-7000000), i64)
+
+	// 格式只作用于本次调用
+	// The format only applies to the call
+	f, err = xyJson.ToFloat64(xyJson.CreateString("2.500"))
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, f)
+
+	// 不符合格式的字符串和非字符串值按ToFloat64等转换
+	// Strings that do not match the format and non-string values convert as in ToFloat64 and friends
+	f, err = xyJson.NumberFormatGerman().ToFloat64(xyJson.CreateString("12.5"))
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, f)
+	f, err = xyJson.NumberFormatGerman().ToFloat64(xyJson.CreateNumber(3.5))
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, f)
+	_, err = xyJson.NumberFormatGerman().ToInt(formatted)
+	assert.Error(t, err)
+	_, err = xyJson.NumberFormatGerman().ToInt(nil)
+	assert.Error(t, err)
+}
+
+// TestNumberFormatFilter 测试JSONPath过滤器中的格式化数字
+// TestNumberFormatFilter tests formatted numbers in JSONPath filters
+func TestNumberFormatFilter(t *testing.T) {
+	root := xyJson.MustParseString(`{"items":[{"price":"1,234.56"},{"price":"999.00"},{"price":"2,000"},{"price":"n/a"}]}`)
+	count := func(path string, format xyJson.NumberFormat) int {
+		matches, err := xyJson.SelectAllWithOptions(root, path, &xyJson.QueryOptions{NumberFormat: format})
+		require.NoError(t, err)
+		return len(matches)
+	}
+
+	assert.Equal(t, 0, count("$.items[?(@.price > 1000)]", xyJson.NumberFormat{}))
+	assert.Equal(t, 2, count("$.items[?(@.price > 1000)]", xyJson.NumberFormatEnglish()))
+	assert.Equal(t, 1, count("$.items[?(@.price < 1000)]", xyJson.NumberFormatEnglish()))
+	assert.Equal(t, 1, count("$.items[?(@.price == 2000)]", xyJson.NumberFormatEnglish()))
+	assert.Equal(t, 3, count("$.items[?(@.price != 999)]", xyJson.NumberFormatEnglish()))
+
+	// 格式化的字面量与数字和格式化字符串都按数值比较
+	// Formatted literals compare numerically with both numbers and formatted strings
+	mixed := xyJson.MustParseString(`{"a":[{"p":1234.5},{"p":"1.234,5"},{"p":"1234,5"},{"p":"x"}]}`)
+	matches, err := xyJson.SelectAllWithOptions(mixed, `$.a[?(@.p == "1.234,5")]`, &xyJson.QueryOptions{NumberFormat: xyJson.NumberFormatGerman()})
+	require.NoError(t, err)
+	assert.Len(t, matches, 3)
+	matches, err = xyJson.SelectAllWithOptions(mixed, `$.a[?(@.p == "1.234,5")]`, nil)
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
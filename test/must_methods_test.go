@@ -1,8 +1,13 @@
 package test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	xyJson "github.com/ihuem/xyJson"
 )
 
@@ -224,6 +229,55 @@ func TestMustToMethodsReturnDefaults(t *testing.T) {
 	}
 }
 
+// TestToMethodsNilValue 测试To和MustTo转换方法对nil值返回NullValueError而不是panic
+// TestToMethodsNilValue tests that To and MustTo conversion methods return NullValueError instead of panicking on nil
+func TestToMethodsNilValue(t *testing.T) {
+	conversions := map[string]func() error{
+		"ToString":  func() error { _, err := xyJson.ToString(nil); return err },
+		"ToInt":     func() error { _, err := xyJson.ToInt(nil); return err },
+		"ToInt64":   func() error { _, err := xyJson.ToInt64(nil); return err },
+		"ToFloat64": func() error { _, err := xyJson.ToFloat64(nil); return err },
+		"ToBool":    func() error { _, err := xyJson.ToBool(nil); return err },
+		"ToTime":    func() error { _, err := xyJson.ToTime(nil); return err },
+		"ToBytes":   func() error { _, err := xyJson.ToBytes(nil); return err },
+		"ToObject":  func() error { _, err := xyJson.ToObject(nil); return err },
+		"ToArray":   func() error { _, err := xyJson.ToArray(nil); return err },
+	}
+
+	for name, convert := range conversions {
+		err := convert()
+		var nullErr *xyJson.NullValueError
+		if !errors.As(err, &nullErr) {
+			t.Errorf("%s: expected NullValueError, got: %v", name, err)
+			continue
+		}
+		var jsonErr *xyJson.JSONError
+		if !errors.As(err, &jsonErr) || jsonErr.Code != xyJson.ErrNullValue {
+			t.Errorf("%s: expected JSONError with code NULL_VALUE, got: %v", name, err)
+		}
+	}
+
+	// 测试MustTo方法对nil值返回默认值
+	// Test MustTo methods return default values for nil
+	if xyJson.MustToInt(nil) != 0 || xyJson.MustToFloat64(nil) != 0 || xyJson.MustToBool(nil) {
+		t.Error("Expected zero values for nil input")
+	}
+	if xyJson.MustToString(nil) != "" || xyJson.MustToBytes(nil) != nil || !xyJson.MustToTime(nil).IsZero() {
+		t.Error("Expected zero values for nil input")
+	}
+	if xyJson.MustToObject(nil).Size() != 0 || xyJson.MustToArray(nil).Length() != 0 {
+		t.Error("Expected empty containers for nil input")
+	}
+
+	// 测试期望类型
+	// Test the expected type
+	_, err := xyJson.ToBool(nil)
+	var nullErr *xyJson.NullValueError
+	if errors.As(err, &nullErr) && nullErr.Expected != xyJson.BoolValueType {
+		t.Errorf("Expected bool type, got: %s", nullErr.Expected)
+	}
+}
+
 // TestMustCreateFromRawReturnDefaults 测试MustCreateFromRaw的正常行为
 // TestMustCreateFromRawReturnDefaults tests that MustCreateFromRaw works normally
 func TestMustCreateFromRawReturnDefaults(t *testing.T) {
@@ -247,3 +301,151 @@ func TestMustCreateFromRawReturnDefaults(t *testing.T) {
 		t.Error("Expected null value")
 	}
 }
+
+// TestMustLiteral 测试从字面量构造值
+// TestMustLiteral tests building values from literals
+func TestMustLiteral(t *testing.T) {
+	value := xyJson.MustLiteral(`{"a": 1, "b": [true, null]}`)
+	assert.Equal(t, 1, xyJson.MustGetInt(value, "$.a"))
+	assert.True(t, xyJson.MustGet(value, "$.b[1]").IsNull())
+
+	// 每次调用返回新的树
+	// Every call returns a new tree
+	require.NoError(t, xyJson.Set(value, "$.a", 2))
+	assert.Equal(t, 1, xyJson.MustGetInt(xyJson.MustLiteral(`{"a": 1, "b": [true, null]}`), "$.a"))
+}
+
+// TestMustLiteralPanics 测试无效字面量panic并指出调用位置
+// TestMustLiteralPanics tests that invalid literals panic with the call site
+func TestMustLiteralPanics(t *testing.T) {
+	defer func() {
+		r := recover()
+		require.NotNil(t, r)
+		err, ok := r.(error)
+		require.True(t, ok)
+		var jsonErr *xyJson.JSONError
+		require.True(t, errors.As(err, &jsonErr))
+		assert.Equal(t, xyJson.ErrInvalidJSON, jsonErr.Code)
+		assert.Contains(t, err.Error(), "must_methods_test.go")
+	}()
+	xyJson.MustLiteral(`{"a": 1,}`)
+}
+
+// TestCheckLiterals 测试在源码中校验字面量
+// TestCheckLiterals tests checking literals in source code
+func TestCheckLiterals(t *testing.T) {
+	src := []byte("package fixtures\n" +
+		"\n" +
+		"import (\n" +
+		"\tj \"github.com/ihuem/xyJson\"\n" +
+		"\t. \"github.com/ihuem/xyJson\"\n" +
+		")\n" +
+		"\n" +
+		"var (\n" +
+		"\tok      = j.MustLiteral(`{\"a\": [1, 2]}`)\n" +
+		"\tbroken  = j.MustLiteral(`{\"a\": [1, 2}`)\n" +
+		"\tquoted  = MustLiteral(\"[1,\\n]\")\n" +
+		"\tdynamic = j.MustLiteral(source)\n" +
+		"\tother   = j.MustParseString(`{`)\n" +
+		")\n")
+
+	problems, err := xyJson.CheckLiterals("fixtures.go", src)
+	require.NoError(t, err)
+	require.Len(t, problems, 2)
+
+	assert.Equal(t, "fixtures.go", problems[0].File)
+	assert.Equal(t, 10, problems[0].Line)
+	assert.Equal(t, 26, problems[0].Column)
+	assert.Contains(t, problems[0].Error(), "fixtures.go:10:26: invalid JSON literal")
+	assert.Equal(t, 11, problems[1].Line)
+
+	var jsonErr *xyJson.JSONError
+	assert.True(t, errors.As(problems[0], &jsonErr))
+
+	_, err = xyJson.CheckLiterals("bad.go", []byte("package"))
+	assert.Error(t, err)
+}
+
+// TestGetterErrorContext 测试便捷获取函数错误中的路径和父节点信息
+// TestGetterErrorContext tests path and parent information in convenience getter errors
+func TestGetterErrorContext(t *testing.T) {
+	root := xyJson.MustParseString(`{
+		"user": {"name": "Alice", "age": "thirty", "tags": ["a", "b"], "address": {"city": "Paris"}}
+	}`)
+
+	t.Run("type_mismatch", func(t *testing.T) {
+		_, err := xyJson.GetBool(root, "$.user.address")
+		require.Error(t, err)
+
+		var je *xyJson.JSONError
+		require.True(t, errors.As(err, &je))
+		assert.Equal(t, xyJson.ErrTypeMismatch, je.Code)
+		assert.Equal(t, "$.user.address", je.Path)
+		assert.Contains(t, je.Message, "got object")
+		assert.True(t, strings.HasPrefix(je.Parent, "$.user object {"), je.Parent)
+		assert.Contains(t, je.Parent, `"name": "Alice"`)
+		assert.Contains(t, je.Parent, `"tags": […2 items]`)
+		assert.Contains(t, err.Error(), "in $.user object")
+	})
+
+	t.Run("conversion_failure", func(t *testing.T) {
+		_, err := xyJson.GetInt(root, "$.user.age")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.user.age")
+		assert.Contains(t, err.Error(), `"age": "thirty"`)
+	})
+
+	t.Run("path_not_found", func(t *testing.T) {
+		_, err := xyJson.GetString(root, "$.user.address.zip.code")
+		require.Error(t, err)
+
+		var je *xyJson.JSONError
+		require.True(t, errors.As(err, &je))
+		assert.Equal(t, xyJson.ErrPathNotFound, je.Code)
+		assert.Equal(t, `$.user.address object {"city": "Paris"}`, je.Parent)
+		assert.Equal(t, 1, strings.Count(err.Error(), "$.user.address.zip.code"), err.Error())
+	})
+
+	t.Run("truncated_parent", func(t *testing.T) {
+		doc := xyJson.MustParseString(`{"data": {"text": "` + strings.Repeat("x", 500) + `"}}`)
+		_, err := xyJson.GetInt(doc, "$.data.missing")
+		require.Error(t, err)
+
+		var je *xyJson.JSONError
+		require.True(t, errors.As(err, &je))
+		assert.LessOrEqual(t, len(je.Parent), xyJson.MaxParentRenderLength+len("$.data object ")+len("…"))
+		assert.True(t, strings.HasSuffix(je.Parent, "…"))
+	})
+
+	t.Run("must_variants_still_return_defaults", func(t *testing.T) {
+		assert.Equal(t, 0, xyJson.MustGetInt(root, "$.user.age"))
+		assert.Equal(t, "", xyJson.MustGetString(root, "$.missing"))
+	})
+}
+
+// TestRequireGetters 测试Require系列在失败时带上下文panic
+// TestRequireGetters tests that the Require family panics with context on failure
+func TestRequireGetters(t *testing.T) {
+	root := xyJson.MustParseString(`{"user": {"name": "Alice", "age": 30, "ok": true, "score": 1.5, "tags": []}}`)
+
+	assert.Equal(t, "Alice", xyJson.RequireString(root, "$.user.name"))
+	assert.Equal(t, 30, xyJson.RequireInt(root, "$.user.age"))
+	assert.Equal(t, int64(30), xyJson.RequireInt64(root, "$.user.age"))
+	assert.Equal(t, 1.5, xyJson.RequireFloat64(root, "$.user.score"))
+	assert.True(t, xyJson.RequireBool(root, "$.user.ok"))
+	assert.Equal(t, 5, xyJson.RequireObject(root, "$.user").Size())
+	assert.Equal(t, 0, xyJson.RequireArray(root, "$.user.tags").Length())
+	assert.NotNil(t, xyJson.RequireGet(root, "$.user"))
+
+	defer func() {
+		recovered := recover()
+		require.NotNil(t, recovered)
+
+		err, ok := recovered.(*xyJson.JSONError)
+		require.True(t, ok)
+		assert.Equal(t, "$.user.name", err.Path)
+		assert.Contains(t, err.Error(), "cannot parse 'Alice' as int")
+		assert.Contains(t, err.Error(), "$.user object")
+	}()
+	xyJson.RequireInt(root, "$.user.name")
+}
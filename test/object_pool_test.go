@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	xyJson "github.com/ihuem/xyJson"
 	"github.com/ihuem/xyJson/test/testutil"
@@ -725,3 +726,375 @@ func BenchmarkObjectPool(b *testing.B) {
 		}
 	})
 }
+
+// TestObjectPoolClose 测试Close停止清理协程
+// TestObjectPoolClose tests that Close stops the cleanup goroutine
+func TestObjectPoolClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	pools := make([]xyJson.IObjectPool, 50)
+	for i := range pools {
+		pools[i] = xyJson.NewObjectPool()
+	}
+	assert.GreaterOrEqual(t, runtime.NumGoroutine(), before+len(pools))
+
+	for _, pool := range pools {
+		require.NoError(t, pool.Close())
+		require.NoError(t, pool.Close())
+	}
+	require.Eventually(t, func() bool {
+		return runtime.NumGoroutine() < before+len(pools)
+	}, 5*time.Second, time.Millisecond)
+
+	// 关闭后池仍可使用
+	// The pool remains usable after closing
+	obj := pools[0].GetObject()
+	assert.NotNil(t, obj)
+	pools[0].PutObject(obj)
+
+	engine := xyJson.NewEngine(nil)
+	assert.NoError(t, engine.Close())
+}
+
+// TestObjectPoolShrink 测试空闲的池被定期收缩
+// TestObjectPoolShrink tests that idle pools are shrunk periodically
+func TestObjectPoolShrink(t *testing.T) {
+	pool := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{
+		Enabled:         true,
+		CleanupInterval: 5 * time.Millisecond,
+		MaxIdleTime:     10 * time.Millisecond,
+		WarmObjects:     10,
+	})
+	defer pool.Close()
+
+	require.Eventually(t, func() bool {
+		return pool.GetStats().Shrinks > 0
+	}, 5*time.Second, time.Millisecond)
+
+	// 持续使用的池不会收缩
+	// A pool in constant use is not shrunk
+	busy := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{
+		Enabled:         true,
+		CleanupInterval: 5 * time.Millisecond,
+		MaxIdleTime:     time.Hour,
+	})
+	defer busy.Close()
+	for i := 0; i < 10; i++ {
+		busy.PutArray(busy.GetArray())
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.Equal(t, int64(0), busy.GetStats().Shrinks)
+}
+
+// TestPoolWarm 测试预分配池对象及预热进度
+// TestPoolWarm tests preallocating pool objects and the warm-up progress
+func TestPoolWarm(t *testing.T) {
+	pool := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{MaxPoolSize: 50, Enabled: true})
+	pool.Warm(10, 20, -1)
+
+	stats := pool.GetStats()
+	assert.Equal(t, int64(30), stats.WarmTarget)
+	assert.Equal(t, int64(30), stats.WarmCompleted)
+	assert.Equal(t, int64(0), stats.TotalAllocated)
+
+	obj := pool.GetObject()
+	require.NotNil(t, obj)
+	assert.Equal(t, 0, obj.Size())
+
+	// 数量受MaxPoolSize限制
+	// Counts are capped at MaxPoolSize
+	pool.Warm(0, 0, 100)
+	assert.Equal(t, int64(80), pool.GetStats().WarmCompleted)
+
+	disabled := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{Enabled: false})
+	disabled.Warm(10, 10, 10)
+	assert.Equal(t, int64(0), disabled.GetStats().WarmTarget)
+}
+
+// TestPoolWarmOnCreate 测试创建时同步和后台预热
+// TestPoolWarmOnCreate tests synchronous and background warm-up on creation
+func TestPoolWarmOnCreate(t *testing.T) {
+	pool := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{
+		Enabled:     true,
+		WarmValues:  5,
+		WarmObjects: 5,
+		WarmArrays:  5,
+	})
+	assert.Equal(t, int64(15), pool.GetStats().WarmCompleted)
+
+	background := xyJson.NewObjectPoolWithOptions(&xyJson.ObjectPoolOptions{
+		Enabled:          true,
+		WarmValues:       1000,
+		WarmArrays:       1000,
+		WarmInBackground: true,
+	})
+	require.Eventually(t, func() bool {
+		stats := background.GetStats()
+		return stats.WarmTarget == 2000 && stats.WarmCompleted == stats.WarmTarget
+	}, 5*time.Second, time.Millisecond)
+}
+
+// TestSmallObjectSpill 测试对象在内联存储和map之间的切换
+// TestSmallObjectSpill tests objects moving between inline storage and a map
+func TestSmallObjectSpill(t *testing.T) {
+	for _, size := range []int{0, 1, xyJson.SmallObjectSize - 1, xyJson.SmallObjectSize, xyJson.SmallObjectSize + 1, 40} {
+		t.Run(fmt.Sprintf("size %d", size), func(t *testing.T) {
+			obj := xyJson.NewObject()
+			var want []string
+			for i := size - 1; i >= 0; i-- {
+				key := fmt.Sprintf("k%02d", i)
+				require.NoError(t, obj.Set(key, i))
+				want = append([]string{key}, want...)
+			}
+			assert.Equal(t, size, obj.Size())
+			if size == 0 {
+				assert.Empty(t, obj.Keys())
+			} else {
+				assert.Equal(t, want, obj.Keys())
+			}
+
+			for i := 0; i < size; i++ {
+				assert.Equal(t, i, obj.Get(fmt.Sprintf("k%02d", i)).AsInt())
+			}
+			assert.False(t, obj.Has("missing"))
+			assert.Nil(t, obj.Get("missing"))
+
+			// 覆盖已有键不改变大小
+			// Overwriting an existing key keeps the size
+			if size > 0 {
+				require.NoError(t, obj.Set("k00", "again"))
+				assert.Equal(t, size, obj.Size())
+				assert.Equal(t, "again", obj.Get("k00").AsString())
+			}
+
+			clone := obj.Clone()
+			assert.True(t, clone.Equals(obj))
+			assert.True(t, obj.Equals(clone))
+
+			for i := 0; i < size; i += 2 {
+				assert.True(t, obj.Delete(fmt.Sprintf("k%02d", i)))
+			}
+			assert.False(t, obj.Delete("k00"))
+			assert.Equal(t, size/2, obj.Size())
+			for i := 1; i < size; i += 2 {
+				assert.True(t, obj.Has(fmt.Sprintf("k%02d", i)))
+			}
+			assert.Equal(t, size, clone.AsObject().Size())
+
+			obj.Clear()
+			assert.Equal(t, 0, obj.Size())
+			require.NoError(t, obj.Set("x", true))
+			assert.Equal(t, []string{"x"}, obj.Keys())
+		})
+	}
+}
+
+// TestSmallObjectEqualsAcrossStorage 测试内联对象与已溢出对象的比较
+// TestSmallObjectEqualsAcrossStorage tests comparing an inline object with a spilled one
+func TestSmallObjectEqualsAcrossStorage(t *testing.T) {
+	inline := xyJson.NewObject()
+	spilled := xyJson.NewObjectWithCapacity(xyJson.SmallObjectSize * 4)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, inline.Set(fmt.Sprintf("k%d", i), i))
+		require.NoError(t, spilled.Set(fmt.Sprintf("k%d", 2-i), 2-i))
+	}
+	assert.True(t, inline.Equals(spilled))
+	assert.True(t, spilled.Equals(inline))
+
+	require.NoError(t, spilled.Set("k0", 9))
+	assert.False(t, inline.Equals(spilled))
+}
+
+// TestSmallArraySpill 测试数组超过内联容量后溢出
+// TestSmallArraySpill tests arrays spilling past the inline capacity
+func TestSmallArraySpill(t *testing.T) {
+	arr := xyJson.NewArray()
+	for i := 0; i < xyJson.SmallArraySize*3; i++ {
+		require.NoError(t, arr.Append(i))
+	}
+	require.NoError(t, arr.Insert(0, -1))
+	require.NoError(t, arr.Delete(arr.Length()-1))
+	assert.Equal(t, xyJson.SmallArraySize*3, arr.Length())
+	for i := 0; i < arr.Length(); i++ {
+		assert.Equal(t, i-1, arr.Get(i).AsInt())
+	}
+
+	clone := arr.Clone()
+	arr.Clear()
+	assert.Equal(t, 0, arr.Length())
+	assert.Equal(t, xyJson.SmallArraySize*3, clone.AsArray().Length())
+
+	require.NoError(t, arr.Append("a"))
+	assert.Equal(t, "a", arr.Get(0).AsString())
+	assert.Equal(t, -1, clone.AsArray().Get(0).AsInt())
+}
+
+// TestSmallStorageRoundTrip 测试由大量小对象组成的文档能正确往返
+// TestSmallStorageRoundTrip tests that documents made of many tiny objects round-trip
+func TestSmallStorageRoundTrip(t *testing.T) {
+	input := telemetryDocument(50)
+	doc, err := xyJson.ParseString(input)
+	require.NoError(t, err)
+
+	out, err := xyJson.SerializeToString(doc)
+	require.NoError(t, err)
+	assert.True(t, xyJson.MustParseString(out).Equals(doc))
+	assert.Equal(t, 49.5, xyJson.MustGetFloat64(doc, "$.points[49].v"))
+	assert.Equal(t, "host-3", xyJson.MustGetString(doc, "$.points[3].tags.host"))
+}
+
+// telemetryDocument 生成由小对象组成的遥测文档
+// telemetryDocument generates a telemetry document made of tiny objects
+func telemetryDocument(points int) string {
+	return `{"points":` + testutil.IndexedArray(points, func(i int) string {
+		return fmt.Sprintf(`{"t":%d,"v":%d.5,"tags":{"host":"host-%d","dc":"eu"}}`, 1700000000+i, i, i)
+	}) + `}`
+}
+
+// BenchmarkParseTinyObjects 基准测试：解析由大量小对象组成的文档
+// BenchmarkParseTinyObjects benchmarks parsing a document made of many tiny objects
+func BenchmarkParseTinyObjects(b *testing.B) {
+	data := []byte(telemetryDocument(1000))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := xyJson.Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestArrayAppendAll 测试批量追加
+// TestArrayAppendAll tests bulk appending
+func TestArrayAppendAll(t *testing.T) {
+	arr := xyJson.NewArray()
+	require.NoError(t, arr.Append(0))
+
+	values := make([]xyJson.IValue, 20)
+	for i := range values {
+		values[i] = xyJson.CreateNumber(i + 1)
+	}
+	values[5] = nil
+	arr.AppendAll(values...)
+	arr.AppendAll()
+
+	assert.Equal(t, 21, arr.Length())
+	assert.Equal(t, 20, arr.Get(20).AsInt())
+	assert.True(t, arr.Get(6).IsNull())
+}
+
+// TestArrayGrow 测试预留空间后追加不再分配
+// TestArrayGrow tests that appends after reserving room do not allocate
+func TestArrayGrow(t *testing.T) {
+	arr := xyJson.NewArray()
+	require.NoError(t, arr.Append("first"))
+	arr.Grow(-1)
+	arr.Grow(0)
+	arr.Grow(1000)
+	assert.Equal(t, 1, arr.Length())
+	assert.Equal(t, "first", arr.Get(0).AsString())
+
+	// AllocsPerRun会额外预热执行一次，共追加501个元素，都在预留的空间内
+	// AllocsPerRun warms up with one extra run, appending 501 elements in total, all within the reserved room
+	value := xyJson.CreateNumber(1)
+	allocs := testing.AllocsPerRun(500, func() {
+		arr.Append(value)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, 502, arr.Length())
+}
+
+// TestObjectSetAll 测试批量设置
+// TestObjectSetAll tests bulk setting
+func TestObjectSetAll(t *testing.T) {
+	obj := xyJson.NewObject()
+	require.NoError(t, obj.Set("keep", true))
+
+	values := make(map[string]xyJson.IValue)
+	for i := 0; i < 30; i++ {
+		values[fmt.Sprintf("k%02d", i)] = xyJson.CreateNumber(i)
+	}
+	values["nothing"] = nil
+	require.NoError(t, obj.SetAll(values))
+
+	assert.Equal(t, 32, obj.Size())
+	assert.True(t, obj.Get("keep").AsBool())
+	assert.Equal(t, 29, obj.Get("k29").AsInt())
+	assert.True(t, obj.Get("nothing").IsNull())
+
+	require.NoError(t, obj.SetAll(map[string]xyJson.IValue{"keep": xyJson.CreateString("replaced")}))
+	assert.Equal(t, 32, obj.Size())
+	assert.Equal(t, "replaced", obj.Get("keep").AsString())
+}
+
+// TestObjectSetAllEmptyKey 测试空键时不做任何修改
+// TestObjectSetAllEmptyKey tests that nothing changes when a key is empty
+func TestObjectSetAllEmptyKey(t *testing.T) {
+	obj := xyJson.NewObject()
+	err := obj.SetAll(map[string]xyJson.IValue{"a": xyJson.CreateNumber(1), "": xyJson.CreateNumber(2)})
+	assert.Error(t, err)
+	assert.Equal(t, 0, obj.Size())
+}
+
+// TestObjectEnsureCapacity 测试预留容量不改变内容
+// TestObjectEnsureCapacity tests that reserving capacity keeps the content
+func TestObjectEnsureCapacity(t *testing.T) {
+	obj := xyJson.NewObject()
+	require.NoError(t, obj.Set("a", 1))
+	require.NoError(t, obj.Set("b", 2))
+
+	obj.EnsureCapacity(1)
+	obj.EnsureCapacity(1000)
+	obj.EnsureCapacity(10)
+	assert.Equal(t, []string{"a", "b"}, obj.Keys())
+	assert.Equal(t, 2, obj.Get("b").AsInt())
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, obj.Set(fmt.Sprintf("k%d", i), i))
+	}
+	assert.Equal(t, 102, obj.Size())
+	assert.True(t, obj.Delete("a"))
+	assert.False(t, obj.Has("a"))
+}
+
+// BenchmarkBuildArrayIncremental 基准测试：逐个追加构建大数组
+// BenchmarkBuildArrayIncremental benchmarks building a large array one append at a time
+func BenchmarkBuildArrayIncremental(b *testing.B) {
+	value := xyJson.CreateNumber(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		arr := xyJson.NewArray()
+		for j := 0; j < 10000; j++ {
+			arr.Append(value)
+		}
+	}
+}
+
+// BenchmarkBuildArrayGrow 基准测试：预留空间后构建大数组
+// BenchmarkBuildArrayGrow benchmarks building a large array after reserving room
+func BenchmarkBuildArrayGrow(b *testing.B) {
+	value := xyJson.CreateNumber(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		arr := xyJson.NewArray()
+		arr.Grow(10000)
+		for j := 0; j < 10000; j++ {
+			arr.Append(value)
+		}
+	}
+}
+
+// BenchmarkBuildObjectSetAll 基准测试：批量设置构建大对象
+// BenchmarkBuildObjectSetAll benchmarks building a large object with SetAll
+func BenchmarkBuildObjectSetAll(b *testing.B) {
+	values := make(map[string]xyJson.IValue, 1000)
+	for i := 0; i < 1000; i++ {
+		values[fmt.Sprintf("k%d", i)] = xyJson.CreateNumber(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		xyJson.NewObject().SetAll(values)
+	}
+}
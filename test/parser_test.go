@@ -1,15 +1,24 @@
 package test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	xyJson "github.com/ihuem/xyJson"
 	"github.com/ihuem/xyJson/test/testutil"
+	"github.com/ihuem/xyJson/xyjsontest"
 )
 
 // TestParseString 测试字符串解析功能
@@ -560,3 +569,1212 @@ func TestParseNumberFormats(t *testing.T) {
 		})
 	}
 }
+
+// adaptiveDoc 创建包含n个元素、每个元素有6个键的测试文档
+// adaptiveDoc creates a test document of n items with six keys each
+func adaptiveDoc(n int) []byte {
+	item := `{"id": 1, "name": "item", "price": 2.5, "tags": ["a", "b"], "active": true, "note": null}`
+	return []byte(`{"items": ` + testutil.RepeatArray(item, n) + `}`)
+}
+
+// TestAdaptiveSizing 测试按历史文档大小预估容量，结果与普通解析相同
+// TestAdaptiveSizing tests capacity estimates from earlier documents, with results identical to regular parsing
+func TestAdaptiveSizing(t *testing.T) {
+	xyJson.ResetAdaptiveSizing()
+	defer xyJson.ResetAdaptiveSizing()
+
+	data := adaptiveDoc(100)
+	assert.Equal(t, xyJson.SizingHints{}, xyJson.AdaptiveSizingHints(len(data)))
+
+	options := &xyJson.ParseOptions{AdaptiveSizing: true}
+	for _, iterative := range []bool{false, true} {
+		xyJson.ResetAdaptiveSizing()
+		options.Iterative = iterative
+		for i := 0; i < 3; i++ {
+			value, err := xyJson.ParseWithOptions(data, options)
+			require.NoError(t, err)
+			assert.Equal(t, xyJson.MustSerializeToString(xyJson.MustParse(data)), xyJson.MustSerializeToString(value))
+		}
+
+		// 101个对象共601个键，101个数组共300个元素
+		// 101 objects with 601 keys, 101 arrays with 300 elements
+		hints := xyJson.AdaptiveSizingHints(len(data))
+		assert.Equal(t, xyJson.SizingHints{ObjectKeys: 6, ArrayElements: 3, Documents: 3}, hints)
+	}
+
+	// 不同大小等级的统计相互独立，失败的解析不计入统计
+	// Size classes are independent, and failed parses are not recorded
+	assert.Equal(t, int64(0), xyJson.AdaptiveSizingHints(len(data)*4).Documents)
+	_, err := xyJson.ParseWithOptions(data[:len(data)-1], options)
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), xyJson.AdaptiveSizingHints(len(data)).Documents)
+
+	// 未启用时不记录
+	// Nothing is recorded when disabled
+	_, err = xyJson.ParseWithOptions(data, &xyJson.ParseOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), xyJson.AdaptiveSizingHints(len(data)).Documents)
+}
+
+// TestAdaptiveSizingAllocations 测试自适应大小调整减少分配
+// TestAdaptiveSizingAllocations tests that adaptive sizing reduces allocations
+func TestAdaptiveSizingAllocations(t *testing.T) {
+	xyJson.ResetAdaptiveSizing()
+	defer xyJson.ResetAdaptiveSizing()
+
+	// 超出内联存储的数组和对象在解析时多次扩容
+	// Arrays and objects larger than the inline storage grow several times while parsing
+	row := `{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7, "h": 8, "i": 9, "j": 10, "k": 11, "l": 12,` +
+		` "values": ` + testutil.RepeatArray(`1`, 40) + `}`
+	data := []byte(testutil.RepeatArray(row, 40))
+	adaptive := &xyJson.ParseOptions{AdaptiveSizing: true}
+	_, err := xyJson.ParseWithOptions(data, adaptive)
+	require.NoError(t, err)
+
+	plain := testing.AllocsPerRun(5, func() {
+		_, _ = xyJson.ParseWithOptions(data, &xyJson.ParseOptions{})
+	})
+	sized := testing.AllocsPerRun(5, func() {
+		_, _ = xyJson.ParseWithOptions(data, adaptive)
+	})
+	assert.Less(t, sized, plain)
+}
+
+// assertMaxDepthExceeded 断言错误是ErrMaxDepthExceeded
+// assertMaxDepthExceeded asserts that the error is ErrMaxDepthExceeded
+func assertMaxDepthExceeded(t *testing.T, err error) {
+	t.Helper()
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr), "%v", err)
+	assert.Equal(t, xyJson.ErrMaxDepthExceeded, jsonErr.Code)
+}
+
+// TestCustomParserMaxDepth 测试自定义解析器限制解码值的嵌套深度
+// TestCustomParserMaxDepth tests that the custom parser limits the nesting depth of decoded values
+func TestCustomParserMaxDepth(t *testing.T) {
+	parser := xyJson.NewCustomParserWithOptions(&xyJson.ParseOptions{MaxDepth: 3})
+
+	var ok [][][]int
+	require.NoError(t, parser.UnmarshalDirectString(`[[[1]]]`, &ok))
+	assert.Equal(t, 1, ok[0][0][0])
+
+	var deep [][][][]int
+	assertMaxDepthExceeded(t, parser.UnmarshalDirectString(`[[[[1]]]]`, &deep))
+
+	type inner struct {
+		Values []int `json:"values"`
+	}
+	type outer struct {
+		Inner inner `json:"inner"`
+	}
+	var nested struct {
+		Outer outer `json:"outer"`
+	}
+	assertMaxDepthExceeded(t, parser.UnmarshalDirectString(`{"outer":{"inner":{"values":[1]}}}`, &nested))
+
+	// 深度在调用之间重置
+	// The depth is reset between calls
+	require.NoError(t, parser.UnmarshalDirectString(`[[[2]]]`, &ok))
+	assert.Equal(t, 2, ok[0][0][0])
+}
+
+// TestCustomParserMaxDepthSkipped 测试跳过的未知字段同样计入深度，深层嵌套不会耗尽栈
+// TestCustomParserMaxDepthSkipped tests that skipped unknown fields count towards the depth too, so deep
+// nesting cannot exhaust the stack
+func TestCustomParserMaxDepthSkipped(t *testing.T) {
+	var target struct {
+		Name string `json:"name"`
+	}
+
+	payload := `{"name":"a","junk":` + strings.Repeat("[", 1000000) + `}`
+	assertMaxDepthExceeded(t, xyJson.NewCustomParser().UnmarshalDirectString(payload, &target))
+
+	parser := xyJson.NewCustomParserWithOptions(&xyJson.ParseOptions{MaxDepth: 2})
+	assertMaxDepthExceeded(t, parser.UnmarshalDirectString(`{"junk":{"a":[1]}}`, &target))
+	require.NoError(t, parser.UnmarshalDirectString(`{"junk":{"a":1},"name":"b"}`, &target))
+	assert.Equal(t, "b", target.Name)
+}
+
+// TestParseWithOptionsMaxDepth 测试ParseWithOptions遵守MaxDepth
+// TestParseWithOptionsMaxDepth tests that ParseWithOptions honors MaxDepth
+func TestParseWithOptionsMaxDepth(t *testing.T) {
+	_, err := xyJson.ParseWithOptions([]byte(`[[1]]`), &xyJson.ParseOptions{MaxDepth: 2})
+	assert.NoError(t, err)
+	_, err = xyJson.ParseWithOptions([]byte(`[[[1]]]`), &xyJson.ParseOptions{MaxDepth: 2})
+	assert.Error(t, err)
+}
+
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	if bom {
+		out = order.AppendUint16(out, 0xFEFF)
+	}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = order.AppendUint16(out, unit)
+	}
+	return out
+}
+
+// TestParseUTF8BOM 测试UTF-8 BOM被自动去除
+// TestParseUTF8BOM tests that a UTF-8 BOM is stripped automatically
+func TestParseUTF8BOM(t *testing.T) {
+	data := append([]byte{0xEF, 0xBB, 0xBF}, `{"name":"测试"}`...)
+
+	value, err := xyJson.Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, "测试", xyJson.MustGetString(value, "$.name"))
+
+	var target struct {
+		Name string `json:"name"`
+	}
+	for _, strategy := range []xyJson.DecodeStrategy{xyJson.DecodeDirect, xyJson.DecodeValue, xyJson.DecodeStdlib} {
+		target.Name = ""
+		require.NoError(t, xyJson.Unmarshal(data, &target, &xyJson.UnmarshalOptions{Strategy: strategy}), strategy.String())
+		assert.Equal(t, "测试", target.Name)
+	}
+}
+
+// TestParseUTF16 测试带BOM和不带BOM的UTF-16输入被转码
+// TestParseUTF16 tests that UTF-16 input with and without BOM is transcoded
+func TestParseUTF16(t *testing.T) {
+	text := `{"emoji":"😀","n":[1,2]}`
+	cases := map[string][]byte{
+		"LE with BOM":    encodeUTF16(text, binary.LittleEndian, true),
+		"BE with BOM":    encodeUTF16(text, binary.BigEndian, true),
+		"LE without BOM": encodeUTF16(text, binary.LittleEndian, false),
+		"BE without BOM": encodeUTF16(text, binary.BigEndian, false),
+		"BE scalar":      encodeUTF16(`7`, binary.BigEndian, false),
+	}
+	for name, data := range cases {
+		value, err := xyJson.Parse(data)
+		require.NoError(t, err, name)
+		if name == "BE scalar" {
+			assert.Equal(t, 7, value.AsInt())
+			continue
+		}
+		assert.Equal(t, "😀", xyJson.MustGetString(value, "$.emoji"), name)
+		assert.Equal(t, 2, xyJson.MustGetInt(value, "$.n[1]"), name)
+	}
+}
+
+// TestParseUnsupportedEncoding 测试UTF-32和截断的UTF-16返回明确错误
+// TestParseUnsupportedEncoding tests that UTF-32 and truncated UTF-16 return clear errors
+func TestParseUnsupportedEncoding(t *testing.T) {
+	_, err := xyJson.Parse([]byte{0xFF, 0xFE, 0, 0, '{', 0, 0, 0, '}', 0, 0, 0})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UTF-32")
+
+	_, err = xyJson.Parse([]byte{0, 0, 0, '1'})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UTF-32")
+
+	_, err = xyJson.Parse([]byte{0xFE, 0xFF, 0, '[', 0})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UTF-16")
+}
+
+// TestParseFile 测试文件解析
+// TestParseFile tests file parsing
+func TestParseFile(t *testing.T) {
+	path := testutil.CreateTempFile(t, `{"name": "Alice", "escaped": "a\"b", "items": [1, 2, {"k": "v"}]}`)
+
+	options := []*xyJson.ParseFileOptions{
+		nil,
+		{UseMmap: false, ZeroCopy: true},
+		{UseMmap: true, ZeroCopy: false},
+	}
+	for _, opts := range options {
+		doc, err := xyJson.ParseFile(path, opts)
+		require.NoError(t, err)
+
+		root := doc.Root()
+		name := strings.Clone(xyJson.MustGetString(root, "$.name"))
+		assert.Equal(t, "Alice", name)
+		assert.Equal(t, `a"b`, xyJson.MustGetString(root, "$.escaped"))
+		assert.Equal(t, "v", xyJson.MustGetString(root, "$.items[2].k"))
+		assert.Greater(t, doc.Size(), 0)
+		if opts == nil && runtime.GOOS != "windows" {
+			assert.True(t, doc.Mapped())
+		}
+
+		require.NoError(t, doc.Close())
+		require.NoError(t, doc.Close())
+		assert.Nil(t, doc.Root())
+		assert.Equal(t, "Alice", name)
+	}
+}
+
+// TestParseFileErrors 测试文件解析的错误处理
+// TestParseFileErrors tests error handling of file parsing
+func TestParseFileErrors(t *testing.T) {
+	_, err := xyJson.ParseFile(filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.Error(t, err)
+
+	_, err = xyJson.ParseFile(testutil.CreateTempFile(t, ""), nil)
+	assert.Error(t, err)
+
+	_, err = xyJson.ParseFile(testutil.CreateTempFile(t, `{"a": [1, 2}`), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test.json")
+
+	_, err = xyJson.ParseFile(testutil.CreateTempFile(t, `[[[1]]]`), &xyJson.ParseFileOptions{UseMmap: true, MaxDepth: 2})
+	assert.Error(t, err)
+}
+
+// TestIterativeExtremeDepth 测试迭代解析和序列化处理极深的嵌套
+// TestIterativeExtremeDepth tests that iterative parsing and serialization handle extreme nesting
+func TestIterativeExtremeDepth(t *testing.T) {
+	const depth = 50000
+	data := testutil.DeepDocument(depth)
+
+	value, err := xyJson.ParseWithOptions([]byte(data), &xyJson.ParseOptions{MaxDepth: depth, Iterative: true})
+	require.NoError(t, err)
+
+	leaf := value
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			require.Equal(t, xyJson.ArrayValueType, leaf.Type())
+			leaf = leaf.AsArray().Get(1)
+		} else {
+			require.Equal(t, xyJson.ObjectValueType, leaf.Type())
+			leaf = leaf.AsObject().Get("k")
+		}
+	}
+	assert.Equal(t, "leaf", leaf.String())
+
+	serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{Compact: true, MaxDepth: depth, Iterative: true})
+	out, err := serializer.Serialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, data, string(out))
+
+	_, err = xyJson.ParseWithOptions([]byte(data), &xyJson.ParseOptions{MaxDepth: depth - 1, Iterative: true})
+	assert.Error(t, err)
+	serializer = xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{Compact: true, MaxDepth: depth - 1, Iterative: true})
+	_, err = serializer.Serialize(value)
+	assert.Error(t, err)
+}
+
+// TestIterativeMatchesRecursive 测试迭代实现与递归实现的结果一致
+// TestIterativeMatchesRecursive tests that the iterative implementations agree with the recursive ones
+func TestIterativeMatchesRecursive(t *testing.T) {
+	optionSets := []*xyJson.SerializeOptions{
+		{Compact: true, MaxDepth: 100},
+		{Indent: "  ", SortKeys: true, MaxDepth: 100},
+		{Indent: "\t", EscapeHTML: true, MaxDepth: 100},
+	}
+
+	gen := xyjsontest.NewGenerator(nil)
+	for i := 0; i < 200; i++ {
+		doc := gen.Document()
+		for _, options := range optionSets {
+			expected, err := xyJson.NewSerializerWithOptions(options).Serialize(doc)
+			require.NoError(t, err)
+
+			iterative := *options
+			iterative.Iterative = true
+			actual, err := xyJson.NewSerializerWithOptions(&iterative).Serialize(doc)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual), "seed %d", gen.Seed())
+
+			parsed, err := xyJson.ParseWithOptions(expected, &xyJson.ParseOptions{MaxDepth: 100, Iterative: true})
+			require.NoError(t, err, "seed %d", gen.Seed())
+			require.True(t, doc.Equals(parsed), "seed %d: %s", gen.Seed(), expected)
+		}
+	}
+}
+
+// TestIterativeParseErrors 测试迭代解析返回与递归解析相同的错误
+// TestIterativeParseErrors tests that iterative parsing returns the same errors as recursive parsing
+func TestIterativeParseErrors(t *testing.T) {
+	inputs := []string{
+		`{`, `[`, `[1`, `[1,`, `{"a"`, `{"a":`, `{"a":1`, `{"a":1,`, `{1:2}`, `{"a" 1}`,
+		`{"a":1,"a":2}`, `[1 2]`, `{"a":1 "b":2}`, `[1,]`, `{"a":[1,{"b":}]}`, `[tru]`, `[1]x`,
+	}
+	for _, input := range inputs {
+		_, expected := xyJson.ParseWithOptions([]byte(input), nil)
+		_, actual := xyJson.ParseWithOptions([]byte(input), &xyJson.ParseOptions{MaxDepth: xyJson.DefaultMaxDepth, Iterative: true})
+		require.Error(t, expected, input)
+		require.Error(t, actual, input)
+		assert.Equal(t, expected.Error(), actual.Error(), input)
+	}
+
+	value, err := xyJson.ParseWithOptions([]byte(` { "a" : [ ] , "b" : { } , "c" : [ { } , [ ] ] } `),
+		&xyJson.ParseOptions{MaxDepth: 3, Iterative: true})
+	require.NoError(t, err)
+	out, err := xyJson.SerializeToString(value)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[],"b":{},"c":[{},[]]}`, out)
+}
+
+// TestIterativeSerializeCycle 测试迭代序列化检测循环引用
+// TestIterativeSerializeCycle tests that iterative serialization detects circular references
+func TestIterativeSerializeCycle(t *testing.T) {
+	obj := xyJson.CreateObject()
+	arr := xyJson.CreateArray()
+	arr.Append(obj)
+	require.NoError(t, obj.Set("self", arr))
+
+	serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{Compact: true, MaxDepth: 100, Iterative: true})
+	_, err := serializer.Serialize(obj)
+	assert.Error(t, err)
+
+	shared := xyJson.CreateArray()
+	shared.Append(xyJson.CreateNumber(1))
+	root := xyJson.CreateArray()
+	root.Append(shared)
+	root.Append(shared)
+	out, err := serializer.Serialize(root)
+	require.NoError(t, err)
+	assert.Equal(t, `[[1],[1]]`, string(out))
+}
+
+// TestParseNonFinite 测试扩展开启时解析NaN和Infinity字面量
+// TestParseNonFinite tests parsing the NaN and Infinity literals with the extension enabled
+func TestParseNonFinite(t *testing.T) {
+	input := `{"nan":NaN,"inf":Infinity,"neg":-Infinity,"list":[NaN, -1, -Infinity]}`
+
+	_, err := xyJson.ParseString(input)
+	assert.Error(t, err)
+
+	value, err := xyJson.ParseWithOptions([]byte(input), &xyJson.ParseOptions{AllowNonFinite: true})
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(xyJson.MustGetFloat64(value, "$.nan")))
+	assert.True(t, math.IsInf(xyJson.MustGetFloat64(value, "$.inf"), 1))
+	assert.True(t, math.IsInf(xyJson.MustGetFloat64(value, "$.neg"), -1))
+	assert.Equal(t, -1, xyJson.MustGetInt(value, "$.list[1]"))
+	assert.True(t, math.IsInf(xyJson.MustGetFloat64(value, "$.list[2]"), -1))
+
+	_, err = xyJson.ParseWithOptions([]byte(`Infinit`), &xyJson.ParseOptions{AllowNonFinite: true})
+	assert.Error(t, err)
+}
+
+// TestSerializeNonFinite 测试扩展开启时输出NaN和Infinity字面量，并可重新解析
+// TestSerializeNonFinite tests emitting the NaN and Infinity literals with the extension enabled, and parsing them back
+func TestSerializeNonFinite(t *testing.T) {
+	arr := xyJson.CreateArray()
+	arr.Append(math.NaN())
+	arr.Append(math.Inf(1))
+	arr.Append(math.Inf(-1))
+	arr.Append(1.5)
+
+	out, err := xyJson.SerializeToString(arr)
+	require.NoError(t, err)
+	assert.Equal(t, `[null,null,null,1.5]`, out)
+
+	serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+		Compact:        true,
+		MaxDepth:       xyJson.DefaultMaxDepth,
+		AllowNonFinite: true,
+	})
+	out, err = serializer.SerializeToString(arr)
+	require.NoError(t, err)
+	assert.Equal(t, `[NaN,Infinity,-Infinity,1.5]`, out)
+
+	back, err := xyJson.ParseWithOptions([]byte(out), &xyJson.ParseOptions{AllowNonFinite: true})
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(back.AsArray().Get(0).AsFloat64()))
+	assert.True(t, math.IsInf(back.AsArray().Get(2).AsFloat64(), -1))
+}
+
+// TestCustomParserNonFinite 测试自定义解析器在扩展开启时解码和跳过非有限数字
+// TestCustomParserNonFinite tests the custom parser decoding and skipping non-finite numbers with the extension enabled
+func TestCustomParserNonFinite(t *testing.T) {
+	var target struct {
+		F float64 `json:"f"`
+		G float32 `json:"g"`
+		I int     `json:"i"`
+	}
+
+	assert.Error(t, xyJson.NewCustomParser().UnmarshalDirectString(`{"f":NaN}`, &target))
+
+	parser := xyJson.NewCustomParserWithOptions(&xyJson.ParseOptions{AllowNonFinite: true})
+	require.NoError(t, parser.UnmarshalDirectString(`{"skip":NaN,"f":-Infinity,"g":Infinity,"i":3}`, &target))
+	assert.True(t, math.IsInf(target.F, -1))
+	assert.True(t, math.IsInf(float64(target.G), 1))
+	assert.Equal(t, 3, target.I)
+
+	assert.Error(t, parser.UnmarshalDirectString(`{"i":NaN}`, &target))
+}
+
+// TestPreserveNumberText 测试数字原始文本的保留
+// TestPreserveNumberText tests preserving the original text of numbers
+func TestPreserveNumberText(t *testing.T) {
+	payload := `{"amount":1.10,"big":123456789012345678901234567890,"exp":1e3,"items":[-0,1E+2,0.000,-12.5e-3,42],"neg":-98765432109876543210}`
+
+	for _, iterative := range []bool{false, true} {
+		value, err := xyJson.ParseWithOptions([]byte(payload), &xyJson.ParseOptions{
+			MaxDepth:           xyJson.DefaultMaxDepth,
+			Iterative:          iterative,
+			PreserveNumberText: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, payload, xyJson.MustSerializeToString(value), "iterative=%v", iterative)
+	}
+
+	value, err := xyJson.ParseWithOptions([]byte(payload), &xyJson.ParseOptions{PreserveNumberText: true})
+	require.NoError(t, err)
+
+	// 数值照常可用 Numeric values work as usual
+	assert.Equal(t, 1.1, xyJson.MustGetFloat64(value, "$.amount"))
+	assert.Equal(t, 1000.0, xyJson.MustGetFloat64(value, "$.exp"))
+	assert.Equal(t, 42, xyJson.MustGetInt(value, "$.items[4]"))
+	assert.InDelta(t, 1.2345678901234568e29, xyJson.MustGetFloat64(value, "$.big"), 1e15)
+	assert.Equal(t, "1.10", xyJson.MustGet(value, "$.amount").String())
+
+	text, ok := xyJson.NumberText(xyJson.MustGet(value, "$.big"))
+	assert.True(t, ok)
+	assert.Equal(t, "123456789012345678901234567890", text)
+	_, ok = xyJson.NumberText(xyJson.MustParseString(`1.10`))
+	assert.False(t, ok)
+
+	// 拷贝和快照保留原始文本 Clones and snapshots keep the text
+	assert.Equal(t, payload, xyJson.MustSerializeToString(value.Clone()))
+	snapshot, err := value.Snapshot()
+	require.NoError(t, err)
+	data, err := snapshot.Serialize()
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(data))
+
+	// 修改文档后未改动的数字保持不变 Untouched numbers stay as they were after edits
+	require.NoError(t, xyJson.Set(value, "$.exp", 7))
+	assert.Equal(t, `{"amount":1.10,"big":123456789012345678901234567890,"exp":7,"items":[-0,1E+2,0.000,-12.5e-3,42],"neg":-98765432109876543210}`,
+		xyJson.MustSerializeToString(value))
+}
+
+// TestPreserveNumberTextEquals 测试保留原始文本的数字的比较
+// TestPreserveNumberTextEquals tests comparing numbers keeping their original text
+func TestPreserveNumberTextEquals(t *testing.T) {
+	parse := func(s string) xyJson.IValue {
+		value, err := xyJson.ParseWithOptions([]byte(s), &xyJson.ParseOptions{PreserveNumberText: true})
+		require.NoError(t, err)
+		return value
+	}
+
+	assert.True(t, parse(`1.10`).Equals(xyJson.MustParseString(`1.1`)), "values compare numerically")
+	assert.True(t, parse(`1.10`).Equals(parse(`1.1`)))
+	assert.True(t, parse(`123456789012345678901234567890`).Equals(parse(`123456789012345678901234567890`)))
+	assert.False(t, parse(`123456789012345678901234567890`).Equals(parse(`123456789012345678901234567891`)),
+		"inexact values compare by text")
+
+	// 默认不保留原始文本 The text is not kept by default
+	assert.Equal(t, "1.1", xyJson.MustSerializeToString(xyJson.MustParseString(`1.10`)))
+	_, err := xyJson.ParseString(`123456789012345678901234567890`)
+	assert.Error(t, err)
+}
+
+// TestParseIntoMatchesParse 测试ParseInto与Parse的结果一致
+// TestParseIntoMatchesParse tests that ParseInto produces the same result as Parse
+func TestParseIntoMatchesParse(t *testing.T) {
+	inputs := []string{
+		`null`, `true`, `-12.5e3`, `"a\"bé"`, `[]`, `{}`,
+		`{"a":1,"b":[true,null,"x"],"c":{"d":-0.5}}`,
+		`{"escaped":1,"plain":2}`,
+	}
+
+	parser := xyJson.NewParser()
+	var doc xyJson.IValue
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			expected := xyJson.MustParseString(input)
+
+			parser.Reset([]byte(input))
+			fresh, err := parser.ParseInto(nil)
+			require.NoError(t, err)
+			assert.True(t, expected.Equals(fresh))
+
+			// 同一个树依次解析不同形状的输入
+			// The same tree parses inputs of different shapes in turn
+			parser.Reset([]byte(input))
+			doc, err = parser.ParseInto(doc)
+			require.NoError(t, err)
+			assert.True(t, expected.Equals(doc))
+		})
+	}
+}
+
+// TestParseIntoReusesNodes 测试结构相同时复用节点
+// TestParseIntoReusesNodes tests that nodes are reused when the structure matches
+func TestParseIntoReusesNodes(t *testing.T) {
+	parser := xyJson.NewParser()
+	parser.Reset([]byte(`{"id":1,"tags":["a","b","c"],"meta":{"ok":true},"old":0}`))
+	root, err := parser.ParseInto(nil)
+	require.NoError(t, err)
+
+	id := xyJson.MustGet(root, "$.id")
+	tags := xyJson.MustGet(root, "$.tags")
+	first := xyJson.MustGet(root, "$.tags[0]")
+	meta := xyJson.MustGet(root, "$.meta")
+	root.SetMeta("source", "kafka")
+
+	parser.Reset([]byte(`{"id":2,"tags":["z"],"meta":{"ok":"yes"},"new":null}`))
+	again, err := parser.ParseInto(root)
+	require.NoError(t, err)
+
+	assert.Same(t, root, again)
+	assert.Same(t, id, xyJson.MustGet(again, "$.id"))
+	assert.Same(t, tags, xyJson.MustGet(again, "$.tags"))
+	assert.Same(t, first, xyJson.MustGet(again, "$.tags[0]"))
+	assert.Same(t, meta, xyJson.MustGet(again, "$.meta"))
+	assert.Nil(t, again.Meta("source"))
+
+	assert.True(t, xyJson.MustParseString(`{"id":2,"tags":["z"],"meta":{"ok":"yes"},"new":null}`).Equals(again))
+	assert.Equal(t, []string{"id", "meta", "new", "tags"}, again.AsObject().Keys())
+	assert.Equal(t, 1, tags.AsArray().Length())
+}
+
+// TestParseIntoTypeChange 测试类型改变时替换节点
+// TestParseIntoTypeChange tests that nodes are replaced when the type changes
+func TestParseIntoTypeChange(t *testing.T) {
+	parser := xyJson.NewParser()
+	parser.Reset([]byte(`{"v":[1,2],"w":{"x":1}}`))
+	root, err := parser.ParseInto(nil)
+	require.NoError(t, err)
+
+	parser.Reset([]byte(`{"v":{"k":"obj"},"w":[{"x":2}]}`))
+	root, err = parser.ParseInto(root)
+	require.NoError(t, err)
+	assert.Equal(t, "obj", xyJson.MustGetString(root, "$.v.k"))
+	assert.Equal(t, 2, xyJson.MustGetInt(root, "$.w[0].x"))
+
+	parser.Reset([]byte(`[1]`))
+	replaced, err := parser.ParseInto(root)
+	require.NoError(t, err)
+	assert.Equal(t, xyJson.ArrayValueType, replaced.Type())
+}
+
+// TestParseIntoLargeObject 测试溢出到map的对象，且输入缓冲区被复用时键不受影响
+// TestParseIntoLargeObject tests objects spilled to a map, with keys unaffected when the input buffer is reused
+func TestParseIntoLargeObject(t *testing.T) {
+	message := func(offset int) []byte {
+		var sb strings.Builder
+		sb.WriteByte('{')
+		for i := 0; i < 20; i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(&sb, `"key%02d":%d`, i, i+offset)
+		}
+		sb.WriteByte('}')
+		return []byte(sb.String())
+	}
+
+	parser := xyJson.NewParser()
+	buf := message(100)
+	parser.Reset(buf)
+	root, err := parser.ParseInto(nil)
+	require.NoError(t, err)
+
+	next := message(200)
+	copy(buf, next)
+	parser.Reset(buf)
+	root, err = parser.ParseInto(root)
+	require.NoError(t, err)
+
+	// 覆盖输入缓冲区后键仍然完整
+	// Keys stay intact after the input buffer is overwritten
+	for i := range buf {
+		buf[i] = 'x'
+	}
+	obj := root.AsObject()
+	assert.Equal(t, 20, obj.Size())
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, i+200, obj.Get(fmt.Sprintf("key%02d", i)).AsInt())
+	}
+}
+
+// TestParseIntoErrors 测试ParseInto的错误
+// TestParseIntoErrors tests ParseInto errors
+func TestParseIntoErrors(t *testing.T) {
+	parser := xyJson.NewParser()
+	root := xyJson.MustParseString(`{"a":1,"b":[1]}`)
+
+	for _, input := range []string{``, ` `, `{"a":1,"a":2}`, `{"":1}`, `[1,]`, `{"a":tru}`, `nul`, `x`, `{"b":[1}`, `1 2`, `01`} {
+		parser.Reset([]byte(input))
+		_, err := parser.ParseInto(root.Clone())
+		assert.Error(t, err, input)
+
+		_, parseErr := xyJson.ParseString(input)
+		assert.Error(t, parseErr, input)
+	}
+}
+
+// TestParseIntoZeroAlloc 测试结构和内容相同的消息反复解析时不分配内存
+// TestParseIntoZeroAlloc tests that repeatedly parsing messages of the same shape and content does not allocate
+func TestParseIntoZeroAlloc(t *testing.T) {
+	messages := [][]byte{
+		[]byte(`{"device":"sensor-1","ok":true,"temp":21.5,"seq":7,"tags":["a","b"]}`),
+		[]byte(`{"device":"sensor-1","ok":false,"temp":21.5,"seq":9,"tags":["a","b"]}`),
+	}
+
+	parser := xyJson.NewParser()
+	var root xyJson.IValue
+	for _, msg := range messages {
+		parser.Reset(msg)
+		var err error
+		root, err = parser.ParseInto(root)
+		require.NoError(t, err)
+	}
+
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		parser.Reset(messages[i%2])
+		parser.ParseInto(root)
+		i++
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, "sensor-1", xyJson.MustGetString(root, "$.device"))
+}
+
+// BenchmarkParseInto 基准测试：复用解析器和值树
+// BenchmarkParseInto benchmarks reusing the parser and the value tree
+func BenchmarkParseInto(b *testing.B) {
+	data := []byte(telemetryDocument(100))
+	parser := xyJson.NewParser()
+	parser.Reset(data)
+	root, err := parser.ParseInto(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.Reset(data)
+		if root, err = parser.ParseInto(root); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseFresh 基准测试：每次解析都创建新的值树
+// BenchmarkParseFresh benchmarks building a fresh value tree on every parse
+func BenchmarkParseFresh(b *testing.B) {
+	data := []byte(telemetryDocument(100))
+	parser := xyJson.NewParser()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestParseMulti 测试解析连续的多个JSON值
+// TestParseMulti tests parsing several concatenated JSON values
+func TestParseMulti(t *testing.T) {
+	values, err := xyJson.ParseMultiString(`{"id":1}{"id":2}` + "\n" + ` [3,4]"s"{"id":5} 6 true null`)
+	require.NoError(t, err)
+	require.Len(t, values, 8)
+
+	assert.Equal(t, 1, xyJson.MustGetInt(values[0], "$.id"))
+	assert.Equal(t, 2, xyJson.MustGetInt(values[1], "$.id"))
+	assert.Equal(t, 2, values[2].AsArray().Length())
+	assert.Equal(t, "s", values[3].String())
+	assert.Equal(t, 5, xyJson.MustGetInt(values[4], "$.id"))
+	assert.Equal(t, 6, values[5].AsInt())
+	assert.True(t, values[6].AsBool())
+	assert.True(t, values[7].IsNull())
+}
+
+// TestParseMultiEmptyAndErrors 测试空输入和错误位置
+// TestParseMultiEmptyAndErrors tests empty input and error location
+func TestParseMultiEmptyAndErrors(t *testing.T) {
+	values, err := xyJson.ParseMulti([]byte(" \n\t"))
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	_, err = xyJson.ParseMultiString(`{"a":1}{"b":}`)
+	require.Error(t, err)
+	var jsonErr *xyJson.JSONError
+	require.ErrorAs(t, err, &jsonErr)
+	assert.Contains(t, jsonErr.Context, "document 1")
+
+	_, err = xyJson.ParseMultiString(`{"a":1}{"b":2`)
+	assert.Error(t, err)
+}
+
+// TestRepair 测试常见格式错误的修复
+// TestRepair tests fixing common defects
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		fixes    []xyJson.Fix
+	}{
+		{
+			name:     "valid input is unchanged",
+			input:    `{"a": [1, -2.5e3, true, null], "b": "x'y"}`,
+			expected: `{"a": [1, -2.5e3, true, null], "b": "x'y"}`,
+		},
+		{
+			name:     "single quotes",
+			input:    `{'a': 'it\'s "q"'}`,
+			expected: `{"a": "it's \"q\""}`,
+			fixes:    []xyJson.Fix{{Kind: xyJson.FixSingleQuotes, Offset: 1}, {Kind: xyJson.FixSingleQuotes, Offset: 6}},
+		},
+		{
+			name:     "unquoted keys",
+			input:    `{name: "feed", $ref_2: {is-on : true}}`,
+			expected: `{"name": "feed", "$ref_2": {"is-on" : true}}`,
+			fixes: []xyJson.Fix{
+				{Kind: xyJson.FixUnquotedKey, Offset: 1},
+				{Kind: xyJson.FixUnquotedKey, Offset: 15},
+				{Kind: xyJson.FixUnquotedKey, Offset: 24},
+			},
+		},
+		{
+			name:     "trailing commas",
+			input:    "[1, 2,\n]",
+			expected: "[1, 2\n]",
+			fixes:    []xyJson.Fix{{Kind: xyJson.FixTrailingComma, Offset: 5}},
+		},
+		{
+			name:     "missing closers at EOF",
+			input:    `{"items": [{"id": 1}, {"id": 2,`,
+			expected: `{"items": [{"id": 1}, {"id": 2}]}`,
+			fixes: []xyJson.Fix{
+				{Kind: xyJson.FixTrailingComma, Offset: 30},
+				{Kind: xyJson.FixMissingClose, Offset: 31},
+				{Kind: xyJson.FixMissingClose, Offset: 31},
+				{Kind: xyJson.FixMissingClose, Offset: 31},
+			},
+		},
+		{
+			name:     "unterminated string",
+			input:    `["abc`,
+			expected: `["abc"]`,
+			fixes:    []xyJson.Fix{{Kind: xyJson.FixUnterminatedString, Offset: 1}, {Kind: xyJson.FixMissingClose, Offset: 5}},
+		},
+		{
+			name:     "inner closer missing",
+			input:    `{"a": [1, 2}`,
+			expected: `{"a": [1, 2]}`,
+			fixes:    []xyJson.Fix{{Kind: xyJson.FixMissingClose, Offset: 11}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, fixes, err := xyJson.Repair([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+			assert.Equal(t, tt.fixes, fixes)
+			_, err = xyJson.Parse(data)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestRepairFailure 测试无法修复的输入
+// TestRepairFailure tests inputs that cannot be repaired
+func TestRepairFailure(t *testing.T) {
+	_, _, err := xyJson.Repair([]byte(`{"a": undefined}`))
+	assert.Error(t, err)
+
+	_, _, err = xyJson.Repair([]byte(`[1]]`))
+	assert.Error(t, err)
+
+	_, fixes, err := xyJson.Repair([]byte(`{'a' 1,}`))
+	assert.Error(t, err)
+	assert.Len(t, fixes, 2, "the fixes applied are still reported")
+
+	_, _, err = xyJson.Repair(nil)
+	assert.Error(t, err)
+
+	assert.Equal(t, "trailing comma at offset 5", xyJson.Fix{Kind: xyJson.FixTrailingComma, Offset: 5}.String())
+}
+
+// TestShapeCacheMatchesParse 测试形状命中、不一致和重新学习时结果都与普通解析相同
+// TestShapeCacheMatchesParse tests that results match regular parsing on shape hits, mismatches and relearning
+func TestShapeCacheMatchesParse(t *testing.T) {
+	inputs := []string{
+		`{"id":1,"name":"a","tags":["x","y"],"pos":{"lat":1.5,"lng":2.5}}`,
+		`{"id":2,"name":"b","tags":["z"],"pos":{"lat":3.5,"lng":4.5}}`,
+		`{"name":"c","id":3,"tags":[],"pos":{"lng":5.5,"lat":6.5}}`,
+		`{"id":4,"name":"d"}`,
+		`{"id":5,"name":"e","tags":["w"],"pos":{"lat":7.5,"lng":8.5},"extra":true}`,
+		`{"id":6,"name":"f","tags":null,"pos":[1,2]}`,
+		`{"id":7,"name":"g","tags":["x","y"],"pos":{"lat":1.5,"lng":2.5}}`,
+		`[{"a":1},{"b":2},{"a":3,"b":4}]`,
+		`"scalar"`,
+		`{}`,
+		`{"id":8,"name":"h","tags":["x","y"],"pos":{"lat":1.5,"lng":2.5}}`,
+	}
+
+	parser := xyJson.NewParserWithShapeCache()
+	for _, input := range inputs {
+		expected := xyJson.MustParseString(input)
+		value, err := parser.ParseString(input)
+		require.NoError(t, err, input)
+		assert.True(t, expected.Equals(value), input)
+		if value.Type() == xyJson.ObjectValueType {
+			assert.Equal(t, expected.AsObject().Keys(), value.AsObject().Keys(), input)
+		}
+	}
+}
+
+// TestShapeCacheDuplicateKey 测试形状不一致时仍然检测重复键
+// TestShapeCacheDuplicateKey tests that duplicate keys are still detected on a shape mismatch
+func TestShapeCacheDuplicateKey(t *testing.T) {
+	parser := xyJson.NewParserWithShapeCache()
+	_, err := parser.ParseString(`{"a":1,"b":2,"c":3}`)
+	require.NoError(t, err)
+
+	for _, input := range []string{`{"a":1,"a":2}`, `{"a":1,"b":2,"a":3}`, `{"b":1,"a":2,"b":3}`, `{"a":1,"a":2}`} {
+		_, err := parser.ParseString(input)
+		assert.Error(t, err, input)
+	}
+
+	value, err := parser.ParseString(`{"a":1,"b":2,"c":3}`)
+	require.NoError(t, err)
+	assert.Equal(t, 3, value.AsObject().Size())
+}
+
+// TestShapeCacheLargeObject 测试超过内联容量的对象
+// TestShapeCacheLargeObject tests objects exceeding the inline capacity
+func TestShapeCacheLargeObject(t *testing.T) {
+	message := func(offset int) string {
+		var sb strings.Builder
+		sb.WriteByte('{')
+		for i := 0; i < 40; i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(&sb, `"field%02d":%d`, i, i+offset)
+		}
+		sb.WriteByte('}')
+		return sb.String()
+	}
+
+	parser := xyJson.NewParserWithShapeCache()
+	for round := 0; round < 3; round++ {
+		value, err := parser.ParseString(message(round))
+		require.NoError(t, err)
+		obj := value.AsObject()
+		assert.Equal(t, 40, obj.Size())
+		assert.Equal(t, 39+round, obj.Get("field39").AsInt())
+	}
+}
+
+// TestShapeCacheFewerAllocs 测试形状命中时分配更少
+// TestShapeCacheFewerAllocs tests that shape hits allocate less
+func TestShapeCacheFewerAllocs(t *testing.T) {
+	data := []byte(telemetryDocument(50))
+	plain := xyJson.NewParser()
+	cached := xyJson.NewParserWithShapeCache()
+	_, err := cached.Parse(data)
+	require.NoError(t, err)
+
+	plainAllocs := testing.AllocsPerRun(20, func() {
+		plain.Parse(data)
+	})
+	cachedAllocs := testing.AllocsPerRun(20, func() {
+		cached.Parse(data)
+	})
+	assert.Less(t, cachedAllocs, plainAllocs)
+}
+
+// BenchmarkParseShapeCache 基准测试：使用形状缓存解析结构相同的消息
+// BenchmarkParseShapeCache benchmarks parsing messages of the same structure with a shape cache
+func BenchmarkParseShapeCache(b *testing.B) {
+	data := []byte(telemetryDocument(100))
+	parser := xyJson.NewParserWithShapeCache()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type numberHolder struct {
+	F float64 `json:"f"`
+	I int64   `json:"i"`
+	S string  `json:"s"`
+}
+
+var malformedNumbers = []string{"01", "-01", "00", "1.", "-1.", "1.e5", "1e", "1e+", "1E-", "-", "-a", "1.5e"}
+
+// TestStrictNumbersCustomParser 测试严格模式下自定义解析器拒绝不符合RFC 8259的数字
+// TestStrictNumbersCustomParser tests that the custom parser rejects non-RFC 8259 numbers in strict mode
+func TestStrictNumbersCustomParser(t *testing.T) {
+	strict := xyJson.NewCustomParserWithOptions(&xyJson.ParseOptions{StrictNumbers: true})
+	for _, num := range malformedNumbers {
+		var h numberHolder
+		assert.Error(t, strict.UnmarshalDirectString(`{"f":`+num+`}`, &h), num)
+		assert.Error(t, strict.UnmarshalDirectString(`{"x":`+num+`,"s":"ok"}`, &h), "skipped "+num)
+	}
+
+	lenient := xyJson.NewCustomParserWithOptions(nil)
+	var h numberHolder
+	require.NoError(t, lenient.UnmarshalDirectString(`{"f":1.,"i":01}`, &h))
+	assert.Equal(t, 1.0, h.F)
+	assert.Equal(t, int64(1), h.I)
+}
+
+// TestStrictNumbersValid 测试严格模式接受所有合法数字
+// TestStrictNumbersValid tests that strict mode accepts all valid numbers
+func TestStrictNumbersValid(t *testing.T) {
+	strict := xyJson.NewCustomParserWithOptions(&xyJson.ParseOptions{StrictNumbers: true})
+	cases := map[string]float64{
+		"0": 0, "-0": 0, "0.5": 0.5, "-0.5": -0.5, "10": 10, "1e3": 1000, "1E+3": 1000,
+		"2.5e-1": 0.25, "0e0": 0, "-12.75E1": -127.5,
+	}
+	for num, want := range cases {
+		var h numberHolder
+		require.NoError(t, strict.UnmarshalDirectString(`{"x":`+num+`,"f":`+num+`}`, &h), num)
+		assert.Equal(t, want, h.F, num)
+
+		value, err := xyJson.ParseString(num)
+		require.NoError(t, err, num)
+		assert.Equal(t, want, value.AsFloat64(), num)
+	}
+}
+
+// TestValueParserRejectsMalformedNumbers 测试值解析器总是拒绝不合法的数字
+// TestValueParserRejectsMalformedNumbers tests that the value parser always rejects malformed numbers
+func TestValueParserRejectsMalformedNumbers(t *testing.T) {
+	for _, num := range malformedNumbers {
+		_, err := xyJson.ParseString(num)
+		assert.Error(t, err, num)
+		_, err = xyJson.ParseString(`[` + num + `]`)
+		assert.Error(t, err, num)
+	}
+
+	_, err := xyJson.ParseString("01")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "leading zeros")
+}
+
+// TestUnmarshalStrictNumbers 测试Unmarshal总是严格校验数字，旧的自定义解析函数保持宽松
+// TestUnmarshalStrictNumbers tests that Unmarshal always checks numbers strictly while the legacy custom parser
+// function stays lenient
+func TestUnmarshalStrictNumbers(t *testing.T) {
+	var h numberHolder
+	opts := &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeDirect}
+	assert.Error(t, xyJson.UnmarshalString(`{"i":01}`, &h, opts))
+	require.NoError(t, xyJson.UnmarshalString(`{"i":10,"f":-0.5e1}`, &h, opts))
+	assert.Equal(t, int64(10), h.I)
+	assert.Equal(t, -5.0, h.F)
+
+	require.NoError(t, xyJson.UnmarshalToStructCustom([]byte(`{"i":01}`), &h))
+	assert.Equal(t, int64(1), h.I)
+}
+
+const zeroCopyJSON = `{"name": "Alice", "escaped": "a\nb", "tags": ["x", "y"], "nested": {"key": "value"}, "n": 42}`
+
+// TestParseZeroCopy 测试零拷贝解析与Detach
+// TestParseZeroCopy tests zero-copy parsing and Detach
+func TestParseZeroCopy(t *testing.T) {
+	buf := []byte(zeroCopyJSON)
+	root, err := xyJson.ParseZeroCopy(buf)
+	require.NoError(t, err)
+
+	expected := xyJson.MustSerializeToString(xyJson.MustParseString(zeroCopyJSON))
+	assert.Equal(t, expected, xyJson.MustSerializeToString(root))
+	assert.NoError(t, xyJson.VerifyZeroCopy(root))
+
+	detached := xyJson.Detach(root)
+	assert.Same(t, root, detached)
+
+	// Detach之后复用缓冲区不影响树
+	// Reusing the buffer after Detach does not affect the tree
+	copy(buf, bytes.Repeat([]byte{'#'}, len(buf)))
+	assert.Equal(t, expected, xyJson.MustSerializeToString(root))
+	assert.Equal(t, "value", xyJson.MustGetString(root, "$.nested.key"))
+	assert.NoError(t, xyJson.VerifyZeroCopy(root))
+
+	assert.Nil(t, xyJson.Detach(nil))
+	_, err = xyJson.ParseZeroCopy([]byte(`{"a": }`))
+	assert.Error(t, err)
+}
+
+// TestFileDocumentDetach 测试文件文档在Close之后通过Detach保留
+// TestFileDocumentDetach tests keeping a file document past Close via Detach
+func TestFileDocumentDetach(t *testing.T) {
+	doc, err := xyJson.ParseFile(testutil.CreateTempFile(t, zeroCopyJSON), nil)
+	require.NoError(t, err)
+
+	root := doc.Detach()
+	require.NoError(t, doc.Close())
+	assert.Nil(t, doc.Detach())
+
+	assert.Equal(t, "Alice", xyJson.MustGetString(root, "$.name"))
+	assert.Equal(t, "y", xyJson.MustGetString(root, "$.tags[1]"))
+}
+
+// TestFailureRecorder 测试默认快照和解析选项中的记录器保留失败的输入
+// TestFailureRecorder tests the recorder of the defaults and of ParseOptions keeping failed inputs
+func TestFailureRecorder(t *testing.T) {
+	var seen []xyJson.ParseFailure
+	recorder := xyJson.NewFailureRecorder(&xyJson.FailureRecorderOptions{
+		Capacity:       2,
+		MaxPayloadSize: 8,
+		OnFailure:      func(f xyJson.ParseFailure) { seen = append(seen, f) },
+	})
+	restore := xyJson.WithDefaults(xyJson.CurrentDefaults().WithFailureRecorder(recorder))
+	defer restore()
+	assert.Same(t, recorder, xyJson.CurrentDefaults().FailureRecorder())
+
+	buf := []byte(`{"a":`)
+	_, err := xyJson.Parse(buf)
+	require.Error(t, err)
+	buf[0] = 'X'
+	_, err = xyJson.ParseWithOptions([]byte(`[1,2,3,4,5,`), &xyJson.ParseOptions{FailureRecorder: recorder})
+	require.Error(t, err)
+	_, err = xyJson.NewParserWithFactory(xyJson.CurrentDefaults().Factory()).ParseString(`[`)
+	require.Error(t, err, "parsers used directly do not record")
+	_, err = xyJson.ParseString(`{"ok":true}`)
+	require.NoError(t, err)
+	_, err = xyJson.ParseString(`nul`)
+	require.Error(t, err)
+
+	assert.Equal(t, uint64(3), recorder.Total())
+	assert.Len(t, seen, 3)
+	assert.Equal(t, `{"a":`, string(seen[0].Data), "data is copied")
+	assert.NotNil(t, seen[0].Err)
+
+	failures := recorder.Failures()
+	require.Len(t, failures, 2)
+	assert.Equal(t, `[1,2,3,4`, string(failures[0].Data))
+	assert.Equal(t, 11, failures[0].Size)
+	assert.True(t, failures[0].Truncated())
+	assert.Equal(t, `nul`, string(failures[1].Data))
+	assert.False(t, failures[1].Truncated())
+
+	// 重放不会再次记录 Replaying does not record again
+	errs := recorder.Replay(nil)
+	require.Len(t, errs, 2)
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.Equal(t, uint64(3), recorder.Total())
+
+	recorder.Clear()
+	assert.Empty(t, recorder.Failures())
+	restore()
+	_, err = xyJson.ParseString(`{`)
+	require.Error(t, err)
+	assert.Equal(t, uint64(3), recorder.Total())
+}
+
+// TestFailureRecorderWriteTo 测试记录的NDJSON往返
+// TestFailureRecorderWriteTo tests the NDJSON round trip of records
+func TestFailureRecorderWriteTo(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	recorder := xyJson.NewFailureRecorder(&xyJson.FailureRecorderOptions{
+		Capacity: 10,
+		Clock:    func() time.Time { return now },
+	})
+	recorder.Record([]byte("{\"a\":\n"), xyJson.NewInvalidJSONError("unexpected end of input", nil))
+	recorder.Record([]byte{0xff, '{'}, nil)
+
+	var out bytes.Buffer
+	n, err := recorder.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"data":"{\"a\":\n"`)
+	assert.Contains(t, lines[1], `"base64":"/3s="`)
+
+	failures, err := xyJson.ReadParseFailures(&out)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "{\"a\":\n", string(failures[0].Data))
+	assert.True(t, now.Equal(failures[0].Time))
+	assert.Contains(t, failures[0].Err.Error(), "unexpected end of input")
+	assert.Equal(t, []byte{0xff, '{'}, failures[1].Data)
+	assert.Nil(t, failures[1].Err)
+
+	_, err = xyJson.ReadParseFailures(strings.NewReader("{\"data\":\"x\",\"size\":0}\n"))
+	assert.Error(t, err)
+	_, err = xyJson.ReadParseFailures(strings.NewReader("{\n"))
+	assert.Error(t, err)
+}
+
+// TestKeyTransformApply 测试键名风格转换
+// TestKeyTransformApply tests converting key naming styles
+func TestKeyTransformApply(t *testing.T) {
+	cases := map[string][3]string{
+		// key: camel, snake, kebab
+		"userId":        {"userId", "user_id", "user-id"},
+		"user_id":       {"userId", "user_id", "user-id"},
+		"user-id":       {"userId", "user_id", "user-id"},
+		"UserID":        {"userId", "user_id", "user-id"},
+		"HTTPServerURL": {"httpServerUrl", "http_server_url", "http-server-url"},
+		"address2Line":  {"address2Line", "address2_line", "address2-line"},
+		"created at":    {"createdAt", "created_at", "created-at"},
+		"_id":           {"_id", "_id", "_id"},
+		"__privateData": {"__privateData", "__private_data", "__private-data"},
+		"name":          {"name", "name", "name"},
+		"ÉtatCivil":     {"étatCivil", "état_civil", "état-civil"},
+		"":              {"", "", ""},
+		"___":           {"___", "___", "___"},
+		"double__under": {"doubleUnder", "double_under", "double-under"},
+	}
+	for key, expected := range cases {
+		assert.Equal(t, expected[0], xyJson.KeyTransformCamelCase.Apply(key), key)
+		assert.Equal(t, expected[1], xyJson.KeyTransformSnakeCase.Apply(key), key)
+		assert.Equal(t, expected[2], xyJson.KeyTransformKebabCase.Apply(key), key)
+		assert.Equal(t, key, xyJson.KeyTransformNone.Apply(key), key)
+	}
+	assert.Equal(t, "snake_case", xyJson.KeyTransformSnakeCase.String())
+}
+
+// TestKeyTransformSerialize 测试序列化时递归转换键名
+// TestKeyTransformSerialize tests converting keys recursively on serialize
+func TestKeyTransformSerialize(t *testing.T) {
+	doc := xyJson.MustParseString(`{"userId": 1, "profile": {"firstName": "Ann", "tags": [{"tagName": "x"}]}}`)
+
+	for _, iterative := range []bool{false, true} {
+		s := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+			Compact:      true,
+			SortKeys:     true,
+			MaxDepth:     xyJson.DefaultMaxDepth,
+			Iterative:    iterative,
+			KeyTransform: xyJson.KeyTransformSnakeCase,
+		})
+		data, err := s.Serialize(doc)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"user_id": 1, "profile": {"first_name": "Ann", "tags": [{"tag_name": "x"}]}}`, string(data))
+	}
+
+	// 文档本身不被修改
+	// The document itself is not modified
+	assert.True(t, xyJson.Exists(doc, "$.profile.firstName"))
+}
+
+// TestKeyTransformParse 测试解析时递归转换键名
+// TestKeyTransformParse tests converting keys recursively on parse
+func TestKeyTransformParse(t *testing.T) {
+	data := []byte(`{"user_id": 1, "profile": {"first_name": "Ann", "tags": [{"tag_name": "x"}]}}`)
+
+	for _, iterative := range []bool{false, true} {
+		doc, err := xyJson.ParseWithOptions(data, &xyJson.ParseOptions{
+			MaxDepth:     xyJson.DefaultMaxDepth,
+			Iterative:    iterative,
+			KeyTransform: xyJson.KeyTransformCamelCase,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), xyJson.MustGetInt64(doc, "$.userId"))
+		assert.Equal(t, "Ann", xyJson.MustGetString(doc, "$.profile.firstName"))
+		assert.Equal(t, "x", xyJson.MustGetString(doc, "$.profile.tags[0].tagName"))
+
+		// 转换后重复的键名报告为重复键
+		// Keys that collide after conversion are reported as duplicates
+		_, err = xyJson.ParseWithOptions([]byte(`{"user_id": 1, "userId": 2}`), &xyJson.ParseOptions{
+			MaxDepth:     xyJson.DefaultMaxDepth,
+			Iterative:    iterative,
+			KeyTransform: xyJson.KeyTransformCamelCase,
+		})
+		assert.Error(t, err)
+	}
+}
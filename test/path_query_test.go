@@ -1,6 +1,13 @@
 package test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -481,6 +488,116 @@ func TestJSONPathDelete(t *testing.T) {
 	})
 }
 
+// TestJSONPathNegativeIndex 测试负索引和last关键字在各操作中的一致语义
+// TestJSONPathNegativeIndex tests consistent negative index and last keyword semantics across operations
+func TestJSONPathNegativeIndex(t *testing.T) {
+	newDoc := func() xyJson.IValue {
+		return xyJson.MustParseString(`{"arr": [1, 2, 3], "rows": [{"v": 1}, {"v": 2}]}`)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		doc := newDoc()
+		assert.Equal(t, 3, xyJson.MustGetInt(doc, "$.arr[-1]"))
+		assert.Equal(t, 3, xyJson.MustGetInt(doc, "$.arr[last]"))
+		assert.Equal(t, 1, xyJson.MustGetInt(doc, "$.arr[-3]"))
+		assert.Equal(t, 2, xyJson.MustGetInt(doc, "$.rows[last].v"))
+		assert.False(t, xyJson.Exists(doc, "$.arr[-4]"))
+	})
+
+	t.Run("set", func(t *testing.T) {
+		doc := newDoc()
+		require.NoError(t, xyJson.Set(doc, "$.arr[-1]", 30))
+		require.NoError(t, xyJson.Set(doc, "$.rows[last].v", 20))
+		assert.Equal(t, "[1,2,30]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.arr")))
+		assert.Equal(t, 20, xyJson.MustGetInt(doc, "$.rows[1].v"))
+
+		// 超出范围的负索引不会扩展数组
+		// Out-of-range negative indices never grow the array
+		err := xyJson.Set(doc, "$.arr[-4]", 0)
+		assert.Error(t, err)
+		assert.Equal(t, 3, xyJson.Count(doc, "$.arr[*]"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		doc := newDoc()
+		require.NoError(t, xyJson.Delete(doc, "$.arr[last]"))
+		require.NoError(t, xyJson.Delete(doc, "$.arr[-2]"))
+		assert.Equal(t, "[2]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.arr")))
+		assert.Error(t, xyJson.Delete(doc, "$.arr[-2]"))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		doc := newDoc()
+		require.NoError(t, xyJson.Set(doc, "$.arr[-1:]", 4))
+		require.NoError(t, xyJson.Set(doc, "$.rows[-1:].v", 3))
+		require.NoError(t, xyJson.Set(doc, "$.fresh[-1:]", "x"))
+		assert.Equal(t, "[1,2,3,4]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.arr")))
+		assert.Equal(t, 3, xyJson.MustGetInt(doc, "$.rows[2].v"))
+		assert.Equal(t, `["x"]`, xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.fresh")))
+
+		// 读取时[-1:]指向最后一个元素
+		// On read [-1:] refers to the last element
+		assert.Equal(t, 4, xyJson.MustGetInt(doc, "$.arr[-1:]"))
+		assert.Equal(t, "$.arr[-1]", xyJson.Q().Key("arr").Last().String())
+	})
+}
+
+// TestJSONPathAppend 测试[+]追加语义和严格数组索引选项
+// TestJSONPathAppend tests [+] append semantics and the strict array index option
+func TestJSONPathAppend(t *testing.T) {
+	t.Run("append_segment", func(t *testing.T) {
+		doc := xyJson.MustParseString(`{"items": [1], "rows": []}`)
+		require.NoError(t, xyJson.Set(doc, "$.items[+]", 2))
+		require.NoError(t, xyJson.Set(doc, "$.rows[+].id", 7))
+		require.NoError(t, xyJson.Set(doc, "$.matrix[+][+]", 1))
+		assert.Equal(t, "[1,2]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.items")))
+		assert.Equal(t, 7, xyJson.MustGetInt(doc, "$.rows[0].id"))
+		assert.Equal(t, "[[1]]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.matrix")))
+	})
+
+	t.Run("append_path", func(t *testing.T) {
+		doc := xyJson.MustParseString(`{"items": ["a"]}`)
+		require.NoError(t, xyJson.AppendPath(doc, "$.items", "b"))
+		require.NoError(t, xyJson.AppendPath(doc, "$.created", true))
+		assert.Equal(t, `["a","b"]`, xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.items")))
+		assert.True(t, xyJson.MustGetBool(doc, "$.created[0]"))
+
+		assert.Error(t, xyJson.AppendPath(doc, "$.items[0]", "c"))
+	})
+
+	t.Run("query_builder", func(t *testing.T) {
+		doc := xyJson.MustParseString(`{"items": []}`)
+		q := xyJson.Q().Key("items").Append()
+		assert.Equal(t, "$.items[+]", q.String())
+		require.NoError(t, q.Set(doc, 1))
+		require.NoError(t, q.Set(doc, 2))
+		assert.Equal(t, 2, xyJson.Count(doc, "$.items[*]"))
+	})
+
+	t.Run("strict_array_index", func(t *testing.T) {
+		// 在当前配置的副本上只修改StrictArrayIndex，性能监控等其他组件的设置保持不变
+		// Only StrictArrayIndex changes on a copy of the current config, other settings such as monitoring stay
+		// as they are
+		original := xyJson.GetGlobalConfig()
+		defer xyJson.SetGlobalConfig(original)
+		strict := *original
+		strict.JSONPath.StrictArrayIndex = true
+		xyJson.SetGlobalConfig(&strict)
+
+		doc := xyJson.MustParseString(`{"items": [1, 2]}`)
+		require.NoError(t, xyJson.Set(doc, "$.items[1]", 20))
+		require.NoError(t, xyJson.Set(doc, "$.items[2]", 3))
+		assert.Error(t, xyJson.Set(doc, "$.items[10]", 4))
+		assert.Equal(t, 3, xyJson.Count(doc, "$.items[*]"))
+
+		// 默认配置下使用null填充
+		// The default configuration pads with nulls
+		xyJson.SetGlobalConfig(original)
+		require.NoError(t, xyJson.Set(doc, "$.items[5]", 6))
+		assert.Equal(t, "[1,20,3,null,null,6]", xyJson.MustSerializeToString(xyJson.MustGet(doc, "$.items")))
+	})
+}
+
 // TestJSONPathRecursiveDescent 测试递归下降查询
 // TestJSONPathRecursiveDescent tests recursive descent queries
 func TestJSONPathRecursiveDescent(t *testing.T) {
@@ -704,3 +821,850 @@ func TestJSONPathCustomFactory(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 }
+
+// TestFindPaths 测试返回谓词匹配节点的JSONPath并可用Get取回
+// TestFindPaths tests returning the JSONPaths of matching nodes that Get can resolve
+func TestFindPaths(t *testing.T) {
+	doc := xyJson.MustParseString(`{"b":{"long":"xxxxxxxx","short":"x"},"a":[1,"yyyyyyyyyy",{"k.v":"zzzzzzzz"}]}`)
+
+	paths := xyJson.FindPaths(doc, func(v xyJson.IValue) bool {
+		return v.Type() == xyJson.StringValueType && len(v.AsString()) > 5
+	})
+	assert.Equal(t, []string{"$.a[1]", "$.a[2].'k.v'", "$.b.long"}, paths)
+	for _, path := range paths {
+		value, err := xyJson.Get(doc, path)
+		require.NoError(t, err, path)
+		assert.Greater(t, len(value.AsString()), 5)
+	}
+
+	root := xyJson.FindPaths(doc, func(v xyJson.IValue) bool { return v.Type() == xyJson.ObjectValueType })
+	assert.Equal(t, []string{"$", "$.a[2]", "$.b"}, root)
+
+	assert.Empty(t, xyJson.FindPaths(doc, func(xyJson.IValue) bool { return false }))
+	assert.Nil(t, xyJson.FindPaths(nil, func(xyJson.IValue) bool { return true }))
+	assert.Nil(t, xyJson.FindPaths(doc, nil))
+}
+
+// TestFindPathsNaN 测试查找NaN数字
+// TestFindPathsNaN tests finding NaN numbers
+func TestFindPathsNaN(t *testing.T) {
+	doc := xyJson.CreateObject()
+	values := xyJson.CreateArray()
+	values.Append(1.5)
+	values.Append(math.NaN())
+	doc.Set("values", values)
+
+	paths := xyJson.FindPaths(doc, func(v xyJson.IValue) bool {
+		return v.Type() == xyJson.NumberValueType && math.IsNaN(v.AsFloat64())
+	})
+	assert.Equal(t, []string{"$.values[1]"}, paths)
+}
+
+// TestEscapeKey 测试键名转义
+// TestEscapeKey tests key escaping
+func TestEscapeKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"name", "name"},
+		{"first-name", "first-name"},
+		{"a.b", "'a.b'"},
+		{"weird[key]", "'weird[key]'"},
+		{"with space", "'with space'"},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{"", "''"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, xyJson.EscapeKey(tt.key), tt.key)
+	}
+}
+
+// TestBuildPath 测试路径构建以及与解析器的往返
+// TestBuildPath tests path building and the round trip through the parser
+func TestBuildPath(t *testing.T) {
+	assert.Equal(t, "$", xyJson.BuildPath())
+	assert.Equal(t, "$.users[0].name", xyJson.BuildPath("users", 0, "name"))
+	assert.Equal(t, "$.'a.b'[2].'weird[key]'", xyJson.BuildPath("a.b", int64(2), "weird[key]"))
+
+	root := xyJson.MustParseString(`{
+		"a.b": {"weird[key]": 1},
+		"with space": {"it's": "quote", "say \"hi\"": "double"},
+		"back\\slash": [10, 20]
+	}`)
+
+	t.Run("get", func(t *testing.T) {
+		assert.Equal(t, 1, xyJson.MustGetInt(root, xyJson.BuildPath("a.b", "weird[key]")))
+		assert.Equal(t, "quote", xyJson.MustGetString(root, xyJson.BuildPath("with space", "it's")))
+		assert.Equal(t, "double", xyJson.MustGetString(root, xyJson.BuildPath("with space", `say "hi"`)))
+		assert.Equal(t, 20, xyJson.MustGetInt(root, xyJson.BuildPath(`back\slash`, 1)))
+	})
+
+	t.Run("set", func(t *testing.T) {
+		doc := xyJson.MustParseString(`{}`)
+		path := xyJson.BuildPath("x.y", "z]")
+		require.NoError(t, xyJson.Set(doc, path, "ok"))
+		assert.Equal(t, "ok", xyJson.MustGetString(doc, path))
+
+		obj := doc.(xyJson.IObject).Get("x.y").(xyJson.IObject)
+		assert.Equal(t, "ok", obj.Get("z]").String())
+	})
+
+	t.Run("quoted_dot_notation", func(t *testing.T) {
+		value, err := xyJson.Get(root, `$."a.b"."weird[key]"`)
+		require.NoError(t, err)
+		assert.Equal(t, 1, value.AsInt())
+
+		values, err := xyJson.GetAll(root, `$..'weird[key]'`)
+		require.NoError(t, err)
+		assert.Len(t, values, 1)
+
+		_, err = xyJson.Get(root, `$.'unterminated`)
+		assert.Error(t, err)
+	})
+}
+
+// TestBracketQuotedKeys 测试方括号引号键名及转义序列
+// TestBracketQuotedKeys tests bracket-quoted keys and escape sequences
+func TestBracketQuotedKeys(t *testing.T) {
+	root := xyJson.MustParseString(`{
+		"a.b": {"c[0]": "dots and brackets"},
+		"it's": "single",
+		"back\\slash": "backslash",
+		"名字": {"城市": "北京"},
+		"émoji 🚀": true,
+		"items": [{"name": "a]b"}, {"name": "c"}]
+	}`)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"dots_and_brackets", `$['a.b']['c[0]']`, "dots and brackets"},
+		{"escaped_single_quote", `$['it\'s']`, "single"},
+		{"double_quoted", `$["it's"]`, "single"},
+		{"escaped_backslash", `$['back\\slash']`, "backslash"},
+		{"unicode_dot", `$.名字.城市`, "北京"},
+		{"unicode_bracket", `$['名字']["城市"]`, "北京"},
+		{"unicode_built", xyJson.BuildPath("名字", "城市"), "北京"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := xyJson.Get(root, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value.String())
+		})
+	}
+
+	t.Run("unicode_with_space", func(t *testing.T) {
+		path := xyJson.BuildPath("émoji 🚀")
+		assert.Equal(t, "$.'émoji 🚀'", path)
+		assert.True(t, xyJson.MustGetBool(root, path))
+		assert.True(t, xyJson.MustGetBool(root, `$['émoji 🚀']`))
+	})
+
+	t.Run("bracket_inside_filter_string", func(t *testing.T) {
+		values, err := xyJson.GetAll(root, `$.items[?(@.name == 'a]b')].name`)
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		assert.Equal(t, "a]b", values[0].String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, path := range []string{`$['unterminated]`, `$['a'x]`, `$['a\`} {
+			_, err := xyJson.Get(root, path)
+			assert.Error(t, err, path)
+		}
+	})
+}
+
+const queryBuilderJSON = `{
+	"store": {
+		"book": [
+			{"title": "Go", "price": 30},
+			{"title": "Rust", "price": 45}
+		]
+	},
+	"users": [
+		{"name": "Alice", "age": 22},
+		{"name": "Bob", "age": 31},
+		{"name": "Carol", "age": 40}
+	],
+	"a.b": {"weird[key]": 1}
+}`
+
+// TestQueryBuilder 测试查询构建器
+// TestQueryBuilder tests the query builder
+func TestQueryBuilder(t *testing.T) {
+	root := xyJson.MustParseString(queryBuilderJSON)
+
+	t.Run("key_and_index", func(t *testing.T) {
+		q := xyJson.Q().Key("store").Key("book").Index(0).Key("title")
+		assert.Equal(t, "$.store.book[0].title", q.String())
+
+		value, err := q.Get(root)
+		require.NoError(t, err)
+		assert.Equal(t, "Go", value.String())
+
+		last, err := xyJson.Q().Key("store").Key("book").Index(-1).Key("title").Get(root)
+		require.NoError(t, err)
+		assert.Equal(t, "Rust", last.String())
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		q := xyJson.Q().Key("users").Filter(xyJson.Field("age").Gt(25)).Key("name")
+		assert.Equal(t, "$.users[?(@.age > 25)].name", q.String())
+
+		names, err := q.GetAll(root)
+		require.NoError(t, err)
+		require.Len(t, names, 2)
+		assert.Equal(t, "Bob", names[0].String())
+		assert.Equal(t, "Carol", names[1].String())
+
+		// 与字符串路径结果一致
+		// Same result as the string path
+		expected, err := xyJson.GetAll(root, q.String())
+		require.NoError(t, err)
+		assert.Equal(t, len(expected), len(names))
+
+		eq, err := xyJson.Q().Key("users").Filter(xyJson.Field("name").Eq("Alice")).Key("age").Get(root)
+		require.NoError(t, err)
+		assert.Equal(t, 22, eq.AsInt())
+	})
+
+	t.Run("wildcards", func(t *testing.T) {
+		titles, err := xyJson.Q().Key("store").Key("book").All().Key("title").GetAll(root)
+		require.NoError(t, err)
+		assert.Len(t, titles, 2)
+
+		prices, err := xyJson.Q().Descendant("price").GetAll(root)
+		require.NoError(t, err)
+		assert.Len(t, prices, 2)
+		assert.Equal(t, "$..price", xyJson.Q().Descendant("price").String())
+	})
+
+	t.Run("special_keys", func(t *testing.T) {
+		// 键名直接存储在路径段中，不受特殊字符影响
+		// Keys are stored directly in segments and are not affected by special characters
+		value, err := xyJson.Q().Key("a.b").Key("weird[key]").Get(root)
+		require.NoError(t, err)
+		assert.Equal(t, 1, value.AsInt())
+	})
+
+	t.Run("mutation", func(t *testing.T) {
+		doc := xyJson.MustParseString(queryBuilderJSON)
+		require.NoError(t, xyJson.Q().Key("meta").Key("version").Set(doc, 2))
+		assert.Equal(t, 2, xyJson.MustGetInt(doc, "$.meta.version"))
+		assert.True(t, xyJson.Q().Key("meta").Exists(doc))
+
+		require.NoError(t, xyJson.Q().Key("meta").Delete(doc))
+		assert.False(t, xyJson.Q().Key("meta").Exists(doc))
+	})
+
+	t.Run("compile", func(t *testing.T) {
+		compiled, err := xyJson.Q().Key("users").All().Compile()
+		require.NoError(t, err)
+		assert.Equal(t, 3, compiled.Count(root))
+		assert.Equal(t, "$.users[*]", compiled.Path())
+
+		rootPath := xyJson.Q().MustCompile()
+		value, err := rootPath.Query(root)
+		require.NoError(t, err)
+		assert.Equal(t, root, value)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		q := xyJson.Q().Key("").Key("x")
+		assert.Error(t, q.Err())
+		_, err := q.Get(root)
+		assert.Error(t, err)
+		assert.Panics(t, func() { q.MustCompile() })
+
+		_, err = xyJson.Q().Filter(nil).Compile()
+		assert.Error(t, err)
+	})
+}
+
+// pagingDoc 创建包含5组、每组7个条目的测试文档
+// pagingDoc creates a test document with 5 groups of 7 items each
+func pagingDoc(t *testing.T) xyJson.IValue {
+	doc, err := xyJson.ParseString(`{"groups":` + testutil.IndexedArray(5, func(g int) string {
+		return `{"items":` + testutil.IndexedArray(7, func(i int) string {
+			return fmt.Sprintf(`{"id":%d,"odd":%t}`, g*7+i, i%2 == 1)
+		}) + `}`
+	}) + `}`)
+	require.NoError(t, err)
+	return doc
+}
+
+// TestSelectAllWithOptionsMatchesSelectAll 测试分页结果与SelectAll的对应切片一致
+// TestSelectAllWithOptionsMatchesSelectAll tests that paged results equal the matching slice of SelectAll
+func TestSelectAllWithOptionsMatchesSelectAll(t *testing.T) {
+	doc := pagingDoc(t)
+	paths := []string{"$.groups[*].items[*].id", "$..id", "$.groups[*].items[?(@.odd == true)]", "$.groups[1].items[*]"}
+
+	for _, path := range paths {
+		all, err := xyJson.GetAll(doc, path)
+		require.NoError(t, err)
+
+		for _, opts := range []xyJson.QueryOptions{{}, {Limit: 3}, {Offset: 4, Limit: 5}, {Offset: 10}, {Offset: 1000, Limit: 2}} {
+			page, err := xyJson.SelectAllWithOptions(doc, path, &opts)
+			require.NoError(t, err)
+
+			expected := all
+			if opts.Offset < len(expected) {
+				expected = expected[opts.Offset:]
+			} else {
+				expected = nil
+			}
+			if opts.Limit > 0 && opts.Limit < len(expected) {
+				expected = expected[:opts.Limit]
+			}
+			require.Len(t, page, len(expected), "%s %+v", path, opts)
+			for i := range expected {
+				assert.Same(t, expected[i], page[i], "%s %+v", path, opts)
+			}
+		}
+	}
+}
+
+// TestQueryAllWithOptions 测试预编译路径分页、根路径和无效选项
+// TestQueryAllWithOptions tests paging with compiled paths, the root path and invalid options
+func TestQueryAllWithOptions(t *testing.T) {
+	doc := pagingDoc(t)
+	path, err := xyJson.CompilePath("$.groups[*].items[*].id")
+	require.NoError(t, err)
+
+	page, err := path.QueryAllWithOptions(doc, &xyJson.QueryOptions{Offset: 6, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	assert.Equal(t, []int{6, 7, 8}, []int{page[0].AsInt(), page[1].AsInt(), page[2].AsInt()})
+
+	page, err = xyJson.SelectAllWithOptions(doc, "$", nil)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Same(t, doc, page[0])
+
+	_, err = xyJson.SelectAllWithOptions(doc, "$.groups[*]", &xyJson.QueryOptions{Limit: -1})
+	assert.Error(t, err)
+	_, err = path.QueryAllWithOptions(doc, &xyJson.QueryOptions{Offset: -1})
+	assert.Error(t, err)
+	_, err = xyJson.SelectAllWithOptions(doc, "groups", nil)
+	assert.Error(t, err)
+}
+
+// TestSelectAllWithOptionsStopsEarly 测试取满一页后不再访问剩余节点，包括递归下降
+// TestSelectAllWithOptionsStopsEarly tests that no further nodes are visited once the page is full, recursive
+// descent included
+func TestSelectAllWithOptionsStopsEarly(t *testing.T) {
+	visited := 0
+	require.NoError(t, xyJson.RegisterFilterFunction("countVisit", func(xyJson.IValue, []xyJson.IValue) bool {
+		visited++
+		return true
+	}))
+	doc := pagingDoc(t)
+
+	for _, path := range []string{"$.groups[*].items[?(@.countVisit())]", "$..items[?(@.countVisit())]"} {
+		visited = 0
+		page, err := xyJson.SelectAllWithOptions(doc, path, &xyJson.QueryOptions{Offset: 2, Limit: 3})
+		require.NoError(t, err, path)
+		require.Len(t, page, 3, path)
+		assert.Equal(t, 2, page[0].(xyJson.IObject).Get("id").AsInt(), path)
+		assert.Equal(t, 5, visited, path)
+	}
+}
+
+const streamQueryJSON = `{
+	"store": {
+		"book": [
+			{"title": "Go", "price": 30, "tags": ["a", "b"]},
+			{"title": "Rust", "price": 45, "tags": []},
+			{"title": "Zig", "price": 12, "meta": {"price": 1}}
+		],
+		"bicycle": {"color": "red", "price": 199.5}
+	},
+	"items": [{"id": 1}, {"id": 2, "items": [{"id": 3}]}],
+	"escaped\"key": {"abc": true}
+}`
+
+// collectStream 收集流式查询的结果并排序
+// collectStream collects and sorts the results of a streaming query
+func collectStream(t *testing.T, input, path string) []string {
+	var results []string
+	err := xyJson.QueryStream(strings.NewReader(input), path, func(value xyJson.IValue) bool {
+		results = append(results, xyJson.MustSerializeToString(value))
+		return true
+	})
+	require.NoError(t, err, path)
+	sort.Strings(results)
+	return results
+}
+
+// TestQueryStream 测试流式查询与内存查询结果一致
+// TestQueryStream tests that streaming queries match in-memory queries
+func TestQueryStream(t *testing.T) {
+	root := xyJson.MustParseString(streamQueryJSON)
+
+	paths := []string{
+		"$",
+		"$.store.book[0].title",
+		"$.store.book[*].title",
+		"$.store.book[-1].title",
+		"$.store.book[last]",
+		"$.store.*",
+		"$..price",
+		"$..items[*].id",
+		"$..*",
+		"$.store.book[?(@.price > 20)].title",
+		"$.store.book[?(@.price < 20)]..price",
+		"$['escaped\"key'].abc",
+		"$.missing.path",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			expected, err := xyJson.GetAll(root, path)
+			require.NoError(t, err)
+			var want []string
+			for _, value := range expected {
+				want = append(want, xyJson.MustSerializeToString(value))
+			}
+			sort.Strings(want)
+
+			assert.Equal(t, want, collectStream(t, streamQueryJSON, path))
+		})
+	}
+}
+
+// TestQueryStreamBehavior 测试流式查询的停止、多文档和错误处理
+// TestQueryStreamBehavior tests stopping, multiple documents and error handling of streaming queries
+func TestQueryStreamBehavior(t *testing.T) {
+	t.Run("early_stop", func(t *testing.T) {
+		count := 0
+		err := xyJson.QueryStream(strings.NewReader(streamQueryJSON), "$..price", func(xyJson.IValue) bool {
+			count++
+			return false
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		input := "{\"id\": 1}\n{\"id\": 2}\n  {\"other\": 3}\n"
+		assert.Equal(t, []string{"1", "2"}, collectStream(t, input, "$.id"))
+	})
+
+	t.Run("large_stream", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		go func() {
+			fmt.Fprint(pw, `{"export": {"items": [`)
+			for i := 0; i < 10000; i++ {
+				if i > 0 {
+					fmt.Fprint(pw, ",")
+				}
+				fmt.Fprintf(pw, `{"id": %d, "payload": {"blob": "%s"}}`, i, strings.Repeat("x", 64))
+			}
+			fmt.Fprint(pw, `]}}`)
+			pw.Close()
+		}()
+
+		sum := 0
+		err := xyJson.QueryStream(pr, "$..items[*].id", func(value xyJson.IValue) bool {
+			sum += value.(xyJson.IScalarValue).AsInt()
+			return true
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 9999*10000/2, sum)
+	})
+
+	t.Run("compiled_path", func(t *testing.T) {
+		compiled, err := xyJson.CompilePath("$.items[*].id")
+		require.NoError(t, err)
+		var ids []int
+		err = compiled.QueryStream(strings.NewReader(streamQueryJSON), func(value xyJson.IValue) bool {
+			ids = append(ids, value.(xyJson.IScalarValue).AsInt())
+			return true
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, ids)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		noop := func(xyJson.IValue) bool { return true }
+		assert.Error(t, xyJson.QueryStream(strings.NewReader(`{"a": [1, 2`), "$.a[*]", noop))
+		assert.Error(t, xyJson.QueryStream(strings.NewReader(`{"a" 1}`), "$.a", noop))
+		assert.Error(t, xyJson.QueryStream(strings.NewReader(`{"a": tru}`), "$.a", noop))
+		assert.Error(t, xyJson.QueryStream(strings.NewReader(`{}`), "invalid", noop))
+		assert.Error(t, xyJson.QueryStream(nil, "$", noop))
+		assert.Error(t, xyJson.QueryStream(strings.NewReader(`{}`), "$", nil))
+		assert.NoError(t, xyJson.QueryStream(strings.NewReader(""), "$", noop))
+	})
+}
+
+// TestInspectAndForward 测试原样转发时提取路径值
+// TestInspectAndForward tests extracting path values while forwarding unchanged
+func TestInspectAndForward(t *testing.T) {
+	input := "{ \"user\" : {\"id\": 7, \"name\":\"a\\u0062\"},\n\t\"items\":[ {\"sku\":\"x\",\"qty\":1.50}, {\"sku\":\"y\",\"qty\":3} ] }\n" +
+		"{\"user\":{\"id\":8},\"items\":[]}\n"
+	paths := []string{"$.user.id", "$.items[?(@.qty > 2)].sku", "$..sku", "$.user.id"}
+
+	var out bytes.Buffer
+	var got []string
+	err := xyJson.InspectAndForward(strings.NewReader(input), &out, paths, func(path string, v xyJson.IValue) {
+		got = append(got, path+"="+v.String())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, input, out.String(), "the output is byte-identical")
+	assert.ElementsMatch(t, []string{
+		"$.user.id=7", "$.user.id=7", "$..sku=x", "$..sku=y", "$.items[?(@.qty > 2)].sku=y",
+		"$.user.id=8", "$.user.id=8",
+	}, got)
+
+	// 没有路径时只转发 Only forwarding without paths
+	out.Reset()
+	require.NoError(t, xyJson.InspectAndForward(strings.NewReader(input), &out, nil, func(string, xyJson.IValue) {
+		t.Fatal("unexpected match")
+	}))
+	assert.Equal(t, input, out.String())
+}
+
+// TestInspectAndForwardErrors 测试转发时的错误处理
+// TestInspectAndForwardErrors tests error handling while forwarding
+func TestInspectAndForwardErrors(t *testing.T) {
+	noop := func(string, xyJson.IValue) {}
+
+	// 非法JSON仍被完整转发 Malformed JSON is still forwarded in full
+	input := `{"a":1,"b":[1,2,}` + strings.Repeat(" trailing data", 1000)
+	var out bytes.Buffer
+	var seen []string
+	err := xyJson.InspectAndForward(strings.NewReader(input), &out, []string{"$.a"}, func(path string, v xyJson.IValue) {
+		seen = append(seen, v.String())
+	})
+	assert.Error(t, err)
+	assert.Equal(t, input, out.String())
+	assert.Equal(t, []string{"1"}, seen)
+
+	// 非法路径在写入前被拒绝 Invalid paths are rejected before anything is written
+	out.Reset()
+	err = xyJson.InspectAndForward(strings.NewReader(`{"a":1}`), &out, []string{"$.a", "$[?("}, noop)
+	assert.Error(t, err)
+	assert.Zero(t, out.Len())
+
+	err = xyJson.InspectAndForward(strings.NewReader(`{"a":1}`), testutil.FailingWriter{}, []string{"$.a"}, noop)
+	assert.EqualError(t, err, "write failed")
+
+	assert.Error(t, xyJson.InspectAndForward(nil, &out, nil, noop))
+	assert.Error(t, xyJson.InspectAndForward(strings.NewReader(""), nil, nil, noop))
+	assert.Error(t, xyJson.InspectAndForward(strings.NewReader(""), &out, nil, nil))
+}
+
+// TestSetRawBytesReplace 测试替换已有的值并保留其余字节
+// TestSetRawBytesReplace tests replacing existing values while keeping every other byte
+func TestSetRawBytesReplace(t *testing.T) {
+	data := []byte(`{ "order": { "status": "new",  "items": [ {"id": 1}, {"id": 2} ] }, "key": 0 }`)
+	original := string(data)
+
+	cases := []struct {
+		path     string
+		raw      string
+		expected string
+	}{
+		{"$.order.status", `"shipped"`, `{ "order": { "status": "shipped",  "items": [ {"id": 1}, {"id": 2} ] }, "key": 0 }`},
+		{"$.order.items[1].id", ` 20 `, `{ "order": { "status": "new",  "items": [ {"id": 1}, {"id": 20} ] }, "key": 0 }`},
+		{"$.order.items[-2]", `null`, `{ "order": { "status": "new",  "items": [ null, {"id": 2} ] }, "key": 0 }`},
+		{"$.key", `[true]`, `{ "order": { "status": "new",  "items": [ {"id": 1}, {"id": 2} ] }, "key": [true] }`},
+		{"$['order']['items']", `[]`, `{ "order": { "status": "new",  "items": [] }, "key": 0 }`},
+		{"$", `{"a":1}`, `{"a":1}`},
+	}
+	for _, c := range cases {
+		out, err := xyJson.SetRawBytes(data, c.path, []byte(c.raw))
+		require.NoError(t, err, c.path)
+		assert.Equal(t, c.expected, string(out), c.path)
+	}
+	assert.Equal(t, original, string(data))
+}
+
+// TestSetRawBytesInsert 测试创建缺失的属性、元素和中间容器
+// TestSetRawBytesInsert tests creating missing properties, elements and intermediate containers
+func TestSetRawBytesInsert(t *testing.T) {
+	cases := []struct {
+		data     string
+		path     string
+		expected string
+	}{
+		{`{}`, "$.a", `{"a":1}`},
+		{`{"b":2}`, "$.a", `{"b":2,"a":1}`},
+		{`{"b":2}`, "$.a.b[0]", `{"b":2,"a":{"b":[1]}}`},
+		{`{"b":2}`, `$["x\"y"]`, `{"b":2,"x\"y":1}`},
+		{`[]`, "$[+]", `[1]`},
+		{`[0 ]`, "$[+]", `[0 ,1]`},
+		{`[0]`, "$[3]", `[0,null,null,1]`},
+	}
+	for _, c := range cases {
+		out, err := xyJson.SetRawBytes([]byte(c.data), c.path, []byte(`1`))
+		require.NoError(t, err, c.path)
+		assert.Equal(t, c.expected, string(out), c.path)
+
+		// 与Set的结果一致
+		// Agrees with Set
+		root := xyJson.MustParseString(c.data)
+		require.NoError(t, xyJson.Set(root, c.path, xyJson.CreateNumber(1)), c.path)
+		assert.True(t, root.Equals(xyJson.MustParse(out)), c.path)
+	}
+
+	out, err := xyJson.SetRawBytes([]byte(`{"a":[]}`), "$.a[1].b", []byte(`1`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[null,{"b":1}]}`, string(out))
+}
+
+// TestSetRawBytesErrors 测试错误情况
+// TestSetRawBytesErrors tests error cases
+func TestSetRawBytesErrors(t *testing.T) {
+	data := []byte(`{"a": [1, 2], "s": "x"}`)
+
+	_, err := xyJson.SetRawBytes(data, "$.a", []byte(`{`))
+	assert.Error(t, err)
+	_, err = xyJson.SetRawBytes(data, "$.a[*]", []byte(`1`))
+	assert.Error(t, err)
+	_, err = xyJson.SetRawBytes(data, "$..a", []byte(`1`))
+	assert.Error(t, err)
+	_, err = xyJson.SetRawBytes(data, "$.a[-3]", []byte(`1`))
+	assert.Error(t, err)
+
+	_, err = xyJson.SetRawBytes(data, "$.s.b", []byte(`1`))
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr))
+	assert.Equal(t, xyJson.ErrTypeMismatch, jsonErr.Code)
+	_, err = xyJson.SetRawBytes(data, "$.a.b", []byte(`1`))
+	assert.Error(t, err)
+
+	_, err = xyJson.SetRawBytes([]byte(`{"a": [1, `), "$.a[5]", []byte(`1`))
+	assert.Error(t, err)
+	_, err = xyJson.SetRawBytes(nil, "$.a", []byte(`1`))
+	assert.Error(t, err)
+}
+
+// BenchmarkSetRawBytes 对大文档做单字段改写
+// BenchmarkSetRawBytes rewrites a single field of a large document
+func BenchmarkSetRawBytes(b *testing.B) {
+	arr := xyJson.CreateArray()
+	for i := 0; i < 1000; i++ {
+		obj := xyJson.CreateObject()
+		obj.Set("id", xyJson.CreateNumber(i))
+		obj.Set("name", xyJson.CreateString("item"))
+		arr.Append(obj)
+	}
+	root := xyJson.CreateObject()
+	root.Set("items", arr)
+	root.Set("status", xyJson.CreateString("new"))
+	data := xyJson.MustSerialize(root)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := xyJson.SetRawBytes(data, "$.status", []byte(`"done"`)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// orderTotal 计算订单总价
+// orderTotal computes the total price of an order
+func orderTotal(root xyJson.IValue) xyJson.IValue {
+	total := 0.0
+	items, _ := xyJson.GetAll(root, "$.items[*]")
+	for _, item := range items {
+		total += xyJson.MustGetFloat64(item, "$.price") * xyJson.MustGetFloat64(item, "$.qty")
+	}
+	return xyJson.CreateNumber(total)
+}
+
+// TestDefineVirtual 测试虚拟路径惰性求值
+// TestDefineVirtual tests that virtual paths are evaluated lazily
+func TestDefineVirtual(t *testing.T) {
+	order := xyJson.MustParseString(`{"items": [{"price": 2.5, "qty": 2}, {"price": 1, "qty": 3}], "total": 0}`)
+
+	calls := 0
+	require.NoError(t, xyJson.DefineVirtual(order, "$.totalPrice", func(root xyJson.IValue) xyJson.IValue {
+		calls++
+		return orderTotal(root)
+	}))
+	assert.Zero(t, calls)
+
+	total, err := xyJson.GetFloat64(order, "$.totalPrice")
+	require.NoError(t, err)
+	assert.Equal(t, 8.0, total)
+	assert.Equal(t, 1, calls)
+
+	// 每次查询重新求值
+	// Every query evaluates again
+	require.NoError(t, xyJson.Set(order, "$.items[0].qty", xyJson.CreateNumber(4)))
+	assert.Equal(t, 13.0, xyJson.MustGetFloat64(order, "$['totalPrice']"))
+	assert.True(t, xyJson.Exists(order, "$.totalPrice"))
+	assert.Equal(t, 1, xyJson.Count(order, "$.totalPrice"))
+
+	compiled, err := xyJson.CompilePath("$.totalPrice")
+	require.NoError(t, err)
+	value, err := compiled.Query(order)
+	require.NoError(t, err)
+	assert.Equal(t, 13.0, value.AsFloat64())
+
+	// 虚拟路径不参与序列化和通配符
+	// Virtual paths take no part in serialization or wildcards
+	assert.NotContains(t, xyJson.MustSerializeToString(order), "totalPrice")
+	assert.Equal(t, 2, xyJson.Count(order, "$.*"))
+	assert.Equal(t, []string{"$.totalPrice"}, xyJson.VirtualPaths(order))
+}
+
+// TestDefineVirtualNested 测试虚拟路径的前缀匹配、覆盖和删除
+// TestDefineVirtualNested tests prefix matching, shadowing and removal of virtual paths
+func TestDefineVirtualNested(t *testing.T) {
+	doc := xyJson.MustParseString(`{"scores": [3, 5, 10], "stats": {"max": -1}}`)
+
+	require.NoError(t, xyJson.DefineVirtual(doc, "$.stats", func(root xyJson.IValue) xyJson.IValue {
+		stats := xyJson.CreateObject()
+		scores, _ := xyJson.GetAll(root, "$.scores[*]")
+		stats.Set("count", xyJson.CreateNumber(len(scores)))
+		stats.Set("max", scores[len(scores)-1])
+		return stats
+	}))
+	require.NoError(t, xyJson.DefineVirtual(doc, "$.stats.label", func(xyJson.IValue) xyJson.IValue {
+		return xyJson.CreateString("scores")
+	}))
+	require.NoError(t, xyJson.DefineVirtual(doc, "$.missing", func(xyJson.IValue) xyJson.IValue { return nil }))
+
+	assert.Equal(t, int64(10), xyJson.MustGetInt64(doc, "$.stats.max"))
+	assert.Equal(t, int64(3), xyJson.MustGetInt64(doc, "$.stats.count"))
+	assert.Equal(t, "scores", xyJson.MustGetString(doc, "$.stats.label"))
+	assert.False(t, xyJson.Exists(doc, "$.missing"))
+	assert.False(t, xyJson.Exists(doc, "$.stats.other"))
+
+	// 克隆继承定义，之后的修改互不影响
+	// Clones inherit the definitions, later changes do not affect each other
+	clone := doc.Clone()
+	require.True(t, xyJson.RemoveVirtual(clone, "$.stats"))
+	assert.Equal(t, int64(-1), xyJson.MustGetInt64(clone, "$.stats.max"))
+	assert.Equal(t, int64(10), xyJson.MustGetInt64(doc, "$.stats.max"))
+	assert.False(t, xyJson.RemoveVirtual(clone, "$.stats"))
+
+	// 重新定义替换已有定义
+	// Redefining replaces the existing definition
+	require.NoError(t, xyJson.DefineVirtual(doc, "$['stats']", func(xyJson.IValue) xyJson.IValue {
+		return xyJson.MustParseString(`{"max": 0}`)
+	}))
+	assert.Equal(t, int64(0), xyJson.MustGetInt64(doc, "$.stats.max"))
+	assert.Len(t, xyJson.VirtualPaths(doc), 3)
+
+	assert.Error(t, xyJson.DefineVirtual(doc, "$.items[*]", orderTotal))
+	assert.Error(t, xyJson.DefineVirtual(doc, "$", orderTotal))
+	assert.Error(t, xyJson.DefineVirtual(doc, "$.x", nil))
+	assert.Error(t, xyJson.DefineVirtual(nil, "$.x", orderTotal))
+}
+
+// newChunkDoc 创建包含n个元素的测试文档
+// newChunkDoc creates a test document with n items
+func newChunkDoc(n int) xyJson.IValue {
+	items := xyJson.CreateArray()
+	for i := 0; i < n; i++ {
+		items.Append(i)
+	}
+	doc := xyJson.CreateObject()
+	doc.Set("items", items)
+	return doc
+}
+
+// TestProcessArrayChunks 测试按固定窗口处理数组
+// TestProcessArrayChunks tests processing arrays in fixed windows
+func TestProcessArrayChunks(t *testing.T) {
+	doc := newChunkDoc(10)
+
+	var sizes []int
+	sum := int64(0)
+	require.NoError(t, xyJson.ProcessArrayChunks(doc, "$.items", 4, func(chunk xyJson.IArray) error {
+		sizes = append(sizes, chunk.Length())
+		for i := 0; i < chunk.Length(); i++ {
+			sum += chunk.Get(i).AsInt64()
+		}
+		return nil
+	}))
+	assert.Equal(t, []int{4, 4, 2}, sizes)
+	assert.Equal(t, int64(45), sum)
+
+	empty := xyJson.MustParseString(`{"items": []}`)
+	require.NoError(t, xyJson.ProcessArrayChunks(empty, "$.items", 4, func(xyJson.IArray) error {
+		t.Fatal("no chunk expected")
+		return nil
+	}))
+
+	noop := func(xyJson.IArray) error { return nil }
+	assert.Error(t, xyJson.ProcessArrayChunks(doc, "$.missing", 4, noop))
+	assert.Error(t, xyJson.ProcessArrayChunks(doc, "$.items[0]", 4, noop))
+	assert.Error(t, xyJson.ProcessArrayChunks(doc, "$.items", 0, noop))
+	assert.Error(t, xyJson.ProcessArrayChunks(doc, "$.items", 4, nil))
+	assert.Error(t, xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 4, noop, &xyJson.ChunkOptions{StartIndex: -1}))
+}
+
+// TestProcessArrayChunksResume 测试检查点和从失败分块恢复
+// TestProcessArrayChunksResume tests checkpoints and resuming from a failed chunk
+func TestProcessArrayChunksResume(t *testing.T) {
+	doc := newChunkDoc(10)
+	boom := errors.New("boom")
+
+	var checkpoints []int
+	var seen []int64
+	fail := true
+	handle := func(chunk xyJson.IArray) error {
+		if fail && chunk.Get(0).AsInt64() == 6 {
+			return boom
+		}
+		for i := 0; i < chunk.Length(); i++ {
+			seen = append(seen, chunk.Get(i).AsInt64())
+		}
+		return nil
+	}
+	options := &xyJson.ChunkOptions{Checkpoint: func(next int) error {
+		checkpoints = append(checkpoints, next)
+		return nil
+	}}
+
+	err := xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 3, handle, options)
+	var chunkErr *xyJson.ChunkError
+	require.True(t, errors.As(err, &chunkErr))
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 6, chunkErr.Start)
+	assert.Equal(t, 9, chunkErr.End)
+	assert.Equal(t, []int{3, 6}, checkpoints)
+
+	fail = false
+	options.StartIndex = chunkErr.Start
+	require.NoError(t, xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 3, handle, options))
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, seen)
+	assert.Equal(t, []int{3, 6, 9, 10}, checkpoints)
+
+	// 检查点返回错误时停止
+	// Processing stops when the checkpoint fails
+	err = xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 3, handle, &xyJson.ChunkOptions{
+		Checkpoint: func(int) error { return boom },
+	})
+	require.True(t, errors.As(err, &chunkErr))
+	assert.Equal(t, 0, chunkErr.Start)
+}
+
+// TestProcessArrayChunksRelease 测试释放已处理的元素
+// TestProcessArrayChunksRelease tests releasing processed elements
+func TestProcessArrayChunksRelease(t *testing.T) {
+	doc := newChunkDoc(5)
+	boom := errors.New("boom")
+
+	err := xyJson.ProcessArrayChunksWithOptions(doc, "$.items", 2, func(chunk xyJson.IArray) error {
+		if chunk.Get(0).AsInt64() == 4 {
+			return boom
+		}
+		return nil
+	}, &xyJson.ChunkOptions{ReleaseProcessed: true})
+	require.Error(t, err)
+	assert.JSONEq(t, `{"items": [null, null, null, null, 4]}`, xyJson.MustSerializeToString(doc))
+}
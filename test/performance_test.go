@@ -1,11 +1,20 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	xyJson "github.com/ihuem/xyJson"
 	"github.com/ihuem/xyJson/test/testutil"
@@ -635,3 +644,429 @@ func BenchmarkPerformanceMonitor(b *testing.B) {
 		})
 	})
 }
+
+// TestAllocSampling 测试计时操作按采样率测量内存分配
+// TestAllocSampling tests that timed operations measure allocations at the sampling rate
+func TestAllocSampling(t *testing.T) {
+	monitor := xyJson.GetGlobalMonitor()
+	previous := monitor.AllocSampleRate()
+	defer monitor.SetAllocSampleRate(previous)
+	defer monitor.Reset()
+
+	assert.Equal(t, xyJson.DefaultAllocSampleRate, xyJson.NewPerformanceMonitor().AllocSampleRate())
+
+	data := testutil.RepeatArray(`{"id": 1, "name": "item"}`, 200)
+
+	monitor.SetAllocSampleRate(1)
+	monitor.Reset()
+	for i := 0; i < 3; i++ {
+		xyJson.MustParseString(data)
+	}
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(3), stats.ParseCount)
+	assert.Equal(t, int64(3), stats.AllocCount)
+	assert.Greater(t, stats.AllocBytes, int64(len(data)))
+	assert.Greater(t, stats.AllocObjects, int64(200))
+	assert.Equal(t, int64(1), stats.AllocSampleRate)
+
+	// 每4个操作采样一个，测得的分配量乘以采样率
+	// One operation in 4 is sampled, scaling the measurement by the rate
+	monitor.SetAllocSampleRate(4)
+	monitor.Reset()
+	for i := 0; i < 8; i++ {
+		xyJson.MustParseString(data)
+	}
+	stats = monitor.GetStats()
+	assert.Equal(t, int64(8), stats.ParseCount)
+	assert.Equal(t, int64(2), stats.AllocCount)
+	assert.Greater(t, stats.AllocBytes, int64(len(data)*8))
+
+	monitor.SetAllocSampleRate(0)
+	monitor.Reset()
+	xyJson.MustParseString(data)
+	xyJson.MustSerialize(xyJson.MustParseString(data))
+	stats = monitor.GetStats()
+	require.Equal(t, int64(2), stats.ParseCount)
+	assert.Equal(t, int64(0), stats.AllocCount)
+	assert.Equal(t, int64(0), stats.AllocBytes)
+	assert.Equal(t, int64(0), stats.AllocObjects)
+
+	monitor.SetAllocSampleRate(-1)
+	assert.Equal(t, 0, monitor.AllocSampleRate())
+}
+
+// TestRecordMemStatsSampling 测试RecordParse只在启用且被采样时读取内存统计
+// TestRecordMemStatsSampling tests that RecordParse only reads the memory statistics when enabled and sampled
+func TestRecordMemStatsSampling(t *testing.T) {
+	monitor := xyJson.NewPerformanceMonitor()
+
+	monitor.Disable()
+	monitor.RecordParse(time.Millisecond, 0)
+	assert.Equal(t, int64(0), monitor.GetStats().MaxMemoryUsage)
+
+	monitor.Enable()
+	monitor.SetAllocSampleRate(0)
+	monitor.RecordParse(time.Millisecond, 0)
+	monitor.RecordSerialize(time.Millisecond, 0)
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(1), stats.ParseCount)
+	assert.Equal(t, int64(0), stats.MaxMemoryUsage)
+
+	monitor.SetAllocSampleRate(1)
+	monitor.RecordParse(time.Millisecond, 0)
+	assert.Greater(t, monitor.GetStats().MaxMemoryUsage, int64(0))
+}
+
+// TestCollectDebugInfo 测试采集诊断信息
+// TestCollectDebugInfo tests collecting diagnostics
+func TestCollectDebugInfo(t *testing.T) {
+	xyJson.ClearPathCache()
+	_, err := xyJson.CompilePath("$.users[0].name")
+	require.NoError(t, err)
+
+	info := xyJson.CollectDebugInfo()
+	assert.Contains(t, info.CachedPaths, "$.users[0].name")
+	assert.Equal(t, len(info.CachedPaths), info.PathCacheSize)
+	assert.Greater(t, info.PathCacheMaxSize, 0)
+	assert.NotNil(t, info.Pool)
+	assert.NotZero(t, info.Memory.Sys)
+
+	value := info.ToValue()
+	assert.True(t, xyJson.Exists(value, "$.monitor.parse_count"))
+	assert.True(t, xyJson.Exists(value, "$.memory.heap_alloc"))
+	assert.Equal(t, info.PathCacheSize, xyJson.MustGetInt(value, "$.path_cache.size"))
+}
+
+// TestDebugHandlerJSON 测试诊断处理器返回JSON
+// TestDebugHandlerJSON tests that the diagnostics handler serves JSON
+func TestDebugHandlerJSON(t *testing.T) {
+	handler := xyJson.DebugHandler()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/debug/xyjson?format=json", nil),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/debug/xyjson", nil)
+			r.Header.Set("Accept", "application/json")
+			return r
+		}(),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+		doc, err := xyJson.Parse(rec.Body.Bytes())
+		require.NoError(t, err)
+		assert.True(t, xyJson.Exists(doc, "$.pool"))
+		assert.True(t, xyJson.Exists(doc, "$.path_cache.paths"))
+		assert.True(t, xyJson.Exists(doc, "$.snapshots"))
+	}
+}
+
+// TestDebugHandlerHTML 测试诊断处理器默认返回HTML并转义内容
+// TestDebugHandlerHTML tests that the diagnostics handler serves escaped HTML by default
+func TestDebugHandlerHTML(t *testing.T) {
+	xyJson.ClearPathCache()
+	_, err := xyJson.CompilePath(`$["<b>"]`)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	xyJson.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/xyjson", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	body := rec.Body.String()
+	assert.Contains(t, body, "Performance monitor")
+	assert.Contains(t, body, "Path cache")
+	assert.Contains(t, body, "&lt;b&gt;")
+	assert.NotContains(t, body, "<b>")
+}
+
+// TestDebugHandlerMethod 测试诊断处理器拒绝非GET请求
+// TestDebugHandlerMethod tests that the diagnostics handler rejects non-GET requests
+func TestDebugHandlerMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	xyJson.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/xyjson", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// hookCall 一次钩子调用的参数
+// hookCall holds the arguments of one hook call
+type hookCall struct {
+	size     int
+	duration time.Duration
+	err      error
+}
+
+// hookRecorder 记录钩子调用
+// hookRecorder records hook calls
+type hookRecorder struct {
+	mu    sync.Mutex
+	calls []hookCall
+}
+
+func (hr *hookRecorder) hook(size int, duration time.Duration, err error) {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+	hr.calls = append(hr.calls, hookCall{size: size, duration: duration, err: err})
+}
+
+// TestCompletionHooks 测试解析和序列化完成钩子
+// TestCompletionHooks tests the parse and serialize completion hooks
+func TestCompletionHooks(t *testing.T) {
+	parsed, serialized := &hookRecorder{}, &hookRecorder{}
+	removeParse := xyJson.OnParseComplete(parsed.hook)
+	removeSerialize := xyJson.OnSerializeComplete(serialized.hook)
+	removeSecond := xyJson.OnParseComplete(parsed.hook)
+
+	value, err := xyJson.Parse([]byte(`{"a":[1,2,3]}`))
+	require.NoError(t, err)
+	removeSecond()
+	removeSecond()
+	_, err = xyJson.ParseString(`{"a":`)
+	require.Error(t, err)
+	data, err := xyJson.Serialize(value)
+	require.NoError(t, err)
+	text, err := xyJson.SerializeToString(value)
+	require.NoError(t, err)
+
+	require.Len(t, parsed.calls, 3, "two hooks for Parse, one for ParseString")
+	assert.Equal(t, 13, parsed.calls[0].size)
+	assert.NoError(t, parsed.calls[0].err)
+	assert.Equal(t, parsed.calls[0], parsed.calls[1])
+	assert.Equal(t, 5, parsed.calls[2].size)
+	assert.Error(t, parsed.calls[2].err)
+
+	require.Len(t, serialized.calls, 2)
+	assert.Equal(t, len(data), serialized.calls[0].size)
+	assert.Equal(t, len(text), serialized.calls[1].size)
+	for _, call := range append(parsed.calls, serialized.calls...) {
+		assert.Greater(t, call.duration, time.Duration(0))
+	}
+
+	_, err = xyJson.Serialize(nil)
+	require.Error(t, err)
+	require.Len(t, serialized.calls, 3)
+	assert.Zero(t, serialized.calls[2].size)
+	assert.Error(t, serialized.calls[2].err)
+
+	removeParse()
+	removeSerialize()
+	_, err = xyJson.Parse([]byte(`1`))
+	require.NoError(t, err)
+	_, err = xyJson.Serialize(value)
+	require.NoError(t, err)
+	assert.Len(t, parsed.calls, 3)
+	assert.Len(t, serialized.calls, 3)
+
+	xyJson.OnParseComplete(nil)()
+}
+
+// TestCompletionHooksNoAllocation 测试未注册钩子时不产生额外分配
+// TestCompletionHooksNoAllocation tests that no allocation is added when no hook is registered
+func TestCompletionHooksNoAllocation(t *testing.T) {
+	value := xyJson.MustParseString(`{"a":1}`)
+	serialize := func() { _, _ = xyJson.Serialize(value) }
+	baseline := testing.AllocsPerRun(100, serialize)
+
+	remove := xyJson.OnSerializeComplete(func(int, time.Duration, error) {})
+	withHook := testing.AllocsPerRun(100, serialize)
+	remove()
+
+	assert.Equal(t, baseline, testing.AllocsPerRun(100, serialize))
+	assert.Equal(t, baseline, withHook, "running registered hooks does not allocate either")
+}
+
+// TestParserWithMonitor 测试解析器把耗时和错误记录到绑定的监控器
+// TestParserWithMonitor tests that a parser records timings and errors to its bound monitor
+func TestParserWithMonitor(t *testing.T) {
+	tenantA := xyJson.NewPerformanceMonitor()
+	tenantB := xyJson.NewPerformanceMonitor()
+	parserA := xyJson.NewParserWithMonitor(tenantA)
+	parserB := xyJson.NewParserWithMonitor(tenantB)
+
+	_, err := parserA.Parse([]byte(`{"a":1}`))
+	require.NoError(t, err)
+	_, err = parserA.ParseString(`[1,2,3]`)
+	require.NoError(t, err)
+	_, err = parserB.ParseString(`{"broken":`)
+	assert.Error(t, err)
+
+	statsA := tenantA.GetStats()
+	assert.Equal(t, int64(2), statsA.ParseCount)
+	assert.Equal(t, int64(0), statsA.ErrorCount)
+
+	statsB := tenantB.GetStats()
+	assert.Equal(t, int64(1), statsB.ParseCount)
+	assert.Equal(t, int64(1), statsB.ErrorCount)
+}
+
+// TestSerializerWithMonitor 测试序列化器把耗时记录到绑定的监控器并保留选项
+// TestSerializerWithMonitor tests that a serializer records timings to its bound monitor and keeps its options
+func TestSerializerWithMonitor(t *testing.T) {
+	monitor := xyJson.NewPerformanceMonitor()
+	serializer := xyJson.NewSerializerWithMonitor(&xyJson.SerializeOptions{Indent: "  ", MaxDepth: xyJson.DefaultMaxDepth}, monitor)
+
+	value := xyJson.MustParseString(`{"a":[1,2]}`)
+	out, err := serializer.SerializeToString(value)
+	require.NoError(t, err)
+	assert.Contains(t, out, "\n  ")
+
+	_, err = serializer.Serialize(nil)
+	assert.Error(t, err)
+
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(2), stats.SerializeCount)
+	assert.Equal(t, int64(1), stats.ErrorCount)
+	assert.Equal(t, int64(0), stats.ParseCount)
+}
+
+// TestNilMonitor 测试未绑定监控器时不计时
+// TestNilMonitor tests that no timing happens without a bound monitor
+func TestNilMonitor(t *testing.T) {
+	value, err := xyJson.NewParserWithMonitor(nil).ParseString(`{"a":1}`)
+	require.NoError(t, err)
+
+	out, err := xyJson.NewSerializerWithMonitor(nil, nil).SerializeToString(value)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, out)
+}
+
+// TestDisabledInstanceMonitor 测试禁用的监控器不记录
+// TestDisabledInstanceMonitor tests that a disabled monitor records nothing
+func TestDisabledInstanceMonitor(t *testing.T) {
+	monitor := xyJson.NewPerformanceMonitor()
+	monitor.Disable()
+
+	_, err := xyJson.NewParserWithMonitor(monitor).ParseString(`true`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), monitor.GetStats().ParseCount)
+}
+
+// TestPerformanceSnapshotDelta 测试快照差值且不重置计数器
+// TestPerformanceSnapshotDelta tests snapshot deltas without resetting the counters
+func TestPerformanceSnapshotDelta(t *testing.T) {
+	monitor := xyJson.NewPerformanceMonitor()
+	monitor.RecordParse(5*time.Millisecond, 0)
+	monitor.RecordError()
+
+	before := monitor.Snapshot()
+	monitor.RecordParse(2*time.Millisecond, 100)
+	monitor.RecordParse(4*time.Millisecond, 0)
+	monitor.RecordSerialize(time.Millisecond, 50)
+	after := monitor.Snapshot()
+
+	delta := xyJson.Delta(before, after)
+	assert.False(t, delta.Reset)
+	assert.Equal(t, int64(2), delta.ParseCount)
+	assert.Equal(t, 6*time.Millisecond, delta.TotalParseTime)
+	assert.Equal(t, 3*time.Millisecond, delta.AvgParseTime)
+	assert.Equal(t, int64(1), delta.SerializeCount)
+	assert.Equal(t, time.Millisecond, delta.AvgSerializeTime)
+	assert.Equal(t, int64(2), delta.AllocCount)
+	assert.Equal(t, int64(150), delta.AllocBytes)
+	assert.Equal(t, int64(0), delta.ErrorCount)
+	assert.GreaterOrEqual(t, delta.Elapsed, time.Duration(0))
+
+	assert.Equal(t, int64(3), monitor.GetStats().ParseCount)
+	assert.Equal(t, int64(1), before.Stats().ParseCount)
+	assert.False(t, after.TakenAt().Before(before.TakenAt()))
+}
+
+// TestPerformanceSnapshotDeltaAcrossReset 测试两个快照之间重置时的差值
+// TestPerformanceSnapshotDeltaAcrossReset tests the delta when a reset happens between the snapshots
+func TestPerformanceSnapshotDeltaAcrossReset(t *testing.T) {
+	monitor := xyJson.NewPerformanceMonitor()
+	monitor.RecordParse(time.Millisecond, 0)
+	monitor.RecordParse(time.Millisecond, 0)
+	before := monitor.Snapshot()
+
+	time.Sleep(time.Millisecond)
+	monitor.Reset()
+	monitor.RecordParse(3*time.Millisecond, 0)
+
+	delta := xyJson.Delta(before, monitor.Snapshot())
+	assert.True(t, delta.Reset)
+	assert.Equal(t, int64(1), delta.ParseCount)
+	assert.Equal(t, 3*time.Millisecond, delta.TotalParseTime)
+}
+
+// goroutineProfile 返回包含标签的goroutine profile文本
+// goroutineProfile returns the goroutine profile text including labels
+func goroutineProfile(t *testing.T) string {
+	var buf bytes.Buffer
+	require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 1))
+	return buf.String()
+}
+
+// TestStartMemoryProfilingWithLabels 测试解析期间goroutine带有pprof标签
+// TestStartMemoryProfilingWithLabels tests that goroutines carry pprof labels while parsing
+func TestStartMemoryProfilingWithLabels(t *testing.T) {
+	assert.Error(t, xyJson.StartMemoryProfilingWithLabels("service"))
+
+	require.NoError(t, xyJson.StartMemoryProfilingWithLabels("service", "orders"))
+	defer xyJson.StopMemoryProfiling()
+
+	data := []byte(testutil.RepeatArray(`{"id":1,"name":"item"}`, 20000))
+	var stop atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !stop.Load() {
+			doc := xyJson.MustParse(data)
+			xyJson.MustSerialize(doc)
+		}
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var parseSeen, serializeSeen bool
+	for time.Now().Before(deadline) && !(parseSeen && serializeSeen) {
+		profile := goroutineProfile(t)
+		parseSeen = parseSeen || strings.Contains(profile, `"xyjson.op":"parse"`)
+		serializeSeen = serializeSeen || strings.Contains(profile, `"xyjson.op":"serialize"`)
+		if strings.Contains(profile, `"xyjson.op"`) {
+			assert.Contains(t, profile, `"service":"orders"`)
+			assert.Contains(t, profile, `"xyjson.size":"large"`)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop.Store(true)
+	<-done
+
+	assert.True(t, parseSeen)
+	assert.True(t, serializeSeen)
+}
+
+// TestProfilingLabelsCleared 测试操作结束和停止分析后不再带有标签
+// TestProfilingLabelsCleared tests that no labels remain after operations and after profiling stops
+func TestProfilingLabelsCleared(t *testing.T) {
+	require.NoError(t, xyJson.StartMemoryProfilingWithLabels())
+	xyJson.MustParseString(`{"a": 1}`)
+	xyJson.StopMemoryProfiling()
+
+	assert.NotContains(t, goroutineProfile(t), "xyjson.op")
+}
+
+// TestProfilingLabelsKeepCallerLabels 测试Context变体结束后恢复调用方的标签
+// TestProfilingLabelsKeepCallerLabels tests that the Context variants restore the caller's labels afterwards
+func TestProfilingLabelsKeepCallerLabels(t *testing.T) {
+	require.NoError(t, xyJson.StartMemoryProfilingWithLabels())
+	defer xyJson.StopMemoryProfiling()
+
+	done := make(chan struct{})
+	release := make(chan struct{})
+	go pprof.Do(context.Background(), pprof.Labels("caller", "worker"), func(ctx context.Context) {
+		value, err := xyJson.ParseContext(ctx, []byte(`{"a": 1}`))
+		assert.NoError(t, err)
+		_, err = xyJson.SerializeContext(ctx, value)
+		assert.NoError(t, err)
+		close(done)
+		<-release
+	})
+	<-done
+	profile := goroutineProfile(t)
+	close(release)
+
+	assert.Contains(t, profile, `labels: {"caller":"worker"}`)
+	assert.NotContains(t, profile, "xyjson.op")
+}
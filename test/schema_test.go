@@ -0,0 +1,947 @@
+package test
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	xyJson "github.com/ihuem/xyJson"
+	"github.com/ihuem/xyJson/openapi"
+	"github.com/ihuem/xyJson/test/testutil"
+)
+
+type schemaAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+// schemaAddressAlias 与schemaAddress是同一类型，用于在局部同名类型的作用域中引用它
+// schemaAddressAlias is the same type as schemaAddress, referring to it where a local type shadows the name
+type schemaAddressAlias = schemaAddress
+
+type schemaUser struct {
+	Name      string            `json:"name"`
+	Age       int               `json:"age"`
+	Score     float64           `json:"score,omitempty"`
+	Email     *string           `json:"email"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]int    `json:"labels"`
+	Address   schemaAddress     `json:"address"`
+	Backup    *schemaAddress    `json:"backup"`
+	CreatedAt time.Time         `json:"created_at"`
+	Extra     interface{}       `json:"extra"`
+	Children  []*schemaUser     `json:"children"`
+	Ignored   string            `json:"-"`
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+// TestGenerateSchema 测试结构体到JSON Schema的生成
+// TestGenerateSchema tests generating JSON Schema from structs
+func TestGenerateSchema(t *testing.T) {
+	schema, err := xyJson.GenerateSchema(schemaUser{})
+	require.NoError(t, err)
+
+	t.Run("root", func(t *testing.T) {
+		assert.Equal(t, xyJson.SchemaDraft, xyJson.MustGetString(schema, "$['$schema']"))
+		assert.Equal(t, "schemaUser", xyJson.MustGetString(schema, "$.title"))
+		assert.Equal(t, "object", xyJson.MustGetString(schema, "$.type"))
+		assert.False(t, xyJson.Exists(schema, "$.properties.Ignored"))
+	})
+
+	t.Run("scalar_types", func(t *testing.T) {
+		assert.Equal(t, "string", xyJson.MustGetString(schema, "$.properties.name.type"))
+		assert.Equal(t, "integer", xyJson.MustGetString(schema, "$.properties.age.type"))
+		assert.Equal(t, "number", xyJson.MustGetString(schema, "$.properties.score.type"))
+		assert.Equal(t, "date-time", xyJson.MustGetString(schema, "$.properties.created_at.format"))
+	})
+
+	t.Run("required", func(t *testing.T) {
+		required, err := xyJson.GetArray(schema, "$.required")
+		require.NoError(t, err)
+		var names []string
+		required.Range(func(_ int, v xyJson.IValue) bool {
+			names = append(names, v.String())
+			return true
+		})
+		assert.Equal(t, []string{"name", "age", "tags", "labels", "address", "created_at", "extra", "children"}, names)
+	})
+
+	t.Run("containers", func(t *testing.T) {
+		assert.Equal(t, "array", xyJson.MustGetString(schema, "$.properties.tags.type"))
+		assert.Equal(t, "string", xyJson.MustGetString(schema, "$.properties.tags.items.type"))
+		assert.Equal(t, "integer", xyJson.MustGetString(schema, "$.properties.labels.additionalProperties.type"))
+	})
+
+	t.Run("nullable_pointer", func(t *testing.T) {
+		assert.Equal(t, "string", xyJson.MustGetString(schema, "$.properties.email.anyOf[0].type"))
+		assert.Equal(t, "null", xyJson.MustGetString(schema, "$.properties.email.anyOf[1].type"))
+	})
+
+	t.Run("definitions", func(t *testing.T) {
+		assert.Equal(t, "#/definitions/schemaAddress", xyJson.MustGetString(schema, "$.properties.address['$ref']"))
+		assert.Equal(t, "#/definitions/schemaAddress", xyJson.MustGetString(schema, "$.properties.backup.anyOf[0]['$ref']"))
+		assert.Equal(t, "string", xyJson.MustGetString(schema, "$.definitions.schemaAddress.properties.city.type"))
+		assert.Equal(t, 1, xyJson.MustGetArray(schema, "$.definitions.schemaAddress.required").Length())
+	})
+
+	t.Run("self_reference", func(t *testing.T) {
+		assert.Equal(t, "#", xyJson.MustGetString(schema, "$.properties.children.items.anyOf[0]['$ref']"))
+	})
+}
+
+// TestGenerateSchemaSameName 测试同名的不同结构体类型得到各自的定义
+// TestGenerateSchemaSameName tests that distinct struct types with the same name get their own definitions
+func TestGenerateSchemaSameName(t *testing.T) {
+	type schemaAddress struct {
+		Street string `json:"street"`
+	}
+	type order struct {
+		Billing  schemaAddress  `json:"billing"`
+		Shipping *schemaAddress `json:"shipping"`
+		Legacy   struct {
+			Address schemaAddressAlias `json:"address"`
+		} `json:"legacy"`
+	}
+
+	schema, err := xyJson.GenerateSchema(order{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "#/definitions/schemaAddress", xyJson.MustGetString(schema, "$.properties.billing['$ref']"))
+	assert.Equal(t, "#/definitions/schemaAddress", xyJson.MustGetString(schema, "$.properties.shipping.anyOf[0]['$ref']"))
+	assert.Equal(t, "#/definitions/schemaAddress2", xyJson.MustGetString(schema, "$.properties.legacy.properties.address['$ref']"))
+	assert.Equal(t, "string", xyJson.MustGetString(schema, "$.definitions.schemaAddress.properties.street.type"))
+	assert.Equal(t, "string", xyJson.MustGetString(schema, "$.definitions.schemaAddress2.properties.city.type"))
+}
+
+// TestGenerateSchemaErrors 测试JSON Schema生成的错误输入
+// TestGenerateSchemaErrors tests invalid inputs for JSON Schema generation
+func TestGenerateSchemaErrors(t *testing.T) {
+	_, err := xyJson.GenerateSchema(nil)
+	assert.Error(t, err)
+
+	_, err = xyJson.GenerateSchema(42)
+	assert.Error(t, err)
+
+	_, err = xyJson.GenerateSchema(struct {
+		M map[int]string `json:"m"`
+	}{})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		xyJson.MustGenerateSchema("not a struct")
+	})
+
+	schema, err := xyJson.GenerateSchema(&schemaAddress{})
+	require.NoError(t, err)
+	assert.Equal(t, "schemaAddress", xyJson.MustGetString(schema, "$.title"))
+}
+
+type schemaUpload struct {
+	Name  string            `json:"name"`
+	Size  int64             `json:"size"`
+	Owner *schemaOwner      `json:"owner"`
+	Tags  []string          `json:"tags,omitempty"`
+	Meta  map[string]string `json:"meta,omitempty"`
+}
+
+type schemaOwner struct {
+	Login string `json:"login"`
+}
+
+const schemaDocument = `{
+	"type": "object",
+	"required": ["id", "kind"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"kind": {"enum": ["a", "b"]},
+		"label": {"type": "string", "minLength": 2, "maxLength": 4, "pattern": "^[a-z]+$"},
+		"ratio": {"type": "number", "exclusiveMaximum": 1, "multipleOf": 0.25},
+		"items": {"type": "array", "maxItems": 3, "items": {"$ref": "#/definitions/item"}},
+		"pair": {"type": "array", "items": [{"type": "string"}, {"type": "number"}], "uniqueItems": true},
+		"choice": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+		"child": {"anyOf": [{"$ref": "#"}, {"type": "null"}]},
+		"extra": {"type": "object", "minProperties": 1, "additionalProperties": {"type": "boolean"}},
+		"never": {"not": {"type": "string"}}
+	},
+	"definitions": {
+		"item": {"type": "object", "required": ["n"], "properties": {"n": {"const": 1}}}
+	}
+}`
+
+// schemaCases 有效和无效文档以及期望的违反路径
+// schemaCases lists valid and invalid documents with the expected violation paths
+var schemaCases = []struct {
+	name string
+	data string
+	path string
+}{
+	{"valid_minimal", `{"id": 1, "kind": "a"}`, ""},
+	{"valid_full", `{"id": 2, "kind": "b", "label": "abc", "ratio": 0.75, "items": [{"n": 1}, {"n": 1.0}],
+		"pair": ["x", 1], "choice": 3, "child": {"id": 3, "kind": "a", "child": null}, "extra": {"x": true}, "never": 1}`, ""},
+	{"wrong_root_type", `[1]`, "$"},
+	{"missing_required", `{"id": 1}`, "$"},
+	{"additional", `{"id": 1, "kind": "a", "zzz": 0}`, "$.zzz"},
+	{"integer", `{"id": 1.5, "kind": "a"}`, "$.id"},
+	{"minimum", `{"id": 0, "kind": "a"}`, "$.id"},
+	{"enum", `{"id": 1, "kind": "c"}`, "$.kind"},
+	{"min_length", `{"id": 1, "kind": "a", "label": "a"}`, "$.label"},
+	{"max_length", `{"id": 1, "kind": "a", "label": "abcde"}`, "$.label"},
+	{"pattern", `{"id": 1, "kind": "a", "label": "AB"}`, "$.label"},
+	{"exclusive_maximum", `{"id": 1, "kind": "a", "ratio": 1}`, "$.ratio"},
+	{"multiple_of", `{"id": 1, "kind": "a", "ratio": 0.3}`, "$.ratio"},
+	{"max_items", `{"id": 1, "kind": "a", "items": [{"n": 1}, {"n": 1}, {"n": 1}, {"n": 1}]}`, "$.items"},
+	{"ref_required", `{"id": 1, "kind": "a", "items": [{"n": 1}, {}]}`, "$.items[1]"},
+	{"const", `{"id": 1, "kind": "a", "items": [{"n": 2}]}`, "$.items[0].n"},
+	{"tuple", `{"id": 1, "kind": "a", "pair": [1, 1]}`, "$.pair[0]"},
+	{"unique", `{"id": 1, "kind": "a", "pair": ["x", 1, 1]}`, "$.pair"},
+	{"one_of", `{"id": 1, "kind": "a", "choice": true}`, "$.choice"},
+	{"recursive", `{"id": 1, "kind": "a", "child": {"id": 1, "kind": "z"}}`, ""},
+	{"min_properties", `{"id": 1, "kind": "a", "extra": {}}`, "$.extra"},
+	{"additional_schema", `{"id": 1, "kind": "a", "extra": {"x": 1}}`, "$.extra.x"},
+	{"not", `{"id": 1, "kind": "a", "never": "s"}`, "$.never"},
+}
+
+// TestSchemaValidate 测试内存校验与流式校验的结果一致
+// TestSchemaValidate tests that in-memory and streaming validation agree
+func TestSchemaValidate(t *testing.T) {
+	schema, err := xyJson.CompileSchema(xyJson.MustParseString(schemaDocument))
+	require.NoError(t, err)
+
+	for _, tc := range schemaCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memErr := schema.Validate(xyJson.MustParseString(tc.data))
+			streamErr := xyJson.ValidateStream(strings.NewReader(tc.data), schema)
+
+			if tc.name == "valid_minimal" || tc.name == "valid_full" {
+				assert.NoError(t, memErr)
+				assert.NoError(t, streamErr)
+				return
+			}
+
+			for _, err := range []error{memErr, streamErr} {
+				var jsonErr *xyJson.JSONError
+				require.ErrorAs(t, err, &jsonErr)
+				assert.Equal(t, xyJson.ErrSchemaViolation, jsonErr.Code, err.Error())
+				if tc.path != "" {
+					assert.Equal(t, tc.path, jsonErr.Path, err.Error())
+				}
+			}
+		})
+	}
+}
+
+// TestSchemaMultipleOfDecimal 测试multipleOf按十进制精确比较
+// TestSchemaMultipleOfDecimal tests that multipleOf compares exactly in decimal
+func TestSchemaMultipleOfDecimal(t *testing.T) {
+	tests := []struct {
+		multipleOf string
+		value      string
+		valid      bool
+	}{
+		{"0.1", "0.3", true},
+		{"0.1", "0.7", true},
+		{"0.1", "-1.1", true},
+		{"0.01", "19.99", true},
+		{"0.01", "0.07", true},
+		{"0.01", "1234567.89", true},
+		{"0.01", "19.999", false},
+		{"0.25", "0.3", false},
+		{"3", "9", true},
+		{"3", "10", false},
+		{"1.5", "4.5", true},
+	}
+	for _, tt := range tests {
+		schema, err := xyJson.CompileSchema(xyJson.MustParseString(`{"multipleOf": ` + tt.multipleOf + `}`))
+		require.NoError(t, err)
+		err = schema.Validate(xyJson.MustParseString(tt.value))
+		streamErr := xyJson.ValidateStream(strings.NewReader(tt.value), schema)
+		if tt.valid {
+			assert.NoError(t, err, "%s of %s", tt.value, tt.multipleOf)
+			assert.NoError(t, streamErr, "%s of %s", tt.value, tt.multipleOf)
+		} else {
+			assert.Error(t, err, "%s of %s", tt.value, tt.multipleOf)
+			assert.Error(t, streamErr, "%s of %s", tt.value, tt.multipleOf)
+		}
+	}
+}
+
+// TestValidateStream 测试流式校验的快速失败和语法检查
+// TestValidateStream tests fail-fast behavior and syntax checks of streaming validation
+func TestValidateStream(t *testing.T) {
+	schema := xyJson.MustCompileSchema(xyJson.MustParseString(schemaDocument))
+
+	t.Run("fail_fast", func(t *testing.T) {
+		r := io.MultiReader(strings.NewReader(`{"id": 1, "kind": "a", "zzz": `), testutil.FailingReader{})
+		err := xyJson.ValidateStream(r, schema)
+		var jsonErr *xyJson.JSONError
+		require.ErrorAs(t, err, &jsonErr)
+		assert.Equal(t, xyJson.ErrSchemaViolation, jsonErr.Code)
+
+		r = io.MultiReader(strings.NewReader(`{"items": [{"n": 1}, {"n": 1}, {"n": 1}, `), testutil.FailingReader{})
+		require.ErrorAs(t, xyJson.ValidateStream(r, schema), &jsonErr)
+		assert.Equal(t, "$.items", jsonErr.Path)
+	})
+
+	t.Run("syntax", func(t *testing.T) {
+		for _, data := range []string{``, `{"id": 1, "kind": "a"`, `{"id": 1 "kind": "a"}`, `{"id": 1, "kind": "a"} {}`, `{"id": 1, "kind": "a", "label": "ab}`} {
+			err := xyJson.ValidateStream(strings.NewReader(data), schema)
+			var jsonErr *xyJson.JSONError
+			require.ErrorAs(t, err, &jsonErr, data)
+			assert.NotEqual(t, xyJson.ErrSchemaViolation, jsonErr.Code, data)
+		}
+	})
+
+	t.Run("generated_schema", func(t *testing.T) {
+		generated := xyJson.MustCompileSchema(xyJson.MustGenerateSchema(schemaUpload{}))
+
+		assert.NoError(t, xyJson.ValidateStream(strings.NewReader(`{"name": "f", "size": 3, "owner": {"login": "l"}, "tags": ["t"]}`), generated))
+		assert.NoError(t, xyJson.ValidateStream(strings.NewReader(`{"name": "f", "size": 3, "owner": null}`), generated))
+		assert.Error(t, xyJson.ValidateStream(strings.NewReader(`{"name": "f", "size": 3, "owner": {}}`), generated))
+		assert.Error(t, xyJson.ValidateStream(strings.NewReader(`{"name": "f", "size": "3"}`), generated))
+		assert.Error(t, xyJson.ValidateStream(strings.NewReader(`{"name": "f", "size": 3, "meta": {"a": 1}}`), generated))
+	})
+
+	t.Run("invalid_arguments", func(t *testing.T) {
+		assert.Error(t, xyJson.ValidateStream(nil, schema))
+		assert.Error(t, xyJson.ValidateStream(strings.NewReader(`{}`), nil))
+	})
+}
+
+// TestCompileSchemaErrors 测试无效Schema文档
+// TestCompileSchemaErrors tests invalid schema documents
+func TestCompileSchemaErrors(t *testing.T) {
+	for _, doc := range []string{
+		`1`,
+		`{"type": "text"}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"$ref": "http://example.com/schema"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"anyOf": []}`,
+		`{"multipleOf": 0}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "$ref": "#/definitions/a"}`,
+	} {
+		_, err := xyJson.CompileSchema(xyJson.MustParseString(doc))
+		assert.Error(t, err, doc)
+	}
+
+	schema := xyJson.MustCompileSchema(xyJson.MustParseString(`false`))
+	assert.Error(t, schema.Validate(xyJson.CreateNull()))
+	schema = xyJson.MustCompileSchema(xyJson.MustParseString(`true`))
+	assert.NoError(t, schema.Validate(xyJson.CreateNull()))
+}
+
+// refDocuments 测试用的远程文档
+// refDocuments are the remote documents used in tests
+var refDocuments = map[string]string{
+	"schemas/user.json":   `{"type":"object","properties":{"address":{"$ref":"common.json#/definitions/address"},"self":{"$ref":"#/definitions/id"}},"definitions":{"id":{"type":"integer"}}}`,
+	"schemas/common.json": `{"definitions":{"address":{"type":"object","properties":{"city":{"type":"string"}}},"node":{"type":"object","properties":{"next":{"$ref":"#/definitions/node"}}}}}`,
+}
+
+// refLoader 从refDocuments加载文档并记录调用次数
+// refLoader loads documents from refDocuments and counts the calls
+func refLoader(calls map[string]int) xyJson.RefLoader {
+	return func(uri string) (xyJson.IValue, error) {
+		calls[uri]++
+		data, ok := refDocuments[uri]
+		if !ok {
+			return nil, errors.New("not found: " + uri)
+		}
+		return xyJson.ParseString(data)
+	}
+}
+
+// TestResolveRefsInlines 测试内联本地和远程引用
+// TestResolveRefsInlines tests inlining local and remote references
+func TestResolveRefsInlines(t *testing.T) {
+	root := xyJson.MustParseString(`{
+		"properties": {
+			"owner": {"$ref": "schemas/user.json"},
+			"backup": {"$ref": "schemas/user.json"},
+			"kind": {"$ref": "#/definitions/kind"},
+			"list": [{"$ref": "#/definitions/kind"}]
+		},
+		"definitions": {"kind": {"enum": ["a", "b"]}}
+	}`)
+	original := root.Clone()
+
+	calls := make(map[string]int)
+	resolved, err := xyJson.ResolveRefs(root, refLoader(calls))
+	require.NoError(t, err)
+
+	expectedUser := `{"type":"object","properties":{"address":{"type":"object","properties":{"city":{"type":"string"}}},"self":{"type":"integer"}},"definitions":{"id":{"type":"integer"}}}`
+	assert.True(t, xyJson.MustParseString(expectedUser).Equals(xyJson.MustGet(resolved, "$.properties.owner")))
+	assert.True(t, xyJson.MustParseString(expectedUser).Equals(xyJson.MustGet(resolved, "$.properties.backup")))
+	assert.Equal(t, "b", xyJson.MustGetString(resolved, "$.properties.kind.enum[1]"))
+	assert.Equal(t, "a", xyJson.MustGetString(resolved, "$.properties.list[0].enum[0]"))
+	assert.Equal(t, map[string]int{"schemas/user.json": 1, "schemas/common.json": 1}, calls)
+
+	// 原文档不变，内联的值彼此独立
+	// The original is unchanged and inlined values are independent
+	assert.True(t, original.Equals(root))
+	require.NoError(t, xyJson.Set(resolved, "$.properties.kind.enum[0]", "z"))
+	assert.Equal(t, "a", xyJson.MustGetString(resolved, "$.properties.list[0].enum[0]"))
+}
+
+// TestResolveRefsErrors 测试循环、缺失和无loader的引用
+// TestResolveRefsErrors tests circular, missing and loader-less references
+func TestResolveRefsErrors(t *testing.T) {
+	var je *xyJson.JSONError
+
+	partial, err := xyJson.ResolveRefs(xyJson.MustParseString(`{"a":{"$ref":"#/b"},"b":{"c":{"$ref":"#/a"}}}`), nil)
+	assert.Nil(t, partial)
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrCircularReference, je.Code)
+	assert.Equal(t, "$.a.c", je.Path)
+
+	_, err = xyJson.ResolveRefs(xyJson.MustParseString(`{"n":{"$ref":"schemas/common.json#/definitions/node"}}`), refLoader(map[string]int{}))
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrCircularReference, je.Code)
+
+	partial, err = xyJson.ResolveRefs(xyJson.MustParseString(`{"x":[{"$ref":"#/missing"}]}`), nil)
+	assert.Nil(t, partial)
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrPathNotFound, je.Code)
+	assert.Equal(t, "$.x[0]", je.Path)
+
+	_, err = xyJson.ResolveRefs(xyJson.MustParseString(`{"$ref":"other.json"}`), nil)
+	assert.Error(t, err)
+
+	_, err = xyJson.ResolveRefs(xyJson.MustParseString(`{"$ref":"missing.json"}`), refLoader(map[string]int{}))
+	require.True(t, errors.As(err, &je))
+	assert.Contains(t, je.Cause.Error(), "not found")
+
+	// 同一个引用在不同分支中出现不是循环
+	// The same reference in sibling branches is not a cycle
+	resolved, err := xyJson.ResolveRefs(xyJson.MustParseString(`{"a":{"$ref":"#/d"},"b":{"$ref":"#/d"},"d":[1]}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, xyJson.MustGetInt(resolved, "$.b[0]"))
+}
+
+// TestBundle 测试将远程引用改写为本地引用
+// TestBundle tests rewriting remote references into local ones
+func TestBundle(t *testing.T) {
+	root := xyJson.MustParseString(`{
+		"properties": {
+			"owner": {"$ref": "schemas/user.json"},
+			"home": {"$ref": "schemas/common.json#/definitions/address"},
+			"chain": {"$ref": "schemas/common.json#/definitions/node"},
+			"kind": {"$ref": "#/definitions/kind"}
+		},
+		"definitions": {"kind": {"enum": ["a"]}, "address": {"const": 1}}
+	}`)
+
+	calls := make(map[string]int)
+	bundled, err := xyJson.Bundle(root, refLoader(calls))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"schemas/user.json": 1, "schemas/common.json": 1}, calls)
+
+	// 已有definitions时使用definitions，重名时添加后缀
+	// definitions is used when present, with a suffix on collisions
+	assert.Equal(t, "#/definitions/user", xyJson.MustGetString(bundled, "$.properties.owner.$ref"))
+	assert.Equal(t, "#/definitions/address_2", xyJson.MustGetString(bundled, "$.properties.home.$ref"))
+	assert.Equal(t, "#/definitions/node", xyJson.MustGetString(bundled, "$.properties.chain.$ref"))
+	assert.Equal(t, "#/definitions/kind", xyJson.MustGetString(bundled, "$.properties.kind.$ref"))
+	assert.Equal(t, 1, xyJson.MustGetInt(bundled, "$.definitions.address.const"))
+
+	// 远程文档中的引用也被改写，递归引用保持为引用
+	// References inside remote documents are rewritten too, recursive references stay references
+	assert.Equal(t, "#/definitions/address_2", xyJson.MustGetString(bundled, "$.definitions.user.properties.address.$ref"))
+	assert.Equal(t, "#/definitions/id", xyJson.MustGetString(bundled, "$.definitions.user.properties.self.$ref"))
+	assert.Equal(t, "integer", xyJson.MustGetString(bundled, "$.definitions.id.type"))
+	assert.Equal(t, "#/definitions/node", xyJson.MustGetString(bundled, "$.definitions.node.properties.next.$ref"))
+
+	// 打包后的文档不再需要loader
+	// The bundled document no longer needs a loader
+	_, err = xyJson.CompileSchema(bundled)
+	require.NoError(t, err)
+	bundled, err = xyJson.Bundle(xyJson.MustParseString(`{"properties":{"u":{"$ref":"schemas/user.json"}}}`), refLoader(map[string]int{}))
+	require.NoError(t, err)
+	inlined, err := xyJson.ResolveRefs(bundled, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "string", xyJson.MustGetString(inlined, "$.properties.u.properties.address.properties.city.type"))
+}
+
+// TestBundleDefaults 测试$defs的创建和不需要打包的文档
+// TestBundleDefaults tests creating $defs and documents that need no bundling
+func TestBundleDefaults(t *testing.T) {
+	local := xyJson.MustParseString(`{"a":{"$ref":"#/b"},"b":1}`)
+	bundled, err := xyJson.Bundle(local, nil)
+	require.NoError(t, err)
+	assert.True(t, local.Equals(bundled))
+
+	bundled, err = xyJson.Bundle(xyJson.MustParseString(`{"a":{"$ref":"schemas/common.json"}}`), refLoader(map[string]int{}))
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/common", xyJson.MustGetString(bundled, "$.a.$ref"))
+	assert.True(t, xyJson.Exists(bundled, "$.$defs.common.definitions.node"))
+
+	_, err = xyJson.Bundle(xyJson.MustParseString(`[{"$ref":"schemas/common.json"}]`), refLoader(map[string]int{}))
+	assert.Error(t, err)
+}
+
+const petstoreSpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "Pets", "version": "1"},
+	"paths": {
+		"/pets": {
+			"get": {
+				"operationId": "listPets",
+				"responses": {
+					"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}},
+					"default": {"$ref": "#/components/responses/Error"}
+				}
+			},
+			"post": {
+				"operationId": "createPet",
+				"requestBody": {"$ref": "#/components/requestBodies/NewPet"},
+				"responses": {
+					"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+					"4XX": {"$ref": "#/components/responses/Error"}
+				}
+			}
+		},
+		"/pets/{id}": {
+			"delete": {"operationId": "deletePet", "responses": {"204": {"description": "gone"}}}
+		},
+		"/pets/mine": {
+			"get": {"operationId": "myPets", "responses": {"200": {"description": "ok", "content": {"text/*": {}}}}}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["id", "name"],
+				"properties": {
+					"id": {"type": "integer", "minimum": 1},
+					"name": {"type": "string", "minLength": 1},
+					"tag": {"type": "string", "nullable": true},
+					"owner": {"$ref": "#/components/schemas/Owner"}
+				}
+			},
+			"Owner": {"type": "object", "required": ["email"], "properties": {"email": {"type": "string", "pattern": "@"}}},
+			"Error": {"type": "object", "required": ["message"], "properties": {"message": {"type": "string"}}}
+		},
+		"requestBodies": {
+			"NewPet": {
+				"required": true,
+				"content": {"application/json": {"schema": {
+					"type": "object",
+					"required": ["name"],
+					"additionalProperties": false,
+					"properties": {"name": {"type": "string"}, "owner": {"$ref": "#/components/schemas/Owner"}}
+				}}}
+			}
+		},
+		"responses": {
+			"Error": {"description": "error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+		}
+	}
+}`
+
+// TestOpenAPILoad 测试加载文档和查找操作
+// TestOpenAPILoad tests loading a document and finding operations
+func TestOpenAPILoad(t *testing.T) {
+	spec, err := openapi.LoadBytes([]byte(petstoreSpec))
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", spec.Version())
+	assert.Len(t, spec.Operations(), 4)
+	assert.Equal(t, []string{"Error", "Owner", "Pet"}, spec.SchemaNames())
+
+	op, ok := spec.OperationByID("createPet")
+	require.True(t, ok)
+	assert.Equal(t, "POST", op.Method())
+	assert.Equal(t, "/pets", op.Path())
+
+	op, params, err := spec.FindOperation("DELETE", "/pets/42?force=true")
+	require.NoError(t, err)
+	assert.Equal(t, "deletePet", op.ID())
+	assert.Equal(t, map[string]string{"id": "42"}, params)
+
+	op, params, err = spec.FindOperation("GET", "/pets/mine")
+	require.NoError(t, err)
+	assert.Equal(t, "myPets", op.ID())
+	assert.Empty(t, params)
+
+	_, _, err = spec.FindOperation("PUT", "/pets")
+	var je *xyJson.JSONError
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrInvalidOperation, je.Code)
+
+	_, _, err = spec.FindOperation("GET", "/owners")
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrPathNotFound, je.Code)
+}
+
+// TestOpenAPIValidateRequest 测试校验请求体
+// TestOpenAPIValidateRequest tests validating request bodies
+func TestOpenAPIValidateRequest(t *testing.T) {
+	spec, err := openapi.LoadBytes([]byte(petstoreSpec))
+	require.NoError(t, err)
+
+	assert.NoError(t, spec.ValidateRequest("POST", "/pets", "application/json; charset=utf-8", []byte(`{"name":"rex","owner":{"email":"a@b"}}`)))
+	assert.NoError(t, spec.ValidateRequest("POST", "/pets", "", []byte(`{"name":"rex"}`)))
+
+	err = spec.ValidateRequest("POST", "/pets", "application/json", []byte(`{"name":"rex","owner":{"email":"nope"}}`))
+	var je *xyJson.JSONError
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrSchemaViolation, je.Code)
+	assert.Equal(t, "$.owner.email", je.Path)
+	assert.Contains(t, err.Error(), "request of POST /pets")
+
+	err = spec.ValidateRequest("POST", "/pets", "application/json", []byte(`{"name":"rex","age":3}`))
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrSchemaViolation, je.Code)
+
+	err = spec.ValidateRequest("POST", "/pets", "application/json", nil)
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, "request body is required", je.Message)
+
+	err = spec.ValidateRequest("POST", "/pets", "application/xml", []byte(`{"name":"rex"}`))
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrInvalidOperation, je.Code)
+
+	assert.NoError(t, spec.ValidateRequest("DELETE", "/pets/1", "", nil))
+	assert.Error(t, spec.ValidateRequest("DELETE", "/pets/1", "", []byte(`{}`)))
+}
+
+// TestOpenAPIValidateResponse 测试按状态码校验响应体和nullable转换
+// TestOpenAPIValidateResponse tests validating response bodies by status code and the nullable conversion
+func TestOpenAPIValidateResponse(t *testing.T) {
+	spec, err := openapi.LoadBytes([]byte(petstoreSpec))
+	require.NoError(t, err)
+
+	assert.NoError(t, spec.ValidateResponse("GET", "/pets", 200, "application/json", []byte(`[{"id":1,"name":"rex","tag":null}]`)))
+	err = spec.ValidateResponse("GET", "/pets", 200, "application/json", []byte(`[{"id":0,"name":"rex"}]`))
+	var je *xyJson.JSONError
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, "$[0].id", je.Path)
+
+	// default响应和nXX范围响应
+	// The default response and nXX range responses
+	assert.NoError(t, spec.ValidateResponse("GET", "/pets", 500, "application/json", []byte(`{"message":"boom"}`)))
+	assert.Error(t, spec.ValidateResponse("GET", "/pets", 500, "application/json", []byte(`{}`)))
+	assert.NoError(t, spec.ValidateResponse("POST", "/pets", 404, "application/json", []byte(`{"message":"no"}`)))
+	assert.Error(t, spec.ValidateResponse("POST", "/pets", 500, "application/json", []byte(`{"message":"no"}`)))
+
+	// 没有Schema的媒体类型接受任何响应体，没有content的响应只接受空响应体
+	// A media type without a schema accepts any body, a response without content only accepts an empty body
+	assert.NoError(t, spec.ValidateResponse("GET", "/pets/mine", 200, "text/plain", []byte(`"hi"`)))
+	assert.NoError(t, spec.ValidateResponse("DELETE", "/pets/7", 204, "", nil))
+	assert.Error(t, spec.ValidateResponse("DELETE", "/pets/7", 204, "application/json", []byte(`{}`)))
+
+	op, _ := spec.OperationByID("listPets")
+	schema, ok := op.ResponseSchema(200, "application/json")
+	require.True(t, ok)
+	assert.NoError(t, schema.Validate(xyJson.MustParseString(`[]`)))
+
+	pet, ok := spec.Schema("Pet")
+	require.True(t, ok)
+	assert.Error(t, pet.Validate(xyJson.MustParseString(`{"id":1}`)))
+}
+
+// TestOpenAPILoadErrors 测试无效文档
+// TestOpenAPILoadErrors tests invalid documents
+func TestOpenAPILoadErrors(t *testing.T) {
+	for _, doc := range []string{
+		`[]`,
+		`{"swagger":"2.0"}`,
+		`{"openapi":"3.0.0","paths":{"/a":{"get":{"requestBody":{"$ref":"#/components/requestBodies/Missing"}}}}}`,
+		`{"openapi":"3.0.0","paths":{"/a":{"get":{"requestBody":{"content":{"application/json":{"schema":{"$ref":"other.json#/X"}}}}}}}}`,
+		`{"openapi":"3.0.0","paths":{"/a":{"get":{"operationId":"x"}},"/b":{"get":{"operationId":"x"}}}}`,
+	} {
+		_, err := openapi.LoadBytes([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+
+	// 加载时深拷贝文档
+	// The document is deep-copied on load
+	doc := xyJson.MustParseString(petstoreSpec)
+	spec := openapi.MustLoad(doc)
+	require.NoError(t, xyJson.Set(doc, "$.components.schemas.Owner.properties.email.pattern", "^x"))
+	assert.NoError(t, spec.ValidateRequest("POST", "/pets", "", []byte(`{"name":"rex","owner":{"email":"a@b"}}`)))
+}
+
+const coerceSchema = `{
+	"type": "object",
+	"properties": {
+		"age": {"type": "integer"},
+		"score": {"type": "number"},
+		"active": {"type": "boolean"},
+		"zip": {"type": "string"},
+		"created": {"type": "string", "format": "date-time"},
+		"born": {"type": "string", "format": "date"},
+		"note": {"type": ["string", "null"]},
+		"nickname": {"type": ["null", "integer"]},
+		"tags": {"type": "array", "items": {"type": "integer"}},
+		"id": {"anyOf": [{"type": "integer"}, {"type": "string", "pattern": "^x"}]}
+	}
+}`
+
+// TestCoerceConvertsToSchemaTypes 测试按Schema转换兼容的值且不修改原文档
+// TestCoerceConvertsToSchemaTypes tests converting compatible values to schema types without modifying the document
+func TestCoerceConvertsToSchemaTypes(t *testing.T) {
+	schema := xyJson.MustCompileSchema(xyJson.MustParseString(coerceSchema))
+	input := `{"age":" 42 ","score":"3.5","active":"true","zip":12345,"created":1700000000,` +
+		`"born":1700000000000,"note":"","nickname":"","tags":["1",2,"3.0"],"id":"7","extra":"5"}`
+	doc := xyJson.MustParseString(input)
+
+	result, err := xyJson.Coerce(doc, schema)
+	require.NoError(t, err)
+
+	out, err := xyJson.SerializeToString(result)
+	require.NoError(t, err)
+	expected := xyJson.MustParseString(`{"age":42,"score":3.5,"active":true,"zip":"12345",` +
+		`"created":"2023-11-14T22:13:20Z","born":"2023-11-14","note":"","nickname":null,"tags":[1,2,3],"id":7,"extra":"5"}`)
+	assert.True(t, expected.Equals(result), out)
+	assert.NoError(t, schema.Validate(result))
+
+	assert.True(t, xyJson.MustParseString(input).Equals(doc))
+}
+
+// TestCoerceReportsFailures 测试报告每个无法转换的路径
+// TestCoerceReportsFailures tests reporting every path that cannot be converted
+func TestCoerceReportsFailures(t *testing.T) {
+	schema := xyJson.MustCompileSchema(xyJson.MustParseString(coerceSchema))
+	doc := xyJson.MustParseString(`{"age":"forty","score":"1","tags":[1,"x",{"a":1}],"active":2,"id":"y"}`)
+
+	result, err := xyJson.Coerce(doc, schema)
+	require.Error(t, err)
+
+	var coercionErr *xyJson.CoercionError
+	require.True(t, errors.As(err, &coercionErr))
+	var paths []string
+	for _, failure := range coercionErr.Failures {
+		paths = append(paths, failure.Path)
+		assert.Equal(t, xyJson.ErrTypeMismatch, failure.Code)
+	}
+	assert.ElementsMatch(t, []string{"$.active", "$.age", "$.tags[1]", "$.tags[2]"}, paths)
+
+	var jsonErr *xyJson.JSONError
+	assert.True(t, errors.As(err, &jsonErr))
+
+	assert.Equal(t, 1, xyJson.MustGetInt(result, "$.score"))
+	assert.Equal(t, "forty", xyJson.MustGetString(result, "$.age"))
+}
+
+// TestCoerceRefsAndAllOf 测试$ref和allOf中的类型转换
+// TestCoerceRefsAndAllOf tests conversion through $ref and allOf
+func TestCoerceRefsAndAllOf(t *testing.T) {
+	schema := xyJson.MustCompileSchema(xyJson.MustParseString(`{
+		"definitions": {"count": {"type": "integer"}},
+		"allOf": [{"properties": {"n": {"$ref": "#/definitions/count"}}}],
+		"properties": {"flag": {"type": "boolean"}}
+	}`))
+
+	result, err := xyJson.Coerce(xyJson.MustParseString(`{"n":"10","flag":0}`), schema)
+	require.NoError(t, err)
+	assert.Equal(t, 10, xyJson.MustGetInt(result, "$.n"))
+	assert.False(t, xyJson.MustGetBool(result, "$.flag"))
+
+	_, err = xyJson.Coerce(nil, schema)
+	assert.Error(t, err)
+}
+
+// TestSanitizeAllowDeny 测试按允许和拒绝列表清洗
+// TestSanitizeAllowDeny tests sanitizing with allow and deny lists
+func TestSanitizeAllowDeny(t *testing.T) {
+	root := xyJson.MustParseString(`{"user":{"name":"ann","password":"x","role":"admin"},` +
+		`"items":[{"sku":"a","internal":1},{"sku":"b"}],"debug":true,"meta":{"password":"y","tag":"t"}}`)
+	original := xyJson.MustSerializeToString(root)
+
+	allow, err := xyJson.NewProjection("user.name", "user.password", "items.sku", "meta")
+	require.NoError(t, err)
+	clean, report, err := xyJson.Sanitize(root, xyJson.SanitizePolicy{Allow: allow, Deny: []string{"password"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"items":[{"sku":"a"},{"sku":"b"}],"meta":{"tag":"t"},"user":{"name":"ann"}}`,
+		xyJson.MustSerializeToString(clean))
+	assert.Equal(t, []xyJson.SanitizeRemoval{
+		{Path: "$.debug", Reason: xyJson.SanitizeNotAllowed},
+		{Path: "$.items[0].internal", Reason: xyJson.SanitizeNotAllowed},
+		{Path: "$.meta.password", Reason: xyJson.SanitizeDenied},
+		{Path: "$.user.password", Reason: xyJson.SanitizeDenied},
+		{Path: "$.user.role", Reason: xyJson.SanitizeNotAllowed},
+	}, report.Removed)
+	assert.False(t, report.Clean())
+	assert.Equal(t, original, xyJson.MustSerializeToString(root), "root is not modified")
+
+	// 允许列表要求对象时标量被移除 Scalars are removed where the allowlist expects an object
+	clean, report, err = xyJson.Sanitize(xyJson.MustParseString(`{"user":"ann"}`), xyJson.SanitizePolicy{Allow: allow})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, xyJson.MustSerializeToString(clean))
+	assert.Equal(t, "not allowed", report.Removed[0].Reason.String())
+
+	// 零值策略不做修改 The zero policy changes nothing
+	clean, report, err = xyJson.Sanitize(root, xyJson.SanitizePolicy{})
+	require.NoError(t, err)
+	assert.True(t, report.Clean())
+	assert.True(t, clean.Equals(root))
+
+	_, _, err = xyJson.Sanitize(nil, xyJson.SanitizePolicy{})
+	assert.Error(t, err)
+}
+
+// TestSanitizeLimits 测试长度和深度限制
+// TestSanitizeLimits tests the length and depth limits
+func TestSanitizeLimits(t *testing.T) {
+	root := xyJson.MustParseString(`{"short":"ok","long":"` + strings.Repeat("x", 20) + `",` +
+		`"` + strings.Repeat("k", 20) + `":1,"list":["a","` + strings.Repeat("y", 20) + `","c"],` +
+		`"big":[1,2,3,4,5,6],"deep":{"a":{"b":{}}}}`)
+	clean, report, err := xyJson.Sanitize(root, xyJson.SanitizePolicy{
+		MaxKeyLength:    10,
+		MaxStringLength: 10,
+		MaxArrayLength:  5,
+		MaxDepth:        3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"deep":{"a":{}},"list":["a","c"],"short":"ok"}`, xyJson.MustSerializeToString(clean))
+
+	reasons := map[string]xyJson.SanitizeReason{}
+	for _, removed := range report.Removed {
+		reasons[removed.Path] = removed.Reason
+	}
+	assert.Equal(t, map[string]xyJson.SanitizeReason{
+		"$.big":                        xyJson.SanitizeTooLong,
+		"$.deep.a.b":                   xyJson.SanitizeTooDeep,
+		"$." + strings.Repeat("k", 20): xyJson.SanitizeTooLong,
+		"$.list[1]":                    xyJson.SanitizeTooLong,
+		"$.long":                       xyJson.SanitizeTooLong,
+	}, reasons)
+
+	// 根值被移除时返回null A removed root yields null
+	clean, report, err = xyJson.Sanitize(xyJson.CreateString(strings.Repeat("z", 20)), xyJson.SanitizePolicy{MaxStringLength: 5})
+	require.NoError(t, err)
+	assert.True(t, clean.IsNull())
+	assert.Equal(t, "$", report.Removed[0].Path)
+}
+
+// TestSanitizeNormalizeUnicode 测试Unicode规范化
+// TestSanitizeNormalizeUnicode tests unicode normalization
+func TestSanitizeNormalizeUnicode(t *testing.T) {
+	root := xyJson.CreateObject()
+	require.NoError(t, root.Set("pass​word", "secret"))
+	require.NoError(t, root.Set("name", "a‮b\x00c\td\xffe"))
+	require.NoError(t, root.Set("nam‍e", "dup"))
+	require.NoError(t, root.Set("plain", "text"))
+
+	clean, report, err := xyJson.Sanitize(root, xyJson.SanitizePolicy{
+		Deny:             []string{"password"},
+		NormalizeUnicode: true,
+		Normalizer:       strings.ToLower,
+	})
+	require.NoError(t, err)
+	obj := clean.(xyJson.IObject)
+	assert.Equal(t, []string{"name", "plain"}, obj.Keys())
+	assert.Equal(t, "abc\td�e", obj.Get("name").String())
+	assert.Equal(t, []string{"$.name", "$.'nam‍e'", "$.'pass​word'"}, report.Normalized)
+	assert.Contains(t, report.Removed, xyJson.SanitizeRemoval{Path: "$.'pass​word'", Reason: xyJson.SanitizeDenied},
+		"invisible characters cannot bypass the denylist")
+	assert.Contains(t, report.Removed, xyJson.SanitizeRemoval{Path: "$.'nam‍e'", Reason: xyJson.SanitizeDuplicateKey})
+}
+
+// TestGenerateCode 测试根据示例JSON生成代码
+// TestGenerateCode tests generating code from a sample JSON document
+func TestGenerateCode(t *testing.T) {
+	sample := xyJson.MustParseString(`{
+		"user": {"name": "Alice", "age": 30, "active": true, "score": 9.5, "first-name": "A"},
+		"items": [{"id": 1, "title": "book"}],
+		"tags": ["a", "b"],
+		"meta": {}
+	}`)
+
+	src, err := xyJson.GenerateCode(sample, &xyJson.CodeGenOptions{
+		PackageName: "api",
+		TypeName:    "Payload",
+		Accessors:   true,
+	})
+	require.NoError(t, err)
+	code := string(src)
+
+	// 生成的代码必须是合法的Go源码
+	// The generated code must be valid Go source
+	_, err = parser.ParseFile(token.NewFileSet(), "gen.go", src, 0)
+	require.NoError(t, err, code)
+
+	assert.Contains(t, code, "package api")
+	assert.Contains(t, code, "type Payload struct")
+	assert.Contains(t, code, "type User struct")
+	assert.Contains(t, code, "type Item struct")
+	assert.Regexp(t, `Items\s+\[\]\*Item\s+`+"`json:\"items\"`", code)
+	assert.Regexp(t, `Tags\s+\[\]string`, code)
+	assert.Regexp(t, `Meta\s+map\[string\]interface\{\}`, code)
+	assert.Contains(t, code, "func GetUserName(root xyJson.IValue) string")
+	assert.Contains(t, code, "func GetUserAge(root xyJson.IValue) int64")
+	assert.Contains(t, code, "func GetUserActive(root xyJson.IValue) bool")
+	assert.Contains(t, code, "func GetUserScore(root xyJson.IValue) float64")
+	assert.Contains(t, code, `mustCompilePath("$.user.first-name")`)
+	// 数组内部不生成访问函数
+	// No accessors are generated inside arrays
+	assert.NotContains(t, code, "GetItemsTitle")
+}
+
+// TestGenerateCodeWithoutAccessors 测试关闭访问函数生成
+// TestGenerateCodeWithoutAccessors tests disabling accessor generation
+func TestGenerateCodeWithoutAccessors(t *testing.T) {
+	src, err := xyJson.GenerateCode(xyJson.MustParseString(`{"a":1}`), &xyJson.CodeGenOptions{PackageName: "m"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(src), "import")
+	assert.Contains(t, string(src), "type Root struct")
+
+	_, err = xyJson.GenerateCode(xyJson.MustParseString(`[1,2]`), nil)
+	assert.Error(t, err)
+}
+
+// TestGenerateCodeMergesArrayElements 测试数组元素类型由所有元素合并得出
+// TestGenerateCodeMergesArrayElements tests that array element types are merged from every element
+func TestGenerateCodeMergesArrayElements(t *testing.T) {
+	sample := xyJson.MustParseString(`{
+		"items": [{"x": 1, "n": null}, {"x": 2.5, "y": "z", "n": 3}, {"x": 4, "k": 1}, {"k": "a", "tags": [1]}, {"tags": [2.5]}],
+		"mixed": [1, "a", 2]
+	}`)
+	src, err := xyJson.GenerateCode(sample, nil)
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Regexp(t, `X\s+float64\s+`+"`json:\"x\"`", code)
+	assert.Regexp(t, `Y\s+string\s+`+"`json:\"y\"`", code)
+	assert.Regexp(t, `N\s+int64\s+`+"`json:\"n\"`", code)
+	assert.Regexp(t, `K\s+interface\{\}\s+`+"`json:\"k\"`", code)
+	assert.Regexp(t, `Tags\s+\[\]float64\s+`+"`json:\"tags\"`", code)
+	assert.Regexp(t, `Mixed\s+\[\]interface\{\}\s+`+"`json:\"mixed\"`", code)
+}
+
+// TestGenerateCodeFromSchema 测试根据JSON Schema生成代码
+// TestGenerateCodeFromSchema tests generating code from a JSON Schema
+func TestGenerateCodeFromSchema(t *testing.T) {
+	schema, err := xyJson.GenerateSchema(schemaUser{})
+	require.NoError(t, err)
+
+	src, err := xyJson.GenerateCodeFromSchema(schema, &xyJson.CodeGenOptions{PackageName: "model", Accessors: true})
+	require.NoError(t, err)
+	code := string(src)
+
+	assert.Contains(t, code, "type SchemaUser struct")
+	assert.Contains(t, code, "type Address struct")
+	assert.True(t, strings.Contains(code, "func GetAddressCity(root xyJson.IValue) string"), code)
+	assert.Contains(t, code, "func GetAge(root xyJson.IValue) int64")
+
+	t.Run("type_name", func(t *testing.T) {
+		src, err := xyJson.GenerateCodeFromSchema(schema, nil)
+		require.NoError(t, err)
+		assert.Contains(t, string(src), "type SchemaUser struct")
+
+		src, err = xyJson.GenerateCodeFromSchema(schema, &xyJson.CodeGenOptions{TypeName: "User"})
+		require.NoError(t, err)
+		assert.Contains(t, string(src), "type User struct")
+		assert.NotContains(t, string(src), "type SchemaUser struct")
+	})
+}
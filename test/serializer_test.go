@@ -1,8 +1,22 @@
 package test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -559,3 +573,857 @@ func TestSerializeErrorHandling(t *testing.T) {
 		assert.True(t, updatedOptions.SortKeys)
 	})
 }
+
+// TestSerializeMapBackedValuesDeterministic 测试由Go map构建的值每次序列化结果相同
+// TestSerializeMapBackedValuesDeterministic tests that values built from Go maps serialize identically every time
+func TestSerializeMapBackedValuesDeterministic(t *testing.T) {
+	raw := map[string]interface{}{
+		"zeta": 1, "alpha": 2, "mid": map[string]interface{}{"y": true, "b": false, "k": nil},
+		"list": []interface{}{map[string]interface{}{"q": 1, "a": 2}},
+	}
+	expected := `{"alpha":2,"list":[{"a":2,"q":1}],"mid":{"b":false,"k":null,"y":true},"zeta":1}`
+
+	for _, sortKeys := range []bool{false, true} {
+		serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+			Compact:  true,
+			SortKeys: sortKeys,
+			MaxDepth: xyJson.DefaultMaxDepth,
+		})
+		for i := 0; i < 20; i++ {
+			value, err := xyJson.CreateFromRaw(raw)
+			require.NoError(t, err)
+			out, err := serializer.SerializeToString(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, out)
+		}
+	}
+
+	parsed := xyJson.MustParseString(`{"zeta":1,"alpha":2}`)
+	out, err := xyJson.SerializeToString(parsed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"alpha":2,"zeta":1}`, out)
+}
+
+// cachedJSON 返回值的缓存序列化结果
+// cachedJSON returns the cached serialization of a value
+func cachedJSON(t *testing.T, value xyJson.IValue) []byte {
+	data, err := value.CachedJSON()
+	require.NoError(t, err)
+	return data
+}
+
+// TestCachedJSON 测试未修改的文档复用序列化结果
+// TestCachedJSON tests that unmodified documents reuse their serialization
+func TestCachedJSON(t *testing.T) {
+	doc := xyJson.MustParseString(`{"name": "svc", "limits": {"cpu": 2, "hosts": ["a", "b"]}}`)
+
+	first := cachedJSON(t, doc)
+	assert.Equal(t, xyJson.MustSerialize(doc), first)
+	second := cachedJSON(t, doc)
+	assert.Same(t, &first[0], &second[0])
+
+	// 其他文档的修改和缓存不影响该缓存
+	// Modifying and caching other documents leaves this cache valid
+	other := xyJson.MustParseString(`{"x": [1]}`)
+	cachedJSON(t, other)
+	require.NoError(t, xyJson.Set(other, "$.x[0]", 2))
+	third := cachedJSON(t, doc)
+	assert.Same(t, &first[0], &third[0])
+
+	scalar := xyJson.CreateString("s")
+	assert.Equal(t, `"s"`, string(cachedJSON(t, scalar)))
+}
+
+// TestCachedJSONInvalidation 测试通过各种API修改后缓存失效
+// TestCachedJSONInvalidation tests that the cache is invalidated by modifications through the APIs
+func TestCachedJSONInvalidation(t *testing.T) {
+	const source = `{"name": "svc", "limits": {"cpu": 2, "hosts": ["a", "b"]}}`
+	mutations := map[string]func(doc xyJson.IValue){
+		"root set":     func(doc xyJson.IValue) { doc.(xyJson.IObject).Set("name", "api") },
+		"root delete":  func(doc xyJson.IValue) { doc.(xyJson.IObject).Delete("name") },
+		"nested set":   func(doc xyJson.IValue) { require.NoError(t, xyJson.Set(doc, "$.limits.cpu", 4)) },
+		"append":       func(doc xyJson.IValue) { xyJson.MustGetArray(doc, "$.limits.hosts").Append("c") },
+		"array delete": func(doc xyJson.IValue) { xyJson.MustGetArray(doc, "$.limits.hosts").Delete(0) },
+		"insert":       func(doc xyJson.IValue) { xyJson.MustGetArray(doc, "$.limits.hosts").Insert(0, "z") },
+		"clear":        func(doc xyJson.IValue) { xyJson.MustGetObject(doc, "$.limits").Clear() },
+		"path delete":  func(doc xyJson.IValue) { require.NoError(t, xyJson.Delete(doc, "$.limits.hosts[1]")) },
+		"parse into": func(doc xyJson.IValue) {
+			parser := xyJson.NewParser()
+			parser.Reset([]byte(`{"name": "svc", "limits": {"cpu": 3, "hosts": ["a", "b"]}}`))
+			_, err := parser.ParseInto(doc)
+			require.NoError(t, err)
+		},
+	}
+
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			doc := xyJson.MustParseString(source)
+			limits := xyJson.MustGetObject(doc, "$.limits")
+			cachedJSON(t, doc)
+			cachedJSON(t, limits)
+
+			mutate(doc)
+			assert.Equal(t, xyJson.MustSerializeToString(doc), string(cachedJSON(t, doc)))
+			assert.Equal(t, xyJson.MustSerializeToString(limits), string(cachedJSON(t, limits)))
+		})
+	}
+}
+
+// TestCompressedRoundTrip 测试压缩序列化与解析
+// TestCompressedRoundTrip tests compressed serialization and parsing
+func TestCompressedRoundTrip(t *testing.T) {
+	root := xyJson.MustParseString(`{"name": "Alice", "tags": ["a", "b"], "n": 1.5}`)
+	expected := xyJson.MustSerializeToString(root)
+
+	for _, encoding := range []string{xyJson.EncodingGzip, xyJson.EncodingIdentity, xyJson.EncodingAuto, " GZIP "} {
+		var buf bytes.Buffer
+		require.NoError(t, xyJson.SerializeCompressed(root, &buf, encoding), encoding)
+
+		decoded, err := xyJson.ParseCompressed(bytes.NewReader(buf.Bytes()), xyJson.EncodingAuto)
+		require.NoError(t, err, encoding)
+		assert.Equal(t, expected, xyJson.MustSerializeToString(decoded), encoding)
+	}
+
+	// 显式指定编码
+	// Explicit encoding
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(expected))
+	require.NoError(t, gz.Close())
+	assert.Equal(t, xyJson.EncodingGzip, xyJson.DetectEncoding(buf.Bytes()))
+
+	decoded, err := xyJson.ParseCompressed(&buf, xyJson.EncodingGzip)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", xyJson.MustGetString(decoded, "$.name"))
+}
+
+// TestCompressedErrors 测试压缩辅助函数的错误处理
+// TestCompressedErrors tests error handling of the compression helpers
+func TestCompressedErrors(t *testing.T) {
+	root := xyJson.CreateObject()
+
+	t.Run("zstd_without_codec", func(t *testing.T) {
+		input := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}
+		assert.Equal(t, xyJson.EncodingZstd, xyJson.DetectEncoding(input))
+		_, err := xyJson.ParseCompressed(bytes.NewReader(input), xyJson.EncodingAuto)
+		assert.Error(t, err)
+		assert.Error(t, xyJson.SerializeCompressed(root, io.Discard, xyJson.EncodingZstd))
+	})
+
+	t.Run("unknown_encoding", func(t *testing.T) {
+		_, err := xyJson.ParseCompressed(strings.NewReader("{}"), "brotli")
+		assert.Error(t, err)
+		assert.Error(t, xyJson.SerializeCompressed(root, io.Discard, "brotli"))
+	})
+
+	t.Run("corrupt_stream", func(t *testing.T) {
+		_, err := xyJson.ParseCompressed(strings.NewReader("{}"), xyJson.EncodingGzip)
+		assert.Error(t, err)
+		_, err = xyJson.ParseCompressed(bytes.NewReader([]byte{0x1f, 0x8b, 0x08}), xyJson.EncodingAuto)
+		assert.Error(t, err)
+	})
+
+	t.Run("nil_arguments", func(t *testing.T) {
+		_, err := xyJson.ParseCompressed(nil, xyJson.EncodingAuto)
+		assert.Error(t, err)
+		assert.Error(t, xyJson.SerializeCompressed(root, nil, xyJson.EncodingGzip))
+		assert.Error(t, xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{Name: "empty"}))
+		assert.Error(t, xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{Name: xyJson.EncodingIdentity}))
+	})
+}
+
+// TestRegisterCompressionCodec 测试注册自定义编解码器
+// TestRegisterCompressionCodec tests registering a custom codec
+func TestRegisterCompressionCodec(t *testing.T) {
+	require.NoError(t, xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{
+		Name:  "test-zlib",
+		Magic: []byte{0x78, 0x9c},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return zlib.NewReader(r)
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, xyJson.SerializeCompressed(xyJson.MustParseString(`{"a": 1}`), &buf, "test-zlib"))
+	assert.Equal(t, "test-zlib", xyJson.DetectEncoding(buf.Bytes()))
+
+	decoded, err := xyJson.ParseCompressed(&buf, xyJson.EncodingAuto)
+	require.NoError(t, err)
+	assert.Equal(t, 1, xyJson.MustGetInt(decoded, "$.a"))
+}
+
+// TestDetectEncodingOrder 测试魔数重叠时的检测结果与注册顺序无关
+// TestDetectEncodingOrder tests that detection with overlapping magic bytes does not depend on the registration
+// order
+func TestDetectEncodingOrder(t *testing.T) {
+	for _, name := range []string{"test-tie-b", "test-tie-a"} {
+		require.NoError(t, xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{
+			Name:      name,
+			Magic:     []byte{0xfe, 0xed},
+			NewReader: func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil },
+		}))
+	}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "test-tie-a", xyJson.DetectEncoding([]byte{0xfe, 0xed, 0x00}))
+	}
+
+	// 以gzip魔数开头的更长魔数优先，自动检测会预读完整的魔数
+	// A longer magic starting with the gzip magic wins, auto detection peeks the whole magic
+	header := []byte{0x1f, 0x8b, 'x', 'y', 'j', 's', 'o', 'n'}
+	require.NoError(t, xyJson.RegisterCompressionCodec(xyJson.CompressionCodec{
+		Name:  "test-framed",
+		Magic: header,
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			if _, err := io.ReadFull(r, make([]byte, len(header))); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(r), nil
+		},
+	}))
+	framed := append(append([]byte{}, header...), `{"a": 1}`...)
+	assert.Equal(t, "test-framed", xyJson.DetectEncoding(framed))
+	assert.Equal(t, xyJson.EncodingGzip, xyJson.DetectEncoding(header[:4]))
+
+	decoded, err := xyJson.ParseCompressed(bytes.NewReader(framed), xyJson.EncodingAuto)
+	require.NoError(t, err)
+	assert.Equal(t, 1, xyJson.MustGetInt(decoded, "$.a"))
+}
+
+// escapeContextDocument 包含注入字符的文档
+// escapeContextDocument is a document containing injection characters
+func escapeContextDocument() xyJson.IValue {
+	root := xyJson.CreateObject()
+	root.Set("html", `</script><img src=x onerror="alert('x')">&amp;`)
+	root.Set("line", "a b c")
+	root.Set("ctrl", "tab\there\x01 end")
+	root.Set("it's", []interface{}{1.5, "ünï"})
+	return root
+}
+
+// TestEscapeContextScript 测试嵌入脚本的转义
+// TestEscapeContextScript tests escaping for embedding in scripts
+func TestEscapeContextScript(t *testing.T) {
+	root := escapeContextDocument()
+	data, err := xyJson.SerializeForContext(root, xyJson.EscapeContextScript)
+	require.NoError(t, err)
+	out := string(data)
+
+	for _, forbidden := range []string{"<", ">", "&", "'", " ", " "} {
+		assert.NotContains(t, out, forbidden)
+	}
+	assert.Contains(t, out, `\u003c/script\u003e`)
+	assert.Contains(t, out, `"it\u0027s"`)
+	assert.True(t, xyJson.MustParse(data).Equals(root), "the output is still equivalent JSON")
+
+	// 默认上下文不变 The default context is unchanged
+	plain := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{Compact: true, MaxDepth: xyJson.DefaultMaxDepth})
+	out, err = plain.SerializeToString(root)
+	require.NoError(t, err)
+	assert.Contains(t, out, "it's")
+	assert.Contains(t, out, "</script>")
+}
+
+// TestEscapeContextHTMLAttribute 测试嵌入HTML属性的转义
+// TestEscapeContextHTMLAttribute tests escaping for embedding in HTML attributes
+func TestEscapeContextHTMLAttribute(t *testing.T) {
+	root := escapeContextDocument()
+	data, err := xyJson.SerializeForContext(root, xyJson.EscapeContextHTMLAttribute)
+	require.NoError(t, err)
+	out := string(data)
+
+	for _, forbidden := range []string{`"`, "'", "<", ">"} {
+		assert.NotContains(t, out, forbidden)
+	}
+	assert.True(t, strings.HasPrefix(out, `{&#34;`))
+	// 浏览器解码属性后得到原始JSON The browser gets the original JSON back
+	assert.True(t, xyJson.MustParseString(html.UnescapeString(out)).Equals(root))
+}
+
+// TestEscapeContextURL 测试嵌入URL的转义
+// TestEscapeContextURL tests escaping for embedding in URLs
+func TestEscapeContextURL(t *testing.T) {
+	root := escapeContextDocument()
+	serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+		Indent:        "  ",
+		MaxDepth:      xyJson.DefaultMaxDepth,
+		EscapeContext: xyJson.EscapeContextURL,
+	})
+	data, err := serializer.Serialize(root)
+	require.NoError(t, err)
+	out := string(data)
+
+	for _, c := range out {
+		assert.True(t, c < 0x80 && strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~%", c),
+			"unexpected %q", c)
+	}
+	assert.Contains(t, out, "%0A%20%20%22ctrl%22", "whitespace and quotes are encoded")
+
+	decoded, err := url.PathUnescape(out)
+	require.NoError(t, err)
+	assert.True(t, xyJson.MustParseString(decoded).Equals(root))
+
+	query, err := url.ParseQuery("filter=" + out)
+	require.NoError(t, err)
+	assert.True(t, xyJson.MustParseString(query.Get("filter")).Equals(root))
+}
+
+// TestEscapeContextString 测试转义上下文的字符串表示
+// TestEscapeContextString tests the string representation of escape contexts
+func TestEscapeContextString(t *testing.T) {
+	assert.Equal(t, "json", xyJson.EscapeContextJSON.String())
+	assert.Equal(t, "script", xyJson.EscapeContextScript.String())
+	assert.Equal(t, "html-attribute", xyJson.EscapeContextHTMLAttribute.String())
+	assert.Equal(t, "url", xyJson.EscapeContextURL.String())
+	assert.Equal(t, "unknown", xyJson.EscapeContext(99).String())
+}
+
+// TestNegotiate 测试按Accept请求头选择表示
+// TestNegotiate tests choosing a representation for an Accept header
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		accept    string
+		mediaType string
+		indent    string
+		lines     bool
+	}{
+		{"", "application/json", "", false},
+		{"*/*", "application/json", "", false},
+		{"text/html, application/*;q=0.8", "application/json", "", false},
+		{"application/json; indent=4", "application/json", "    ", false},
+		{"application/json;pretty=true", "application/json", "  ", false},
+		{"application/x-ndjson, application/json;q=0.5", "application/x-ndjson", "", true},
+		{"application/jsonl", "application/jsonl", "", true},
+		{"application/json;q=0.2, */*;q=0.9", "application/x-ndjson", "", true},
+		{"application/problem+json, application/json;q=0.9", "application/problem+json", "", false},
+		{"APPLICATION/JSON; CHARSET=UTF-8", "application/json", "", false},
+		{"application/json;charset=utf-16, application/x-ndjson;q=0.1", "application/x-ndjson", "", true},
+	}
+	for _, c := range cases {
+		rep, err := xyJson.Negotiate(c.accept)
+		require.NoError(t, err, c.accept)
+		assert.Equal(t, c.mediaType, rep.MediaType, c.accept)
+		assert.Equal(t, c.indent, rep.Indent, c.accept)
+		assert.Equal(t, c.lines, rep.Lines, c.accept)
+		assert.Equal(t, "utf-8", rep.Charset, c.accept)
+	}
+
+	for _, accept := range []string{"text/html", "application/xml, text/*", "application/json;q=0, application/*;q=0", "*/*;q=0"} {
+		_, err := xyJson.Negotiate(accept)
+		var je *xyJson.JSONError
+		require.True(t, errors.As(err, &je), accept)
+		assert.Equal(t, xyJson.ErrInvalidOperation, je.Code)
+	}
+}
+
+// TestRepresentationSerialize 测试按表示序列化
+// TestRepresentationSerialize tests serializing in a representation
+func TestRepresentationSerialize(t *testing.T) {
+	value := xyJson.MustParseString(`[{"a":1},"<b>",[2]]`)
+
+	rep, err := xyJson.Negotiate("application/json")
+	require.NoError(t, err)
+	data, err := rep.Serialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"a":1},"\u003cb\u003e",[2]]`, string(data))
+	assert.Equal(t, "application/json; charset=utf-8", rep.ContentType())
+
+	rep, err = xyJson.Negotiate("application/json;indent=2")
+	require.NoError(t, err)
+	data, err = rep.Serialize(xyJson.MustParseString(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1\n}", string(data))
+
+	rep, err = xyJson.Negotiate("application/x-ndjson;indent=2")
+	require.NoError(t, err)
+	data, err = rep.Serialize(value)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n\"\\u003cb\\u003e\"\n[2]\n", string(data))
+	data, err = rep.Serialize(xyJson.MustParseString(`{"a":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n", string(data))
+	data, err = rep.Serialize(xyJson.CreateArray())
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = rep.Serialize(nil)
+	assert.Error(t, err)
+}
+
+// TestWriteNegotiated 测试HTTP处理函数中的内容协商
+// TestWriteNegotiated tests content negotiation in HTTP handlers
+func TestWriteNegotiated(t *testing.T) {
+	value := xyJson.MustParseString(`[1,2]`)
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header = header
+		rec := httptest.NewRecorder()
+		xyJson.WriteNegotiated(rec, req, http.StatusCreated, value)
+		return rec
+	}
+
+	rec := serve(http.Header{"Accept": {"application/x-ndjson"}})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+	assert.Equal(t, "1\n2\n", rec.Body.String())
+
+	rec = serve(http.Header{"Accept": {"text/html", "application/json"}, "Accept-Charset": {"iso-8859-1, utf-8;q=0.5"}})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "[1,2]", rec.Body.String())
+
+	rec = serve(http.Header{})
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	rec = serve(http.Header{"Accept": {"text/html"}})
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+
+	for _, charsets := range []string{"iso-8859-1", "utf-8;q=0, *", "*;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Charset", charsets)
+		_, err := xyJson.NegotiateRequest(req)
+		assert.Error(t, err, charsets)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Charset", "iso-8859-1, *;q=0.1")
+	_, err := xyJson.NegotiateRequest(req)
+	assert.NoError(t, err)
+}
+
+// TestObjectWriter 测试流式对象写入
+// TestObjectWriter tests streaming object writing
+func TestObjectWriter(t *testing.T) {
+	var buf bytes.Buffer
+	ow := xyJson.NewObjectWriter(&buf)
+
+	require.NoError(t, ow.Begin())
+	require.NoError(t, ow.WriteField("name", xyJson.CreateString("a \"quoted\" name")))
+	require.NoError(t, ow.BeginObject("data"))
+	assert.Equal(t, 2, ow.Depth())
+	require.NoError(t, ow.WriteField("count", xyJson.CreateNumber(3)))
+	require.NoError(t, ow.WriteFieldJSON("items", []byte(" [1, 2, {\"x\": null}] ")))
+	require.NoError(t, ow.BeginObject("empty"))
+	require.NoError(t, ow.End())
+	require.NoError(t, ow.End())
+	require.NoError(t, ow.WriteField("name2", nil))
+	require.NoError(t, ow.End())
+	require.NoError(t, ow.Close())
+
+	assert.Equal(t, `{"name":"a \"quoted\" name","data":{"count":3,"items":[1, 2, {"x": null}],"empty":{}},"name2":null}`, buf.String())
+
+	root, err := xyJson.Parse(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 2, xyJson.MustGetInt(root, "$.data.items[1]"))
+}
+
+// TestObjectWriterErrors 测试流式对象写入的错误处理
+// TestObjectWriterErrors tests error handling of streaming object writing
+func TestObjectWriterErrors(t *testing.T) {
+	t.Run("duplicate_key", func(t *testing.T) {
+		ow := xyJson.NewObjectWriter(&bytes.Buffer{})
+		require.NoError(t, ow.Begin())
+		require.NoError(t, ow.WriteField("a", xyJson.CreateNumber(1)))
+		err := ow.WriteField("a", xyJson.CreateNumber(2))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate key 'a'")
+
+		// 错误是粘滞的
+		// Errors are sticky
+		assert.Equal(t, err, ow.WriteField("b", xyJson.CreateNumber(3)))
+		assert.Equal(t, err, ow.Close())
+	})
+
+	t.Run("duplicate_key_per_level", func(t *testing.T) {
+		ow := xyJson.NewObjectWriter(&bytes.Buffer{})
+		require.NoError(t, ow.Begin())
+		require.NoError(t, ow.WriteField("a", xyJson.CreateNumber(1)))
+		require.NoError(t, ow.BeginObject("nested"))
+		require.NoError(t, ow.WriteField("a", xyJson.CreateNumber(2)))
+		require.NoError(t, ow.End())
+		assert.Error(t, ow.BeginObject("nested"))
+	})
+
+	t.Run("invalid_raw_json", func(t *testing.T) {
+		var buf bytes.Buffer
+		ow := xyJson.NewObjectWriter(&buf)
+		require.NoError(t, ow.Begin())
+		assert.Error(t, ow.WriteFieldJSON("raw", []byte(`{"a":`)))
+	})
+
+	t.Run("unbalanced", func(t *testing.T) {
+		ow := xyJson.NewObjectWriter(&bytes.Buffer{})
+		assert.Error(t, ow.WriteField("a", xyJson.CreateNull()))
+
+		ow = xyJson.NewObjectWriter(&bytes.Buffer{})
+		assert.Error(t, ow.Close())
+
+		ow = xyJson.NewObjectWriter(&bytes.Buffer{})
+		require.NoError(t, ow.Begin())
+		require.NoError(t, ow.BeginObject("open"))
+		assert.Error(t, ow.Close())
+
+		ow = xyJson.NewObjectWriter(&bytes.Buffer{})
+		require.NoError(t, ow.Begin())
+		require.NoError(t, ow.End())
+		assert.Error(t, ow.End())
+
+		ow = xyJson.NewObjectWriter(&bytes.Buffer{})
+		require.NoError(t, ow.Begin())
+		assert.Error(t, ow.Begin())
+	})
+}
+
+// TestSerializeTruncated 测试限长序列化的截断标记
+// TestSerializeTruncated tests the truncation markers of size-bounded serialization
+func TestSerializeTruncated(t *testing.T) {
+	doc := xyJson.MustParseString(`{"name": "` + strings.Repeat("x", 50) + `", "ids": [1, 2, 3, 4, 5, 6],` +
+		` "meta": {"a": 1, "b": 2, "c": {"d": [true]}}, "short": "ok"}`)
+	original := xyJson.MustSerializeToString(doc)
+
+	data, err := xyJson.SerializeTruncated(doc, 1<<20, &xyJson.TruncateOptions{
+		MaxStringLength: 10,
+		MaxArrayItems:   4,
+		MaxObjectKeys:   2,
+		MaxDepth:        2,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"...": "...(+2 keys)",
+		"ids": [1, 2, 3, 4, "...(+2 items)"],
+		"meta": {"...": "...(+1 keys)", "a": 1, "b": 2}
+	}`, string(data))
+
+	data, err = xyJson.SerializeTruncated(doc, 1<<20, &xyJson.TruncateOptions{MaxStringLength: 10, MaxDepth: 2})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ids": [1, 2, 3, 4, 5, 6],
+		"meta": {"a": 1, "b": 2, "c": "...(object, 1 keys)"},
+		"name": "xxxxxxxxxx...(+40 bytes)",
+		"short": "ok"
+	}`, string(data))
+
+	// 预算足够时与普通序列化相同，原文档不被修改
+	// With enough budget the output equals normal serialization, and the document is not modified
+	data, err = xyJson.SerializeTruncated(doc, 1<<20, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, original, string(data))
+	assert.Equal(t, original, xyJson.MustSerializeToString(doc))
+
+	data, err = xyJson.SerializeTruncated(doc, 1<<20, &xyJson.TruncateOptions{Indent: "  "})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  ")
+}
+
+// TestSerializeTruncatedBudget 测试结果始终在预算内且为有效JSON
+// TestSerializeTruncatedBudget tests that the result always fits the budget and is valid JSON
+func TestSerializeTruncatedBudget(t *testing.T) {
+	items := xyJson.CreateArray()
+	for i := 0; i < 5000; i++ {
+		item := xyJson.CreateObject()
+		item.Set("id", i)
+		item.Set("text", strings.Repeat("日本語", 20))
+		items.Append(item)
+	}
+	doc := xyJson.CreateObject()
+	doc.Set("items", items)
+	doc.Set("note", strings.Repeat("n", 4000))
+
+	for _, budget := range []int{100000, 4096, 1000, 200, 60, 40} {
+		data, err := xyJson.SerializeTruncated(doc, budget, nil)
+		require.NoError(t, err, budget)
+		assert.LessOrEqual(t, len(data), budget)
+		assert.True(t, json.Valid(data), string(data))
+	}
+
+	data, err := xyJson.SerializeTruncated(doc, 4096, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "items)")
+
+	data, err = xyJson.SerializeTruncated(doc, 40, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"...":"...(+2 keys)"}`, string(data))
+
+	data, err = xyJson.SerializeTruncated(doc, 21, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"...(object, 2 keys)"`, string(data))
+
+	_, err = xyJson.SerializeTruncated(doc, 10, nil)
+	assert.Error(t, err)
+	_, err = xyJson.SerializeTruncated(doc, 0, nil)
+	assert.Error(t, err)
+	_, err = xyJson.SerializeTruncated(nil, 100, nil)
+	assert.Error(t, err)
+
+	data, err = xyJson.SerializeTruncated(xyJson.CreateString(strings.Repeat("s", 100)), 30, nil)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 30)
+	assert.Contains(t, string(data), "bytes)")
+}
+
+// TestSerializeTruncatedGreedy 测试在预算内按顺序尽量保留成员和元素
+// TestSerializeTruncatedGreedy tests that members and items are kept in order as far as the budget allows
+func TestSerializeTruncatedGreedy(t *testing.T) {
+	doc := xyJson.MustParseString(`{"name": "` + strings.Repeat("x", 50) + `", "ids": [1, 2, 3, 4, 5, 6],` +
+		` "meta": {"a": 1, "b": 2, "c": {"d": [true]}}, "short": "ok"}`)
+
+	data, err := xyJson.SerializeTruncated(doc, 60, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"...":"...(+3 keys)","ids":[1,2,3,4,5,6]}`, string(data))
+
+	// 第一个放不下的成员在剩余预算内继续截断
+	// The first member that does not fit is truncated further within the remaining budget
+	data, err = xyJson.SerializeTruncated(xyJson.MustParseString(`{"a": 1, "items": [10, 11, 12, 13, 14, 15, 16, 17, 18, 19]}`), 40, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1,"items":[10,11,"...(+8 items)"]}`, string(data))
+
+	data, err = xyJson.SerializeTruncated(xyJson.MustParseString(`[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`), 20, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `[1,"...(+9 items)"]`, string(data))
+
+	for budget := 22; budget <= 240; budget++ {
+		data, err = xyJson.SerializeTruncated(doc, budget, &xyJson.TruncateOptions{Indent: "  "})
+		require.NoError(t, err, budget)
+		assert.LessOrEqual(t, len(data), budget)
+		assert.True(t, json.Valid(data), string(data))
+	}
+}
+
+// yieldDocument 创建用于让出测试的嵌套文档
+// yieldDocument creates a nested document for the yield tests
+func yieldDocument(t *testing.T) xyJson.IValue {
+	root, err := xyJson.ParseString(`{"groups":` + testutil.IndexedArray(200, func(i int) string {
+		return `{"id":` + strconv.Itoa(i) + `,"items":[{"price":1},{"price":2}]}`
+	}) + `}`)
+	require.NoError(t, err)
+	return root
+}
+
+// runsDuring 报告在单个P上执行fn期间，另一个已就绪的协程是否得到运行
+// runsDuring reports whether another ready goroutine got to run on a single P while fn was running
+func runsDuring(fn func()) bool {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	var ran atomic.Bool
+	go ran.Store(true)
+	fn()
+	return ran.Load()
+}
+
+// TestSerializeYieldInterval 测试序列化时按间隔让出处理器
+// TestSerializeYieldInterval tests yielding the processor at an interval while serializing
+func TestSerializeYieldInterval(t *testing.T) {
+	root := yieldDocument(t)
+	expected := xyJson.MustSerializeToString(root)
+
+	for _, iterative := range []bool{false, true} {
+		serializer := xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+			MaxDepth:      xyJson.DefaultMaxDepth,
+			Compact:       true,
+			Iterative:     iterative,
+			YieldInterval: 500,
+		})
+		var data []byte
+		var err error
+		assert.True(t, runsDuring(func() { data, err = serializer.Serialize(root) }), "iterative=%v", iterative)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+// TestQueryYieldInterval 测试查询时按间隔让出处理器
+// TestQueryYieldInterval tests yielding the processor at an interval while querying
+func TestQueryYieldInterval(t *testing.T) {
+	root := yieldDocument(t)
+	options := &xyJson.QueryOptions{YieldInterval: 500}
+
+	var prices []xyJson.IValue
+	var err error
+	assert.True(t, runsDuring(func() { prices, err = xyJson.SelectAllWithOptions(root, "$..price", options) }))
+	require.NoError(t, err)
+	assert.Len(t, prices, 400)
+
+	compiled, err := xyJson.CompilePath("$.groups[?(@.id >= 100)].id")
+	require.NoError(t, err)
+	ids, err := compiled.QueryAllWithOptions(root, &xyJson.QueryOptions{Limit: 5, YieldInterval: 10})
+	require.NoError(t, err)
+	require.Len(t, ids, 5)
+	assert.Equal(t, "100", ids[0].String())
+
+	_, err = xyJson.SelectAllWithOptions(root, "$..price", &xyJson.QueryOptions{YieldInterval: -1})
+	assert.Error(t, err)
+}
+
+// TestLogValueJSONHandler 测试通过slog JSON处理器输出文档
+// TestLogValueJSONHandler tests logging documents through the slog JSON handler
+func TestLogValueJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	doc := xyJson.MustParseString(`{"user": {"name": "Alice", "age": 30}, "tags": ["a", {"b": null}], "empty": {},` +
+		` "ratio": 0.5, "ok": true, "none": null}`)
+	logger.Info("request", "body", doc, "id", xyJson.CreateNumber(7))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, float64(7), record["id"])
+
+	body := record["body"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Alice", "age": float64(30)}, body["user"])
+	assert.Equal(t, []interface{}{"a", map[string]interface{}{"b": nil}}, body["tags"])
+	assert.Equal(t, map[string]interface{}{}, body["empty"])
+	assert.Equal(t, 0.5, body["ratio"])
+	assert.Equal(t, true, body["ok"])
+	assert.Contains(t, body, "none")
+	assert.Nil(t, body["none"])
+}
+
+// TestLogValueTextHandler 测试通过slog文本处理器输出文档
+// TestLogValueTextHandler tests logging documents through the slog text handler
+func TestLogValueTextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	doc := xyJson.MustParseString(`{"user": {"name": "Alice"}, "tags": ["a", "b"]}`)
+	logger.Info("request", "body", doc)
+	assert.Contains(t, buf.String(), `body.user.name=Alice`)
+	assert.Contains(t, buf.String(), `body.tags="[\"a\",\"b\"]"`)
+
+	// 级别未启用时不输出
+	// Nothing is output when the level is disabled
+	buf.Reset()
+	logger.Debug("request", "body", doc)
+	assert.Empty(t, buf.String())
+
+	var value slog.LogValuer = xyJson.CreateString("x")
+	assert.Equal(t, "x", value.LogValue().String())
+	assert.Equal(t, slog.KindGroup, doc.LogValue().Kind())
+}
+
+func newSortingSerializer(rules ...xyJson.ArraySortRule) xyJson.ISerializer {
+	return xyJson.NewSerializerWithOptions(&xyJson.SerializeOptions{
+		EscapeHTML: true,
+		Compact:    true,
+		MaxDepth:   xyJson.DefaultMaxDepth,
+		ArraySort:  rules,
+	})
+}
+
+// TestArraySortByKey 测试按键路径排序数组且不修改原文档
+// TestArraySortByKey tests sorting arrays by a key path without modifying the document
+func TestArraySortByKey(t *testing.T) {
+	input := `{"users":[{"id":3,"name":"c"},{"id":1,"name":"a"},{"name":"x"},{"id":2,"name":"b"}]}`
+	doc := xyJson.MustParseString(input)
+
+	out, err := newSortingSerializer(xyJson.ArraySortRule{Path: "$.users", By: ".id"}).SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"users":[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3,"name":"c"},{"name":"x"}]}`, out)
+
+	original, err := xyJson.SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, input, original)
+
+	out, err = newSortingSerializer(xyJson.ArraySortRule{Path: "$.users", By: "id", Descending: true}).SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"users":[{"id":3,"name":"c"},{"id":2,"name":"b"},{"id":1,"name":"a"},{"name":"x"}]}`, out)
+}
+
+// TestArraySortWildcardAndScalars 测试通配符路径、元素自身排序和稳定性
+// TestArraySortWildcardAndScalars tests wildcard paths, sorting by the element itself and stability
+func TestArraySortWildcardAndScalars(t *testing.T) {
+	doc := xyJson.MustParseString(`{"groups":[{"tags":["b","a",1,null]},{"tags":["z","y"]}],` +
+		`"items":[{"k":1,"v":"first"},{"k":0},{"k":1,"v":"second"}]}`)
+
+	out, err := newSortingSerializer(
+		xyJson.ArraySortRule{Path: "$.groups[*].tags"},
+		xyJson.ArraySortRule{Path: "$.items", By: "$.k"},
+	).SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"groups":[{"tags":[null,1,"a","b"]},{"tags":["y","z"]}],`+
+		`"items":[{"k":0},{"k":1,"v":"first"},{"k":1,"v":"second"}]}`, out)
+}
+
+// TestArraySortInvalidPath 测试无效规则路径返回错误
+// TestArraySortInvalidPath tests that an invalid rule path returns an error
+func TestArraySortInvalidPath(t *testing.T) {
+	doc := xyJson.MustParseString(`{"a":[2,1]}`)
+
+	_, err := newSortingSerializer(xyJson.ArraySortRule{Path: "$.a[", By: ""}).Serialize(doc)
+	assert.Error(t, err)
+
+	out, err := newSortingSerializer(xyJson.ArraySortRule{Path: "$.missing"}).SerializeToString(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[2,1]}`, out)
+}
+
+// etag 返回值的实体标签
+// etag returns the entity tag of a value
+func etag(t *testing.T, value xyJson.IValue) string {
+	tag, err := value.ETag()
+	require.NoError(t, err)
+	return tag
+}
+
+// TestVersion 测试每次修改都使版本号变大
+// TestVersion tests that every modification increases the version
+func TestVersion(t *testing.T) {
+	doc := xyJson.MustParseString(`{"a": 1, "list": [{"b": 2}]}`)
+	version := doc.Version()
+	assert.Equal(t, version, doc.Version())
+
+	mutations := []func(){
+		func() { doc.(xyJson.IObject).Set("a", 2) },
+		func() { doc.(xyJson.IObject).Set("a", 3) },
+		func() { require.NoError(t, xyJson.Set(doc, "$.list[0].b", 3)) },
+		func() { xyJson.MustGetArray(doc, "$.list").Append(1) },
+		func() { require.NoError(t, xyJson.Delete(doc, "$.list[1]")) },
+		func() { doc.(xyJson.IObject).Delete("a") },
+	}
+	for i, mutate := range mutations {
+		mutate()
+		next := doc.Version()
+		assert.Greater(t, next, version, "mutation %d", i)
+		version = next
+	}
+
+	assert.Equal(t, uint64(0), xyJson.CreateNumber(1).Version())
+}
+
+// TestVersionDeepTree 测试超过默认最大深度的修改同样使版本号变大
+// TestVersionDeepTree tests that modifications beyond the default maximum depth also increase the version
+func TestVersionDeepTree(t *testing.T) {
+	root := xyJson.CreateArray()
+	leaf := root
+	for i := 0; i < xyJson.DefaultMaxDepth+10; i++ {
+		next := xyJson.CreateArray()
+		leaf.Append(next)
+		leaf = next
+	}
+
+	version := root.Version()
+	leaf.Append(1)
+	assert.Greater(t, root.Version(), version)
+}
+
+// TestETag 测试实体标签由内容决定并随修改变化
+// TestETag tests that entity tags are decided by content and change with modifications
+func TestETag(t *testing.T) {
+	doc := xyJson.MustParseString(`{"b": [1, 2], "a": "x"}`)
+	same := xyJson.MustParseString(`{"a": "x", "b": [1, 2]}`)
+
+	tag := etag(t, doc)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, tag)
+	assert.Equal(t, tag, etag(t, doc))
+	assert.Equal(t, tag, etag(t, same))
+
+	require.NoError(t, xyJson.Set(doc, "$.b[0]", 5))
+	changed := etag(t, doc)
+	assert.NotEqual(t, tag, changed)
+
+	// 改回原内容后标签也恢复
+	// Restoring the content restores the tag
+	require.NoError(t, xyJson.Set(doc, "$.b[0]", 1))
+	assert.Equal(t, tag, etag(t, doc))
+
+	assert.NotEmpty(t, etag(t, xyJson.CreateString("s")))
+}
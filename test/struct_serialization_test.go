@@ -2,11 +2,21 @@ package test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	. "github.com/ihuem/xyJson"
+	xyJson "github.com/ihuem/xyJson"
 )
 
 // 测试用的结构体定义
@@ -351,6 +361,76 @@ func TestSerializeToStruct_ErrorCases(t *testing.T) {
 	}
 }
 
+// scalarKinds 覆盖所有直接写入的标量字段类型
+type scalarKinds struct {
+	Name    string
+	Label   label
+	Flag    bool
+	I       int
+	I8      int8
+	I16     int16
+	I32     int32
+	I64     int64
+	U       uint
+	U8      uint8
+	U16     uint16
+	U32     uint32
+	U64     uint64
+	F32     float32
+	F64     float64
+	Nested  Person
+	Pointer *Person
+}
+
+type label string
+
+// TestSerializeToStruct_ScalarSetters 测试标量字段的直接写入与反射路径结果一致
+func TestSerializeToStruct_ScalarSetters(t *testing.T) {
+	jsonData := `{
+		"Name": "n", "Label": "l", "Flag": true,
+		"I": -1, "I8": -128, "I16": 32767, "I32": -2147483648, "I64": 9007199254740993,
+		"U": 1, "U8": 255, "U16": 65535, "U32": 4294967295, "U64": 42,
+		"F32": 1.5, "F64": -2.25,
+		"Nested": {"name": "a", "age": 1},
+		"Pointer": {"name": "b", "age": 2}
+	}`
+
+	var got, want scalarKinds
+	if err := UnmarshalStringToStruct(jsonData, &got); err != nil {
+		t.Fatalf("UnmarshalStringToStruct failed: %v", err)
+	}
+	if err := json.Unmarshal([]byte(jsonData), &want); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// 超出范围和类型不匹配仍然报错
+	errorCases := []string{
+		`{"I8": 128}`,
+		`{"U8": 256}`,
+		`{"U": -1}`,
+		`{"Flag": "true"}`,
+		`{"F64": "1.5"}`,
+	}
+	for _, data := range errorCases {
+		var target scalarKinds
+		if err := UnmarshalStringToStruct(data, &target); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+
+	// null保持反射路径的零值语义
+	target := scalarKinds{Name: "keep", I: 7}
+	if err := UnmarshalStringToStruct(`{"Name": null, "I": null}`, &target); err != nil {
+		t.Fatalf("UnmarshalStringToStruct failed: %v", err)
+	}
+	if target.Name != "" || target.I != 0 {
+		t.Errorf("Expected zero values for null, got %+v", target)
+	}
+}
+
 // TestMustSerializeToStruct 测试Must版本函数
 func TestMustSerializeToStruct(t *testing.T) {
 	jsonData := `{"name":"Test User","age":30}`
@@ -721,3 +801,1127 @@ func BenchmarkUnmarshalToStructFast(b *testing.B) {
 		}
 	})
 }
+
+// discShape 判别字段测试用的多态接口
+// discShape is the polymorphic interface used by the discriminator tests
+type discShape interface {
+	Area() float64
+}
+
+type discCircle struct {
+	Type   string  `json:"type"`
+	Radius float64 `json:"r"`
+}
+
+func (c discCircle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type discRect struct {
+	Type   string  `json:"type"`
+	Width  float64 `json:"w"`
+	Height float64 `json:"h"`
+}
+
+// Area 使用指针接收者，解码结果为*discRect
+// Area uses a pointer receiver, so the decoded value is a *discRect
+func (r *discRect) Area() float64 { return r.Width * r.Height }
+
+type discDrawing struct {
+	Name   string               `json:"name"`
+	Main   discShape            `json:"main"`
+	Shapes []discShape          `json:"shapes"`
+	ByName map[string]discShape `json:"byName"`
+}
+
+var discShapeType = reflect.TypeOf((*discShape)(nil)).Elem()
+
+// registerShapes 注册测试用判别字段并在测试结束时删除
+// registerShapes registers the test discriminator and removes it when the test ends
+func registerShapes(t *testing.T) {
+	require.NoError(t, xyJson.RegisterDiscriminator(discShapeType, "type", map[string]reflect.Type{
+		"circle": reflect.TypeOf(discCircle{}),
+		"rect":   reflect.TypeOf(discRect{}),
+	}))
+	t.Cleanup(func() { _ = xyJson.RegisterDiscriminator(discShapeType, "", nil) })
+}
+
+const discDrawingJSON = `{
+	"name": "demo",
+	"main": {"type": "circle", "r": 1},
+	"shapes": [{"type": "circle", "r": 2}, {"type": "rect", "w": 2, "h": 3}, null],
+	"byName": {"box": {"type": "rect", "w": 1, "h": 1}}
+}`
+
+// TestRegisterDiscriminator 测试按判别字段解码多态值
+// TestRegisterDiscriminator tests decoding polymorphic values by discriminator field
+func TestRegisterDiscriminator(t *testing.T) {
+	registerShapes(t)
+
+	decoders := map[string]func(string, interface{}) error{
+		"Unmarshal": func(data string, target interface{}) error { return xyJson.UnmarshalString(data, target) },
+		"SerializeToStruct": func(data string, target interface{}) error {
+			return xyJson.SerializeToStruct(xyJson.MustParseString(data), target)
+		},
+		"DecodeStrictInto": xyJson.DecodeStrictIntoString,
+	}
+	for name, decode := range decoders {
+		var drawing discDrawing
+		require.NoError(t, decode(discDrawingJSON, &drawing), name)
+
+		assert.Equal(t, "demo", drawing.Name, name)
+		assert.Equal(t, discCircle{Type: "circle", Radius: 1}, drawing.Main, name)
+		require.Len(t, drawing.Shapes, 3, name)
+		assert.Equal(t, discCircle{Type: "circle", Radius: 2}, drawing.Shapes[0], name)
+		assert.Equal(t, &discRect{Type: "rect", Width: 2, Height: 3}, drawing.Shapes[1], name)
+		assert.Nil(t, drawing.Shapes[2], name)
+		assert.Equal(t, 1.0, drawing.ByName["box"].Area(), name)
+	}
+}
+
+// TestRegisterDiscriminatorErrors 测试判别字段缺失、未知和注册错误
+// TestRegisterDiscriminatorErrors tests missing and unknown discriminators and registration errors
+func TestRegisterDiscriminatorErrors(t *testing.T) {
+	registerShapes(t)
+
+	invalid := map[string]string{
+		`{"main": {"type": "triangle"}}`: "unknown discriminator value 'triangle'",
+		`{"main": {"r": 1}}`:             "type",
+		`{"main": {"type": 1}}`:          "discriminator 'type'",
+		`{"main": [1]}`:                  "",
+	}
+	for data, message := range invalid {
+		var drawing discDrawing
+		err := xyJson.UnmarshalString(data, &drawing)
+		require.Error(t, err, data)
+		assert.Contains(t, err.Error(), message, data)
+
+		err = xyJson.DecodeStrictIntoString(data, &drawing)
+		var strictErr *xyJson.StrictDecodeError
+		require.True(t, errors.As(err, &strictErr), data)
+		assert.Equal(t, "$.main", strictErr.Fields[0].Path, data)
+	}
+
+	// 未注册的接口仍解码为原始值
+	// Unregistered interfaces still decode to raw values
+	require.NoError(t, xyJson.RegisterDiscriminator(discShapeType, "", nil))
+	var raw struct {
+		Main interface{} `json:"main"`
+	}
+	require.NoError(t, xyJson.UnmarshalString(`{"main": {"type": "circle"}}`, &raw))
+	assert.Equal(t, map[string]interface{}{"type": "circle"}, raw.Main)
+
+	assert.Error(t, xyJson.RegisterDiscriminator(reflect.TypeOf(discCircle{}), "type",
+		map[string]reflect.Type{"circle": reflect.TypeOf(discCircle{})}))
+	assert.Error(t, xyJson.RegisterDiscriminator(discShapeType, "",
+		map[string]reflect.Type{"circle": reflect.TypeOf(discCircle{})}))
+	assert.Error(t, xyJson.RegisterDiscriminator(discShapeType, "type",
+		map[string]reflect.Type{"num": reflect.TypeOf(0)}))
+}
+
+type documentEvent struct {
+	Type     string          `json:"type"`
+	Payload  xyJson.IValue   `json:"payload"`
+	Meta     xyJson.IObject  `json:"meta"`
+	Tags     xyJson.IArray   `json:"tags"`
+	Optional xyJson.IValue   `json:"optional"`
+	Nested   []xyJson.IValue `json:"nested"`
+	ByName   map[string]xyJson.IObject
+}
+
+// TestDocumentFieldsPassthrough 测试IValue、IObject和IArray字段直接接收子树
+// TestDocumentFieldsPassthrough tests that IValue, IObject and IArray fields receive subtrees directly
+func TestDocumentFieldsPassthrough(t *testing.T) {
+	root := xyJson.MustParseString(`{
+		"type": "click", "payload": {"x": 1, "y": [2, 3]}, "meta": {"source": "web"},
+		"tags": ["a", "b"], "optional": null, "nested": [1, {"k": true}], "ByName": {"n": {"v": 1}}
+	}`)
+
+	var event documentEvent
+	require.NoError(t, xyJson.SerializeToStruct(root, &event))
+
+	assert.Equal(t, "click", event.Type)
+	require.NotNil(t, event.Payload)
+	assert.Same(t, root.AsObject().Get("payload"), event.Payload)
+	assert.Equal(t, int64(3), xyJson.MustGetInt64(event.Payload, "$.y[1]"))
+	assert.Equal(t, "web", event.Meta.Get("source").String())
+	assert.Equal(t, 2, event.Tags.Length())
+	require.NotNil(t, event.Optional)
+	assert.True(t, event.Optional.IsNull())
+	require.Len(t, event.Nested, 2)
+	assert.Equal(t, xyJson.ObjectValueType, event.Nested[1].Type())
+	assert.Equal(t, 1, event.ByName["n"].Size())
+
+	var fromUnmarshal documentEvent
+	require.NoError(t, xyJson.UnmarshalString(`{"payload": [1], "meta": null}`, &fromUnmarshal))
+	assert.Equal(t, xyJson.ArrayValueType, fromUnmarshal.Payload.Type())
+	assert.Nil(t, fromUnmarshal.Meta)
+}
+
+// TestDocumentFieldsMismatch 测试IObject和IArray字段拒绝其它类型
+// TestDocumentFieldsMismatch tests that IObject and IArray fields reject other types
+func TestDocumentFieldsMismatch(t *testing.T) {
+	var event documentEvent
+	err := xyJson.UnmarshalString(`{"meta": [1]}`, &event)
+	var jsonErr *xyJson.JSONError
+	require.True(t, errors.As(err, &jsonErr), "%v", err)
+	assert.Equal(t, xyJson.ErrTypeMismatch, jsonErr.Code)
+
+	err = xyJson.DecodeStrictIntoString(`{"meta": 1, "tags": {}, "payload": "ok"}`, &event)
+	var strictErr *xyJson.StrictDecodeError
+	require.True(t, errors.As(err, &strictErr), "%v", err)
+	require.Len(t, strictErr.Fields, 2)
+	assert.Equal(t, "$.meta", strictErr.Fields[0].Path)
+	assert.Equal(t, "$.tags", strictErr.Fields[1].Path)
+	assert.Equal(t, "ok", event.Payload.String())
+}
+
+type regionCode int
+
+func (r regionCode) String() string { return fmt.Sprintf("R%02d", int(r)) }
+
+type textKey struct{ a, b string }
+
+func (k textKey) MarshalText() ([]byte, error) { return []byte(k.a + "/" + k.b), nil }
+
+type failingKey struct{}
+
+func (failingKey) MarshalText() ([]byte, error) { return nil, errors.New("boom") }
+
+// TestCreateFromRawNumericMapKeys 测试整数、Stringer和TextMarshaler键被转换为字符串
+// TestCreateFromRawNumericMapKeys tests that integer, Stringer and TextMarshaler keys are stringified
+func TestCreateFromRawNumericMapKeys(t *testing.T) {
+	value, err := xyJson.CreateFromRaw(map[string]interface{}{
+		"ints":    map[int]string{1: "a", -2: "b"},
+		"int64s":  map[int64]bool{9007199254740993: true},
+		"uints":   map[uint8]int{7: 1},
+		"regions": map[regionCode]int{3: 30},
+		"text":    map[textKey]int{{"x", "y"}: 1},
+	})
+	require.NoError(t, err)
+
+	out, err := xyJson.SerializeToString(value)
+	require.NoError(t, err)
+	assert.Equal(t, `{"int64s":{"9007199254740993":true},"ints":{"-2":"b","1":"a"},"regions":{"R03":30},`+
+		`"text":{"x/y":1},"uints":{"7":1}}`, out)
+
+	_, err = xyJson.CreateFromRaw(map[failingKey]int{{}: 1})
+	assert.Error(t, err)
+}
+
+// TestValueFactoryCustomMapKey 测试通过工厂选项自定义map键转换
+// TestValueFactoryCustomMapKey tests customizing map key conversion through factory options
+func TestValueFactoryCustomMapKey(t *testing.T) {
+	factory := xyJson.NewValueFactoryWithOptions(&xyJson.ValueFactoryOptions{
+		MapKey: func(key interface{}) (string, error) {
+			if n, ok := key.(int); ok {
+				if n < 0 {
+					return "", errors.New("negative key")
+				}
+				return fmt.Sprintf("id-%d", n), nil
+			}
+			return xyJson.DefaultMapKey(key)
+		},
+	})
+
+	value, err := factory.CreateFromRaw(map[int]string{1: "a", 2: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id-1", "id-2"}, value.AsObject().Keys())
+
+	_, err = factory.CreateFromRaw(map[int]string{-1: "a"})
+	assert.Error(t, err)
+
+	value, err = xyJson.NewValueFactoryWithOptions(nil).CreateFromRaw(map[regionCode]int{1: 1})
+	require.NoError(t, err)
+	assert.True(t, value.AsObject().Has("R01"))
+}
+
+type strictItem struct {
+	SKU   string `json:"sku"`
+	Qty   uint8  `json:"qty"`
+	Price float32
+}
+
+type strictOrder struct {
+	ID       int               `json:"id"`
+	Customer string            `json:"customer"`
+	Paid     bool              `json:"paid"`
+	Created  time.Time         `json:"created"`
+	Items    []strictItem      `json:"items"`
+	Notes    *string           `json:"notes"`
+	Labels   map[string]int16  `json:"labels"`
+	Extra    interface{}       `json:"extra"`
+	Codes    [2]int            `json:"codes"`
+	Meta     map[string]string `json:"-"`
+}
+
+// TestDecodeStrictIntoValid 测试有效文档正常解码
+// TestDecodeStrictIntoValid tests that valid documents decode normally
+func TestDecodeStrictIntoValid(t *testing.T) {
+	var order strictOrder
+	require.NoError(t, xyJson.DecodeStrictIntoString(`{
+		"id": 7, "customer": "Ann", "paid": true, "created": "2024-05-01T10:00:00Z",
+		"items": [{"sku": "a", "qty": 2, "Price": 1.5}], "notes": "x",
+		"labels": {"l": -3}, "extra": [1, "two"], "codes": [4, 5], "unknown": 1, "Meta": {"k": "v"}
+	}`, &order))
+
+	assert.Equal(t, 7, order.ID)
+	assert.True(t, order.Paid)
+	assert.Equal(t, 2024, order.Created.Year())
+	assert.Equal(t, []strictItem{{SKU: "a", Qty: 2, Price: 1.5}}, order.Items)
+	require.NotNil(t, order.Notes)
+	assert.Equal(t, "x", *order.Notes)
+	assert.Equal(t, map[string]int16{"l": -3}, order.Labels)
+	assert.Equal(t, []interface{}{int64(1), "two"}, order.Extra)
+	assert.Equal(t, [2]int{4, 5}, order.Codes)
+	assert.Nil(t, order.Meta)
+}
+
+// TestDecodeStrictIntoCollectsErrors 测试收集所有字段错误及其路径
+// TestDecodeStrictIntoCollectsErrors tests that every field error is collected with its path
+func TestDecodeStrictIntoCollectsErrors(t *testing.T) {
+	var order strictOrder
+	err := xyJson.DecodeStrictIntoString(`{
+		"id": 1.5, "customer": 42, "paid": "yes", "created": "yesterday",
+		"items": [{"sku": "a", "qty": 300}, {"sku": "b", "qty": -1, "Price": 1e40}, "c"],
+		"labels": {"ok": 1, "big": 40000}, "codes": [1, 2, 3]
+	}`, &order)
+
+	var strictErr *xyJson.StrictDecodeError
+	require.True(t, errors.As(err, &strictErr), "%v", err)
+
+	paths := make([]string, len(strictErr.Fields))
+	for i, field := range strictErr.Fields {
+		paths[i] = field.Path
+		assert.Equal(t, field.Path, field.Err.Path)
+	}
+	assert.Equal(t, []string{
+		"$.codes", "$.created", "$.customer", "$.id",
+		"$.items[0].qty", "$.items[1].Price", "$.items[1].qty", "$.items[2]",
+		"$.labels.big", "$.paid",
+	}, paths)
+
+	fields := strictErr.Fields
+	assert.Equal(t, "int", fields[3].GoType)
+	assert.Equal(t, xyJson.ErrNumberConversion, fields[3].Err.Code)
+	assert.Contains(t, fields[3].Err.Message, "fractional part")
+	assert.Contains(t, fields[1].Err.Error(), "invalid time format")
+	assert.Contains(t, fields[4].Err.Message, "out of uint8 range")
+	assert.Contains(t, strictErr.Error(), "10 fields could not be decoded")
+
+	// 有效字段照常写入
+	// Valid fields are still written
+	assert.Equal(t, "a", order.Items[0].SKU)
+	assert.Equal(t, int16(1), order.Labels["ok"])
+	assert.Equal(t, [2]int{1, 2}, order.Codes)
+	assert.Zero(t, order.ID)
+
+	var jsonErr *xyJson.JSONError
+	assert.True(t, errors.As(err, &jsonErr))
+}
+
+// TestDecodeStrictIntoFailures 测试非字段错误直接返回
+// TestDecodeStrictIntoFailures tests that errors other than field errors are returned directly
+func TestDecodeStrictIntoFailures(t *testing.T) {
+	var order strictOrder
+	err := xyJson.DecodeStrictIntoString(`{"id":`, &order)
+	require.Error(t, err)
+	var strictErr *xyJson.StrictDecodeError
+	assert.False(t, errors.As(err, &strictErr))
+
+	assert.Error(t, xyJson.DecodeStrictIntoString(`{}`, order))
+	assert.Error(t, xyJson.DecodeStrictIntoString(`{}`, nil))
+
+	var n uint64
+	require.NoError(t, xyJson.DecodeStrictIntoString(`1e19`, &n))
+	assert.Equal(t, uint64(1e19), n)
+
+	err = xyJson.DecodeStrictIntoString(`[1, 2]`, &order)
+	require.True(t, errors.As(err, &strictErr))
+	require.Len(t, strictErr.Fields, 1)
+	assert.Equal(t, "$", strictErr.Fields[0].Path)
+}
+
+// TestStructCacheEviction 测试结构体信息缓存按时钟算法淘汰
+// TestStructCacheEviction tests the clock eviction of the struct info cache
+func TestStructCacheEviction(t *testing.T) {
+	xyJson.ClearStructCache()
+	xyJson.SetStructCacheSize(2)
+	defer xyJson.SetStructCacheSize(0)
+	defer xyJson.ClearStructCache()
+
+	type a struct{ N int }
+	type b struct{ N int }
+	type c struct{ N int }
+	decode := func(target any) {
+		require.NoError(t, xyJson.UnmarshalStringToStruct(`{"N":1}`, target))
+	}
+
+	before := xyJson.GetStructCacheStats()
+	decode(&a{})
+	decode(&b{})
+	decode(&a{})
+	decode(&c{}) // a被访问过而b没有 a was used since insertion and b was not
+	decode(&a{})
+
+	stats := xyJson.GetStructCacheStats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, 2, stats.MaxSize)
+	assert.Equal(t, uint64(1), stats.Evictions-before.Evictions)
+	assert.Equal(t, uint64(3), stats.Misses-before.Misses)
+	assert.Equal(t, uint64(2), stats.Hits-before.Hits)
+
+	decode(&b{})
+	stats = xyJson.GetStructCacheStats()
+	assert.Equal(t, uint64(4), stats.Misses-before.Misses, "b was evicted")
+
+	xyJson.SetStructCacheSize(1)
+	assert.Equal(t, 1, xyJson.GetStructCacheStats().Size)
+	xyJson.ClearStructCache()
+	assert.Equal(t, 0, xyJson.GetStructCacheStats().Size)
+
+	xyJson.SetStructCacheSize(0)
+	assert.Equal(t, xyJson.StructCacheSize, xyJson.GetStructCacheStats().MaxSize)
+}
+
+// TestStructCacheManyTypes 测试解码大量不同类型时缓存保持在上限内且结果正确
+// TestStructCacheManyTypes tests that decoding many distinct types keeps the cache within its limit with
+// correct results
+func TestStructCacheManyTypes(t *testing.T) {
+	xyJson.SetStructCacheSize(8)
+	defer xyJson.SetStructCacheSize(0)
+	defer xyJson.ClearStructCache()
+
+	for i := 0; i < 50; i++ {
+		typ := reflect.StructOf([]reflect.StructField{{
+			Name: "V",
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(`json:"v` + string(rune('a'+i%26)) + string(rune('a'+i/26)) + `"`),
+		}})
+		target := reflect.New(typ)
+		data := `{"v` + string(rune('a'+i%26)) + string(rune('a'+i/26)) + `":` + "7}"
+		require.NoError(t, xyJson.UnmarshalStringToStruct(data, target.Interface()))
+		assert.Equal(t, 7, int(target.Elem().Field(0).Int()))
+		assert.LessOrEqual(t, xyJson.GetStructCacheStats().Size, 8)
+	}
+}
+
+// TestCustomParserSharedCache 测试自定义解析器使用全局缓存并可被多个协程共享
+// TestCustomParserSharedCache tests that custom parsers use the global cache and can be shared between goroutines
+func TestCustomParserSharedCache(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	xyJson.ClearStructCache()
+	defer xyJson.ClearStructCache()
+
+	var first item
+	require.NoError(t, xyJson.NewCustomParser().UnmarshalDirectString(`{"id":1,"name":"a"}`, &first))
+	before := xyJson.GetStructCacheStats()
+	require.NoError(t, xyJson.NewCustomParser().UnmarshalDirectString(`{"id":2,"name":"b"}`, &first))
+	after := xyJson.GetStructCacheStats()
+	assert.Equal(t, before.Misses, after.Misses, "a new parser reuses the cached struct info")
+	assert.Equal(t, item{ID: 2, Name: "b"}, first)
+
+	parser := xyJson.NewCustomParser()
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var got item
+			data := `{"id":` + strconv.Itoa(i) + `,"name":"n` + strconv.Itoa(i) + `"}`
+			if err := parser.UnmarshalDirectString(data, &got); err != nil {
+				errs <- err
+				return
+			}
+			if got.ID != i || got.Name != "n"+strconv.Itoa(i) {
+				errs <- assert.AnError
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+type validatedAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"len=5,regexp=^[0-9]{3,5}$"`
+}
+
+type validatedSignup struct {
+	Name    string             `json:"name" validate:"required,max=8"`
+	Age     int                `json:"age" validate:"min=13,max=120"`
+	Email   string             `json:"email" validate:"regexp=^[^@,]+@[^@]+$"`
+	Plan    string             `json:"plan" validate:"oneof=free pro"`
+	Tags    []string           `json:"tags" validate:"max=2"`
+	Score   *float64           `json:"score" validate:"min=0.5"`
+	Address *validatedAddress  `json:"address"`
+	Extra   []validatedAddress `json:"extra"`
+}
+
+// TestValidateTagsPass 测试满足规则的输入正常解码
+// TestValidateTagsPass tests that input satisfying the rules decodes normally
+func TestValidateTagsPass(t *testing.T) {
+	input := `{"name":"ann","age":30,"email":"a@b.c","plan":"pro","tags":["x"],"address":{"city":"Oslo","zip":"01234"}}`
+
+	var signup validatedSignup
+	require.NoError(t, xyJson.UnmarshalString(input, &signup))
+	assert.Equal(t, "Oslo", signup.Address.City)
+
+	var fromValue validatedSignup
+	require.NoError(t, xyJson.SerializeToStruct(xyJson.MustParseString(input), &fromValue))
+	assert.Equal(t, signup, fromValue)
+}
+
+// TestValidateTagsAggregate 测试所有字段错误被汇总并带有JSONPath
+// TestValidateTagsAggregate tests that every field error is aggregated with its JSONPath
+func TestValidateTagsAggregate(t *testing.T) {
+	input := `{"name":"","age":7,"email":"nope","plan":"gold","tags":["a","b","c"],"score":0.1,` +
+		`"address":{"city":"","zip":"12"},"extra":[{"city":"x","zip":"12345"},{"zip":"abcde"}]}`
+
+	for _, strategy := range []xyJson.DecodeStrategy{xyJson.DecodeAuto, xyJson.DecodeValue, xyJson.DecodeStdlib} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			var signup validatedSignup
+			err := xyJson.UnmarshalString(input, &signup, &xyJson.UnmarshalOptions{Strategy: strategy})
+
+			var validationErr *xyJson.ValidationError
+			require.True(t, errors.As(err, &validationErr), "%v", err)
+
+			var paths []string
+			for _, field := range validationErr.Fields {
+				paths = append(paths, field.Path)
+				assert.Equal(t, xyJson.ErrValidation, field.Err.Code)
+			}
+			assert.Equal(t, []string{
+				"$.name", "$.age", "$.email", "$.plan", "$.tags", "$.score",
+				"$.address.city", "$.address.zip", "$.address.zip",
+				"$.extra[1].city", "$.extra[1].zip",
+			}, paths)
+			assert.Equal(t, "is required", validationErr.Fields[0].Err.Message)
+			assert.Equal(t, "must be at least 13", validationErr.Fields[1].Err.Message)
+			assert.Equal(t, "must be one of [free pro]", validationErr.Fields[3].Err.Message)
+			assert.Equal(t, "length must be at most 2", validationErr.Fields[4].Err.Message)
+			assert.Equal(t, "*float64", validationErr.Fields[5].GoType)
+			assert.Contains(t, err.Error(), "11 fields failed validation")
+		})
+	}
+}
+
+// TestValidateTagsSkipAndDirect 测试跳过检查和直接解码策略
+// TestValidateTagsSkipAndDirect tests skipping the checks and the direct decoding strategy
+func TestValidateTagsSkipAndDirect(t *testing.T) {
+	type limits struct {
+		RPS   int    `json:"rps" validate:"min=1,max=1000"`
+		Label string `json:"label" validate:"regexp=^[a-z]+(,[a-z]+)*$"`
+	}
+
+	var l limits
+	err := xyJson.UnmarshalString(`{"rps":0,"label":"a,b"}`, &l, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeDirect})
+	var validationErr *xyJson.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Fields, 1)
+	assert.Equal(t, "$.rps", validationErr.Fields[0].Path)
+
+	require.NoError(t, xyJson.UnmarshalString(`{"rps":0}`, &l, &xyJson.UnmarshalOptions{SkipValidation: true}))
+	assert.NoError(t, xyJson.ValidateStruct(&limits{RPS: 5, Label: "a,b"}))
+}
+
+// TestValidateTagsInvalid 测试无效的标签返回错误
+// TestValidateTagsInvalid tests that invalid tags return an error
+func TestValidateTagsInvalid(t *testing.T) {
+	type badRule struct {
+		B bool `json:"b" validate:"oneof=true false"`
+	}
+	type badRegexp struct {
+		N int `json:"n" validate:"regexp=^a$"`
+	}
+	type badBound struct {
+		S string `json:"s" validate:"min=x"`
+	}
+
+	for _, target := range []interface{}{&badRule{}, &badRegexp{}, &badBound{}} {
+		err := xyJson.UnmarshalString(`{}`, target)
+		var jsonErr *xyJson.JSONError
+		require.True(t, errors.As(err, &jsonErr), "%T", target)
+		assert.Equal(t, xyJson.ErrInvalidOperation, jsonErr.Code)
+	}
+	assert.Error(t, xyJson.ValidateStruct(nil))
+}
+
+// TestValidateTagsCompatibility 测试其它校验库的规则被忽略，SerializeToStruct不检查标签
+// TestValidateTagsCompatibility tests that rules of other validation libraries are ignored and that
+// SerializeToStruct does not check tags
+func TestValidateTagsCompatibility(t *testing.T) {
+	type playground struct {
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"gte=0,lte=130,max=150"`
+	}
+
+	var p playground
+	require.NoError(t, xyJson.UnmarshalString(`{"email":"a@b.c","age":30}`, &p))
+	var invalid playground
+	err := xyJson.UnmarshalString(`{"age":200}`, &invalid)
+	var validationErr *xyJson.ValidationError
+	require.True(t, errors.As(err, &validationErr), "%v", err)
+	assert.Len(t, validationErr.Fields, 2)
+
+	var signup validatedSignup
+	require.NoError(t, xyJson.SerializeToStruct(xyJson.MustParseString(`{"age":7}`), &signup))
+	assert.Equal(t, 7, signup.Age)
+	assert.Error(t, xyJson.ValidateStruct(&signup))
+}
+
+type unmarshalPlain struct {
+	ID     int64          `json:"id"`
+	Name   string         `json:"name"`
+	Active bool           `json:"active"`
+	Score  float64        `json:"score"`
+	Tags   []string       `json:"tags"`
+	Items  []unmarshalRow `json:"items"`
+	Hidden string         `json:"-"`
+}
+
+type unmarshalRow struct {
+	Key   string `json:"key"`
+	Count uint16 `json:"count"`
+}
+
+type unmarshalRich struct {
+	Name    string                 `json:"name"`
+	Created time.Time              `json:"created"`
+	Extra   map[string]interface{} `json:"extra"`
+	Parent  *unmarshalRow          `json:"parent"`
+	Any     interface{}            `json:"any"`
+}
+
+// TestUnmarshal 测试统一解码入口在各种目标上与encoding/json一致
+// TestUnmarshal tests that the unified decoding entry point matches encoding/json across targets
+func TestUnmarshal(t *testing.T) {
+	t.Run("plain_struct", func(t *testing.T) {
+		data := []byte(`{"id": 7, "name": "a\"b", "active": true, "score": 1.5, "tags": ["x", "y"],
+			"items": [{"key": "k1", "count": 3}, {"key": "k2", "count": 4}], "unknown": {"nested": [1, null]}, "Hidden": "h"}`)
+
+		var got, want unmarshalPlain
+		require.NoError(t, xyJson.Unmarshal(data, &got))
+		require.NoError(t, json.Unmarshal(data, &want))
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("unicode_escape", func(t *testing.T) {
+		var got unmarshalPlain
+		require.NoError(t, xyJson.UnmarshalString(`{"name": "caf\u00e9"}`, &got))
+		assert.Equal(t, "café", got.Name)
+
+		// 策略只由类型决定，直接解码也处理代理对和孤立代理
+		// The strategy depends on the type alone, direct decoding also handles surrogate pairs and lone surrogates
+		data := []byte(`{"name": "\ud83d\ude00 \u4E2D \ud800x", "tags": ["\u0041\n"]}`)
+		var direct, want unmarshalPlain
+		require.NoError(t, xyJson.Unmarshal(data, &direct, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeDirect}))
+		require.NoError(t, json.Unmarshal(data, &want))
+		assert.Equal(t, want, direct)
+		assert.Error(t, xyJson.UnmarshalString(`{"name": "\u12g4"}`, &direct, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeDirect}))
+	})
+
+	t.Run("rich_struct", func(t *testing.T) {
+		data := `{"name": "r", "created": "2024-01-02T03:04:05Z", "extra": {"a": 1}, "parent": {"key": "p", "count": 1}, "any": [true]}`
+
+		var got unmarshalRich
+		require.NoError(t, xyJson.UnmarshalString(data, &got))
+		assert.Equal(t, 2024, got.Created.Year())
+		assert.Equal(t, "p", got.Parent.Key)
+		assert.Contains(t, got.Extra, "a")
+		assert.NotNil(t, got.Any)
+	})
+
+	t.Run("non_struct_targets", func(t *testing.T) {
+		var m map[string]int
+		require.NoError(t, xyJson.UnmarshalString(`{"a": 1, "b": 2}`, &m))
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, m)
+
+		var s []string
+		require.NoError(t, xyJson.UnmarshalString(`["x", "y"]`, &s))
+		assert.Equal(t, []string{"x", "y"}, s)
+
+		var v xyJson.IValue
+		require.NoError(t, xyJson.UnmarshalString(`{"a": [1]}`, &v))
+		assert.Equal(t, 1, xyJson.MustGetInt(v, "$.a[0]"))
+	})
+
+	t.Run("explicit_strategies", func(t *testing.T) {
+		data := []byte(`{"id": 1, "name": "n", "tags": ["t"]}`)
+		for _, strategy := range []xyJson.DecodeStrategy{xyJson.DecodeDirect, xyJson.DecodeValue, xyJson.DecodeStdlib} {
+			var got unmarshalPlain
+			require.NoError(t, xyJson.Unmarshal(data, &got, &xyJson.UnmarshalOptions{Strategy: strategy}), strategy.String())
+			assert.Equal(t, unmarshalPlain{ID: 1, Name: "n", Tags: []string{"t"}}, got, strategy.String())
+		}
+	})
+
+	t.Run("strategies_agree", func(t *testing.T) {
+		// 两种策略拒绝相同的输入 Both strategies reject the same input
+		for _, input := range []string{`{"id":1} x`, `{"id":1}{"id":2}`, `{"id":01}`, `{"id":-01}`, `{"id":1.}`, `{"id":1,"id":2}`} {
+			for _, strategy := range []xyJson.DecodeStrategy{xyJson.DecodeAuto, xyJson.DecodeDirect, xyJson.DecodeValue} {
+				var got unmarshalPlain
+				assert.Error(t, xyJson.UnmarshalString(input, &got, &xyJson.UnmarshalOptions{Strategy: strategy}), "%s %s", input, strategy)
+			}
+		}
+
+		// 两种策略以相同方式转换写入字符串字段的值 Both strategies convert values decoded into string fields the same way
+		data := `{"name": 1.5, "tags": [true, 10, {"a": 1}, [2]], "items": [{"key": false}]}`
+		var direct, value unmarshalPlain
+		require.NoError(t, xyJson.UnmarshalString(data, &direct, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeDirect}))
+		require.NoError(t, xyJson.UnmarshalString(data, &value, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeValue}))
+		assert.Equal(t, value, direct)
+		assert.Equal(t, "1.5", direct.Name)
+		assert.Equal(t, []string{"true", "10", "", ""}, direct.Tags)
+		assert.Equal(t, "false", direct.Items[0].Key)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var got unmarshalPlain
+		assert.Error(t, xyJson.UnmarshalString(`{"id": "x"}`, &got))
+		assert.Error(t, xyJson.UnmarshalString(`{"id": 1`, &got, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeValue}))
+		assert.Error(t, xyJson.UnmarshalString(`{}`, got))
+		assert.Error(t, xyJson.UnmarshalString(`{}`, nil))
+		assert.Error(t, xyJson.UnmarshalString(`{}`, (*unmarshalPlain)(nil)))
+		assert.Error(t, xyJson.UnmarshalString(`{}`, &got, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeStrategy(99)}))
+		assert.Panics(t, func() { xyJson.MustUnmarshal([]byte(`[`), &got) })
+	})
+}
+
+type unmarshalPatch struct {
+	Name    string            `json:"name"`
+	Email   *string           `json:"email"`
+	Age     int               `json:"age"`
+	Tags    []string          `json:"tags"`
+	Address unmarshalAddress  `json:"address"`
+	Scores  []int             `json:"scores"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type unmarshalAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+// TestUnmarshalNullPolicy 测试null策略和字段状态记录
+// TestUnmarshalNullPolicy tests the null policy and field presence recording
+func TestUnmarshalNullPolicy(t *testing.T) {
+	t.Run("default_zero", func(t *testing.T) {
+		got := unmarshalPatch{Name: "old", Age: 3}
+		require.NoError(t, xyJson.UnmarshalString(`{"name": null, "age": null}`, &got))
+		assert.Equal(t, "", got.Name)
+		assert.Equal(t, 0, got.Age)
+	})
+
+	t.Run("error_on_non_nullable", func(t *testing.T) {
+		opts := &xyJson.UnmarshalOptions{NullPolicy: xyJson.NullError}
+
+		got := unmarshalPatch{Name: "old"}
+		err := xyJson.UnmarshalString(`{"name": "new", "address": {"city": null}}`, &got, opts)
+		require.Error(t, err)
+		var nullErr *xyJson.NullValueError
+		require.ErrorAs(t, err, &nullErr)
+		assert.Equal(t, "$.address.city", nullErr.Path)
+		assert.Equal(t, "old", got.Name, "target must be untouched on failure")
+
+		err = xyJson.UnmarshalString(`{"scores": [1, null]}`, &got, opts)
+		require.ErrorAs(t, err, &nullErr)
+		assert.Equal(t, "$.scores[1]", nullErr.Path)
+
+		err = xyJson.UnmarshalString(`{"labels": {"a": null}}`, &got, opts)
+		require.ErrorAs(t, err, &nullErr)
+		assert.Equal(t, "$.labels.a", nullErr.Path)
+
+		require.NoError(t, xyJson.UnmarshalString(`{"email": null, "tags": null, "labels": null}`, &got, opts))
+		assert.Nil(t, got.Email)
+	})
+
+	t.Run("presence", func(t *testing.T) {
+		presence := xyJson.NewFieldPresence()
+		opts := &xyJson.UnmarshalOptions{Presence: presence}
+
+		var got unmarshalPatch
+		require.NoError(t, xyJson.UnmarshalString(`{"name": "n", "email": null, "address": {"zip": "123"}}`, &got, opts))
+		assert.Equal(t, xyJson.FieldSet, presence.State("$.name"))
+		assert.True(t, presence.IsNull("$.email"))
+		assert.True(t, presence.IsPresent("$.email"))
+		assert.False(t, presence.IsPresent("$.age"))
+		assert.True(t, presence.IsSet("$.address.zip"))
+		assert.Equal(t, xyJson.FieldAbsent, presence.State("$.address.city"))
+		assert.Equal(t, []string{"$.email"}, presence.Paths(xyJson.FieldNull))
+		assert.Equal(t, []string{"$.address", "$.address.zip", "$.name"}, presence.Paths(xyJson.FieldSet))
+
+		require.NoError(t, xyJson.UnmarshalString(`{"age": 1}`, &got, opts))
+		assert.Equal(t, []string{"$.age"}, presence.Paths(xyJson.FieldSet))
+		assert.Empty(t, presence.Paths(xyJson.FieldNull))
+	})
+
+	t.Run("strategy_conflict", func(t *testing.T) {
+		var got unmarshalPatch
+		err := xyJson.UnmarshalString(`{}`, &got, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeStdlib, NullPolicy: xyJson.NullError})
+		assert.Error(t, err)
+	})
+}
+
+// TestDecodeWithPresence 测试解码时返回出现过的字段
+// TestDecodeWithPresence tests returning the present fields from decoding
+func TestDecodeWithPresence(t *testing.T) {
+	var patch unmarshalPatch
+	presence, err := xyJson.DecodeWithPresence([]byte(`{"age": 0, "email": null, "address": {"city": ""}}`), &patch)
+	require.NoError(t, err)
+
+	assert.True(t, presence.Has("age"))
+	assert.True(t, presence.Has("$.age"))
+	assert.True(t, presence.Has("email"))
+	assert.True(t, presence.Has("address.city"))
+	assert.False(t, presence.Has("name"))
+	assert.False(t, presence.Has("address.zip"))
+	assert.Equal(t, 0, patch.Age)
+
+	_, err = xyJson.DecodeWithPresence([]byte(`{"age": `), &patch)
+	assert.Error(t, err)
+}
+
+const scanPathsJSON = `{
+	"user": {
+		"profile": {"name": "Alice", "age": 30, "joined": "2024-01-02T00:00:00Z"},
+		"addresses": [{"city": "Paris"}, {"city": "Berlin"}],
+		"contacts": [{"email": "a@example.com"}, {"email": "b@example.com"}],
+		"scores": [1, 2, 3]
+	},
+	"meta": {"version": "2"}
+}`
+
+type scanLocation struct {
+	City string `jsonpath:"$.user.addresses[last].city"`
+}
+
+type scanUserDTO struct {
+	Name      string            `jsonpath:"$.user.profile.name,required"`
+	Age       int               `jsonpath:"$.user.profile.age"`
+	Joined    time.Time         `jsonpath:"$.user.profile.joined"`
+	FirstCity string            `jsonpath:"$.user.addresses[0].city"`
+	Emails    []string          `jsonpath:"$.user.contacts[*].email"`
+	Scores    []int             `jsonpath:"$.user.scores"`
+	Meta      map[string]string `jsonpath:"$.meta"`
+	Missing   string            `jsonpath:"$.user.nickname"`
+	Ignored   string            `jsonpath:"-"`
+	Location  *scanLocation
+	Next      *scanUserDTO
+}
+
+// TestScanPaths 测试根据jsonpath标签扫描结构体
+// TestScanPaths tests scanning a struct via jsonpath tags
+func TestScanPaths(t *testing.T) {
+	root := xyJson.MustParseString(scanPathsJSON)
+
+	dto := scanUserDTO{Missing: "keep", Ignored: "keep"}
+	require.NoError(t, xyJson.ScanPaths(root, &dto))
+
+	assert.Equal(t, "Alice", dto.Name)
+	assert.Equal(t, 30, dto.Age)
+	assert.Equal(t, 2024, dto.Joined.Year())
+	assert.Equal(t, "Paris", dto.FirstCity)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, dto.Emails)
+	assert.Equal(t, []int{1, 2, 3}, dto.Scores)
+	assert.Equal(t, map[string]string{"version": "2"}, dto.Meta)
+	assert.Equal(t, "keep", dto.Missing)
+	assert.Equal(t, "keep", dto.Ignored)
+	require.NotNil(t, dto.Location)
+	assert.Equal(t, "Berlin", dto.Location.City)
+	assert.Nil(t, dto.Next)
+}
+
+// TestScanPathsErrors 测试ScanPaths的错误处理
+// TestScanPathsErrors tests ScanPaths error handling
+func TestScanPathsErrors(t *testing.T) {
+	root := xyJson.MustParseString(scanPathsJSON)
+
+	t.Run("required_missing", func(t *testing.T) {
+		var target struct {
+			Nick string `jsonpath:"$.user.nickname,required"`
+		}
+		err := xyJson.ScanPaths(root, &target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Nick")
+	})
+
+	t.Run("type_mismatch", func(t *testing.T) {
+		var target struct {
+			Age bool `jsonpath:"$.user.profile.age"`
+		}
+		err := xyJson.ScanPaths(root, &target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "$.user.profile.age")
+	})
+
+	t.Run("invalid_path", func(t *testing.T) {
+		var target struct {
+			Bad string `jsonpath:"user.name"`
+		}
+		assert.Error(t, xyJson.ScanPaths(root, &target))
+	})
+
+	t.Run("invalid_target", func(t *testing.T) {
+		var target scanLocation
+		assert.Error(t, xyJson.ScanPaths(root, target))
+		assert.Error(t, xyJson.ScanPaths(nil, &target))
+		assert.Error(t, xyJson.ScanPaths(root, nil))
+		assert.Panics(t, func() { xyJson.MustScanPaths(root, 1) })
+	})
+}
+
+// TestNumberConversionMatrix 测试整数和浮点数之间转换的范围检查
+// TestNumberConversionMatrix tests the range checks of conversions between integers and floats
+func TestNumberConversionMatrix(t *testing.T) {
+	ints := []struct {
+		value any
+		bits  int
+		ok    bool
+	}{
+		{127, 8, true},
+		{128, 8, false},
+		{-128, 8, true},
+		{-129, 8, false},
+		{2.0, 16, true},
+		{1.5, 16, false},
+		{math.Pow(2, 63), 64, false},
+		{-math.Pow(2, 63), 64, true},
+		{math.Inf(1), 64, false},
+		{int64(math.MaxInt64), 0, true},
+	}
+	for _, c := range ints {
+		_, err := xyJson.NumberToInt(xyJson.CreateNumber(c.value), c.bits, nil)
+		assert.Equal(t, c.ok, err == nil, "%v int%d: %v", c.value, c.bits, err)
+	}
+
+	uints := []struct {
+		value any
+		bits  int
+		ok    bool
+	}{
+		{255, 8, true},
+		{256, 8, false},
+		{-1, 64, false},
+		{math.Pow(2, 63), 64, true},
+		{math.Pow(2, 64), 64, false},
+	}
+	for _, c := range uints {
+		_, err := xyJson.NumberToUint(xyJson.CreateNumber(c.value), c.bits, nil)
+		assert.Equal(t, c.ok, err == nil, "%v uint%d: %v", c.value, c.bits, err)
+	}
+
+	_, err := xyJson.NumberToInt(xyJson.CreateNumber(300), 8, nil)
+	var je *xyJson.JSONError
+	require.True(t, errors.As(err, &je))
+	assert.Equal(t, xyJson.ErrNumberConversion, je.Code)
+	assert.Equal(t, "value 300 out of int8 range", je.Message)
+
+	_, err = xyJson.NumberToFloat(xyJson.CreateNumber(1e300), 32, nil)
+	assert.Error(t, err)
+	f, err := xyJson.NumberToFloat(xyJson.CreateNumber(0.1), 32, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, f)
+
+	strict := &xyJson.NumberConversionOptions{RejectPrecisionLoss: true}
+	_, err = xyJson.NumberToFloat(xyJson.CreateNumber(int64(1)<<53+1), 64, strict)
+	assert.Error(t, err)
+	_, err = xyJson.NumberToFloat(xyJson.CreateNumber(0.1), 32, strict)
+	assert.Error(t, err)
+	_, err = xyJson.NumberToFloat(xyJson.CreateNumber(int64(1)<<53), 64, strict)
+	assert.NoError(t, err)
+
+	_, err = xyJson.NumberToInt(xyJson.CreateString("1"), 64, nil)
+	assert.Error(t, err)
+}
+
+// TestNumberConversionTruncation 测试浮点数转换为整数的截断策略
+// TestNumberConversionTruncation tests the truncation policies of float to integer conversions
+func TestNumberConversionTruncation(t *testing.T) {
+	expected := map[xyJson.FloatTruncation][2]int64{
+		xyJson.TruncationTowardZero: {-2, 2},
+		xyJson.TruncationRound:      {-3, 3},
+		xyJson.TruncationFloor:      {-3, 2},
+		xyJson.TruncationCeil:       {-2, 3},
+	}
+	for policy, want := range expected {
+		options := &xyJson.NumberConversionOptions{Truncation: policy}
+		low, err := xyJson.NumberToInt(xyJson.CreateNumber(-2.5), 64, options)
+		require.NoError(t, err, policy.String())
+		high, err := xyJson.NumberToInt(xyJson.CreateNumber(2.5), 64, options)
+		require.NoError(t, err, policy.String())
+		assert.Equal(t, want, [2]int64{low, high}, policy.String())
+	}
+
+	// 截断后仍检查范围 The range is still checked after truncation
+	_, err := xyJson.NumberToUint(xyJson.CreateNumber(255.7), 8, &xyJson.NumberConversionOptions{Truncation: xyJson.TruncationRound})
+	assert.Error(t, err)
+	u, err := xyJson.NumberToUint(xyJson.CreateNumber(-0.4), 8, &xyJson.NumberConversionOptions{Truncation: xyJson.TruncationTowardZero})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), u)
+}
+
+// TestNumberConversionUnmarshalOptions 测试通过UnmarshalOptions指定数字转换选项
+// TestNumberConversionUnmarshalOptions tests number conversion options given through UnmarshalOptions
+func TestNumberConversionUnmarshalOptions(t *testing.T) {
+	type target struct {
+		Small int8    `json:"small"`
+		Count uint16  `json:"count"`
+		Ratio float32 `json:"ratio"`
+	}
+
+	_, err := xyJson.ToInt(xyJson.CreateNumber(2.5))
+	assert.Error(t, err)
+	_, err = xyJson.ToInt64(xyJson.CreateNumber(math.Pow(2, 63)))
+	assert.Error(t, err)
+
+	var decoded target
+	err = xyJson.UnmarshalStringToStruct(`{"small":1.5}`, &decoded)
+	assert.Error(t, err)
+	err = xyJson.UnmarshalStringToStruct(`{"small":200}`, &decoded)
+	assert.Error(t, err)
+	err = xyJson.NewCustomParser().UnmarshalDirectString(`{"count":70000}`, &decoded)
+	assert.Error(t, err)
+	err = xyJson.NewCustomParser().UnmarshalDirectString(`{"ratio":1e39}`, &decoded)
+	assert.Error(t, err)
+
+	round := &xyJson.NumberConversionOptions{Truncation: xyJson.TruncationRound}
+	for _, strategy := range []xyJson.DecodeStrategy{xyJson.DecodeAuto, xyJson.DecodeDirect, xyJson.DecodeValue} {
+		decoded = target{}
+		options := &xyJson.UnmarshalOptions{Strategy: strategy, Numbers: round}
+		require.NoError(t, xyJson.UnmarshalString(`{"small":-1.5,"count":2.4}`, &decoded, options), strategy.String())
+		assert.Equal(t, int8(-2), decoded.Small, strategy.String())
+		assert.Equal(t, uint16(2), decoded.Count, strategy.String())
+
+		// 范围检查不受截断策略影响 The range check is independent of the truncation policy
+		err = xyJson.UnmarshalString(`{"small":200}`, &decoded, options)
+		assert.Error(t, err, strategy.String())
+	}
+
+	var counts map[string]int
+	require.NoError(t, xyJson.UnmarshalString(`{"a":2.5}`, &counts, &xyJson.UnmarshalOptions{Numbers: round}))
+	assert.Equal(t, 3, counts["a"])
+
+	// 选项只作用于传入它的调用 The options only apply to the call they are passed to
+	err = xyJson.UnmarshalStringToStruct(`{"small":1.5}`, &decoded)
+	assert.Error(t, err)
+	n, err := xyJson.NumberToInt(xyJson.CreateNumber(2.5), 0, round)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+type projectionUser struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Items []struct {
+		Price float64 `json:"price"`
+	} `json:"items"`
+}
+
+// wideDocument 生成包含大量无关字段的文档
+// wideDocument generates a document with many irrelevant fields
+func wideDocument() string {
+	var sb strings.Builder
+	sb.WriteString(`{"id": 42, "name": "wide!", "owner": {"login": "ann", "bio": "long text", "stats": [1, 2, 3]},`)
+	sb.WriteString(`"items": [{"price": 1.5, "sku": "a"}, {"price": 2, "tags": {"x": null}}, 7],`)
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, `"extra%d": {"nested": [%d, "s\"q", true, {"deep": false}]},`, i, i)
+	}
+	sb.WriteString(`"last": null}`)
+	return sb.String()
+}
+
+// TestParseProjected 测试按投影解析只保留需要的字段
+// TestParseProjected tests that projected parsing only keeps the needed fields
+func TestParseProjected(t *testing.T) {
+	data := []byte(wideDocument())
+
+	t.Run("path_list", func(t *testing.T) {
+		proj, err := xyJson.NewProjection("$.id", "name", "owner.login", "$.items[*].price")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "items.price", "name", "owner.login"}, proj.Paths())
+
+		root, err := xyJson.ParseProjected(data, proj)
+		require.NoError(t, err)
+
+		out, err := xyJson.SerializeToString(root)
+		require.NoError(t, err)
+		expected := xyJson.MustParseString(`{"id": 42, "name": "wide!", "owner": {"login": "ann"}, "items": [{"price": 1.5}, {"price": 2}]}`)
+		assert.True(t, expected.Equals(root), out)
+	})
+
+	t.Run("subtree_and_prefix", func(t *testing.T) {
+		proj, err := xyJson.NewProjection("owner.login", "owner", "extra3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"extra3", "owner"}, proj.Paths())
+
+		root, err := xyJson.ParseProjected(data, proj)
+		require.NoError(t, err)
+		assert.Equal(t, 3, xyJson.MustGet(root, "$.owner.stats").AsArray().Length())
+		assert.Equal(t, 3, xyJson.MustGetInt(root, "$.extra3.nested[0]"))
+		assert.False(t, xyJson.Exists(root, "$.extra4"))
+	})
+
+	t.Run("struct_projection", func(t *testing.T) {
+		proj, err := xyJson.ProjectionOf(&projectionUser{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "items.price", "name", "owner.login"}, proj.Paths())
+
+		root, err := xyJson.ParseProjected(data, proj)
+		require.NoError(t, err)
+
+		var user projectionUser
+		require.NoError(t, xyJson.SerializeToStruct(root, &user))
+		assert.Equal(t, int64(42), user.ID)
+		assert.Equal(t, "ann", user.Owner.Login)
+		assert.Len(t, user.Items, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := xyJson.NewProjection("a..b")
+		assert.Error(t, err)
+		_, err = xyJson.NewProjection("$")
+		assert.Error(t, err)
+		_, err = xyJson.ProjectionOf(42)
+		assert.Error(t, err)
+
+		proj, _ := xyJson.NewProjection("a")
+		_, err = xyJson.ParseProjected([]byte(`{"a": 1, "b": [}`), proj)
+		assert.Error(t, err)
+		_, err = xyJson.ParseProjected([]byte(`{"a": 1} x`), proj)
+		assert.Error(t, err)
+
+		root, err := xyJson.ParseProjected([]byte(`"scalar"`), proj)
+		require.NoError(t, err)
+		assert.True(t, root.IsNull())
+	})
+}
+
+// TestUnmarshalProjected 测试按投影直接解码到结构体
+// TestUnmarshalProjected tests direct decoding into structs according to a projection
+func TestUnmarshalProjected(t *testing.T) {
+	data := []byte(`{"id": 1, "name": "n", "owner": {"login": "l"}, "items": [{"price": 3}], "skip": {"a": [1]}}`)
+
+	proj, err := xyJson.NewProjection("id", "items.price")
+	require.NoError(t, err)
+
+	var user projectionUser
+	user.Name = "kept"
+	require.NoError(t, xyJson.UnmarshalProjected(data, &user, proj))
+	assert.Equal(t, int64(1), user.ID)
+	assert.Equal(t, "kept", user.Name)
+	assert.Empty(t, user.Owner.Login)
+	require.Len(t, user.Items, 1)
+	assert.Equal(t, 3.0, user.Items[0].Price)
+
+	var all projectionUser
+	require.NoError(t, xyJson.UnmarshalProjected(data, &all, nil))
+	assert.Equal(t, "l", all.Owner.Login)
+}
@@ -214,4 +214,47 @@ func (g *TestDataGenerator) GenerateJSONPathTestData() string {
 	
 	jsonStr, _ := g.GenerateJSONString(data)
 	return jsonStr
-}
\ No newline at end of file
+}
+
+// RepeatArray 返回由n个相同元素item组成的JSON数组文本
+// RepeatArray returns the text of a JSON array made of n copies of item
+func RepeatArray(item string, n int) string {
+	return IndexedArray(n, func(int) string { return item })
+}
+
+// IndexedArray 返回由item(0)到item(n-1)组成的JSON数组文本
+// IndexedArray returns the text of a JSON array made of item(0) through item(n-1)
+func IndexedArray(n int, item func(i int) string) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(item(i))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// DeepDocument 返回数组和对象交替嵌套depth层、最内层为"leaf"的JSON文本
+// DeepDocument returns the text of depth alternately nested arrays and objects around "leaf"
+func DeepDocument(depth int) string {
+	var sb strings.Builder
+	for i := 0; i < depth; i++ {
+		if i%2 == 0 {
+			sb.WriteString(`[1,`)
+		} else {
+			sb.WriteString(`{"k":`)
+		}
+	}
+	sb.WriteString(`"leaf"`)
+	for i := depth - 1; i >= 0; i-- {
+		if i%2 == 0 {
+			sb.WriteString(`]`)
+		} else {
+			sb.WriteString(`}`)
+		}
+	}
+	return sb.String()
+}
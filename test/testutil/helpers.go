@@ -2,6 +2,9 @@ package testutil
 
 import (
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"testing"
@@ -9,20 +12,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ihuem/xyJson/xyjsontest"
 )
 
-// AssertJSONEqual 断言两个JSON字符串相等（忽略格式差异）
-// AssertJSONEqual asserts that two JSON strings are equal (ignoring formatting)
+// AssertJSONEqual 断言两个JSON字符串相等（忽略格式差异），失败时输出逐路径的差异
+// AssertJSONEqual asserts that two JSON strings are equal (ignoring formatting), printing a per-path diff on failure
 func AssertJSONEqual(t *testing.T, expected, actual string) {
-	var expectedObj, actualObj interface{}
-	
-	err := json.Unmarshal([]byte(expected), &expectedObj)
-	require.NoError(t, err, "Expected JSON should be valid")
-	
-	err = json.Unmarshal([]byte(actual), &actualObj)
-	require.NoError(t, err, "Actual JSON should be valid")
-	
-	assert.Equal(t, expectedObj, actualObj, "JSON objects should be equal")
+	t.Helper()
+	xyjsontest.AssertEqualJSON(t, expected, actual)
 }
 
 // AssertValidJSON 断言字符串是有效的JSON
@@ -147,17 +145,29 @@ func DeepEqual(t *testing.T, expected, actual interface{}) {
 // CreateTempFile 创建临时测试文件
 // CreateTempFile creates a temporary test file
 func CreateTempFile(t *testing.T, content string) string {
-	file := t.TempDir() + "/test.json"
-	err := writeFile(file, content)
-	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "test.json")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
 	return file
 }
 
-// writeFile 写入文件的辅助函数
-func writeFile(filename, content string) error {
-	// 这里应该使用 os.WriteFile，但为了避免导入os包
-	// 在实际实现中需要添加适当的导入
-	return nil // 占位符实现
+// FailingWriter 写入总是失败的Writer
+// FailingWriter is a writer whose writes always fail
+type FailingWriter struct{}
+
+// Write 返回写入错误
+// Write returns a write error
+func (FailingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+// FailingReader 读取总是失败的Reader
+// FailingReader is a reader whose reads always fail
+type FailingReader struct{}
+
+// Read 返回读取错误
+// Read returns a read error
+func (FailingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
 }
 
 // TableTest 表格驱动测试的辅助结构
@@ -1,7 +1,12 @@
 package test
 
 import (
+	"math"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	xyJson "github.com/ihuem/xyJson"
 )
@@ -178,3 +183,214 @@ func TestTryGetVsGetComparison(t *testing.T) {
 		t.Error("TryGetString对不存在路径应该返回false")
 	}
 }
+
+const typedGettersJSON = `{
+	"event": {
+		"at": "2024-01-02T15:04:05Z",
+		"day": "2024-01-02",
+		"payload": "aGVsbG8=",
+		"id": 42,
+		"bigId": "18446744073709551615",
+		"negative": -1,
+		"timeout": "5s",
+		"retry": 250,
+		"retryText": "1500",
+		"invalid": "soon"
+	}
+}`
+
+// TestTypedGetters 测试时间、字节、无符号整数和时间间隔获取函数
+// TestTypedGetters tests the time, bytes, uint64 and duration getters
+func TestTypedGetters(t *testing.T) {
+	root := xyJson.MustParseString(typedGettersJSON)
+
+	t.Run("time", func(t *testing.T) {
+		at, err := xyJson.GetTime(root, "$.event.at")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), at)
+		assert.Equal(t, 2, xyJson.MustGetTime(root, "$.event.day").Day())
+
+		_, err = xyJson.GetTime(root, "$.event.id")
+		assert.Error(t, err)
+		assert.True(t, xyJson.MustGetTime(root, "$.event.missing").IsZero())
+
+		fallback := time.Unix(0, 0)
+		assert.Equal(t, fallback, xyJson.GetTimeWithDefault(root, "$.event.invalid", fallback))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		payload, err := xyJson.GetBytes(root, "$.event.payload")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), payload)
+
+		_, ok := xyJson.TryGetBytes(root, "$.event.id")
+		assert.False(t, ok)
+		assert.Nil(t, xyJson.MustGetBytes(root, "$.event.missing"))
+		assert.Equal(t, []byte("x"), xyJson.GetBytesWithDefault(root, "$.event.missing", []byte("x")))
+	})
+
+	t.Run("uint64", func(t *testing.T) {
+		id, err := xyJson.GetUint64(root, "$.event.id")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), id)
+		assert.Equal(t, uint64(18446744073709551615), xyJson.MustGetUint64(root, "$.event.bigId"))
+
+		_, err = xyJson.GetUint64(root, "$.event.negative")
+		assert.Error(t, err)
+		_, ok := xyJson.TryGetUint64(root, "$.event.invalid")
+		assert.False(t, ok)
+		assert.Equal(t, uint64(7), xyJson.GetUint64WithDefault(root, "$.event.negative", 7))
+
+		// 保留的数字文本在超出int64范围时仍能精确转换
+		// Preserved number text converts exactly beyond the int64 range
+		preserved, err := xyJson.ParseWithOptions([]byte(`{"max": 18446744073709551615, "big": 9223372036854775808, "over": 18446744073709551616, "exp": 1e3}`),
+			&xyJson.ParseOptions{PreserveNumberText: true})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(18446744073709551615), xyJson.MustGetUint64(preserved, "$.max"))
+		assert.Equal(t, uint64(9223372036854775808), xyJson.MustGetUint64(preserved, "$.big"))
+		assert.Equal(t, uint64(1000), xyJson.MustGetUint64(preserved, "$.exp"))
+		_, err = xyJson.GetUint64(preserved, "$.over")
+		assert.Error(t, err)
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		timeout, err := xyJson.GetDuration(root, "$.event.timeout")
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, timeout)
+		assert.Equal(t, 250*time.Millisecond, xyJson.MustGetDuration(root, "$.event.retry"))
+		assert.Equal(t, 1500*time.Millisecond, xyJson.MustGetDuration(root, "$.event.retryText"))
+
+		_, err = xyJson.GetDuration(root, "$.event.invalid")
+		assert.Error(t, err)
+		_, ok := xyJson.TryGetDuration(root, "$.event")
+		assert.False(t, ok)
+		assert.Equal(t, time.Minute, xyJson.GetDurationWithDefault(root, "$.event.missing", time.Minute))
+	})
+
+	t.Run("nil_values", func(t *testing.T) {
+		_, err := xyJson.ToUint64(nil)
+		assert.Error(t, err)
+		_, err = xyJson.ToDuration(nil)
+		assert.Error(t, err)
+		assert.Equal(t, uint64(0), xyJson.MustToUint64(nil))
+		assert.Equal(t, time.Duration(0), xyJson.MustToDuration(nil))
+	})
+}
+
+// TestAsInt64OK 测试无分配的整数访问
+// TestAsInt64OK tests non-allocating integer access
+func TestAsInt64OK(t *testing.T) {
+	factory := xyJson.NewValueFactory()
+
+	tests := []struct {
+		name  string
+		value xyJson.IValue
+		want  int64
+		ok    bool
+	}{
+		{"int64", factory.CreateNumber(int64(42)), 42, true},
+		{"whole float", factory.CreateNumber(3.0), 3, true},
+		{"negative", xyJson.MustParseString(`-7`), -7, true},
+		{"fractional", factory.CreateNumber(1.5), 0, false},
+		{"overflow", factory.CreateNumber(math.Pow(2, 63)), 0, false},
+		{"min int64", factory.CreateNumber(-math.Pow(2, 63)), math.MinInt64, true},
+		{"numeric string", factory.CreateString("12"), 0, false},
+		{"bool", factory.CreateBool(true), 0, false},
+		{"null", factory.CreateNull(), 0, false},
+		{"object", factory.CreateObject(), 0, false},
+		{"array", factory.CreateArray(), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.value.AsInt64OK()
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestScalarOKAccessors 测试浮点、字符串和布尔的无分配访问
+// TestScalarOKAccessors tests non-allocating float, string and boolean access
+func TestScalarOKAccessors(t *testing.T) {
+	doc := xyJson.MustParseString(`{"f":2.5,"i":4,"s":"hi","b":false,"n":null}`)
+	obj := doc.AsObject()
+
+	f, ok := obj.Get("f").AsFloat64OK()
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, f)
+	f, ok = obj.Get("i").AsFloat64OK()
+	assert.True(t, ok)
+	assert.Equal(t, 4.0, f)
+	_, ok = obj.Get("s").AsFloat64OK()
+	assert.False(t, ok)
+
+	s, ok := obj.Get("s").AsStringOK()
+	assert.True(t, ok)
+	assert.Equal(t, "hi", s)
+	_, ok = obj.Get("i").AsStringOK()
+	assert.False(t, ok)
+
+	b, ok := obj.Get("b").AsBoolOK()
+	assert.True(t, ok)
+	assert.False(t, b)
+	_, ok = obj.Get("n").AsBoolOK()
+	assert.False(t, ok)
+
+	_, ok = doc.AsStringOK()
+	assert.False(t, ok)
+}
+
+// TestOKAccessorsDoNotAllocate 测试访问器成功和失败时都不分配内存
+// TestOKAccessorsDoNotAllocate tests that the accessors allocate neither on success nor on failure
+func TestOKAccessorsDoNotAllocate(t *testing.T) {
+	doc := xyJson.MustParseString(`{"n":1.5,"s":"text","b":true}`)
+	number := doc.AsObject().Get("n")
+	str := doc.AsObject().Get("s")
+	flag := doc.AsObject().Get("b")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		number.AsInt64OK()
+		number.AsFloat64OK()
+		str.AsInt64OK()
+		str.AsStringOK()
+		flag.AsBoolOK()
+		doc.AsFloat64OK()
+	})
+	assert.Zero(t, allocs)
+}
+
+// BenchmarkScalarAccessOK 基准测试：无分配访问器
+// BenchmarkScalarAccessOK benchmarks the non-allocating accessor
+func BenchmarkScalarAccessOK(b *testing.B) {
+	values := []xyJson.IValue{xyJson.MustParseString(`42`), xyJson.MustParseString(`"x"`)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		values[i&1].AsInt64OK()
+	}
+}
+
+// BenchmarkScalarAccessAssert 基准测试：类型断言加返回错误的Int64
+// BenchmarkScalarAccessAssert benchmarks type asserting and the error-returning Int64
+func BenchmarkScalarAccessAssert(b *testing.B) {
+	values := []xyJson.IValue{xyJson.MustParseString(`42`), xyJson.MustParseString(`"x"`)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if scalar, ok := values[i&1].(xyJson.IScalarValue); ok {
+			scalar.Int64()
+		}
+	}
+}
+
+// BenchmarkScalarAccessAsInt64 基准测试：失败时返回0的AsInt64
+// BenchmarkScalarAccessAsInt64 benchmarks AsInt64, which returns 0 on failure
+func BenchmarkScalarAccessAsInt64(b *testing.B) {
+	values := []xyJson.IValue{xyJson.MustParseString(`42`), xyJson.MustParseString(`"x"`)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		values[i&1].AsInt64()
+	}
+}
@@ -0,0 +1,216 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	xyJson "github.com/ihuem/xyJson"
+	"github.com/ihuem/xyJson/xyjsontest"
+)
+
+// recordingT 记录失败信息的TestingT
+// recordingT is a TestingT that records failure messages
+type recordingT struct {
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// TestAssertEqualJSON 测试文档相等断言
+// TestAssertEqualJSON tests the document equality assertion
+func TestAssertEqualJSON(t *testing.T) {
+	expected := `{"id": 1, "tags": ["a", "b"], "price": 2.0, "meta": {"at": "now"}}`
+
+	xyjsontest.AssertEqualJSON(t, expected, []byte(`{"meta":{"at":"now"},"price":2,"tags":["a","b"],"id":1.0}`))
+	xyjsontest.AssertEqualJSON(t, expected, xyJson.MustParseString(expected))
+	xyjsontest.AssertEqualJSON(t, map[string]interface{}{"a": []int{1, 2}}, `{"a":[1,2]}`)
+
+	rec := &recordingT{}
+	ok := xyjsontest.AssertEqualJSON(rec, expected, `{"id": 2, "tags": ["a"], "price": 2.0, "meta": {"at": "now"}, "new": null}`)
+	assert.False(t, ok)
+	require.Len(t, rec.failures, 1)
+	assert.Equal(t, "JSON documents differ:\n"+
+		"\t$.id: expected 1, got 2\n"+
+		"\t$.tags[1]: missing, expected \"b\"\n"+
+		"\t$.new: unexpected null", rec.failures[0])
+
+	rec = &recordingT{}
+	assert.False(t, xyjsontest.AssertEqualJSON(rec, `{`, `{}`))
+	assert.Contains(t, rec.failures[0], "invalid expected document")
+}
+
+// TestAssertEqualJSONIgnorePaths 测试忽略路径
+// TestAssertEqualJSONIgnorePaths tests ignored paths
+func TestAssertEqualJSONIgnorePaths(t *testing.T) {
+	expected := `{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}], "createdAt": "x"}`
+	actual := `{"items": [{"id": 7, "name": "a"}, {"id": 8, "name": "b"}], "createdAt": "y", "requestId": "r-1"}`
+
+	xyjsontest.AssertEqualJSON(t, expected, actual, "$.items[*].id", "$.createdAt", "$.requestId")
+
+	rec := &recordingT{}
+	assert.False(t, xyjsontest.AssertEqualJSON(rec, expected, actual, "$.items[*].id"))
+	assert.Contains(t, rec.failures[0], "$.createdAt")
+	assert.NotContains(t, rec.failures[0], "$.items")
+}
+
+// TestAssertSubset 测试子集断言
+// TestAssertSubset tests the subset assertion
+func TestAssertSubset(t *testing.T) {
+	actual := `{"user": {"name": "Alice", "age": 30, "roles": [{"id": 1, "name": "admin"}]}, "ok": true}`
+	xyjsontest.AssertSubset(t, `{"user": {"name": "Alice", "roles": [{"id": 1}]}}`, actual)
+	xyjsontest.AssertSubset(t, `{}`, actual)
+
+	rec := &recordingT{}
+	assert.False(t, xyjsontest.AssertSubset(rec, `{"user": {"name": "Bob", "email": "b@x"}}`, actual))
+	assert.Equal(t, "JSON document is not a subset:\n"+
+		"\t$.user.email: missing, expected \"b@x\"\n"+
+		"\t$.user.name: expected \"Bob\", got \"Alice\"", rec.failures[0])
+}
+
+// TestAssertPathEquals 测试路径值断言
+// TestAssertPathEquals tests the path value assertion
+func TestAssertPathEquals(t *testing.T) {
+	root := xyJson.MustParseString(`{"a": {"b": 42, "s": "{}", "list": [1, 2]}}`)
+	xyjsontest.AssertPathEquals(t, root, "$.a.b", 42)
+	xyjsontest.AssertPathEquals(t, root, "$.a.b", 42.0)
+	xyjsontest.AssertPathEquals(t, root, "$.a.s", "{}")
+	xyjsontest.AssertPathEquals(t, root, "$.a.list", []int{1, 2})
+
+	rec := &recordingT{}
+	assert.False(t, xyjsontest.AssertPathEquals(rec, root, "$.a.list", []int{1, 3}))
+	assert.Equal(t, "JSON value differs:\n\t$.a.list[1]: expected 3, got 2", rec.failures[0])
+
+	rec = &recordingT{}
+	assert.False(t, xyjsontest.AssertPathEquals(rec, root, "$.a.missing", 1))
+	assert.Contains(t, rec.failures[0], "$.a.missing")
+}
+
+// TestDiffLimit 测试失败信息中的差异数量上限
+// TestDiffLimit tests the limit on differences in failure messages
+func TestDiffLimit(t *testing.T) {
+	expected := make([]int, 30)
+	actual := make([]int, 30)
+	for i := range actual {
+		actual[i] = i + 1
+	}
+	assert.Len(t, xyjsontest.Diff(xyJson.MustCreateFromRaw(expected), xyJson.MustCreateFromRaw(actual)), 30)
+
+	rec := &recordingT{}
+	xyjsontest.AssertEqualJSON(rec, expected, actual)
+	assert.Equal(t, xyjsontest.MaxReportedDifferences+2, strings.Count(rec.failures[0], "\n")+1)
+	assert.Contains(t, rec.failures[0], "... and 10 more")
+}
+
+// TestGeneratorRoundTrip 测试生成的文档序列化后能解析回相等的值
+// TestGeneratorRoundTrip tests that generated documents parse back to equal values after serialization
+func TestGeneratorRoundTrip(t *testing.T) {
+	gen := xyjsontest.NewGenerator(nil)
+	for i := 0; i < 200; i++ {
+		doc := gen.Document()
+		data, err := xyJson.Serialize(doc)
+		require.NoError(t, err, "seed %d", gen.Seed())
+
+		parsed, err := xyJson.Parse(data)
+		require.NoError(t, err, "seed %d: %s", gen.Seed(), data)
+		require.True(t, doc.Equals(parsed), "seed %d: %s", gen.Seed(), data)
+	}
+}
+
+// TestGeneratorDeterministic 测试相同种子生成相同的文档
+// TestGeneratorDeterministic tests that the same seed generates the same documents
+func TestGeneratorDeterministic(t *testing.T) {
+	options := xyjsontest.DefaultGeneratorOptions()
+	options.Seed = 42
+	a := xyjsontest.NewGenerator(options)
+	b := xyjsontest.NewGenerator(options)
+	assert.Equal(t, int64(42), a.Seed())
+
+	for i := 0; i < 20; i++ {
+		docA, docB := a.Document(), b.Document()
+		assert.True(t, docA.Equals(docB))
+
+		data, err := xyJson.Serialize(docA)
+		require.NoError(t, err)
+		assert.Equal(t, a.Mutate(data), b.Mutate(data))
+	}
+	assert.NotZero(t, xyjsontest.NewGenerator(nil).Seed())
+}
+
+// TestGeneratorOptions 测试深度、宽度和类型分布
+// TestGeneratorOptions tests depth, width and type distribution
+func TestGeneratorOptions(t *testing.T) {
+	gen := xyjsontest.NewGenerator(&xyjsontest.GeneratorOptions{
+		Seed:            7,
+		MaxDepth:        2,
+		MaxWidth:        3,
+		MaxStringLength: 4,
+		Weights:         xyjsontest.TypeWeights{Number: 1, Array: 1},
+	})
+
+	for i := 0; i < 100; i++ {
+		doc := gen.Document()
+		require.Equal(t, xyJson.ArrayValueType, doc.Type())
+		assert.LessOrEqual(t, doc.AsArray().Length(), 3)
+
+		assert.LessOrEqual(t, generatedDepth(doc), 2)
+		assert.Empty(t, xyJson.FindPaths(doc, func(v xyJson.IValue) bool {
+			return v.Type() != xyJson.NumberValueType && v.Type() != xyJson.ArrayValueType
+		}))
+	}
+
+	scalars := xyjsontest.NewGenerator(&xyjsontest.GeneratorOptions{Seed: 1, Weights: xyjsontest.TypeWeights{String: 1}})
+	assert.Equal(t, xyJson.StringValueType, scalars.Document().Type())
+}
+
+// generatedDepth 返回容器的嵌套深度，标量为0
+// generatedDepth returns the nesting depth of containers, 0 for scalars
+func generatedDepth(value xyJson.IValue) int {
+	if value.Type() != xyJson.ArrayValueType {
+		return 0
+	}
+	depth := 0
+	value.AsArray().Range(func(_ int, child xyJson.IValue) bool {
+		if d := generatedDepth(child); d > depth {
+			depth = d
+		}
+		return true
+	})
+	return depth + 1
+}
+
+// TestGeneratorMutate 测试变体与原文不同且解析不会panic
+// TestGeneratorMutate tests that variants differ from the original and parsing them does not panic
+func TestGeneratorMutate(t *testing.T) {
+	gen := xyjsontest.NewGenerator(&xyjsontest.GeneratorOptions{Seed: 3, MaxDepth: 3, MaxWidth: 4, MaxStringLength: 6,
+		Weights: xyjsontest.DefaultGeneratorOptions().Weights})
+
+	changed, invalid := 0, 0
+	for i := 0; i < 500; i++ {
+		data, err := xyJson.Serialize(gen.Document())
+		require.NoError(t, err)
+		original := bytes.Clone(data)
+
+		variant := gen.Mutate(data)
+		assert.Equal(t, original, data)
+		if !bytes.Equal(variant, data) {
+			changed++
+		}
+		assert.NotPanics(t, func() {
+			if _, err := xyJson.Parse(variant); err != nil {
+				invalid++
+			}
+		}, "%q", variant)
+	}
+	assert.Greater(t, changed, 450)
+	assert.Greater(t, invalid, 250)
+	assert.NotEmpty(t, gen.Mutate(nil))
+}
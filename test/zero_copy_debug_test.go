@@ -0,0 +1,23 @@
+//go:build xyjson_debug
+
+package test
+
+import (
+	"testing"
+
+	xyJson "github.com/ihuem/xyJson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestZeroCopyDebugContract 测试调试构建检测借用期间被修改的缓冲区
+// TestZeroCopyDebugContract tests that debug builds detect a buffer modified while borrowed
+func TestZeroCopyDebugContract(t *testing.T) {
+	buf := []byte(zeroCopyJSON)
+	root, err := xyJson.ParseZeroCopy(buf)
+	require.NoError(t, err)
+
+	buf[10] = 'X'
+	assert.Error(t, xyJson.VerifyZeroCopy(root))
+	assert.Panics(t, func() { xyJson.Detach(root) })
+}
@@ -0,0 +1,475 @@
+package xyJson
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncateMarkerKey 对象成员被截断时添加的标记键
+// TruncateMarkerKey is the marker key added when object members are truncated
+const TruncateMarkerKey = "..."
+
+// TruncateOptions 限长序列化的截断选项，0表示不限制
+// TruncateOptions holds the truncation options of size-bounded serialization, 0 means unlimited
+type TruncateOptions struct {
+	// MaxStringLength 字符串保留的最大字节数，超出部分替换为"...(+N bytes)"
+	// MaxStringLength is the maximum number of bytes kept of a string, the rest is replaced by "...(+N bytes)"
+	MaxStringLength int
+
+	// MaxArrayItems 数组保留的最大元素数，超出部分替换为一个"...(+N items)"元素
+	// MaxArrayItems is the maximum number of array items kept, the rest is replaced by one "...(+N items)" item
+	MaxArrayItems int
+
+	// MaxObjectKeys 对象保留的最大成员数（按键名排序），超出部分替换为"...": "...(+N keys)"成员
+	// MaxObjectKeys is the maximum number of object members kept (in key order), the rest is replaced by a
+	// "...": "...(+N keys)" member
+	MaxObjectKeys int
+
+	// MaxDepth 保留的最大嵌套深度，更深的容器替换为"...(object, N keys)"或"...(array, N items)"
+	// MaxDepth is the maximum nesting depth kept, deeper containers are replaced by "...(object, N keys)" or
+	// "...(array, N items)"
+	MaxDepth int
+
+	// Indent 缩进字符串，为空时输出紧凑JSON
+	// Indent is the indentation string, compact JSON is emitted when empty
+	Indent string
+}
+
+// DefaultTruncateOptions 返回默认截断选项
+// DefaultTruncateOptions returns the default truncation options
+func DefaultTruncateOptions() *TruncateOptions {
+	return &TruncateOptions{
+		MaxStringLength: 1024,
+		MaxArrayItems:   100,
+		MaxObjectKeys:   0,
+		MaxDepth:        0,
+		Indent:          "",
+	}
+}
+
+// SerializeTruncated 在maxBytes字节内序列化值，用显式标记截断过长的字符串和过大的数组，结果始终是有效JSON
+// SerializeTruncated serializes a value within maxBytes, truncating long strings and large arrays with
+// explicit markers; the result is always valid JSON
+//
+// 先按opts截断；结果仍超出预算时从根开始按顺序贪心地保留能放下的成员和元素，第一个放不下的子值在剩余预算内
+// 继续截断，其后的部分替换为标记。连根容器的标记都放不下时根替换为"...(object, N keys)"这样的字符串，
+// 仍放不下时返回错误。value本身不会被修改，opts为nil时使用默认选项
+// The value is first truncated according to opts; while the result still exceeds the budget, members and
+// items are kept greedily in order from the root as long as they fit, the first child that does not fit is
+// truncated further within the remaining budget, and the rest is replaced by a marker. When not even the
+// marker of the root container fits, the root is replaced by a string such as "...(object, N keys)", and an
+// error is returned when that does not fit either. value itself is not modified, and nil opts means the
+// default options
+//
+// 示例 Example:
+//
+//	data, err := xyJson.SerializeTruncated(payload, 4096, nil)
+//	logger.Info("request", "body", string(data))
+func SerializeTruncated(value IValue, maxBytes int, opts *TruncateOptions) ([]byte, error) {
+	if value == nil {
+		return nil, NewNullPointerError("value cannot be nil")
+	}
+	if maxBytes <= 0 {
+		return nil, NewInvalidOperationError("serialize truncated", "maxBytes must be positive")
+	}
+	if opts == nil {
+		opts = DefaultTruncateOptions()
+	}
+
+	t := &truncater{
+		serializer: NewSerializerWithOptions(&SerializeOptions{
+			Indent:     opts.Indent,
+			Compact:    opts.Indent == "",
+			EscapeHTML: true,
+			MaxDepth:   DefaultMaxDepth,
+		}),
+		indent:  len(opts.Indent),
+		strLen:  unlimitedIfZero(opts.MaxStringLength),
+		items:   unlimitedIfZero(opts.MaxArrayItems),
+		keys:    unlimitedIfZero(opts.MaxObjectKeys),
+		depth:   unlimitedIfZero(opts.MaxDepth),
+		factory: defaultFactory(),
+	}
+
+	truncated := t.truncate(value, 0)
+	data, err := t.serializer.Serialize(truncated)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= maxBytes {
+		return data, nil
+	}
+
+	fitted, ok, err := t.fit(truncated, 0, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if data, err = t.serializer.Serialize(fitted); err != nil {
+			return nil, err
+		}
+	}
+	if !ok || len(data) > maxBytes {
+		return nil, NewInvalidOperationError("serialize truncated",
+			strconv.Itoa(maxBytes)+" bytes cannot hold the truncated value")
+	}
+	return data, nil
+}
+
+// unlimitedIfZero 将选项中的非正数限制转换为-1（不限制）
+// unlimitedIfZero converts a non-positive option limit to -1 (unlimited)
+func unlimitedIfZero(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+// truncater 按选项的限制构建截断后的副本，并在字节预算内贪心地裁剪
+// truncater builds truncated copies under the limits of the options and trims them greedily to a byte budget
+type truncater struct {
+	serializer ISerializer
+
+	// indent 缩进字符串的长度，0表示紧凑输出
+	// indent is the length of the indentation string, 0 means compact output
+	indent int
+
+	// strLen、items、keys、depth 选项中的限制，-1表示不限制
+	// strLen, items, keys and depth are the limits of the options, -1 means unlimited
+	strLen, items, keys, depth int
+
+	factory IValueFactory
+}
+
+// truncate 返回value在选项限制下的截断副本，未截断的标量直接复用
+// truncate returns a truncated copy of value under the limits of the options, reusing scalars that need no
+// truncation
+func (t *truncater) truncate(value IValue, depth int) IValue {
+	switch v := value.(type) {
+	case IObject:
+		size := v.Size()
+		if t.depth >= 0 && depth >= t.depth {
+			return t.containerMarker(v)
+		}
+
+		keys := v.Keys()
+		if t.keys >= 0 && len(keys) > t.keys {
+			keys = keys[:t.keys]
+		}
+		obj := t.factory.CreateObject()
+		for _, key := range keys {
+			obj.Set(key, t.truncate(v.Get(key), depth+1))
+		}
+		if dropped := size - len(keys); dropped > 0 {
+			obj.Set(TruncateMarkerKey, keysMarker(t.factory, dropped))
+		}
+		return obj
+
+	case IArray:
+		length := v.Length()
+		if t.depth >= 0 && depth >= t.depth {
+			return t.containerMarker(v)
+		}
+
+		kept := length
+		if t.items >= 0 && kept > t.items {
+			kept = t.items
+		}
+		arr := t.factory.CreateArray()
+		for i := 0; i < kept; i++ {
+			arr.Append(t.truncate(v.Get(i), depth+1))
+		}
+		if dropped := length - kept; dropped > 0 {
+			arr.Append(itemsMarker(t.factory, dropped))
+		}
+		return arr
+
+	case nil:
+		return t.factory.CreateNull()
+
+	default:
+		if v.Type() != StringValueType || t.strLen < 0 {
+			return v
+		}
+		return t.truncateString(v, t.strLen)
+	}
+}
+
+// truncateString 保留字符串的前limit个字节（不拆分UTF-8字符），其余替换为"...(+N bytes)"；不会变短时返回原值
+// truncateString keeps the first limit bytes of a string (without splitting UTF-8 characters) and replaces the
+// rest by "...(+N bytes)"; the original value is returned when that would not be shorter
+func (t *truncater) truncateString(v IValue, limit int) IValue {
+	s := v.AsString()
+	if len(s) <= limit {
+		return v
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	truncated := s[:cut] + "...(+" + strconv.Itoa(len(s)-cut) + " bytes)"
+	if len(truncated) >= len(s) {
+		return v
+	}
+	return t.factory.CreateString(truncated)
+}
+
+// containerMarker 返回替换整个容器的标记字符串
+// containerMarker returns the marker string replacing a whole container
+func (t *truncater) containerMarker(value IValue) IValue {
+	if obj, ok := value.(IObject); ok {
+		return t.factory.CreateString("...(object, " + strconv.Itoa(obj.Size()) + " keys)")
+	}
+	return t.factory.CreateString("...(array, " + strconv.Itoa(value.(IArray).Length()) + " items)")
+}
+
+// keysMarker 返回省略了dropped个成员的对象标记值
+// keysMarker returns the object marker value for dropped omitted members
+func keysMarker(factory IValueFactory, dropped int) IValue {
+	return factory.CreateString("...(+" + strconv.Itoa(dropped) + " keys)")
+}
+
+// itemsMarker 返回省略了dropped个元素的数组标记元素
+// itemsMarker returns the array marker item for dropped omitted items
+func itemsMarker(factory IValueFactory, dropped int) IValue {
+	return factory.CreateString("...(+" + strconv.Itoa(dropped) + " items)")
+}
+
+// size 返回value位于depth层时序列化的字节数：缩进输出中value的每个换行之后都多出depth层缩进
+// size returns the number of bytes value serializes to at depth: in indented output every newline inside
+// value is followed by depth more levels of indentation
+func (t *truncater) size(value IValue, depth int) (int, error) {
+	data, err := t.serializer.Serialize(value)
+	if err != nil {
+		return 0, err
+	}
+	return len(data) + bytes.Count(data, []byte{'\n'})*depth*t.indent, nil
+}
+
+// containerSize 返回n个成员或元素、内容共entries字节的容器位于depth层时的字节数，entries已包含每项的缩进
+// containerSize returns the number of bytes of a container at depth holding n members or items whose content
+// totals entries bytes, entries already including the indentation of every entry
+func (t *truncater) containerSize(n, entries, depth int) int {
+	switch {
+	case n == 0:
+		return 2
+	case t.indent == 0:
+		return 2 + entries + n - 1
+	default:
+		// "{\n"、每项之间的",\n"、"\n"加上depth层缩进和"}"
+		// "{\n", ",\n" between entries, then "\n", depth levels of indentation and "}"
+		return 4 + depth*t.indent + entries + 2*(n-1)
+	}
+}
+
+// entryOverhead 返回depth层容器中一项除值以外的字节数：缩进以及对象成员的键名和冒号
+// entryOverhead returns the bytes of an entry of a container at depth other than its value: the indentation
+// and, for object members, the key and the colon
+func (t *truncater) entryOverhead(key IValue, depth int) (int, error) {
+	overhead := (depth + 1) * t.indent
+	if key == nil {
+		return overhead, nil
+	}
+	quoted, err := t.size(key, 0)
+	if err != nil {
+		return 0, err
+	}
+	if t.indent > 0 {
+		return overhead + quoted + 2, nil
+	}
+	return overhead + quoted + 1, nil
+}
+
+// fit 返回value位于depth层时不超过budget字节的截断副本，ok为false表示放不下
+// fit returns a truncated copy of value at depth within budget bytes, ok being false when it cannot fit
+//
+// value是原始值，先按选项截断，仍放不下时容器按顺序保留能放下的项，第一个放不下的项在剩余预算内递归截断，
+// 其后的项替换为标记；容器连标记都放不下时替换为容器标记字符串，字符串缩短保留的前缀
+// value is the original value, truncated by the options first; when that does not fit a container keeps its
+// entries in order while they fit, the first entry that does not fit is truncated recursively within the
+// remaining budget and the entries after it are replaced by a marker; a container that cannot even hold the
+// marker is replaced by its marker string, and strings shorten the kept prefix
+func (t *truncater) fit(value IValue, depth, budget int) (_ IValue, ok bool, err error) {
+	truncated := t.truncate(value, depth)
+	size, err := t.size(truncated, depth)
+	if err != nil || size <= budget {
+		return truncated, err == nil, err
+	}
+	if t.depth >= 0 && depth >= t.depth {
+		return nil, false, nil
+	}
+
+	switch v := value.(type) {
+	case IObject:
+		keys := v.Keys()
+		if t.keys >= 0 && len(keys) > t.keys {
+			keys = keys[:t.keys]
+		}
+		names := make([]IValue, len(keys))
+		children := make([]IValue, len(keys))
+		for i, key := range keys {
+			names[i], children[i] = t.factory.CreateString(key), v.Get(key)
+		}
+		kept, ok, err := t.fitEntries(names, children, v.Size(), depth, budget)
+		if err != nil || ok {
+			if !ok {
+				return nil, false, err
+			}
+			obj := t.factory.CreateObject()
+			for i, child := range kept {
+				obj.Set(keys[i], child)
+			}
+			if dropped := v.Size() - len(kept); dropped > 0 {
+				obj.Set(TruncateMarkerKey, keysMarker(t.factory, dropped))
+			}
+			return obj, true, nil
+		}
+		return t.fitMarker(v, depth, budget)
+
+	case IArray:
+		length := v.Length()
+		kept := length
+		if t.items >= 0 && kept > t.items {
+			kept = t.items
+		}
+		children := make([]IValue, kept)
+		for i := range children {
+			children[i] = v.Get(i)
+		}
+		fitted, ok, err := t.fitEntries(nil, children, length, depth, budget)
+		if err != nil || ok {
+			if !ok {
+				return nil, false, err
+			}
+			arr := t.factory.CreateArray()
+			for _, child := range fitted {
+				arr.Append(child)
+			}
+			if dropped := length - len(fitted); dropped > 0 {
+				arr.Append(itemsMarker(t.factory, dropped))
+			}
+			return arr, true, nil
+		}
+		return t.fitMarker(v, depth, budget)
+
+	default:
+		if v.Type() != StringValueType {
+			return nil, false, nil
+		}
+		// 逐步缩短保留的前缀直到放下
+		// Shorten the kept prefix step by step until it fits
+		for limit := len(v.AsString()) - (size - budget); limit >= 0; {
+			truncated = t.truncateString(v, limit)
+			if size, err = t.size(truncated, depth); err != nil {
+				return nil, false, err
+			}
+			if size <= budget {
+				return truncated, true, nil
+			}
+			if limit == 0 {
+				break
+			}
+			limit = max(0, limit-(size-budget))
+		}
+		return nil, false, nil
+	}
+}
+
+// fitMarker 返回替换整个容器的标记字符串，放不下时ok为false
+// fitMarker returns the marker string replacing a whole container, ok being false when it does not fit
+func (t *truncater) fitMarker(value IValue, depth, budget int) (IValue, bool, error) {
+	marker := t.containerMarker(value)
+	size, err := t.size(marker, depth)
+	if err != nil || size > budget {
+		return nil, false, err
+	}
+	return marker, true, nil
+}
+
+// fitEntries 在budget字节内按顺序选择depth层容器保留的项，total是原容器的项数，其余的项由标记代替；
+// names为nil表示数组，否则是对象成员的键名。返回保留的截断副本，连标记都放不下时ok为false
+// fitEntries picks in order the entries a container at depth keeps within budget bytes, total being the
+// number of entries of the original container and the rest being replaced by a marker; names is nil for
+// arrays and holds the member keys of objects otherwise. It returns the truncated copies kept, ok being false
+// when not even the marker fits
+func (t *truncater) fitEntries(names, children []IValue, total, depth, budget int) (_ []IValue, ok bool, err error) {
+	var markerName IValue
+	if names != nil {
+		markerName = t.factory.CreateString(TruncateMarkerKey)
+	}
+	// withMarker 返回n项共entries字节、另有dropped项被省略时的容器大小
+	// withMarker returns the container size with n entries totalling entries bytes and dropped entries omitted
+	withMarker := func(n, entries, dropped int) (int, error) {
+		if dropped == 0 {
+			return t.containerSize(n, entries, depth), nil
+		}
+		marker := itemsMarker(t.factory, dropped)
+		if names != nil {
+			marker = keysMarker(t.factory, dropped)
+		}
+		overhead, err := t.entryOverhead(markerName, depth)
+		if err != nil {
+			return 0, err
+		}
+		size, err := t.size(marker, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		return t.containerSize(n+1, entries+overhead+size, depth), nil
+	}
+
+	var kept []IValue
+	entries := 0
+	for i, child := range children {
+		var name IValue
+		if names != nil {
+			name = names[i]
+		}
+		overhead, err := t.entryOverhead(name, depth)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated := t.truncate(child, depth+1)
+		size, err := t.size(truncated, depth+1)
+		if err != nil {
+			return nil, false, err
+		}
+		whole, err := withMarker(len(kept)+1, entries+overhead+size, total-i-1)
+		if err != nil {
+			return nil, false, err
+		}
+		if whole <= budget {
+			kept = append(kept, truncated)
+			entries += overhead + size
+			continue
+		}
+
+		// 第一个放不下的项在剩余预算内继续截断，之后停止
+		// The first entry that does not fit is truncated further within the remaining budget, then stop
+		empty, err := withMarker(len(kept)+1, entries+overhead, total-i-1)
+		if err != nil {
+			return nil, false, err
+		}
+		if empty < budget {
+			fitted, ok, err := t.fit(child, depth+1, budget-empty)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				if size, err = t.size(fitted, depth+1); err != nil {
+					return nil, false, err
+				}
+				kept = append(kept, fitted)
+				entries += overhead + size
+			}
+		}
+		break
+	}
+
+	size, err := withMarker(len(kept), entries, total-len(kept))
+	if err != nil || size > budget {
+		return nil, false, err
+	}
+	return kept, true, nil
+}
@@ -0,0 +1,313 @@
+package xyJson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ToUint64 转换为64位无符号整数
+// ToUint64 converts to 64-bit unsigned integer
+//
+// 支持非负整数、无小数部分的浮点数以及十进制数字字符串；超出int64范围的值可以通过字符串或
+// 开启PreserveNumberText解析出的数字精确表示
+// Supports non-negative integers, floats without a fractional part and decimal number strings; values
+// beyond the int64 range are represented exactly by strings or by numbers parsed with PreserveNumberText
+func ToUint64(value IValue) (uint64, error) {
+	if value == nil {
+		return 0, NewNullValueError(NumberValueType)
+	}
+	if _, ok := value.(IScalarValue); !ok {
+		return 0, NewTypeMismatchError(NumberValueType, value.Type(), "")
+	}
+	if text, ok := NumberText(value); ok {
+		// 原始文本是整数时精确解析，否则按数值转换
+		// Parse the original text exactly when it is an integer, otherwise convert the numeric value
+		if result, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return result, nil
+		}
+	}
+
+	switch v := value.Raw().(type) {
+	case int64, float64:
+		u, err := convertToUint(v, 64, &defaultNumberConversion)
+		if err != nil {
+			return 0, err
+		}
+		return u, nil
+	case string:
+		result, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, NewInvalidOperationError("uint64 conversion", fmt.Sprintf("cannot parse '%s' as uint64", v))
+		}
+		return result, nil
+	default:
+		return 0, NewTypeMismatchError(NumberValueType, value.Type(), "")
+	}
+}
+
+// ToDuration 转换为时间间隔
+// ToDuration converts to duration
+//
+// 数字按毫秒解释，字符串按time.ParseDuration格式（如"5s"、"1m30s"）解析，
+// 纯数字字符串同样按毫秒解释
+// Numbers are interpreted as milliseconds, strings are parsed with time.ParseDuration (e.g. "5s", "1m30s"),
+// and plain numeric strings are also interpreted as milliseconds
+func ToDuration(value IValue) (time.Duration, error) {
+	if value == nil {
+		return 0, NewNullValueError(StringValueType)
+	}
+	if _, ok := value.(IScalarValue); !ok {
+		return 0, NewTypeMismatchError(StringValueType, value.Type(), "")
+	}
+
+	switch v := value.Raw().(type) {
+	case int64:
+		return time.Duration(v) * time.Millisecond, nil
+	case float64:
+		return time.Duration(v * float64(time.Millisecond)), nil
+	case string:
+		str := strings.TrimSpace(v)
+		if ms, err := strconv.ParseFloat(str, 64); err == nil {
+			return time.Duration(ms * float64(time.Millisecond)), nil
+		}
+		duration, err := time.ParseDuration(str)
+		if err != nil {
+			return 0, NewInvalidOperationError("duration conversion", fmt.Sprintf("cannot parse '%s' as duration", v))
+		}
+		return duration, nil
+	default:
+		return 0, NewTypeMismatchError(StringValueType, value.Type(), "")
+	}
+}
+
+// MustToUint64 转换为64位无符号整数，如果失败则返回0
+// MustToUint64 converts to 64-bit unsigned integer, returns 0 on failure
+func MustToUint64(value IValue) uint64 {
+	result, err := ToUint64(value)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// MustToDuration 转换为时间间隔，如果失败则返回0
+// MustToDuration converts to duration, returns 0 on failure
+func MustToDuration(value IValue) time.Duration {
+	result, err := ToDuration(value)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// GetTime 使用JSONPath获取时间值
+// GetTime gets time value using JSONPath
+//
+// 支持RFC3339及常见日期时间格式的字符串
+// Supports RFC3339 and common date-time formatted strings
+//
+// 示例 Example:
+//
+//	data := `{"event":{"at":"2024-01-02T15:04:05Z"}}`
+//	root, _ := xyJson.ParseString(data)
+//	at, err := xyJson.GetTime(root, "$.event.at")
+func GetTime(root IValue, path string) (time.Time, error) {
+	result, err := getTime(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getTime 不补充错误上下文的GetTime
+// getTime is GetTime without the error context
+func getTime(root IValue, path string) (time.Time, error) {
+	value, err := Get(root, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ToTime(value)
+}
+
+// GetBytes 使用JSONPath获取字节数组，字符串按base64解码
+// GetBytes gets byte array using JSONPath, strings are decoded as base64
+//
+// 非base64字符串返回其原始字节，与ToBytes保持一致
+// Non-base64 strings return their raw bytes, consistent with ToBytes
+func GetBytes(root IValue, path string) ([]byte, error) {
+	result, err := getBytes(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getBytes 不补充错误上下文的GetBytes
+// getBytes is GetBytes without the error context
+func getBytes(root IValue, path string) ([]byte, error) {
+	value, err := Get(root, path)
+	if err != nil {
+		return nil, err
+	}
+	return ToBytes(value)
+}
+
+// GetUint64 使用JSONPath获取64位无符号整数值
+// GetUint64 gets 64-bit unsigned integer value using JSONPath
+func GetUint64(root IValue, path string) (uint64, error) {
+	result, err := getUint64(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getUint64 不补充错误上下文的GetUint64
+// getUint64 is GetUint64 without the error context
+func getUint64(root IValue, path string) (uint64, error) {
+	value, err := Get(root, path)
+	if err != nil {
+		return 0, err
+	}
+	return ToUint64(value)
+}
+
+// GetDuration 使用JSONPath获取时间间隔
+// GetDuration gets duration using JSONPath
+//
+// 数字按毫秒解释，字符串如"5s"按time.ParseDuration解析
+// Numbers are interpreted as milliseconds, strings such as "5s" are parsed with time.ParseDuration
+//
+// 示例 Example:
+//
+//	data := `{"timeout":"5s","retry":250}`
+//	root, _ := xyJson.ParseString(data)
+//	timeout, _ := xyJson.GetDuration(root, "$.timeout") // 5s
+//	retry, _ := xyJson.GetDuration(root, "$.retry")     // 250ms
+func GetDuration(root IValue, path string) (time.Duration, error) {
+	result, err := getDuration(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getDuration 不补充错误上下文的GetDuration
+// getDuration is GetDuration without the error context
+func getDuration(root IValue, path string) (time.Duration, error) {
+	value, err := Get(root, path)
+	if err != nil {
+		return 0, err
+	}
+	return ToDuration(value)
+}
+
+// MustGetTime 使用JSONPath获取时间值，如果失败则返回零值时间
+// MustGetTime gets time value using JSONPath, returns zero time on failure
+func MustGetTime(root IValue, path string) time.Time {
+	result, err := getTime(root, path)
+	if err != nil {
+		return time.Time{}
+	}
+	return result
+}
+
+// MustGetBytes 使用JSONPath获取字节数组，如果失败则返回nil
+// MustGetBytes gets byte array using JSONPath, returns nil on failure
+func MustGetBytes(root IValue, path string) []byte {
+	result, err := getBytes(root, path)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// MustGetUint64 使用JSONPath获取64位无符号整数值，如果失败则返回0
+// MustGetUint64 gets 64-bit unsigned integer value using JSONPath, returns 0 on failure
+func MustGetUint64(root IValue, path string) uint64 {
+	result, err := getUint64(root, path)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// MustGetDuration 使用JSONPath获取时间间隔，如果失败则返回0
+// MustGetDuration gets duration using JSONPath, returns 0 on failure
+func MustGetDuration(root IValue, path string) time.Duration {
+	result, err := getDuration(root, path)
+	if err != nil {
+		return 0
+	}
+	return result
+}
+
+// TryGetTime 使用JSONPath尝试获取时间值
+// TryGetTime attempts to get time value using JSONPath
+func TryGetTime(root IValue, path string) (time.Time, bool) {
+	result, err := getTime(root, path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return result, true
+}
+
+// TryGetBytes 使用JSONPath尝试获取字节数组
+// TryGetBytes attempts to get byte array using JSONPath
+func TryGetBytes(root IValue, path string) ([]byte, bool) {
+	result, err := getBytes(root, path)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// TryGetUint64 使用JSONPath尝试获取64位无符号整数值
+// TryGetUint64 attempts to get 64-bit unsigned integer value using JSONPath
+func TryGetUint64(root IValue, path string) (uint64, bool) {
+	result, err := getUint64(root, path)
+	if err != nil {
+		return 0, false
+	}
+	return result, true
+}
+
+// TryGetDuration 使用JSONPath尝试获取时间间隔
+// TryGetDuration attempts to get duration using JSONPath
+func TryGetDuration(root IValue, path string) (time.Duration, bool) {
+	result, err := getDuration(root, path)
+	if err != nil {
+		return 0, false
+	}
+	return result, true
+}
+
+// GetTimeWithDefault 使用JSONPath获取时间值，失败时返回默认值
+// GetTimeWithDefault gets time value using JSONPath, returns default value on failure
+func GetTimeWithDefault(root IValue, path string, defaultValue time.Time) time.Time {
+	if result, ok := TryGetTime(root, path); ok {
+		return result
+	}
+	return defaultValue
+}
+
+// GetBytesWithDefault 使用JSONPath获取字节数组，失败时返回默认值
+// GetBytesWithDefault gets byte array using JSONPath, returns default value on failure
+func GetBytesWithDefault(root IValue, path string, defaultValue []byte) []byte {
+	if result, ok := TryGetBytes(root, path); ok {
+		return result
+	}
+	return defaultValue
+}
+
+// GetUint64WithDefault 使用JSONPath获取64位无符号整数值，失败时返回默认值
+// GetUint64WithDefault gets 64-bit unsigned integer value using JSONPath, returns default value on failure
+func GetUint64WithDefault(root IValue, path string, defaultValue uint64) uint64 {
+	if result, ok := TryGetUint64(root, path); ok {
+		return result
+	}
+	return defaultValue
+}
+
+// GetDurationWithDefault 使用JSONPath获取时间间隔，失败时返回默认值
+// GetDurationWithDefault gets duration using JSONPath, returns default value on failure
+//
+// 示例 Example:
+//
+//	timeout := xyJson.GetDurationWithDefault(root, "$.timeout", 30*time.Second)
+func GetDurationWithDefault(root IValue, path string, defaultValue time.Duration) time.Duration {
+	if result, ok := TryGetDuration(root, path); ok {
+		return result
+	}
+	return defaultValue
+}
@@ -0,0 +1,344 @@
+package xyJson
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// DecodeStrategy 解码策略
+// DecodeStrategy is a decoding strategy
+type DecodeStrategy int
+
+const (
+	// DecodeAuto 根据目标类型和数据自动选择策略
+	// DecodeAuto picks a strategy from the target type and the data
+	DecodeAuto DecodeStrategy = iota
+	// DecodeDirect 使用自定义解析器直接写入结构体，仅支持简单结构体
+	// DecodeDirect writes directly into structs with the custom parser, plain structs only
+	DecodeDirect
+	// DecodeValue 先解析为IValue再映射到目标，支持map、interface、指针和time.Time
+	// DecodeValue parses into IValue first and then maps it to the target, supporting maps, interfaces,
+	// pointers and time.Time
+	DecodeValue
+	// DecodeStdlib 使用encoding/json
+	// DecodeStdlib uses encoding/json
+	DecodeStdlib
+)
+
+// String 返回解码策略的字符串表示
+// String returns the string representation of the decoding strategy
+func (ds DecodeStrategy) String() string {
+	switch ds {
+	case DecodeAuto:
+		return "auto"
+	case DecodeDirect:
+		return "direct"
+	case DecodeValue:
+		return "value"
+	case DecodeStdlib:
+		return "stdlib"
+	default:
+		return "unknown"
+	}
+}
+
+// UnmarshalOptions 解码选项
+// UnmarshalOptions represents decoding options
+type UnmarshalOptions struct {
+	// Strategy 解码策略
+	// Strategy is the decoding strategy
+	Strategy DecodeStrategy
+
+	// NullPolicy null写入不可为空字段（非指针、非interface/map/slice）时的行为
+	// NullPolicy is the behavior when null is decoded into a non-nullable field (not a pointer, interface, map
+	// or slice)
+	NullPolicy NullPolicy
+
+	// Presence 非nil时记录每个结构体字段是显式null、有值还是缺失，解码前会被清空
+	// Presence, when non-nil, records whether each struct field was explicitly null, set or absent; it is
+	// reset before decoding
+	Presence *FieldPresence
+
+	// StrictNumbers 保留用于兼容，Unmarshal的所有策略总是严格按RFC 8259语法校验数字
+	// StrictNumbers is kept for compatibility, every strategy of Unmarshal always checks numbers strictly
+	// against the RFC 8259 grammar
+	//
+	// Deprecated: 数字总是被严格校验 Numbers are always checked strictly.
+	StrictNumbers bool
+
+	// SkipValidation 是否跳过解码后的validate标签检查
+	// SkipValidation indicates whether to skip the validate tag checks after decoding
+	SkipValidation bool
+
+	// Numbers 数字字段的转换选项，例如浮点数写入整数字段时的截断策略；nil表示默认选项
+	// Numbers are the conversion options of numeric fields, such as the truncation policy when a float is
+	// decoded into an integer field; nil means the defaults
+	Numbers *NumberConversionOptions
+
+	// lenient 自定义解析器接受非严格的数字、重复的键和值之后的多余数据，只用于保持旧解码函数的行为
+	// lenient lets the custom parser accept non-strict numbers, duplicate keys and extra data after the value,
+	// only used to keep the behavior of the legacy decoding functions
+	lenient bool
+}
+
+// DefaultUnmarshalOptions 返回默认解码选项
+// DefaultUnmarshalOptions returns default decoding options
+func DefaultUnmarshalOptions() *UnmarshalOptions {
+	return &UnmarshalOptions{
+		Strategy:   DecodeAuto,
+		NullPolicy: NullAsZero,
+	}
+}
+
+var (
+	// plainTypeCache 记录类型是否可以由自定义解析器直接解码
+	// plainTypeCache records whether a type can be decoded directly by the custom parser
+	plainTypeCache = make(map[reflect.Type]bool)
+	plainTypeMutex sync.RWMutex
+
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Unmarshal 将JSON字节数组解码到target，统一了所有解码路径
+// Unmarshal decodes a JSON byte array into target, unifying all decoding paths
+//
+// 默认只根据目标类型自动选择策略：只含标量、嵌套结构体和切片的简单结构体使用自定义解析器直接写入；
+// 含map、interface、指针或time.Time的目标使用IValue中间表示；target为*IValue时直接返回解析树
+// By default the strategy is picked from the target type alone: plain structs containing only scalars, nested
+// structs and slices are written directly by the custom parser; targets with maps, interfaces, pointers or
+// time.Time go through the IValue representation; a *IValue target receives the parsed tree
+//
+// 两种策略接受相同的输入并以相同的方式转换：都拒绝不符合RFC 8259的数字、重复的键和值之后的多余数据；
+// 数字和布尔值写入字符串字段时都转换为其文本，容器写入字符串字段时都得到空字符串
+// Both strategies accept the same input and convert it the same way: both reject non-RFC 8259 numbers,
+// duplicate keys and extra data after the value; numbers and booleans decoded into string fields become their
+// text and containers decoded into string fields become the empty string
+//
+// NullPolicy和Presence需要IValue中间表示，设置它们时自动选择DecodeValue，显式指定其它策略会返回错误
+// NullPolicy and Presence need the IValue representation, setting them picks DecodeValue automatically and
+// explicitly requesting another strategy returns an error
+//
+// 解码成功后按validate标签检查target，失败时返回*ValidationError，详见ValidateStruct
+// After a successful decode target is checked against its validate tags, returning a *ValidationError on
+// failure, see ValidateStruct
+//
+// 示例 Example:
+//
+//	var user User
+//	err := xyJson.Unmarshal(data, &user)
+//
+//	var doc map[string]interface{}
+//	err = xyJson.Unmarshal(data, &doc, &xyJson.UnmarshalOptions{Strategy: xyJson.DecodeStdlib})
+func Unmarshal(data []byte, target interface{}, options ...*UnmarshalOptions) (err error) {
+	defer recoverPanic("unmarshal", &err)
+	opts := DefaultUnmarshalOptions()
+	for _, o := range options {
+		if o != nil {
+			opts = o
+		}
+	}
+
+	if target == nil {
+		return NewNullPointerError("target cannot be nil")
+	}
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr {
+		return NewJSONError(ErrInvalidOperation, "target must be a pointer", nil)
+	}
+	if rv.IsNil() {
+		return NewNullPointerError("target pointer cannot be nil")
+	}
+
+	data, err = decodeInput(data)
+	if err != nil {
+		return err
+	}
+
+	strategy := opts.Strategy
+	inspected := opts.NullPolicy != NullAsZero || opts.Presence != nil
+	if inspected {
+		if strategy != DecodeAuto && strategy != DecodeValue {
+			return NewInvalidOperationError("unmarshal", "null policy and presence require the value strategy, got "+strategy.String())
+		}
+		strategy = DecodeValue
+	} else if strategy == DecodeAuto {
+		strategy = selectDecodeStrategy(rv.Elem().Type())
+	}
+
+	switch strategy {
+	case DecodeDirect:
+		parser := NewCustomParserWithOptions(&ParseOptions{StrictNumbers: !opts.lenient}).(*customParser)
+		parser.strictSyntax = !opts.lenient
+		parser.numbers = opts.Numbers
+		err = parser.UnmarshalDirect(data, target)
+	case DecodeValue:
+		err = unmarshalValue(data, rv.Elem(), opts)
+	case DecodeStdlib:
+		err = json.Unmarshal(data, target)
+	default:
+		return NewInvalidOperationError("unmarshal", "unknown strategy "+strategy.String())
+	}
+	if err != nil || opts.SkipValidation {
+		return err
+	}
+	return validateValue(rv)
+}
+
+// UnmarshalString 将JSON字符串解码到target
+// UnmarshalString decodes a JSON string into target
+func UnmarshalString(data string, target interface{}, options ...*UnmarshalOptions) error {
+	return Unmarshal([]byte(data), target, options...)
+}
+
+// MustUnmarshal 将JSON字节数组解码到target，失败时panic
+// MustUnmarshal decodes a JSON byte array into target, panics on failure
+func MustUnmarshal(data []byte, target interface{}, options ...*UnmarshalOptions) {
+	if err := Unmarshal(data, target, options...); err != nil {
+		panic(err)
+	}
+}
+
+// DecodeWithPresence 将JSON字节数组解码到target，并返回输入中出现过的字段
+// DecodeWithPresence decodes a JSON byte array into target and returns the fields present in the input
+//
+// 用于区分"字段被省略"和"字段被设为零值"，适合PATCH风格的更新
+// Used to distinguish "field omitted" from "field set to its zero value", suited to PATCH-style updates
+//
+// 示例 Example:
+//
+//	var patch UserPatch
+//	presence, err := xyJson.DecodeWithPresence(body, &patch)
+//	if err != nil {
+//		return err
+//	}
+//	if presence.Has("address.city") {
+//		user.Address.City = patch.Address.City
+//	}
+func DecodeWithPresence(data []byte, target interface{}) (*FieldPresence, error) {
+	presence := NewFieldPresence()
+	if err := Unmarshal(data, target, &UnmarshalOptions{Presence: presence}); err != nil {
+		return nil, err
+	}
+	return presence, nil
+}
+
+// selectDecodeStrategy 只根据目标类型选择解码策略，同一类型总是使用同一策略
+// selectDecodeStrategy picks a decoding strategy from the target type alone, so a type always uses the same
+// strategy
+func selectDecodeStrategy(t reflect.Type) DecodeStrategy {
+	if t.Kind() == reflect.Struct && isPlainType(t) {
+		return DecodeDirect
+	}
+	return DecodeValue
+}
+
+// unmarshalValue 先解析为IValue再映射到目标
+// unmarshalValue parses into IValue first and then maps it to the target
+func unmarshalValue(data []byte, elem reflect.Value, opts *UnmarshalOptions) error {
+	value, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	// 在写入目标之前检查null策略，失败时目标保持不变
+	// Check the null policy before writing the target, so the target is untouched on failure
+	if opts.NullPolicy != NullAsZero || opts.Presence != nil {
+		if opts.Presence != nil {
+			opts.Presence.Reset()
+		}
+		inspector := &nullInspector{policy: opts.NullPolicy, presence: opts.Presence}
+		if err := inspector.inspect(value, elem.Type(), "$", 0); err != nil {
+			return err
+		}
+	}
+
+	if elem.Type() == ivalueType {
+		elem.Set(reflect.ValueOf(&value).Elem())
+		return nil
+	}
+	if elem.Kind() == reflect.Struct && opts.Numbers == nil {
+		return defaultSerializer().SerializeToStruct(value, elem.Addr().Interface())
+	}
+
+	mapper := mappingSerializer
+	if opts.Numbers != nil {
+		// 数字转换选项属于单次解码 The number conversion options belong to this decode
+		mapper = &serializer{options: mappingSerializer.options, numbers: opts.Numbers}
+	}
+	if elem.Kind() == reflect.Struct {
+		return mapper.SerializeToStruct(value, elem.Addr().Interface())
+	}
+
+	visited := make(map[IValue]bool)
+	return mapper.setValueByType(elem, value, elem.Type(), visited, 0)
+}
+
+// isPlainType 检查类型是否只包含自定义解析器支持的成员
+// isPlainType checks whether a type only contains members supported by the custom parser
+func isPlainType(t reflect.Type) bool {
+	plainTypeMutex.RLock()
+	plain, exists := plainTypeCache[t]
+	plainTypeMutex.RUnlock()
+	if exists {
+		return plain
+	}
+
+	plain = checkPlainType(t, make(map[reflect.Type]bool))
+
+	plainTypeMutex.Lock()
+	defer plainTypeMutex.Unlock()
+	if len(plainTypeCache) < StructCacheSize {
+		plainTypeCache[t] = plain
+	}
+	return plain
+}
+
+// checkPlainType 递归检查类型，visiting用于处理递归类型
+// checkPlainType checks a type recursively, visiting handles recursive types
+func checkPlainType(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice, reflect.Array:
+		// []byte在encoding/json中是base64字符串
+		// []byte is a base64 string in encoding/json
+		if t.Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		return checkPlainType(t.Elem(), visiting)
+	case reflect.Struct:
+		if t == timeType {
+			return false
+		}
+		if visiting[t] {
+			return true
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous || parseJSONTag(field.Tag.Get("json")).AsString {
+				return false
+			}
+			if !checkPlainType(field.Type, visiting) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+package xyJson
+
+// virtualMetaKey 存储虚拟路径定义的内部元数据键
+// virtualMetaKey is the internal metadata key holding virtual path definitions
+const virtualMetaKey = "xyjson.virtual"
+
+// VirtualFunc 计算虚拟路径的值，root为定义虚拟路径的文档；返回nil表示路径不存在
+// VirtualFunc computes the value of a virtual path, root is the document the path is defined on; returning
+// nil means the path does not exist
+type VirtualFunc func(root IValue) IValue
+
+// virtualPath 一个虚拟路径定义
+// virtualPath is a single virtual path definition
+type virtualPath struct {
+	path     string
+	segments []*pathSegment
+	fn       VirtualFunc
+}
+
+// DefineVirtual 在文档上定义计算路径，通过Get、typed getter、SelectAll、Exists和预编译路径查询时
+// 惰性求值，每次查询都会重新调用fn
+// DefineVirtual defines a computed path on a document, evaluated lazily by Get, the typed getters,
+// SelectAll, Exists and compiled paths, calling fn again on every query
+//
+// path只能由属性和索引组成。查询路径以虚拟路径开头时，剩余部分作用于fn的结果，因此虚拟路径可以返回
+// 对象供进一步查询；虚拟路径优先于文档中同一位置的真实值。通配符和递归下降不会匹配虚拟路径，
+// 虚拟路径也不参与序列化、Set和Delete。定义保存在root的元数据中，Clone得到的副本继承已有的定义。
+// 同一文档上的DefineVirtual和RemoveVirtual不能并发调用
+// path may only consist of properties and indices. When a query path starts with a virtual path, the rest
+// of it applies to the result of fn, so a virtual path may return an object for further querying; virtual
+// paths take precedence over real values at the same location. Wildcards and recursive descent never match
+// virtual paths, and virtual paths take no part in serialization, Set or Delete. Definitions are kept in the
+// metadata of root and copies made by Clone inherit the existing ones. DefineVirtual and RemoveVirtual must
+// not be called concurrently on the same document
+//
+// 示例 Example:
+//
+//	xyJson.DefineVirtual(order, "$.totalPrice", func(root xyJson.IValue) xyJson.IValue {
+//		total := 0.0
+//		items, _ := xyJson.GetAll(root, "$.items[*]")
+//		for _, item := range items {
+//			total += xyJson.MustGetFloat64(item, "$.price") * xyJson.MustGetFloat64(item, "$.qty")
+//		}
+//		return xyJson.CreateNumber(total)
+//	})
+//	total, _ := xyJson.GetFloat64(order, "$.totalPrice")
+func DefineVirtual(root IValue, path string, fn VirtualFunc) error {
+	if root == nil {
+		return NewNullPointerError("root cannot be nil")
+	}
+	if fn == nil {
+		return NewNullPointerError("virtual function cannot be nil")
+	}
+
+	segments, err := parseVirtualPath(path)
+	if err != nil {
+		return err
+	}
+
+	existing := virtualPaths(root)
+	defs := make([]*virtualPath, 0, len(existing)+1)
+	for _, def := range existing {
+		if !segmentsEqual(def.segments, segments) {
+			defs = append(defs, def)
+		}
+	}
+	defs = append(defs, &virtualPath{path: path, segments: segments, fn: fn})
+	root.SetMeta(virtualMetaKey, defs)
+	return nil
+}
+
+// RemoveVirtual 删除虚拟路径定义，返回定义是否存在
+// RemoveVirtual removes a virtual path definition, returning whether it existed
+func RemoveVirtual(root IValue, path string) bool {
+	if root == nil {
+		return false
+	}
+	segments, err := parseVirtualPath(path)
+	if err != nil {
+		return false
+	}
+
+	existing := virtualPaths(root)
+	defs := make([]*virtualPath, 0, len(existing))
+	for _, def := range existing {
+		if !segmentsEqual(def.segments, segments) {
+			defs = append(defs, def)
+		}
+	}
+	if len(defs) == len(existing) {
+		return false
+	}
+	if len(defs) == 0 {
+		root.SetMeta(virtualMetaKey, nil)
+	} else {
+		root.SetMeta(virtualMetaKey, defs)
+	}
+	return true
+}
+
+// VirtualPaths 按定义顺序返回文档上的虚拟路径
+// VirtualPaths returns the virtual paths defined on a document, in definition order
+func VirtualPaths(root IValue) []string {
+	if root == nil {
+		return nil
+	}
+	defs := virtualPaths(root)
+	paths := make([]string, len(defs))
+	for i, def := range defs {
+		paths[i] = def.path
+	}
+	return paths
+}
+
+// virtualPaths 返回文档上的虚拟路径定义；定义列表写时复制，Clone共享的列表不会被修改
+// virtualPaths returns the virtual path definitions of a document; the list is copy-on-write, so a list
+// shared through Clone is never modified
+func virtualPaths(root IValue) []*virtualPath {
+	defs, _ := root.Meta(virtualMetaKey).([]*virtualPath)
+	return defs
+}
+
+// parseVirtualPath 解析虚拟路径，只接受属性和索引段
+// parseVirtualPath parses a virtual path, accepting only property and index segments
+func parseVirtualPath(path string) ([]*pathSegment, error) {
+	segments, err := (&pathQuery{}).parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, NewInvalidOperationError("define virtual", "the root cannot be a virtual path")
+	}
+	for _, segment := range segments {
+		if !plainSegment(segment) {
+			return nil, NewInvalidOperationError("define virtual", "only properties and indices are supported: "+path)
+		}
+	}
+	return segments, nil
+}
+
+// plainSegment 检查段是否为普通的属性或索引
+// plainSegment checks whether a segment is a plain property or index
+func plainSegment(segment *pathSegment) bool {
+	return (segment.Type == PropertySegmentType || segment.Type == IndexSegmentType) &&
+		!segment.Recursive && !segment.Wildcard && !segment.Append && segment.Filter == nil
+}
+
+// segmentsEqual 检查两个普通段序列是否相同
+// segmentsEqual checks whether two sequences of plain segments are equal
+func segmentsEqual(a, b []*pathSegment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !plainSegment(b[i]) || a[i].Type != b[i].Type || a[i].Key != b[i].Key || a[i].Index != b[i].Index {
+			return false
+		}
+	}
+	return true
+}
+
+// selectVirtual 查找作为查询路径前缀的最长虚拟路径并求值，剩余段作用于结果
+// selectVirtual finds the longest virtual path that prefixes the query and evaluates it, applying the
+// remaining segments to the result
+func (pq *pathQuery) selectVirtual(root IValue, segments []*pathSegment, selectAll bool) ([]IValue, bool) {
+	defs := virtualPaths(root)
+	var match *virtualPath
+	for _, def := range defs {
+		n := len(def.segments)
+		if n <= len(segments) && segmentsEqual(def.segments, segments[:n]) && (match == nil || n > len(match.segments)) {
+			match = def
+		}
+	}
+	if match == nil {
+		return nil, false
+	}
+
+	value := match.fn(root)
+	if value == nil {
+		return nil, true
+	}
+	return pq.executeQuery(value, segments[len(match.segments):], selectAll), true
+}
@@ -42,50 +42,10 @@
 package xyJson
 
 import (
-	"sync"
+	"context"
 	"time"
 )
 
-// 包级别的全局实例，提供便捷的默认功能访问
-// Package-level global instances providing convenient access to default functionality
-var (
-	// defaultFactory 默认值工厂，用于创建各种JSON值类型
-	// defaultFactory is the default value factory for creating various JSON value types
-	defaultFactory IValueFactory
-
-	// defaultParser 默认解析器，用于解析JSON数据
-	// defaultParser is the default parser for parsing JSON data
-	defaultParser IParser
-
-	// defaultSerializer 默认序列化器，用于序列化JSON值
-	// defaultSerializer is the default serializer for serializing JSON values
-	defaultSerializer ISerializer
-
-	// defaultPathQuery 默认路径查询器，用于JSONPath查询
-	// defaultPathQuery is the default path query for JSONPath operations
-	defaultPathQuery IPathQuery
-
-	// parserPool parser对象池，用于重用parser实例以提高性能和减少内存分配
-	// parserPool is a parser object pool for reusing parser instances to improve performance and reduce memory allocation
-	parserPool sync.Pool
-)
-
-// init 初始化默认实例
-// init initializes default instances
-func init() {
-	pool := NewObjectPool()
-	defaultFactory = NewValueFactoryWithPool(pool)
-	defaultParser = NewParserWithFactory(defaultFactory)
-	defaultSerializer = NewSerializer()
-	defaultPathQuery = NewPathQueryWithFactory(defaultFactory)
-
-	// 初始化parser对象池
-	// Initialize parser object pool
-	parserPool.New = func() interface{} {
-		return NewParserWithFactory(defaultFactory)
-	}
-}
-
 // Parse 解析JSON字节数组为IValue接口
 // Parse parses a JSON byte array into an IValue interface
 //
@@ -107,6 +67,24 @@ func init() {
 //	obj := value.(xyJson.IObject)
 //	name := obj.Get("name").String() // "Alice"
 func Parse(data []byte) (IValue, error) {
+	return ParseContext(context.Background(), data)
+}
+
+// ParseContext 解析JSON字节数据，启用剖析标签时在ctx的标签之上打标签，结束后恢复ctx的标签
+// ParseContext parses JSON byte data, tagging on top of the labels of ctx when profiling labels are enabled
+// and restoring the labels of ctx afterwards
+//
+// 示例 Example:
+//
+//	pprof.Do(ctx, pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+//		value, err := xyJson.ParseContext(ctx, data)
+//		// ...
+//	})
+func ParseContext(ctx context.Context, data []byte) (_ IValue, err error) {
+	hooks, start := parseHooks.start()
+	defer func() { runHooks(hooks, start, len(data), err) }()
+	defer recoverPanic("parse", &err)
+	defer labelParse(ctx, len(data))()
 	timer := GetGlobalMonitor().StartParseTimer()
 	var hasError bool
 	defer func() {
@@ -119,12 +97,15 @@ func Parse(data []byte) (IValue, error) {
 
 	// 从对象池获取parser实例以提高性能
 	// Get parser instance from object pool for better performance
-	parser := parserPool.Get().(IParser)
-	defer parserPool.Put(parser)
+	defaults := currentDefaults.Load()
+	pool := defaults.parserPool
+	parser := pool.Get().(IParser)
+	defer pool.Put(parser)
 
 	result, err := parser.Parse(data)
 	if err != nil {
 		hasError = true
+		recordParseFailure(defaults.failureRecorder, data, err)
 	}
 	return result, err
 }
@@ -149,7 +130,11 @@ func Parse(data []byte) (IValue, error) {
 //	// 访问数组元素 / Access array elements
 //	arr := value.(xyJson.IArray)
 //	firstItem := arr.Get(0) // 1
-func ParseString(data string) (IValue, error) {
+func ParseString(data string) (_ IValue, err error) {
+	hooks, start := parseHooks.start()
+	defer func() { runHooks(hooks, start, len(data), err) }()
+	defer recoverPanic("parse", &err)
+	defer labelParse(context.Background(), len(data))()
 	timer := GetGlobalMonitor().StartParseTimer()
 	var hasError bool
 	defer func() {
@@ -162,12 +147,17 @@ func ParseString(data string) (IValue, error) {
 
 	// 从对象池获取parser实例以提高性能
 	// Get parser instance from object pool for better performance
-	parser := parserPool.Get().(IParser)
-	defer parserPool.Put(parser)
+	defaults := currentDefaults.Load()
+	pool := defaults.parserPool
+	parser := pool.Get().(IParser)
+	defer pool.Put(parser)
 
 	result, err := parser.ParseString(data)
 	if err != nil {
 		hasError = true
+		if defaults.failureRecorder != nil {
+			recordParseFailure(defaults.failureRecorder, []byte(data), err)
+		}
 	}
 	return result, err
 }
@@ -219,7 +209,8 @@ func MustParseString(data string) IValue {
 //		log.Fatal(err)
 //	}
 //	fmt.Println(value.String())
-func ParseFromMap(data map[string]interface{}) (IValue, error) {
+func ParseFromMap(data map[string]interface{}) (_ IValue, err error) {
+	defer recoverPanic("parse from map", &err)
 	timer := GetGlobalMonitor().StartParseTimer()
 	var hasError bool
 	defer func() {
@@ -232,8 +223,9 @@ func ParseFromMap(data map[string]interface{}) (IValue, error) {
 
 	// 从对象池获取parser实例以提高性能
 	// Get parser instance from object pool for better performance
-	parser := parserPool.Get().(IParser)
-	defer parserPool.Put(parser)
+	pool := currentDefaults.Load().parserPool
+	parser := pool.Get().(IParser)
+	defer pool.Put(parser)
 
 	result, err := parser.ParseFromMap(data)
 	if err != nil {
@@ -288,6 +280,24 @@ func MustParseFromMap(data map[string]interface{}) IValue {
 //	}
 //	fmt.Println(string(data)) // {"name":"Bob","age":30}
 func Serialize(value IValue) ([]byte, error) {
+	return SerializeContext(context.Background(), value)
+}
+
+// SerializeContext 序列化JSON值，启用剖析标签时在ctx的标签之上打标签，结束后恢复ctx的标签
+// SerializeContext serializes a JSON value, tagging on top of the labels of ctx when profiling labels are
+// enabled and restoring the labels of ctx afterwards
+//
+// 示例 Example:
+//
+//	pprof.Do(ctx, pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+//		data, err := xyJson.SerializeContext(ctx, value)
+//		// ...
+//	})
+func SerializeContext(ctx context.Context, value IValue) (out []byte, err error) {
+	hooks, start := serializeHooks.start()
+	defer func() { runHooks(hooks, start, len(out), err) }()
+	defer recoverPanic("serialize", &err)
+	defer labelSerialize(ctx, value)()
 	timer := GetGlobalMonitor().StartSerializeTimer()
 	var hasError bool
 	defer func() {
@@ -298,7 +308,7 @@ func Serialize(value IValue) ([]byte, error) {
 		}
 	}()
 
-	result, err := defaultSerializer.Serialize(value)
+	result, err := defaultSerializer().Serialize(value)
 	if err != nil {
 		hasError = true
 	}
@@ -326,7 +336,11 @@ func Serialize(value IValue) ([]byte, error) {
 //		log.Fatal(err)
 //	}
 //	fmt.Println(jsonStr) // [1,"hello",true]
-func SerializeToString(value IValue) (string, error) {
+func SerializeToString(value IValue) (out string, err error) {
+	hooks, start := serializeHooks.start()
+	defer func() { runHooks(hooks, start, len(out), err) }()
+	defer recoverPanic("serialize", &err)
+	defer labelSerialize(context.Background(), value)()
 	timer := GetGlobalMonitor().StartSerializeTimer()
 	var hasError bool
 	defer func() {
@@ -337,7 +351,7 @@ func SerializeToString(value IValue) (string, error) {
 		}
 	}()
 
-	result, err := defaultSerializer.SerializeToString(value)
+	result, err := defaultSerializer().SerializeToString(value)
 	if err != nil {
 		hasError = true
 	}
@@ -366,7 +380,8 @@ func MustSerializeToString(value IValue) string {
 
 // Pretty 格式化JSON值
 // Pretty formats JSON value with indentation
-func Pretty(value IValue) (string, error) {
+func Pretty(value IValue) (_ string, err error) {
+	defer recoverPanic("serialize", &err)
 	prettySerializer := PrettySerializer(DefaultIndent)
 	return prettySerializer.SerializeToString(value)
 }
@@ -383,7 +398,8 @@ func MustPretty(value IValue) string {
 
 // Compact 压缩JSON值
 // Compact compacts JSON value
-func Compact(value IValue) (string, error) {
+func Compact(value IValue) (_ string, err error) {
+	defer recoverPanic("serialize", &err)
 	compactSerializer := CompactSerializer()
 	return compactSerializer.SerializeToString(value)
 }
@@ -422,8 +438,9 @@ func MustCompact(value IValue) string {
 //		log.Fatal(err)
 //	}
 //	fmt.Printf("Name: %s, Age: %d\n", person.Name, person.Age)
-func SerializeToStruct(value IValue, target interface{}) error {
-	return defaultSerializer.SerializeToStruct(value, target)
+func SerializeToStruct(value IValue, target interface{}) (err error) {
+	defer recoverPanic("serialize to struct", &err)
+	return defaultSerializer().SerializeToStruct(value, target)
 }
 
 // MustSerializeToStruct 将JSON值序列化到Go结构体，如果失败则panic
@@ -445,7 +462,7 @@ func SerializeToStruct(value IValue, target interface{}) error {
 //	xyJson.MustSerializeToStruct(value, &config)
 //	fmt.Printf("Server: %s:%d\n", config.Host, config.Port)
 func MustSerializeToStruct(value IValue, target interface{}) {
-	defaultSerializer.MustSerializeToStruct(value, target)
+	defaultSerializer().MustSerializeToStruct(value, target)
 }
 
 // UnmarshalToStruct 解析JSON字节数组并序列化到Go结构体
@@ -471,12 +488,10 @@ func MustSerializeToStruct(value IValue, target interface{}) {
 //	if err != nil {
 //		log.Fatal(err)
 //	}
+//
+// Deprecated: 请使用Unmarshal并按需指定DecodeValue策略 / use Unmarshal, with the DecodeValue strategy if needed.
 func UnmarshalToStruct(data []byte, target interface{}) error {
-	value, err := Parse(data)
-	if err != nil {
-		return err
-	}
-	return SerializeToStruct(value, target)
+	return Unmarshal(data, target, legacyUnmarshalOptions(DecodeValue))
 }
 
 // MustUnmarshalToStruct 解析JSON字节数组并序列化到Go结构体，如果失败则panic
@@ -496,9 +511,10 @@ func UnmarshalToStruct(data []byte, target interface{}) error {
 //	data := []byte(`{"debug":true,"timeout":30}`)
 //	var settings Settings
 //	xyJson.MustUnmarshalToStruct(data, &settings)
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeValue策略 / use MustUnmarshal, with the DecodeValue strategy if needed.
 func MustUnmarshalToStruct(data []byte, target interface{}) {
-	value := MustParse(data)
-	MustSerializeToStruct(value, target)
+	MustUnmarshal(data, target, legacyUnmarshalOptions(DecodeValue))
 }
 
 // UnmarshalStringToStruct 解析JSON字符串并序列化到Go结构体
@@ -510,12 +526,10 @@ func MustUnmarshalToStruct(data []byte, target interface{}) {
 //
 // 返回值 Returns:
 //   - error: 解析或序列化错误 / Parse or serialization error
+//
+// Deprecated: 请使用UnmarshalString并按需指定DecodeValue策略 / use UnmarshalString, with the DecodeValue strategy if needed.
 func UnmarshalStringToStruct(data string, target interface{}) error {
-	value, err := ParseString(data)
-	if err != nil {
-		return err
-	}
-	return SerializeToStruct(value, target)
+	return UnmarshalString(data, target, legacyUnmarshalOptions(DecodeValue))
 }
 
 // MustUnmarshalStringToStruct 解析JSON字符串并序列化到Go结构体，如果失败则panic
@@ -524,9 +538,10 @@ func UnmarshalStringToStruct(data string, target interface{}) error {
 // 参数 Parameters:
 //   - data: JSON字符串 / JSON string
 //   - target: 目标结构体指针 / Target struct pointer
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeValue策略 / use MustUnmarshal, with the DecodeValue strategy if needed.
 func MustUnmarshalStringToStruct(data string, target interface{}) {
-	value := MustParseString(data)
-	MustSerializeToStruct(value, target)
+	MustUnmarshal([]byte(data), target, legacyUnmarshalOptions(DecodeValue))
 }
 
 // UnmarshalToStructFast 快速解析JSON字节数组到Go结构体（跳过IValue中间表示）
@@ -545,8 +560,10 @@ func MustUnmarshalStringToStruct(data string, target interface{}) {
 //   - error: 解析或序列化错误 / Parse or serialization error
 //
 // 注意 Note: 此函数为性能优化版本，功能相对简化 / This is a performance-optimized version with simplified functionality
+//
+// Deprecated: 请使用Unmarshal并按需指定DecodeStdlib策略 / use Unmarshal, with the DecodeStdlib strategy if needed.
 func UnmarshalToStructFast(data []byte, target interface{}) error {
-	return defaultSerializer.UnmarshalToStructFast(data, target)
+	return Unmarshal(data, target, legacyUnmarshalOptions(DecodeStdlib))
 }
 
 // UnmarshalStringToStructFast 快速解析JSON字符串到Go结构体（跳过IValue中间表示）
@@ -558,8 +575,10 @@ func UnmarshalToStructFast(data []byte, target interface{}) error {
 //
 // 返回值 Returns:
 //   - error: 解析或序列化错误 / Parse or serialization error
+//
+// Deprecated: 请使用UnmarshalString并按需指定DecodeStdlib策略 / use UnmarshalString, with the DecodeStdlib strategy if needed.
 func UnmarshalStringToStructFast(data string, target interface{}) error {
-	return UnmarshalToStructFast([]byte(data), target)
+	return UnmarshalString(data, target, legacyUnmarshalOptions(DecodeStdlib))
 }
 
 // MustUnmarshalToStructFast 快速解析JSON字节数组到Go结构体，如果失败则panic
@@ -568,6 +587,8 @@ func UnmarshalStringToStructFast(data string, target interface{}) error {
 // 参数 Parameters:
 //   - data: JSON字节数组 / JSON byte array
 //   - target: 目标结构体指针 / Target struct pointer
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeStdlib策略 / use MustUnmarshal, with the DecodeStdlib strategy if needed.
 func MustUnmarshalToStructFast(data []byte, target interface{}) {
 	err := UnmarshalToStructFast(data, target)
 	if err != nil {
@@ -581,6 +602,8 @@ func MustUnmarshalToStructFast(data []byte, target interface{}) {
 // 参数 Parameters:
 //   - data: JSON字符串 / JSON string
 //   - target: 目标结构体指针 / Target struct pointer
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeStdlib策略 / use MustUnmarshal, with the DecodeStdlib strategy if needed.
 func MustUnmarshalStringToStructFast(data string, target interface{}) {
 	err := UnmarshalStringToStructFast(data, target)
 	if err != nil {
@@ -611,18 +634,24 @@ func MustUnmarshalStringToStructFast(data string, target interface{}) {
 //	}
 //	var user User
 //	err := xyJson.UnmarshalToStructCustom([]byte(`{"name":"Alice","age":25}`), &user)
+//
+// Deprecated: 请使用Unmarshal并按需指定DecodeDirect策略 / use Unmarshal, with the DecodeDirect strategy if needed.
 func UnmarshalToStructCustom(data []byte, target interface{}) error {
-	return defaultSerializer.UnmarshalToStructCustom(data, target)
+	return Unmarshal(data, target, legacyUnmarshalOptions(DecodeDirect))
 }
 
 // UnmarshalStringToStructCustom 使用自定义解析器将JSON字符串解析到结构体
 // UnmarshalStringToStructCustom unmarshals JSON string to struct using custom parser
+//
+// Deprecated: 请使用UnmarshalString并按需指定DecodeDirect策略 / use UnmarshalString, with the DecodeDirect strategy if needed.
 func UnmarshalStringToStructCustom(data string, target interface{}) error {
-	return defaultSerializer.UnmarshalStringToStructCustom(data, target)
+	return UnmarshalString(data, target, legacyUnmarshalOptions(DecodeDirect))
 }
 
 // MustUnmarshalToStructCustom 使用自定义解析器解析JSON到结构体，如果失败则panic
 // MustUnmarshalToStructCustom unmarshals JSON to struct using custom parser, panics on failure
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeDirect策略 / use MustUnmarshal, with the DecodeDirect strategy if needed.
 func MustUnmarshalToStructCustom(data []byte, target interface{}) {
 	err := UnmarshalToStructCustom(data, target)
 	if err != nil {
@@ -632,6 +661,8 @@ func MustUnmarshalToStructCustom(data []byte, target interface{}) {
 
 // MustUnmarshalStringToStructCustom 使用自定义解析器解析JSON字符串到结构体，如果失败则panic
 // MustUnmarshalStringToStructCustom unmarshals JSON string to struct using custom parser, panics on failure
+//
+// Deprecated: 请使用MustUnmarshal并按需指定DecodeDirect策略 / use MustUnmarshal, with the DecodeDirect strategy if needed.
 func MustUnmarshalStringToStructCustom(data string, target interface{}) {
 	err := UnmarshalStringToStructCustom(data, target)
 	if err != nil {
@@ -639,6 +670,18 @@ func MustUnmarshalStringToStructCustom(data string, target interface{}) {
 	}
 }
 
+// legacyUnmarshalOptions 旧的结构体解码函数使用的选项：固定的策略，不检查validate标签，自定义解析器保持宽松
+// legacyUnmarshalOptions are the options used by the old struct decoding functions: a fixed strategy, no
+// validate tag checks and the lenient custom parser
+func legacyUnmarshalOptions(strategy DecodeStrategy) *UnmarshalOptions {
+	return &UnmarshalOptions{
+		Strategy:       strategy,
+		NullPolicy:     NullAsZero,
+		SkipValidation: true,
+		lenient:        true,
+	}
+}
+
 // Get 使用JSONPath表达式从根值中获取单个匹配的值
 // Get retrieves a single matching value from the root using a JSONPath expression
 //
@@ -665,8 +708,9 @@ func MustUnmarshalStringToStructCustom(data string, target interface{}) {
 //	if err == nil {
 //		fmt.Println(name.String()) // "Alice"
 //	}
-func Get(root IValue, path string) (IValue, error) {
-	return defaultPathQuery.SelectOne(root, path)
+func Get(root IValue, path string) (_ IValue, err error) {
+	defer recoverPanic("get", &err)
+	return defaultPathQuery().SelectOne(root, path)
 }
 
 // MustGet 使用JSONPath获取值，如果失败则返回null值
@@ -713,41 +757,53 @@ func MustGet(root IValue, path string) IValue {
 //			fmt.Println(price.String()) // 10, 20, 15
 //		}
 //	}
-func GetAll(root IValue, path string) ([]IValue, error) {
-	return defaultPathQuery.SelectAll(root, path)
+func GetAll(root IValue, path string) (_ []IValue, err error) {
+	defer recoverPanic("get all", &err)
+	return defaultPathQuery().SelectAll(root, path)
 }
 
 // Set 根据路径设置值
 // Set sets value by path
 func Set(root IValue, path string, value any) error {
-	v, err := defaultFactory.CreateFromRaw(value)
-	if err != nil {
-		return err
-	}
-	return defaultPathQuery.Set(root, path, v)
+	return SetContext(context.Background(), root, path, value)
+}
+
+// AppendPath 将值追加到路径指向的数组末尾，数组不存在时自动创建
+// AppendPath appends a value to the end of the array at the path, creating the array when missing
+//
+// 等价于Set(root, path+"[+]", value)
+// Equivalent to Set(root, path+"[+]", value)
+//
+// 示例 Example:
+//
+//	root := xyJson.MustParseString(`{"items":[1,2]}`)
+//	xyJson.AppendPath(root, "$.items", 3) // {"items":[1,2,3]}
+func AppendPath(root IValue, path string, value any) error {
+	return Set(root, path+"[+]", value)
 }
 
 // Delete 根据路径删除值
 // Delete deletes value by path
 func Delete(root IValue, path string) error {
-	return defaultPathQuery.Delete(root, path)
+	return DeleteContext(context.Background(), root, path)
 }
 
 // Exists 检查路径是否存在
 // Exists checks if path exists
 func Exists(root IValue, path string) bool {
-	return defaultPathQuery.Exists(root, path)
+	return defaultPathQuery().Exists(root, path)
 }
 
 // Count 统计匹配路径的数量
 // Count counts matching paths
 func Count(root IValue, path string) int {
-	return defaultPathQuery.Count(root, path)
+	return defaultPathQuery().Count(root, path)
 }
 
 // Filter 根据条件过滤JSONPath查询结果
 // Filter filters JSONPath query results based on a condition
-func Filter(root IValue, path string, predicate func(IValue) bool) ([]IValue, error) {
+func Filter(root IValue, path string, predicate func(IValue) bool) (_ []IValue, err error) {
+	defer recoverPanic("filter", &err)
 	if predicate == nil {
 		return nil, NewJSONError(ErrInvalidOperation, "predicate cannot be nil", nil)
 	}
@@ -909,6 +965,13 @@ func SetBatch(root IValue, operations []BatchSetOperation) []BatchSetResult {
 //		fmt.Println(name) // "Alice"
 //	}
 func GetString(root IValue, path string) (string, error) {
+	result, err := getString(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getString 不补充错误上下文的GetString
+// getString is GetString without the error context
+func getString(root IValue, path string) (string, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return "", err
@@ -927,6 +990,13 @@ func GetString(root IValue, path string) (string, error) {
 //   - int: 整数值 / Integer value
 //   - error: 查询或转换错误 / Query or conversion error
 func GetInt(root IValue, path string) (int, error) {
+	result, err := getInt(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getInt 不补充错误上下文的GetInt
+// getInt is GetInt without the error context
+func getInt(root IValue, path string) (int, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return 0, err
@@ -937,6 +1007,13 @@ func GetInt(root IValue, path string) (int, error) {
 // GetInt64 使用JSONPath获取64位整数值
 // GetInt64 gets 64-bit integer value using JSONPath
 func GetInt64(root IValue, path string) (int64, error) {
+	result, err := getInt64(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getInt64 不补充错误上下文的GetInt64
+// getInt64 is GetInt64 without the error context
+func getInt64(root IValue, path string) (int64, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return 0, err
@@ -964,6 +1041,13 @@ func GetInt64(root IValue, path string) (int64, error) {
 //		fmt.Printf("Price: %.2f\n", price) // "Price: 29.99"
 //	}
 func GetFloat64(root IValue, path string) (float64, error) {
+	result, err := getFloat64(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getFloat64 不补充错误上下文的GetFloat64
+// getFloat64 is GetFloat64 without the error context
+func getFloat64(root IValue, path string) (float64, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return 0, err
@@ -982,6 +1066,13 @@ func GetFloat64(root IValue, path string) (float64, error) {
 //   - bool: 布尔值 / Boolean value
 //   - error: 查询或转换错误 / Query or conversion error
 func GetBool(root IValue, path string) (bool, error) {
+	result, err := getBool(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getBool 不补充错误上下文的GetBool
+// getBool is GetBool without the error context
+func getBool(root IValue, path string) (bool, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return false, err
@@ -1000,6 +1091,13 @@ func GetBool(root IValue, path string) (bool, error) {
 //   - IObject: 对象值 / Object value
 //   - error: 查询或转换错误 / Query or conversion error
 func GetObject(root IValue, path string) (IObject, error) {
+	result, err := getObject(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getObject 不补充错误上下文的GetObject
+// getObject is GetObject without the error context
+func getObject(root IValue, path string) (IObject, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return CreateObject(), err
@@ -1018,6 +1116,13 @@ func GetObject(root IValue, path string) (IObject, error) {
 //   - IArray: 数组值 / Array value
 //   - error: 查询或转换错误 / Query or conversion error
 func GetArray(root IValue, path string) (IArray, error) {
+	result, err := getArray(root, path)
+	return result, withPathContext(root, path, err)
+}
+
+// getArray 不补充错误上下文的GetArray
+// getArray is GetArray without the error context
+func getArray(root IValue, path string) (IArray, error) {
 	value, err := Get(root, path)
 	if err != nil {
 		return CreateArray(), err
@@ -1039,7 +1144,7 @@ func GetArray(root IValue, path string) (IArray, error) {
 // This method returns empty string on failure as a safer alternative
 // 推荐使用TryGetString作为更安全的替代方案 / Consider using TryGetString as a safer alternative
 func MustGetString(root IValue, path string) string {
-	result, err := GetString(root, path)
+	result, err := getString(root, path)
 	if err != nil {
 		return ""
 	}
@@ -1053,7 +1158,7 @@ func MustGetString(root IValue, path string) string {
 // This method returns 0 on failure as a safer alternative
 // 推荐使用TryGetInt作为更安全的替代方案 / Consider using TryGetInt as a safer alternative
 func MustGetInt(root IValue, path string) int {
-	result, err := GetInt(root, path)
+	result, err := getInt(root, path)
 	if err != nil {
 		return 0
 	}
@@ -1067,7 +1172,7 @@ func MustGetInt(root IValue, path string) int {
 // This method returns 0 on failure as a safer alternative
 // 推荐使用TryGetInt64作为更安全的替代方案 / Consider using TryGetInt64 as a safer alternative
 func MustGetInt64(root IValue, path string) int64 {
-	result, err := GetInt64(root, path)
+	result, err := getInt64(root, path)
 	if err != nil {
 		return 0
 	}
@@ -1088,7 +1193,7 @@ func MustGetInt64(root IValue, path string) int64 {
 // This method returns 0.0 on failure as a safer alternative
 // 推荐使用TryGetFloat64作为更安全的替代方案 / Consider using TryGetFloat64 as a safer alternative
 func MustGetFloat64(root IValue, path string) float64 {
-	result, err := GetFloat64(root, path)
+	result, err := getFloat64(root, path)
 	if err != nil {
 		return 0.0
 	}
@@ -1102,7 +1207,7 @@ func MustGetFloat64(root IValue, path string) float64 {
 // This method returns false on failure as a safer alternative
 // 推荐使用TryGetBool作为更安全的替代方案 / Consider using TryGetBool as a safer alternative
 func MustGetBool(root IValue, path string) bool {
-	result, err := GetBool(root, path)
+	result, err := getBool(root, path)
 	if err != nil {
 		return false
 	}
@@ -1116,7 +1221,7 @@ func MustGetBool(root IValue, path string) bool {
 // This method returns empty object on failure as a safer alternative
 // 推荐使用TryGetObject作为更安全的替代方案 / Consider using TryGetObject as a safer alternative
 func MustGetObject(root IValue, path string) IObject {
-	result, err := GetObject(root, path)
+	result, err := getObject(root, path)
 	if err != nil {
 		return CreateObject()
 	}
@@ -1129,7 +1234,7 @@ func MustGetObject(root IValue, path string) IObject {
 // 注意 Note: 此方法在失败时返回空数组，作为更安全的替代方案
 // This method returns empty array on failure as a safer alternative
 func MustGetArray(root IValue, path string) IArray {
-	result, err := GetArray(root, path)
+	result, err := getArray(root, path)
 	if err != nil {
 		return CreateArray()
 	}
@@ -1157,7 +1262,7 @@ func MustGetArray(root IValue, path string) IArray {
 //		fmt.Println("获取姓名失败")
 //	}
 func TryGetString(root IValue, path string) (string, bool) {
-	result, err := GetString(root, path)
+	result, err := getString(root, path)
 	if err != nil {
 		return "", false
 	}
@@ -1175,7 +1280,7 @@ func TryGetString(root IValue, path string) (string, bool) {
 //   - int: 整数值，失败时返回0 / Integer value, 0 on failure
 //   - bool: 是否成功获取 / Whether the operation succeeded
 func TryGetInt(root IValue, path string) (int, bool) {
-	result, err := GetInt(root, path)
+	result, err := getInt(root, path)
 	if err != nil {
 		return 0, false
 	}
@@ -1185,7 +1290,7 @@ func TryGetInt(root IValue, path string) (int, bool) {
 // TryGetInt64 使用JSONPath尝试获取64位整数值
 // TryGetInt64 attempts to get 64-bit integer value using JSONPath
 func TryGetInt64(root IValue, path string) (int64, bool) {
-	result, err := GetInt64(root, path)
+	result, err := getInt64(root, path)
 	if err != nil {
 		return 0, false
 	}
@@ -1213,7 +1318,7 @@ func TryGetInt64(root IValue, path string) (int64, bool) {
 //		fmt.Println("获取价格失败")
 //	}
 func TryGetFloat64(root IValue, path string) (float64, bool) {
-	result, err := GetFloat64(root, path)
+	result, err := getFloat64(root, path)
 	if err != nil {
 		return 0.0, false
 	}
@@ -1231,7 +1336,7 @@ func TryGetFloat64(root IValue, path string) (float64, bool) {
 //   - bool: 布尔值，失败时返回false / Boolean value, false on failure
 //   - bool: 是否成功获取 / Whether the operation succeeded
 func TryGetBool(root IValue, path string) (bool, bool) {
-	result, err := GetBool(root, path)
+	result, err := getBool(root, path)
 	if err != nil {
 		return false, false
 	}
@@ -1249,7 +1354,7 @@ func TryGetBool(root IValue, path string) (bool, bool) {
 //   - IObject: 对象值，失败时返回nil / Object value, nil on failure
 //   - bool: 是否成功获取 / Whether the operation succeeded
 func TryGetObject(root IValue, path string) (IObject, bool) {
-	result, err := GetObject(root, path)
+	result, err := getObject(root, path)
 	if err != nil {
 		return nil, false
 	}
@@ -1267,7 +1372,7 @@ func TryGetObject(root IValue, path string) (IObject, bool) {
 //   - IArray: 数组值，失败时返回nil / Array value, nil on failure
 //   - bool: 是否成功获取 / Whether the operation succeeded
 func TryGetArray(root IValue, path string) (IArray, bool) {
-	result, err := GetArray(root, path)
+	result, err := getArray(root, path)
 	if err != nil {
 		return nil, false
 	}
@@ -1431,7 +1536,7 @@ func GetArrayWithDefault(root IValue, path string, defaultValue IArray) IArray {
 //	fmt.Println(nullValue.IsNull()) // true
 //	fmt.Println(nullValue.String()) // "null"
 func CreateNull() IValue {
-	return defaultFactory.CreateNull()
+	return defaultFactory().CreateNull()
 }
 
 // CreateString 创建一个JSON字符串值
@@ -1449,7 +1554,7 @@ func CreateNull() IValue {
 //	fmt.Println(strValue.String()) // "Hello, World!"
 //	fmt.Println(strValue.Type()) // StringType
 func CreateString(value string) IValue {
-	return defaultFactory.CreateString(value)
+	return defaultFactory().CreateString(value)
 }
 
 // CreateNumber 创建一个JSON数字值
@@ -1468,7 +1573,7 @@ func CreateString(value string) IValue {
 //	fmt.Println(intValue.String()) // "42"
 //	fmt.Println(floatValue.String()) // "3.14159"
 func CreateNumber(value interface{}) IValue {
-	return defaultFactory.CreateNumber(value)
+	return defaultFactory().CreateNumber(value)
 }
 
 // MustCreateNumber 创建数字值，如果失败则返回null值
@@ -1480,13 +1585,13 @@ func MustCreateNumber(value interface{}) IValue {
 // CreateBool 创建布尔值
 // CreateBool creates a boolean value
 func CreateBool(value bool) IValue {
-	return defaultFactory.CreateBool(value)
+	return defaultFactory().CreateBool(value)
 }
 
 // CreateObject 创建对象
 // CreateObject creates an object
 func CreateObject() IObject {
-	return defaultFactory.CreateObject()
+	return defaultFactory().CreateObject()
 }
 
 // CreateObjectWithCapacity 创建指定容量的对象
@@ -1499,7 +1604,7 @@ func CreateObjectWithCapacity(capacity int) IObject {
 // CreateArray 创建数组
 // CreateArray creates an array
 func CreateArray() IArray {
-	return defaultFactory.CreateArray()
+	return defaultFactory().CreateArray()
 }
 
 // CreateArrayWithCapacity 创建指定容量的数组
@@ -1511,8 +1616,9 @@ func CreateArrayWithCapacity(capacity int) IArray {
 
 // CreateFromRaw 从原始数据创建JSON值
 // CreateFromRaw creates JSON value from raw data
-func CreateFromRaw(value interface{}) (IValue, error) {
-	return defaultFactory.CreateFromRaw(value)
+func CreateFromRaw(value interface{}) (_ IValue, err error) {
+	defer recoverPanic("create from raw", &err)
+	return defaultFactory().CreateFromRaw(value)
 }
 
 // MustCreateFromRaw 从原始数据创建JSON值，如果失败则返回null值
@@ -1528,40 +1634,38 @@ func MustCreateFromRaw(value interface{}) IValue {
 // NewBuilder 创建JSON构建器
 // NewBuilder creates a JSON builder
 func NewBuilder() *JSONBuilder {
-	return NewJSONBuilderWithFactory(defaultFactory)
+	return NewJSONBuilderWithFactory(defaultFactory())
 }
 
 // GetDefaultFactory 获取默认工厂
 // GetDefaultFactory gets the default factory
 func GetDefaultFactory() IValueFactory {
-	return defaultFactory
+	return defaultFactory()
 }
 
 // GetDefaultParser 获取默认解析器
 // GetDefaultParser gets the default parser
 func GetDefaultParser() IParser {
-	return defaultParser
+	return currentDefaults.Load().parser
 }
 
 // GetDefaultSerializer 获取默认序列化器
 // GetDefaultSerializer gets the default serializer
 func GetDefaultSerializer() ISerializer {
-	return defaultSerializer
+	return defaultSerializer()
 }
 
 // GetDefaultPathQuery 获取默认路径查询器
 // GetDefaultPathQuery gets the default path query
 func GetDefaultPathQuery() IPathQuery {
-	return defaultPathQuery
+	return defaultPathQuery()
 }
 
 // SetDefaultFactory 设置默认工厂
 // SetDefaultFactory sets the default factory
 func SetDefaultFactory(factory IValueFactory) {
 	if factory != nil {
-		defaultFactory = factory
-		defaultParser = NewParserWithFactory(factory)
-		defaultPathQuery = NewPathQueryWithFactory(factory)
+		updateDefaults(func(d *Defaults) *Defaults { return d.WithFactory(factory) })
 	}
 }
 
@@ -1569,7 +1673,7 @@ func SetDefaultFactory(factory IValueFactory) {
 // SetDefaultParser sets the default parser
 func SetDefaultParser(parser IParser) {
 	if parser != nil {
-		defaultParser = parser
+		updateDefaults(func(d *Defaults) *Defaults { return d.WithParser(parser) })
 	}
 }
 
@@ -1577,7 +1681,7 @@ func SetDefaultParser(parser IParser) {
 // SetDefaultSerializer sets the default serializer
 func SetDefaultSerializer(serializer ISerializer) {
 	if serializer != nil {
-		defaultSerializer = serializer
+		updateDefaults(func(d *Defaults) *Defaults { return d.WithSerializer(serializer) })
 	}
 }
 
@@ -1585,7 +1689,7 @@ func SetDefaultSerializer(serializer ISerializer) {
 // SetDefaultPathQuery sets the default path query
 func SetDefaultPathQuery(pathQuery IPathQuery) {
 	if pathQuery != nil {
-		defaultPathQuery = pathQuery
+		updateDefaults(func(d *Defaults) *Defaults { return d.WithPathQuery(pathQuery) })
 	}
 }
 
@@ -1619,9 +1723,10 @@ func StartMemoryProfiling() {
 	GetGlobalProfiler().Start()
 }
 
-// StopMemoryProfiling 停止内存分析
-// StopMemoryProfiling stops memory profiling
+// StopMemoryProfiling 停止内存分析，同时停止StartMemoryProfilingWithLabels启用的pprof标签
+// StopMemoryProfiling stops memory profiling and the pprof labels enabled by StartMemoryProfilingWithLabels
 func StopMemoryProfiling() {
+	profileLabelsEnabled.Store(false)
 	GetGlobalProfiler().Stop()
 }
 
@@ -1648,9 +1753,10 @@ func GetMemoryTrend() (trend string, growth float64) {
 
 // ToString 转换为字符串
 // ToString converts to string
-func ToString(value IValue) (string, error) {
+func ToString(value IValue) (_ string, err error) {
+	defer recoverPanic("convert", &err)
 	if value == nil {
-		return "", NewTypeMismatchError(StringValueType, NullValueType, "")
+		return "", NewNullValueError(StringValueType)
 	}
 	if value.Type() == StringValueType {
 		return value.String(), nil
@@ -1663,7 +1769,11 @@ func ToString(value IValue) (string, error) {
 
 // ToInt 转换为整数
 // ToInt converts to integer
-func ToInt(value IValue) (int, error) {
+func ToInt(value IValue) (_ int, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return 0, NewNullValueError(NumberValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Int()
 	}
@@ -1672,7 +1782,11 @@ func ToInt(value IValue) (int, error) {
 
 // ToInt64 转换为64位整数
 // ToInt64 converts to 64-bit integer
-func ToInt64(value IValue) (int64, error) {
+func ToInt64(value IValue) (_ int64, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return 0, NewNullValueError(NumberValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Int64()
 	}
@@ -1681,7 +1795,11 @@ func ToInt64(value IValue) (int64, error) {
 
 // ToFloat64 转换为64位浮点数
 // ToFloat64 converts to 64-bit float
-func ToFloat64(value IValue) (float64, error) {
+func ToFloat64(value IValue) (_ float64, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return 0, NewNullValueError(NumberValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Float64()
 	}
@@ -1690,7 +1808,11 @@ func ToFloat64(value IValue) (float64, error) {
 
 // ToBool 转换为布尔值
 // ToBool converts to boolean
-func ToBool(value IValue) (bool, error) {
+func ToBool(value IValue) (_ bool, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return false, NewNullValueError(BoolValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Bool()
 	}
@@ -1699,7 +1821,11 @@ func ToBool(value IValue) (bool, error) {
 
 // ToTime 转换为时间
 // ToTime converts to time
-func ToTime(value IValue) (time.Time, error) {
+func ToTime(value IValue) (_ time.Time, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return time.Time{}, NewNullValueError(StringValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Time()
 	}
@@ -1708,7 +1834,11 @@ func ToTime(value IValue) (time.Time, error) {
 
 // ToBytes 转换为字节数组
 // ToBytes converts to byte array
-func ToBytes(value IValue) ([]byte, error) {
+func ToBytes(value IValue) (_ []byte, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return nil, NewNullValueError(StringValueType)
+	}
 	if scalar, ok := value.(IScalarValue); ok {
 		return scalar.Bytes()
 	}
@@ -1717,7 +1847,11 @@ func ToBytes(value IValue) ([]byte, error) {
 
 // ToObject 转换为对象
 // ToObject converts to object
-func ToObject(value IValue) (IObject, error) {
+func ToObject(value IValue) (_ IObject, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return nil, NewNullValueError(ObjectValueType)
+	}
 	if obj, ok := value.(IObject); ok {
 		return obj, nil
 	}
@@ -1726,7 +1860,11 @@ func ToObject(value IValue) (IObject, error) {
 
 // ToArray 转换为数组
 // ToArray converts to array
-func ToArray(value IValue) (IArray, error) {
+func ToArray(value IValue) (_ IArray, err error) {
+	defer recoverPanic("convert", &err)
+	if value == nil {
+		return nil, NewNullValueError(ArrayValueType)
+	}
 	if arr, ok := value.(IArray); ok {
 		return arr, nil
 	}
@@ -0,0 +1,270 @@
+package xyjsontest
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+// TypeWeights 生成各类值的相对权重，权重为0的类型不会生成
+// TypeWeights are the relative weights of each kind of generated value, kinds with weight 0 are never generated
+type TypeWeights struct {
+	Null   int
+	Bool   int
+	Number int
+	String int
+	Object int
+	Array  int
+}
+
+// total 返回权重之和
+// total returns the sum of the weights
+func (tw TypeWeights) total() int {
+	return tw.Null + tw.Bool + tw.Number + tw.String + tw.Object + tw.Array
+}
+
+// GeneratorOptions 随机文档生成选项
+// GeneratorOptions holds random document generator options
+type GeneratorOptions struct {
+	// Seed 随机种子，0表示使用基于时间的种子；相同种子和选项生成相同的文档序列
+	// Seed is the random seed, 0 means a time-based seed; the same seed and options produce the same sequence
+	// of documents
+	Seed int64
+
+	// MaxDepth 最大嵌套深度，达到后只生成标量
+	// MaxDepth is the maximum nesting depth, only scalars are generated beyond it
+	MaxDepth int
+
+	// MaxWidth 每个对象的最大键数和每个数组的最大元素数
+	// MaxWidth is the maximum number of keys per object and elements per array
+	MaxWidth int
+
+	// MaxStringLength 字符串和键的最大长度（按字符计）
+	// MaxStringLength is the maximum length of strings and keys, in characters
+	MaxStringLength int
+
+	// Weights 值类型分布
+	// Weights is the value type distribution
+	Weights TypeWeights
+}
+
+// DefaultGeneratorOptions 返回默认生成选项
+// DefaultGeneratorOptions returns default generator options
+func DefaultGeneratorOptions() *GeneratorOptions {
+	return &GeneratorOptions{
+		Seed:            0,
+		MaxDepth:        4,
+		MaxWidth:        6,
+		MaxStringLength: 12,
+		Weights: TypeWeights{
+			Null:   1,
+			Bool:   2,
+			Number: 4,
+			String: 4,
+			Object: 2,
+			Array:  2,
+		},
+	}
+}
+
+// stringRunes 生成字符串使用的字符，包含需要转义的字符和多字节字符
+// stringRunes are the characters strings are generated from, including characters that need escaping and
+// multi-byte characters
+var stringRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.\"\\/\n\téü世界😀")
+
+// mutationTokens Mutate插入的片段
+// mutationTokens are the fragments Mutate inserts
+var mutationTokens = []string{"{", "}", "[", "]", ",", ":", "\"", "\\", "nul", "tru", "-", "1e", "0x", "\x00", "é"}
+
+// Generator 可复现的随机JSON文档生成器，用于基于属性的测试，不能并发使用
+// Generator is a reproducible random JSON document generator for property-based testing, not safe for
+// concurrent use
+//
+// 示例 Example:
+//
+//	gen := xyjsontest.NewGenerator(&xyjsontest.GeneratorOptions{Seed: 42, MaxDepth: 3, MaxWidth: 5,
+//		MaxStringLength: 8, Weights: xyjsontest.TypeWeights{Number: 1, String: 1, Object: 1}})
+//	for i := 0; i < 1000; i++ {
+//		doc := gen.Document()
+//		data, _ := xyJson.Serialize(doc)
+//		parsed, err := xyJson.Parse(data)
+//		if err != nil || !parsed.Equals(doc) {
+//			t.Fatalf("round trip failed for seed %d: %s", gen.Seed(), data)
+//		}
+//		xyJson.Parse(gen.Mutate(data)) // 不应panic / must not panic
+//	}
+type Generator struct {
+	rand    *rand.Rand
+	seed    int64
+	options GeneratorOptions
+}
+
+// NewGenerator 使用指定选项创建生成器，options为nil时使用默认选项
+// NewGenerator creates a generator with the specified options, using the defaults when options is nil
+func NewGenerator(options *GeneratorOptions) *Generator {
+	if options == nil {
+		options = DefaultGeneratorOptions()
+	}
+	opts := *options
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.MaxWidth < 0 {
+		opts.MaxWidth = 0
+	}
+	if opts.MaxStringLength < 0 {
+		opts.MaxStringLength = 0
+	}
+	if opts.Weights.total() <= 0 {
+		opts.Weights = DefaultGeneratorOptions().Weights
+	}
+	return &Generator{
+		rand:    rand.New(rand.NewSource(opts.Seed)),
+		seed:    opts.Seed,
+		options: opts,
+	}
+}
+
+// Seed 返回实际使用的种子，用于复现失败
+// Seed returns the seed actually in use, for reproducing failures
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// Document 生成一个随机文档；权重允许时根节点总是对象或数组
+// Document generates a random document; the root is always an object or array when the weights allow one
+func (g *Generator) Document() xyJson.IValue {
+	weights := g.options.Weights
+	if weights.Object+weights.Array > 0 && g.options.MaxDepth > 0 {
+		if g.rand.Intn(weights.Object+weights.Array) < weights.Object {
+			return g.object(1)
+		}
+		return g.array(1)
+	}
+	return g.value(1)
+}
+
+// value 按权重生成depth层的值
+// value generates a value at depth according to the weights
+func (g *Generator) value(depth int) xyJson.IValue {
+	weights := g.options.Weights
+	if depth > g.options.MaxDepth {
+		weights.Object, weights.Array = 0, 0
+		if weights.total() == 0 {
+			return xyJson.CreateNull()
+		}
+	}
+
+	n := g.rand.Intn(weights.total())
+	switch {
+	case n < weights.Null:
+		return xyJson.CreateNull()
+	case n < weights.Null+weights.Bool:
+		return xyJson.CreateBool(g.rand.Intn(2) == 0)
+	case n < weights.Null+weights.Bool+weights.Number:
+		return xyJson.CreateNumber(g.number())
+	case n < weights.Null+weights.Bool+weights.Number+weights.String:
+		return xyJson.CreateString(g.text(0))
+	case n < weights.total()-weights.Array:
+		return g.object(depth)
+	default:
+		return g.array(depth)
+	}
+}
+
+// object 生成对象，键互不相同且非空
+// object generates an object with distinct, non-empty keys
+func (g *Generator) object(depth int) xyJson.IValue {
+	obj := xyJson.CreateObject()
+	width := g.rand.Intn(g.options.MaxWidth + 1)
+	for i := 0; i < width; i++ {
+		key := g.text(1)
+		if obj.Has(key) {
+			key += strconv.Itoa(i)
+		}
+		obj.Set(key, g.value(depth+1))
+	}
+	return obj
+}
+
+// array 生成数组
+// array generates an array
+func (g *Generator) array(depth int) xyJson.IValue {
+	arr := xyJson.CreateArray()
+	width := g.rand.Intn(g.options.MaxWidth + 1)
+	for i := 0; i < width; i++ {
+		arr.Append(g.value(depth + 1))
+	}
+	return arr
+}
+
+// number 生成整数或带小数部分的浮点数；浮点数总是有小数部分，序列化往返后类型不变
+// number generates an integer or a float with a fractional part; floats always have a fractional part so
+// their type survives a serialization round trip
+func (g *Generator) number() interface{} {
+	switch g.rand.Intn(3) {
+	case 0:
+		return int64(g.rand.Intn(201) - 100)
+	case 1:
+		return g.rand.Int63() - g.rand.Int63()
+	default:
+		f := (g.rand.Float64() - 0.5) * 1e6
+		if f == float64(int64(f)) {
+			f += 0.25
+		}
+		return f
+	}
+}
+
+// text 生成长度不小于minLength的随机字符串
+// text generates a random string of at least minLength characters
+func (g *Generator) text(minLength int) string {
+	length := minLength
+	if g.options.MaxStringLength > minLength {
+		length += g.rand.Intn(g.options.MaxStringLength - minLength + 1)
+	}
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteRune(stringRunes[g.rand.Intn(len(stringRunes))])
+	}
+	return sb.String()
+}
+
+// Mutate 对JSON文本做一次随机的小改动，生成接近有效的变体，用于检验解析器对畸形输入的健壮性
+// Mutate makes one small random change to JSON text, producing a near-valid variant for checking how robust
+// a parser is against malformed input
+//
+// 改动包括删除、复制或交换字节，插入结构字符和不完整的字面量，以及截断。结果通常是无效的JSON，
+// 但也可能仍然有效。data不会被修改
+// Changes include deleting, duplicating or swapping bytes, inserting structural characters and incomplete
+// literals, and truncating. The result is usually invalid JSON but may still be valid. data is not modified
+func (g *Generator) Mutate(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte(mutationTokens[g.rand.Intn(len(mutationTokens))])
+	}
+
+	pos := g.rand.Intn(len(data))
+	out := make([]byte, 0, len(data)+4)
+	switch g.rand.Intn(5) {
+	case 0: // 删除一个字节 / delete a byte
+		out = append(append(out, data[:pos]...), data[pos+1:]...)
+	case 1: // 复制一个字节 / duplicate a byte
+		out = append(append(append(out, data[:pos+1]...), data[pos]), data[pos+1:]...)
+	case 2: // 插入片段 / insert a fragment
+		token := mutationTokens[g.rand.Intn(len(mutationTokens))]
+		out = append(append(append(out, data[:pos]...), token...), data[pos:]...)
+	case 3: // 交换相邻字节 / swap adjacent bytes
+		out = append(out, data...)
+		if pos+1 < len(out) {
+			out[pos], out[pos+1] = out[pos+1], out[pos]
+		} else if pos > 0 {
+			out[pos], out[pos-1] = out[pos-1], out[pos]
+		}
+	default: // 截断 / truncate
+		out = append(out, data[:pos]...)
+	}
+	return out
+}
@@ -0,0 +1,373 @@
+// Package xyjsontest 提供针对JSON文档的测试断言，失败时输出逐路径的差异
+// Package xyjsontest provides test assertions on JSON documents, printing a per-path diff on failure
+//
+// 文档参数可以是IValue、JSON文本（string或[]byte）或任意可由CreateFromRaw转换的Go值。
+// 数字按数值比较，1与1.0相等；对象键的顺序和空白不影响结果
+// Document arguments may be an IValue, JSON text (string or []byte) or any Go value CreateFromRaw can convert.
+// Numbers are compared by value, so 1 equals 1.0; key order and whitespace do not matter
+//
+// 示例 Example:
+//
+//	func TestHandler(t *testing.T) {
+//		body := callHandler()
+//		xyjsontest.AssertEqualJSON(t, `{"id": 1, "name": "Alice", "createdAt": ""}`, body, "$.createdAt")
+//		xyjsontest.AssertSubset(t, `{"name": "Alice"}`, body)
+//		xyjsontest.AssertPathEquals(t, body, "$.id", 1)
+//	}
+package xyjsontest
+
+import (
+	"fmt"
+	"strings"
+
+	xyJson "github.com/ihuem/xyJson"
+)
+
+// MaxReportedDifferences 失败信息中最多列出的差异数
+// MaxReportedDifferences is the maximum number of differences listed in a failure message
+const MaxReportedDifferences = 20
+
+// maxRenderedLength 差异中渲染值的最大长度
+// maxRenderedLength is the maximum length of a value rendered in a difference
+const maxRenderedLength = 80
+
+// TestingT 断言所需的testing.TB子集
+// TestingT is the subset of testing.TB the assertions need
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// DifferenceKind 差异的种类
+// DifferenceKind is the kind of a difference
+type DifferenceKind int
+
+const (
+	// Changed 两边都有值但不相等
+	// Changed means both sides have a value but they differ
+	Changed DifferenceKind = iota
+	// Missing 期望的值在实际文档中不存在
+	// Missing means the expected value is absent from the actual document
+	Missing
+	// Unexpected 实际文档中有期望文档没有的值
+	// Unexpected means the actual document has a value the expected document does not
+	Unexpected
+)
+
+// String 返回差异种类的字符串表示
+// String returns the string representation of the difference kind
+func (dk DifferenceKind) String() string {
+	switch dk {
+	case Changed:
+		return "changed"
+	case Missing:
+		return "missing"
+	case Unexpected:
+		return "unexpected"
+	default:
+		return "unknown"
+	}
+}
+
+// Difference 两个文档在某个路径上的差异
+// Difference is a difference between two documents at one path
+type Difference struct {
+	// Path 差异所在的JSONPath
+	// Path is the JSONPath of the difference
+	Path string
+
+	// Kind 差异的种类
+	// Kind is the kind of the difference
+	Kind DifferenceKind
+
+	// Expected和Actual 两边的值，Missing时Actual为nil，Unexpected时Expected为nil
+	// Expected and Actual are the values on each side, Actual is nil for Missing and Expected is nil for
+	// Unexpected
+	Expected xyJson.IValue
+	Actual   xyJson.IValue
+}
+
+// String 返回差异的单行描述
+// String returns a one-line description of the difference
+func (d Difference) String() string {
+	switch d.Kind {
+	case Missing:
+		return fmt.Sprintf("%s: missing, expected %s", d.Path, render(d.Expected))
+	case Unexpected:
+		return fmt.Sprintf("%s: unexpected %s", d.Path, render(d.Actual))
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", d.Path, render(d.Expected), render(d.Actual))
+	}
+}
+
+// Diff 比较两个文档，返回按路径排列的所有差异；ignorePaths匹配的节点（支持通配符）在两边都被跳过
+// Diff compares two documents and returns every difference in path order; nodes matched by ignorePaths
+// (wildcards are supported) are skipped on both sides
+func Diff(expected, actual xyJson.IValue, ignorePaths ...string) []Difference {
+	d := newDiffer(expected, actual, ignorePaths, false)
+	d.compare(expected, actual, "$")
+	return d.differences
+}
+
+// AssertEqualJSON 断言两个文档相等，ignorePaths匹配的节点被忽略
+// AssertEqualJSON asserts that two documents are equal, ignoring the nodes matched by ignorePaths
+func AssertEqualJSON(t TestingT, expected, actual interface{}, ignorePaths ...string) bool {
+	t.Helper()
+	expectedValue, actualValue, ok := documents(t, expected, actual)
+	if !ok {
+		return false
+	}
+	return report(t, "JSON documents differ", Diff(expectedValue, actualValue, ignorePaths...))
+}
+
+// AssertSubset 断言expected是actual的子集：expected中的每个键都存在于actual且值满足子集关系，actual可以有额外的键。
+// 数组要求长度相同，元素逐个满足子集关系
+// AssertSubset asserts that expected is a subset of actual: every key in expected exists in actual with a
+// value that is itself a subset, while actual may have extra keys. Arrays must have the same length with each
+// element a subset
+func AssertSubset(t TestingT, expected, actual interface{}) bool {
+	t.Helper()
+	expectedValue, actualValue, ok := documents(t, expected, actual)
+	if !ok {
+		return false
+	}
+	d := newDiffer(expectedValue, actualValue, nil, true)
+	d.compare(expectedValue, actualValue, "$")
+	return report(t, "JSON document is not a subset", d.differences)
+}
+
+// AssertPathEquals 断言root中path处的值等于expected；expected中的字符串按字符串值比较，而不是JSON文本
+// AssertPathEquals asserts that the value at path in root equals expected; strings in expected are compared
+// as string values, not as JSON text
+func AssertPathEquals(t TestingT, root interface{}, path string, expected interface{}) bool {
+	t.Helper()
+	rootValue, err := toDocument(root)
+	if err != nil {
+		t.Errorf("invalid actual document: %v", err)
+		return false
+	}
+	expectedValue, ok := expected.(xyJson.IValue)
+	if !ok {
+		if expectedValue, err = xyJson.CreateFromRaw(expected); err != nil {
+			t.Errorf("invalid expected value: %v", err)
+			return false
+		}
+	}
+
+	actualValue, err := xyJson.Get(rootValue, path)
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+		return false
+	}
+
+	d := newDiffer(expectedValue, actualValue, nil, false)
+	d.compare(expectedValue, actualValue, path)
+	return report(t, "JSON value differs", d.differences)
+}
+
+// documents 转换两个文档参数
+// documents converts the two document arguments
+func documents(t TestingT, expected, actual interface{}) (xyJson.IValue, xyJson.IValue, bool) {
+	t.Helper()
+	expectedValue, err := toDocument(expected)
+	if err != nil {
+		t.Errorf("invalid expected document: %v", err)
+		return nil, nil, false
+	}
+	actualValue, err := toDocument(actual)
+	if err != nil {
+		t.Errorf("invalid actual document: %v", err)
+		return nil, nil, false
+	}
+	return expectedValue, actualValue, true
+}
+
+// toDocument 将文档参数转换为IValue，字符串和字节切片按JSON文本解析
+// toDocument converts a document argument into an IValue, parsing strings and byte slices as JSON text
+func toDocument(document interface{}) (xyJson.IValue, error) {
+	switch v := document.(type) {
+	case xyJson.IValue:
+		return v, nil
+	case string:
+		return xyJson.ParseString(v)
+	case []byte:
+		return xyJson.Parse(v)
+	default:
+		return xyJson.CreateFromRaw(v)
+	}
+}
+
+// report 有差异时报告失败
+// report reports a failure when there are differences
+func report(t TestingT, title string, differences []Difference) bool {
+	t.Helper()
+	if len(differences) == 0 {
+		return true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(title)
+	sb.WriteByte(':')
+	for i, difference := range differences {
+		if i == MaxReportedDifferences {
+			fmt.Fprintf(&sb, "\n\t... and %d more", len(differences)-i)
+			break
+		}
+		sb.WriteString("\n\t")
+		sb.WriteString(difference.String())
+	}
+	t.Errorf("%s", sb.String())
+	return false
+}
+
+// render 渲染值用于差异输出，过长时截断
+// render renders a value for diff output, truncating long values
+func render(value xyJson.IValue) string {
+	if value == nil {
+		return "<nothing>"
+	}
+	text, err := xyJson.SerializeToString(value)
+	if err != nil {
+		return value.String()
+	}
+	if len(text) > maxRenderedLength {
+		return text[:maxRenderedLength] + "..."
+	}
+	return text
+}
+
+// differ 比较状态
+// differ holds comparison state
+type differ struct {
+	ignored     map[xyJson.IValue]struct{}
+	subset      bool
+	differences []Difference
+}
+
+// newDiffer 创建比较器，将ignorePaths在两个文档中解析为要跳过的节点
+// newDiffer creates a differ, resolving ignorePaths into the nodes to skip in both documents
+func newDiffer(expected, actual xyJson.IValue, ignorePaths []string, subset bool) *differ {
+	d := &differ{subset: subset}
+	for _, path := range ignorePaths {
+		for _, root := range []xyJson.IValue{expected, actual} {
+			matches, err := xyJson.GetAll(root, path)
+			if err != nil {
+				continue
+			}
+			if d.ignored == nil {
+				d.ignored = make(map[xyJson.IValue]struct{})
+			}
+			for _, match := range matches {
+				d.ignored[match] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+// isIgnored 判断节点是否被忽略
+// isIgnored reports whether a node is ignored
+func (d *differ) isIgnored(value xyJson.IValue) bool {
+	if value == nil || d.ignored == nil {
+		return false
+	}
+	_, ignored := d.ignored[value]
+	return ignored
+}
+
+// add 记录差异
+// add records a difference
+func (d *differ) add(path string, kind DifferenceKind, expected, actual xyJson.IValue) {
+	d.differences = append(d.differences, Difference{Path: path, Kind: kind, Expected: expected, Actual: actual})
+}
+
+// compare 递归比较两个值
+// compare compares two values recursively
+func (d *differ) compare(expected, actual xyJson.IValue, path string) {
+	if d.isIgnored(expected) || d.isIgnored(actual) {
+		return
+	}
+	if expected == nil || actual == nil || expected.Type() != actual.Type() {
+		d.add(path, Changed, expected, actual)
+		return
+	}
+
+	switch expected.Type() {
+	case xyJson.ObjectValueType:
+		d.compareObjects(expected.AsObject(), actual.AsObject(), path)
+	case xyJson.ArrayValueType:
+		d.compareArrays(expected.AsArray(), actual.AsArray(), path)
+	case xyJson.NumberValueType:
+		if !numbersEqual(expected, actual) {
+			d.add(path, Changed, expected, actual)
+		}
+	default:
+		if !expected.Equals(actual) {
+			d.add(path, Changed, expected, actual)
+		}
+	}
+}
+
+// compareObjects 按键比较对象，子集模式下不报告多余的键
+// compareObjects compares objects by key, not reporting extra keys in subset mode
+func (d *differ) compareObjects(expected, actual xyJson.IObject, path string) {
+	for _, key := range expected.Keys() {
+		childPath := path + "." + xyJson.EscapeKey(key)
+		expectedChild := expected.Get(key)
+		if !actual.Has(key) {
+			if !d.isIgnored(expectedChild) {
+				d.add(childPath, Missing, expectedChild, nil)
+			}
+			continue
+		}
+		d.compare(expectedChild, actual.Get(key), childPath)
+	}
+	if d.subset {
+		return
+	}
+	for _, key := range actual.Keys() {
+		if expected.Has(key) {
+			continue
+		}
+		if actualChild := actual.Get(key); !d.isIgnored(actualChild) {
+			d.add(path+"."+xyJson.EscapeKey(key), Unexpected, nil, actualChild)
+		}
+	}
+}
+
+// compareArrays 按下标比较数组
+// compareArrays compares arrays by index
+func (d *differ) compareArrays(expected, actual xyJson.IArray, path string) {
+	length := expected.Length()
+	if actual.Length() > length {
+		length = actual.Length()
+	}
+	for i := 0; i < length; i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= actual.Length():
+			if expectedChild := expected.Get(i); !d.isIgnored(expectedChild) {
+				d.add(childPath, Missing, expectedChild, nil)
+			}
+		case i >= expected.Length():
+			if actualChild := actual.Get(i); !d.isIgnored(actualChild) {
+				d.add(childPath, Unexpected, nil, actualChild)
+			}
+		default:
+			d.compare(expected.Get(i), actual.Get(i), childPath)
+		}
+	}
+}
+
+// numbersEqual 按数值比较两个数字，整数按int64比较以避免精度损失
+// numbersEqual compares two numbers by value, comparing integers as int64 to avoid losing precision
+func numbersEqual(expected, actual xyJson.IValue) bool {
+	if a, ok := expected.AsInt64OK(); ok {
+		if b, ok := actual.AsInt64OK(); ok {
+			return a == b
+		}
+	}
+	a, aok := expected.AsFloat64OK()
+	b, bok := actual.AsFloat64OK()
+	return aok && bok && a == b
+}
@@ -0,0 +1,38 @@
+package xyJson
+
+import "runtime"
+
+// yielder 长时间运行的操作中按间隔让出处理器的计数器，nil表示从不让出
+// yielder counts work in long-running operations and yields the processor at an interval, nil never yields
+//
+// 序列化一个巨大的文档或对其执行递归查询可能长时间占用当前P，使同一P上对延迟敏感的协程得不到调度；
+// 每完成interval个单位的工作调用一次runtime.Gosched可以让它们及时运行，代价是操作本身稍慢
+// Serializing a huge document or running a recursive query over it may hold the current P for a long time,
+// starving latency-sensitive goroutines on the same P; calling runtime.Gosched every interval units of work
+// lets them run in time, at the cost of a slightly slower operation
+type yielder struct {
+	interval int
+	count    int
+}
+
+// newYielder 创建让出计数器，interval<=0时返回nil
+// newYielder creates a yield counter, nil when interval <= 0
+func newYielder(interval int) *yielder {
+	if interval <= 0 {
+		return nil
+	}
+	return &yielder{interval: interval}
+}
+
+// step 记录n个单位的工作，累计达到间隔时让出处理器
+// step records n units of work, yielding the processor once the interval is reached
+func (y *yielder) step(n int) {
+	if y == nil {
+		return
+	}
+	y.count += n
+	if y.count >= y.interval {
+		y.count = 0
+		runtime.Gosched()
+	}
+}
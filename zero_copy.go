@@ -0,0 +1,111 @@
+package xyJson
+
+import (
+	"strings"
+)
+
+// ParseZeroCopy 以零拷贝模式解析JSON字节数组
+// ParseZeroCopy parses a JSON byte array in zero-copy mode
+//
+// 安全约定：未转义的字符串值和对象键直接引用data而不复制，因此在结果树被丢弃或Detach之前，
+// 调用方不得修改、复用或释放data。含转义的字符串和数字总是被复制。
+// 使用xyjson_debug构建标签时，Detach和VerifyZeroCopy会检测data在借用期间是否被修改
+// Safety contract: unescaped string values and object keys reference data without copying, so the caller
+// must not modify, reuse or release data until the resulting tree is dropped or detached. Strings containing
+// escapes and numbers are always copied. With the xyjson_debug build tag, Detach and VerifyZeroCopy detect
+// whether data was modified while borrowed
+//
+// 使用示例 Usage Example:
+//
+//	buf := readFromPool()
+//	root, err := xyJson.ParseZeroCopy(buf)
+//	if err != nil {
+//		return err
+//	}
+//	root = xyJson.Detach(root) // 需要在归还buf后保留树时 / when the tree must outlive buf
+//	returnToPool(buf)
+func ParseZeroCopy(data []byte) (IValue, error) {
+	timer := GetGlobalMonitor().StartParseTimer()
+	p := NewParserWithFactory(defaultFactory()).(*parser)
+	p.zeroCopy = true
+
+	root, err := p.Parse(data)
+	if err != nil {
+		timer.EndWithError()
+		return nil, err
+	}
+	timer.End()
+
+	trackBorrowed(root, data)
+	return root, nil
+}
+
+// Detach 将零拷贝树中的字符串和键复制为独立内存，返回同一棵树
+// Detach copies the strings and keys of a zero-copy tree into owned memory, returning the same tree
+//
+// Detach原地修改树，之后可以安全地释放输入缓冲区；对非零拷贝的树调用也是安全的，只是多做一次复制
+// Detach modifies the tree in place, after which the input buffer can be released safely; calling it on a
+// tree that is not zero-copy is also safe, it merely copies once more
+func Detach(root IValue) IValue {
+	if root == nil {
+		return nil
+	}
+	verifyBorrowed(root)
+	detachValue(root, 0)
+	releaseBorrowed(root)
+	return root
+}
+
+// VerifyZeroCopy 检查零拷贝树借用的缓冲区自解析以来是否被修改
+// VerifyZeroCopy checks whether the buffer borrowed by a zero-copy tree was modified since parsing
+//
+// 仅在xyjson_debug构建中进行检查，其它构建总是返回nil
+// The check is only performed in xyjson_debug builds, other builds always return nil
+func VerifyZeroCopy(root IValue) error {
+	return checkBorrowed(root)
+}
+
+// detachValue 递归复制值中引用外部内存的字符串
+// detachValue recursively copies strings that reference external memory
+func detachValue(value IValue, depth int) {
+	if depth > MaxNestingDepth {
+		return
+	}
+
+	switch v := value.(type) {
+	case *scalarValue:
+		if s, ok := v.rawData.(string); ok {
+			v.rawData = strings.Clone(s)
+		}
+	case *objectValue:
+		v.mu.Lock()
+		var children []IValue
+		if v.data != nil {
+			data := make(map[string]IValue, len(v.data))
+			for key, child := range v.data {
+				data[strings.Clone(key)] = child
+				children = append(children, child)
+			}
+			v.data = data
+			v.capacity = len(data)
+		} else {
+			for i := 0; i < v.n; i++ {
+				v.small[i].key = strings.Clone(v.small[i].key)
+				children = append(children, v.small[i].value)
+			}
+		}
+		v.mu.Unlock()
+
+		for _, child := range children {
+			detachValue(child, depth+1)
+		}
+	case *arrayValue:
+		v.mu.RLock()
+		children := v.data
+		v.mu.RUnlock()
+
+		for _, child := range children {
+			detachValue(child, depth+1)
+		}
+	}
+}
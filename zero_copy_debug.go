@@ -0,0 +1,61 @@
+//go:build xyjson_debug
+
+package xyJson
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync"
+)
+
+// borrowRecord 零拷贝树借用的缓冲区及其解析时的校验和
+// borrowRecord is the buffer borrowed by a zero-copy tree and its checksum at parse time
+type borrowRecord struct {
+	data     []byte
+	checksum uint32
+}
+
+var (
+	// borrowedBuffers 调试构建中记录每棵零拷贝树借用的缓冲区，直到Detach为止
+	// borrowedBuffers records the buffer borrowed by each zero-copy tree in debug builds, until Detach
+	borrowedBuffers = make(map[IValue]borrowRecord)
+	borrowedMutex   sync.Mutex
+)
+
+// trackBorrowed 记录零拷贝树借用的缓冲区
+// trackBorrowed records the buffer borrowed by a zero-copy tree
+func trackBorrowed(root IValue, data []byte) {
+	borrowedMutex.Lock()
+	defer borrowedMutex.Unlock()
+	borrowedBuffers[root] = borrowRecord{data: data, checksum: crc32.ChecksumIEEE(data)}
+}
+
+// releaseBorrowed 取消记录
+// releaseBorrowed stops tracking a tree
+func releaseBorrowed(root IValue) {
+	borrowedMutex.Lock()
+	defer borrowedMutex.Unlock()
+	delete(borrowedBuffers, root)
+}
+
+// checkBorrowed 检查借用的缓冲区是否被修改
+// checkBorrowed checks whether the borrowed buffer was modified
+func checkBorrowed(root IValue) error {
+	borrowedMutex.Lock()
+	record, exists := borrowedBuffers[root]
+	borrowedMutex.Unlock()
+
+	if !exists || crc32.ChecksumIEEE(record.data) == record.checksum {
+		return nil
+	}
+	return NewInvalidOperationError("zero-copy access",
+		fmt.Sprintf("input buffer of %d bytes was modified while borrowed by the tree", len(record.data)))
+}
+
+// verifyBorrowed 借用的缓冲区被修改时panic
+// verifyBorrowed panics if the borrowed buffer was modified
+func verifyBorrowed(root IValue) {
+	if err := checkBorrowed(root); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !xyjson_debug
+
+package xyJson
+
+// trackBorrowed 非调试构建中不记录借用关系
+// trackBorrowed does not track borrowing outside debug builds
+func trackBorrowed(root IValue, data []byte) {}
+
+// releaseBorrowed 非调试构建中无操作
+// releaseBorrowed is a no-op outside debug builds
+func releaseBorrowed(root IValue) {}
+
+// checkBorrowed 非调试构建中总是返回nil
+// checkBorrowed always returns nil outside debug builds
+func checkBorrowed(root IValue) error {
+	return nil
+}
+
+// verifyBorrowed 非调试构建中无操作
+// verifyBorrowed is a no-op outside debug builds
+func verifyBorrowed(root IValue) {}